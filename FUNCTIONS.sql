@@ -122,6 +122,8 @@ SELECT SPACE(10) as ten_spaces;
 SELECT ASCII('A') as ascii_value;
 SELECT CHAR(65) as char_from_ascii;
 SELECT SOUNDEX('Smith') as soundex_code;
+SELECT DIFFERENCE('Smith', 'Smythe') as soundex_similarity;
+SELECT LEVENSHTEIN('kitten', 'sitting') as edit_distance;
 SELECT QUOTE('It''s a test') as quoted_string;
 
 -- ============================================================
@@ -406,6 +408,18 @@ SELECT UNHEX('48656C6C6F') as hex_decoded;
 -- SELECT JSON_GET('{"name":"John","age":30}', 'name') as json_name;
 -- SELECT JSON_EXTRACT('{"user":{"name":"Jane"}}', 'user.name') as nested_value;
 
+-- JSON modification (text in, text out; JSON columns stay decoded)
+-- SELECT JSON_SET('{"a":1}', 'b.c', 2) as json_set;
+-- SELECT JSON_DELETE('{"a":[1,2,3]}', 'a[1]') as json_delete;
+-- SELECT JSON_MERGE('{"a":{"x":1}}', '{"a":{"y":2}}') as json_merge;
+-- SELECT JSON_PATCH('{"a":1,"b":2}', '{"a":null,"c":3}') as json_patch;
+
+-- JSON construction and inspection
+-- SELECT JSON_ARRAY(1, 'two', NULL) as json_array;
+-- SELECT JSON_OBJECT('id', 1, 'tags', JSON_ARRAY('a', 'b')) as json_object;
+-- SELECT JSON_TYPE('[1,2]') as json_type, JSON_VALID('{"a":') as json_valid;
+-- SELECT category, JSON_ARRAYAGG(name) FROM products GROUP BY category;
+
 -- ============================================================
 -- TYPE CONVERSION AND INTROSPECTION
 -- ============================================================
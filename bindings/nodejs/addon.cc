@@ -0,0 +1,167 @@
+// Node-API addon for tinySQL. The engine lives in the Go shared library
+// built from libtinysql/; the addon loads it at run time instead of
+// linking it, so the same code builds with the platform's own C++
+// toolchain on Linux, macOS and Windows. Queries run on the libuv thread pool and settle a
+// Promise with the JSON payload from TinySQLExec.
+
+#include <node_api.h>
+
+#include <string>
+
+#ifdef _WIN32
+#include <windows.h>
+#else
+#include <dlfcn.h>
+#endif
+
+namespace {
+
+typedef char* (*ExecFn)(char*);
+typedef void (*ResetFn)(void);
+typedef void (*FreeFn)(char*);
+
+ExecFn tinysql_exec = nullptr;
+ResetFn tinysql_reset = nullptr;
+FreeFn tinysql_free = nullptr;
+
+#define CHECK(env, call)                                   \
+  do {                                                     \
+    if ((call) != napi_ok) {                               \
+      napi_throw_error((env), nullptr, "N-API call failed"); \
+      return nullptr;                                      \
+    }                                                      \
+  } while (0)
+
+bool GetString(napi_env env, napi_value value, std::string* out) {
+  size_t len = 0;
+  if (napi_get_value_string_utf8(env, value, nullptr, 0, &len) != napi_ok) {
+    return false;
+  }
+  out->resize(len);
+  return napi_get_value_string_utf8(env, value, &(*out)[0], len + 1, &len) == napi_ok;
+}
+
+bool Loaded(napi_env env) {
+  if (tinysql_exec == nullptr) {
+    napi_throw_error(env, nullptr, "tinySQL library is not loaded");
+    return false;
+  }
+  return true;
+}
+
+// load(path) opens the Go shared library and resolves its exports.
+napi_value Load(napi_env env, napi_callback_info info) {
+  size_t argc = 1;
+  napi_value argv[1];
+  CHECK(env, napi_get_cb_info(env, info, &argc, argv, nullptr, nullptr));
+  std::string path;
+  if (argc < 1 || !GetString(env, argv[0], &path)) {
+    napi_throw_type_error(env, nullptr, "load expects the library path");
+    return nullptr;
+  }
+
+#ifdef _WIN32
+  HMODULE lib = LoadLibraryA(path.c_str());
+  if (lib == nullptr) {
+    napi_throw_error(env, nullptr, ("cannot load " + path).c_str());
+    return nullptr;
+  }
+  tinysql_exec = reinterpret_cast<ExecFn>(GetProcAddress(lib, "TinySQLExec"));
+  tinysql_reset = reinterpret_cast<ResetFn>(GetProcAddress(lib, "TinySQLReset"));
+  tinysql_free = reinterpret_cast<FreeFn>(GetProcAddress(lib, "TinySQLFree"));
+#else
+  void* lib = dlopen(path.c_str(), RTLD_NOW | RTLD_LOCAL);
+  if (lib == nullptr) {
+    napi_throw_error(env, nullptr, dlerror());
+    return nullptr;
+  }
+  tinysql_exec = reinterpret_cast<ExecFn>(dlsym(lib, "TinySQLExec"));
+  tinysql_reset = reinterpret_cast<ResetFn>(dlsym(lib, "TinySQLReset"));
+  tinysql_free = reinterpret_cast<FreeFn>(dlsym(lib, "TinySQLFree"));
+#endif
+
+  if (tinysql_exec == nullptr || tinysql_reset == nullptr || tinysql_free == nullptr) {
+    tinysql_exec = nullptr;
+    napi_throw_error(env, nullptr, (path + " does not export the tinySQL functions").c_str());
+  }
+  return nullptr;
+}
+
+struct ExecWork {
+  napi_async_work work;
+  napi_deferred deferred;
+  std::string sql;
+  std::string payload;
+};
+
+void ExecExecute(napi_env env, void* data) {
+  ExecWork* w = static_cast<ExecWork*>(data);
+  char* res = tinysql_exec(&w->sql[0]);
+  w->payload = res;
+  tinysql_free(res);
+}
+
+void ExecComplete(napi_env env, napi_status status, void* data) {
+  ExecWork* w = static_cast<ExecWork*>(data);
+  napi_value payload;
+  if (status == napi_ok &&
+      napi_create_string_utf8(env, w->payload.c_str(), w->payload.size(), &payload) == napi_ok) {
+    napi_resolve_deferred(env, w->deferred, payload);
+  } else {
+    napi_value msg, err;
+    napi_create_string_utf8(env, "tinySQL query was cancelled", NAPI_AUTO_LENGTH, &msg);
+    napi_create_error(env, nullptr, msg, &err);
+    napi_reject_deferred(env, w->deferred, err);
+  }
+  napi_delete_async_work(env, w->work);
+  delete w;
+}
+
+// exec(sql) returns a Promise of the TinySQLExec JSON payload.
+napi_value Exec(napi_env env, napi_callback_info info) {
+  size_t argc = 1;
+  napi_value argv[1];
+  CHECK(env, napi_get_cb_info(env, info, &argc, argv, nullptr, nullptr));
+  if (!Loaded(env)) {
+    return nullptr;
+  }
+  ExecWork* w = new ExecWork();
+  if (argc < 1 || !GetString(env, argv[0], &w->sql)) {
+    delete w;
+    napi_throw_type_error(env, nullptr, "exec expects an SQL string");
+    return nullptr;
+  }
+
+  napi_value promise, name;
+  if (napi_create_promise(env, &w->deferred, &promise) != napi_ok ||
+      napi_create_string_utf8(env, "tinysql.exec", NAPI_AUTO_LENGTH, &name) != napi_ok ||
+      napi_create_async_work(env, nullptr, name, ExecExecute, ExecComplete, w, &w->work) != napi_ok) {
+    delete w;
+    napi_throw_error(env, nullptr, "cannot schedule the query");
+    return nullptr;
+  }
+  CHECK(env, napi_queue_async_work(env, w->work));
+  return promise;
+}
+
+// reset() replaces the database with an empty one.
+napi_value Reset(napi_env env, napi_callback_info info) {
+  if (Loaded(env)) {
+    tinysql_reset();
+  }
+  return nullptr;
+}
+
+napi_value Init(napi_env env, napi_value exports) {
+  napi_property_descriptor props[] = {
+      {"load", nullptr, Load, nullptr, nullptr, nullptr, napi_default, nullptr},
+      {"exec", nullptr, Exec, nullptr, nullptr, nullptr, napi_default, nullptr},
+      {"reset", nullptr, Reset, nullptr, nullptr, nullptr, napi_default, nullptr},
+  };
+  CHECK(env, napi_define_properties(env, exports, sizeof(props) / sizeof(props[0]), props));
+  return exports;
+}
+
+}  // namespace
+
+NAPI_MODULE(NODE_GYP_MODULE_NAME, Init)
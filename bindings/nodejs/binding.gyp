@@ -0,0 +1,31 @@
+{
+  "variables": {
+    "conditions": [
+      ["OS=='win'", {"tinysql_lib": "tinysql.dll"}],
+      ["OS=='mac'", {"tinysql_lib": "libtinysql.dylib"}],
+      ["OS!='win' and OS!='mac'", {"tinysql_lib": "libtinysql.so"}]
+    ]
+  },
+  "targets": [
+    {
+      "target_name": "libtinysql",
+      "type": "none",
+      "actions": [
+        {
+          "action_name": "go_build",
+          "inputs": ["libtinysql/main.go"],
+          "outputs": ["<(PRODUCT_DIR)/<(tinysql_lib)"],
+          "action": ["go", "build", "-buildmode=c-shared", "-o", "<(PRODUCT_DIR)/<(tinysql_lib)", "./libtinysql"]
+        }
+      ]
+    },
+    {
+      "target_name": "tinysql",
+      "dependencies": ["libtinysql"],
+      "sources": ["addon.cc"],
+      "conditions": [
+        ["OS!='win' and OS!='mac'", {"libraries": ["-ldl"]}]
+      ]
+    }
+  ]
+}
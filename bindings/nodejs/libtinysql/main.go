@@ -0,0 +1,133 @@
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"unsafe"
+
+	tsql "github.com/SimonWaldherr/tinySQL"
+	"github.com/SimonWaldherr/tinySQL/internal/engine"
+)
+
+var (
+	nodeDB   = tsql.NewDB()
+	nodeLock sync.Mutex
+)
+
+//export TinySQLExec
+func TinySQLExec(sql *C.char) *C.char {
+	return cStringJSON(execSQL(C.GoString(sql)))
+}
+
+// execSQL runs query and returns its payload: columns and rows for
+// statements that produce a result, rowsAffected for everything else.
+func execSQL(query string) map[string]any {
+	stmt, err := tsql.ParseSQL(query)
+	if err != nil {
+		return errorPayload(err)
+	}
+
+	nodeLock.Lock()
+	defer nodeLock.Unlock()
+
+	before := tableRows(stmt)
+	rs, err := tsql.Execute(context.Background(), nodeDB, "default", stmt)
+	if err != nil {
+		return errorPayload(err)
+	}
+
+	switch s := stmt.(type) {
+	case *engine.Insert:
+		if len(s.Returning) == 0 {
+			n := tableRows(stmt) - before
+			if s.OnConflict != nil && !s.OnConflict.DoNothing {
+				// Every VALUES row either appends or updates one row.
+				n = len(s.Rows)
+			}
+			return map[string]any{"status": "ok", "rowsAffected": n}
+		}
+	case *engine.Update:
+		if len(s.Returning) == 0 {
+			return map[string]any{"status": "ok", "rowsAffected": countCell(rs, "updated")}
+		}
+	case *engine.Delete:
+		if len(s.Returning) == 0 {
+			return map[string]any{"status": "ok", "rowsAffected": countCell(rs, "deleted")}
+		}
+	}
+	if rs == nil || len(rs.Cols) == 0 {
+		return map[string]any{"status": "ok", "rowsAffected": 0}
+	}
+
+	rows := make([]map[string]any, len(rs.Rows))
+	for i, row := range rs.Rows {
+		obj := make(map[string]any, len(rs.Cols))
+		for _, col := range rs.Cols {
+			obj[col] = row[strings.ToLower(col)]
+		}
+		rows[i] = obj
+	}
+	return map[string]any{
+		"status":  "ok",
+		"columns": rs.Cols,
+		"rows":    rows,
+	}
+}
+
+// tableRows returns the size of an INSERT's target table, or 0.
+func tableRows(stmt tsql.Statement) int {
+	ins, ok := stmt.(*engine.Insert)
+	if !ok {
+		return 0
+	}
+	t, err := nodeDB.Get("default", ins.Table)
+	if err != nil {
+		return 0
+	}
+	return len(t.Rows)
+}
+
+// countCell reads the count an UPDATE or DELETE reports in its one-row
+// summary.
+func countCell(rs *tsql.ResultSet, name string) int {
+	if rs == nil || len(rs.Rows) != 1 {
+		return 0
+	}
+	n, _ := rs.Rows[0][name].(int)
+	return n
+}
+
+//export TinySQLReset
+func TinySQLReset() {
+	nodeLock.Lock()
+	defer nodeLock.Unlock()
+	nodeDB = tsql.NewDB()
+}
+
+//export TinySQLFree
+func TinySQLFree(ptr *C.char) {
+	if ptr != nil {
+		C.free(unsafe.Pointer(ptr))
+	}
+}
+
+func errorPayload(err error) map[string]any {
+	return map[string]any{
+		"status": "error",
+		"error":  err.Error(),
+	}
+}
+
+func cStringJSON(v any) *C.char {
+	buf, _ := json.Marshal(v)
+	return C.CString(string(buf))
+}
+
+func main() {}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestExecPayloads(t *testing.T) {
+	TinySQLReset()
+	for _, tc := range []struct {
+		sql  string
+		want string
+	}{
+		{"CREATE TABLE users (id INT, name TEXT)", "map[rowsAffected:0 status:ok]"},
+		{"INSERT INTO users VALUES (1, 'Ada'), (2, 'Grace'), (3, 'Linus')", "map[rowsAffected:3 status:ok]"},
+		{"UPDATE users SET name = 'Alan' WHERE id >= 2", "map[rowsAffected:2 status:ok]"},
+		{"DELETE FROM users WHERE id = 3", "map[rowsAffected:1 status:ok]"},
+		{"SELECT id, name FROM users WHERE id < 3 ORDER BY id", "map[columns:[id name] rows:[map[id:1 name:Ada] map[id:2 name:Alan]] status:ok]"},
+		{"DELETE FROM users WHERE id = 1 RETURNING name", "map[columns:[name] rows:[map[name:Ada]] status:ok]"},
+		{"SELECT * FROM missing", "map[error:no such table \"missing\" (tenant \"default\") status:error]"},
+	} {
+		if got := fmt.Sprint(execSQL(tc.sql)); got != tc.want {
+			t.Errorf("%s:\n got %s\nwant %s", tc.sql, got, tc.want)
+		}
+	}
+}
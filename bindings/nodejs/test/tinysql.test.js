@@ -0,0 +1,61 @@
+'use strict';
+
+const assert = require('assert');
+const tinysql = require('..');
+
+describe('tinysql', function () {
+  beforeEach(async function () {
+    tinysql.reset();
+    await tinysql.exec('CREATE TABLE users (id INT, name TEXT, age INT)');
+  });
+
+  it('creates a table', async function () {
+    assert.deepStrictEqual(await tinysql.exec('CREATE TABLE notes (id INT)'), { rowsAffected: 0 });
+    const res = await tinysql.exec('SELECT * FROM notes');
+    assert.deepStrictEqual(res, { columns: ['id'], rows: [] });
+  });
+
+  it('inserts rows', async function () {
+    const res = await tinysql.exec("INSERT INTO users VALUES (1, 'Ada', 36), (2, 'Grace', 45)");
+    assert.deepStrictEqual(res, { rowsAffected: 2 });
+  });
+
+  it('selects with WHERE', async function () {
+    await tinysql.exec("INSERT INTO users VALUES (1, 'Ada', 36), (2, 'Grace', 45), (3, 'Linus', 28)");
+    const res = await tinysql.exec('SELECT id, name FROM users WHERE age > 30 ORDER BY id');
+    assert.deepStrictEqual(res.columns, ['id', 'name']);
+    assert.deepStrictEqual(res.rows, [{ id: 1, name: 'Ada' }, { id: 2, name: 'Grace' }]);
+  });
+
+  it('updates rows', async function () {
+    await tinysql.exec("INSERT INTO users VALUES (1, 'Ada', 36), (2, 'Grace', 45)");
+    assert.deepStrictEqual(await tinysql.exec('UPDATE users SET age = age + 1 WHERE id = 2'), { rowsAffected: 1 });
+    const res = await tinysql.exec('SELECT age FROM users WHERE id = 2');
+    assert.deepStrictEqual(res.rows, [{ age: 46 }]);
+  });
+
+  it('deletes rows', async function () {
+    await tinysql.exec("INSERT INTO users VALUES (1, 'Ada', 36), (2, 'Grace', 45), (3, 'Linus', 28)");
+    assert.deepStrictEqual(await tinysql.exec('DELETE FROM users WHERE age < 40'), { rowsAffected: 2 });
+    const res = await tinysql.exec('SELECT name FROM users');
+    assert.deepStrictEqual(res.rows, [{ name: 'Grace' }]);
+  });
+
+  it('rejects invalid statements', async function () {
+    await assert.rejects(tinysql.exec('SELEKT 1'), Error);
+    await assert.rejects(tinysql.exec('SELECT * FROM missing'), /missing/);
+    await assert.rejects(tinysql.exec(42), TypeError);
+    // The database still works afterwards.
+    assert.deepStrictEqual(await tinysql.exec('SELECT COUNT(*) AS n FROM users'), { columns: ['n'], rows: [{ n: 0 }] });
+  });
+
+  it('runs concurrent queries', async function () {
+    const inserts = [];
+    for (let i = 1; i <= 20; i++) {
+      inserts.push(tinysql.exec(`INSERT INTO users VALUES (${i}, 'user${i}', ${i})`));
+    }
+    await Promise.all(inserts);
+    const res = await tinysql.exec('SELECT COUNT(*) AS n FROM users');
+    assert.strictEqual(res.rows[0].n, 20);
+  });
+});
@@ -0,0 +1,22 @@
+/** A row, keyed by column name. */
+export type Row = Record<string, unknown>;
+
+/** The result of a statement that returns rows, such as SELECT. */
+export interface QueryResult {
+  columns: string[];
+  rows: Row[];
+}
+
+/** The result of any other statement: DML, DDL, ... */
+export interface ExecResult {
+  rowsAffected: number;
+}
+
+/**
+ * Runs one SQL statement against the in-memory database. Rejects with the
+ * engine's error message if the statement fails.
+ */
+export function exec(sql: string): Promise<QueryResult | ExecResult>;
+
+/** Replaces the database with an empty one. */
+export function reset(): void;
@@ -0,0 +1,43 @@
+'use strict';
+
+// Promise-based wrapper around the tinySQL addon. Types are in tinysql.d.ts.
+
+const path = require('path');
+
+const buildDir = path.join(__dirname, 'build', 'Release');
+const libName = {
+  win32: 'tinysql.dll',
+  darwin: 'libtinysql.dylib',
+}[process.platform] || 'libtinysql.so';
+
+const addon = require(path.join(buildDir, 'tinysql.node'));
+addon.load(path.join(buildDir, libName));
+
+/**
+ * Runs one SQL statement against the in-memory database.
+ *
+ * @param {string} sql
+ * @returns {Promise<import('./tinysql').QueryResult | import('./tinysql').ExecResult>}
+ *   `{columns, rows}` for statements that return rows, `{rowsAffected}`
+ *   for everything else. Rejects with the engine's error message.
+ */
+async function exec(sql) {
+  if (typeof sql !== 'string') {
+    throw new TypeError('sql must be a string');
+  }
+  const res = JSON.parse(await addon.exec(sql));
+  if (res.status !== 'ok') {
+    throw new Error(res.error || 'unknown tinySQL error');
+  }
+  if (res.columns) {
+    return { columns: res.columns, rows: res.rows };
+  }
+  return { rowsAffected: res.rowsAffected };
+}
+
+/** Replaces the database with an empty one. */
+function reset() {
+  addon.reset();
+}
+
+module.exports = { exec, reset };
@@ -9,7 +9,72 @@ import ctypes
 import json
 import pathlib
 import sys
-from typing import Any, Dict, List, Optional
+from typing import Any, Dict, Iterator, List, Optional
+
+
+class Cursor:
+    """Iterates over the rows of a SELECT, fetching them from the library
+    as they are needed."""
+
+    def __init__(self, db: "TinySQL", handle: int):
+        self._db = db
+        self._handle = handle
+        # A zero-row fetch reports the columns, or the query's error.
+        try:
+            self.columns: List[str] = db._handle_response(db.lib.TinySQLFetchMany(handle, 0))["columns"]
+        except RuntimeError:
+            self.close()
+            raise
+
+    def fetchone(self) -> Optional[Dict[str, Any]]:
+        return self._db._handle_response(self._db.lib.TinySQLFetchOne(self._handle))["row"]
+
+    def fetchmany(self, size: int = 100) -> List[Dict[str, Any]]:
+        return self._db._handle_response(self._db.lib.TinySQLFetchMany(self._handle, size))["rows"]
+
+    def close(self) -> None:
+        self._db.lib.TinySQLCloseCursor(self._handle)
+
+    def __iter__(self) -> Iterator[Dict[str, Any]]:
+        while True:
+            rows = self.fetchmany()
+            if not rows:
+                return
+            yield from rows
+
+    def __enter__(self) -> "Cursor":
+        return self
+
+    def __exit__(self, *exc: Any) -> None:
+        self.close()
+
+
+class Transaction:
+    """Runs statements on a private snapshot of the database until it is
+    committed or rolled back. As a context manager it commits on success
+    and rolls back on an exception."""
+
+    def __init__(self, db: "TinySQL", handle: int):
+        self._db = db
+        self._handle = handle
+
+    def execute(self, sql: str) -> Dict[str, Any]:
+        return self._db._handle_response(self._db.lib.TinySQLExecTx(self._handle, sql.encode("utf-8")))
+
+    def commit(self) -> None:
+        self._db._handle_response(self._db.lib.TinySQLCommitTx(self._handle))
+
+    def rollback(self) -> None:
+        self._db._handle_response(self._db.lib.TinySQLRollbackTx(self._handle))
+
+    def __enter__(self) -> "Transaction":
+        return self
+
+    def __exit__(self, exc_type: Any, *exc: Any) -> None:
+        if exc_type is None:
+            self.commit()
+        else:
+            self.rollback()
 
 
 class TinySQL:
@@ -39,6 +104,30 @@ class TinySQL:
         self.lib.TinySQLReset.argtypes = []
         self.lib.TinySQLReset.restype = None
 
+        self.lib.TinySQLOpenCursor.argtypes = [ctypes.c_char_p]
+        self.lib.TinySQLOpenCursor.restype = ctypes.c_longlong
+
+        self.lib.TinySQLFetchOne.argtypes = [ctypes.c_longlong]
+        self.lib.TinySQLFetchOne.restype = ctypes.c_void_p
+
+        self.lib.TinySQLFetchMany.argtypes = [ctypes.c_longlong, ctypes.c_longlong]
+        self.lib.TinySQLFetchMany.restype = ctypes.c_void_p
+
+        self.lib.TinySQLCloseCursor.argtypes = [ctypes.c_longlong]
+        self.lib.TinySQLCloseCursor.restype = None
+
+        self.lib.TinySQLBeginTx.argtypes = []
+        self.lib.TinySQLBeginTx.restype = ctypes.c_longlong
+
+        self.lib.TinySQLExecTx.argtypes = [ctypes.c_longlong, ctypes.c_char_p]
+        self.lib.TinySQLExecTx.restype = ctypes.c_void_p
+
+        self.lib.TinySQLCommitTx.argtypes = [ctypes.c_longlong]
+        self.lib.TinySQLCommitTx.restype = ctypes.c_void_p
+
+        self.lib.TinySQLRollbackTx.argtypes = [ctypes.c_longlong]
+        self.lib.TinySQLRollbackTx.restype = ctypes.c_void_p
+
     def version(self) -> str:
         return self.lib.TinySQLVersion().decode("utf-8")
 
@@ -61,6 +150,12 @@ class TinySQL:
         ptr = self.lib.TinySQLExec(sql.encode("utf-8"))
         return self._handle_response(ptr)
 
+    def cursor(self, sql: str) -> Cursor:
+        return Cursor(self, self.lib.TinySQLOpenCursor(sql.encode("utf-8")))
+
+    def transaction(self) -> Transaction:
+        return Transaction(self, self.lib.TinySQLBeginTx())
+
     def save(self, path: str) -> None:
         ptr = self.lib.TinySQLSave(path.encode("utf-8"))
         self._handle_response(ptr)
@@ -86,6 +181,15 @@ def main():
         for row in result.get("rows", []):
             print(f"  {row}")
             
+        print("Iterating with a cursor...")
+        with db.cursor("SELECT name FROM users ORDER BY id;") as cur:
+            for row in cur:
+                print(f"  {row['name']}")
+
+        print("Adding a user in a transaction...")
+        with db.transaction() as tx:
+            tx.execute("INSERT INTO users VALUES (4, 'Dave');")
+
         print("Saving database to 'test.db'...")
         db.save("test.db")
         
@@ -28,6 +28,7 @@ extern const char *_GoStringPtr(_GoString_ s);
 #line 1 "cgo-generated-wrapper"
 
 
+
 /* End of preamble from import "C" comments.  */
 
 
@@ -91,8 +92,16 @@ extern char* TinySQLVersion(void);
 extern char* TinySQLSave(char* path);
 extern char* TinySQLLoad(char* path);
 extern char* TinySQLExec(char* sql);
+extern GoInt TinySQLOpenCursor(char* sql);
+extern char* TinySQLFetchOne(GoInt handle);
+extern char* TinySQLFetchMany(GoInt handle, GoInt n);
+extern void TinySQLCloseCursor(GoInt handle);
 extern void TinySQLReset(void);
 extern void TinySQLFree(char* ptr);
+extern GoInt TinySQLBeginTx(void);
+extern char* TinySQLExecTx(GoInt txHandle, char* sql);
+extern char* TinySQLCommitTx(GoInt txHandle);
+extern char* TinySQLRollbackTx(GoInt txHandle);
 
 #ifdef __cplusplus
 }
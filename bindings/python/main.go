@@ -8,11 +8,15 @@ import "C"
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"unsafe"
 
 	tsql "github.com/SimonWaldherr/tinySQL"
+	"github.com/SimonWaldherr/tinySQL/internal/engine"
 	"github.com/SimonWaldherr/tinySQL/internal/storage"
 )
 
@@ -55,43 +59,185 @@ func TinySQLLoad(path *C.char) *C.char {
 
 //export TinySQLExec
 func TinySQLExec(sql *C.char) *C.char {
+	return cStringJSON(execSQL(C.GoString(sql)))
+}
+
+func execSQL(query string) map[string]any {
 	pyLock.Lock()
 	defer pyLock.Unlock()
+	return execOn(pyDB, query)
+}
 
-	query := C.GoString(sql)
+// execOn runs query on db and returns the TinySQLExec payload.
+func execOn(db *tsql.DB, query string) map[string]any {
 	stmt, err := tsql.ParseSQL(query)
 	if err != nil {
-		return cStringError(err)
+		return errorPayload(err)
 	}
 
-	rs, err := tsql.Execute(context.Background(), pyDB, "default", stmt)
+	rs, err := tsql.Execute(context.Background(), db, "default", stmt)
 	if err != nil {
-		return cStringError(err)
+		return errorPayload(err)
 	}
 
 	if rs == nil {
-		return cStringJSON(map[string]any{
+		return map[string]any{
 			"status": "ok",
 			"rows":   0,
-		})
+		}
 	}
 
 	rows := make([]map[string]any, len(rs.Rows))
 	for i, row := range rs.Rows {
-		obj := make(map[string]any)
-		for _, col := range rs.Cols {
-			obj[col] = row[strings.ToLower(col)]
-		}
-		rows[i] = obj
+		rows[i] = rowObject(rs.Cols, row)
 	}
 
-	payload := map[string]any{
+	return map[string]any{
 		"status":  "ok",
 		"columns": rs.Cols,
 		"rows":    rows,
 	}
+}
+
+// Cursors let Python iterate over a result instead of receiving it as one
+// JSON document. The query runs when the cursor is opened; rows are
+// converted to JSON only as they are fetched. Each cursor keeps its own
+// position, so several can be read side by side.
+type cursor struct {
+	mu     sync.Mutex
+	cols   []string
+	rows   []tsql.Row
+	next   int
+	err    error
+	closed bool
+}
+
+var (
+	cursors    sync.Map // int -> *cursor
+	lastCursor atomic.Int64
+)
+
+//export TinySQLOpenCursor
+func TinySQLOpenCursor(sql *C.char) int {
+	return openCursor(C.GoString(sql))
+}
+
+//export TinySQLFetchOne
+func TinySQLFetchOne(handle int) *C.char {
+	return cStringJSON(fetchOne(handle))
+}
+
+//export TinySQLFetchMany
+func TinySQLFetchMany(handle int, n int) *C.char {
+	return cStringJSON(fetchMany(handle, n))
+}
+
+//export TinySQLCloseCursor
+func TinySQLCloseCursor(handle int) {
+	closeCursor(handle)
+}
 
-	return cStringJSON(payload)
+// openCursor runs a SELECT and returns the handle of a cursor over its
+// rows. A statement that fails still gets a handle; every fetch on it
+// reports the error, so callers check the first fetch.
+func openCursor(query string) int {
+	c := &cursor{}
+	c.cols, c.rows, c.err = selectRows(query)
+	handle := int(lastCursor.Add(1))
+	cursors.Store(handle, c)
+	return handle
+}
+
+func selectRows(query string) ([]string, []tsql.Row, error) {
+	stmt, err := tsql.ParseSQL(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, ok := stmt.(*engine.Select); !ok {
+		return nil, nil, errors.New("cursors need a SELECT statement; use TinySQLExec for other statements")
+	}
+
+	pyLock.Lock()
+	defer pyLock.Unlock()
+	rs, err := tsql.Execute(context.Background(), pyDB, "default", stmt)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rs == nil {
+		return []string{}, nil, nil
+	}
+	return rs.Cols, rs.Rows, nil
+}
+
+// fetchOne returns {"status":"ok","row":...}; row is null once the cursor
+// is exhausted.
+func fetchOne(handle int) map[string]any {
+	res := fetchMany(handle, 1)
+	if res["status"] != "ok" {
+		return res
+	}
+	var row any
+	if rows := res["rows"].([]map[string]any); len(rows) > 0 {
+		row = rows[0]
+	}
+	return map[string]any{"status": "ok", "row": row}
+}
+
+// fetchMany returns the next n rows or fewer once the cursor runs out.
+// n = 0 fetches nothing and is a cheap way to read the columns or the
+// error of a freshly opened cursor.
+func fetchMany(handle int, n int) map[string]any {
+	if n < 0 {
+		return errorPayload(fmt.Errorf("fetch size must be >= 0, got %d", n))
+	}
+	v, ok := cursors.Load(handle)
+	if !ok {
+		return errorPayload(fmt.Errorf("cursor %d is closed or does not exist", handle))
+	}
+	c := v.(*cursor)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case c.closed:
+		// Closed between the lookup and the lock.
+		return errorPayload(fmt.Errorf("cursor %d is closed or does not exist", handle))
+	case c.err != nil:
+		return errorPayload(c.err)
+	}
+
+	end := min(c.next+n, len(c.rows))
+	rows := make([]map[string]any, 0, end-c.next)
+	for _, row := range c.rows[c.next:end] {
+		rows = append(rows, rowObject(c.cols, row))
+	}
+	c.next = end
+	return map[string]any{
+		"status":  "ok",
+		"columns": c.cols,
+		"rows":    rows,
+	}
+}
+
+// closeCursor releases a cursor's rows. Closing an unknown or already
+// closed cursor does nothing.
+func closeCursor(handle int) {
+	v, ok := cursors.LoadAndDelete(handle)
+	if !ok {
+		return
+	}
+	c := v.(*cursor)
+	c.mu.Lock()
+	c.closed = true
+	c.rows = nil
+	c.mu.Unlock()
+}
+
+func rowObject(cols []string, row tsql.Row) map[string]any {
+	obj := make(map[string]any, len(cols))
+	for _, col := range cols {
+		obj[col] = row[strings.ToLower(col)]
+	}
+	return obj
 }
 
 //export TinySQLReset
@@ -109,10 +255,14 @@ func TinySQLFree(ptr *C.char) {
 }
 
 func cStringError(err error) *C.char {
-	return cStringJSON(map[string]any{
+	return cStringJSON(errorPayload(err))
+}
+
+func errorPayload(err error) map[string]any {
+	return map[string]any{
 		"status": "error",
 		"error":  err.Error(),
-	})
+	}
 }
 
 func cStringJSON(v any) *C.char {
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// seedUsers resets the database to a users table with ids 1..n.
+func seedUsers(t *testing.T, n int) {
+	t.Helper()
+	TinySQLReset()
+	stmts := []string{"CREATE TABLE users (id INT, name TEXT)"}
+	for i := 1; i <= n; i++ {
+		stmts = append(stmts, fmt.Sprintf("INSERT INTO users VALUES (%d, 'user%d')", i, i))
+	}
+	for _, sql := range stmts {
+		if res := execSQL(sql); res["status"] != "ok" {
+			t.Fatalf("%s: %v", sql, res["error"])
+		}
+	}
+}
+
+func fetchIDs(t *testing.T, res map[string]any) string {
+	t.Helper()
+	if res["status"] != "ok" {
+		t.Fatalf("fetch failed: %v", res["error"])
+	}
+	var ids []string
+	for _, r := range res["rows"].([]map[string]any) {
+		ids = append(ids, fmt.Sprint(r["id"]))
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestCursorFetchOneAndMany(t *testing.T) {
+	seedUsers(t, 5)
+	h := openCursor("SELECT id, name FROM users ORDER BY id")
+	defer closeCursor(h)
+
+	for want := 1; want <= 2; want++ {
+		res := fetchOne(h)
+		row, _ := res["row"].(map[string]any)
+		if res["status"] != "ok" || fmt.Sprint(row["id"]) != fmt.Sprint(want) || row["name"] != fmt.Sprintf("user%d", want) {
+			t.Fatalf("fetchOne = %v, want id %d", res, want)
+		}
+	}
+	if got := fetchIDs(t, fetchMany(h, 2)); got != "3,4" {
+		t.Fatalf("fetchMany(2) = %s, want 3,4", got)
+	}
+	// The last batch is short, then the cursor is exhausted.
+	if got := fetchIDs(t, fetchMany(h, 10)); got != "5" {
+		t.Fatalf("fetchMany(10) = %s, want 5", got)
+	}
+	if got := fetchIDs(t, fetchMany(h, 10)); got != "" {
+		t.Fatalf("exhausted fetchMany = %s, want no rows", got)
+	}
+	if res := fetchOne(h); res["status"] != "ok" || res["row"] != nil {
+		t.Fatalf("exhausted fetchOne = %v, want a null row", res)
+	}
+}
+
+func TestCursorColumnsAndErrors(t *testing.T) {
+	seedUsers(t, 1)
+	h := openCursor("SELECT name, id FROM users")
+	res := fetchMany(h, 0)
+	if res["status"] != "ok" || fmt.Sprint(res["columns"]) != "[name id]" || len(res["rows"].([]map[string]any)) != 0 {
+		t.Fatalf("fetchMany(0) = %v, want the columns and no rows", res)
+	}
+	if res := fetchMany(h, -1); res["status"] != "error" {
+		t.Errorf("negative fetch size = %v, want an error", res)
+	}
+	closeCursor(h)
+
+	for _, sql := range []string{"SELEKT 1", "SELECT * FROM missing", "DELETE FROM users"} {
+		h := openCursor(sql)
+		if res := fetchOne(h); res["status"] != "error" {
+			t.Errorf("%s: fetch = %v, want an error", sql, res)
+		}
+		closeCursor(h)
+	}
+	// The DELETE was refused, not run.
+	h = openCursor("SELECT id FROM users")
+	defer closeCursor(h)
+	if got := fetchIDs(t, fetchMany(h, 10)); got != "1" {
+		t.Fatalf("rows after refused DELETE = %s", got)
+	}
+}
+
+func TestClosedCursorReturnsError(t *testing.T) {
+	seedUsers(t, 3)
+	h := openCursor("SELECT id FROM users")
+	fetchOne(h)
+	closeCursor(h)
+	for name, res := range map[string]map[string]any{"fetchOne": fetchOne(h), "fetchMany": fetchMany(h, 1)} {
+		if res["status"] != "error" || !strings.Contains(fmt.Sprint(res["error"]), "closed") {
+			t.Errorf("%s on closed cursor = %v, want an error", name, res)
+		}
+	}
+	closeCursor(h) // closing twice is harmless
+	if res := fetchOne(12345678); res["status"] != "error" {
+		t.Errorf("unknown handle = %v, want an error", res)
+	}
+}
+
+func TestConcurrentCursorsAreIsolated(t *testing.T) {
+	seedUsers(t, 100)
+	const readers = 8
+	results := make([]string, readers)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h := openCursor("SELECT id FROM users ORDER BY id")
+			defer closeCursor(h)
+			var ids []string
+			for {
+				res := fetchMany(h, i+1)
+				if res["status"] != "ok" {
+					results[i] = fmt.Sprint(res["error"])
+					return
+				}
+				rows := res["rows"].([]map[string]any)
+				if len(rows) == 0 {
+					break
+				}
+				for _, r := range rows {
+					ids = append(ids, fmt.Sprint(r["id"]))
+				}
+			}
+			results[i] = strings.Join(ids, ",")
+		}()
+	}
+	wg.Wait()
+
+	var want []string
+	for i := 1; i <= 100; i++ {
+		want = append(want, fmt.Sprint(i))
+	}
+	for i, got := range results {
+		if got != strings.Join(want, ",") {
+			t.Errorf("reader %d (batches of %d) read %s", i, i+1, got)
+		}
+	}
+}
+
+func TestCursorKeepsItsResult(t *testing.T) {
+	seedUsers(t, 2)
+	h := openCursor("SELECT id FROM users ORDER BY id")
+	defer closeCursor(h)
+	fetchOne(h)
+	// Later writes and resets do not change an open cursor's rows.
+	execSQL("INSERT INTO users VALUES (3, 'user3')")
+	TinySQLReset()
+	if got := fetchIDs(t, fetchMany(h, 10)); got != "2" {
+		t.Fatalf("remaining rows = %s, want 2", got)
+	}
+}
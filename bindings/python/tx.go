@@ -0,0 +1,127 @@
+package main
+
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+// Transactions run on a private copy of the database taken at
+// TinySQLBeginTx: nobody else sees their writes before commit, and they do
+// not see what others commit meanwhile. Commit copies the changed tables
+// back unless one of them was changed by someone else since the
+// transaction began (optimistic locking on table versions, as in the SQL
+// driver); then the commit fails and the transaction's writes are lost.
+type pyTx struct {
+	mu     sync.Mutex
+	origin *storage.DB // pyDB at begin; TinySQLReset or TinySQLLoad since then fails the commit
+	base   *storage.DB // table versions at begin, without rows
+	shadow *storage.DB // receives the transaction's writes; nil once ended
+}
+
+var (
+	txs    sync.Map // int -> *pyTx
+	lastTx atomic.Int64
+)
+
+//export TinySQLBeginTx
+func TinySQLBeginTx() int {
+	return beginTx()
+}
+
+//export TinySQLExecTx
+func TinySQLExecTx(txHandle int, sql *C.char) *C.char {
+	return cStringJSON(execTx(txHandle, C.GoString(sql)))
+}
+
+//export TinySQLCommitTx
+func TinySQLCommitTx(txHandle int) *C.char {
+	return cStringJSON(commitTx(txHandle))
+}
+
+//export TinySQLRollbackTx
+func TinySQLRollbackTx(txHandle int) *C.char {
+	return cStringJSON(rollbackTx(txHandle))
+}
+
+func beginTx() int {
+	pyLock.Lock()
+	base, shadow := pyDB.SnapshotForTx()
+	tx := &pyTx{origin: pyDB, base: base, shadow: shadow}
+	pyLock.Unlock()
+
+	handle := int(lastTx.Add(1))
+	txs.Store(handle, tx)
+	return handle
+}
+
+func execTx(handle int, query string) map[string]any {
+	v, ok := txs.Load(handle)
+	if !ok {
+		return errorPayload(errNoTx(handle))
+	}
+	tx := v.(*pyTx)
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	if tx.shadow == nil {
+		// Committed or rolled back between the lookup and the lock.
+		return errorPayload(errNoTx(handle))
+	}
+	return execOn(tx.shadow, query)
+}
+
+func commitTx(handle int) map[string]any {
+	tx, err := endTx(handle)
+	if err != nil {
+		return errorPayload(err)
+	}
+
+	pyLock.Lock()
+	defer pyLock.Unlock()
+	if pyDB != tx.origin {
+		return errorPayload(errors.New("transaction conflict: the database was reset or loaded since the transaction began"))
+	}
+	changes := storage.CollectWALChanges(tx.base, tx.shadow)
+	for _, ch := range changes {
+		baseTable, baseErr := tx.base.Get(ch.Tenant, ch.Name)
+		currentTable, currentErr := pyDB.Get(ch.Tenant, ch.Name)
+		if (baseErr == nil) != (currentErr == nil) || (baseErr == nil && baseTable.Version != currentTable.Version) {
+			return errorPayload(fmt.Errorf("transaction conflict on table %q", ch.Name))
+		}
+	}
+	if err := pyDB.ApplyWALChanges(changes); err != nil {
+		return errorPayload(err)
+	}
+	return map[string]any{"status": "ok"}
+}
+
+func rollbackTx(handle int) map[string]any {
+	if _, err := endTx(handle); err != nil {
+		return errorPayload(err)
+	}
+	return map[string]any{"status": "ok"}
+}
+
+// endTx removes a transaction, waiting for a statement still running in
+// it, and returns its final state. The handle is invalid afterwards.
+func endTx(handle int) (*pyTx, error) {
+	v, ok := txs.LoadAndDelete(handle)
+	if !ok {
+		return nil, errNoTx(handle)
+	}
+	tx := v.(*pyTx)
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	final := &pyTx{origin: tx.origin, base: tx.base, shadow: tx.shadow}
+	tx.base, tx.shadow = nil, nil
+	return final, nil
+}
+
+func errNoTx(handle int) error {
+	return fmt.Errorf("transaction %d does not exist or has already ended", handle)
+}
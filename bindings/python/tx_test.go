@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// txIDs returns the ids in users as seen by transaction h.
+func txIDs(t *testing.T, h int) string {
+	t.Helper()
+	return fetchIDs(t, execTx(h, "SELECT id FROM users ORDER BY id"))
+}
+
+func committedIDs(t *testing.T) string {
+	t.Helper()
+	return fetchIDs(t, execSQL("SELECT id FROM users ORDER BY id"))
+}
+
+func mustExecTx(t *testing.T, h int, sql string) {
+	t.Helper()
+	if res := execTx(h, sql); res["status"] != "ok" {
+		t.Fatalf("%s: %v", sql, res["error"])
+	}
+}
+
+func TestTransactionsSeeOwnSnapshots(t *testing.T) {
+	seedUsers(t, 2)
+	a, b := beginTx(), beginTx()
+	defer rollbackTx(b)
+
+	mustExecTx(t, a, "INSERT INTO users VALUES (3, 'user3')")
+	mustExecTx(t, b, "DELETE FROM users WHERE id = 1")
+	if got := txIDs(t, a); got != "1,2,3" {
+		t.Fatalf("transaction a sees %s, want 1,2,3", got)
+	}
+	if got := txIDs(t, b); got != "2" {
+		t.Fatalf("transaction b sees %s, want 2", got)
+	}
+	if got := committedIDs(t); got != "1,2" {
+		t.Fatalf("outside the transactions: %s, want 1,2", got)
+	}
+
+	if res := commitTx(a); res["status"] != "ok" {
+		t.Fatalf("commit: %v", res["error"])
+	}
+	// b keeps the snapshot it began with.
+	if got := txIDs(t, b); got != "2" {
+		t.Fatalf("transaction b sees %s after a committed, want 2", got)
+	}
+}
+
+func TestTransactionCommitWritesChanges(t *testing.T) {
+	seedUsers(t, 1)
+	h := beginTx()
+	mustExecTx(t, h, "INSERT INTO users VALUES (2, 'user2')")
+	mustExecTx(t, h, "CREATE TABLE notes (id INT)")
+	mustExecTx(t, h, "INSERT INTO notes VALUES (7)")
+	if res := commitTx(h); res["status"] != "ok" {
+		t.Fatalf("commit: %v", res["error"])
+	}
+	if got := committedIDs(t); got != "1,2" {
+		t.Fatalf("after commit: %s, want 1,2", got)
+	}
+	if got := fetchIDs(t, execSQL("SELECT id FROM notes")); got != "7" {
+		t.Fatalf("notes after commit: %s, want 7", got)
+	}
+}
+
+func TestTransactionRollbackDiscardsChanges(t *testing.T) {
+	seedUsers(t, 1)
+	h := beginTx()
+	mustExecTx(t, h, "UPDATE users SET name = 'changed'")
+	mustExecTx(t, h, "CREATE TABLE notes (id INT)")
+	if res := rollbackTx(h); res["status"] != "ok" {
+		t.Fatalf("rollback: %v", res["error"])
+	}
+	res := execSQL("SELECT name FROM users")
+	if rows := res["rows"].([]map[string]any); len(rows) != 1 || rows[0]["name"] != "user1" {
+		t.Fatalf("after rollback: %v, want user1 unchanged", res)
+	}
+	if res := execSQL("SELECT * FROM notes"); res["status"] != "error" {
+		t.Fatalf("table created in a rolled back transaction exists: %v", res)
+	}
+}
+
+func TestTransactionCommitConflicts(t *testing.T) {
+	seedUsers(t, 1)
+	a, b := beginTx(), beginTx()
+	mustExecTx(t, a, "INSERT INTO users VALUES (2, 'user2')")
+	mustExecTx(t, b, "INSERT INTO users VALUES (3, 'user3')")
+	if res := commitTx(a); res["status"] != "ok" {
+		t.Fatalf("first commit: %v", res["error"])
+	}
+	res := commitTx(b)
+	if res["status"] != "error" || !strings.Contains(fmt.Sprint(res["error"]), `conflict on table "users"`) {
+		t.Fatalf("second commit = %v, want a conflict on users", res)
+	}
+	if got := committedIDs(t); got != "1,2" {
+		t.Fatalf("after the conflict: %s, want 1,2", got)
+	}
+
+	// A write outside any transaction conflicts too; other tables do not.
+	c, d := beginTx(), beginTx()
+	mustExecTx(t, c, "DELETE FROM users")
+	mustExecTx(t, d, "CREATE TABLE notes (id INT)")
+	execSQL("INSERT INTO users VALUES (4, 'user4')")
+	if res := commitTx(c); res["status"] != "error" {
+		t.Fatalf("commit after an outside write = %v, want a conflict", res)
+	}
+	if res := commitTx(d); res["status"] != "ok" {
+		t.Fatalf("commit on another table: %v", res["error"])
+	}
+
+	e := beginTx()
+	mustExecTx(t, e, "INSERT INTO users VALUES (5, 'user5')")
+	TinySQLReset()
+	if res := commitTx(e); res["status"] != "error" {
+		t.Fatalf("commit after a reset = %v, want an error", res)
+	}
+}
+
+func TestTransactionUnknownHandle(t *testing.T) {
+	seedUsers(t, 1)
+	h := beginTx()
+	if res := commitTx(h); res["status"] != "ok" {
+		t.Fatalf("commit: %v", res["error"])
+	}
+	for name, res := range map[string]map[string]any{
+		"exec":           execTx(h, "SELECT 1"),
+		"second commit":  commitTx(h),
+		"rollback":       rollbackTx(h),
+		"unknown handle": execTx(-1, "SELECT 1"),
+		"unknown commit": commitTx(12345678),
+	} {
+		if res["status"] != "error" || !strings.Contains(fmt.Sprint(res["error"]), "does not exist or has already ended") {
+			t.Errorf("%s = %v, want a no-such-transaction error", name, res)
+		}
+	}
+}
@@ -43,6 +43,9 @@ func (e *TinySQLExecutor) ExecuteSQL(ctx context.Context, sql string) (interface
 	return rs, nil
 }
 
+// JobTenant keeps the scheduler's _job_history table in the demo tenant.
+func (e *TinySQLExecutor) JobTenant() string { return e.tenant }
+
 func formatFirstRow(rs *tinysql.ResultSet) string {
 	if len(rs.Rows) == 0 {
 		return "(empty)"
@@ -211,9 +214,22 @@ func main() {
 			job.Name, status, lastRun, nextRun)
 	}
 
+	// ── Job history ────────────────────────────────────────────────────────
+	fmt.Println()
+	fmt.Println("10. Last 10 job runs (also queryable as _job_history):")
+	for _, run := range catalog.GetJobHistory("", 10) {
+		line := fmt.Sprintf("   %-25s %-8s %s (%s, rows: %d)",
+			run.JobName, run.Status, run.StartedAt.Format("15:04:05"),
+			run.FinishedAt.Sub(run.StartedAt).Round(time.Millisecond), run.RowsAffected)
+		if run.ErrorMsg != "" {
+			line += " error: " + run.ErrorMsg
+		}
+		fmt.Println(line)
+	}
+
 	// ── Cleanup ────────────────────────────────────────────────────────────
 	fmt.Println()
-	fmt.Println("10. Stopping scheduler...")
+	fmt.Println("11. Stopping scheduler...")
 	tdb.StopJobScheduler()
 
 	fmt.Println()
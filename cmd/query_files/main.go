@@ -6,6 +6,8 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sort"
@@ -63,9 +65,10 @@ var (
 func main() {
 	config, err := parseFlags()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		slog.Error("invalid flags", "error", err)
 		os.Exit(2)
 	}
+	slog.SetDefault(newLogger(os.Stderr, config.Verbose))
 
 	if config.Interactive {
 		runInteractiveMode(config)
@@ -73,17 +76,27 @@ func main() {
 	}
 
 	if len(config.Files) == 0 || strings.TrimSpace(config.Query) == "" {
-		fmt.Fprintf(os.Stderr, "Error: both file and query are required in non-interactive mode\n")
+		slog.Error("both file and query are required in non-interactive mode")
 		flag.Usage()
 		os.Exit(2)
 	}
 
 	if err := executeQuery(config); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		slog.Error("query failed", "error", err)
 		os.Exit(1)
 	}
 }
 
+// newLogger writes text logs to w: progress and timing at debug level with
+// -verbose, otherwise only warnings and errors.
+func newLogger(w io.Writer, verbose bool) *slog.Logger {
+	level := slog.LevelInfo
+	if verbose {
+		level = slog.LevelDebug
+	}
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
 func parseFlags() (Config, error) {
 	config := Config{}
 
@@ -200,9 +213,7 @@ func executeQuery(config Config) error {
 		return err
 	}
 
-	if config.Verbose {
-		fmt.Fprintf(os.Stderr, "Executing query: %s\n", config.Query)
-	}
+	slog.Debug("executing query", "sql", config.Query)
 
 	result, duration, err := runner.executeSQL(config.Query)
 	if err != nil {
@@ -213,9 +224,7 @@ func executeQuery(config Config) error {
 		if err := outputResults(result, config.Output); err != nil {
 			return err
 		}
-		if config.Verbose {
-			fmt.Fprintf(os.Stderr, "\n(%d rows in %v)\n", len(result.Rows), duration)
-		}
+		slog.Debug("query finished", "rows", len(result.Rows), "duration", duration)
 	}
 	return nil
 }
@@ -278,16 +287,12 @@ func (r *Runner) loadInputs(inputs []string, explicitTable string) error {
 	}
 
 	for _, job := range jobs {
-		if r.config.Verbose {
-			fmt.Fprintf(os.Stderr, "Loading %s into table '%s'...\n", job.file, job.tableName)
-		}
+		slog.Debug("loading file", "file", job.file, "table", job.tableName)
 		start := time.Now()
 		if err := r.loadFile(job.file, job.tableName); err != nil {
 			return fmt.Errorf("failed to load %s: %w", job.file, err)
 		}
-		if r.config.Verbose {
-			fmt.Fprintf(os.Stderr, "✓ Loaded %s in %v\n", job.file, time.Since(start))
-		}
+		slog.Debug("loaded file", "file", job.file, "duration", time.Since(start))
 	}
 	return nil
 }
@@ -316,9 +321,7 @@ func (r *Runner) loadJobsParallel(jobs []loadJob) error {
 					errCh <- fmt.Errorf("failed to load %s: %w", job.file, err)
 					continue
 				}
-				if r.config.Verbose {
-					fmt.Fprintf(os.Stderr, "✓ Loaded %s in %v\n", job.file, time.Since(start))
-				}
+				slog.Debug("loaded file", "file", job.file, "table", job.tableName, "duration", time.Since(start))
 			}
 		}()
 	}
@@ -471,19 +474,13 @@ func (r *Runner) loadFile(filename, tableName string) error {
 			return err
 		}
 
-		if r.config.Verbose {
-			if len(result.Errors) > 0 {
-				fmt.Fprintf(os.Stderr, "  Warnings for %s:\n", filename)
-				for i, msg := range result.Errors {
-					if i >= 5 {
-						fmt.Fprintf(os.Stderr, "  ... and %d more warnings\n", len(result.Errors)-5)
-						break
-					}
-					fmt.Fprintf(os.Stderr, "  - %s\n", msg)
-				}
-			}
-			fmt.Fprintf(os.Stderr, "  Imported %d rows, skipped %d rows\n", result.RowsInserted, result.RowsSkipped)
+		if len(result.Errors) > 0 {
+			// Only the first few, so a badly broken file does not
+			// flood the terminal.
+			slog.Warn("import warnings", "file", filename, "count", len(result.Errors),
+				"first", result.Errors[:min(len(result.Errors), 5)])
 		}
+		slog.Debug("imported rows", "file", filename, "rows", result.RowsInserted, "skipped", result.RowsSkipped)
 		return nil
 	}
 
@@ -510,9 +507,7 @@ func (r *Runner) loadFile(filename, tableName string) error {
 	if err != nil {
 		return err
 	}
-	if r.config.Verbose {
-		fmt.Fprintf(os.Stderr, "  Imported %d rows\n", result.RowsInserted)
-	}
+	slog.Debug("imported rows", "file", filename, "rows", result.RowsInserted)
 	return nil
 }
 
@@ -1,11 +1,13 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -23,6 +25,18 @@ func TestBuildQueryFiles(t *testing.T) {
 	_ = os.Remove(out)
 }
 
+func TestNewLoggerVerbose(t *testing.T) {
+	var buf bytes.Buffer
+	newLogger(&buf, false).Debug("loading file", "file", "a.csv")
+	if buf.Len() != 0 {
+		t.Fatalf("progress logged without -verbose: %s", buf.String())
+	}
+	newLogger(&buf, true).Debug("loading file", "file", "a.csv")
+	if !strings.Contains(buf.String(), "level=DEBUG") || !strings.Contains(buf.String(), "file=a.csv") {
+		t.Fatalf("verbose log = %q", buf.String())
+	}
+}
+
 func TestParseDelimiterSpec(t *testing.T) {
 	tests := []struct {
 		in   string
@@ -33,6 +33,8 @@ let wasmApi = {
     getTableSchema: null,
     exportDatabase: null,
     importDatabase: null,
+    saveDB: null,
+    loadDB: null,
 };
 
 // Client-side pending tables (used when WASM not ready)
@@ -56,6 +58,9 @@ let resultViewState = {
 let editorSaveTimer = null;
 let snapshotSaveTimer = null;
 let applyingHashDemo = false;
+// Set by initWasm({persist: true}): snapshots go to IndexedDB via
+// saveDB/loadDB instead of localStorage.
+let persistEnabled = false;
 let lastAppliedHash = '';
 
 function escapeRegex(text) {
@@ -217,6 +222,9 @@ function restoreEditorState() {
 }
 
 function saveDatabaseSnapshotNow() {
+    if (persistEnabled) {
+        return savePersistedDatabase();
+    }
     if (!wasmReady || typeof wasmApi.exportDatabase !== 'function') {
         return false;
     }
@@ -277,6 +285,33 @@ function restoreDatabaseSnapshot() {
     }
 }
 
+function savePersistedDatabase() {
+    if (!wasmReady || typeof wasmApi.saveDB !== 'function') {
+        return false;
+    }
+    wasmApi.saveDB().catch((error) => {
+        console.warn('IndexedDB save failed:', error);
+        updateStatus('Local persistence failed');
+    });
+    return true;
+}
+
+async function restorePersistedDatabase() {
+    if (typeof wasmApi.loadDB !== 'function') {
+        return false;
+    }
+    try {
+        const result = await wasmApi.loadDB();
+        if (result && result.loaded) {
+            updateStatus('Restored database from IndexedDB');
+            return true;
+        }
+    } catch (error) {
+        updateStatus(`Saved database could not be restored: ${error.message}`);
+    }
+    return false;
+}
+
 function sqlMayMutate(sql) {
     const stripped = String(sql || '')
         .replace(/--[^\n]*/g, ' ')
@@ -290,8 +325,10 @@ function sqlMayMutate(sql) {
     });
 }
 
-// Initialize WASM
-async function initWasm() {
+// Initialize WASM. With {persist: true} the database is restored from and
+// saved to IndexedDB.
+async function initWasm(options = {}) {
+    persistEnabled = Boolean(options.persist) && typeof indexedDB !== 'undefined';
     const go = new Go();
     
     try {
@@ -312,6 +349,8 @@ async function initWasm() {
         wasmApi.getTableSchema = window.getTableSchema;
         wasmApi.exportDatabase = window.exportDatabase;
         wasmApi.importDatabase = window.importDatabase;
+        wasmApi.saveDB = window.saveDB;
+        wasmApi.loadDB = window.loadDB;
 
         console.log("Available WASM functions:", Object.fromEntries(
             Object.entries(wasmApi).map(([k,v]) => [k, typeof v])
@@ -322,7 +361,11 @@ async function initWasm() {
         document.getElementById('executeBtn').disabled = false;
         const hashDemoPayload = decodeDemoHash();
         if (!hashDemoPayload) {
-            restoreDatabaseSnapshot();
+            // With persistence on, an older localStorage snapshot is still
+            // picked up until the first IndexedDB save.
+            if (!persistEnabled || !(await restorePersistedDatabase())) {
+                restoreDatabaseSnapshot();
+            }
         }
         // If any tables were registered client-side before WASM was ready,
         // import them now into the WASM-backed database so queries will work.
@@ -1183,7 +1226,7 @@ document.addEventListener('DOMContentLoaded', () => {
     setupAccessibilityShortcuts();
     setupSqlAutocomplete();
     renderIntroPage();
-    initWasm();
+    initWasm({ persist: new URLSearchParams(window.location.search).has('persist') });
     
     // Setup demo buttons
     const loadAllDemosBtn = document.getElementById('loadAllDemosBtn');
@@ -2189,6 +2232,9 @@ function clearAllTables() {
     }
     window.clearVanillaGrid?.();
     storageRemove(DB_SNAPSHOT_KEY);
+    if (persistEnabled) {
+        savePersistedDatabase();
+    }
     renderIntroPage();
     updateStatus('Database cleared');
 }
@@ -2247,7 +2293,10 @@ async function onExecuteClick() {
         const startTime = performance.now();
         // Use executeMulti if available and query contains semicolons
         const hasMulti = query.includes(';') && typeof wasmApi.executeMulti === 'function';
-        const result = hasMulti ? wasmApi.executeMulti(query) : wasmApi.executeQuery(query);
+        // executeQuery returns a Promise and rejects on SQL errors.
+        const result = hasMulti
+            ? wasmApi.executeMulti(query)
+            : await wasmApi.executeQuery(query).catch((error) => ({ success: false, error: error.message }));
         const wallMs = performance.now() - startTime;
         const duration = result?.durationMs != null
             ? result.durationMs.toFixed(2) + ' ms'
@@ -0,0 +1,102 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"syscall/js"
+	"time"
+)
+
+// queryMu runs asynchronous queries one at a time. Each waits for a turn of
+// the JavaScript event loop before it starts, so timers and input handlers
+// run between queued queries instead of after all of them.
+var queryMu sync.Mutex
+
+// executeQuery executes a single SQL query on the default database. It
+// returns a Promise that resolves to the result payload and rejects with an
+// Error for invalid or failing SQL. executeQueryAsync is the same function.
+func executeQuery(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return rejectedPromise("Usage: executeQuery(sqlQuery)")
+	}
+	return queryPromise(defaultDBName, args[0].String())
+}
+
+// executeQueryOnDB is executeQuery on the database called name.
+func executeQueryOnDB(this js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return rejectedPromise("Usage: executeQueryOnDB(dbName, sqlQuery)")
+	}
+	return queryPromise(strings.TrimSpace(args[0].String()), args[1].String())
+}
+
+func queryPromise(dbName, sqlText string) js.Value {
+	return newPromise(func() (interface{}, error) {
+		queryStr, err := normalizeSQLInput(sqlText)
+		if err != nil {
+			return nil, err
+		}
+
+		queryMu.Lock()
+		defer queryMu.Unlock()
+		yieldToEventLoop()
+
+		// Looked up only now: the database may have been dropped while the
+		// query waited.
+		target, err := lookupDB(dbName)
+		if err != nil {
+			return nil, err
+		}
+		start := time.Now()
+		result, err := executeSQLText(target, queryStr)
+		if err != nil {
+			return nil, err
+		}
+		lastQueryDurMs = float64(time.Since(start).Microseconds()) / 1000.0
+		lastResult = result
+		return successResultPayload(result, 0), nil
+	})
+}
+
+// newPromise returns a JavaScript Promise settled by fn, which runs on its
+// own goroutine so it may wait for other JavaScript callbacks.
+func newPromise(fn func() (interface{}, error)) js.Value {
+	var executor js.Func
+	executor = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		resolve, reject := args[0], args[1]
+		go func() {
+			defer executor.Release()
+			v, err := fn()
+			if err != nil {
+				reject.Invoke(js.Global().Get("Error").New(err.Error()))
+				return
+			}
+			resolve.Invoke(v)
+		}()
+		return nil
+	})
+	return js.Global().Get("Promise").New(executor)
+}
+
+func rejectedPromise(msg string) js.Value {
+	return newPromise(func() (interface{}, error) { return nil, errors.New(msg) })
+}
+
+// yieldToEventLoop blocks until JavaScript has had a turn of its event
+// loop. Go on js/wasm runs every ready goroutine before it hands control
+// back to JavaScript, so work started from a callback would otherwise run
+// before the caller even receives its Promise.
+func yieldToEventLoop() {
+	done := make(chan struct{})
+	var resume js.Func
+	resume = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		resume.Release()
+		close(done)
+		return nil
+	})
+	js.Global().Call("setTimeout", resume, 0)
+	<-done
+}
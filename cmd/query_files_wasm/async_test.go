@@ -0,0 +1,105 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"syscall/js"
+	"testing"
+)
+
+func query(sql string) js.Value {
+	return executeQuery(js.Undefined(), []js.Value{js.ValueOf(sql)}).(js.Value)
+}
+
+func TestExecuteQueryResolves(t *testing.T) {
+	resetPage()
+	execDefault("CREATE TABLE t (id INT, name TEXT)")
+	execDefault("INSERT INTO t VALUES (1, 'a'), (2, 'b')")
+	p := query("SELECT name FROM t ORDER BY id")
+	if p.Type() != js.TypeObject || p.Get("then").Type() != js.TypeFunction {
+		t.Fatalf("executeQuery returned %v, want a Promise", p)
+	}
+	v, err := await(p)
+	if err != "" {
+		t.Fatalf("rejected: %s", err)
+	}
+	rows := v.Get("rows")
+	if !v.Get("success").Bool() || rows.Length() != 2 || rows.Index(1).Get("name").String() != "b" {
+		t.Fatalf("result = %s", js.Global().Get("JSON").Call("stringify", v))
+	}
+}
+
+func TestExecuteQueryRejectsBadSQL(t *testing.T) {
+	resetPage()
+	for _, sql := range []string{"SELEKT 1", "SELECT * FROM missing", "  "} {
+		if _, err := await(query(sql)); err == "" {
+			t.Errorf("%q resolved, want a rejection", sql)
+		}
+	}
+	if _, err := await(executeQuery(js.Undefined(), nil).(js.Value)); !strings.Contains(err, "Usage") {
+		t.Errorf("no arguments rejected with %q, want usage", err)
+	}
+}
+
+func TestExecuteQueryConcurrent(t *testing.T) {
+	resetPage()
+	execDefault("CREATE TABLE hits (n INT)")
+	const n = 50
+	promises := make([]js.Value, 0, 2*n)
+	for i := 0; i < n; i++ {
+		promises = append(promises, query(fmt.Sprintf("INSERT INTO hits VALUES (%d)", i)))
+		promises = append(promises, query("SELECT COUNT(*) AS c FROM hits"))
+	}
+	// Queries run in call order, so each count sees exactly the inserts
+	// issued before it.
+	for i, p := range promises {
+		v, err := await(p)
+		if err != "" {
+			t.Fatalf("query %d rejected: %s", i, err)
+		}
+		if i%2 == 1 {
+			if got := v.Get("rows").Index(0).Get("c").Int(); got != i/2+1 {
+				t.Fatalf("count after %d inserts = %d", i/2+1, got)
+			}
+		}
+	}
+}
+
+func TestExecuteQueryKeepsEventLoopResponsive(t *testing.T) {
+	resetPage()
+	execDefault("CREATE TABLE nums (n INT)")
+	for i := 0; i < 200; i++ {
+		execDefault(fmt.Sprintf("INSERT INTO nums VALUES (%d)", i))
+	}
+	// A setInterval counter runs while three slow queries are queued; each
+	// result records the counter when it resolves.
+	run := js.Global().Call("eval", `(async (query) => {
+		let ticks = 0;
+		const timer = setInterval(() => { ticks++; }, 0);
+		const sql = 'SELECT COUNT(*) AS c FROM nums a CROSS JOIN nums b WHERE a.n < b.n';
+		const pending = [query(sql), query(sql), query(sql)];
+		const atCall = ticks;
+		const seen = await Promise.all(pending.map((p) => p.then(() => ticks)));
+		clearInterval(timer);
+		return [atCall, ...seen].join(',');
+	})`)
+	queryFn := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		return query(args[0].String())
+	})
+	defer queryFn.Release()
+	v, err := await(run.Invoke(queryFn))
+	if err != "" {
+		t.Fatal(err)
+	}
+	var atCall, first, second, third int
+	if _, err := fmt.Sscanf(v.String(), "%d,%d,%d,%d", &atCall, &first, &second, &third); err != nil {
+		t.Fatal(err)
+	}
+	// The call returns before any query runs, and the timer fires before
+	// each query starts.
+	if atCall != 0 || !(first > 0 && second > first && third > second) {
+		t.Fatalf("ticks at call, then at each result = %s; want the counter to advance between queries", v)
+	}
+}
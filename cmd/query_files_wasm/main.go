@@ -27,10 +27,14 @@ const (
 	defaultImportTimeout = 60 * time.Second
 	maxSQLBytes          = 256 * 1024
 	queryCacheSize       = 256
+	defaultDBName        = ""
 )
 
 var (
-	db         *tinysql.DB
+	// dbs holds the open databases by name. The unnamed default database
+	// (defaultDBName) always exists and is the one every function without a
+	// database name works on.
+	dbs        = map[string]*tinysql.DB{defaultDBName: tinysql.NewDB()}
 	tenant     = defaultTenant
 	queryCache *tinysql.QueryCache
 
@@ -42,12 +46,12 @@ var (
 func main() {
 	c := make(chan struct{})
 
-	db = tinysql.NewDB()
 	queryCache = tinysql.NewQueryCache(queryCacheSize)
 	registerDemoStoredProcedures()
 
 	js.Global().Set("importFile", js.FuncOf(importFile))
 	js.Global().Set("executeQuery", js.FuncOf(executeQuery))
+	js.Global().Set("executeQueryAsync", js.FuncOf(executeQuery))
 	js.Global().Set("executeMulti", js.FuncOf(executeMulti))
 	js.Global().Set("clearDatabase", js.FuncOf(clearDatabase))
 	js.Global().Set("dropTable", js.FuncOf(dropTable))
@@ -56,6 +60,11 @@ func main() {
 	js.Global().Set("getTableSchema", js.FuncOf(getTableSchema))
 	js.Global().Set("exportDatabase", js.FuncOf(exportDatabase))
 	js.Global().Set("importDatabase", js.FuncOf(importDatabase))
+	js.Global().Set("createDB", js.FuncOf(createDB))
+	js.Global().Set("dropDB", js.FuncOf(dropDB))
+	js.Global().Set("executeQueryOnDB", js.FuncOf(executeQueryOnDB))
+	js.Global().Set("saveDB", js.FuncOf(saveDB))
+	js.Global().Set("loadDB", js.FuncOf(loadDB))
 
 	println("TinySQL Query Files WASM initialized!")
 	<-c
@@ -98,7 +107,25 @@ func normalizeSQLInput(raw string) (string, error) {
 	return raw, nil
 }
 
-func executeSQLText(sqlText string) (*tinysql.ResultSet, error) {
+// lookupDB returns the database called name; "" is the default database.
+func lookupDB(name string) (*tinysql.DB, error) {
+	target, ok := dbs[name]
+	if !ok {
+		return nil, fmt.Errorf("database %q does not exist", name)
+	}
+	return target, nil
+}
+
+// optionalDBName returns the database name passed as args[i], or the
+// default database's when the argument is missing.
+func optionalDBName(args []js.Value, i int) string {
+	if len(args) <= i || args[i].IsUndefined() || args[i].IsNull() {
+		return defaultDBName
+	}
+	return strings.TrimSpace(args[i].String())
+}
+
+func executeSQLText(target *tinysql.DB, sqlText string) (*tinysql.ResultSet, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), defaultQueryTimeout)
 	defer cancel()
 
@@ -107,7 +134,7 @@ func executeSQLText(sqlText string) (*tinysql.ResultSet, error) {
 		return nil, fmt.Errorf("Parse error: %w", err)
 	}
 
-	result, err := tinysql.ExecuteCompiled(ctx, db, tenant, compiled)
+	result, err := tinysql.ExecuteCompiled(ctx, target, tenant, compiled)
 	if err != nil {
 		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
 			return nil, fmt.Errorf("Query timeout after %s", defaultQueryTimeout)
@@ -165,10 +192,15 @@ func successResultPayload(result *tinysql.ResultSet, statementsRun int) map[stri
 	return payload
 }
 
-// importFile imports a file (CSV, JSON, XML) into the database.
+// importFile imports a file (CSV, JSON, XML) into the database named by the
+// optional fourth argument, or the default database.
 func importFile(this js.Value, args []js.Value) interface{} {
 	if len(args) < 3 {
-		return jsErr("Usage: importFile(fileName, fileContent, tableName)")
+		return jsErr("Usage: importFile(fileName, fileContent, tableName[, dbName])")
+	}
+	db, err := lookupDB(optionalDBName(args, 3))
+	if err != nil {
+		return jsErr(err.Error())
 	}
 
 	fileName := strings.TrimSpace(args[0].String())
@@ -208,7 +240,6 @@ func importFile(this js.Value, args []js.Value) interface{} {
 
 	reader := strings.NewReader(fileContent)
 	var impResult *tinysql.ImportResult
-	var err error
 
 	switch {
 	case ext == ".csv" || ext == ".tsv" || ext == ".txt":
@@ -288,28 +319,6 @@ func importFile(this js.Value, args []js.Value) interface{} {
 	}
 }
 
-// executeQuery executes a single SQL query and returns results.
-func executeQuery(this js.Value, args []js.Value) interface{} {
-	if len(args) < 1 {
-		return jsErr("Usage: executeQuery(sqlQuery)")
-	}
-
-	queryStr, err := normalizeSQLInput(args[0].String())
-	if err != nil {
-		return jsErr(err.Error())
-	}
-
-	start := time.Now()
-	result, err := executeSQLText(queryStr)
-	if err != nil {
-		return jsErr(err.Error())
-	}
-	lastQueryDurMs = float64(time.Since(start).Microseconds()) / 1000.0
-	lastResult = result
-
-	return successResultPayload(result, 0)
-}
-
 // executeMulti runs multiple semicolon-separated SQL statements and returns
 // the result of the last SELECT (or an aggregate summary).
 func executeMulti(this js.Value, args []js.Value) interface{} {
@@ -334,7 +343,7 @@ func executeMulti(this js.Value, args []js.Value) interface{} {
 		if err != nil {
 			return jsErr(fmt.Sprintf("Statement %d: %v", i+1, err))
 		}
-		rs, err := executeSQLText(stmtSQL)
+		rs, err := executeSQLText(dbs[defaultDBName], stmtSQL)
 		if err != nil {
 			return jsErr(fmt.Sprintf("Statement %d: %v", i+1, err))
 		}
@@ -348,9 +357,14 @@ func executeMulti(this js.Value, args []js.Value) interface{} {
 	return successResultPayload(lastRS, len(stmts))
 }
 
-// clearDatabase clears all tables from the database.
+// clearDatabase clears all tables from the database named by the optional
+// argument, or the default database.
 func clearDatabase(this js.Value, args []js.Value) interface{} {
-	db = tinysql.NewDB()
+	name := optionalDBName(args, 0)
+	if _, err := lookupDB(name); err != nil {
+		return jsErr(err.Error())
+	}
+	dbs[name] = tinysql.NewDB()
 	queryCache = tinysql.NewQueryCache(queryCacheSize)
 	lastResult = nil
 	return map[string]interface{}{
@@ -359,9 +373,53 @@ func clearDatabase(this js.Value, args []js.Value) interface{} {
 	}
 }
 
+// createDB creates an empty database called name. Databases are isolated
+// from each other: a query only sees the tables of the database it runs on.
+func createDB(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return jsErr("Usage: createDB(name)")
+	}
+	name := strings.TrimSpace(args[0].String())
+	if name == "" {
+		return jsErr("database name must not be empty")
+	}
+	if _, ok := dbs[name]; ok {
+		return jsErr(fmt.Sprintf("database %q already exists", name))
+	}
+	dbs[name] = tinysql.NewDB()
+	return map[string]interface{}{
+		"success": true,
+		"message": "Database created",
+		"name":    name,
+	}
+}
+
+// dropDB removes the database called name. The default database cannot be
+// dropped.
+func dropDB(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return jsErr("Usage: dropDB(name)")
+	}
+	name := strings.TrimSpace(args[0].String())
+	if name == "" {
+		return jsErr("the default database cannot be dropped")
+	}
+	target, err := lookupDB(name)
+	if err != nil {
+		return jsErr(err.Error())
+	}
+	delete(dbs, name)
+	_ = target.Close()
+	return map[string]interface{}{
+		"success": true,
+		"message": "Database dropped",
+		"name":    name,
+	}
+}
+
 // exportDatabase serializes the current database as a base64 GOB snapshot.
 func exportDatabase(this js.Value, args []js.Value) interface{} {
-	data, err := tinysql.SaveToBytes(db)
+	data, err := tinysql.SaveToBytes(dbs[defaultDBName])
 	if err != nil {
 		return jsErr("export failed: " + err.Error())
 	}
@@ -390,7 +448,7 @@ func importDatabase(this js.Value, args []js.Value) interface{} {
 	if err != nil {
 		return jsErr("import failed: " + err.Error())
 	}
-	db = loaded
+	dbs[defaultDBName] = loaded
 	queryCache = tinysql.NewQueryCache(queryCacheSize)
 	lastResult = nil
 	return map[string]interface{}{
@@ -413,7 +471,7 @@ func dropTable(this js.Value, args []js.Value) interface{} {
 	if strings.HasPrefix(lower, "sys.") || strings.HasPrefix(lower, "catalog.") {
 		return jsErr("virtual tables cannot be dropped")
 	}
-	if err := db.Drop(tenant, name); err != nil {
+	if err := dbs[defaultDBName].Drop(tenant, name); err != nil {
 		return jsErr("drop failed: " + err.Error())
 	}
 	lastResult = nil
@@ -427,7 +485,7 @@ func dropTable(this js.Value, args []js.Value) interface{} {
 // listTables returns the names and row counts of all loaded tables,
 // plus virtual sys.* and catalog.* tables.
 func listTables(this js.Value, args []js.Value) interface{} {
-	tables := db.ListTables(tenant)
+	tables := dbs[defaultDBName].ListTables(tenant)
 	sort.Slice(tables, func(i, j int) bool {
 		return strings.ToLower(tables[i].Name) < strings.ToLower(tables[j].Name)
 	})
@@ -485,7 +543,7 @@ func getTableSchema(this js.Value, args []js.Value) interface{} {
 	lower := strings.ToLower(name)
 	if strings.HasPrefix(lower, "sys.") || strings.HasPrefix(lower, "catalog.") {
 		q := fmt.Sprintf("SELECT * FROM %s LIMIT 1", name)
-		rs, err := executeSQLText(q)
+		rs, err := executeSQLText(dbs[defaultDBName], q)
 		if err != nil {
 			return jsErr(err.Error())
 		}
@@ -505,7 +563,7 @@ func getTableSchema(this js.Value, args []js.Value) interface{} {
 		}
 	}
 
-	tbl, err := db.Get(tenant, name)
+	tbl, err := dbs[defaultDBName].Get(tenant, name)
 	if err != nil {
 		return jsErr("Table not found: " + name)
 	}
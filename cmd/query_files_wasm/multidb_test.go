@@ -0,0 +1,130 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"strings"
+	"syscall/js"
+	"testing"
+)
+
+func call(fn func(js.Value, []js.Value) interface{}, args ...interface{}) map[string]interface{} {
+	vals := make([]js.Value, len(args))
+	for i, a := range args {
+		vals[i] = js.ValueOf(a)
+	}
+	return fn(js.Undefined(), vals).(map[string]interface{})
+}
+
+func queryOn(name, sql string) (js.Value, string) {
+	return await(executeQueryOnDB(js.Undefined(), []js.Value{js.ValueOf(name), js.ValueOf(sql)}).(js.Value))
+}
+
+func TestMultipleDatabasesAreIsolated(t *testing.T) {
+	resetPage()
+	for _, name := range []string{"sales", "hr"} {
+		if res := call(createDB, name); res["success"] != true {
+			t.Fatalf("createDB(%s) = %v", name, res)
+		}
+	}
+	if res := call(importFile, "orders.csv", "id,total\n1,9.5\n2,20\n", "orders", "sales"); res["success"] != true {
+		t.Fatalf("import into sales: %v", res)
+	}
+	if res := call(importFile, "people.csv", "id,name\n1,Ada\n", "people", "hr"); res["success"] != true {
+		t.Fatalf("import into hr: %v", res)
+	}
+
+	v, err := queryOn("sales", "SELECT COUNT(*) AS c FROM orders")
+	if err != "" || v.Get("rows").Index(0).Get("c").Int() != 2 {
+		t.Fatalf("sales query = %v, %s", v, err)
+	}
+	if _, err := queryOn("hr", "SELECT name FROM people"); err != "" {
+		t.Fatalf("hr query: %s", err)
+	}
+	// Neither database, nor the default one, sees the other's tables.
+	for _, q := range []struct{ db, sql string }{
+		{"sales", "SELECT * FROM people"},
+		{"hr", "SELECT * FROM orders"},
+		{"hr", "SELECT * FROM sales.orders"},
+		{"", "SELECT * FROM orders"},
+	} {
+		if _, err := queryOn(q.db, q.sql); err == "" {
+			t.Errorf("%q on %q succeeded, want the table to be missing", q.sql, q.db)
+		}
+	}
+
+	if res := call(clearDatabase, "sales"); res["success"] != true {
+		t.Fatalf("clearDatabase(sales) = %v", res)
+	}
+	if _, err := queryOn("sales", "SELECT * FROM orders"); err == "" {
+		t.Error("orders survived clearing sales")
+	}
+	if _, err := queryOn("hr", "SELECT name FROM people"); err != "" {
+		t.Errorf("clearing sales cleared hr: %s", err)
+	}
+}
+
+func TestDropDB(t *testing.T) {
+	resetPage()
+	call(createDB, "scratch")
+	if res := call(dropDB, "scratch"); res["success"] != true {
+		t.Fatalf("dropDB = %v", res)
+	}
+	if _, err := queryOn("scratch", "SELECT 1"); !strings.Contains(err, "does not exist") {
+		t.Errorf("query on dropped database rejected with %q", err)
+	}
+	if res := call(dropDB, "scratch"); res["success"] != false {
+		t.Errorf("dropping twice = %v, want an error", res)
+	}
+	if res := call(dropDB, ""); res["success"] != false {
+		t.Errorf("dropping the default database = %v, want an error", res)
+	}
+	if res := call(importFile, "a.csv", "id\n1\n", "a", "scratch"); res["success"] != false {
+		t.Errorf("import into dropped database = %v, want an error", res)
+	}
+	// The name can be used again.
+	if res := call(createDB, "scratch"); res["success"] != true {
+		t.Errorf("recreate = %v", res)
+	}
+}
+
+func TestCreateDBRejectsDuplicateAndEmptyNames(t *testing.T) {
+	resetPage()
+	call(createDB, "main")
+	call(importFile, "a.csv", "id\n1\n", "a", "main")
+	if res := call(createDB, "main"); res["success"] != false || !strings.Contains(res["error"].(string), "already exists") {
+		t.Errorf("duplicate createDB = %v", res)
+	}
+	if _, err := queryOn("main", "SELECT * FROM a"); err != "" {
+		t.Errorf("duplicate createDB replaced the database: %s", err)
+	}
+	if res := call(createDB, "  "); res["success"] != false {
+		t.Errorf("empty name = %v, want an error", res)
+	}
+}
+
+func TestDefaultDatabaseUnchanged(t *testing.T) {
+	resetPage()
+	call(createDB, "other")
+	// The three-argument importFile and executeQuery use the default
+	// database, which executeQueryOnDB reaches as "".
+	if res := call(importFile, "t.csv", "id\n7\n", "t"); res["success"] != true {
+		t.Fatalf("import = %v", res)
+	}
+	v, err := await(query("SELECT id FROM t"))
+	if err != "" || v.Get("rows").Index(0).Get("id").Int() != 7 {
+		t.Fatalf("executeQuery = %v, %s", v, err)
+	}
+	if _, err := queryOn("", "SELECT id FROM t"); err != "" {
+		t.Errorf(`executeQueryOnDB("") = %s`, err)
+	}
+	if res := call(clearDatabase); res["success"] != true {
+		t.Fatalf("clearDatabase() = %v", res)
+	}
+	if _, err := await(query("SELECT id FROM t")); err == "" {
+		t.Error("clearDatabase() kept the default database's tables")
+	}
+	if _, ok := dbs["other"]; !ok {
+		t.Error("clearDatabase() dropped a named database")
+	}
+}
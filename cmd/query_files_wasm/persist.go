@@ -0,0 +1,141 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"errors"
+	"syscall/js"
+	"time"
+
+	tinysql "github.com/SimonWaldherr/tinySQL"
+)
+
+// IndexedDB persistence. saveDB and loadDB keep the same GOB snapshot of the
+// default database that exportDatabase produces in the browser's IndexedDB, which, unlike the
+// localStorage snapshot, is not limited to a few megabytes. Both return a
+// Promise, since IndexedDB only answers asynchronously.
+const (
+	idbName    = "tinysql_query_files"
+	idbVersion = 1
+	idbStore   = "snapshots"
+	idbKey     = "database"
+)
+
+// saveDB stores the current database in IndexedDB. The Promise resolves to
+// {success, sizeBytes, savedAt}.
+func saveDB(this js.Value, args []js.Value) interface{} {
+	return newPromise(func() (interface{}, error) {
+		data, err := tinysql.SaveToBytes(dbs[defaultDBName])
+		if err != nil {
+			return nil, errors.New("save failed: " + err.Error())
+		}
+		buf := js.Global().Get("Uint8Array").New(len(data))
+		js.CopyBytesToJS(buf, data)
+
+		idb, err := openIDB()
+		if err != nil {
+			return nil, err
+		}
+		defer idb.Call("close")
+		tx := idb.Call("transaction", idbStore, "readwrite")
+		tx.Call("objectStore", idbStore).Call("put", buf, idbKey)
+		// The snapshot is durable once the transaction completes, not when
+		// the put request succeeds.
+		if err := awaitEvent(tx, "oncomplete", "onerror", "onabort"); err != nil {
+			return nil, errors.New("save failed: " + err.Error())
+		}
+		return map[string]interface{}{
+			"success":   true,
+			"sizeBytes": len(data),
+			"savedAt":   time.Now().UTC().Format(time.RFC3339),
+		}, nil
+	})
+}
+
+// loadDB replaces the current database with the one saved in IndexedDB.
+// The Promise resolves to {success, loaded}; loaded is false and the
+// database untouched when nothing was saved.
+func loadDB(this js.Value, args []js.Value) interface{} {
+	return newPromise(func() (interface{}, error) {
+		idb, err := openIDB()
+		if err != nil {
+			return nil, err
+		}
+		defer idb.Call("close")
+		req := idb.Call("transaction", idbStore, "readonly").Call("objectStore", idbStore).Call("get", idbKey)
+		if err := awaitEvent(req, "onsuccess", "onerror"); err != nil {
+			return nil, errors.New("load failed: " + err.Error())
+		}
+		saved := req.Get("result")
+		if saved.IsUndefined() || saved.IsNull() {
+			return map[string]interface{}{"success": true, "loaded": false}, nil
+		}
+		data := make([]byte, saved.Get("length").Int())
+		js.CopyBytesToGo(data, saved)
+		loaded, err := tinysql.LoadFromBytes(data)
+		if err != nil {
+			return nil, errors.New("load failed: " + err.Error())
+		}
+		dbs[defaultDBName] = loaded
+		queryCache = tinysql.NewQueryCache(queryCacheSize)
+		lastResult = nil
+		return map[string]interface{}{
+			"success":   true,
+			"loaded":    true,
+			"sizeBytes": len(data),
+		}, nil
+	})
+}
+
+// openIDB opens the snapshot database, creating its object store on first
+// use.
+func openIDB() (js.Value, error) {
+	factory := js.Global().Get("indexedDB")
+	if factory.IsUndefined() || factory.IsNull() {
+		return js.Value{}, errors.New("IndexedDB is not available")
+	}
+	req := factory.Call("open", idbName, idbVersion)
+	upgrade := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		idb := req.Get("result")
+		if !idb.Get("objectStoreNames").Call("contains", idbStore).Bool() {
+			idb.Call("createObjectStore", idbStore)
+		}
+		return nil
+	})
+	defer upgrade.Release()
+	req.Set("onupgradeneeded", upgrade)
+	if err := awaitEvent(req, "onsuccess", "onerror"); err != nil {
+		return js.Value{}, errors.New("open IndexedDB: " + err.Error())
+	}
+	return req.Get("result"), nil
+}
+
+// awaitEvent blocks until target fires its ok event or one of the failure
+// events and returns target's error for the latter. It must not run on the
+// JavaScript event loop's goroutine, which has to keep running to deliver
+// the event.
+func awaitEvent(target js.Value, ok string, failures ...string) error {
+	done := make(chan error, 1)
+	onOK := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		done <- nil
+		return nil
+	})
+	defer onOK.Release()
+	onFail := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		msg := "unknown IndexedDB error"
+		if e := target.Get("error"); !e.IsUndefined() && !e.IsNull() {
+			msg = e.Get("message").String()
+		}
+		select {
+		case done <- errors.New(msg):
+		default:
+		}
+		return nil
+	})
+	defer onFail.Release()
+	target.Set(ok, onOK)
+	for _, ev := range failures {
+		target.Set(ev, onFail)
+	}
+	return <-done
+}
@@ -0,0 +1,159 @@
+//go:build js && wasm
+
+// Run with Node.js:
+//
+//	GOOS=js GOARCH=wasm go test -exec="$(go env GOROOT)/lib/wasm/go_js_wasm_exec" .
+
+package main
+
+import (
+	"strings"
+	"syscall/js"
+	"testing"
+
+	tinysql "github.com/SimonWaldherr/tinySQL"
+)
+
+// fakeIndexedDB is an in-memory stand-in for the parts of IndexedDB that
+// persist.go uses; Node.js has no IndexedDB. Like the real one it answers
+// every request on a later turn of the event loop.
+const fakeIndexedDB = `(() => {
+	const dbs = new Map();
+	const later = (fn) => setTimeout(fn, 0);
+	const request = (fn) => {
+		const req = {};
+		later(() => { req.result = fn(); req.onsuccess && req.onsuccess(); });
+		return req;
+	};
+	return {
+		open(name) {
+			const req = {};
+			later(() => {
+				const fresh = !dbs.has(name);
+				if (fresh) dbs.set(name, new Map());
+				const stores = dbs.get(name);
+				req.result = {
+					objectStoreNames: { contains: (s) => stores.has(s) },
+					createObjectStore: (s) => stores.set(s, new Map()),
+					close() {},
+					transaction(s) {
+						const tx = {
+							objectStore: () => ({
+								put: (v, k) => request(() => { stores.get(s).set(k, v.slice()); }),
+								get: (k) => request(() => stores.get(s).get(k)),
+							}),
+						};
+						later(() => later(() => tx.oncomplete && tx.oncomplete()));
+						return tx;
+					},
+				};
+				if (fresh) req.onupgradeneeded && req.onupgradeneeded();
+				req.onsuccess();
+			});
+			return req;
+		},
+		deleteDatabase: (name) => request(() => { dbs.delete(name); }),
+	};
+})()`
+
+func installFakeIndexedDB(t *testing.T) {
+	t.Helper()
+	js.Global().Set("indexedDB", js.Global().Call("eval", fakeIndexedDB))
+	t.Cleanup(func() { js.Global().Delete("indexedDB") })
+}
+
+// await waits for promise and returns its value, or the rejection message.
+func await(promise js.Value) (js.Value, string) {
+	type settled struct {
+		v   js.Value
+		err string
+	}
+	ch := make(chan settled, 1)
+	onResolve := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		ch <- settled{v: args[0]}
+		return nil
+	})
+	defer onResolve.Release()
+	onReject := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		ch <- settled{err: args[0].Get("message").String()}
+		return nil
+	})
+	defer onReject.Release()
+	promise.Call("then", onResolve, onReject)
+	s := <-ch
+	return s.v, s.err
+}
+
+// resetPage simulates a page reload: a fresh, empty in-memory database.
+func resetPage() {
+	dbs = map[string]*tinysql.DB{defaultDBName: tinysql.NewDB()}
+	queryCache = tinysql.NewQueryCache(queryCacheSize)
+	lastResult = nil
+}
+
+func execDefault(sql string) (*tinysql.ResultSet, error) {
+	return executeSQLText(dbs[defaultDBName], sql)
+}
+
+func TestSaveAndLoadDBSurvivesReload(t *testing.T) {
+	installFakeIndexedDB(t)
+	resetPage()
+	res := importFile(js.Undefined(), []js.Value{js.ValueOf("people.csv"), js.ValueOf("id,name\n1,Ada\n2,Grace\n"), js.ValueOf("people")})
+	if m := res.(map[string]interface{}); m["success"] != true {
+		t.Fatalf("import: %v", m)
+	}
+	if v, err := await(saveDB(js.Undefined(), nil).(js.Value)); err != "" || !v.Get("success").Bool() {
+		t.Fatalf("saveDB: %s", err)
+	}
+
+	resetPage()
+	v, err := await(loadDB(js.Undefined(), nil).(js.Value))
+	if err != "" || !v.Get("loaded").Bool() {
+		t.Fatalf("loadDB = %v, %s; want the saved database loaded", v, err)
+	}
+	rs, qerr := execDefault("SELECT name FROM people ORDER BY id")
+	if qerr != nil {
+		t.Fatalf("query after reload: %v", qerr)
+	}
+	if len(rs.Rows) != 2 {
+		t.Fatalf("rows after reload = %d, want 2", len(rs.Rows))
+	}
+}
+
+func TestLoadDBAfterClearingIndexedDBStartsFresh(t *testing.T) {
+	installFakeIndexedDB(t)
+	resetPage()
+	execDefault("CREATE TABLE notes (id INT)")
+	if _, err := await(saveDB(js.Undefined(), nil).(js.Value)); err != "" {
+		t.Fatalf("saveDB: %s", err)
+	}
+
+	if _, err := await(promiseOf(js.Global().Get("indexedDB").Call("deleteDatabase", idbName))); err != "" {
+		t.Fatalf("deleteDatabase: %s", err)
+	}
+	resetPage()
+	v, err := await(loadDB(js.Undefined(), nil).(js.Value))
+	if err != "" || v.Get("loaded").Bool() {
+		t.Fatalf("loadDB = %v, %s; want nothing loaded", v, err)
+	}
+	if _, qerr := execDefault("SELECT * FROM notes"); qerr == nil {
+		t.Fatal("table notes still exists after IndexedDB was cleared")
+	}
+}
+
+func TestPersistenceWithoutIndexedDB(t *testing.T) {
+	js.Global().Delete("indexedDB")
+	resetPage()
+	for name, fn := range map[string]func(js.Value, []js.Value) interface{}{"saveDB": saveDB, "loadDB": loadDB} {
+		if _, err := await(fn(js.Undefined(), nil).(js.Value)); !strings.Contains(err, "IndexedDB is not available") {
+			t.Errorf("%s rejected with %q, want IndexedDB is not available", name, err)
+		}
+	}
+}
+
+// promiseOf wraps an IndexedDB request in a Promise.
+func promiseOf(req js.Value) js.Value {
+	return newPromise(func() (interface{}, error) {
+		return nil, awaitEvent(req, "onsuccess", "onerror")
+	})
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/SimonWaldherr/tinySQL/internal/svgchart"
+)
+
+// renderChartSVG charts a two-column result: a line chart when every label
+// is a date or timestamp, a bar chart otherwise. It reports false when the
+// result is not a label column followed by a numeric column.
+func renderChartSVG(cols []string, rows []map[string]any) (string, bool) {
+	if len(cols) != 2 {
+		return "", false
+	}
+	if len(rows) > 0 && !numericColumn(rows, cols[1]) {
+		return "", false
+	}
+	if len(rows) > 0 && timeColumn(rows, cols[0]) {
+		return renderLineChartSVG(cols, rows), true
+	}
+	return renderBarChartSVG(cols, rows), true
+}
+
+// renderBarChartSVG draws one bar per row, labelled by the first column and
+// sized by the second. NULL values leave a gap.
+func renderBarChartSVG(cols []string, rows []map[string]any) string {
+	pts := make([]svgchart.Point, len(rows))
+	for i, r := range rows {
+		v, ok := chartValue(r[cols[1]])
+		pts[i] = svgchart.Point{Label: cell(r[cols[0]]), Value: v, Null: !ok}
+	}
+	return svgchart.Bar(cols[0], cols[1], pts)
+}
+
+// renderLineChartSVG draws the rows as a time series: the first column is
+// a date or timestamp placed proportionally on the x axis, the second the
+// value. Rows whose time or value cannot be read are skipped.
+func renderLineChartSVG(cols []string, rows []map[string]any) string {
+	var pts []svgchart.Point
+	for _, r := range rows {
+		t, okT := svgchart.ParseTime(r[cols[0]])
+		v, okV := chartValue(r[cols[1]])
+		if okT && okV {
+			pts = append(pts, svgchart.Point{Time: t, Value: v})
+		}
+	}
+	return svgchart.Line(cols[0], cols[1], pts)
+}
+
+func chartValue(v any) (float64, bool) {
+	if v == nil {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(cell(v), 64)
+	return f, err == nil
+}
+
+// timeColumn reports whether every non-NULL value of column c is a date or
+// timestamp.
+func timeColumn(rows []map[string]any, c string) bool {
+	seen := false
+	for _, r := range rows {
+		if r[c] == nil {
+			continue
+		}
+		if _, ok := svgchart.ParseTime(r[c]); !ok {
+			return false
+		}
+		seen = true
+	}
+	return seen
+}
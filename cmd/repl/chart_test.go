@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+// checkSVG fails unless svg is well-formed XML with an <svg> root and a
+// viewBox, and returns the number of elements named each tag.
+func checkSVG(t *testing.T, svg string) map[string]int {
+	t.Helper()
+	counts := map[string]int{}
+	dec := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid SVG: %v\n%s", err, svg)
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			if counts["svg"] == 0 && se.Name.Local != "svg" {
+				t.Fatalf("root element = %s, want svg", se.Name.Local)
+			}
+			counts[se.Name.Local]++
+		}
+	}
+	if !strings.Contains(svg, `viewBox="0 0 `) || !strings.Contains(svg, `width="100%"`) {
+		t.Fatalf("SVG does not scale with a viewBox: %s", svg)
+	}
+	if strings.Contains(svg, "href") || strings.Contains(svg, "url(") {
+		t.Fatalf("SVG references external resources: %s", svg)
+	}
+	return counts
+}
+
+func TestRenderBarChartSVG(t *testing.T) {
+	cols := []string{"region", "sales"}
+	rows := []map[string]any{{"region": "north", "sales": 120}, {"region": "south", "sales": 80.5}, {"region": "<east>", "sales": nil}}
+	svg, ok := renderChartSVG(cols, rows)
+	if !ok {
+		t.Fatal("two-column numeric result was not charted")
+	}
+	counts := checkSVG(t, svg)
+	if counts["rect"] != 2 {
+		t.Errorf("bars = %d, want 2 (NULL leaves a gap)", counts["rect"])
+	}
+	for _, want := range []string{`class="axis-label"`, ">region</text>", ">sales</text>", ">north</text>", "&lt;east&gt;", ">120</text>"} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("bar chart lacks %s", want)
+		}
+	}
+}
+
+func TestRenderLineChartSVGForTimeSeries(t *testing.T) {
+	cols := []string{"day", "visits"}
+	rows := []map[string]any{
+		{"day": "2024-01-03", "visits": 7},
+		{"day": "2024-01-01", "visits": 3},
+		{"day": "2024-01-02", "visits": 5},
+	}
+	svg, ok := renderChartSVG(cols, rows)
+	if !ok {
+		t.Fatal("time series was not charted")
+	}
+	counts := checkSVG(t, svg)
+	if counts["polyline"] != 1 || counts["circle"] != 3 || counts["rect"] != 0 {
+		t.Fatalf("line chart elements = %v", counts)
+	}
+	// Points are sorted by time, so the first tick is the earliest day.
+	if first, last := strings.Index(svg, ">2024-01-01</text>"), strings.Index(svg, ">2024-01-03</text>"); first < 0 || last < first {
+		t.Errorf("time axis labels missing or out of order:\n%s", svg)
+	}
+	if !strings.Contains(svg, ">day</text>") || !strings.Contains(svg, ">visits</text>") {
+		t.Errorf("axis labels missing:\n%s", svg)
+	}
+}
+
+func TestRenderChartSVGPlaceholderAndShape(t *testing.T) {
+	for _, svg := range []string{
+		renderBarChartSVG([]string{"a", "b"}, nil),
+		renderLineChartSVG([]string{"a", "b"}, nil),
+	} {
+		checkSVG(t, svg)
+		if !strings.Contains(svg, "No data to chart") {
+			t.Errorf("empty chart lacks placeholder: %s", svg)
+		}
+	}
+	if _, ok := renderChartSVG([]string{"a", "b", "c"}, []map[string]any{{"a": "x", "b": 1, "c": 2}}); ok {
+		t.Error("three-column result should not be charted")
+	}
+	if _, ok := renderChartSVG([]string{"a", "b"}, []map[string]any{{"a": "x", "b": "text"}}); ok {
+		t.Error("non-numeric value column should not be charted")
+	}
+}
+
+func TestChartWidthFollowsRowCount(t *testing.T) {
+	rowsOf := func(n int) []map[string]any {
+		rows := make([]map[string]any, n)
+		for i := range rows {
+			rows[i] = map[string]any{"k": string(rune('a' + i)), "v": i + 1}
+		}
+		return rows
+	}
+	viewBox := func(svg string) string {
+		i := strings.Index(svg, `viewBox="`)
+		return strings.SplitN(svg[i+len(`viewBox="`):], `"`, 2)[0]
+	}
+	cols := []string{"k", "v"}
+	small := viewBox(renderBarChartSVG(cols, rowsOf(2)))
+	large := viewBox(renderBarChartSVG(cols, rowsOf(20)))
+	if small != "0 0 320 272" {
+		t.Errorf("small chart viewBox = %q, want the minimum width", small)
+	}
+	if large != "0 0 1200 272" {
+		t.Errorf("20-bar chart viewBox = %q, want one slot per bar", large)
+	}
+}
+
+func TestHTMLChartFlagAddsChartAfterTable(t *testing.T) {
+	*flagHTMLChart = true
+	defer func() { *flagHTMLChart = false }()
+	db := openTestDB(t)
+	if _, err := db.Exec("CREATE TABLE sales (region TEXT, amount INT)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("INSERT INTO sales VALUES ('north', 3), ('south', 5)"); err != nil {
+		t.Fatal(err)
+	}
+	var parts []string
+	if err := handleSelectStatement(db, "SELECT region, amount FROM sales", false, true, false, "table", nil, &parts, false); err != nil {
+		t.Fatal(err)
+	}
+	frag := strings.Join(parts, "")
+	table, chart := strings.Index(frag, `<table class="results">`), strings.Index(frag, `<div class="chart-wrap"><svg`)
+	if table < 0 || chart < table {
+		t.Fatalf("want the table followed by its chart:\n%s", frag)
+	}
+}
@@ -1,25 +1,34 @@
 package main
 
 import (
-	"bufio"
+	"context"
 	"database/sql"
+	"errors"
 	"flag"
 	"fmt"
 	"html"
 	"html/template"
+	"io"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"time"
 
 	_ "github.com/SimonWaldherr/tinySQL/driver"
+	"github.com/SimonWaldherr/tinySQL/internal/lineedit"
 	"github.com/SimonWaldherr/tinySQL/sqlutil"
 )
 
 var flagDSN = flag.String("dsn", "mem://?tenant=default", "DSN (mem:// or file:/path.db?tenant=...&autosave=1)")
 var flagEcho = flag.Bool("echo", false, "Echo SQL statements before execution")
-var flagFormat = flag.String("format", "table", "Output format: table, csv, tsv, json, yaml, markdown")
+var flagFormat = flag.String("format", "table", "Output format: table, csv, tsv, json, yaml, markdown, chart")
 var flagBeautiful = flag.Bool("beautiful", false, "Pretty-print SQL blocks and results (group statements until next SELECT)")
 var flagHTML = flag.Bool("html", false, "Emit a single HTML page showing the SQL blocks and results (useful when redirecting input)")
+var flagHTMLStatic = flag.Bool("html-static", false, "Like -html, but without the sort and filter controls on result tables")
+var flagHTMLChart = flag.Bool("html-chart", false, "Like -html, and chart two-column results (label, number) as SVG bar or line charts")
 var flagErrorsOnly = flag.Bool("errors-only", false, "Only print queries/results that produce errors (ERR)")
+var flagNoAlign = flag.Bool("no-align", false, "Left-align every table column (disables numeric right-alignment for scripting)")
 
 func main() {
 	flag.Parse()
@@ -31,7 +40,7 @@ func main() {
 	}
 	defer db.Close()
 
-	runREPL(db, *flagEcho, *flagFormat, *flagBeautiful, *flagHTML, *flagErrorsOnly)
+	runREPL(db, *flagEcho, *flagFormat, *flagBeautiful, *flagHTML || *flagHTMLStatic || *flagHTMLChart, *flagErrorsOnly)
 }
 
 // checkInteractiveMode checks if stdin is a terminal
@@ -49,21 +58,41 @@ func printREPLBanner(interactive, htmlMode bool) {
 	}
 }
 
-// printPrompt prints the appropriate prompt based on state
-func printPrompt(hasBuffer, interactive, htmlMode, firstPrompt bool) bool {
+// nextPrompt returns the prompt to read the next line with, printing the
+// blank line that separates one statement's output from the next prompt.
+func nextPrompt(hasBuffer, interactive, htmlMode, firstPrompt bool) (string, bool) {
 	if !interactive || htmlMode {
-		return firstPrompt
+		return "", firstPrompt
 	}
 
 	if !hasBuffer {
 		if !firstPrompt {
 			fmt.Println()
 		}
-		fmt.Print("sql> ")
-		return false
+		return "sql> ", false
+	}
+	return " ... ", firstPrompt
+}
+
+// openInput returns the line source for the REPL: the history-backed line
+// editor on an interactive terminal, a plain line scanner otherwise.
+func openInput(db *sql.DB, interactive, htmlMode bool) lineedit.Reader {
+	if !interactive || htmlMode {
+		return lineedit.NewScanner(os.Stdin, os.Stdout)
+	}
+	history, err := lineedit.LoadHistory(lineedit.DefaultHistoryPath(), lineedit.DefaultHistorySize)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "history:", err)
 	}
-	fmt.Print(" ... ")
-	return firstPrompt
+	return lineedit.Open(os.Stdin, os.Stdout, history, replCompleter(db))
+}
+
+// replCompleter completes table and column names of the open database.
+func replCompleter(db *sql.DB) lineedit.Completer {
+	return lineedit.SQLCompleter{
+		Tables:  func() []string { return replListTableNames(db) },
+		Columns: func(table string) []string { return replTableColumns(db, table) },
+	}.Complete
 }
 
 // handleInputError handles scanner errors
@@ -96,16 +125,13 @@ func executeStatement(db *sql.DB, q string, beautiful, htmlMode, errorsOnly bool
 }
 
 func runREPL(db *sql.DB, echo bool, format string, beautiful bool, htmlMode bool, errorsOnly bool) {
-	sc := bufio.NewScanner(os.Stdin)
-	// Scanner token limit is 64K by default; allow larger statements/files.
-	sc.Buffer(make([]byte, 1024), 4*1024*1024)
-
 	var buf strings.Builder
 	firstPrompt := true
 
 	// If stdin is not a terminal (e.g., redirected from a file) suppress
 	// interactive prompts like `sql>` to keep non-interactive output clean.
 	interactive := checkInteractiveMode()
+	in := openInput(db, interactive, htmlMode)
 
 	// Keep HTML output clean: never print banners/prompts to stdout in htmlMode.
 	printREPLBanner(interactive, htmlMode)
@@ -120,11 +146,18 @@ func runREPL(db *sql.DB, echo bool, format string, beautiful bool, htmlMode bool
 	var htmlParts []string
 
 	for {
-		firstPrompt = printPrompt(buf.Len() > 0, interactive, htmlMode, firstPrompt)
+		var prompt string
+		prompt, firstPrompt = nextPrompt(buf.Len() > 0, interactive, htmlMode, firstPrompt)
 
-		if !sc.Scan() {
+		raw, err := in.ReadLine(prompt)
+		if errors.Is(err, lineedit.ErrInterrupt) {
+			// Ctrl-C discards the statement being typed.
+			buf.Reset()
+			continue
+		}
+		if err != nil {
 			// Input closed (or read error).
-			if err := sc.Err(); err != nil {
+			if err != io.EOF {
 				handleInputError(err, htmlMode, &htmlParts)
 			}
 
@@ -134,7 +167,6 @@ func runREPL(db *sql.DB, echo bool, format string, beautiful bool, htmlMode bool
 			return
 		}
 
-		raw := sc.Text()
 		line := strings.TrimSpace(raw)
 
 		// Always collect source lines when beautiful mode is enabled so we can
@@ -231,6 +263,7 @@ Commands:
   .read FILE            Execute SQL from file
   .output FORMAT        Show current or set output format (table, csv, tsv, json, yaml, markdown)
   .timer on|off         Toggle execution timing
+  .watch SECONDS QUERY  Re-run QUERY every SECONDS until Ctrl-C
   .clear                Clear the screen`)
 		return true
 
@@ -301,12 +334,51 @@ Commands:
 		fmt.Print("\033[2J\033[H")
 		return true
 
+	case ".watch":
+		rest := strings.TrimSpace(strings.TrimPrefix(line, cmd))
+		secs, q, _ := strings.Cut(rest, " ")
+		n, err := strconv.ParseFloat(secs, 64)
+		q = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(q), ";"))
+		if err != nil || n <= 0 || q == "" {
+			fmt.Println("Usage: .watch SECONDS QUERY")
+			return true
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		replWatch(ctx, db, time.Duration(n*float64(time.Second)), q, *flagFormat)
+		return true
+
 	default:
 		return false
 	}
 	return false
 }
 
+// replWatch clears the screen and prints the result of q every interval
+// until ctx is cancelled or the query fails.
+func replWatch(ctx context.Context, db *sql.DB, interval time.Duration, q, format string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		fmt.Print("\033[2J\033[H")
+		rows, err := db.QueryContext(ctx, q)
+		if err != nil {
+			if ctx.Err() == nil {
+				fmt.Println("ERR:", err)
+			}
+			return
+		}
+		cols, _ := rows.Columns()
+		printRows(rows, cols, format)
+		rows.Close()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 // replListTableNames returns all table names sorted.
 func replListTableNames(db *sql.DB) []string {
 	rows, err := db.Query(`SELECT name FROM sys.tables ORDER BY name`)
@@ -324,6 +396,18 @@ func replListTableNames(db *sql.DB) []string {
 }
 
 // replShowSchema prints column info for a table.
+// replTableColumns returns the column names of table, or nil if it cannot
+// be read.
+func replTableColumns(db *sql.DB, table string) []string {
+	r, err := db.Query(fmt.Sprintf("SELECT * FROM %s LIMIT 0", table))
+	if err != nil {
+		return nil
+	}
+	defer r.Close()
+	cols, _ := r.Columns()
+	return cols
+}
+
 func replShowSchema(db *sql.DB, table string) {
 	r, err := db.Query(fmt.Sprintf("SELECT * FROM %s LIMIT 0", table))
 	if err != nil {
@@ -510,7 +594,11 @@ func handleSelectStatement(db *sql.DB, q string, beautiful, htmlMode, errorsOnly
 				} else {
 					*htmlParts = append(*htmlParts, renderSQLHTML(q))
 				}
-				*htmlParts = append(*htmlParts, renderRowsHTML(out, cols))
+				frag := renderRowsHTML(out, cols)
+				if svg, ok := renderChartSVG(cols, out); ok && *flagHTMLChart {
+					frag += "<div class=\"chart-wrap\">" + svg + "</div>\n"
+				}
+				*htmlParts = append(*htmlParts, frag)
 				*htmlParts = append(*htmlParts, "<hr/>")
 			}
 		}
@@ -600,45 +688,124 @@ func printRows(rows *sql.Rows, cols []string, format string) {
 		printTSV(out, cols)
 	case "markdown", "md":
 		printMarkdown(out, cols)
+	case "chart":
+		// Two-column results print as an SVG document; anything else
+		// falls back to the table.
+		if svg, ok := renderChartSVG(cols, out); ok {
+			fmt.Println(svg)
+		} else {
+			printTable(out, cols)
+		}
 	default:
 		printTable(out, cols)
 	}
 }
 
 func printTable(out []map[string]any, cols []string) {
+	writeTable(os.Stdout, out, cols, !*flagNoAlign)
+}
+
+// writeTable renders rows as a plain-text table. When align is set, columns
+// whose values are all numeric are right-aligned with their decimal points
+// lined up.
+func writeTable(w io.Writer, out []map[string]any, cols []string, align bool) {
+	cells := make([][]string, len(out))
+	for j, r := range out {
+		cells[j] = make([]string, len(cols))
+		for i, c := range cols {
+			cells[j][i] = cell(r[c])
+		}
+	}
+	numericCol := make([]bool, len(cols))
+	if align {
+		for i := range cols {
+			numericCol[i] = numericColumn(out, cols[i])
+			if numericCol[i] {
+				alignDecimals(cells, i)
+			}
+		}
+	}
 	width := make([]int, len(cols))
 	for i, c := range cols {
 		width[i] = len(c)
 	}
-	for _, r := range out {
-		for i, c := range cols {
-			if w := len(cell(r[c])); w > width[i] {
-				width[i] = w
+	for _, row := range cells {
+		for i, v := range row {
+			if len(v) > width[i] {
+				width[i] = len(v)
 			}
 		}
 	}
+	pad := func(s string, i int) string {
+		if numericCol[i] {
+			return padLeft(s, width[i])
+		}
+		return padRight(s, width[i])
+	}
 	for i, c := range cols {
-		fmt.Print(padRight(c, width[i]))
+		fmt.Fprint(w, pad(c, i))
 		if i < len(cols)-1 {
-			fmt.Print("  ")
+			fmt.Fprint(w, "  ")
 		}
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 	for i := range cols {
-		fmt.Print(strings.Repeat("-", width[i]))
+		fmt.Fprint(w, strings.Repeat("-", width[i]))
 		if i < len(cols)-1 {
-			fmt.Print("  ")
+			fmt.Fprint(w, "  ")
 		}
 	}
-	fmt.Println()
-	for _, r := range out {
-		for i, c := range cols {
-			fmt.Print(padRight(cell(r[c]), width[i]))
+	fmt.Fprintln(w)
+	for _, row := range cells {
+		for i, v := range row {
+			fmt.Fprint(w, pad(v, i))
 			if i < len(cols)-1 {
-				fmt.Print("  ")
+				fmt.Fprint(w, "  ")
 			}
 		}
-		fmt.Println()
+		fmt.Fprintln(w)
+	}
+}
+
+// numericColumn reports whether every non-NULL value of column c parses as a
+// number. Columns that contain only NULLs are not numeric.
+func numericColumn(out []map[string]any, c string) bool {
+	seen := false
+	for _, r := range out {
+		v := r[c]
+		if v == nil {
+			continue
+		}
+		if _, err := strconv.ParseFloat(cell(v), 64); err != nil {
+			return false
+		}
+		seen = true
+	}
+	return seen
+}
+
+// alignDecimals pads the fractional part of column i so that, once the
+// column is right-aligned, all decimal points share the same position.
+func alignDecimals(cells [][]string, i int) {
+	frac := 0
+	for _, row := range cells {
+		if dot := strings.IndexByte(row[i], '.'); dot >= 0 && len(row[i])-dot > frac {
+			frac = len(row[i]) - dot
+		}
+	}
+	if frac == 0 {
+		return
+	}
+	for _, row := range cells {
+		v := row[i]
+		if v == "NULL" {
+			continue
+		}
+		n := 0
+		if dot := strings.IndexByte(v, '.'); dot >= 0 {
+			n = len(v) - dot
+		}
+		row[i] = v + strings.Repeat(" ", frac-n)
 	}
 }
 
@@ -649,6 +816,13 @@ func padRight(s string, w int) string {
 	return s + strings.Repeat(" ", w-len(s))
 }
 
+func padLeft(s string, w int) string {
+	if len(s) >= w {
+		return s
+	}
+	return strings.Repeat(" ", w-len(s)) + s
+}
+
 func dePtr(p any) any {
 	switch v := p.(type) {
 	case *any:
@@ -719,22 +893,49 @@ func rowsToSlice(rows *sql.Rows, cols []string) ([]map[string]any, error) {
 }
 
 // renderRowsHTML returns an HTML table for the given rows and columns.
+// Unless -html-static is set, headers are marked sortable and a row of
+// filter inputs follows them; the page script wires both up.
 func renderRowsHTML(out []map[string]any, cols []string) string {
+	interactive := !*flagHTMLStatic
+	numericCol := make([]bool, len(cols))
+	for i, c := range cols {
+		numericCol[i] = numericColumn(out, c)
+	}
 	var b strings.Builder
 	b.WriteString("<table class=\"results\">\n<thead>\n<tr>\n")
-	for _, c := range cols {
-		b.WriteString("<th>" + html.EscapeString(c) + "</th>")
+	for i, c := range cols {
+		switch {
+		case !interactive:
+			b.WriteString("<th>")
+		case numericCol[i]:
+			fmt.Fprintf(&b, "<th class=\"sortable\" data-col=\"%d\" data-type=\"number\">", i)
+		default:
+			fmt.Fprintf(&b, "<th class=\"sortable\" data-col=\"%d\">", i)
+		}
+		b.WriteString(html.EscapeString(c) + "</th>")
+	}
+	if interactive {
+		b.WriteString("\n</tr>\n<tr class=\"filters\">\n")
+		for i, c := range cols {
+			fmt.Fprintf(&b, "<th><input type=\"search\" class=\"col-filter\" data-col=\"%d\" placeholder=\"Filter\" aria-label=\"Filter %s\"></th>", i, html.EscapeString(c))
+		}
 	}
 	b.WriteString("\n</tr>\n</thead>\n<tbody>\n")
 	for _, r := range out {
 		b.WriteString("<tr>")
-		for _, c := range cols {
+		for i, c := range cols {
 			v := r[c]
-			s := "NULL"
-			if v != nil {
-				s = fmt.Sprintf("%v", v)
+			if v == nil {
+				// Marked so the CSV download can tell NULL from the text "NULL".
+				b.WriteString("<td class=\"null\">NULL</td>")
+				continue
+			}
+			s := fmt.Sprintf("%v", v)
+			if numericCol[i] {
+				b.WriteString("<td style=\"text-align:right\">" + html.EscapeString(s) + "</td>")
+			} else {
+				b.WriteString("<td>" + html.EscapeString(s) + "</td>")
 			}
-			b.WriteString("<td>" + html.EscapeString(s) + "</td>")
 		}
 		b.WriteString("</tr>\n")
 	}
@@ -896,6 +1097,8 @@ type htmlPageData struct {
 	Title string
 	Lead  string
 	Parts []template.HTML
+	// Interactive adds the sort and filter script for result tables.
+	Interactive bool
 }
 
 const htmlPageTemplate = `<!doctype html>
@@ -949,6 +1152,14 @@ const htmlPageTemplate = `<!doctype html>
 		table.results th,table.results td{border-bottom:1px solid var(--border);padding:10px;text-align:left;font-size:13px;background:var(--card)}
 		table.results th{background:var(--codebg);color:var(--muted);font-weight:700}
 		table.results tr:last-child td{border-bottom:none}
+		.chart-wrap{margin:12px 0;padding:8px;border-radius:12px;border:1px solid var(--border);background:var(--card);color:var(--text)}
+		.chart-wrap svg{display:block;max-height:360px}
+		table.results th.sortable{cursor:pointer;user-select:none}
+		table.results th.sortable[data-sort=asc]::after{content:' \25B2'}
+		table.results th.sortable[data-sort=desc]::after{content:' \25BC'}
+		table.results tr.filters th{padding:4px 6px}
+		table.results input.col-filter{width:100%;box-sizing:border-box;font-size:12px;padding:4px 6px;border:1px solid var(--border);
+			border-radius:6px;background:var(--card);color:var(--text)}
 
 		.err{color:#b31d28;font-weight:700;margin:8px 0}
 		.ok{color:#15803d;font-weight:700;margin:8px 0;font-size:13px}
@@ -1130,7 +1341,7 @@ const htmlPageTemplate = `<!doctype html>
 						let html = '<div class="results-wrap"><table class="results"><thead><tr>';
 						for(const c of cols) html += '<th>'+escapeHtml(String(c))+'</th>';
 						html += '</tr></thead><tbody>';
-						for(const r of rows){ html += '<tr>'; for(const c of cols){ const v = r[c]; html += '<td>'+ (v==null? 'NULL' : escapeHtml(String(v))) +'</td>'; } html += '</tr>'; }
+						for(const r of rows){ html += '<tr>'; for(const c of cols){ const v = r[c]; html += v==null ? '<td class="null">NULL</td>' : '<td>'+escapeHtml(String(v))+'</td>'; } html += '</tr>'; }
 						html += '</tbody></table></div>';
 						resultWrap.innerHTML = html;
 					}else{
@@ -1141,6 +1352,91 @@ const htmlPageTemplate = `<!doctype html>
 				}
 			}
 		</script>
+		<script>
+		// CSV download of a sql-block's result: the live result when the
+		// query was re-run, otherwise the generated one. Only rows left
+		// visible by the filters are written; NULL cells become empty fields.
+		function csvField(s){ return /[",\r\n]/.test(s) ? '"'+s.replace(/"/g,'""')+'"' : s; }
+		function rowsToCSV(rows){
+			return rows.map(r=>r.map(c=>c==null ? '' : csvField(String(c))).join(',')).join('\n')+'\n';
+		}
+		function tableRows(table){
+			const rows=[];
+			const head=table.tHead && table.tHead.rows[0];
+			if(head) rows.push(Array.from(head.cells).map(c=>c.textContent));
+			const body=table.tBodies[0];
+			Array.from(body ? body.rows : []).forEach(r=>{
+				if(!r.hidden) rows.push(Array.from(r.cells).map(c=>c.classList.contains('null') ? null : c.textContent));
+			});
+			return rows;
+		}
+		// csvFileName names the download after the queried table, or the
+		// first column alias for queries without FROM.
+		function csvFileName(sql){
+			const m=/\bfrom\s+([A-Za-z_][\w.]*)/i.exec(sql) || /\bas\s+([A-Za-z_]\w*)/i.exec(sql);
+			return (m ? m[1] : 'result').replace(/[^\w.-]/g,'_')+'.csv';
+		}
+		function downloadCSV(id){
+			const block=document.getElementById(id);
+			if(!block) return;
+			const table=block.querySelector('.inline-result table.results') || block.querySelector('table.results');
+			if(!table){ toast('No result to download'); return; }
+			const ta=block.querySelector('textarea');
+			const url=URL.createObjectURL(new Blob([rowsToCSV(tableRows(table))], {type:'text/csv;charset=utf-8'}));
+			const a=document.createElement('a');
+			a.href=url;
+			a.download=csvFileName(ta ? ta.value : '');
+			document.body.appendChild(a);
+			a.click();
+			a.remove();
+			setTimeout(()=>URL.revokeObjectURL(url), 0);
+		}
+		</script>
+		{{if .Interactive}}
+		<script>
+		// Result tables: clicking a sortable header cycles ascending,
+		// descending and the original order; each filter input keeps the rows
+		// whose cell in that column contains its text, ignoring case.
+		function nextSortDir(dir){ return dir==='asc' ? 'desc' : (dir==='desc' ? '' : 'asc'); }
+		function compareCells(x, y, numeric){
+			if(numeric){
+				const a=parseFloat(x), b=parseFloat(y);
+				if(isNaN(a) || isNaN(b)) return isNaN(a) - isNaN(b);
+				return a-b;
+			}
+			return x.localeCompare(y, undefined, {numeric:true, sensitivity:'base'});
+		}
+		function sortResults(table, th){
+			const dir=nextSortDir(th.dataset.sort || '');
+			table.querySelectorAll('th.sortable').forEach(h=>{ delete h.dataset.sort; h.removeAttribute('aria-sort'); });
+			if(dir){ th.dataset.sort=dir; th.setAttribute('aria-sort', dir==='asc' ? 'ascending' : 'descending'); }
+			const col=Number(th.dataset.col), numeric=th.dataset.type==='number', body=table.tBodies[0];
+			const rows=Array.from(body.rows);
+			rows.sort((a,b)=>{
+				if(!dir) return a.dataset.order-b.dataset.order;
+				const c=compareCells(a.cells[col].textContent, b.cells[col].textContent, numeric);
+				return dir==='asc' ? c : -c;
+			});
+			rows.forEach(r=>body.appendChild(r));
+		}
+		function filterResults(table){
+			const filters=Array.from(table.querySelectorAll('input.col-filter'))
+				.map(i=>[Number(i.dataset.col), i.value.trim().toLowerCase()])
+				.filter(f=>f[1]);
+			Array.from(table.tBodies[0].rows).forEach(r=>{
+				r.hidden=!filters.every(([c,q])=>r.cells[c].textContent.toLowerCase().includes(q));
+			});
+		}
+		document.addEventListener('DOMContentLoaded', ()=>{
+			document.querySelectorAll('table.results').forEach(table=>{
+				if(!table.tBodies[0]) return;
+				Array.from(table.tBodies[0].rows).forEach((r,i)=>{ r.dataset.order=i; });
+				table.querySelectorAll('th.sortable').forEach(th=>th.addEventListener('click', ()=>sortResults(table, th)));
+				table.querySelectorAll('input.col-filter').forEach(i=>i.addEventListener('input', ()=>filterResults(table)));
+			});
+		});
+		</script>
+		{{end}}
 
 		{{range .Parts}}
 	{{.}}
@@ -1177,7 +1473,11 @@ func decorateHTMLFragment(p string, i int, next string) string {
 
 	// Add copy control (no toggle) and replace the pre block with textarea
 	// while preserving the contained HTML-escaped text.
-	controls := fmt.Sprintf("<div class=\"controls\"><button type=\"button\" class=\"btn\" onclick=\"copySQL('%s')\">Copy</button><button type=\"button\" class=\"btn btn-run\" onclick=\"runBlockQuery('%s')\">Run</button></div>", id, id)
+	buttons := fmt.Sprintf("<button type=\"button\" class=\"btn\" onclick=\"copySQL('%s')\">Copy</button><button type=\"button\" class=\"btn btn-run\" onclick=\"runBlockQuery('%s')\">Run</button>", id, id)
+	if strings.Contains(next, "<table class=\"results\"") {
+		buttons += fmt.Sprintf("<button type=\"button\" class=\"btn btn-csv\" onclick=\"downloadCSV('%s')\">Download CSV</button>", id)
+	}
+	controls := "<div class=\"controls\">" + buttons + "</div>"
 	// Replace only the first <pre> occurrence.
 	if strings.Contains(p, "<pre>") {
 		// Make the textarea editable so users can tweak and re-run example queries.
@@ -1209,9 +1509,10 @@ func decorateHTMLFragment(p string, i int, next string) string {
 // emitHTMLPage writes a single HTML document to stdout using the collected fragments.
 func emitHTMLPage(parts []string) {
 	data := htmlPageData{
-		Title: "tinySQL - Function Examples",
-		Lead:  "Auto-generated from input SQL. Results are shown below; SQL is visible above each result.",
-		Parts: make([]template.HTML, 0, len(parts)),
+		Title:       "tinySQL - Function Examples",
+		Lead:        "Auto-generated from input SQL. Results are shown below; SQL is visible above each result.",
+		Parts:       make([]template.HTML, 0, len(parts)),
+		Interactive: !*flagHTMLStatic,
 	}
 
 	for i := 0; i < len(parts); i++ {
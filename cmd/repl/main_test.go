@@ -3,9 +3,11 @@ package main
 import (
 	"context"
 	"database/sql"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -181,3 +183,234 @@ func TestReplDumpTable(t *testing.T) {
 	// Should not panic
 	replDumpTable(db, "ddt")
 }
+
+func TestWriteTableAlignsDecimals(t *testing.T) {
+	rows := []map[string]any{
+		{"name": "a", "price": 1.5},
+		{"name": "bb", "price": 22.25},
+		{"name": "ccc", "price": 333.0},
+		{"name": "d", "price": nil},
+	}
+	var buf strings.Builder
+	writeTable(&buf, rows, []string{"name", "price"}, true)
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("expected 6 lines, got %d:\n%s", len(lines), buf.String())
+	}
+	if !strings.HasSuffix(lines[0], " price") {
+		t.Errorf("numeric header not right-aligned: %q", lines[0])
+	}
+	dot := strings.IndexByte(lines[2], '.')
+	if got := strings.IndexByte(lines[3], '.'); got != dot {
+		t.Errorf("decimal point at %d, want %d:\n%s", got, dot, buf.String())
+	}
+	if got := strings.Index(lines[4], "333") + 3; got != dot {
+		t.Errorf("integer part ends at %d, want %d:\n%s", got, dot, buf.String())
+	}
+	if !strings.HasSuffix(lines[5], " NULL") {
+		t.Errorf("NULL not right-aligned: %q", lines[5])
+	}
+}
+
+func TestWriteTableNoAlign(t *testing.T) {
+	rows := []map[string]any{{"n": 1}, {"n": 100}}
+	var buf strings.Builder
+	writeTable(&buf, rows, []string{"n"}, false)
+	if got, want := buf.String(), "n  \n---\n1  \n100\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRenderRowsHTMLRightAlignsNumbers(t *testing.T) {
+	rows := []map[string]any{{"name": "a", "qty": 3}}
+	out := renderRowsHTML(rows, []string{"name", "qty"})
+	if !strings.Contains(out, `<td style="text-align:right">3</td>`) {
+		t.Errorf("numeric cell not right-aligned: %s", out)
+	}
+	if !strings.Contains(out, "<td>a</td>") {
+		t.Errorf("text cell should stay left-aligned: %s", out)
+	}
+}
+
+func TestReplCompleter(t *testing.T) {
+	db := openTestDB(t)
+	if _, err := db.Exec("CREATE TABLE people (id INT, name TEXT, nickname TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	complete := replCompleter(db)
+	line := []rune("SELECT * FROM peo")
+	if _, got := complete(line, len(line)); len(got) != 1 || got[0] != "people" {
+		t.Fatalf("table completion = %v", got)
+	}
+	line = []rune("SELECT p.n FROM people p")
+	if _, got := complete(line, 10); strings.Join(got, ",") != "name,nickname" {
+		t.Fatalf("column completion = %v", got)
+	}
+}
+
+// captureStdout returns what f prints to os.Stdout.
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+	f()
+	os.Stdout = stdout
+	w.Close()
+	return <-done
+}
+
+func TestReplWatch(t *testing.T) {
+	db := openTestDB(t)
+	if _, err := db.Exec("CREATE TABLE t (id INT)"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Millisecond)
+	defer cancel()
+	inserted := make(chan struct{})
+	go func() {
+		defer close(inserted)
+		for i := 0; ctx.Err() == nil; i++ {
+			if _, err := db.Exec("INSERT INTO t VALUES (?)", i); err != nil {
+				t.Error(err)
+				return
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}()
+	out := captureStdout(t, func() {
+		replWatch(ctx, db, 100*time.Millisecond, "SELECT COUNT(*) AS n FROM t", "csv")
+	})
+	<-inserted
+
+	frames := strings.Split(out, "\033[2J\033[H")[1:]
+	if len(frames) < 5 {
+		t.Fatalf("query ran %d times in 0.6s, want at least 5", len(frames))
+	}
+	if frames[0] == frames[len(frames)-1] || !strings.HasPrefix(frames[0], "n\n") {
+		t.Fatalf("frames = %q, want CSV counts that grow", frames)
+	}
+}
+
+func TestHandleMetaWatchUsage(t *testing.T) {
+	db := openTestDB(t)
+	for _, line := range []string{".watch", ".watch 0 SELECT 1", ".watch 1"} {
+		if out := captureStdout(t, func() { handleMeta(db, line) }); !strings.Contains(out, "Usage") {
+			t.Errorf("%s printed %q, want usage", line, out)
+		}
+	}
+}
+
+func TestRenderRowsHTMLSortAndFilterControls(t *testing.T) {
+	rows := []map[string]any{{"name": "b", "qty": 10}, {"name": "a", "qty": 9}}
+	table := renderRowsHTML(rows, []string{"name", "qty"})
+	for _, want := range []string{
+		`<th class="sortable" data-col="0">name</th>`,
+		`<th class="sortable" data-col="1" data-type="number">qty</th>`,
+		`<tr class="filters">`,
+		`<input type="search" class="col-filter" data-col="1" placeholder="Filter" aria-label="Filter qty">`,
+	} {
+		if !strings.Contains(table, want) {
+			t.Errorf("table lacks %s:\n%s", want, table)
+		}
+	}
+
+	page := captureStdout(t, func() { emitHTMLPage([]string{table}) })
+	for _, want := range []string{"function sortResults(", "function filterResults(", "nextSortDir", "toLowerCase().includes(q)", `<div class="results-wrap"><table class="results">`} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page lacks %q", want)
+		}
+	}
+}
+
+func TestRenderRowsHTMLStatic(t *testing.T) {
+	*flagHTMLStatic = true
+	defer func() { *flagHTMLStatic = false }()
+
+	table := renderRowsHTML([]map[string]any{{"name": "a"}}, []string{"name"})
+	if !strings.Contains(table, "<th>name</th>") || strings.Contains(table, "col-filter") || strings.Contains(table, "sortable") {
+		t.Errorf("static table has interactive controls:\n%s", table)
+	}
+	if page := captureStdout(t, func() { emitHTMLPage([]string{table}) }); strings.Contains(page, "sortResults") {
+		t.Error("static page includes the sort/filter script")
+	}
+}
+
+func TestHTMLPageCSVDownloadButton(t *testing.T) {
+	rows := []map[string]any{{"id": 1, "note": nil}}
+	page := captureStdout(t, func() {
+		emitHTMLPage([]string{
+			renderSQLHTML("SELECT id, note FROM notes"), renderRowsHTML(rows, []string{"id", "note"}),
+			renderSQLHTML("CREATE TABLE t (x INT)"), "<div class='ok'>(ok)</div>",
+		})
+	})
+	if !strings.Contains(page, `onclick="downloadCSV('sql-1')">Download CSV</button>`) {
+		t.Errorf("result block lacks the download button:\n%s", page)
+	}
+	if strings.Count(page, ">Download CSV</button>") != 1 {
+		t.Error("block without a result table got a download button")
+	}
+	if !strings.Contains(page, `<td class="null">NULL</td>`) {
+		t.Error("NULL cell is not marked for the CSV export")
+	}
+}
+
+// The CSV script runs under node, when installed, against a fake DOM: the
+// click must produce a Blob with correctly quoted CSV and a file name taken
+// from the query.
+func TestHTMLPageCSVDownloadScript(t *testing.T) {
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("node not installed")
+	}
+	page := captureStdout(t, func() { emitHTMLPage(nil) })
+	var script string
+	for _, part := range strings.Split(page, "<script>")[1:] {
+		if body := strings.SplitN(part, "</script>", 2)[0]; strings.Contains(body, "function rowsToCSV") {
+			script = body
+		}
+	}
+	if script == "" {
+		t.Fatal("CSV script not found in page")
+	}
+	harness := script + `
+const cell=(text, isNull)=>({textContent:text, classList:{contains:c=>c==='null' && !!isNull}});
+const table={
+	tHead:{rows:[{cells:[cell('id'), cell('note')]}]},
+	tBodies:[{rows:[
+		{hidden:false, cells:[cell('1'), cell('NULL', true)]},
+		{hidden:false, cells:[cell('2'), cell('a, "quoted" note')]},
+		{hidden:true, cells:[cell('3'), cell('filtered out')]},
+		{hidden:false, cells:[cell('4'), cell('NULL')]},
+	]}],
+};
+const block={querySelector:s=>s.includes('textarea') ? {value:'SELECT id, note FROM app.notes n'} : (s.includes('inline') ? null : table)};
+let saved={};
+globalThis.toast=()=>{};
+globalThis.Blob=class{constructor(parts){ saved.csv=parts.join(''); }};
+globalThis.URL={createObjectURL:()=>'blob:1', revokeObjectURL:()=>{}};
+globalThis.document={
+	getElementById:id=>id==='sql-0' ? block : null,
+	body:{appendChild:()=>{}},
+	createElement:()=>({click(){ saved.clicked=true; saved.name=this.download; }, remove(){}}),
+};
+downloadCSV('sql-0');
+console.log(JSON.stringify([saved.clicked, saved.name, saved.csv, csvFileName('SELECT 1 AS answer')]));
+`
+	out, err := exec.Command(node, "-e", harness).CombinedOutput()
+	if err != nil {
+		t.Fatalf("node: %v\n%s", err, out)
+	}
+	want := `[true,"app.notes.csv","id,note\n1,\n2,\"a, \"\"quoted\"\" note\"\n4,NULL\n","answer.csv"]`
+	if got := strings.TrimSpace(string(out)); got != want {
+		t.Fatalf("download = %s\nwant       %s", got, want)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"time"
+
+	"google.golang.org/grpc/status"
+)
+
+// QueryStream (/tinysql.TinySQL/QueryStream) runs a query like Query but
+// sends the result as a stream of queryResponse messages of up to
+// queryStreamChunkRows rows each, so results larger than -grpc-max-send-bytes
+// or -max-response-rows can be read. Every message carries the columns and
+// its own row count; a failed query is a single message with Error set.
+
+const queryStreamChunkRows = 1000
+
+func (s *server) QueryStream(req *queryRequest, stream TinySQL_QueryStreamServer) error {
+	ctx := stream.Context()
+	start := time.Now()
+	sqlText, rs, err := s.runQuery(ctx, req)
+	if err != nil {
+		return stream.Send(&queryResponse{SQL: sqlText, Error: err.Error(), Duration: time.Since(start).String()})
+	}
+
+	cols := queryColumns(rs)
+	if len(cols) == 0 {
+		return stream.Send(&queryResponse{SQL: sqlText, Duration: time.Since(start).String()})
+	}
+	for off := 0; off < len(rs.Rows); off += queryStreamChunkRows {
+		// Stop as soon as the client cancels or goes away rather than
+		// converting rows nobody will read.
+		if err := ctx.Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+		end := min(off+queryStreamChunkRows, len(rs.Rows))
+		rows := make([]map[string]any, 0, end-off)
+		for _, r := range rs.Rows[off:end] {
+			rows = append(rows, copyRow(r))
+		}
+		if err := stream.Send(&queryResponse{
+			SQL:      sqlText,
+			Columns:  cols,
+			Rows:     rows,
+			Duration: time.Since(start).String(),
+			Count:    len(rows),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SimonWaldherr/tinySQL/internal/engine"
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// newStreamServer returns a server with a table nums (id) holding ids
+// 1..n, filled directly in storage since n INSERTs would be slow.
+func newStreamServer(t *testing.T, n int) *server {
+	t.Helper()
+	db := storage.NewDB()
+	t.Cleanup(func() { _ = db.Close() })
+	tbl := storage.NewTable("nums", []storage.Column{{Name: "id", Type: storage.IntType}}, false)
+	tbl.Rows = make([][]any, n)
+	for i := range tbl.Rows {
+		tbl.Rows[i] = []any{i + 1}
+	}
+	if err := db.Put("default", tbl); err != nil {
+		t.Fatal(err)
+	}
+	return &server{db: db, cache: engine.NewQueryCache(10), defaultT: "default", metrics: newMetricsRegistry()}
+}
+
+// serveGRPC serves s over gRPC with the server's interceptors and returns
+// a client connection.
+func serveGRPC(t *testing.T, s *server) *grpc.ClientConn {
+	t.Helper()
+	encoding.RegisterCodec(jsonCodec{})
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	gs := grpc.NewServer(
+		grpc.UnaryInterceptor(s.grpcUnaryInterceptor()),
+		grpc.StreamInterceptor(s.grpcStreamInterceptor()),
+	)
+	registerTinySQLServer(gs, s)
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(jsonCodec{})),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// queryStream calls QueryStream and returns every message received.
+func queryStream(ctx context.Context, conn *grpc.ClientConn, req *queryRequest) ([]*queryResponse, error) {
+	desc := &grpc.StreamDesc{StreamName: "QueryStream", ServerStreams: true}
+	cs, err := conn.NewStream(ctx, desc, "/tinysql.TinySQL/QueryStream")
+	if err != nil {
+		return nil, err
+	}
+	if err := cs.SendMsg(req); err != nil {
+		return nil, err
+	}
+	if err := cs.CloseSend(); err != nil {
+		return nil, err
+	}
+	var msgs []*queryResponse
+	for {
+		msg := new(queryResponse)
+		if err := cs.RecvMsg(msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				return msgs, nil
+			}
+			return msgs, err
+		}
+		msgs = append(msgs, msg)
+	}
+}
+
+// checkChunks fails unless msgs hold ids 1..n in chunks of
+// queryStreamChunkRows, the last possibly shorter.
+func checkChunks(t *testing.T, msgs []*queryResponse, n int) {
+	t.Helper()
+	want := 1
+	for i, msg := range msgs {
+		if msg.Error != "" {
+			t.Fatalf("message %d: %s", i, msg.Error)
+		}
+		if len(msg.Rows) != msg.Count || len(msg.Columns) != 1 || msg.Columns[0] != "id" {
+			t.Fatalf("message %d: count %d, columns %v for %d rows", i, msg.Count, msg.Columns, len(msg.Rows))
+		}
+		if size := min(queryStreamChunkRows, n-want+1); len(msg.Rows) != size {
+			t.Fatalf("message %d has %d rows, want %d", i, len(msg.Rows), size)
+		}
+		for _, r := range msg.Rows {
+			if fmt.Sprint(r["id"]) != fmt.Sprint(want) {
+				t.Fatalf("message %d: id %v, want %d", i, r["id"], want)
+			}
+			want++
+		}
+	}
+	if want != n+1 {
+		t.Fatalf("received %d rows, want %d", want-1, n)
+	}
+}
+
+func TestQueryStreamReturnsAllRows(t *testing.T) {
+	const n = 100_000
+	conn := serveGRPC(t, newStreamServer(t, n))
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	msgs, err := queryStream(ctx, conn, &queryRequest{SQL: "SELECT id FROM nums ORDER BY id"})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	if len(msgs) != n/queryStreamChunkRows {
+		t.Fatalf("messages = %d, want %d", len(msgs), n/queryStreamChunkRows)
+	}
+	checkChunks(t, msgs, n)
+}
+
+func TestQueryStreamChunkBoundaries(t *testing.T) {
+	s := newStreamServer(t, 2500)
+	for _, tc := range []struct {
+		sql    string
+		n      int
+		chunks int
+	}{
+		{"SELECT id FROM nums ORDER BY id", 2500, 3},
+		{"SELECT id FROM nums WHERE id <= 1000 ORDER BY id", 1000, 1},
+		{"SELECT id FROM nums WHERE id <= 1001 ORDER BY id", 1001, 2},
+	} {
+		stream := &fakeQueryStream{ctx: context.Background()}
+		if err := s.QueryStream(&queryRequest{SQL: tc.sql}, stream); err != nil {
+			t.Fatalf("%s: %v", tc.sql, err)
+		}
+		if len(stream.sent) != tc.chunks {
+			t.Fatalf("%s: %d messages, want %d", tc.sql, len(stream.sent), tc.chunks)
+		}
+		checkChunks(t, stream.sent, tc.n)
+	}
+}
+
+func TestQueryStreamEmptyAndFailedQueries(t *testing.T) {
+	s := newStreamServer(t, 10)
+	stream := &fakeQueryStream{ctx: context.Background()}
+	if err := s.QueryStream(&queryRequest{SQL: "SELECT id FROM nums WHERE id > 10"}, stream); err != nil {
+		t.Fatal(err)
+	}
+	if len(stream.sent) != 1 || stream.sent[0].Count != 0 || stream.sent[0].Error != "" {
+		t.Fatalf("empty result sent %+v, want one empty message", stream.sent)
+	}
+
+	stream = &fakeQueryStream{ctx: context.Background()}
+	if err := s.QueryStream(&queryRequest{SQL: "SELEKT nothing"}, stream); err != nil {
+		t.Fatal(err)
+	}
+	if len(stream.sent) != 1 || stream.sent[0].Error == "" {
+		t.Fatalf("invalid SQL sent %+v, want one error message", stream.sent)
+	}
+}
+
+func TestQueryStreamStopsWhenClientCancels(t *testing.T) {
+	s := newStreamServer(t, 10_000)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// The client goes away after reading two chunks.
+	stream := &fakeQueryStream{ctx: ctx, onSend: func(sent int) {
+		if sent == 2 {
+			cancel()
+		}
+	}}
+	err := s.QueryStream(&queryRequest{SQL: "SELECT id FROM nums"}, stream)
+	if status.Code(err) != codes.Canceled {
+		t.Fatalf("err = %v, want Canceled", err)
+	}
+	if len(stream.sent) != 2 {
+		t.Fatalf("sent %d messages after the client left, want 2", len(stream.sent))
+	}
+}
+
+func TestQueryStreamConcurrent(t *testing.T) {
+	conn := serveGRPC(t, newStreamServer(t, 5000))
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	// Each stream reads a different number of rows; checkChunks may not run
+	// on these goroutines, so results are checked after they finish.
+	results := make([][]*queryResponse, 8)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sql := fmt.Sprintf("SELECT id FROM nums WHERE id <= %d ORDER BY id", (i+1)*600)
+			msgs, err := queryStream(ctx, conn, &queryRequest{SQL: sql})
+			if err != nil {
+				t.Errorf("%s: %v", sql, err)
+			}
+			results[i] = msgs
+		}()
+	}
+	wg.Wait()
+	for i, msgs := range results {
+		checkChunks(t, msgs, (i+1)*600)
+	}
+}
+
+func TestQueryStreamRequiresAuth(t *testing.T) {
+	s := newStreamServer(t, 10)
+	s.authToken = "secret"
+	conn := serveGRPC(t, s)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := queryStream(ctx, conn, &queryRequest{SQL: "SELECT id FROM nums"}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("err = %v, want Unauthenticated", err)
+	}
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer secret")
+	msgs, err := queryStream(ctx, conn, &queryRequest{SQL: "SELECT id FROM nums ORDER BY id"})
+	if err != nil {
+		t.Fatalf("authorized stream: %v", err)
+	}
+	checkChunks(t, msgs, 10)
+}
+
+// fakeQueryStream records the messages sent to it. onSend runs after
+// each message with the number sent so far.
+type fakeQueryStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	sent   []*queryResponse
+	onSend func(sent int)
+}
+
+func (f *fakeQueryStream) Context() context.Context { return f.ctx }
+
+func (f *fakeQueryStream) Send(m *queryResponse) error {
+	f.sent = append(f.sent, m)
+	if f.onSend != nil {
+		f.onSend(len(f.sent))
+	}
+	return nil
+}
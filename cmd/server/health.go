@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+// Health statuses reported by /healthz. "degraded" still answers 200 so a
+// load balancer keeps routing to a node whose local database works even if
+// a peer or the disk needs attention; only "fail" answers 503.
+const (
+	healthOK       = "ok"
+	healthDegraded = "degraded"
+	healthFail     = "fail"
+	healthSkipped  = "skipped"
+	healthLowDisk  = "low"
+
+	defaultHealthTimeout = 100 * time.Millisecond
+
+	// minHealthyFreeDiskBytes is the free-space floor below which a
+	// file-backed server reports its disk check as low (degraded).
+	minHealthyFreeDiskBytes uint64 = 64 << 20 // 64 MiB
+)
+
+// errDiskSpaceUnsupported is returned by freeDiskBytes where the platform
+// offers no free-space query; the disk check is then reported as skipped.
+var errDiskSpaceUnsupported = errors.New("disk space check not supported on this platform")
+
+type healthResponse struct {
+	Status string       `json:"status"`
+	Checks healthChecks `json:"checks"`
+}
+
+type healthChecks struct {
+	DB    string            `json:"db"`
+	Disk  string            `json:"disk"`
+	Peers map[string]string `json:"peers"`
+}
+
+// handleHealth probes the server's dependencies: the local database must
+// answer SELECT 1 within the health timeout (else "fail"), and a low disk
+// or an unreachable peer downgrades the result to "degraded".
+func (s *server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	resp := s.checkHealth(r.Context())
+	code := http.StatusOK
+	if resp.Status == healthFail {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, resp)
+}
+
+func (s *server) checkHealth(ctx context.Context) *healthResponse {
+	resp := &healthResponse{
+		Status: healthOK,
+		Checks: healthChecks{
+			DB:    s.probeDB(ctx),
+			Disk:  s.probeDisk(),
+			Peers: s.probePeers(ctx),
+		},
+	}
+	if resp.Checks.Disk != healthOK && resp.Checks.Disk != healthSkipped {
+		resp.Status = healthDegraded
+	}
+	for _, st := range resp.Checks.Peers {
+		if st != healthOK {
+			resp.Status = healthDegraded
+			break
+		}
+	}
+	if resp.Checks.DB != healthOK {
+		resp.Status = healthFail
+	}
+	return resp
+}
+
+func (s *server) healthTimeoutOrDefault() time.Duration {
+	if s.healthTimeout > 0 {
+		return s.healthTimeout
+	}
+	return defaultHealthTimeout
+}
+
+// probeDB runs SELECT 1 through the regular Query path, so a saturated
+// execution semaphore or a wedged engine shows up as a failed probe.
+func (s *server) probeDB(ctx context.Context) string {
+	ctx, cancel := context.WithTimeout(ctx, s.healthTimeoutOrDefault())
+	defer cancel()
+	resp, _ := s.Query(ctx, &queryRequest{Tenant: s.defaultT, SQL: "SELECT 1"})
+	if resp.Error != "" {
+		return resp.Error
+	}
+	return healthOK
+}
+
+// probeDisk checks free space under the storage path of a file-backed
+// database. In-memory databases have nothing to check.
+func (s *server) probeDisk() string {
+	cfg := s.db.Config()
+	if cfg == nil || cfg.Path == "" || cfg.Mode == storage.ModeMemory {
+		return healthSkipped
+	}
+	free, err := freeDiskBytes(cfg.Path)
+	if err != nil {
+		// The path may name a file that does not exist yet; fall back to
+		// its directory.
+		free, err = freeDiskBytes(filepath.Dir(cfg.Path))
+	}
+	if errors.Is(err, errDiskSpaceUnsupported) {
+		return healthSkipped
+	}
+	if err != nil {
+		return err.Error()
+	}
+	if free < minHealthyFreeDiskBytes {
+		return healthLowDisk
+	}
+	return healthOK
+}
+
+// probePeers pings every configured federation peer with SELECT 1 over
+// gRPC, bounded by the health timeout.
+func (s *server) probePeers(ctx context.Context) map[string]string {
+	out := make(map[string]string, len(s.peers))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, addr := range s.peers {
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			st := healthOK
+			if _, err := grpcQuery(ctx, addr, &queryRequest{Tenant: s.defaultT, SQL: "SELECT 1"}, s.authToken, s.healthTimeoutOrDefault(), *flagGRPCMaxRecv, s.peerDialCreds); err != nil {
+				st = err.Error()
+			}
+			mu.Lock()
+			out[addr] = st
+			mu.Unlock()
+		}(addr)
+	}
+	wg.Wait()
+	return out
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/SimonWaldherr/tinySQL/internal/engine"
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+const (
+	// envInitSQL holds a SQL script that is executed once at startup,
+	// before the HTTP and gRPC listeners accept requests.
+	envInitSQL = "TINYSQL_INIT_SQL"
+	// envInitFile names a SQL file executed at startup, before envInitSQL.
+	envInitFile = "TINYSQL_INIT_FILE"
+)
+
+// runInitSQL seeds db from TINYSQL_INIT_FILE and TINYSQL_INIT_SQL. Both are
+// optional; when both are set the file runs first so the inline script can
+// build on the schema it creates. The seed only runs while tenant has no
+// tables: a restarted file-backed server already holds the seeded schema,
+// and replaying its CREATE TABLE statements would abort the start.
+func runInitSQL(ctx context.Context, db *storage.DB, tenant string) error {
+	if os.Getenv(envInitFile) == "" && os.Getenv(envInitSQL) == "" {
+		return nil
+	}
+	if tables := db.ListTables(tenant); len(tables) > 0 {
+		slog.Info("skipping init SQL, database already has tables", "tenant", tenant, "tables", len(tables))
+		return nil
+	}
+	if path := os.Getenv(envInitFile); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", envInitFile, err)
+		}
+		if err := execInitScript(ctx, db, tenant, string(data)); err != nil {
+			return fmt.Errorf("%s: %w", envInitFile, err)
+		}
+	}
+	if script := os.Getenv(envInitSQL); script != "" {
+		if err := execInitScript(ctx, db, tenant, script); err != nil {
+			return fmt.Errorf("%s: %w", envInitSQL, err)
+		}
+	}
+	return nil
+}
+
+func execInitScript(ctx context.Context, db *storage.DB, tenant, script string) error {
+	for _, stmtSQL := range splitSQLStatements(script) {
+		stmt, err := engine.NewParser(stmtSQL).ParseStatement()
+		if err != nil {
+			return fmt.Errorf("parse %q: %w", truncateForLog(stmtSQL, 80), err)
+		}
+		if _, err := engine.Execute(ctx, db, tenant, stmt); err != nil {
+			return fmt.Errorf("execute %q: %w", truncateForLog(stmtSQL, 80), err)
+		}
+	}
+	return nil
+}
+
+// splitSQLStatements splits a script on semicolons outside quoted strings,
+// quoted identifiers and comments. A doubled quote inside a string does not
+// end the string. Comments are dropped, so `-- don't; skip` neither opens a
+// string nor ends a statement.
+func splitSQLStatements(script string) []string {
+	var stmts []string
+	var buf strings.Builder
+	flush := func() {
+		if stmt := strings.TrimSpace(buf.String()); stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+		buf.Reset()
+	}
+	for i := 0; i < len(script); i++ {
+		ch := script[i]
+		switch {
+		case ch == '\'' || ch == '"':
+			// Copy the quoted text through its closing quote.
+			j := i + 1
+			for ; j < len(script); j++ {
+				if script[j] == ch {
+					if j+1 < len(script) && script[j+1] == ch {
+						j++
+						continue
+					}
+					break
+				}
+			}
+			if j >= len(script) {
+				j = len(script) - 1
+			}
+			buf.WriteString(script[i : j+1])
+			i = j
+		case ch == '-' && i+1 < len(script) && script[i+1] == '-':
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				i = len(script)
+				continue
+			}
+			buf.WriteByte('\n')
+			i += end
+		case ch == '/' && i+1 < len(script) && script[i+1] == '*':
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				i = len(script)
+				continue
+			}
+			buf.WriteByte(' ')
+			i += end + 3
+		case ch == ';':
+			flush()
+		default:
+			buf.WriteByte(ch)
+		}
+	}
+	flush()
+	return stmts
+}
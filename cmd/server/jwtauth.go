@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// JWT bearer authentication for the HTTP data API (-auth-jwt-secret). Only
+// HS256 is accepted. Every token must name the tenant it may use; a token
+// with "admin": true may address any tenant.
+
+var (
+	errJWTMalformed = errors.New("malformed token")
+	errJWTSignature = errors.New("invalid token signature")
+	errJWTExpired   = errors.New("token expired")
+	errJWTNotYet    = errors.New("token not valid yet")
+	errJWTNoTenant  = errors.New("token has no tenant claim")
+)
+
+// jwtClaims are the claims the server reads; others are ignored.
+type jwtClaims struct {
+	Tenant    string   `json:"tenant"`
+	Admin     bool     `json:"admin,omitempty"`
+	ExpiresAt *float64 `json:"exp,omitempty"`
+	NotBefore *float64 `json:"nbf,omitempty"`
+}
+
+// parseHS256JWT verifies token against secret and returns its claims. exp
+// and nbf are optional and checked against now when present.
+func parseHS256JWT(token string, secret []byte, now time.Time) (*jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errJWTMalformed
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	// Checking alg first rejects "none" and algorithm-confusion tokens
+	// before the signature is even looked at.
+	if header.Alg != "HS256" {
+		return nil, fmt.Errorf("unsupported token algorithm %q", header.Alg)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errJWTMalformed
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return nil, errJWTSignature
+	}
+
+	var claims jwtClaims
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	unix := float64(now.Unix())
+	if claims.ExpiresAt != nil && unix >= *claims.ExpiresAt {
+		return nil, errJWTExpired
+	}
+	if claims.NotBefore != nil && unix < *claims.NotBefore {
+		return nil, errJWTNotYet
+	}
+	claims.Tenant = strings.TrimSpace(claims.Tenant)
+	if claims.Tenant == "" {
+		return nil, errJWTNoTenant
+	}
+	return &claims, nil
+}
+
+func decodeJWTSegment(seg string, v any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return errJWTMalformed
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return errJWTMalformed
+	}
+	return nil
+}
+
+type jwtClaimsContextKey struct{}
+
+func jwtClaimsFromContext(ctx context.Context) *jwtClaims {
+	c, _ := ctx.Value(jwtClaimsContextKey{}).(*jwtClaims)
+	return c
+}
+
+// withJWT requires a valid bearer JWT and stores its claims in r.Context()
+// for jwtTenant.
+func (s *server) withJWT(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := bearerToken(r.Header.Get("Authorization"))
+		if token == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="tinysql"`)
+			writeErrorJSON(w, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+		claims, err := parseHS256JWT(token, s.jwtSecret, time.Now())
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="tinysql", error="invalid_token"`)
+			writeErrorJSON(w, http.StatusUnauthorized, "unauthorized: "+err.Error())
+			return
+		}
+		h(w, r.WithContext(context.WithValue(r.Context(), jwtClaimsContextKey{}, claims)))
+	}
+}
+
+// withAPIAuth guards the data endpoints: with -auth-jwt-secret they need a
+// JWT, otherwise the static -auth token as before.
+func (s *server) withAPIAuth(h http.HandlerFunc) http.HandlerFunc {
+	if len(s.jwtSecret) > 0 {
+		return s.withJWT(h)
+	}
+	return s.withAuth(h)
+}
+
+// jwtTenant resolves the tenant of a request authenticated by withJWT: the
+// token's tenant when the request names none, the requested one when it
+// matches or the token is an admin token, and an error otherwise. Without
+// JWT claims the requested tenant passes through unchanged.
+func (s *server) jwtTenant(ctx context.Context, requested string) (string, error) {
+	claims := jwtClaimsFromContext(ctx)
+	if claims == nil {
+		return requested, nil
+	}
+	requested = strings.TrimSpace(requested)
+	switch {
+	case requested == "":
+		return claims.Tenant, nil
+	case claims.Admin || requested == claims.Tenant:
+		return requested, nil
+	}
+	return "", fmt.Errorf("token is not valid for tenant %q", requested)
+}
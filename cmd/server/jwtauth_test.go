@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SimonWaldherr/tinySQL/internal/engine"
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+const testJWTSecret = "test-secret"
+
+func signTestJWT(t *testing.T, alg string, claims map[string]any) string {
+	t.Helper()
+	enc := func(v any) string {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	unsigned := enc(map[string]string{"alg": alg, "typ": "JWT"}) + "." + enc(claims)
+	mac := hmac.New(sha256.New, []byte(testJWTSecret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newJWTTestServer(t *testing.T) http.Handler {
+	t.Helper()
+	db := storage.NewDB()
+	t.Cleanup(func() { _ = db.Close() })
+	s := &server{
+		db:           db,
+		cache:        engine.NewQueryCache(10),
+		defaultT:     "default",
+		jwtSecret:    []byte(testJWTSecret),
+		maxBodyBytes: defaultMaxBodyBytes,
+		metrics:      newMetricsRegistry(),
+	}
+	for _, tenant := range []string{"acme", "globex"} {
+		for _, sql := range []string{"CREATE TABLE t (name TEXT)", "INSERT INTO t VALUES ('" + tenant + "')"} {
+			if resp, _ := s.Exec(context.Background(), &execRequest{Tenant: tenant, SQL: sql}); !resp.Success {
+				t.Fatalf("%s: %s", sql, resp.Error)
+			}
+		}
+	}
+	return s.httpHandler()
+}
+
+func jwtRequest(t *testing.T, h http.Handler, path, token, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestJWTAuthGrantsTenantAccess(t *testing.T) {
+	h := newJWTTestServer(t)
+	token := signTestJWT(t, "HS256", map[string]any{"tenant": "acme", "exp": time.Now().Add(time.Hour).Unix()})
+
+	// Without a tenant in the body the token's tenant is used.
+	for _, body := range []string{`{"sql":"SELECT name FROM t"}`, `{"tenant":"acme","sql":"SELECT name FROM t"}`} {
+		w := jwtRequest(t, h, "/api/query", token, body)
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"name":"acme"`) {
+			t.Fatalf("%s: status %d: %s", body, w.Code, w.Body.String())
+		}
+	}
+	if w := jwtRequest(t, h, "/api/exec", token, `{"sql":"INSERT INTO t VALUES ('x')"}`); w.Code != http.StatusOK {
+		t.Fatalf("exec: status %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestJWTAuthRejectsBadTokens(t *testing.T) {
+	h := newJWTTestServer(t)
+	const body = `{"sql":"SELECT name FROM t"}`
+	tamperedClaims := strings.Split(signTestJWT(t, "HS256", map[string]any{"tenant": "acme"}), ".")
+	tamperedClaims[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"tenant":"acme","admin":true}`))
+
+	cases := []struct {
+		name, token string
+		want        int
+	}{
+		{"missing header", "", http.StatusUnauthorized},
+		{"expired", signTestJWT(t, "HS256", map[string]any{"tenant": "acme", "exp": time.Now().Add(-time.Minute).Unix()}), http.StatusUnauthorized},
+		{"not yet valid", signTestJWT(t, "HS256", map[string]any{"tenant": "acme", "nbf": time.Now().Add(time.Hour).Unix()}), http.StatusUnauthorized},
+		{"no tenant claim", signTestJWT(t, "HS256", map[string]any{"admin": true}), http.StatusUnauthorized},
+		{"alg none", signTestJWT(t, "none", map[string]any{"tenant": "acme"}), http.StatusUnauthorized},
+		{"tampered claims", strings.Join(tamperedClaims, "."), http.StatusUnauthorized},
+		{"garbage", "not-a-jwt", http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		if w := jwtRequest(t, h, "/api/query", tc.token, body); w.Code != tc.want {
+			t.Errorf("%s: status %d, want %d: %s", tc.name, w.Code, tc.want, w.Body.String())
+		}
+	}
+	if w := jwtRequest(t, h, "/api/query", "", body); w.Header().Get("WWW-Authenticate") == "" {
+		t.Error("401 without WWW-Authenticate")
+	}
+}
+
+func TestJWTAuthTenantIsolation(t *testing.T) {
+	h := newJWTTestServer(t)
+	acme := signTestJWT(t, "HS256", map[string]any{"tenant": "acme"})
+	if w := jwtRequest(t, h, "/api/query", acme, `{"tenant":"globex","sql":"SELECT name FROM t"}`); w.Code != http.StatusForbidden {
+		t.Fatalf("wrong tenant: status %d: %s", w.Code, w.Body.String())
+	}
+	if w := jwtRequest(t, h, "/api/exec", acme, `{"tenant":"globex","sql":"DROP TABLE t"}`); w.Code != http.StatusForbidden {
+		t.Fatalf("wrong tenant exec: status %d: %s", w.Code, w.Body.String())
+	}
+
+	admin := signTestJWT(t, "HS256", map[string]any{"tenant": "acme", "admin": true})
+	w := jwtRequest(t, h, "/api/query", admin, `{"tenant":"globex","sql":"SELECT name FROM t"}`)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"name":"globex"`) {
+		t.Fatalf("admin: status %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestJWTAuthLeavesProbesOpen(t *testing.T) {
+	h := newJWTTestServer(t)
+	for _, path := range []string{"/api/status", "/healthz"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code == http.StatusUnauthorized {
+			t.Errorf("%s requires a token", path)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SimonWaldherr/tinySQL/internal/engine"
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+
+	"golang.org/x/net/websocket"
+)
+
+// Live queries (/ws/query): the client opens a WebSocket and sends one
+// liveQueryRequest; the server re-runs the query every interval_ms and
+// sends the rows added and removed since the previous run. Only SELECT
+// statements are accepted. The first message holds every row as added. A
+// message is sent on every run, empty when nothing changed, so clients
+// also see that the query is still alive.
+
+const (
+	defaultLiveQueryInterval = time.Second
+	minLiveQueryInterval     = 100 * time.Millisecond
+)
+
+type liveQueryRequest struct {
+	SQL        string `json:"sql"`
+	Tenant     string `json:"tenant"`
+	IntervalMS int64  `json:"interval_ms"`
+}
+
+type liveQueryMessage struct {
+	Added   []map[string]any `json:"added"`
+	Removed []map[string]any `json:"removed"`
+	Error   string           `json:"error,omitempty"`
+}
+
+// handleLiveQuery upgrades the request to a WebSocket. Browsers attach
+// cookies and basic auth to cross-site WebSocket handshakes, so the
+// handshake also checks Origin (see checkLiveQueryOrigin).
+func (s *server) handleLiveQuery(w http.ResponseWriter, r *http.Request) {
+	websocket.Server{Handler: s.serveLiveQuery, Handshake: s.checkLiveQueryOrigin}.ServeHTTP(w, r)
+}
+
+// checkLiveQueryOrigin accepts a handshake without an Origin header (a
+// non-browser client), from the server's own host, or from an origin
+// listed in -ws-allowed-origins. Anything else is refused with 403.
+func (s *server) checkLiveQueryOrigin(config *websocket.Config, r *http.Request) error {
+	origin, err := websocket.Origin(config, r)
+	if err != nil {
+		return err
+	}
+	config.Origin = origin
+	if origin == nil || strings.EqualFold(origin.Host, r.Host) {
+		return nil
+	}
+	want := strings.TrimSuffix(strings.ToLower(origin.Scheme+"://"+origin.Host), "/")
+	for _, allowed := range s.wsOrigins {
+		if strings.EqualFold(strings.TrimSuffix(allowed, "/"), want) {
+			return nil
+		}
+	}
+	return fmt.Errorf("origin %s is not allowed", origin)
+}
+
+// serveLiveQuery runs one live query until the client disconnects or the
+// query fails.
+func (s *server) serveLiveQuery(ws *websocket.Conn) {
+	defer ws.Close()
+	// The HTTP server's read and write timeouts still apply to the hijacked
+	// connection and would cut every live query off after a few seconds.
+	_ = ws.SetDeadline(time.Time{})
+
+	var req liveQueryRequest
+	if err := websocket.JSON.Receive(ws, &req); err != nil {
+		_ = websocket.JSON.Send(ws, liveQueryMessage{Error: "invalid JSON: " + err.Error()})
+		return
+	}
+	tenant, err := s.jwtTenant(ws.Request().Context(), req.Tenant)
+	if err != nil {
+		_ = websocket.JSON.Send(ws, liveQueryMessage{Error: err.Error()})
+		return
+	}
+	if err := s.checkLiveQuerySQL(req.SQL); err != nil {
+		_ = websocket.JSON.Send(ws, liveQueryMessage{Error: err.Error()})
+		return
+	}
+	interval := time.Duration(req.IntervalMS) * time.Millisecond
+	switch {
+	case req.IntervalMS <= 0:
+		interval = defaultLiveQueryInterval
+	case interval < minLiveQueryInterval:
+		interval = minLiveQueryInterval
+	}
+
+	ctx, cancel := context.WithCancel(ws.Request().Context())
+	defer cancel()
+	// The client sends nothing after its request; a read returning means it
+	// closed the socket or went away; the request context of a hijacked
+	// connection is not cancelled when that happens.
+	go func() {
+		_, _ = io.Copy(io.Discard, ws)
+		cancel()
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var prev []map[string]any
+	for {
+		resp, _ := s.Query(ctx, &queryRequest{Tenant: tenant, SQL: req.SQL})
+		if ctx.Err() != nil {
+			return
+		}
+		if resp.Error != "" {
+			_ = websocket.JSON.Send(ws, liveQueryMessage{Error: resp.Error})
+			return
+		}
+		added, removed := diffRows(prev, resp.Rows)
+		msg := liveQueryMessage{Added: added, Removed: removed}
+		if msg.Added == nil {
+			msg.Added = []map[string]any{}
+		}
+		if msg.Removed == nil {
+			msg.Removed = []map[string]any{}
+		}
+		if err := websocket.JSON.Send(ws, msg); err != nil {
+			return
+		}
+		prev = resp.Rows
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkLiveQuerySQL rejects a live query that is not a single SELECT, so
+// a socket cannot re-run an INSERT or DELETE on every tick.
+func (s *server) checkLiveQuerySQL(sql string) error {
+	sqlText, err := s.normalizeSQL(sql)
+	if err != nil {
+		return err
+	}
+	stmt, err := engine.NewParser(sqlText).ParseStatement()
+	if err != nil {
+		return err
+	}
+	if _, ok := stmt.(*engine.Select); !ok {
+		return fmt.Errorf("live queries must be SELECT statements")
+	}
+	return nil
+}
+
+// diffRows compares two results of the same query as multisets of rows:
+// added are the rows of next that prev lacks, removed the rows of prev that
+// next lacks. Rows are matched by a hash of their JSON encoding, so a
+// changed row shows up as removed and added.
+func diffRows(prev, next []map[string]any) (added, removed []map[string]any) {
+	remaining := make(map[uint64]int, len(prev))
+	for _, r := range prev {
+		remaining[rowKey(r)]++
+	}
+	for _, r := range next {
+		k := rowKey(r)
+		if remaining[k] > 0 {
+			remaining[k]--
+			continue
+		}
+		added = append(added, r)
+	}
+	for _, r := range prev {
+		k := rowKey(r)
+		if remaining[k] > 0 {
+			remaining[k]--
+			removed = append(removed, r)
+		}
+	}
+	return added, removed
+}
+
+// rowKey hashes a row's JSON encoding, which lists columns in sorted order.
+func rowKey(row map[string]any) uint64 {
+	h := fnv.New64a()
+	b, _ := storage.JSONMarshal(row)
+	_, _ = h.Write(b)
+	return h.Sum64()
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SimonWaldherr/tinySQL/internal/engine"
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+
+	"golang.org/x/net/websocket"
+)
+
+func TestDiffRows(t *testing.T) {
+	row := func(id int, name string) map[string]any { return map[string]any{"id": id, "name": name} }
+	prev := []map[string]any{row(1, "a"), row(2, "b"), row(2, "b"), row(3, "c")}
+	next := []map[string]any{row(3, "c"), row(2, "b"), row(1, "z"), row(4, "d")}
+	added, removed := diffRows(prev, next)
+	if got := fmt.Sprint(added); got != fmt.Sprint([]map[string]any{row(1, "z"), row(4, "d")}) {
+		t.Errorf("added = %v", got)
+	}
+	// One of the duplicate rows is gone and the changed row is replaced.
+	if got := fmt.Sprint(removed); got != fmt.Sprint([]map[string]any{row(1, "a"), row(2, "b")}) {
+		t.Errorf("removed = %v", got)
+	}
+	if added, removed := diffRows(next, next); added != nil || removed != nil {
+		t.Errorf("unchanged result diff = %v, %v", added, removed)
+	}
+}
+
+// startLiveQueryServer serves the HTTP API with a table t of ids 1 and 2.
+func startLiveQueryServer(t *testing.T) (*server, string) {
+	t.Helper()
+	db := storage.NewDB()
+	t.Cleanup(func() { _ = db.Close() })
+	s := &server{db: db, cache: engine.NewQueryCache(10), defaultT: "default", metrics: newMetricsRegistry()}
+	for _, sql := range []string{"CREATE TABLE t (id INT)", "INSERT INTO t VALUES (1)", "INSERT INTO t VALUES (2)"} {
+		if resp, _ := s.Exec(context.Background(), &execRequest{SQL: sql}); !resp.Success {
+			t.Fatalf("%s: %s", sql, resp.Error)
+		}
+	}
+	ts := httptest.NewServer(s.httpHandler())
+	t.Cleanup(ts.Close)
+	return s, "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws/query"
+}
+
+// liveQueryOrigin is the Origin a page served by the test server sends.
+func liveQueryOrigin(url string) string {
+	return "http" + strings.TrimSuffix(strings.TrimPrefix(url, "ws"), "/ws/query")
+}
+
+func dialLiveQuery(t *testing.T, url, request string) *websocket.Conn {
+	t.Helper()
+	ws, err := websocket.Dial(url, "", liveQueryOrigin(url))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	if _, err := ws.Write([]byte(request)); err != nil {
+		t.Fatalf("send request: %v", err)
+	}
+	return ws
+}
+
+func receiveLive(t *testing.T, ws *websocket.Conn) liveQueryMessage {
+	t.Helper()
+	_ = ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var msg liveQueryMessage
+	if err := websocket.JSON.Receive(ws, &msg); err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	return msg
+}
+
+func ids(rows []map[string]any) string {
+	var out []string
+	for _, r := range rows {
+		out = append(out, fmt.Sprint(r["id"]))
+	}
+	return strings.Join(out, ",")
+}
+
+func TestLiveQueryStreamsDiffs(t *testing.T) {
+	s, url := startLiveQueryServer(t)
+	ws := dialLiveQuery(t, url, `{"sql":"SELECT id FROM t ORDER BY id","interval_ms":100}`)
+	defer ws.Close()
+
+	if msg := receiveLive(t, ws); ids(msg.Added) != "1,2" || len(msg.Removed) != 0 {
+		t.Fatalf("initial message = %+v, want every row added", msg)
+	}
+	if resp, _ := s.Exec(context.Background(), &execRequest{SQL: "INSERT INTO t VALUES (3)"}); !resp.Success {
+		t.Fatal(resp.Error)
+	}
+	waitForDiff := func() liveQueryMessage {
+		for {
+			if msg := receiveLive(t, ws); len(msg.Added)+len(msg.Removed) > 0 {
+				return msg
+			}
+		}
+	}
+	if msg := waitForDiff(); ids(msg.Added) != "3" || len(msg.Removed) != 0 {
+		t.Fatalf("after insert = %+v, want id 3 added", msg)
+	}
+	if resp, _ := s.Exec(context.Background(), &execRequest{SQL: "DELETE FROM t WHERE id = 1"}); !resp.Success {
+		t.Fatal(resp.Error)
+	}
+	if msg := waitForDiff(); ids(msg.Removed) != "1" || len(msg.Added) != 0 {
+		t.Fatalf("after delete = %+v, want id 1 removed", msg)
+	}
+}
+
+func TestLiveQueryInterval(t *testing.T) {
+	_, url := startLiveQueryServer(t)
+	for _, tc := range []struct {
+		intervalMS int
+		want       time.Duration
+	}{
+		{250, 250 * time.Millisecond},
+		{1, minLiveQueryInterval}, // raised to the minimum
+	} {
+		ws := dialLiveQuery(t, url, fmt.Sprintf(`{"sql":"SELECT id FROM t","interval_ms":%d}`, tc.intervalMS))
+		receiveLive(t, ws)
+		start := time.Now()
+		receiveLive(t, ws)
+		receiveLive(t, ws)
+		// Ticker jitter can shorten a single period slightly; two periods
+		// must not come in much under twice the interval.
+		if got := time.Since(start); got < 2*tc.want-20*time.Millisecond {
+			t.Errorf("interval_ms %d: two updates in %v, want at least %v", tc.intervalMS, got, 2*tc.want)
+		}
+		ws.Close()
+	}
+}
+
+func TestLiveQueryStopsWhenClientDisconnects(t *testing.T) {
+	_, url := startLiveQueryServer(t)
+	before := runtime.NumGoroutine()
+	ws := dialLiveQuery(t, url, `{"sql":"SELECT id FROM t","interval_ms":100}`)
+	receiveLive(t, ws)
+	if err := ws.Close(); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Fatalf("goroutines = %d after disconnect, want %d:\n%s", runtime.NumGoroutine(), before, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestLiveQueryInvalidSQL(t *testing.T) {
+	_, url := startLiveQueryServer(t)
+	for _, request := range []string{
+		`{"sql":"SELEKT nothing","interval_ms":100}`,
+		`{"sql":"","interval_ms":100}`,
+		`not json`,
+		`{"sql":"INSERT INTO t VALUES (9)","interval_ms":100}`,
+		`{"sql":"DELETE FROM t","interval_ms":100}`,
+	} {
+		ws := dialLiveQuery(t, url, request)
+		msg := receiveLive(t, ws)
+		if msg.Error == "" {
+			t.Errorf("%s: message = %+v, want an error", request, msg)
+		}
+		// The server closes the socket after reporting the error.
+		var next liveQueryMessage
+		_ = ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+		if err := websocket.JSON.Receive(ws, &next); err == nil {
+			t.Errorf("%s: socket still open, got %+v", request, next)
+		}
+		ws.Close()
+	}
+}
+
+func TestLiveQueryRejectsWrites(t *testing.T) {
+	s, url := startLiveQueryServer(t)
+	ws := dialLiveQuery(t, url, `{"sql":"DELETE FROM t","interval_ms":100}`)
+	defer ws.Close()
+	if msg := receiveLive(t, ws); !strings.Contains(msg.Error, "SELECT") {
+		t.Fatalf("message = %+v, want a SELECT-only error", msg)
+	}
+	resp, _ := s.Query(context.Background(), &queryRequest{SQL: "SELECT id FROM t"})
+	if len(resp.Rows) != 2 {
+		t.Fatalf("rows after rejected DELETE = %v, want both kept", resp.Rows)
+	}
+}
+
+func TestLiveQueryChecksOrigin(t *testing.T) {
+	s, url := startLiveQueryServer(t)
+	if ws, err := websocket.Dial(url, "", "https://evil.example"); err == nil {
+		ws.Close()
+		t.Fatal("cross-site handshake accepted")
+	}
+	s.wsOrigins = []string{"https://app.example.com"}
+	ws, err := websocket.Dial(url, "", "https://APP.example.com/")
+	if err != nil {
+		t.Fatalf("allow-listed origin refused: %v", err)
+	}
+	defer ws.Close()
+	if _, err := ws.Write([]byte(`{"sql":"SELECT id FROM t ORDER BY id"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if msg := receiveLive(t, ws); ids(msg.Added) != "1,2" {
+		t.Fatalf("message = %+v, want every row added", msg)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"crypto/subtle"
 	"crypto/tls"
@@ -9,8 +10,9 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"hash/fnv"
 	"io"
-	"log"
+	"log/slog"
 	"net"
 	"net/http"
 	"net/url"
@@ -27,13 +29,18 @@ import (
 
 	"github.com/SimonWaldherr/tinySQL/internal/engine"
 	"github.com/SimonWaldherr/tinySQL/internal/storage"
+	"github.com/SimonWaldherr/tinySQL/internal/telemetry"
 
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/health"
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
 )
 
@@ -74,19 +81,30 @@ const (
 	maxLogErrorLen = 200
 )
 
+// Federation modes accepted by -federation-mode.
+const (
+	federationBroadcast = "broadcast"
+	federationSharded   = "sharded"
+)
+
 // Flags
 var (
-	flagDSN            = flag.String("dsn", "mem://?tenant=default", "Storage DSN (mem:// or file:/path.db?tenant=...&autosave=1)")
-	flagHTTP           = flag.String("http", ":8080", "HTTP listen address (empty to disable)")
-	flagAuth           = flag.String("auth", "", "Authorization token for HTTP and gRPC (optional)")
-	flagGRPC           = flag.String("grpc", ":9090", "gRPC listen address (empty to disable)")
-	flagPeers          = flag.String("peers", "", "Comma-separated list of gRPC peer addresses for federation")
-	flagTenant         = flag.String("tenant", "default", "Default tenant if none provided in request")
-	flagTrustedProxies = flag.String("trusted-proxies", "", "Comma-separated trusted proxy CIDRs/IPs for X-Forwarded-For handling")
+	flagDSN               = flag.String("dsn", "mem://?tenant=default", "Storage DSN (mem:// or file:/path.db?tenant=...&autosave=1)")
+	flagHTTP              = flag.String("http", ":8080", "HTTP listen address (empty to disable)")
+	flagAuth              = flag.String("auth", "", "Authorization token for HTTP and gRPC (optional)")
+	flagAuthJWTSecret     = flag.String("auth-jwt-secret", "", "Require an HS256 JWT with a tenant claim on the HTTP data API (/api/exec, /api/query, /api/query/page, /api/cluster/status, /api/federated/query, /ws/query); overrides -auth there")
+	flagGRPC              = flag.String("grpc", ":9090", "gRPC listen address (empty to disable)")
+	flagPeers             = flag.String("peers", "", "Comma-separated list of gRPC peer addresses for federation")
+	flagFederationMode    = flag.String("federation-mode", federationBroadcast, "Federated query routing: broadcast (query local + all peers and merge) or sharded (route each tenant to its owning peer)")
+	flagReplicationFactor = flag.Int("replication-factor", 1, "In sharded federation mode, number of peers each tenant is routed to; the first successful response wins")
+	flagTenant            = flag.String("tenant", "default", "Default tenant if none provided in request")
+	flagTrustedProxies    = flag.String("trusted-proxies", "", "Comma-separated trusted proxy CIDRs/IPs for X-Forwarded-For handling")
+	flagWSAllowedOrigins  = flag.String("ws-allowed-origins", "", "Comma-separated origins (e.g. https://app.example.com) allowed to open /ws/query besides the server's own host")
 
 	flagRequestTimeout  = flag.Duration("request-timeout", defaultRequestTimeout, "Maximum time per SQL request")
 	flagPeerTimeout     = flag.Duration("peer-timeout", defaultPeerTimeout, "Maximum time per federated peer call")
 	flagShutdownTimeout = flag.Duration("shutdown-timeout", defaultShutdownTimeout, "Graceful shutdown timeout")
+	flagHealthTimeout   = flag.Duration("health-timeout", defaultHealthTimeout, "Maximum time for each /healthz dependency probe (local SELECT 1, peer pings)")
 
 	flagReadTimeout       = flag.Duration("http-read-timeout", defaultReadTimeout, "HTTP read timeout")
 	flagReadHeaderTimeout = flag.Duration("http-read-header-timeout", defaultReadHeaderTimeout, "HTTP read header timeout")
@@ -99,6 +117,8 @@ var (
 
 	flagMaxResponseRows  = flag.Int("max-response-rows", defaultMaxResponseRows, "Maximum rows returned in a query response before truncation (0 = unlimited); a federated query caps the combined total across all peers, not each source independently")
 	flagMaxResponseBytes = flag.Int64("max-response-bytes", defaultMaxResponseBytes, "Maximum approximate JSON-encoded size in bytes of a query response's rows before truncation (0 = unlimited)")
+	flagMaxQueryRows     = flag.Int("max-query-rows", 0, "Fail a statement whose result has more rows than this instead of truncating the response (0 = unlimited)")
+	flagMaxQueryMemory   = flag.Int64("max-query-memory-bytes", 0, "Fail a statement whose scan, join, filter or result row set is estimated to exceed this many bytes (0 = unlimited)")
 
 	flagGRPCMaxRecv = flag.Int("grpc-max-recv-bytes", defaultMaxGRPCMsgBytes, "Maximum gRPC request size in bytes")
 	flagGRPCMaxSend = flag.Int("grpc-max-send-bytes", defaultMaxGRPCMsgBytes, "Maximum gRPC response size in bytes")
@@ -119,7 +139,13 @@ var (
 	flagPeerTLSServerName = flag.String("peer-tls-server-name", "", "Optional server name override for federation peer TLS")
 	flagPeerTLSSkipVerify = flag.Bool("peer-tls-skip-verify", false, "Skip TLS certificate verification for federation peers (unsafe)")
 
-	flagVerbose = flag.Bool("v", false, "Verbose logging")
+	flagVerbose   = flag.Bool("v", false, "Verbose logging")
+	flagLogLevel  = flag.String("log-level", "info", "Minimum log level: debug, info, warn or error (debug adds one line per statement)")
+	flagLogFormat = flag.String("log-format", "text", "Log output format: text or json")
+
+	flagSlowQueryLog       = flag.String("slow-query-log", "", "Append statements slower than -slow-query-threshold to this file as JSON lines (empty to disable)")
+	flagTraceOutput        = flag.String("trace-output", "", "Write OpenTelemetry spans for HTTP requests and statements as JSON to this file (- for stdout; empty uses the global tracer provider, a no-op by default)")
+	flagSlowQueryThreshold = flag.Duration("slow-query-threshold", 500*time.Millisecond, "Minimum execution time logged by -slow-query-log (0 logs every statement)")
 )
 
 // HTTP types
@@ -165,6 +191,7 @@ func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(dat
 type TinySQLServer interface {
 	Exec(context.Context, *execRequest) (*execResponse, error)
 	Query(context.Context, *queryRequest) (*queryResponse, error)
+	QueryStream(*queryRequest, TinySQL_QueryStreamServer) error
 }
 
 func registerTinySQLServer(s *grpc.Server, srv TinySQLServer) {
@@ -175,7 +202,9 @@ func registerTinySQLServer(s *grpc.Server, srv TinySQLServer) {
 			{MethodName: "Exec", Handler: _TinySQL_Exec_Handler},
 			{MethodName: "Query", Handler: _TinySQL_Query_Handler},
 		},
-		Streams:  []grpc.StreamDesc{},
+		Streams: []grpc.StreamDesc{
+			{StreamName: "QueryStream", Handler: _TinySQL_QueryStream_Handler, ServerStreams: true},
+		},
 		Metadata: "tinysql", // informational
 	}, srv)
 }
@@ -210,27 +239,59 @@ func _TinySQL_Query_Handler(srv any, ctx context.Context, dec func(any) error, i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TinySQL_QueryStream_Handler(srv any, stream grpc.ServerStream) error {
+	in := new(queryRequest)
+	if err := stream.RecvMsg(in); err != nil {
+		return err
+	}
+	return srv.(TinySQLServer).QueryStream(in, &tinySQLQueryStreamServer{stream})
+}
+
+// TinySQL_QueryStreamServer is the server side of a QueryStream call.
+type TinySQL_QueryStreamServer interface {
+	Send(*queryResponse) error
+	grpc.ServerStream
+}
+
+type tinySQLQueryStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *tinySQLQueryStreamServer) Send(m *queryResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // server state
 type server struct {
 	db               *storage.DB
 	cache            *engine.QueryCache
 	peers            []string
+	federationMode   string
+	replicationN     int
 	defaultT         string
 	authToken        string
+	jwtSecret        []byte // HS256 key for the HTTP data API; nil = -auth token
 	trustedProxies   []*net.IPNet
+	wsOrigins        []string // extra Origins accepted by /ws/query
 	peerDialCreds    credentials.TransportCredentials
 	requestTimeout   time.Duration
 	peerTimeout      time.Duration
+	healthTimeout    time.Duration
 	maxBodyBytes     int64
 	maxSQLBytes      int
 	maxResponseRows  int
 	maxResponseBytes int64
+	queryLimits      engine.QueryOptions        // per-statement engine limits; zero = unlimited
+	slowLog          *telemetry.SlowQueryLogger // nil unless -slow-query-log is set
+	tracing          trace.TracerProvider       // nil = otel global provider
+	log              *slog.Logger               // nil = slog.Default()
 	verbose          bool
 	analytics        bool
 	startedAt        time.Time
 	ready            atomic.Bool
 	metrics          *metricsRegistry
-	execSem          chan struct{} // bounded concurrency for Exec/Query; nil = unlimited
+	grpcHealth       *health.Server // nil until startGRPCServer registers it
+	execSem          chan struct{}  // bounded concurrency for Exec/Query; nil = unlimited
 }
 
 func newServer(db *storage.DB, defaultTenant, authToken string, peers []string, trustedProxies []*net.IPNet, peerDialCreds credentials.TransportCredentials) *server {
@@ -238,16 +299,22 @@ func newServer(db *storage.DB, defaultTenant, authToken string, peers []string,
 		db:               db,
 		cache:            engine.NewQueryCache(200),
 		peers:            peers,
+		federationMode:   *flagFederationMode,
+		replicationN:     *flagReplicationFactor,
 		defaultT:         defaultTenant,
 		authToken:        strings.TrimSpace(authToken),
+		jwtSecret:        []byte(*flagAuthJWTSecret),
 		trustedProxies:   trustedProxies,
+		wsOrigins:        parsePeerList(*flagWSAllowedOrigins),
 		peerDialCreds:    peerDialCreds,
 		requestTimeout:   *flagRequestTimeout,
 		peerTimeout:      *flagPeerTimeout,
+		healthTimeout:    *flagHealthTimeout,
 		maxBodyBytes:     *flagMaxBodyBytes,
 		maxSQLBytes:      *flagMaxSQLBytes,
 		maxResponseRows:  *flagMaxResponseRows,
 		maxResponseBytes: *flagMaxResponseBytes,
+		queryLimits:      engine.QueryOptions{MaxRows: *flagMaxQueryRows, MaxMemoryBytes: *flagMaxQueryMemory},
 		verbose:          *flagVerbose,
 		analytics:        *flagAnalytics,
 		startedAt:        time.Now(),
@@ -382,42 +449,17 @@ func (s *server) withAuth(h http.HandlerFunc) http.HandlerFunc {
 func (s *server) grpcUnaryInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
 		start := time.Now()
-		statusCode := codes.OK
+		ctx = grpcRequestID(ctx)
 		defer func() {
 			if rec := recover(); rec != nil {
-				log.Printf("panic in gRPC %s: %v", info.FullMethod, rec)
+				s.logger().ErrorContext(ctx, "grpc panic", "method", info.FullMethod, "panic", fmt.Sprint(rec))
 				err = status.Error(codes.Internal, "internal server error")
 			}
-			statusCode = status.Code(err)
-			s.metrics.Observe("grpc", info.FullMethod, "UNARY", int(statusCode), time.Since(start))
-			if s.verbose {
-				log.Printf("grpc method=%s status=%s duration=%s", info.FullMethod, statusCode.String(), time.Since(start))
-			}
-			// Always log failures, regardless of -v, so operators aren't blind
-			// to errors by default. Only the status/error class and a bounded
-			// error string are logged here -- never SQL text or parameters.
-			if statusCode != codes.OK {
-				errMsg := ""
-				if err != nil {
-					errMsg = truncateForLog(err.Error(), maxLogErrorLen)
-				}
-				log.Printf("grpc FAILED method=%s status=%s error=%q", info.FullMethod, statusCode.String(), errMsg)
-			}
+			s.observeGRPC(ctx, info.FullMethod, "UNARY", start, err)
 		}()
 
-		if s.authToken != "" {
-			md, ok := metadata.FromIncomingContext(ctx)
-			if !ok {
-				return nil, status.Error(codes.Unauthenticated, "missing authorization metadata")
-			}
-			vals := md.Get("authorization")
-			token := ""
-			if len(vals) > 0 {
-				token = bearerToken(vals[0])
-			}
-			if !s.isAuthorized(token) {
-				return nil, status.Error(codes.Unauthenticated, "unauthorized")
-			}
+		if err := s.grpcAuthorize(ctx, info.FullMethod); err != nil {
+			return nil, err
 		}
 
 		reqCtx, cancel := s.withRequestTimeout(ctx)
@@ -426,11 +468,87 @@ func (s *server) grpcUnaryInterceptor() grpc.UnaryServerInterceptor {
 	}
 }
 
+// grpcStreamInterceptor is grpcUnaryInterceptor for streaming RPCs: request
+// ID, authentication, the request timeout, panic recovery, metrics and the
+// failure log.
+func (s *server) grpcStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		start := time.Now()
+		ctx := grpcRequestID(ss.Context())
+		defer func() {
+			if rec := recover(); rec != nil {
+				s.logger().ErrorContext(ctx, "grpc panic", "method", info.FullMethod, "panic", fmt.Sprint(rec))
+				err = status.Error(codes.Internal, "internal server error")
+			}
+			s.observeGRPC(ctx, info.FullMethod, "STREAM", start, err)
+		}()
+
+		if err := s.grpcAuthorize(ctx, info.FullMethod); err != nil {
+			return err
+		}
+
+		reqCtx, cancel := s.withRequestTimeout(ctx)
+		defer cancel()
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: reqCtx})
+	}
+}
+
+// contextServerStream replaces a stream's context.
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (c *contextServerStream) Context() context.Context { return c.ctx }
+
+// grpcAuthorize checks the -auth token in the call's authorization
+// metadata. Health checks stay unauthenticated, matching the HTTP /healthz
+// and /readyz probes, and so does reflection, which only lists services.
+func (s *server) grpcAuthorize(ctx context.Context, fullMethod string) error {
+	if s.authToken == "" ||
+		strings.HasPrefix(fullMethod, "/"+healthgrpc.Health_ServiceDesc.ServiceName+"/") ||
+		strings.HasPrefix(fullMethod, "/grpc.reflection.") {
+		return nil
+	}
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "missing authorization metadata")
+	}
+	vals := md.Get("authorization")
+	token := ""
+	if len(vals) > 0 {
+		token = bearerToken(vals[0])
+	}
+	if !s.isAuthorized(token) {
+		return status.Error(codes.Unauthenticated, "unauthorized")
+	}
+	return nil
+}
+
+// observeGRPC records a finished call in the metrics and logs it.
+func (s *server) observeGRPC(ctx context.Context, method, kind string, start time.Time, err error) {
+	statusCode := status.Code(err)
+	s.metrics.Observe("grpc", method, kind, int(statusCode), time.Since(start))
+	if s.verbose {
+		s.logger().InfoContext(ctx, "grpc request", "method", method, "status", statusCode.String(), "duration", time.Since(start))
+	}
+	// Always log failures, regardless of -v, so operators aren't blind
+	// to errors by default. Only the status/error class and a bounded
+	// error string are logged here -- never SQL text or parameters.
+	if statusCode != codes.OK {
+		errMsg := ""
+		if err != nil {
+			errMsg = truncateForLog(err.Error(), maxLogErrorLen)
+		}
+		s.logger().WarnContext(ctx, "grpc request failed", "method", method, "status", statusCode.String(), "error", errMsg)
+	}
+}
+
 func (s *server) recoverMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if rec := recover(); rec != nil {
-				log.Printf("panic in HTTP %s %s: %v", r.Method, r.URL.Path, rec)
+				s.logger().ErrorContext(r.Context(), "http panic", "method", r.Method, "path", r.URL.Path, "panic", fmt.Sprint(rec))
 				writeErrorJSON(w, http.StatusInternalServerError, "internal server error")
 			}
 		}()
@@ -448,6 +566,16 @@ func (s *statusRecorder) WriteHeader(code int) {
 	s.ResponseWriter.WriteHeader(code)
 }
 
+// Hijack lets the WebSocket endpoint take over the connection through
+// instrumentHTTP.
+func (s *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(s.ResponseWriter).Hijack()
+	if err == nil {
+		s.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
 func parseIP(raw string) net.IP {
 	raw = strings.TrimSpace(raw)
 	if raw == "" {
@@ -519,13 +647,13 @@ func (s *server) instrumentHTTP(route string, h http.HandlerFunc) http.HandlerFu
 
 		s.metrics.Observe("http", route, r.Method, rec.status, dur)
 		if s.verbose {
-			log.Printf("http route=%s method=%s status=%d duration=%s client_ip=%s", route, r.Method, rec.status, dur, s.clientIPFromRequest(r))
+			s.logger().InfoContext(r.Context(), "http request", "route", route, "method", r.Method, "status", rec.status, "duration", dur, "client_ip", s.clientIPFromRequest(r))
 		}
 		// Always log non-2xx responses, regardless of -v, so failures aren't
 		// silent by default. Only route/method/status/error-class are logged
 		// here -- never request bodies, SQL text, or parameter values.
-		if rec.status < 200 || rec.status >= 300 {
-			log.Printf("http FAILED route=%s method=%s status=%d class=%s", route, r.Method, rec.status, httpErrorClass(rec.status))
+		if (rec.status < 200 && rec.status != http.StatusSwitchingProtocols) || rec.status >= 300 {
+			s.logger().WarnContext(r.Context(), "http request failed", "route", route, "method", r.Method, "status", rec.status, "class", httpErrorClass(rec.status))
 		}
 	}
 }
@@ -582,7 +710,9 @@ func (s *server) Exec(ctx context.Context, req *execRequest) (*execResponse, err
 	}
 	defer release()
 
-	_, err = engine.Execute(ctx, s.db, tenant, stmt)
+	execStart := time.Now()
+	_, err = engine.ExecuteWith(engine.WithAuditText(ctx, sqlText), s.db, tenant, stmt, engine.WithQueryOptions(s.queryLimits), engine.WithTracer(s.tracer()))
+	s.logSlowQuery(sqlText, tenant, execStart, 0, err)
 	if err != nil {
 		return &execResponse{Success: false, Error: err.Error(), Duration: time.Since(start).String()}, nil
 	}
@@ -591,61 +721,104 @@ func (s *server) Exec(ctx context.Context, req *execRequest) (*execResponse, err
 
 func (s *server) Query(ctx context.Context, req *queryRequest) (*queryResponse, error) {
 	start := time.Now()
+	sqlText, rs, err := s.runQuery(ctx, req)
+	if err != nil {
+		return &queryResponse{SQL: sqlText, Error: err.Error(), Duration: time.Since(start).String()}, nil
+	}
+
+	var rows []map[string]any
+	cols := queryColumns(rs)
+	if len(cols) > 0 {
+		rows = make([]map[string]any, 0, len(rs.Rows))
+		for _, r := range rs.Rows {
+			rows = append(rows, copyRow(r))
+		}
+	}
+
+	rows, truncated := truncateRows(rows, s.maxResponseRows, s.maxResponseBytes)
+	return &queryResponse{
+		SQL:       sqlText,
+		Columns:   cols,
+		Rows:      rows,
+		Duration:  time.Since(start).String(),
+		Count:     len(rows),
+		Truncated: truncated,
+	}, nil
+}
+
+// runQuery parses and executes a query request. The returned SQL is the
+// normalized text once it could be normalized, otherwise the request's.
+func (s *server) runQuery(ctx context.Context, req *queryRequest) (string, *engine.ResultSet, error) {
 	tenant := s.tenantOrDefault(req.Tenant)
 	sqlText, err := s.normalizeSQL(req.SQL)
 	if err != nil {
-		return &queryResponse{SQL: req.SQL, Error: err.Error(), Duration: time.Since(start).String()}, nil
+		return req.SQL, nil, err
 	}
 
 	ctx, cancel, err := s.withRequestTimeoutOverride(ctx, req.TimeoutMS)
 	if err != nil {
-		return &queryResponse{SQL: req.SQL, Error: err.Error(), Duration: time.Since(start).String()}, nil
+		return req.SQL, nil, err
 	}
 	defer cancel()
 
 	compiled, err := s.cache.Compile(sqlText)
 	if err != nil {
-		return &queryResponse{SQL: sqlText, Error: err.Error(), Duration: time.Since(start).String()}, nil
+		return sqlText, nil, err
 	}
 
 	release, err := s.acquireExecSlot(ctx)
 	if err != nil {
-		return &queryResponse{SQL: sqlText, Error: err.Error(), Duration: time.Since(start).String()}, nil
+		return sqlText, nil, err
 	}
 	defer release()
 
-	rs, err := compiled.Execute(ctx, s.db, tenant)
-	if err != nil {
-		return &queryResponse{SQL: sqlText, Error: err.Error(), Duration: time.Since(start).String()}, nil
+	execStart := time.Now()
+	rs, err := engine.ExecuteWith(engine.WithAuditText(ctx, sqlText), s.db, tenant, compiled.Statement, engine.WithQueryOptions(s.queryLimits), engine.WithTracer(s.tracer()))
+	rowCount := 0
+	if rs != nil {
+		rowCount = len(rs.Rows)
 	}
+	s.logSlowQuery(sqlText, tenant, execStart, rowCount, err)
+	return sqlText, rs, err
+}
 
-	var cols []string
-	var rows []map[string]any
-	if rs != nil && len(rs.Rows) > 0 {
-		for c := range rs.Rows[0] {
-			cols = append(cols, c)
-		}
-		sort.Strings(cols)
+// queryColumns returns the sorted column names of the first row, or nil
+// for an empty result.
+func queryColumns(rs *engine.ResultSet) []string {
+	if rs == nil || len(rs.Rows) == 0 {
+		return nil
+	}
+	cols := make([]string, 0, len(rs.Rows[0]))
+	for c := range rs.Rows[0] {
+		cols = append(cols, c)
+	}
+	sort.Strings(cols)
+	return cols
+}
 
-		rows = make([]map[string]any, 0, len(rs.Rows))
-		for _, r := range rs.Rows {
-			m := make(map[string]any, len(r))
-			for k, v := range r {
-				m[k] = v
-			}
-			rows = append(rows, m)
-		}
+func copyRow(r engine.Row) map[string]any {
+	m := make(map[string]any, len(r))
+	for k, v := range r {
+		m[k] = v
 	}
+	return m
+}
 
-	rows, truncated := truncateRows(rows, s.maxResponseRows, s.maxResponseBytes)
-	return &queryResponse{
-		SQL:       sqlText,
-		Columns:   cols,
-		Rows:      rows,
-		Duration:  time.Since(start).String(),
-		Count:     len(rows),
-		Truncated: truncated,
-	}, nil
+// logger returns the server's logger, the process default unless a test
+// installed its own.
+func (s *server) logger() *slog.Logger {
+	if s.log != nil {
+		return s.log
+	}
+	return slog.Default()
+}
+
+// logSlowQuery hands a finished statement to the slow query log. A write
+// failure is reported but never fails the request.
+func (s *server) logSlowQuery(sqlText, tenant string, started time.Time, rows int, execErr error) {
+	if err := s.slowLog.Record(sqlText, tenant, started, rows, execErr); err != nil {
+		s.logger().Error("slow query log write failed", "error", err)
+	}
 }
 
 // truncateRows caps rows to at most maxRows entries and/or an approximate
@@ -709,6 +882,12 @@ func (s *server) handleExec(w http.ResponseWriter, r *http.Request) {
 		writeErrorJSON(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
 		return
 	}
+	tenant, err := s.jwtTenant(r.Context(), req.Tenant)
+	if err != nil {
+		writeErrorJSON(w, http.StatusForbidden, err.Error())
+		return
+	}
+	req.Tenant = tenant
 
 	resp, _ := s.Exec(r.Context(), &req)
 	if !resp.Success {
@@ -729,6 +908,12 @@ func (s *server) handleQuery(w http.ResponseWriter, r *http.Request) {
 		writeErrorJSON(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
 		return
 	}
+	tenant, err := s.jwtTenant(r.Context(), req.Tenant)
+	if err != nil {
+		writeErrorJSON(w, http.StatusForbidden, err.Error())
+		return
+	}
+	req.Tenant = tenant
 
 	resp, _ := s.Query(r.Context(), &req)
 	if resp.Error != "" {
@@ -738,10 +923,6 @@ func (s *server) handleQuery(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, resp)
 }
 
-func (s *server) handleHealth(w http.ResponseWriter, _ *http.Request) {
-	writeJSON(w, http.StatusOK, map[string]any{"ok": true})
-}
-
 func (s *server) handleReady(w http.ResponseWriter, _ *http.Request) {
 	if !s.ready.Load() {
 		writeErrorJSON(w, http.StatusServiceUnavailable, "server not ready")
@@ -886,6 +1067,12 @@ func (s *server) handleFederatedQuery(w http.ResponseWriter, r *http.Request) {
 		writeErrorJSON(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
 		return
 	}
+	tenant, err := s.jwtTenant(r.Context(), req.Tenant)
+	if err != nil {
+		writeErrorJSON(w, http.StatusForbidden, err.Error())
+		return
+	}
+	req.Tenant = tenant
 
 	peerTimeout, err := s.peerTimeoutOverride(req.PeerTimeoutMS)
 	if err != nil {
@@ -893,6 +1080,11 @@ func (s *server) handleFederatedQuery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if s.federationMode == federationSharded {
+		s.handleShardedQuery(w, r, &req, peerTimeout)
+		return
+	}
+
 	type peerRes struct {
 		rows      []map[string]any
 		cols      []string
@@ -941,7 +1133,7 @@ func (s *server) handleFederatedQuery(w http.ResponseWriter, r *http.Request) {
 	for res := range ch {
 		if res.err != nil {
 			if s.verbose {
-				log.Printf("federation peer error: %v", res.err)
+				s.logger().WarnContext(r.Context(), "federation peer error", "error", res.err)
 			}
 			continue
 		}
@@ -950,7 +1142,7 @@ func (s *server) handleFederatedQuery(w http.ResponseWriter, r *http.Request) {
 		}
 		if len(cols) > 0 && !sameColumns(cols, res.cols) {
 			if s.verbose {
-				log.Printf("federation peer columns mismatch: local=%v peer=%v", cols, res.cols)
+				s.logger().WarnContext(r.Context(), "federation peer columns mismatch", "local", cols, "peer", res.cols)
 			}
 			continue
 		}
@@ -979,6 +1171,91 @@ func (s *server) handleFederatedQuery(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleShardedQuery serves a federated query in sharded mode: the tenant's
+// owning peers (see peersForTenant) are queried concurrently and the first
+// successful response is returned. The local node is not consulted, since in
+// sharded mode a tenant's data lives only on the peers it hashes to.
+func (s *server) handleShardedQuery(w http.ResponseWriter, r *http.Request, req *queryRequest, peerTimeout time.Duration) {
+	tenant := s.tenantOrDefault(req.Tenant)
+	targets := s.peersForTenant(tenant)
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	type peerRes struct {
+		out *queryResponse
+		err error
+	}
+	ch := make(chan peerRes, len(targets))
+	for _, addr := range targets {
+		go func(addr string) {
+			out, err := grpcQuery(ctx, addr, &queryRequest{Tenant: tenant, SQL: req.SQL, TimeoutMS: req.TimeoutMS}, s.authToken, peerTimeout, *flagGRPCMaxRecv, s.peerDialCreds)
+			ch <- peerRes{out: out, err: err}
+		}(addr)
+	}
+
+	var errs []error
+	for range targets {
+		res := <-ch
+		if res.err != nil {
+			if s.verbose {
+				s.logger().WarnContext(r.Context(), "federation peer error", "error", res.err)
+			}
+			errs = append(errs, res.err)
+			continue
+		}
+		// First success wins; cancel the remaining replicas.
+		cancel()
+		rows, truncated := truncateRows(res.out.Rows, s.maxResponseRows, s.maxResponseBytes)
+		writeJSON(w, http.StatusOK, &queryResponse{
+			SQL:       req.SQL,
+			Columns:   res.out.Columns,
+			Rows:      rows,
+			Duration:  res.out.Duration,
+			Count:     len(rows),
+			Truncated: truncated || res.out.Truncated,
+		})
+		return
+	}
+	writeErrorJSON(w, http.StatusBadGateway, "all peers failed for tenant "+tenant+": "+errors.Join(errs...).Error())
+}
+
+// peersForTenant returns the peers a tenant is routed to in sharded mode:
+// the peer at FNV64a(tenant) % len(peers) followed by the next
+// replicationN-1 peers in list order, so replicas of a tenant never land on
+// the same peer twice.
+func (s *server) peersForTenant(tenant string) []string {
+	if len(s.peers) == 0 {
+		return nil
+	}
+	n := s.replicationN
+	if n < 1 {
+		n = 1
+	}
+	if n > len(s.peers) {
+		n = len(s.peers)
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(tenant))
+	start := int(h.Sum64() % uint64(len(s.peers)))
+	out := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		out = append(out, s.peers[(start+i)%len(s.peers)])
+	}
+	return out
+}
+
+func parseFederationMode(raw string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", federationBroadcast:
+		return federationBroadcast, nil
+	case federationSharded:
+		return federationSharded, nil
+	default:
+		return "", fmt.Errorf("invalid -federation-mode %q (expected broadcast or sharded)", raw)
+	}
+}
+
 func equalStringSlices(a, b []string) bool {
 	if len(a) != len(b) {
 		return false
@@ -995,7 +1272,7 @@ func writeJSON(w http.ResponseWriter, statusCode int, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 	if err := json.NewEncoder(w).Encode(v); err != nil {
-		log.Printf("json encode response failed: %v", err)
+		slog.Error("json encode response failed", "error", err)
 	}
 }
 
@@ -1034,6 +1311,7 @@ func grpcQuery(ctx context.Context, addr string, req *queryRequest, authToken st
 	if authToken != "" {
 		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+authToken)
 	}
+	ctx = outgoingRequestID(ctx)
 
 	var resp queryResponse
 	if err := conn.Invoke(ctx, "/tinysql.TinySQL/Query", req, &resp); err != nil {
@@ -1414,6 +1692,12 @@ func openFileDBFromDSN(dsn string) (*storage.DB, string, error) {
 func run() error {
 	flag.Parse()
 
+	logger, err := telemetry.NewLogger(os.Stderr, *flagLogLevel, *flagLogFormat)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+
 	httpAddr, grpcAddr, minTLSVersion, trustedProxies, err := parseRunConfig()
 	if err != nil {
 		return err
@@ -1435,15 +1719,47 @@ func run() error {
 
 	tenant := resolveRunTenant(dsnTenant)
 
+	if err := runInitSQL(context.Background(), db, tenant); err != nil {
+		_ = db.Close()
+		return err
+	}
+
 	peerDialCreds, err := buildRunPeerDialCreds(minTLSVersion)
 	if err != nil {
 		_ = db.Close()
 		return err
 	}
 
-	warnIfUnauthenticatedAndExposed(*flagAuth, httpAddr, grpcAddr)
+	sqlHTTPAddr := httpAddr
+	if *flagAuthJWTSecret != "" {
+		// The HTTP endpoints that run SQL require a JWT.
+		sqlHTTPAddr = ""
+	}
+	warnIfUnauthenticatedAndExposed(*flagAuth, sqlHTTPAddr, grpcAddr)
+
+	shutdownTracing, err := setupTracing(*flagTraceOutput)
+	if err != nil {
+		_ = db.Close()
+		return fmt.Errorf("set up tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), *flagShutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("tracing shutdown failed", "error", err)
+		}
+	}()
 
 	srv := newServer(db, tenant, *flagAuth, parsePeerList(*flagPeers), trustedProxies, peerDialCreds)
+	if *flagSlowQueryLog != "" {
+		slowLog, err := telemetry.OpenSlowQueryLog(*flagSlowQueryLog, *flagSlowQueryThreshold)
+		if err != nil {
+			_ = db.Close()
+			return fmt.Errorf("open slow query log: %w", err)
+		}
+		defer slowLog.Close()
+		srv.slowLog = slowLog
+	}
 	encoding.RegisterCodec(jsonCodec{})
 
 	errChan := make(chan error, 2)
@@ -1462,7 +1778,7 @@ func run() error {
 
 	srv.ready.Store(false)
 
-	shutdownErr := shutdownRunServers(httpSrv, grpcSrv, db)
+	shutdownErr := shutdownRunServers(httpSrv, grpcSrv, srv.grpcHealth, db)
 
 	if runErr != nil {
 		return runErr
@@ -1495,10 +1811,10 @@ func warnIfUnauthenticatedAndExposed(authToken, httpAddr, grpcAddr string) {
 	if len(exposed) == 0 {
 		return
 	}
-	log.Printf("WARNING: no -auth token configured, and %s is bound to a non-loopback address — "+
+	slog.Warn("no -auth token configured on a non-loopback address — "+
 		"anyone who can reach this host can run arbitrary SQL against the database with no authentication. "+
 		"Set -auth to a token, or bind to 127.0.0.1 if this is not intentional.",
-		strings.Join(exposed, " and "))
+		"listeners", strings.Join(exposed, " and "))
 }
 
 // isLoopbackListenAddr reports whether a "host:port" listen address (as
@@ -1542,6 +1858,14 @@ func parseRunConfig() (string, string, uint16, []*net.IPNet, error) {
 	if err != nil {
 		return "", "", 0, nil, err
 	}
+	mode, err := parseFederationMode(*flagFederationMode)
+	if err != nil {
+		return "", "", 0, nil, err
+	}
+	*flagFederationMode = mode
+	if *flagReplicationFactor < 1 {
+		return "", "", 0, nil, fmt.Errorf("-replication-factor must be >= 1")
+	}
 	return httpAddr, grpcAddr, minTLSVersion, trustedProxies, nil
 }
 
@@ -1584,19 +1908,27 @@ func buildRunPeerDialCreds(minTLSVersion uint16) (credentials.TransportCredentia
 	)
 }
 
+// httpHandler routes the HTTP API behind request IDs, panic recovery and
+// tracing.
+func (s *server) httpHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/exec", s.instrumentHTTP("/api/exec", s.withAPIAuth(s.handleExec)))
+	mux.HandleFunc("/api/query", s.instrumentHTTP("/api/query", s.withAPIAuth(s.handleQuery)))
+	mux.HandleFunc("/api/query/page", s.instrumentHTTP("/api/query/page", s.withAPIAuth(s.handleQueryPage)))
+	mux.HandleFunc("/api/status", s.instrumentHTTP("/api/status", s.withAuth(s.handleStatus)))
+	mux.HandleFunc("/api/cluster/status", s.instrumentHTTP("/api/cluster/status", s.withAPIAuth(s.handleClusterStatus)))
+	mux.HandleFunc("/api/federated/query", s.instrumentHTTP("/api/federated/query", s.withAPIAuth(s.handleFederatedQuery)))
+	mux.HandleFunc("/ws/query", s.instrumentHTTP("/ws/query", s.withAPIAuth(s.handleLiveQuery)))
+	mux.HandleFunc("/metrics", s.instrumentHTTP("/metrics", s.withAuth(s.handleMetrics)))
+	mux.HandleFunc("/healthz", s.instrumentHTTP("/healthz", s.handleHealth))
+	mux.HandleFunc("/readyz", s.instrumentHTTP("/readyz", s.handleReady))
+	return s.withRequestID(s.recoverMiddleware(s.traceHTTP(mux)))
+}
+
 func startHTTPServer(srv *server, db *storage.DB, httpAddr string, minTLSVersion uint16, errChan chan<- error) (*http.Server, error) {
 	if httpAddr == "" {
 		return nil, nil
 	}
-	mux := http.NewServeMux()
-	mux.HandleFunc("/api/exec", srv.instrumentHTTP("/api/exec", srv.withAuth(srv.handleExec)))
-	mux.HandleFunc("/api/query", srv.instrumentHTTP("/api/query", srv.withAuth(srv.handleQuery)))
-	mux.HandleFunc("/api/status", srv.instrumentHTTP("/api/status", srv.withAuth(srv.handleStatus)))
-	mux.HandleFunc("/api/cluster/status", srv.instrumentHTTP("/api/cluster/status", srv.withAuth(srv.handleClusterStatus)))
-	mux.HandleFunc("/api/federated/query", srv.instrumentHTTP("/api/federated/query", srv.withAuth(srv.handleFederatedQuery)))
-	mux.HandleFunc("/metrics", srv.instrumentHTTP("/metrics", srv.withAuth(srv.handleMetrics)))
-	mux.HandleFunc("/healthz", srv.instrumentHTTP("/healthz", srv.handleHealth))
-	mux.HandleFunc("/readyz", srv.instrumentHTTP("/readyz", srv.handleReady))
 
 	httpTLSCfg, err := loadServerTLSConfig(*flagHTTPTLSCert, *flagHTTPTLSKey, minTLSVersion)
 	if err != nil {
@@ -1607,7 +1939,7 @@ func startHTTPServer(srv *server, db *storage.DB, httpAddr string, minTLSVersion
 
 	httpSrv := &http.Server{
 		Addr:              httpAddr,
-		Handler:           srv.recoverMiddleware(mux),
+		Handler:           srv.httpHandler(),
 		ReadTimeout:       *flagReadTimeout,
 		ReadHeaderTimeout: *flagReadHeaderTimeout,
 		WriteTimeout:      *flagWriteTimeout,
@@ -1622,7 +1954,7 @@ func startHTTPServer(srv *server, db *storage.DB, httpAddr string, minTLSVersion
 		if httpTLSCfg != nil {
 			proto = "https"
 		}
-		log.Printf("HTTP listening on %s (%s)", httpAddr, proto)
+		slog.Info("HTTP listening", "addr", httpAddr, "proto", proto)
 		if httpTLSCfg != nil {
 			serveErr = httpSrv.ListenAndServeTLS("", "")
 		} else {
@@ -1650,6 +1982,7 @@ func startGRPCServer(srv *server, db *storage.DB, grpcAddr string, minTLSVersion
 		grpc.MaxRecvMsgSize(*flagGRPCMaxRecv),
 		grpc.MaxSendMsgSize(*flagGRPCMaxSend),
 		grpc.UnaryInterceptor(srv.grpcUnaryInterceptor()),
+		grpc.StreamInterceptor(srv.grpcStreamInterceptor()),
 	}
 	grpcTLSCfg, err := loadServerTLSConfig(*flagGRPCTLSCert, *flagGRPCTLSKey, minTLSVersion)
 	if err != nil {
@@ -1664,13 +1997,14 @@ func startGRPCServer(srv *server, db *storage.DB, grpcAddr string, minTLSVersion
 
 	grpcSrv := grpc.NewServer(grpcOpts...)
 	registerTinySQLServer(grpcSrv, srv)
+	srv.grpcHealth = registerIntrospection(grpcSrv)
 
 	go func() {
 		proto := "plaintext"
 		if *flagGRPCTLSCert != "" && *flagGRPCTLSKey != "" {
 			proto = "tls"
 		}
-		log.Printf("gRPC listening on %s (%s)", grpcAddr, proto)
+		slog.Info("gRPC listening", "addr", grpcAddr, "proto", proto)
 		if serveErr := grpcSrv.Serve(lis); serveErr != nil && !errors.Is(serveErr, grpc.ErrServerStopped) {
 			errChan <- fmt.Errorf("grpc serve: %w", serveErr)
 		}
@@ -1678,6 +2012,21 @@ func startGRPCServer(srv *server, db *storage.DB, grpcAddr string, minTLSVersion
 	return grpcSrv, nil
 }
 
+// registerIntrospection adds the standard grpc.health.v1 health service and
+// the server reflection service, so tools such as grpcurl and Kubernetes gRPC
+// probes work without a .proto file. Reflection lists tinysql.TinySQL by
+// name only; its methods use the JSON codec and have no protobuf
+// descriptors. The returned health server reports SERVING for both the
+// overall server ("") and tinysql.TinySQL until Shutdown is called on it.
+func registerIntrospection(grpcSrv *grpc.Server) *health.Server {
+	hs := health.NewServer()
+	healthgrpc.RegisterHealthServer(grpcSrv, hs)
+	reflection.Register(grpcSrv)
+	hs.SetServingStatus("", healthgrpc.HealthCheckResponse_SERVING)
+	hs.SetServingStatus("tinysql.TinySQL", healthgrpc.HealthCheckResponse_SERVING)
+	return hs
+}
+
 func waitForServerStop(errChan <-chan error) error {
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
@@ -1686,16 +2035,16 @@ func waitForServerStop(errChan <-chan error) error {
 	select {
 	case err := <-errChan:
 		if err != nil {
-			log.Printf("server error: %v", err)
+			slog.Error("server error", "error", err)
 		}
 		return err
 	case sig := <-sigCh:
-		log.Printf("received signal %s, shutting down", sig)
+		slog.Info("received signal, shutting down", "signal", sig.String())
 		return nil
 	}
 }
 
-func shutdownRunServers(httpSrv *http.Server, grpcSrv *grpc.Server, db *storage.DB) error {
+func shutdownRunServers(httpSrv *http.Server, grpcSrv *grpc.Server, grpcHealth *health.Server, db *storage.DB) error {
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), *flagShutdownTimeout)
 	defer cancel()
 
@@ -1706,6 +2055,11 @@ func shutdownRunServers(httpSrv *http.Server, grpcSrv *grpc.Server, db *storage.
 		}
 	}
 	if grpcSrv != nil {
+		// Flip health to NOT_SERVING first so probes and load balancers
+		// drain traffic while GracefulStop waits for in-flight RPCs.
+		if grpcHealth != nil {
+			grpcHealth.Shutdown()
+		}
 		done := make(chan struct{})
 		go func() {
 			grpcSrv.GracefulStop()
@@ -1725,6 +2079,7 @@ func shutdownRunServers(httpSrv *http.Server, grpcSrv *grpc.Server, db *storage.
 
 func main() {
 	if err := run(); err != nil {
-		log.Fatal(err)
+		slog.Error("tinysql server stopped", "error", err)
+		os.Exit(1)
 	}
 }
@@ -6,7 +6,7 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net"
 	"net/http"
 	"net/http/httptest"
@@ -15,11 +15,19 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/SimonWaldherr/tinySQL/internal/engine"
 	"github.com/SimonWaldherr/tinySQL/internal/storage"
+	"github.com/SimonWaldherr/tinySQL/internal/telemetry"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
 )
 
 func TestBuildServer(t *testing.T) {
@@ -462,22 +470,86 @@ func TestQueryTruncatesRows(t *testing.T) {
 	}
 }
 
+func TestQueryLimitsRejectStatements(t *testing.T) {
+	db := storage.NewDB()
+	defer db.Close()
+
+	s := &server{
+		db:          db,
+		cache:       engine.NewQueryCache(10),
+		defaultT:    "default",
+		queryLimits: engine.QueryOptions{MaxRows: 3},
+	}
+	ctx := context.Background()
+	for _, sql := range []string{"CREATE TABLE t (id INT)", "INSERT INTO t VALUES (1), (2), (3), (4)"} {
+		if resp, _ := s.Exec(ctx, &execRequest{Tenant: "default", SQL: sql}); !resp.Success {
+			t.Fatalf("%s: %s", sql, resp.Error)
+		}
+	}
+
+	resp, _ := s.Query(ctx, &queryRequest{Tenant: "default", SQL: "SELECT id FROM t"})
+	if !strings.Contains(resp.Error, engine.ErrQueryRowLimitExceeded.Error()) || len(resp.Rows) != 0 {
+		t.Fatalf("query over the row limit: error=%q rows=%d", resp.Error, len(resp.Rows))
+	}
+	resp, _ = s.Query(ctx, &queryRequest{Tenant: "default", SQL: "SELECT id FROM t WHERE id < 4"})
+	if resp.Error != "" || resp.Count != 3 {
+		t.Fatalf("query within the row limit: error=%q count=%d", resp.Error, resp.Count)
+	}
+
+	s.queryLimits = engine.QueryOptions{MaxMemoryBytes: 64}
+	exec, _ := s.Exec(ctx, &execRequest{Tenant: "default", SQL: "UPDATE t SET id = id + 10 WHERE id IN (SELECT id FROM t)"})
+	if exec.Success || !strings.Contains(exec.Error, engine.ErrQueryMemoryExceeded.Error()) {
+		t.Fatalf("exec over the memory limit: %+v", exec)
+	}
+}
+
+func TestSlowQueryLogRecordsStatements(t *testing.T) {
+	db := storage.NewDB()
+	defer db.Close()
+
+	var buf bytes.Buffer
+	s := &server{
+		db:       db,
+		cache:    engine.NewQueryCache(10),
+		defaultT: "default",
+		slowLog:  &telemetry.SlowQueryLogger{Threshold: time.Hour, Output: &buf},
+	}
+	ctx := context.Background()
+	s.Exec(ctx, &execRequest{Tenant: "acme", SQL: "CREATE TABLE t (id INT)"})
+	s.Exec(ctx, &execRequest{Tenant: "acme", SQL: "INSERT INTO t VALUES (1), (2)"})
+	if buf.Len() != 0 {
+		t.Fatalf("statements under the threshold were logged: %s", buf.String())
+	}
+
+	s.slowLog.Threshold = 0
+	s.Query(ctx, &queryRequest{Tenant: "acme", SQL: "SELECT id FROM t"})
+	s.Query(ctx, &queryRequest{Tenant: "acme", SQL: "SELECT nope FROM missing"})
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d log lines, want 2: %s", len(lines), buf.String())
+	}
+	var ok, failed telemetry.SlowQueryEntry
+	if err := json.Unmarshal([]byte(lines[0]), &ok); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &failed); err != nil {
+		t.Fatal(err)
+	}
+	if ok.SQL != "SELECT id FROM t" || ok.Tenant != "acme" || ok.Rows != 2 || ok.Error != "" || ok.Timestamp.IsZero() {
+		t.Fatalf("logged entry = %+v", ok)
+	}
+	if failed.Error == "" {
+		t.Fatalf("failed query logged without its error: %+v", failed)
+	}
+}
+
 // TestInstrumentHTTPAlwaysLogsFailures verifies that a non-2xx HTTP response
 // is logged even with verbose logging (-v) disabled, while a successful
 // response stays silent -- matching the "silent by default" fix that made
 // failures always observable without leaking request content into the log.
 func TestInstrumentHTTPAlwaysLogsFailures(t *testing.T) {
-	s := &server{verbose: false, metrics: newMetricsRegistry()}
-
 	var buf bytes.Buffer
-	origOutput := log.Writer()
-	origFlags := log.Flags()
-	log.SetOutput(&buf)
-	log.SetFlags(0)
-	defer func() {
-		log.SetOutput(origOutput)
-		log.SetFlags(origFlags)
-	}()
+	s := &server{verbose: false, metrics: newMetricsRegistry(), log: slog.New(slog.NewTextHandler(&buf, nil))}
 
 	failing := s.instrumentHTTP("/api/test", func(w http.ResponseWriter, r *http.Request) {
 		writeErrorJSON(w, http.StatusBadRequest, "boom")
@@ -487,10 +559,10 @@ func TestInstrumentHTTPAlwaysLogsFailures(t *testing.T) {
 	failing(rec, req)
 
 	logged := buf.String()
-	if !strings.Contains(logged, "FAILED") {
+	if !strings.Contains(logged, "http request failed") {
 		t.Fatalf("expected a FAILED log line for a non-2xx response with verbose=false, got: %q", logged)
 	}
-	if !strings.Contains(logged, "route=/api/test") || !strings.Contains(logged, "status=400") {
+	if !strings.Contains(logged, "route=/api/test") || !strings.Contains(logged, "status=400") || !strings.Contains(logged, "level=WARN") {
 		t.Fatalf("expected FAILED log to include route and status, got: %q", logged)
 	}
 
@@ -502,7 +574,385 @@ func TestInstrumentHTTPAlwaysLogsFailures(t *testing.T) {
 	rec2 := httptest.NewRecorder()
 	ok(rec2, req2)
 
-	if strings.Contains(buf.String(), "FAILED") {
+	if strings.Contains(buf.String(), "http request failed") {
 		t.Fatalf("expected no failure log for a 200 response, got: %q", buf.String())
 	}
 }
+
+// TestHTTPStructuredLogging runs a request through the full handler with the
+// logger run() installs: at info level the access line carries the incoming
+// trace ID but neither SQL nor result values; at debug level the engine's
+// statement line joins it under the same trace ID.
+func TestHTTPStructuredLogging(t *testing.T) {
+	if _, err := setupTracing(""); err != nil {
+		t.Fatal(err)
+	}
+	db := storage.NewDB()
+	defer db.Close()
+	s := &server{
+		db:           db,
+		cache:        engine.NewQueryCache(10),
+		defaultT:     "default",
+		maxBodyBytes: defaultMaxBodyBytes,
+		metrics:      newMetricsRegistry(),
+		verbose:      true,
+	}
+	ctx := context.Background()
+	for _, sql := range []string{"CREATE TABLE users (id INT, email TEXT)", "INSERT INTO users VALUES (1, 'alice@example.com')"} {
+		if resp, _ := s.Exec(ctx, &execRequest{SQL: sql}); !resp.Success {
+			t.Fatalf("%s: %s", sql, resp.Error)
+		}
+	}
+	orig := slog.Default()
+	defer slog.SetDefault(orig)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	run := func(level string) []map[string]any {
+		t.Helper()
+		var buf bytes.Buffer
+		logger, err := telemetry.NewLogger(&buf, level, "json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		slog.SetDefault(logger)
+		req := httptest.NewRequest(http.MethodPost, "/api/query", strings.NewReader(`{"sql":"SELECT email FROM users WHERE id = 1"}`))
+		req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+		w := httptest.NewRecorder()
+		s.httpHandler().ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", w.Code, w.Body.String())
+		}
+		var lines []map[string]any
+		for _, raw := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			var line map[string]any
+			if err := json.Unmarshal([]byte(raw), &line); err != nil {
+				t.Fatalf("line %q is not JSON: %v", raw, err)
+			}
+			if line["trace_id"] != traceID {
+				t.Errorf("line without the request's trace_id: %v", line)
+			}
+			lines = append(lines, line)
+		}
+		return lines
+	}
+
+	lines := run("info")
+	if len(lines) != 1 || lines[0]["msg"] != "http request" || lines[0]["route"] != "/api/query" || lines[0]["status"] != float64(200) {
+		t.Fatalf("info lines = %v", lines)
+	}
+	for k, v := range lines[0] {
+		if str, ok := v.(string); ok && (strings.Contains(str, "alice@example.com") || strings.Contains(str, "SELECT")) {
+			t.Fatalf("%s = %q leaks request content at info level", k, str)
+		}
+	}
+
+	lines = run("debug")
+	if len(lines) != 2 || lines[0]["msg"] != "statement executed" || lines[0]["sql"] != "SELECT email FROM users WHERE id = 1" {
+		t.Fatalf("debug lines = %v", lines)
+	}
+}
+
+// countingPeer is a minimal TinySQLServer that records how many queries it
+// served, used to observe federation routing end to end over gRPC.
+type countingPeer struct {
+	name    string
+	queries atomic.Int64
+}
+
+func (p *countingPeer) Exec(context.Context, *execRequest) (*execResponse, error) {
+	return &execResponse{Success: true}, nil
+}
+
+func (p *countingPeer) Query(_ context.Context, req *queryRequest) (*queryResponse, error) {
+	p.queries.Add(1)
+	return &queryResponse{
+		SQL:     req.SQL,
+		Columns: []string{"peer", "tenant"},
+		Rows:    []map[string]any{{"peer": p.name, "tenant": req.Tenant}},
+		Count:   1,
+	}, nil
+}
+
+func (p *countingPeer) QueryStream(req *queryRequest, stream TinySQL_QueryStreamServer) error {
+	resp, _ := p.Query(stream.Context(), req)
+	return stream.Send(resp)
+}
+
+func startCountingPeer(t *testing.T, name string) (*countingPeer, string) {
+	t.Helper()
+	encoding.RegisterCodec(jsonCodec{})
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	peer := &countingPeer{name: name}
+	gs := grpc.NewServer()
+	registerTinySQLServer(gs, peer)
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.Stop)
+	return peer, lis.Addr().String()
+}
+
+func TestPeersForTenantReplication(t *testing.T) {
+	s := &server{peers: []string{"a:1", "b:1", "c:1"}, replicationN: 2}
+	got := s.peersForTenant("acme")
+	if len(got) != 2 || got[0] == got[1] {
+		t.Fatalf("peersForTenant = %v, want 2 distinct peers", got)
+	}
+	if again := s.peersForTenant("acme"); !equalStringSlices(got, again) {
+		t.Fatalf("routing not deterministic: %v vs %v", got, again)
+	}
+
+	s.replicationN = 10
+	if got := s.peersForTenant("acme"); len(got) != 3 {
+		t.Fatalf("replication factor must be capped at peer count, got %v", got)
+	}
+}
+
+func TestShardedFederationSplitsTenants(t *testing.T) {
+	p1, addr1 := startCountingPeer(t, "p1")
+	p2, addr2 := startCountingPeer(t, "p2")
+
+	s := &server{
+		peers:          []string{addr1, addr2},
+		federationMode: federationSharded,
+		replicationN:   1,
+		defaultT:       "default",
+		peerTimeout:    5 * time.Second,
+		maxBodyBytes:   defaultMaxBodyBytes,
+	}
+
+	const tenants = 200
+	for i := 0; i < tenants; i++ {
+		tenant := fmt.Sprintf("tenant-%d", i)
+		body := fmt.Sprintf(`{"tenant":%q,"sql":"SELECT 1"}`, tenant)
+		req := httptest.NewRequest(http.MethodPost, "/api/federated/query", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		s.handleFederatedQuery(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("tenant %s: status = %d, body=%s", tenant, rec.Code, rec.Body.String())
+		}
+		var resp queryResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		if resp.Count != 1 {
+			t.Fatalf("tenant %s: expected exactly one peer to answer, got %d rows", tenant, resp.Count)
+		}
+	}
+
+	n1, n2 := p1.queries.Load(), p2.queries.Load()
+	if n1+n2 != tenants {
+		t.Fatalf("peers handled %d+%d queries, want %d total", n1, n2, tenants)
+	}
+	// FNV64a over distinct tenant names should split roughly evenly.
+	if n1 < tenants*3/10 || n2 < tenants*3/10 {
+		t.Fatalf("uneven tenant split: p1=%d p2=%d", n1, n2)
+	}
+}
+
+func decodeHealth(t *testing.T, rec *httptest.ResponseRecorder) healthResponse {
+	t.Helper()
+	var body healthResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode health response: %v (%s)", err, rec.Body.String())
+	}
+	return body
+}
+
+func TestHandleHealthOK(t *testing.T) {
+	db := storage.NewDB()
+	defer db.Close()
+	s := &server{db: db, cache: engine.NewQueryCache(10), defaultT: "default"}
+
+	rec := httptest.NewRecorder()
+	s.handleHealth(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", rec.Code, rec.Body.String())
+	}
+	body := decodeHealth(t, rec)
+	if body.Status != healthOK || body.Checks.DB != healthOK || body.Checks.Disk != healthSkipped {
+		t.Fatalf("unexpected health body: %+v", body)
+	}
+}
+
+func TestHandleHealthFailingDB(t *testing.T) {
+	db := storage.NewDB()
+	defer db.Close()
+	// A saturated execution semaphore means SELECT 1 can never start, which
+	// is indistinguishable from a wedged engine from the probe's viewpoint.
+	sem := make(chan struct{}, 1)
+	sem <- struct{}{}
+	s := &server{db: db, cache: engine.NewQueryCache(10), defaultT: "default", execSem: sem, healthTimeout: 20 * time.Millisecond}
+
+	rec := httptest.NewRecorder()
+	s.handleHealth(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503 (%s)", rec.Code, rec.Body.String())
+	}
+	body := decodeHealth(t, rec)
+	if body.Status != healthFail || body.Checks.DB == healthOK {
+		t.Fatalf("unexpected health body: %+v", body)
+	}
+}
+
+func TestHandleHealthUnreachablePeerIsDegraded(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	deadAddr := lis.Addr().String()
+	_ = lis.Close()
+
+	db := storage.NewDB()
+	defer db.Close()
+	s := &server{db: db, cache: engine.NewQueryCache(10), defaultT: "default", peers: []string{deadAddr}, healthTimeout: 50 * time.Millisecond}
+
+	rec := httptest.NewRecorder()
+	s.handleHealth(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", rec.Code, rec.Body.String())
+	}
+	body := decodeHealth(t, rec)
+	if body.Status != healthDegraded || body.Checks.Peers[deadAddr] == healthOK {
+		t.Fatalf("unexpected health body: %+v", body)
+	}
+}
+
+func TestGRPCHealthAndReflection(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &server{authToken: "secret", metrics: newMetricsRegistry()}
+	gs := grpc.NewServer(grpc.UnaryInterceptor(s.grpcUnaryInterceptor()))
+	registerTinySQLServer(gs, s)
+	hs := registerIntrospection(gs)
+	go func() { _ = gs.Serve(lis) }()
+	defer gs.Stop()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Health checks must not require the auth token.
+	hc := healthgrpc.NewHealthClient(conn)
+	resp, err := hc.Check(ctx, &healthgrpc.HealthCheckRequest{Service: "tinysql.TinySQL"})
+	if err != nil {
+		t.Fatalf("health check: %v", err)
+	}
+	if resp.Status != healthgrpc.HealthCheckResponse_SERVING {
+		t.Fatalf("status = %v, want SERVING", resp.Status)
+	}
+
+	hs.Shutdown()
+	resp, err = hc.Check(ctx, &healthgrpc.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("health check after shutdown: %v", err)
+	}
+	if resp.Status != healthgrpc.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("status = %v, want NOT_SERVING", resp.Status)
+	}
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		t.Fatalf("reflection stream: %v", err)
+	}
+	if err := stream.Send(&reflectionpb.ServerReflectionRequest{MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{}}); err != nil {
+		t.Fatalf("reflection send: %v", err)
+	}
+	rr, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("reflection recv: %v", err)
+	}
+	found := false
+	for _, svc := range rr.GetListServicesResponse().GetService() {
+		if svc.GetName() == "tinysql.TinySQL" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("reflection did not list tinysql.TinySQL: %v", rr.GetListServicesResponse().GetService())
+	}
+}
+
+func TestRunInitSQLFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	initFile := filepath.Join(dir, "schema.sql")
+	if err := os.WriteFile(initFile, []byte("CREATE TABLE users (id INT, name TEXT);\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(envInitFile, initFile)
+	t.Setenv(envInitSQL, "INSERT INTO users VALUES (1, 'a;b'); INSERT INTO users VALUES (2, 'it''s');")
+
+	db := storage.NewDB()
+	defer db.Close()
+	ctx := context.Background()
+	if err := runInitSQL(ctx, db, "default"); err != nil {
+		t.Fatalf("runInitSQL: %v", err)
+	}
+
+	s := &server{db: db, cache: engine.NewQueryCache(10), defaultT: "default"}
+	resp, err := s.Query(ctx, &queryRequest{SQL: "SELECT name FROM users ORDER BY id"})
+	if err != nil || resp.Error != "" {
+		t.Fatalf("first query failed: %v %s", err, resp.Error)
+	}
+	if len(resp.Rows) != 2 || resp.Rows[0]["name"] != "a;b" || resp.Rows[1]["name"] != "it's" {
+		t.Fatalf("unexpected rows: %+v", resp.Rows)
+	}
+}
+
+func TestSplitSQLStatements(t *testing.T) {
+	script := `-- don't split here; or here
+CREATE TABLE t (id INT); /* a block; with 'quote */ INSERT INTO t VALUES (1);
+INSERT INTO "odd;name" VALUES ('a;b', 'it''s', '-- not a comment'); -- trailing`
+	want := []string{
+		"CREATE TABLE t (id INT)",
+		"INSERT INTO t VALUES (1)",
+		`INSERT INTO "odd;name" VALUES ('a;b', 'it''s', '-- not a comment')`,
+	}
+	got := splitSQLStatements(script)
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("splitSQLStatements =\n%q\nwant\n%q", got, want)
+	}
+}
+
+// A file-backed server keeps its seeded schema, so the seed must not run
+// again on the next start.
+func TestRunInitSQLSkipsExistingDatabase(t *testing.T) {
+	t.Setenv(envInitSQL, "CREATE TABLE users (id INT); INSERT INTO users VALUES (1);")
+	dsn := "file:" + filepath.Join(t.TempDir(), "seed.db") + "?autosave=1"
+	ctx := context.Background()
+	for start := 1; start <= 2; start++ {
+		db, tenant, err := openDBFromDSN(dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := runInitSQL(ctx, db, tenant); err != nil {
+			t.Fatalf("start %d: runInitSQL: %v", start, err)
+		}
+		s := &server{db: db, cache: engine.NewQueryCache(10), defaultT: tenant}
+		resp, _ := s.Query(ctx, &queryRequest{SQL: "SELECT id FROM users"})
+		if resp.Error != "" || len(resp.Rows) != 1 {
+			t.Fatalf("start %d: rows %v, error %q; want the one seeded row", start, resp.Rows, resp.Error)
+		}
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRunInitSQLReportsFailingStatement(t *testing.T) {
+	t.Setenv(envInitSQL, "CREATE TABLE ok (id INT); INSERT INTO missing VALUES (1)")
+	db := storage.NewDB()
+	defer db.Close()
+	err := runInitSQL(context.Background(), db, "default")
+	if err == nil || !strings.Contains(err.Error(), envInitSQL) {
+		t.Fatalf("expected %s error, got %v", envInitSQL, err)
+	}
+}
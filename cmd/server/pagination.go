@@ -0,0 +1,341 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SimonWaldherr/tinySQL/internal/engine"
+)
+
+// Cursor pagination (/api/query/page): the query must have an ORDER BY whose
+// last column makes the order unique. The cursor is the base64 JSON object
+// of the ORDER BY values of the last row returned, and the next page starts
+// strictly after it, so rows inserted or deleted between requests neither
+// repeat nor shift rows the way OFFSET paging does.
+
+const defaultPageSize = 100
+
+// pageAlias names the derived table the user's query is wrapped in.
+const pageAlias = "cursor_page"
+
+type pageQueryRequest struct {
+	Tenant    string `json:"tenant"`
+	SQL       string `json:"sql"`
+	PageSize  int    `json:"page_size,omitempty"`
+	Cursor    string `json:"cursor,omitempty"`
+	TimeoutMS int64  `json:"timeout_ms,omitempty"`
+}
+
+type pageQueryResponse struct {
+	Columns    []string         `json:"columns"`
+	Rows       []map[string]any `json:"rows"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+	HasMore    bool             `json:"has_more"`
+	Error      string           `json:"error,omitempty"`
+	Duration   string           `json:"duration"`
+}
+
+// QueryPage runs one page of a cursor-paginated query.
+func (s *server) QueryPage(ctx context.Context, req *pageQueryRequest) *pageQueryResponse {
+	start := time.Now()
+	fail := func(err error) *pageQueryResponse {
+		return &pageQueryResponse{Error: err.Error(), Duration: time.Since(start).String()}
+	}
+	tenant := s.tenantOrDefault(req.Tenant)
+	sqlText, err := s.normalizeSQL(req.SQL)
+	if err != nil {
+		return fail(err)
+	}
+	size := req.PageSize
+	switch {
+	case size < 0:
+		return fail(fmt.Errorf("page_size must be >= 0"))
+	case size == 0:
+		size = defaultPageSize
+	case s.maxResponseRows > 0 && size > s.maxResponseRows:
+		size = s.maxResponseRows
+	}
+
+	stmt, err := engine.NewParser(sqlText).ParseStatement()
+	if err != nil {
+		return fail(err)
+	}
+	sel, order, err := pageStatement(stmt, size)
+	if err != nil {
+		return fail(err)
+	}
+	if req.Cursor != "" {
+		after, err := decodeCursor(req.Cursor, order)
+		if err != nil {
+			return fail(err)
+		}
+		sel.Where = afterCursor(order, after)
+	}
+
+	ctx, cancel, err := s.withRequestTimeoutOverride(ctx, req.TimeoutMS)
+	if err != nil {
+		return fail(err)
+	}
+	defer cancel()
+	release, err := s.acquireExecSlot(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	defer release()
+
+	execStart := time.Now()
+	rs, err := engine.ExecuteWith(engine.WithAuditText(ctx, sqlText), s.db, tenant, sel, engine.WithQueryOptions(s.queryLimits), engine.WithTracer(s.tracer()))
+	rowCount := 0
+	if rs != nil {
+		rowCount = len(rs.Rows)
+	}
+	s.logSlowQuery(sqlText, tenant, execStart, rowCount, err)
+	if err != nil {
+		return fail(err)
+	}
+
+	cols := s.pageColumns(tenant, sel.From.Subquery, rs.Cols)
+	resp := &pageQueryResponse{Columns: cols, Rows: make([]map[string]any, 0, min(rowCount, size))}
+	for i, r := range rs.Rows {
+		if i == size {
+			resp.HasMore = true
+			break
+		}
+		// Rows of a derived table also carry "cursor_page.col" keys; only
+		// the result columns are returned.
+		m := make(map[string]any, len(cols))
+		for _, c := range cols {
+			m[c] = pageRowValue(r, c)
+		}
+		resp.Rows = append(resp.Rows, m)
+	}
+	if resp.HasMore {
+		resp.NextCursor, err = encodeCursor(order, resp.Rows[len(resp.Rows)-1])
+		if err != nil {
+			return fail(err)
+		}
+	}
+	resp.Duration = time.Since(start).String()
+	return resp
+}
+
+// pageStatement checks that stmt is a single SELECT with an ORDER BY and no
+// LIMIT or OFFSET of its own and wraps it as
+//
+//	SELECT * FROM (<stmt without ORDER BY>) AS cursor_page ORDER BY ... LIMIT size+1
+//
+// The cursor condition goes into the outer WHERE, so ORDER BY may name
+// output aliases and the condition cannot interact with the query's own
+// WHERE, GROUP BY or DISTINCT. The extra row tells whether there is a next
+// page.
+func pageStatement(stmt engine.Statement, size int) (*engine.Select, []engine.OrderItem, error) {
+	inner, ok := stmt.(*engine.Select)
+	if !ok {
+		return nil, nil, errors.New("paginated query must be a SELECT")
+	}
+	switch {
+	case inner.Union != nil:
+		return nil, nil, errors.New("paginated query must not use UNION")
+	case len(inner.OrderBy) == 0:
+		return nil, nil, errors.New("paginated query needs an ORDER BY")
+	case inner.Limit != nil || inner.Offset != nil:
+		return nil, nil, errors.New("paginated query must not use LIMIT or OFFSET")
+	}
+	order := make([]engine.OrderItem, len(inner.OrderBy))
+	for i, o := range inner.OrderBy {
+		// The wrapped query's columns are unqualified: ORDER BY t.id
+		// sorts the outer query by id.
+		order[i] = engine.OrderItem{Col: o.Col[strings.LastIndex(o.Col, ".")+1:], Desc: o.Desc}
+	}
+	inner.OrderBy = nil
+	limit := size + 1
+	return &engine.Select{
+		From:    engine.FromItem{Subquery: inner, Alias: pageAlias},
+		Projs:   []engine.SelectItem{{Star: true}},
+		OrderBy: order,
+		Limit:   &limit,
+	}, order, nil
+}
+
+// pageColumns puts cols, the columns of the wrapping SELECT *, back in the
+// order of inner's projection: the derived table does not keep it. A plain
+// * takes the order of the table it selects from; columns whose place is
+// not known keep their relative order at the end.
+func (s *server) pageColumns(tenant string, inner *engine.Select, cols []string) []string {
+	var want []string
+	for i, it := range inner.Projs {
+		switch {
+		case it.Star:
+			if inner.From.Table == "" || len(inner.Joins) > 0 {
+				continue
+			}
+			if t, err := s.db.Get(tenant, inner.From.Table); err == nil {
+				for _, c := range t.Cols {
+					want = append(want, c.Name)
+				}
+			}
+		case it.Alias != "":
+			want = append(want, it.Alias)
+		default:
+			if ref, ok := it.Expr.(*engine.VarRef); ok {
+				want = append(want, ref.Name[strings.LastIndex(ref.Name, ".")+1:])
+			} else {
+				// The engine's name for an unaliased expression.
+				want = append(want, fmt.Sprintf("col_%d", i))
+			}
+		}
+	}
+	ordered := make([]string, 0, len(cols))
+	used := make([]bool, len(cols))
+	for _, w := range want {
+		for i, c := range cols {
+			if !used[i] && strings.EqualFold(c, w) {
+				ordered = append(ordered, c)
+				used[i] = true
+				break
+			}
+		}
+	}
+	for i, c := range cols {
+		if !used[i] {
+			ordered = append(ordered, c)
+		}
+	}
+	return ordered
+}
+
+// afterCursor builds the condition for rows after the cursor values in the
+// given order: the row-value comparison (c1, c2) > (v1, v2) expanded as
+// c1 > v1 OR (c1 = v1 AND c2 > v2), with < for DESC columns.
+func afterCursor(order []engine.OrderItem, after []any) engine.Expr {
+	var cond engine.Expr
+	for i := len(order) - 1; i >= 0; i-- {
+		op := ">"
+		if order[i].Desc {
+			op = "<"
+		}
+		var term engine.Expr = &engine.Binary{Op: op, Left: pageColumn(order[i].Col), Right: &engine.Literal{Val: after[i]}}
+		if cond != nil {
+			eq := &engine.Binary{Op: "=", Left: pageColumn(order[i].Col), Right: &engine.Literal{Val: after[i]}}
+			term = &engine.Binary{Op: "OR", Left: term, Right: &engine.Binary{Op: "AND", Left: eq, Right: cond}}
+		}
+		cond = term
+	}
+	return cond
+}
+
+func pageColumn(name string) *engine.VarRef {
+	return &engine.VarRef{Name: name, Lower: strings.ToLower(name)}
+}
+
+// encodeCursor returns the cursor for the page after row.
+func encodeCursor(order []engine.OrderItem, row map[string]any) (string, error) {
+	values := make(map[string]any, len(order))
+	for _, o := range order {
+		v, ok := lookupFold(row, o.Col)
+		switch {
+		case !ok:
+			return "", fmt.Errorf("ORDER BY column %q must be selected to paginate", o.Col)
+		case v == nil:
+			return "", fmt.Errorf("ORDER BY column %q is NULL; paginate on non-NULL columns", o.Col)
+		}
+		values[o.Col] = v
+	}
+	b, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+var errBadCursor = errors.New("invalid cursor")
+
+// decodeCursor returns the cursor's values in ORDER BY order. A cursor
+// that is not one this query produced, e.g. edited by hand or taken from a
+// query with a different ORDER BY, is errBadCursor.
+func decodeCursor(cursor string, order []engine.OrderItem) ([]any, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errBadCursor
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var values map[string]any
+	if err := dec.Decode(&values); err != nil || len(values) != len(order) {
+		return nil, errBadCursor
+	}
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		return nil, errBadCursor
+	}
+	after := make([]any, len(order))
+	for i, o := range order {
+		switch v := values[o.Col].(type) {
+		case string, bool:
+			after[i] = v
+		case json.Number:
+			if n, err := v.Int64(); err == nil {
+				after[i] = int(n)
+			} else if f, err := v.Float64(); err == nil {
+				after[i] = f
+			} else {
+				return nil, errBadCursor
+			}
+		default:
+			// Missing, NULL, or an object or array.
+			return nil, errBadCursor
+		}
+	}
+	return after, nil
+}
+
+func pageRowValue(row map[string]any, col string) any {
+	v, _ := lookupFold(row, col)
+	return v
+}
+
+// lookupFold finds col in row ignoring case, as SQL column names do.
+func lookupFold(row map[string]any, col string) (any, bool) {
+	if v, ok := row[col]; ok {
+		return v, true
+	}
+	for k, v := range row {
+		if strings.EqualFold(k, col) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func (s *server) handleQueryPage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrorJSON(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req pageQueryRequest
+	if err := decodeJSONBody(w, r, s.maxBodyBytes, &req); err != nil {
+		writeErrorJSON(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	tenant, err := s.jwtTenant(r.Context(), req.Tenant)
+	if err != nil {
+		writeErrorJSON(w, http.StatusForbidden, err.Error())
+		return
+	}
+	req.Tenant = tenant
+
+	resp := s.QueryPage(r.Context(), &req)
+	if resp.Error != "" {
+		writeJSON(w, http.StatusBadRequest, resp)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
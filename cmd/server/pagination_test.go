@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SimonWaldherr/tinySQL/internal/engine"
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+// newPagingServer returns a server with items (id, grp, name) holding ids
+// 1..10, grp alternating a/b.
+func newPagingServer(t *testing.T) *server {
+	t.Helper()
+	db := storage.NewDB()
+	t.Cleanup(func() { _ = db.Close() })
+	s := &server{db: db, cache: engine.NewQueryCache(10), defaultT: "default", maxBodyBytes: defaultMaxBodyBytes, metrics: newMetricsRegistry()}
+	sqls := []string{"CREATE TABLE items (id INT, grp TEXT, name TEXT)"}
+	for i := 1; i <= 10; i++ {
+		sqls = append(sqls, fmt.Sprintf("INSERT INTO items VALUES (%d, '%c', 'item %d')", i, "ab"[i%2], i))
+	}
+	for _, sql := range sqls {
+		if resp, _ := s.Exec(context.Background(), &execRequest{SQL: sql}); !resp.Success {
+			t.Fatalf("%s: %s", sql, resp.Error)
+		}
+	}
+	return s
+}
+
+// pageThrough requests every page of sql and returns the ids in order and
+// the number of pages.
+func pageThrough(t *testing.T, s *server, sql string, size int) ([]string, int) {
+	t.Helper()
+	var ids []string
+	cursor := ""
+	for pages := 1; pages < 20; pages++ {
+		resp := s.QueryPage(context.Background(), &pageQueryRequest{SQL: sql, PageSize: size, Cursor: cursor})
+		if resp.Error != "" {
+			t.Fatalf("page %d: %s", pages, resp.Error)
+		}
+		if len(resp.Rows) > size {
+			t.Fatalf("page %d has %d rows, want at most %d", pages, len(resp.Rows), size)
+		}
+		for _, r := range resp.Rows {
+			ids = append(ids, fmt.Sprint(r["id"]))
+		}
+		if !resp.HasMore {
+			if resp.NextCursor != "" {
+				t.Fatalf("last page has next_cursor %q", resp.NextCursor)
+			}
+			return ids, pages
+		}
+		cursor = resp.NextCursor
+	}
+	t.Fatal("pagination did not end")
+	return nil, 0
+}
+
+func TestQueryPageVisitsEveryRowOnce(t *testing.T) {
+	s := newPagingServer(t)
+	ids, pages := pageThrough(t, s, "SELECT id, name FROM items ORDER BY id", 3)
+	if got := strings.Join(ids, ","); got != "1,2,3,4,5,6,7,8,9,10" || pages != 4 {
+		t.Fatalf("ids = %s over %d pages, want 1..10 over 4", got, pages)
+	}
+	// A page size dividing the row count must not end with an empty page.
+	if _, pages := pageThrough(t, s, "SELECT id FROM items ORDER BY id", 5); pages != 2 {
+		t.Fatalf("pages = %d, want 2", pages)
+	}
+}
+
+func TestQueryPageCursorStartsNextPage(t *testing.T) {
+	s := newPagingServer(t)
+	const sql = "SELECT id FROM items WHERE id > 2 ORDER BY id"
+	first := s.QueryPage(context.Background(), &pageQueryRequest{SQL: sql, PageSize: 2})
+	if first.Error != "" || !first.HasMore || fmt.Sprint(first.Rows) != "[map[id:3] map[id:4]]" {
+		t.Fatalf("first page = %+v", first)
+	}
+	// Rows inserted before the cursor do not shift the next page.
+	if resp, _ := s.Exec(context.Background(), &execRequest{SQL: "INSERT INTO items VALUES (0, 'a', 'new')"}); !resp.Success {
+		t.Fatal(resp.Error)
+	}
+	second := s.QueryPage(context.Background(), &pageQueryRequest{SQL: sql, PageSize: 2, Cursor: first.NextCursor})
+	if second.Error != "" || fmt.Sprint(second.Rows) != "[map[id:5] map[id:6]]" {
+		t.Fatalf("second page = %+v, want ids 5 and 6", second)
+	}
+}
+
+func TestQueryPageDescending(t *testing.T) {
+	s := newPagingServer(t)
+	ids, _ := pageThrough(t, s, "SELECT id FROM items ORDER BY id DESC", 4)
+	if got := strings.Join(ids, ","); got != "10,9,8,7,6,5,4,3,2,1" {
+		t.Fatalf("ids = %s", got)
+	}
+}
+
+func TestQueryPageMultiColumnCursor(t *testing.T) {
+	s := newPagingServer(t)
+	ids, _ := pageThrough(t, s, "SELECT grp, id FROM items ORDER BY grp DESC, id", 3)
+	// b holds the odd ids, a the even ones; pages break inside each group.
+	if got := strings.Join(ids, ","); got != "1,3,5,7,9,2,4,6,8,10" {
+		t.Fatalf("ids = %s", got)
+	}
+	resp := s.QueryPage(context.Background(), &pageQueryRequest{SQL: "SELECT grp, id FROM items ORDER BY grp DESC, id", PageSize: 4})
+	raw, _ := base64.RawURLEncoding.DecodeString(resp.NextCursor)
+	var cursor map[string]any
+	if err := json.Unmarshal(raw, &cursor); err != nil || fmt.Sprint(cursor) != "map[grp:b id:7]" {
+		t.Fatalf("cursor = %s, want the ORDER BY values of the last row", raw)
+	}
+}
+
+func TestQueryPageKeepsColumnOrder(t *testing.T) {
+	s := newPagingServer(t)
+	cases := []struct{ sql, want string }{
+		{"SELECT name, id FROM items ORDER BY name DESC, id", "[name id]"},
+		{"SELECT id, grp AS g, name FROM items ORDER BY g, id", "[id g name]"},
+		{"SELECT * FROM items ORDER BY id", "[id grp name]"},
+		{"SELECT i.name, i.id FROM items i ORDER BY i.id", "[name id]"},
+	}
+	for _, tc := range cases {
+		// Map iteration order varies, so one lucky run proves little.
+		for range 5 {
+			resp := s.QueryPage(context.Background(), &pageQueryRequest{SQL: tc.sql, PageSize: 2})
+			if resp.Error != "" || fmt.Sprint(resp.Columns) != tc.want {
+				t.Fatalf("%s: columns = %v (%s), want %s", tc.sql, resp.Columns, resp.Error, tc.want)
+			}
+		}
+	}
+}
+
+func TestQueryPageRejectsBadInput(t *testing.T) {
+	s := newPagingServer(t)
+	enc := func(v string) string { return base64.RawURLEncoding.EncodeToString([]byte(v)) }
+	cases := []struct {
+		name, sql, cursor, want string
+	}{
+		{"no ORDER BY", "SELECT id FROM items", "", "ORDER BY"},
+		{"own LIMIT", "SELECT id FROM items ORDER BY id LIMIT 3", "", "LIMIT"},
+		{"not a SELECT", "DELETE FROM items", "", "SELECT"},
+		{"not base64", "SELECT id FROM items ORDER BY id", "!!!", "invalid cursor"},
+		{"not JSON", "SELECT id FROM items ORDER BY id", enc("id=3"), "invalid cursor"},
+		{"wrong column", "SELECT id FROM items ORDER BY id", enc(`{"name":3}`), "invalid cursor"},
+		{"extra column", "SELECT id FROM items ORDER BY id", enc(`{"id":3,"x":1}`), "invalid cursor"},
+		{"injected value", "SELECT id FROM items ORDER BY id", enc(`{"id":{"sql":"1 OR 1=1"}}`), "invalid cursor"},
+		{"NULL value", "SELECT id FROM items ORDER BY id", enc(`{"id":null}`), "invalid cursor"},
+	}
+	for _, tc := range cases {
+		resp := s.QueryPage(context.Background(), &pageQueryRequest{SQL: tc.sql, PageSize: 2, Cursor: tc.cursor})
+		if !strings.Contains(resp.Error, tc.want) {
+			t.Errorf("%s: error = %q, want it to mention %q", tc.name, resp.Error, tc.want)
+		}
+	}
+}
+
+func TestHandleQueryPage(t *testing.T) {
+	s := newPagingServer(t)
+	post := func(body string) (*httptest.ResponseRecorder, pageQueryResponse) {
+		w := httptest.NewRecorder()
+		s.httpHandler().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/query/page", strings.NewReader(body)))
+		var resp pageQueryResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("response %q: %v", w.Body, err)
+		}
+		return w, resp
+	}
+	w, resp := post(`{"sql":"SELECT id FROM items ORDER BY id","page_size":4}`)
+	if w.Code != http.StatusOK || len(resp.Rows) != 4 || !resp.HasMore || resp.NextCursor == "" {
+		t.Fatalf("status %d, response %+v", w.Code, resp)
+	}
+	w, resp = post(`{"sql":"SELECT id FROM items ORDER BY id","page_size":4,"cursor":"` + resp.NextCursor + `x"}`)
+	if w.Code != http.StatusBadRequest || resp.Error == "" {
+		t.Fatalf("tampered cursor: status %d, response %+v", w.Code, resp)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/SimonWaldherr/tinySQL/internal/telemetry"
+)
+
+// requestIDHeader carries the correlation ID of an HTTP request; gRPC calls
+// use the lower-case form as metadata key.
+const (
+	requestIDHeader   = "X-Request-ID"
+	requestIDMetadata = "x-request-id"
+	maxRequestIDLen   = 128
+)
+
+// GetRequestID returns the correlation ID of the request ctx belongs to, or
+// "" outside a request.
+func GetRequestID(ctx context.Context) string {
+	return telemetry.RequestID(ctx)
+}
+
+// requestIDOrNew keeps a caller-supplied ID when it is short printable ASCII,
+// so it cannot forge log lines, and otherwise generates a UUID v4.
+func requestIDOrNew(id string) string {
+	if id == "" || len(id) > maxRequestIDLen {
+		return uuid.NewString()
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return uuid.NewString()
+		}
+	}
+	return id
+}
+
+// withRequestID tags each request with its X-Request-ID, echoes it in the
+// response and stores it in r.Context(), where the server's logger picks it
+// up for every line logged while handling the request.
+func (s *server) withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := requestIDOrNew(r.Header.Get(requestIDHeader))
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(telemetry.WithRequestID(r.Context(), id)))
+	})
+}
+
+// grpcRequestID is withRequestID for gRPC: the ID comes from the incoming
+// x-request-id metadata and is returned as a response header.
+func grpcRequestID(ctx context.Context) context.Context {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if vals := md.Get(requestIDMetadata); len(vals) > 0 {
+			id = vals[0]
+		}
+	}
+	id = requestIDOrNew(id)
+	// SetHeader fails only outside a real RPC, e.g. when tests call the
+	// interceptor directly; the ID is still usable for logging.
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, id))
+	return telemetry.WithRequestID(ctx, id)
+}
+
+// outgoingRequestID forwards the current request's ID to a peer call.
+func outgoingRequestID(ctx context.Context) context.Context {
+	if id := GetRequestID(ctx); id != "" {
+		return metadata.AppendToOutgoingContext(ctx, requestIDMetadata, id)
+	}
+	return ctx
+}
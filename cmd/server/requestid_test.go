@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/SimonWaldherr/tinySQL/internal/engine"
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+	"github.com/SimonWaldherr/tinySQL/internal/telemetry"
+)
+
+func newRequestIDTestServer(t *testing.T) *server {
+	t.Helper()
+	db := storage.NewDB()
+	t.Cleanup(func() { _ = db.Close() })
+	return &server{
+		db:           db,
+		cache:        engine.NewQueryCache(10),
+		defaultT:     "default",
+		maxBodyBytes: defaultMaxBodyBytes,
+		metrics:      newMetricsRegistry(),
+		verbose:      true,
+	}
+}
+
+func TestRequestIDEchoedOrGenerated(t *testing.T) {
+	h := newRequestIDTestServer(t).httpHandler()
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.Header.Set(requestIDHeader, "client-abc-123")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if got := w.Header().Get(requestIDHeader); got != "client-abc-123" {
+		t.Fatalf("provided ID echoed as %q", got)
+	}
+
+	for _, supplied := range []string{"", "bad id\nforged=1", strings.Repeat("x", maxRequestIDLen+1)} {
+		req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+		if supplied != "" {
+			req.Header.Set(requestIDHeader, supplied)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		id, err := uuid.Parse(w.Header().Get(requestIDHeader))
+		if err != nil || id.Version() != 4 {
+			t.Fatalf("supplied %q: response ID %q is not a UUID v4 (%v)", supplied, w.Header().Get(requestIDHeader), err)
+		}
+	}
+}
+
+func TestRequestIDInLogsAndIsolated(t *testing.T) {
+	s := newRequestIDTestServer(t)
+	if resp, _ := s.Exec(context.Background(), &execRequest{SQL: "CREATE TABLE t (id INT)"}); !resp.Success {
+		t.Fatal(resp.Error)
+	}
+	var buf bytes.Buffer
+	var mu sync.Mutex
+	logger, err := telemetry.NewLogger(lockedWriter{&mu, &buf}, "debug", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := slog.Default()
+	slog.SetDefault(logger)
+	defer slog.SetDefault(orig)
+	h := s.httpHandler()
+
+	const n = 16
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("req-%d", i)
+			req := httptest.NewRequest(http.MethodPost, "/api/query", strings.NewReader(fmt.Sprintf(`{"sql":"SELECT %d AS n FROM t"}`, i)))
+			req.Header.Set(requestIDHeader, id)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if w.Code != http.StatusOK || w.Header().Get(requestIDHeader) != id {
+				t.Errorf("%s: status %d, ID %q", id, w.Code, w.Header().Get(requestIDHeader))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Each request logs its statement (debug) and its access line (info);
+	// both must carry that request's ID and no other.
+	seen := map[string]int{}
+	for _, raw := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var line map[string]any
+		if err := json.Unmarshal([]byte(raw), &line); err != nil {
+			t.Fatalf("line %q: %v", raw, err)
+		}
+		id, _ := line["request_id"].(string)
+		if line["msg"] == "statement executed" {
+			var i int
+			fmt.Sscanf(line["sql"].(string), "SELECT %d", &i)
+			if id != fmt.Sprintf("req-%d", i) {
+				t.Errorf("statement %q logged with request_id %q", line["sql"], id)
+			}
+		}
+		seen[id]++
+	}
+	for i := 0; i < n; i++ {
+		if id := fmt.Sprintf("req-%d", i); seen[id] != 2 {
+			t.Errorf("%s appears in %d lines, want 2", id, seen[id])
+		}
+	}
+}
+
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  *bytes.Buffer
+}
+
+func (l lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+func TestRequestIDPropagatedToPeers(t *testing.T) {
+	encoding.RegisterCodec(jsonCodec{})
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make(chan string, 1)
+	capture := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		ids <- strings.Join(md.Get(requestIDMetadata), ",")
+		return handler(ctx, req)
+	}
+	gs := grpc.NewServer(grpc.UnaryInterceptor(capture))
+	registerTinySQLServer(gs, &countingPeer{name: "peer"})
+	go func() { _ = gs.Serve(lis) }()
+	defer gs.Stop()
+
+	ctx := telemetry.WithRequestID(context.Background(), "fed-42")
+	if _, err := grpcQuery(ctx, lis.Addr().String(), &queryRequest{SQL: "SELECT 1"}, "", 0, 1<<20, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-ids; got != "fed-42" {
+		t.Fatalf("peer saw request ID %q, want fed-42", got)
+	}
+	if got := GetRequestID(grpcRequestID(metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestIDMetadata, "fed-42")))); got != "fed-42" {
+		t.Fatalf("interceptor adopted %q, want fed-42", got)
+	}
+}
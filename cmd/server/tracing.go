@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/SimonWaldherr/tinySQL/cmd/server"
+
+// setupTracing installs the global tracer provider. With an empty path the
+// global provider is left alone, which is a no-op unless the binary is
+// extended to register one; otherwise spans are written as JSON to path
+// ("-" for stdout). Incoming W3C traceparent headers are honored either way.
+// The returned func flushes and closes the exporter.
+func setupTracing(path string) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	if path == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	var out io.WriteCloser = nopWriteCloser{os.Stdout}
+	if path != "-" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		out = f
+	}
+	exp, err := stdouttrace.New(stdouttrace.WithWriter(out))
+	if err != nil {
+		_ = out.Close()
+		return nil, err
+	}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exp))
+	otel.SetTracerProvider(tp)
+	return func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		return err
+	}, nil
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+// tracerProviderOrGlobal returns the provider for HTTP and statement spans.
+func (s *server) tracerProviderOrGlobal() trace.TracerProvider {
+	if s.tracing != nil {
+		return s.tracing
+	}
+	return otel.GetTracerProvider()
+}
+
+// tracer returns the tracer handed to the engine for statement spans.
+func (s *server) tracer() trace.Tracer {
+	return s.tracerProviderOrGlobal().Tracer(tracerName)
+}
+
+// traceHTTP starts a server span per request, named after the method and
+// path, and stores it in r.Context() so statement spans become its children.
+func (s *server) traceHTTP(h http.Handler) http.Handler {
+	return otelhttp.NewHandler(h, "tinysql.http",
+		otelhttp.WithTracerProvider(s.tracerProviderOrGlobal()),
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method + " " + r.URL.Path
+		}))
+}
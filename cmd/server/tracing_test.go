@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/SimonWaldherr/tinySQL/internal/engine"
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+func TestHTTPQueryTracing(t *testing.T) {
+	if _, err := setupTracing(""); err != nil {
+		t.Fatal(err)
+	}
+	db := storage.NewDB()
+	defer db.Close()
+	rec := tracetest.NewSpanRecorder()
+	s := &server{
+		db:           db,
+		cache:        engine.NewQueryCache(10),
+		defaultT:     "default",
+		maxBodyBytes: defaultMaxBodyBytes,
+		metrics:      newMetricsRegistry(),
+		tracing:      sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)),
+	}
+	ctx := context.Background()
+	for _, sql := range []string{"CREATE TABLE t (id INT)", "INSERT INTO t VALUES (1), (2), (3)"} {
+		if resp, _ := s.Exec(ctx, &execRequest{SQL: sql}); !resp.Success {
+			t.Fatalf("%s: %s", sql, resp.Error)
+		}
+	}
+	before := len(rec.Ended())
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodPost, "/api/query", strings.NewReader(`{"sql":"SELECT id FROM t WHERE id > 1"}`))
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	s.httpHandler().ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+
+	byName := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range rec.Ended()[before:] {
+		byName[span.Name()] = span
+	}
+	httpSpan, stmt, scan := byName["POST /api/query"], byName["tinysql.Execute"], byName["tinysql.scan"]
+	if httpSpan == nil || stmt == nil || scan == nil {
+		t.Fatalf("spans = %v", byName)
+	}
+	if got := httpSpan.SpanContext().TraceID().String(); got != traceID {
+		t.Errorf("HTTP span trace ID = %s, want the incoming %s", got, traceID)
+	}
+	if stmt.Parent().SpanID() != httpSpan.SpanContext().SpanID() {
+		t.Error("statement span is not a child of the HTTP span")
+	}
+	if scan.Parent().SpanID() != stmt.SpanContext().SpanID() {
+		t.Error("scan span is not a child of the statement span")
+	}
+	for _, kv := range scan.Attributes() {
+		if kv.Key == "tinysql.rows" && kv.Value.AsInt64() != 2 {
+			t.Errorf("scan rows = %d, want 2", kv.Value.AsInt64())
+		}
+	}
+}
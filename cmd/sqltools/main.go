@@ -28,6 +28,10 @@ type BeautifyOptions struct {
 	Uppercase      bool   // Convert keywords to uppercase
 	LineWidth      int    // Max line width before wrapping (0 = no limit)
 	NewlineOnComma bool   // Put each column on new line in SELECT
+	// PreserveComments keeps -- and /* */ comments. A -- comment that
+	// follows code stays at the end of that line; other comments get a
+	// line of their own.
+	PreserveComments bool
 }
 
 // DefaultBeautifyOptions returns standard formatting options.
@@ -37,6 +41,8 @@ func DefaultBeautifyOptions() BeautifyOptions {
 		Uppercase:      true,
 		LineWidth:      80,
 		NewlineOnComma: false,
+
+		PreserveComments: true,
 	}
 }
 
@@ -52,7 +58,8 @@ func NewSQLBeautifier(opts BeautifyOptions) *SQLBeautifier {
 
 // Beautify formats a SQL statement.
 func (b *SQLBeautifier) Beautify(sql string) string {
-	sql = normalizeWhitespace(sql)
+	// The tokenizer skips whitespace itself; collapsing it first would
+	// turn "-- note\nSELECT 1" into one comment.
 	tokens := tokenizeSQL(sql)
 	if b.opts.Uppercase {
 		tokens = uppercaseKeywords(tokens)
@@ -98,11 +105,14 @@ func normalizeWhitespace(s string) string {
 type sqlToken struct {
 	typ   string
 	value string
+	// ownLine marks a comment with nothing but whitespace before it on
+	// its source line.
+	ownLine bool
 }
 
 // skipWhitespace skips whitespace characters
 func skipWhitespace(sql string, i int) int {
-	for i < len(sql) && (sql[i] == ' ' || sql[i] == '\t' || sql[i] == '\n') {
+	for i < len(sql) && isSpace(sql[i]) {
 		i++
 	}
 	return i
@@ -114,7 +124,7 @@ func tokenizeSingleLineComment(sql string, i int) (sqlToken, int) {
 	for j < len(sql) && sql[j] != '\n' {
 		j++
 	}
-	return sqlToken{"comment", sql[i:j]}, j
+	return sqlToken{typ: "comment", value: sql[i:j]}, j
 }
 
 // tokenizeMultiLineComment tokenizes a multi-line comment (/* ... */)
@@ -126,7 +136,7 @@ func tokenizeMultiLineComment(sql string, i int) (sqlToken, int) {
 	if j+1 < len(sql) {
 		j += 2
 	}
-	return sqlToken{"comment", sql[i:j]}, j
+	return sqlToken{typ: "comment", value: sql[i:j]}, j
 }
 
 // tokenizeString tokenizes a string literal ('...')
@@ -141,7 +151,7 @@ func tokenizeString(sql string, i int) (sqlToken, int) {
 	if j < len(sql) {
 		j++
 	}
-	return sqlToken{"string", sql[i:j]}, j
+	return sqlToken{typ: "string", value: sql[i:j]}, j
 }
 
 // tokenizeNumber tokenizes a numeric literal
@@ -150,7 +160,7 @@ func tokenizeNumber(sql string, i int) (sqlToken, int) {
 	for j < len(sql) && (sql[j] >= '0' && sql[j] <= '9' || sql[j] == '.') {
 		j++
 	}
-	return sqlToken{"number", sql[i:j]}, j
+	return sqlToken{typ: "number", value: sql[i:j]}, j
 }
 
 // tokenizeIdentOrKeyword tokenizes an identifier or keyword
@@ -161,24 +171,30 @@ func tokenizeIdentOrKeyword(sql string, i int) (sqlToken, int) {
 	}
 	word := sql[i:j]
 	if allKeywords[strings.ToUpper(word)] {
-		return sqlToken{"keyword", word}, j
+		return sqlToken{typ: "keyword", value: word}, j
 	}
-	return sqlToken{"ident", word}, j
+	return sqlToken{typ: "ident", value: word}, j
 }
 
 func tokenizeSQL(sql string) []sqlToken {
 	var tokens []sqlToken
 	i := 0
+	lineStart := true
 	for i < len(sql) {
 		// Skip whitespace
-		if sql[i] == ' ' || sql[i] == '\t' || sql[i] == '\n' {
-			i = skipWhitespace(sql, i)
+		if isSpace(sql[i]) {
+			j := skipWhitespace(sql, i)
+			if strings.Contains(sql[i:j], "\n") {
+				lineStart = true
+			}
+			i = j
 			continue
 		}
 
 		// Single-line comment
 		if i+1 < len(sql) && sql[i] == '-' && sql[i+1] == '-' {
 			tok, nextIdx := tokenizeSingleLineComment(sql, i)
+			tok.ownLine = lineStart
 			tokens = append(tokens, tok)
 			i = nextIdx
 			continue
@@ -187,10 +203,13 @@ func tokenizeSQL(sql string) []sqlToken {
 		// Multi-line comment
 		if i+1 < len(sql) && sql[i] == '/' && sql[i+1] == '*' {
 			tok, nextIdx := tokenizeMultiLineComment(sql, i)
+			tok.ownLine = lineStart
 			tokens = append(tokens, tok)
 			i = nextIdx
+			lineStart = false
 			continue
 		}
+		lineStart = false
 
 		// String literal
 		if sql[i] == '\'' {
@@ -217,12 +236,16 @@ func tokenizeSQL(sql string) []sqlToken {
 		}
 
 		// Symbol
-		tokens = append(tokens, sqlToken{"symbol", string(sql[i])})
+		tokens = append(tokens, sqlToken{typ: "symbol", value: string(sql[i])})
 		i++
 	}
 	return tokens
 }
 
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
 func isIdentStart(c byte) bool {
 	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_'
 }
@@ -244,31 +267,53 @@ func (b *SQLBeautifier) formatTokens(tokens []sqlToken) string {
 	var sb strings.Builder
 	indent := 0
 	newLine := true
+	// space is set after a token that the next one is separated from; it
+	// is written only if the next token goes on the same line.
+	space := false
+	// breakLine ends the current line unless it is already empty.
+	breakLine := func() {
+		if !newLine {
+			sb.WriteString("\n")
+			newLine = true
+		}
+		space = false
+	}
 
 	for i, tok := range tokens {
 		upper := strings.ToUpper(tok.value)
 
+		if tok.typ == "comment" {
+			if !b.opts.PreserveComments {
+				continue
+			}
+			if tok.ownLine || strings.HasPrefix(tok.value, "/*") {
+				breakLine()
+			}
+			b.writeIndent(&sb, indent, newLine)
+			if space {
+				sb.WriteString(" ")
+			}
+			sb.WriteString(tok.value)
+			newLine = false
+			breakLine()
+			continue
+		}
+
 		if tok.typ == "keyword" && majorKeywords[upper] {
 			switch upper {
 			case "SELECT", "INSERT", "UPDATE", "DELETE", "CREATE", "ALTER", "DROP", "WITH":
-				if i > 0 {
-					sb.WriteString("\n")
-				}
-				newLine = true
+				breakLine()
 			case "FROM", "WHERE", "ORDER", "GROUP", "HAVING", "LIMIT", "OFFSET", "SET", "VALUES":
-				sb.WriteString("\n")
-				newLine = true
+				breakLine()
 			case "AND", "OR":
-				sb.WriteString("\n")
+				breakLine()
 				indent = 1
-				newLine = true
 			case "JOIN", "LEFT", "RIGHT", "INNER":
-				sb.WriteString("\n")
-				newLine = true
+				breakLine()
 			case "UNION", "EXCEPT", "INTERSECT":
-				sb.WriteString("\n\n")
+				breakLine()
+				sb.WriteString("\n")
 				indent = 0
-				newLine = true
 			}
 		}
 
@@ -283,25 +328,33 @@ func (b *SQLBeautifier) formatTokens(tokens []sqlToken) string {
 		}
 
 		if newLine && tok.value != "(" && tok.value != ")" {
-			for j := 0; j < indent; j++ {
-				sb.WriteString(b.opts.IndentString)
-			}
-			newLine = false
+			b.writeIndent(&sb, indent, true)
+		} else if space {
+			sb.WriteString(" ")
 		}
+		newLine = false
 
 		sb.WriteString(tok.value)
 
+		space = false
 		if i+1 < len(tokens) {
 			next := tokens[i+1]
-			if tok.value != "(" && next.value != ")" && next.value != "," && tok.value != "." && next.value != "." {
-				sb.WriteString(" ")
-			}
+			space = tok.value != "(" && next.value != ")" && next.value != "," && tok.value != "." && next.value != "."
 		}
 	}
 
 	return strings.TrimSpace(sb.String())
 }
 
+func (b *SQLBeautifier) writeIndent(sb *strings.Builder, indent int, newLine bool) {
+	if !newLine {
+		return
+	}
+	for j := 0; j < indent; j++ {
+		sb.WriteString(b.opts.IndentString)
+	}
+}
+
 // ============================================================================
 // Schema Browser
 // ============================================================================
@@ -632,6 +685,18 @@ func PrintPlan(plan *QueryPlan, w *tabwriter.Writer) {
 	w.Flush()
 }
 
+// PrintAnalyzedPlan displays the measured plan returned by tsql.ExplainAnalyze.
+func PrintAnalyzedPlan(plan *tsql.AnalyzedPlan, w *tabwriter.Writer) {
+	fmt.Fprintf(w, "Step\tOperation\tObject\tEst. Rows\tActual Rows\tTime (ms)\tDetails\n")
+	fmt.Fprintf(w, "----\t---------\t------\t---------\t-----------\t---------\t-------\n")
+	for i, step := range plan.Steps {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%.3f\t%s\n",
+			i+1, step.Operation, step.Object, step.EstimatedRows, step.ActualRows, step.ElapsedMs, step.Details)
+	}
+	fmt.Fprintf(w, "\t\t\t\t%d\t%.3f\ttotal\n", plan.ActualRows, plan.ElapsedMs)
+	w.Flush()
+}
+
 // ============================================================================
 // Data Export
 // ============================================================================
@@ -672,6 +737,15 @@ func (e *Exporter) Export(rs *tsql.ResultSet, tableName string, w io.Writer) err
 	}
 }
 
+// ExportDatabase writes every table of tenant to w. Only FormatSQL can hold
+// a whole database; it produces the script tsql.ExportSQL writes.
+func (e *Exporter) ExportDatabase(ctx context.Context, db *tsql.DB, tenant string, w io.Writer) error {
+	if e.format != FormatSQL {
+		return fmt.Errorf("format %s cannot export a whole database; use sql", e.format)
+	}
+	return tsql.ExportSQL(ctx, db, tenant, w)
+}
+
 func (e *Exporter) exportCSV(rs *tsql.ResultSet, w io.Writer) error {
 	return exporter.ExportCSV(w, rs, exporter.Options{})
 }
@@ -777,6 +851,7 @@ type ValidationResult struct {
 	Error    string
 	Warnings []string
 	SQLType  string
+	Issues   []SemanticIssue // Unresolved names, set by ValidateSQLSemantic
 }
 
 // ValidateSQL checks if SQL is syntactically correct.
@@ -824,6 +899,394 @@ func ValidateSQL(sql string) ValidationResult {
 	return result
 }
 
+// SemanticIssue is a name ValidateSQLSemantic could not resolve.
+type SemanticIssue struct {
+	Kind       string // "table", "column" or "function"
+	Name       string // As written, e.g. "u.nmae"
+	Table      string // For a column: the table it was looked up in, if only one
+	Suggestion string // Closest known name, or ""
+}
+
+func (i SemanticIssue) String() string {
+	msg := fmt.Sprintf("unknown %s %q", i.Kind, i.Name)
+	if i.Table != "" {
+		msg += fmt.Sprintf(" in table %q", i.Table)
+	}
+	if i.Suggestion != "" {
+		msg += fmt.Sprintf(" - did you mean %q?", i.Suggestion)
+	}
+	return msg
+}
+
+// ValidateSQLSemantic validates like ValidateSQL and also checks the names
+// a SELECT, INSERT, UPDATE or DELETE uses against db: tables must exist in
+// tenant, columns on the table or alias they refer to, and functions must
+// be known to the engine. Columns of views, table functions, sys.* and
+// catalog.* tables, and derived tables with unnamed expressions are not
+// checked.
+func ValidateSQLSemantic(sql string, db *tsql.DB, tenant string) ValidationResult {
+	result := ValidateSQL(sql)
+	if !result.Valid {
+		return result
+	}
+	stmt, err := tsql.ParseSQL(sql)
+	if err != nil {
+		return result
+	}
+
+	c := &semanticChecker{db: db, tenant: tenant, reported: map[string]bool{}}
+	c.statement(stmt)
+	if len(c.issues) > 0 {
+		msgs := make([]string, len(c.issues))
+		for i, issue := range c.issues {
+			msgs[i] = issue.String()
+		}
+		result.Valid = false
+		result.Error = strings.Join(msgs, "; ")
+		result.Issues = c.issues
+	}
+	return result
+}
+
+type semanticChecker struct {
+	db       *tsql.DB
+	tenant   string
+	issues   []SemanticIssue
+	reported map[string]bool
+}
+
+// semanticSource is a table, view, CTE or derived table a query reads,
+// under the name columns are qualified with.
+type semanticSource struct {
+	ref   string   // Alias or table name, lower case
+	table string   // Name for messages
+	cols  []string // nil when unknown: every column is accepted
+}
+
+func (src semanticSource) has(col string) bool {
+	if src.cols == nil {
+		return true
+	}
+	for _, c := range src.cols {
+		if strings.EqualFold(c, col) {
+			return true
+		}
+	}
+	return false
+}
+
+// semanticScope holds the sources and output aliases of one query. A
+// subquery's scope has the enclosing query's scope as parent, so correlated
+// references and CTE names resolve.
+type semanticScope struct {
+	parent  *semanticScope
+	sources []semanticSource
+	aliases []string
+	ctes    map[string]semanticSource
+}
+
+func (c *semanticChecker) report(issue SemanticIssue) {
+	key := issue.Kind + "\x00" + strings.ToLower(issue.Name)
+	if c.reported[key] {
+		return
+	}
+	c.reported[key] = true
+	c.issues = append(c.issues, issue)
+}
+
+func (c *semanticChecker) statement(stmt engine.Statement) {
+	switch s := stmt.(type) {
+	case *engine.Select:
+		c.selectStmt(s, nil)
+	case *engine.Insert:
+		scope := c.targetScope(s.Table)
+		c.targetColumns(scope, s.Cols)
+		for _, row := range s.Rows {
+			c.exprs(scope, row)
+		}
+		if oc := s.OnConflict; oc != nil {
+			c.targetColumns(scope, oc.Cols)
+			// DO UPDATE SET may read the rejected row as EXCLUDED.col.
+			excluded := semanticSource{ref: "excluded", table: s.Table, cols: scope.sources[0].cols}
+			setScope := &semanticScope{sources: []semanticSource{scope.sources[0], excluded}}
+			c.assignments(setScope, oc.Sets)
+		}
+		c.projections(scope, s.Returning)
+	case *engine.Update:
+		scope := c.targetScope(s.Table)
+		c.assignments(scope, s.Sets)
+		c.expr(scope, s.Where)
+		c.projections(scope, s.Returning)
+	case *engine.Delete:
+		scope := c.targetScope(s.Table)
+		c.expr(scope, s.Where)
+		c.projections(scope, s.Returning)
+	}
+}
+
+// targetScope returns the scope of an INSERT, UPDATE or DELETE: its
+// target table.
+func (c *semanticChecker) targetScope(table string) *semanticScope {
+	return &semanticScope{sources: []semanticSource{c.table(nil, table, table)}}
+}
+
+func (c *semanticChecker) targetColumns(scope *semanticScope, cols []string) {
+	for _, col := range cols {
+		c.column(scope, col)
+	}
+}
+
+func (c *semanticChecker) assignments(scope *semanticScope, sets map[string]engine.Expr) {
+	for col, e := range sets {
+		c.column(&semanticScope{sources: scope.sources[:1]}, col)
+		c.expr(scope, e)
+	}
+}
+
+func (c *semanticChecker) selectStmt(s *engine.Select, parent *semanticScope) {
+	scope := &semanticScope{parent: parent}
+	for _, cte := range s.CTEs {
+		if scope.ctes == nil {
+			scope.ctes = map[string]semanticSource{}
+		}
+		ref := strings.ToLower(cte.Name)
+		// A recursive CTE reads itself before its columns are known.
+		scope.ctes[ref] = semanticSource{ref: ref, table: cte.Name}
+		c.selectStmt(cte.Select, scope)
+		cols := cte.Columns
+		if len(cols) == 0 {
+			cols = outputColumns(cte.Select)
+		}
+		scope.ctes[ref] = semanticSource{ref: ref, table: cte.Name, cols: cols}
+	}
+
+	c.from(scope, s.From)
+	for _, j := range s.Joins {
+		c.from(scope, j.Right)
+	}
+	for _, j := range s.Joins {
+		c.expr(scope, j.On)
+	}
+	for _, p := range s.Projs {
+		if p.Alias != "" {
+			scope.aliases = append(scope.aliases, p.Alias)
+		}
+	}
+	c.projections(scope, s.Projs)
+	c.exprs(scope, s.DistinctOn)
+	c.expr(scope, s.Where)
+	c.exprs(scope, s.GroupBy)
+	c.expr(scope, s.Having)
+	c.orderBy(scope, s.OrderBy)
+	if pv := s.Pivot; pv != nil {
+		c.function(pv.AggFunc)
+		c.expr(scope, pv.ValueExpr)
+		c.column(scope, pv.PivotCol)
+	}
+
+	// The other queries of a UNION see the same CTEs.
+	for u := s.Union; u != nil; u = u.Next {
+		c.selectStmt(u.Right, &semanticScope{parent: parent, ctes: scope.ctes})
+	}
+}
+
+func (c *semanticChecker) from(scope *semanticScope, f engine.FromItem) {
+	ref := f.Alias
+	switch {
+	case f.Subquery != nil:
+		c.selectStmt(f.Subquery, scope)
+		scope.sources = append(scope.sources, semanticSource{ref: strings.ToLower(ref), table: ref, cols: outputColumns(f.Subquery)})
+	case f.TableFunc != nil:
+		if _, ok := engine.GetTableFunc(f.TableFunc.Name); !ok {
+			c.report(SemanticIssue{Kind: "function", Name: f.TableFunc.Name})
+		}
+		c.exprs(scope, f.TableFunc.Args)
+		scope.sources = append(scope.sources, semanticSource{ref: strings.ToLower(ref), table: ref})
+	case f.Table != "":
+		if ref == "" {
+			ref = f.Table
+		}
+		scope.sources = append(scope.sources, c.table(scope, f.Table, ref))
+	}
+}
+
+// table resolves a table name read under ref. An unknown table is
+// reported and gets unknown columns, so its columns are not reported too.
+func (c *semanticChecker) table(scope *semanticScope, name, ref string) semanticSource {
+	src := semanticSource{ref: strings.ToLower(ref), table: name}
+	for sc := scope; sc != nil; sc = sc.parent {
+		if cte, ok := sc.ctes[strings.ToLower(name)]; ok {
+			src.cols = cte.cols
+			return src
+		}
+	}
+	if t, err := c.db.Get(c.tenant, name); err == nil {
+		src.cols = make([]string, len(t.Cols))
+		for i, col := range t.Cols {
+			src.cols[i] = col.Name
+		}
+		return src
+	}
+	if strings.Contains(name, ".") {
+		// sys.*, catalog.* and schema-qualified names.
+		return src
+	}
+	cat := c.db.Catalog()
+	if _, ok := cat.GetView("main", name); ok {
+		return src
+	}
+	if _, ok := cat.GetMaterializedView("main", name); ok {
+		return src
+	}
+
+	var names []string
+	for _, t := range c.db.ListTables(c.tenant) {
+		names = append(names, t.Name)
+	}
+	c.report(SemanticIssue{Kind: "table", Name: name, Suggestion: engine.SuggestName(name, names)})
+	return src
+}
+
+// outputColumns returns the column names of a query used as a table, or
+// nil if some are unknown.
+func outputColumns(s *engine.Select) []string {
+	if s.Pivot != nil {
+		return nil
+	}
+	cols := make([]string, 0, len(s.Projs))
+	for _, p := range s.Projs {
+		switch ref, ok := p.Expr.(*engine.VarRef); {
+		case p.Star:
+			return nil
+		case p.Alias != "":
+			cols = append(cols, p.Alias)
+		case ok:
+			cols = append(cols, ref.Name[strings.LastIndex(ref.Name, ".")+1:])
+		default:
+			return nil
+		}
+	}
+	return cols
+}
+
+func (c *semanticChecker) projections(scope *semanticScope, items []engine.SelectItem) {
+	for _, p := range items {
+		if !p.Star {
+			c.expr(scope, p.Expr)
+		}
+	}
+}
+
+var plainColumnName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+func (c *semanticChecker) orderBy(scope *semanticScope, items []engine.OrderItem) {
+	for _, o := range items {
+		// Ordinals and expressions are left to the engine.
+		if plainColumnName.MatchString(o.Col) {
+			c.column(scope, o.Col)
+		}
+	}
+}
+
+func (c *semanticChecker) exprs(scope *semanticScope, es []engine.Expr) {
+	for _, e := range es {
+		c.expr(scope, e)
+	}
+}
+
+func (c *semanticChecker) expr(scope *semanticScope, e engine.Expr) {
+	switch ex := e.(type) {
+	case *engine.VarRef:
+		c.column(scope, ex.Name)
+	case *engine.Unary:
+		c.expr(scope, ex.Expr)
+	case *engine.Binary:
+		c.expr(scope, ex.Left)
+		c.expr(scope, ex.Right)
+	case *engine.IsNull:
+		c.expr(scope, ex.Expr)
+	case *engine.InExpr:
+		c.expr(scope, ex.Expr)
+		c.exprs(scope, ex.Values)
+	case *engine.LikeExpr:
+		c.exprs(scope, []engine.Expr{ex.Expr, ex.Pattern, ex.Escape})
+	case *engine.RegexpExpr:
+		c.exprs(scope, []engine.Expr{ex.Expr, ex.Pattern})
+	case *engine.BetweenExpr:
+		c.exprs(scope, []engine.Expr{ex.Expr, ex.Lo, ex.Hi})
+	case *engine.CaseExpr:
+		c.expr(scope, ex.Operand)
+		for _, w := range ex.Whens {
+			c.expr(scope, w.When)
+			c.expr(scope, w.Then)
+		}
+		c.expr(scope, ex.Else)
+	case *engine.FuncCall:
+		c.function(ex.Name)
+		c.exprs(scope, ex.Args)
+		if ex.Over != nil {
+			c.exprs(scope, ex.Over.PartitionBy)
+			c.orderBy(scope, ex.Over.OrderBy)
+		}
+	case *engine.ExistsExpr:
+		c.selectStmt(ex.Select, scope)
+	case *engine.SubqueryExpr:
+		c.selectStmt(ex.Select, scope)
+	}
+}
+
+func (c *semanticChecker) function(name string) {
+	if !engine.IsKnownFunction(name) {
+		c.report(SemanticIssue{Kind: "function", Name: name, Suggestion: engine.SuggestName(name, engine.FunctionNames())})
+	}
+}
+
+// column resolves a column reference, qualified by a table or alias or
+// not, in scope and the scopes enclosing it.
+func (c *semanticChecker) column(scope *semanticScope, name string) {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		qual, col := strings.ToLower(name[:i]), name[i+1:]
+		var refs []string
+		for sc := scope; sc != nil; sc = sc.parent {
+			for _, src := range sc.sources {
+				if src.ref != qual {
+					refs = append(refs, src.ref)
+					continue
+				}
+				if col != "*" && !src.has(col) {
+					c.report(SemanticIssue{Kind: "column", Name: name, Table: src.table, Suggestion: engine.SuggestName(col, src.cols)})
+				}
+				return
+			}
+		}
+		c.report(SemanticIssue{Kind: "table", Name: name[:i], Suggestion: engine.SuggestName(qual, refs)})
+		return
+	}
+
+	for sc := scope; sc != nil; sc = sc.parent {
+		for _, src := range sc.sources {
+			if src.has(name) {
+				return
+			}
+		}
+		for _, alias := range sc.aliases {
+			if strings.EqualFold(alias, name) {
+				return
+			}
+		}
+	}
+	issue := SemanticIssue{Kind: "column", Name: name}
+	candidates := append([]string(nil), scope.aliases...)
+	for _, src := range scope.sources {
+		candidates = append(candidates, src.cols...)
+	}
+	if len(scope.sources) == 1 {
+		issue.Table = scope.sources[0].table
+	}
+	issue.Suggestion = engine.SuggestName(name, candidates)
+	c.report(issue)
+}
+
 // ============================================================================
 // SQL Linter (multi-rule analysis)
 // ============================================================================
@@ -1209,10 +1672,16 @@ func readSQLInputFrom(args []string, stdin io.Reader) (string, error) {
 func main() {
 	beautifyCmd := flag.NewFlagSet("beautify", flag.ExitOnError)
 	beautifyUpper := beautifyCmd.Bool("upper", true, "Uppercase keywords")
+	beautifyComments := beautifyCmd.Bool("comments", true, "Keep -- and /* */ comments")
 
 	validateCmd := flag.NewFlagSet("validate", flag.ExitOnError)
+	validateDB := validateCmd.String("db", "", "Database file to check table, column and function names against")
+	validateTenant := validateCmd.String("tenant", "default", "Tenant name for -db")
 
 	explainCmd := flag.NewFlagSet("explain", flag.ExitOnError)
+	explainAnalyze := explainCmd.Bool("analyze", false, "Execute the query and report actual row counts and timings")
+	explainDB := explainCmd.String("db", "", "Database file to run -analyze against")
+	explainTenant := explainCmd.String("tenant", "default", "Tenant name for -analyze")
 
 	lintCmd := flag.NewFlagSet("lint", flag.ExitOnError)
 
@@ -1221,6 +1690,12 @@ func main() {
 
 	diffCmd := flag.NewFlagSet("diff", flag.ExitOnError)
 
+	dumpCmd := flag.NewFlagSet("dump", flag.ExitOnError)
+	dumpDB := dumpCmd.String("db", "", "Database file to export")
+	dumpTenant := dumpCmd.String("tenant", "default", "Tenant name")
+	dumpFormat := dumpCmd.String("format", "sql", "Output format (sql)")
+	dumpOut := dumpCmd.String("o", "", "Output file (default: stdout)")
+
 	replCmd := flag.NewFlagSet("repl", flag.ExitOnError)
 	replTenant := replCmd.String("tenant", "default", "Tenant name")
 
@@ -1239,6 +1714,7 @@ func main() {
 		}
 		opts := DefaultBeautifyOptions()
 		opts.Uppercase = *beautifyUpper
+		opts.PreserveComments = *beautifyComments
 		b := NewSQLBeautifier(opts)
 		fmt.Println(b.Beautify(sql))
 
@@ -1246,10 +1722,18 @@ func main() {
 		validateCmd.Parse(os.Args[2:])
 		sql := readSQLInput(validateCmd.Args())
 		if sql == "" {
-			fmt.Println("Usage: sqltools validate <sql>  or  sqltools validate @file.sql")
+			fmt.Println("Usage: sqltools validate [-db=file] <sql>  or  sqltools validate @file.sql")
 			os.Exit(1)
 		}
 		result := ValidateSQL(sql)
+		if *validateDB != "" {
+			db, err := tsql.LoadFromFile(*validateDB)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			result = ValidateSQLSemantic(sql, db, *validateTenant)
+		}
 		if result.Valid {
 			fmt.Printf("✓ Valid %s statement\n", result.SQLType)
 			for _, w := range result.Warnings {
@@ -1264,9 +1748,28 @@ func main() {
 		explainCmd.Parse(os.Args[2:])
 		sql := readSQLInput(explainCmd.Args())
 		if sql == "" {
-			fmt.Println("Usage: sqltools explain <sql>  or  sqltools explain @file.sql")
+			fmt.Println("Usage: sqltools explain [-analyze [-db=file]] <sql>  or  sqltools explain @file.sql")
 			os.Exit(1)
 		}
+		if *explainAnalyze {
+			db := tsql.NewDB()
+			if *explainDB != "" {
+				loaded, err := tsql.LoadFromFile(*explainDB)
+				if err != nil {
+					fmt.Printf("Error: %v\n", err)
+					os.Exit(1)
+				}
+				db = loaded
+			}
+			plan, err := tsql.ExplainAnalyze(context.Background(), db, *explainTenant, sql)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			PrintAnalyzedPlan(plan, w)
+			break
+		}
 		plan, err := ExplainQuery(sql)
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
@@ -1330,6 +1833,33 @@ func main() {
 			fmt.Printf("  Parameters: %s\n", strings.Join(t.Parameters, ", "))
 		}
 
+	case "dump":
+		dumpCmd.Parse(os.Args[2:])
+		if *dumpDB == "" {
+			fmt.Println("Usage: sqltools dump -db=file [-tenant=default] [-format=sql] [-o=out.sql]")
+			os.Exit(1)
+		}
+		db, err := tsql.LoadFromFile(*dumpDB)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		var out io.Writer = os.Stdout
+		if *dumpOut != "" {
+			f, err := os.Create(*dumpOut)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			defer f.Close()
+			out = f
+		}
+		exp := NewExporter(ExportFormat(strings.ToLower(*dumpFormat)))
+		if err := exp.ExportDatabase(context.Background(), db, *dumpTenant, out); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
 	case "repl":
 		replCmd.Parse(os.Args[2:])
 		runToolsREPL(*replTenant)
@@ -1346,11 +1876,12 @@ func printUsage() {
 Commands:
   beautify [-upper=true] <sql>    Format SQL statement
   validate <sql>                  Check SQL syntax
-  explain <sql>                   Show query execution plan
+  explain [-analyze] <sql>        Show query execution plan (-analyze runs it)
   lint <sql>                      Multi-rule SQL analysis
   normalize [-placeholders] <sql> Canonicalize SQL for comparison
   diff <fileA.sql> <fileB.sql>    Compare two SQL files
   templates                       List query templates
+  dump -db=file [-format=sql]     Export a database as a SQL script
   repl [-tenant=default]          Interactive SQL tools shell
 
 File input: Use @filename to read SQL from a file, e.g.:
@@ -1366,6 +1897,8 @@ Examples:
   sqltools normalize -placeholders "SELECT * FROM users WHERE id = 42"
   sqltools diff old_schema.sql new_schema.sql
   sqltools explain "SELECT * FROM orders JOIN users ON orders.user_id = users.id"
+  sqltools explain -analyze -db=shop.db "SELECT * FROM orders WHERE total > 100"
+  sqltools dump -db=shop.db -o=shop.sql
   sqltools repl`)
 }
 
@@ -1482,14 +2015,15 @@ func toolsHandleSchema(parts []string, tenant string, browser *SchemaBrowser) {
 	}
 }
 
-// toolsHandleValidate validates SQL syntax and prints the result.
-func toolsHandleValidate(parts []string) {
+// toolsHandleValidate validates SQL against the session database and
+// prints the result.
+func toolsHandleValidate(parts []string, db *tsql.DB, tenant string) {
 	if len(parts) < 2 {
 		fmt.Println("Usage: .validate <sql>")
 		return
 	}
 	sql := strings.Join(parts[1:], " ")
-	result := ValidateSQL(sql)
+	result := ValidateSQLSemantic(sql, db, tenant)
 	if result.Valid {
 		fmt.Printf("✓ Valid %s statement\n", result.SQLType)
 		for _, w := range result.Warnings {
@@ -1500,13 +2034,28 @@ func toolsHandleValidate(parts []string) {
 	}
 }
 
-// toolsHandleExplain prints the query plan for a SQL statement.
-func toolsHandleExplain(parts []string) {
+// toolsHandleExplain prints the query plan for a SQL statement. With
+// --analyze the statement runs against the session database and the
+// measured plan is printed instead.
+func toolsHandleExplain(parts []string, db *tsql.DB, tenant string) {
+	analyze := len(parts) > 1 && (parts[1] == "--analyze" || parts[1] == "-analyze")
+	if analyze {
+		parts = parts[1:]
+	}
 	if len(parts) < 2 {
-		fmt.Println("Usage: .explain <sql>")
+		fmt.Println("Usage: .explain [--analyze] <sql>")
 		return
 	}
 	sql := strings.Join(parts[1:], " ")
+	if analyze {
+		plan, err := tsql.ExplainAnalyze(context.Background(), db, tenant, sql)
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		PrintAnalyzedPlan(plan, tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0))
+		return
+	}
 	plan, err := ExplainQuery(sql)
 	if err != nil {
 		fmt.Println("Error:", err)
@@ -1552,7 +2101,7 @@ Commands:
   .history [n]          Show last n queries (default 10)
   .beautify <sql>       Format SQL
   .validate <sql>       Check SQL syntax
-  .explain <sql>        Show query plan
+  .explain [--analyze] <sql>  Show query plan (--analyze runs it and measures)
   .export <format> <file> <sql>   Export results (csv, json, ndjson, sql)
   .template <name>      Show template
   .templates            List all templates`)
@@ -1586,10 +2135,10 @@ Commands:
 		fmt.Println(beautifier.Beautify(sql))
 
 	case ".validate":
-		toolsHandleValidate(parts)
+		toolsHandleValidate(parts, db, tenant)
 
 	case ".explain":
-		toolsHandleExplain(parts)
+		toolsHandleExplain(parts, db, tenant)
 
 	case ".templates":
 		for _, t := range CommonTemplates() {
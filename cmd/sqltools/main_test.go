@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"text/tabwriter"
 	"time"
 
 	tsql "github.com/SimonWaldherr/tinySQL"
@@ -77,6 +78,45 @@ func TestBeautify_Comments(t *testing.T) {
 	}
 }
 
+func TestBeautify_InlineComment(t *testing.T) {
+	b := NewSQLBeautifier(DefaultBeautifyOptions())
+	got := b.Beautify("select a, -- first column\n b from t -- the table\nwhere x = 1")
+	for _, line := range []string{"SELECT a, -- first column", "FROM t -- the table", "WHERE x = 1"} {
+		if !strings.Contains(got, line+"\n") && !strings.HasSuffix(got, line) {
+			t.Errorf("missing line %q in:\n%s", line, got)
+		}
+	}
+}
+
+func TestBeautify_BlockComment(t *testing.T) {
+	b := NewSQLBeautifier(DefaultBeautifyOptions())
+	got := b.Beautify("select a /* the answer */ from t")
+	want := "SELECT a\n/* the answer */\nFROM t"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestBeautify_CommentsInSubquery(t *testing.T) {
+	b := NewSQLBeautifier(DefaultBeautifyOptions())
+	got := b.Beautify("select * from t where id in (select id /* active */ from u -- users\n where active = true)")
+	for _, want := range []string{"  /* active */\n", "FROM u -- users\n", "WHERE active = TRUE)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+}
+
+func TestBeautify_StripComments(t *testing.T) {
+	opts := DefaultBeautifyOptions()
+	opts.PreserveComments = false
+	b := NewSQLBeautifier(opts)
+	got := b.Beautify("-- header\nselect a, /* b */ c -- trailing\nfrom t")
+	if want := "SELECT a, c\nFROM t"; got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
 // ---- Validator tests --------------------------------------------------------
 
 func TestValidateSQL_ValidSelect(t *testing.T) {
@@ -151,6 +191,136 @@ func TestValidateSQL_Update(t *testing.T) {
 	}
 }
 
+// ---- Semantic validator tests -----------------------------------------------
+
+// semanticTestDB returns a database with users (id, name) and orders
+// (id, user_id, total).
+func semanticTestDB(t *testing.T) *tsql.DB {
+	t.Helper()
+	db := tsql.NewDB()
+	for _, sql := range []string{
+		"CREATE TABLE users (id INT, name TEXT)",
+		"CREATE TABLE orders (id INT, user_id INT, total FLOAT)",
+	} {
+		if _, err := tsql.ExecSQL(context.Background(), db, "default", sql); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+// onlyIssue fails unless res reports exactly one issue and returns it.
+func onlyIssue(t *testing.T, res ValidationResult) SemanticIssue {
+	t.Helper()
+	if res.Valid || len(res.Issues) != 1 {
+		t.Fatalf("want one issue, got valid=%v issues=%+v", res.Valid, res.Issues)
+	}
+	return res.Issues[0]
+}
+
+func TestValidateSQLSemantic_UnknownTable(t *testing.T) {
+	db := semanticTestDB(t)
+	issue := onlyIssue(t, ValidateSQLSemantic("SELECT id FROM customers", db, "default"))
+	if issue.Kind != "table" || issue.Name != "customers" || issue.Suggestion != "" {
+		t.Errorf("issue = %+v, want unknown table customers without suggestion", issue)
+	}
+	issue = onlyIssue(t, ValidateSQLSemantic("SELECT id FROM userz", db, "default"))
+	if issue.Suggestion != "users" {
+		t.Errorf("suggestion for userz = %q, want users", issue.Suggestion)
+	}
+}
+
+func TestValidateSQLSemantic_UnknownColumn(t *testing.T) {
+	db := semanticTestDB(t)
+	res := ValidateSQLSemantic("SELECT id, email FROM users", db, "default")
+	issue := onlyIssue(t, res)
+	if issue.Kind != "column" || issue.Name != "email" || issue.Table != "users" || issue.Suggestion != "" {
+		t.Errorf("issue = %+v, want unknown column email in users", issue)
+	}
+	if !strings.Contains(res.Error, `unknown column "email" in table "users"`) {
+		t.Errorf("Error = %q", res.Error)
+	}
+	for _, sql := range []string{
+		"UPDATE users SET email = 'x' WHERE id = 1",
+		"INSERT INTO users (id, email) VALUES (1, 'x')",
+		"DELETE FROM users WHERE email = 'x'",
+	} {
+		if issue := onlyIssue(t, ValidateSQLSemantic(sql, db, "default")); issue.Name != "email" {
+			t.Errorf("%s: issue = %+v, want email", sql, issue)
+		}
+	}
+}
+
+func TestValidateSQLSemantic_TypoSuggestions(t *testing.T) {
+	db := semanticTestDB(t)
+	for _, tc := range []struct {
+		sql, name, suggestion string
+	}{
+		{"SELECT nmae FROM users", "nmae", "name"},
+		{"SELECT id FROM orders WHERE totl > 10", "totl", "total"},
+		{"SELECT UPPR(name) FROM users", "UPPR", "UPPER"},
+	} {
+		issue := onlyIssue(t, ValidateSQLSemantic(tc.sql, db, "default"))
+		if issue.Name != tc.name || issue.Suggestion != tc.suggestion {
+			t.Errorf("%s: issue = %+v, want %s -> %s", tc.sql, issue, tc.name, tc.suggestion)
+		}
+	}
+	res := ValidateSQLSemantic("SELECT nmae FROM users", db, "default")
+	if !strings.Contains(res.Error, `did you mean "name"?`) {
+		t.Errorf("Error = %q, want a did-you-mean hint", res.Error)
+	}
+}
+
+func TestValidateSQLSemantic_ValidQueries(t *testing.T) {
+	db := semanticTestDB(t)
+	for _, sql := range []string{
+		"SELECT id, name FROM users WHERE id = 1",
+		"SELECT user_id, COUNT(*) AS n, SUM(total) FROM orders GROUP BY user_id HAVING n > 1 ORDER BY n",
+		"SELECT CASE WHEN id > 1 THEN UPPER(name) ELSE COALESCE(name, '') END FROM users",
+		"SELECT id FROM users WHERE id IN (SELECT user_id FROM orders) UNION SELECT user_id FROM orders",
+		"SELECT ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY total) FROM orders",
+		"WITH big AS (SELECT user_id, total FROM orders WHERE total > 10) SELECT user_id FROM big",
+		"INSERT INTO users (id, name) VALUES (1, 'a') ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name",
+		"UPDATE orders SET total = total * 2 WHERE user_id = 1",
+		"SELECT * FROM sys.tables",
+	} {
+		if res := ValidateSQLSemantic(sql, db, "default"); !res.Valid {
+			t.Errorf("%s: %s", sql, res.Error)
+		}
+	}
+	if res := ValidateSQLSemantic("SELEKT 1", db, "default"); res.Valid || len(res.Issues) != 0 {
+		t.Errorf("syntax error: %+v, want invalid without semantic issues", res)
+	}
+}
+
+func TestValidateSQLSemantic_Aliases(t *testing.T) {
+	db := semanticTestDB(t)
+	for _, sql := range []string{
+		"SELECT u.name, o.total FROM users u JOIN orders AS o ON o.user_id = u.id",
+		"SELECT u.id FROM users u WHERE EXISTS (SELECT 1 FROM orders o WHERE o.user_id = u.id)",
+		"SELECT s.uid FROM (SELECT id AS uid FROM users) s ORDER BY s.uid",
+		"SELECT name AS n FROM users ORDER BY n",
+	} {
+		if res := ValidateSQLSemantic(sql, db, "default"); !res.Valid {
+			t.Errorf("%s: %s", sql, res.Error)
+		}
+	}
+
+	// The alias decides which table a column is looked up in.
+	issue := onlyIssue(t, ValidateSQLSemantic("SELECT o.name FROM users u JOIN orders o ON o.user_id = u.id", db, "default"))
+	if issue.Name != "o.name" || issue.Table != "orders" {
+		t.Errorf("issue = %+v, want o.name in orders", issue)
+	}
+	issue = onlyIssue(t, ValidateSQLSemantic("SELECT s.id FROM (SELECT id AS uid FROM users) s", db, "default"))
+	if issue.Name != "s.id" || issue.Suggestion != "uid" {
+		t.Errorf("issue = %+v, want s.id -> uid", issue)
+	}
+	issue = onlyIssue(t, ValidateSQLSemantic("SELECT x.id FROM users u", db, "default"))
+	if issue.Kind != "table" || issue.Name != "x" || issue.Suggestion != "u" {
+		t.Errorf("issue = %+v, want unknown alias x -> u", issue)
+	}
+}
+
 // ---- ExplainQuery tests -----------------------------------------------------
 
 func TestExplainQuery_Select(t *testing.T) {
@@ -205,6 +375,29 @@ func TestExplainQuery_InvalidSQL(t *testing.T) {
 	}
 }
 
+func TestPrintAnalyzedPlan(t *testing.T) {
+	db := tsql.NewDB()
+	ctx := context.Background()
+	if _, err := tsql.ExecSQL(ctx, db, "default", "CREATE TABLE t (x INT)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tsql.ExecSQL(ctx, db, "default", "INSERT INTO t VALUES (1), (2), (3)"); err != nil {
+		t.Fatal(err)
+	}
+	plan, err := tsql.ExplainAnalyze(ctx, db, "default", "SELECT x FROM t ORDER BY x DESC")
+	if err != nil {
+		t.Fatalf("ExplainAnalyze: %v", err)
+	}
+	var buf bytes.Buffer
+	PrintAnalyzedPlan(plan, tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0))
+	out := buf.String()
+	for _, want := range []string{"Actual Rows", "SCAN", "total"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
 // ---- SchemaBrowser tests ----------------------------------------------------
 
 func TestSchemaBrowser_ListTables(t *testing.T) {
@@ -604,3 +797,24 @@ func TestExporterNDJSON(t *testing.T) {
 		t.Fatalf("expected two NDJSON records, got %q", buf.String())
 	}
 }
+
+func TestExporterExportDatabase(t *testing.T) {
+	ctx := context.Background()
+	db := tsql.NewDB()
+	if _, err := tsql.ExecSQL(ctx, db, "default", `CREATE TABLE t (id INT, name TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tsql.ExecSQL(ctx, db, "default", `INSERT INTO t VALUES (1, NULL)`); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := NewExporter(FormatSQL).ExportDatabase(ctx, db, "default", &buf); err != nil {
+		t.Fatalf("ExportDatabase: %v", err)
+	}
+	if !strings.Contains(buf.String(), `CREATE TABLE "t"`) || !strings.Contains(buf.String(), "(1, NULL)") {
+		t.Fatalf("unexpected dump:\n%s", buf.String())
+	}
+	if err := NewExporter(FormatCSV).ExportDatabase(ctx, db, "default", &buf); err == nil {
+		t.Fatal("csv database export should fail")
+	}
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
 	"errors"
@@ -14,12 +13,15 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"text/tabwriter"
 	"time"
 
 	tsql "github.com/SimonWaldherr/tinySQL"
 	"github.com/SimonWaldherr/tinySQL/exporter"
+	"github.com/SimonWaldherr/tinySQL/internal/lineedit"
+	"github.com/SimonWaldherr/tinySQL/internal/telemetry"
 )
 
 // Config holds the runtime configuration.
@@ -32,6 +34,13 @@ type Config struct {
 	Timer     bool
 	NullValue string
 	Mode      OutputMode
+	// SlowLog, when set, receives every executed statement; it keeps the
+	// ones over its threshold.
+	SlowLog *telemetry.SlowQueryLogger
+	// TimerOut receives the "Time: ..." line printed after each statement
+	// while Timer is on; nil means stderr, so timings stay out of
+	// redirected results.
+	TimerOut io.Writer
 }
 
 type OutputMode string
@@ -84,6 +93,9 @@ func runCLI(args []string) error {
 		cmd     = fs.String("cmd", "", "Run specific SQL and exit")
 		batch   = fs.Bool("batch", false, "Force batch mode")
 		outFile = fs.String("output", "", "Write output to file")
+		timer   = fs.Bool("timer", false, "Print the execution time of every statement to stderr")
+		slowLog = fs.String("slow-query-log", "", "Append slow statements to this file as JSON lines")
+		slowMin = fs.Duration("slow-query-threshold", 500*time.Millisecond, "Minimum execution time logged by -slow-query-log")
 	)
 
 	if err := fs.Parse(args); err != nil {
@@ -96,9 +108,18 @@ func runCLI(args []string) error {
 		Header:    *headers,
 		Echo:      *echo,
 		Batch:     *batch,
+		Timer:     *timer,
 		Mode:      OutputMode(*mode),
 		NullValue: "", // default empty for column mode, usually
 	}
+	if *slowLog != "" {
+		l, err := telemetry.OpenSlowQueryLog(*slowLog, *slowMin)
+		if err != nil {
+			return err
+		}
+		defer l.Close()
+		cfg.SlowLog = l
+	}
 
 	// Determine Database Path
 	remaining := fs.Args()
@@ -123,6 +144,11 @@ func runCLI(args []string) error {
 		}
 	}()
 
+	// Seed from TINYSQL_INIT_FILE / TINYSQL_INIT_SQL before any user input
+	if err := runInitSQL(db, cfg, savePath); err != nil {
+		return err
+	}
+
 	// Setup Output Writer
 	var out io.Writer = os.Stdout
 	if cfg.Output != "" {
@@ -177,20 +203,36 @@ func runCLI(args []string) error {
 
 // ---- REPL (Interactive Shell) -----------------------------------------------
 
+// errQuit is returned by handleMeta for .quit and .exit so Run can close an
+// open .output file before returning.
+var errQuit = errors.New("quit")
+
 type Repl struct {
 	db       *tsql.DB
 	cfg      *Config
 	savePath string
 	out      io.Writer
 	buf      strings.Builder
+	// defaultOut is where results go when no .output file is open;
+	// outFile is the file opened by .output, if any.
+	defaultOut io.Writer
+	outFile    *os.File
+	// stopWatch cancels a running .watch; the Ctrl-C handler calls it
+	// instead of exiting.
+	mu        sync.Mutex
+	stopWatch context.CancelFunc
+	// in supplies input lines. Run opens the terminal line editor (or a
+	// plain scanner when stdin is not a terminal) unless one is set.
+	in lineedit.Reader
 }
 
 func NewRepl(db *tsql.DB, cfg *Config, savePath string, out io.Writer) *Repl {
 	return &Repl{
-		db:       db,
-		cfg:      cfg,
-		savePath: savePath,
-		out:      out,
+		db:         db,
+		cfg:        cfg,
+		savePath:   savePath,
+		out:        out,
+		defaultOut: out,
 	}
 }
 
@@ -198,16 +240,25 @@ func (r *Repl) Run() error {
 	fmt.Fprintf(r.out, "TinySQL version v0.4.0 (mimicking sqlite3)\n")
 	fmt.Fprintf(r.out, "Enter \".help\" for usage hints.\n")
 	fmt.Fprintf(r.out, "Connected to: %s\n", r.savePath)
+	defer r.closeOutput()
 
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Buffer(make([]byte, 1024*1024), 10*1024*1024) // 10MB buffer
+	if r.in == nil {
+		history, err := lineedit.LoadHistory(lineedit.DefaultHistoryPath(), lineedit.DefaultHistorySize)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "history: %v\n", err)
+		}
+		r.in = lineedit.Open(os.Stdin, os.Stdout, history, r.completer())
+	}
 
 	// Handle Ctrl+C gracefully
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 	go func() {
 		for range sigChan {
-			// If user hits Ctrl+C, reset buffer or exit if empty
+			// If user hits Ctrl+C, stop .watch, reset buffer or exit if empty
+			if r.cancelWatch() {
+				continue
+			}
 			if r.buf.Len() > 0 {
 				fmt.Fprintln(r.out, "^C")
 				r.buf.Reset()
@@ -218,18 +269,31 @@ func (r *Repl) Run() error {
 		}
 	}()
 
-	r.printPrompt()
-
-	for scanner.Scan() {
-		line := scanner.Text()
+	for {
+		line, err := r.in.ReadLine(r.prompt())
+		if errors.Is(err, lineedit.ErrInterrupt) {
+			// Ctrl-C in the line editor: same as the signal handler above.
+			if r.buf.Len() == 0 {
+				return nil
+			}
+			r.buf.Reset()
+			continue
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
 		trimmed := strings.TrimSpace(line)
 
 		// Meta commands (only processed if buffer is empty)
 		if r.buf.Len() == 0 && strings.HasPrefix(trimmed, ".") {
-			if err := r.handleMeta(trimmed); err != nil {
+			if err := r.handleMeta(trimmed); errors.Is(err, errQuit) {
+				return nil
+			} else if err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			}
-			r.printPrompt()
 			continue
 		}
 
@@ -250,17 +314,38 @@ func (r *Repl) Run() error {
 				}
 			}
 		}
-		r.printPrompt()
 	}
-	return scanner.Err()
 }
 
-func (r *Repl) printPrompt() {
+// completer completes table and column names of the current tenant.
+func (r *Repl) completer() lineedit.Completer {
+	return lineedit.SQLCompleter{
+		Tables: func() []string {
+			var names []string
+			for _, t := range r.db.ListTables(r.cfg.Tenant) {
+				names = append(names, t.Name)
+			}
+			return names
+		},
+		Columns: func(table string) []string {
+			t, err := r.db.Get(r.cfg.Tenant, table)
+			if err != nil {
+				return nil
+			}
+			names := make([]string, len(t.Cols))
+			for i, c := range t.Cols {
+				names[i] = c.Name
+			}
+			return names
+		},
+	}.Complete
+}
+
+func (r *Repl) prompt() string {
 	if r.buf.Len() == 0 {
-		fmt.Fprint(r.out, "tinysql> ")
-	} else {
-		fmt.Fprint(r.out, "   ...> ")
+		return "tinysql> "
 	}
+	return "   ...> "
 }
 
 func (r *Repl) handleMeta(line string) error {
@@ -272,7 +357,13 @@ func (r *Repl) handleMeta(line string) error {
 	case ".help":
 		printHelp(r.out)
 	case ".quit", ".exit":
-		os.Exit(0)
+		return errQuit
+	case ".output":
+		target := "stdout"
+		if len(args) > 0 {
+			target = args[0]
+		}
+		return r.setOutput(target)
 	case ".tables":
 		printTables(r.out, r.db, r.cfg.Tenant)
 	case ".schema":
@@ -333,12 +424,86 @@ func (r *Repl) handleMeta(line string) error {
 		return countTables(r.out, r.db, r.cfg.Tenant, args)
 	case ".stats":
 		return showStats(r.out, r.db, r.cfg.Tenant)
+	case ".watch":
+		rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), cmd))
+		secs, query, _ := strings.Cut(rest, " ")
+		n, err := strconv.ParseFloat(secs, 64)
+		if err != nil || n <= 0 || strings.TrimSpace(query) == "" {
+			return errors.New("usage: .watch SECONDS QUERY")
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		r.mu.Lock()
+		r.stopWatch = cancel
+		r.mu.Unlock()
+		defer r.cancelWatch()
+		return r.watch(ctx, time.Duration(n*float64(time.Second)), query)
 	default:
 		return fmt.Errorf("unknown command: %s", cmd)
 	}
 	return nil
 }
 
+// watch clears the screen and runs query every interval until ctx is
+// cancelled, printing results in the current mode.
+func (r *Repl) watch(ctx context.Context, interval time.Duration, query string) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		fmt.Fprint(r.out, "\033[2J\033[H")
+		if _, err := execute(ctx, r.db, r.cfg, query, r.out); err != nil && ctx.Err() == nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// cancelWatch stops a running .watch and reports whether there was one.
+func (r *Repl) cancelWatch() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopWatch == nil {
+		return false
+	}
+	r.stopWatch()
+	r.stopWatch = nil
+	return true
+}
+
+// setOutput sends results to the file at target, or back to the default
+// output for "stdout". A previously opened file is closed first.
+func (r *Repl) setOutput(target string) error {
+	if err := r.closeOutput(); err != nil {
+		return err
+	}
+	if target == "stdout" {
+		return nil
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("cannot open output file: %w", err)
+	}
+	r.outFile = f
+	r.out = f
+	return nil
+}
+
+// closeOutput closes the .output file, if any, and restores the default
+// output.
+func (r *Repl) closeOutput() error {
+	r.out = r.defaultOut
+	if r.outFile == nil {
+		return nil
+	}
+	err := r.outFile.Close()
+	r.outFile = nil
+	return err
+}
+
 func printHelp(out io.Writer) {
 	fmt.Fprintln(out, `
 .count [TABLE...]      Show row counts for tables
@@ -349,12 +514,14 @@ func printHelp(out io.Writer) {
 .import FILE [TABLE]   Import CSV/JSON file into table
 .mode MODE             Set output mode (column, list, csv, json, table)
 .nullvalue STRING      Use STRING in place of NULL values
+.output ?FILE?         Send output to FILE or stdout if FILE is omitted
 .read FILENAME         Execute SQL in FILENAME
 .save FILENAME         Write in-memory database into FILENAME
 .schema ?TABLE?        Show the CREATE statements
 .stats                 Show database statistics
 .tables                List names of tables
-.timer on|off          Turn SQL timer on or off`)
+.timer on|off          Turn SQL timer on or off
+.watch SECONDS QUERY   Re-run QUERY every SECONDS until Ctrl-C`)
 }
 
 // dumpTables outputs INSERT statements for specified tables (or all).
@@ -577,8 +744,16 @@ func execute(ctx context.Context, db *tsql.DB, cfg *Config, sqlText string, out
 			return dirty, err
 		}
 
+		execStart := time.Now()
 		res, err := tsql.Execute(ctx, db, cfg.Tenant, parsed)
 		duration := time.Since(start)
+		rows := 0
+		if res != nil {
+			rows = len(res.Rows)
+		}
+		if logErr := cfg.SlowLog.Record(stmtSQL, cfg.Tenant, execStart, rows, err); logErr != nil {
+			fmt.Fprintf(os.Stderr, "slow query log: %v\n", logErr)
+		}
 
 		if err != nil {
 			return dirty, err
@@ -596,12 +771,54 @@ func execute(ctx context.Context, db *tsql.DB, cfg *Config, sqlText string, out
 		}
 
 		if cfg.Timer {
-			fmt.Fprintf(out, "Run Time: real %.3fs\n", duration.Seconds())
+			timerOut := cfg.TimerOut
+			if timerOut == nil {
+				timerOut = os.Stderr
+			}
+			fmt.Fprintf(timerOut, "Time: %.3fms\n", float64(duration)/float64(time.Millisecond))
 		}
 	}
 	return dirty, nil
 }
 
+// runInitSQL executes the scripts named by the TINYSQL_INIT_FILE and
+// TINYSQL_INIT_SQL environment variables (file first) against db. Result
+// sets produced by the seed scripts are discarded. A database that already
+// has tables in the tenant was seeded on an earlier start and is left as is.
+func runInitSQL(db *tsql.DB, cfg *Config, savePath string) error {
+	if len(db.ListTables(cfg.Tenant)) > 0 {
+		return nil
+	}
+	seedCfg := *cfg
+	seedCfg.Echo = false
+	seedCfg.Timer = false
+
+	var scripts []string
+	if path := os.Getenv("TINYSQL_INIT_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("TINYSQL_INIT_FILE: %w", err)
+		}
+		scripts = append(scripts, string(data))
+	}
+	if sqlText := os.Getenv("TINYSQL_INIT_SQL"); sqlText != "" {
+		scripts = append(scripts, sqlText)
+	}
+
+	dirty := false
+	for _, script := range scripts {
+		d, err := execute(context.Background(), db, &seedCfg, script, io.Discard)
+		dirty = dirty || d
+		if err != nil {
+			return fmt.Errorf("init SQL: %w", err)
+		}
+	}
+	if dirty && savePath != "" {
+		return tsql.SaveToFile(db, savePath)
+	}
+	return nil
+}
+
 // ---- Output Formatters ------------------------------------------------------
 
 type Printer interface {
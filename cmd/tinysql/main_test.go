@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -12,6 +13,8 @@ import (
 	"time"
 
 	tsql "github.com/SimonWaldherr/tinySQL"
+	"github.com/SimonWaldherr/tinySQL/internal/lineedit"
+	"github.com/SimonWaldherr/tinySQL/internal/telemetry"
 )
 
 func TestBuildTinysql(t *testing.T) {
@@ -204,15 +207,18 @@ func TestExecute_Select(t *testing.T) {
 
 func TestExecute_Timer(t *testing.T) {
 	db := setupTestDB(t)
-	cfg := &Config{Tenant: "default", Mode: ModeColumn, Header: true, Timer: true}
+	var timing bytes.Buffer
+	cfg := &Config{Tenant: "default", Mode: ModeColumn, Header: true, Timer: true, TimerOut: &timing}
 	var buf bytes.Buffer
-	_, err := execute(context.Background(), db, cfg, "SELECT 1 AS x", &buf)
+	_, err := execute(context.Background(), db, cfg, "SELECT 1 AS x; SELECT name FROM users", &buf)
 	if err != nil {
 		t.Fatalf("execute: %v", err)
 	}
-	out := buf.String()
-	if !strings.Contains(out, "Run Time:") {
-		t.Errorf("expected 'Run Time:' in timer output, got:\n%s", out)
+	if got := strings.Count(timing.String(), "Time: "); got != 2 || !strings.HasSuffix(timing.String(), "ms\n") {
+		t.Errorf("expected one 'Time: ...ms' line per statement, got:\n%s", timing.String())
+	}
+	if strings.Contains(buf.String(), "Time:") {
+		t.Errorf("timing leaked into the result output:\n%s", buf.String())
 	}
 }
 
@@ -236,6 +242,29 @@ func TestExecute_MultiStatement(t *testing.T) {
 	}
 }
 
+func TestExecute_SlowQueryLog(t *testing.T) {
+	db := setupTestDB(t)
+	var log bytes.Buffer
+	cfg := &Config{Tenant: "default", Mode: ModeColumn, SlowLog: &telemetry.SlowQueryLogger{Output: &log}}
+	var buf bytes.Buffer
+	if _, err := execute(context.Background(), db, cfg, "SELECT name FROM users; SELECT 1 AS x", &buf); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(log.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"sql":"SELECT name FROM users"`) || !strings.Contains(lines[1], `"rows":1`) {
+		t.Fatalf("slow query log:\n%s", log.String())
+	}
+
+	log.Reset()
+	cfg.SlowLog.Threshold = time.Hour
+	if _, err := execute(context.Background(), db, cfg, "SELECT 1 AS x", &buf); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if log.Len() != 0 {
+		t.Fatalf("fast query was logged: %s", log.String())
+	}
+}
+
 func TestSplitStatements(t *testing.T) {
 	tests := []struct {
 		input string
@@ -388,6 +417,168 @@ func TestReplHandleMeta_Timer(t *testing.T) {
 	}
 }
 
+func TestReplTimerTogglesStatementTiming(t *testing.T) {
+	db := setupTestDB(t)
+	var timing bytes.Buffer
+	cfg := &Config{Tenant: "default", Mode: ModeList, TimerOut: &timing}
+	r := NewRepl(db, cfg, "", io.Discard)
+	r.in = lineedit.NewScanner(strings.NewReader(
+		"SELECT 1;\n.timer on\nSELECT 2;\nSELECT 3;\n.timer off\nSELECT 4;\n"), io.Discard)
+	if err := r.Run(); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got := strings.Count(timing.String(), "Time: "); got != 2 {
+		t.Fatalf("timing lines = %d, want 2 (only while .timer is on):\n%s", got, timing.String())
+	}
+}
+
+// -timer works together with other output flags and never writes into the
+// result file.
+func TestRunCLITimerFlag(t *testing.T) {
+	dir := t.TempDir()
+	outPath := filepath.Join(dir, "out.json")
+
+	stderr := os.Stderr
+	rd, wr, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stderr = wr
+	runErr := runCLI([]string{"-timer", "-mode", "json", "-output", outPath, ":memory:", "SELECT 1 AS x"})
+	os.Stderr = stderr
+	wr.Close()
+	timing, _ := io.ReadAll(rd)
+	if runErr != nil {
+		t.Fatalf("runCLI: %v", runErr)
+	}
+
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(strings.TrimSpace(string(data)), "[") || strings.Contains(string(data), "Time:") {
+		t.Fatalf("output = %q, want only the JSON result", data)
+	}
+	if !strings.Contains(string(timing), "Time: ") {
+		t.Fatalf("stderr = %q, want the statement timing", timing)
+	}
+}
+
+func TestReplOutputRedirectsResults(t *testing.T) {
+	db := setupTestDB(t)
+	dir := t.TempDir()
+	first := filepath.Join(dir, "result.csv")
+	second := filepath.Join(dir, "second.csv")
+	cfg := &Config{Tenant: "default", Mode: ModeCSV, Header: true}
+	var term bytes.Buffer
+	r := NewRepl(db, cfg, "", &term)
+	r.in = lineedit.NewScanner(strings.NewReader(
+		".output "+first+"\nSELECT name FROM users ORDER BY name;\n"+
+			".output "+second+"\nSELECT 2 AS n;\n"+
+			".output stdout\nSELECT 'back' AS s;\n.quit\nSELECT 'after quit' AS s;\n"), io.Discard)
+	if err := r.Run(); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	data, err := os.ReadFile(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); !strings.HasPrefix(got, "name\nAlice\n") || strings.Contains(got, "n\n2") {
+		t.Fatalf("first file = %q, want only the first query's CSV", got)
+	}
+	// The second .output closed the first file; .quit closed the second.
+	if data, _ := os.ReadFile(second); string(data) != "n\n2\n" {
+		t.Fatalf("second file = %q", data)
+	}
+	if r.outFile != nil {
+		t.Fatal("output file still open after .quit")
+	}
+	out := term.String()
+	if strings.Contains(out, "Alice") || !strings.Contains(out, "back") || strings.Contains(out, "after quit") {
+		t.Fatalf("terminal output = %q, want only the result after .output stdout", out)
+	}
+}
+
+func TestReplOutputUnwritablePath(t *testing.T) {
+	db := setupTestDB(t)
+	var buf bytes.Buffer
+	r := NewRepl(db, &Config{Tenant: "default", Mode: ModeList}, "", &buf)
+
+	err := r.handleMeta(".output " + filepath.Join(t.TempDir(), "missing", "out.txt"))
+	if err == nil || !strings.Contains(err.Error(), "cannot open output file") {
+		t.Fatalf("err = %v, want a cannot open output file error", err)
+	}
+	if r.out != &buf {
+		t.Fatal("failed .output changed the output writer")
+	}
+}
+
+func TestReplWatchRerunsQuery(t *testing.T) {
+	db := setupTestDB(t)
+	var buf bytes.Buffer
+	r := NewRepl(db, &Config{Tenant: "default", Mode: ModeList}, "", &buf)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 600*time.Millisecond)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		stmt, _ := tsql.ParseSQL("INSERT INTO orders (id, user_id, amount) VALUES (102, 2, 1.5)")
+		for ctx.Err() == nil {
+			if _, err := tsql.Execute(context.Background(), db, "default", stmt); err != nil {
+				t.Error(err)
+				return
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}()
+	if err := r.watch(ctx, 100*time.Millisecond, "SELECT COUNT(*) AS n FROM orders"); err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+	<-done
+
+	frames := strings.Split(buf.String(), "\033[2J\033[H")[1:]
+	if len(frames) < 5 {
+		t.Fatalf("query ran %d times in 0.6s, want at least 5", len(frames))
+	}
+	first := strings.TrimSpace(frames[0])
+	last := strings.TrimSpace(frames[len(frames)-1])
+	if first == last {
+		t.Fatalf("count did not change while rows were inserted: %q", frames)
+	}
+}
+
+// Ctrl-C during .watch stops the loop instead of leaving the shell.
+func TestReplWatchStopsOnInterrupt(t *testing.T) {
+	db := setupTestDB(t)
+	r := NewRepl(db, &Config{Tenant: "default", Mode: ModeList}, "", io.Discard)
+
+	errc := make(chan error, 1)
+	go func() { errc <- r.handleMeta(".watch 0.05 SELECT name FROM users") }()
+	deadline := time.Now().Add(time.Second)
+	for !r.cancelWatch() {
+		if time.Now().After(deadline) {
+			t.Fatal(".watch never started")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Fatalf(".watch: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal(".watch did not stop after interrupt")
+	}
+
+	for _, line := range []string{".watch", ".watch 0 SELECT 1", ".watch x SELECT 1", ".watch 1"} {
+		if err := r.handleMeta(line); err == nil || !strings.Contains(err.Error(), "usage") {
+			t.Errorf("%s: err = %v, want usage", line, err)
+		}
+	}
+}
+
 func TestReplHandleMeta_Dump(t *testing.T) {
 	db := setupTestDB(t)
 	cfg := &Config{Tenant: "default", Mode: ModeColumn}
@@ -420,3 +611,76 @@ func TestReplHandleMeta_Unknown(t *testing.T) {
 		t.Errorf("expected 'unknown' in error, got: %v", err)
 	}
 }
+
+func TestRunCLIInitSQLFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	initFile := filepath.Join(dir, "seed.sql")
+	if err := os.WriteFile(initFile, []byte("CREATE TABLE items (id INT, label TEXT);"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TINYSQL_INIT_FILE", initFile)
+	t.Setenv("TINYSQL_INIT_SQL", "INSERT INTO items VALUES (1, 'seeded'); SELECT * FROM items;")
+
+	outPath := filepath.Join(dir, "out.csv")
+	if err := runCLI([]string{"-mode", "csv", "-output", outPath, ":memory:", "SELECT label FROM items"}); err != nil {
+		t.Fatalf("runCLI: %v", err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != "label\nseeded\n" {
+		t.Fatalf("output = %q, want only the user query's result", got)
+	}
+}
+
+func TestRunCLIInitSQLSkipsExistingDatabase(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TINYSQL_INIT_SQL", "CREATE TABLE items (id INT, label TEXT); INSERT INTO items VALUES (1, 'seeded');")
+
+	dbPath := filepath.Join(dir, "seed.db")
+	outPath := filepath.Join(dir, "out.csv")
+	for i := 0; i < 2; i++ {
+		if err := runCLI([]string{"-mode", "csv", "-output", outPath, dbPath, "SELECT COUNT(*) AS n FROM items"}); err != nil {
+			t.Fatalf("start %d: %v", i+1, err)
+		}
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != "n\n1\n" {
+		t.Fatalf("output after restart = %q, want the seed applied once", got)
+	}
+}
+
+// Run reads statements spanning several lines from its line source, here a
+// scanner as used when stdin is not a terminal.
+func TestReplRunReadsFromLineSource(t *testing.T) {
+	db := setupTestDB(t)
+	cfg := &Config{Tenant: "default", Mode: ModeList}
+	var buf bytes.Buffer
+	r := NewRepl(db, cfg, "", &buf)
+	r.in = lineedit.NewScanner(strings.NewReader("SELECT\n  42 AS answer;\n"), io.Discard)
+	if err := r.Run(); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !strings.Contains(buf.String(), "42") {
+		t.Fatalf("output = %q, want the query result", buf.String())
+	}
+}
+
+func TestReplCompleterUsesTenantSchema(t *testing.T) {
+	db := setupTestDB(t)
+	r := NewRepl(db, &Config{Tenant: "default"}, "", io.Discard)
+	complete := r.completer()
+
+	line := []rune("SELECT * FROM us")
+	if start, got := complete(line, len(line)); start != 14 || fmt.Sprint(got) != "[users]" {
+		t.Fatalf("table completion = %d %v", start, got)
+	}
+	line = []rune("SELECT o.am FROM orders o")
+	if start, got := complete(line, 11); start != 9 || fmt.Sprint(got) != "[amount]" {
+		t.Fatalf("column completion = %d %v", start, got)
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"hash/crc32"
+	"strconv"
+	"strings"
+)
+
+// cacheControl turns a `-- cache:` front-matter value (or the
+// -cache-default flag) into a Cache-Control header: a number of seconds
+// becomes "max-age=N, public", "no-store" is passed through and an empty
+// value sends no header. It reports false for anything else.
+func cacheControl(v string) (string, bool) {
+	v = strings.TrimSpace(v)
+	switch {
+	case v == "":
+		return "", true
+	case strings.EqualFold(v, "no-store"):
+		return "no-store", true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return "", false
+	}
+	return fmt.Sprintf("max-age=%d, public", n), true
+}
+
+// pageETag is the strong entity tag of a rendered page: the CRC-32 of its
+// HTML.
+func pageETag(body []byte) string {
+	return fmt.Sprintf(`"%08x"`, crc32.ChecksumIEEE(body))
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const cachePage = "SELECT 'text' AS component, 'cached' AS content;"
+
+func TestPageCacheFrontMatter(t *testing.T) {
+	cases := []struct {
+		name, page, fallback, want string
+	}{
+		{"max-age", "-- cache: 60\n" + cachePage, "", "max-age=60, public"},
+		{"no-store", "-- cache: no-store\n" + cachePage, "30", "no-store"},
+		{"default", cachePage, "30", "max-age=30, public"},
+		{"no default", cachePage, "", ""},
+		{"invalid falls back", "-- cache: soon\n" + cachePage, "no-store", "no-store"},
+	}
+	for _, tc := range cases {
+		h := paramsHandler(t, tc.page)
+		h.cache = tc.fallback
+		rec := get(h, "/tasks")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d", tc.name, rec.Code)
+		}
+		if got := rec.Header().Get("Cache-Control"); got != tc.want {
+			t.Errorf("%s: Cache-Control = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestPageETagNotModified(t *testing.T) {
+	h := paramsHandler(t, "-- cache: 60\n"+cachePage)
+	first := get(h, "/tasks")
+	etag := first.Header().Get("ETag")
+	if etag == "" || etag != pageETag(first.Body.Bytes()) {
+		t.Fatalf("ETag = %q, want the CRC-32 of the body", etag)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Fatalf("matching If-None-Match: status %d, %d body bytes; want 304 and no body", rec.Code, rec.Body.Len())
+	}
+
+	req.Header.Set("If-None-Match", `"00000000"`)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("stale If-None-Match: status %d, want 200", rec.Code)
+	}
+}
+
+func TestNoStorePageIsNeverRevalidated(t *testing.T) {
+	h := paramsHandler(t, "-- cache: no-store\n"+cachePage)
+	rec := get(h, "/tasks")
+	if etag := rec.Header().Get("ETag"); etag != "" {
+		t.Fatalf("no-store page has ETag %q", etag)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+	req.Header.Set("If-None-Match", pageETag(rec.Body.Bytes()))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("no-store page answered %d to If-None-Match, want 200", rec.Code)
+	}
+}
+
+func TestFormPageIsNotCached(t *testing.T) {
+	h := paramsHandler(t, "-- cache: 60\n"+formPage)
+	rec := get(h, "/tasks")
+	if rec.Code != http.StatusOK || len(rec.Result().Cookies()) != 1 {
+		t.Fatalf("status %d, cookies %v; want a signed form page", rec.Code, rec.Result().Cookies())
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "private, no-store" {
+		t.Errorf("Cache-Control = %q, want private, no-store", got)
+	}
+	if etag := rec.Header().Get("ETag"); etag != "" {
+		t.Errorf("form page has ETag %q", etag)
+	}
+}
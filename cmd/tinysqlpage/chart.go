@@ -0,0 +1,68 @@
+package main
+
+import (
+	"html"
+	"strconv"
+	"strings"
+
+	tsql "github.com/SimonWaldherr/tinySQL"
+	"github.com/SimonWaldherr/tinySQL/internal/svgchart"
+)
+
+// chartComponent is an inline SVG bar or line chart. Every row is one
+// point: label is the x-axis category, value the number and the optional
+// series column groups points into bars side by side or separate lines:
+//
+//	SELECT 'bar_chart' AS component, 'Sales' AS title,
+//	       region AS label, total AS value, year AS series FROM sales
+//
+// Rows without a label are skipped, so a leading header row keeps the
+// chart (and its "No data" placeholder) on the page when the data query
+// returns nothing.
+type chartComponent struct {
+	Title string
+	SVG   string
+}
+
+func (c chartComponent) HTML() string {
+	var sb strings.Builder
+	sb.WriteString(`<section class="component card chart">`)
+	if c.Title != "" {
+		sb.WriteString(`<div class="section-title">` + html.EscapeString(c.Title) + `</div>`)
+	}
+	sb.WriteString(`<div class="chart-wrap">` + c.SVG + `</div></section>`)
+	return sb.String()
+}
+
+// buildChartComponent charts rs as kind, "bar_chart" or "line_chart". A
+// line chart whose labels are all dates or timestamps gets a time axis.
+// The first row may name the axes with x_label and y_label.
+func buildChartComponent(rs *tsql.ResultSet, kind string) component {
+	first := rs.Rows[0]
+	xLabel, yLabel := stringValue(first, "x_label"), stringValue(first, "y_label")
+	if xLabel == "" {
+		xLabel = "label"
+	}
+	if yLabel == "" {
+		yLabel = "value"
+	}
+	var pts []svgchart.Point
+	for _, row := range rs.Rows {
+		label, ok := tsql.GetVal(row, "label")
+		if !ok || label == nil {
+			continue
+		}
+		p := svgchart.Point{Label: formatValue(label), Series: stringValue(row, "series"), Null: true}
+		if v, err := strconv.ParseFloat(stringValue(row, "value"), 64); err == nil {
+			p.Value, p.Null = v, false
+		}
+		// svgchart.Line uses a time axis only when every point has a time.
+		p.Time, _ = svgchart.ParseTime(label)
+		pts = append(pts, p)
+	}
+	svg := svgchart.Bar(xLabel, yLabel, pts)
+	if kind == "line_chart" {
+		svg = svgchart.Line(xLabel, yLabel, pts)
+	}
+	return chartComponent{Title: stringValue(first, "title"), SVG: svg}
+}
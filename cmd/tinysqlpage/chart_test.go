@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+// pageSVG renders page and returns the one inline chart on it, failing
+// unless it is well-formed XML.
+func pageSVG(t *testing.T, page string) string {
+	t.Helper()
+	rec := get(paramsHandler(t, page), "/tasks")
+	body := rec.Body.String()
+	start, end := strings.Index(body, "<svg"), strings.Index(body, "</svg>")
+	if start < 0 || end < start {
+		t.Fatalf("status %d, no chart on page:\n%s", rec.Code, body)
+	}
+	svg := body[start : end+len("</svg>")]
+	dec := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			return svg
+		} else if err != nil {
+			t.Fatalf("invalid SVG: %v\n%s", err, svg)
+		}
+	}
+}
+
+func TestBarChartComponent(t *testing.T) {
+	svg := pageSVG(t, "SELECT 'bar_chart' AS component, 'By status' AS title, status AS label, COUNT(*) AS value FROM tasks GROUP BY status ORDER BY status;")
+	// active = 2 and done = 1 on a 0..2 axis: full height and half height.
+	for _, want := range []string{
+		`<rect class="bar" x="72.4" y="16" width="39.2" height="200"`,
+		`<rect class="bar" x="128.4" y="116" width="39.2" height="100"`,
+		">active</text>", ">done</text>",
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("bar chart lacks %s:\n%s", want, svg)
+		}
+	}
+}
+
+func TestBarChartComponentSeriesAndNegatives(t *testing.T) {
+	svg := pageSVG(t, `SELECT 'bar_chart' AS component, 'Q1' AS label, -10 AS value, 'north' AS series
+UNION ALL SELECT 'bar_chart', 'Q1', 30, 'south'
+UNION ALL SELECT 'bar_chart', 'Q2', 20, 'north'
+UNION ALL SELECT 'bar_chart', 'Q2', 10, 'south';`)
+	if n := strings.Count(svg, `class="bar"`); n != 4 {
+		t.Errorf("bars = %d, want two per label", n)
+	}
+	if strings.Count(svg, ">Q1</text>") != 1 || !strings.Contains(svg, ">north</text>") || !strings.Contains(svg, ">south</text>") {
+		t.Errorf("want one tick per label and a legend:\n%s", svg)
+	}
+	// The axis runs from -10, so the negative bar starts at zero and
+	// extends down.
+	if !strings.Contains(svg, ">-10</text>") || !strings.Contains(svg, `y="186" width="22.4" height="50"`) {
+		t.Errorf("negative value not drawn below zero:\n%s", svg)
+	}
+}
+
+func TestLineChartComponent(t *testing.T) {
+	svg := pageSVG(t, `SELECT 'line_chart' AS component, 'Visits' AS title, 'day' AS x_label, '2024-01-03' AS label, 7 AS value
+UNION ALL SELECT 'line_chart', '', '', '2024-01-01', 3
+UNION ALL SELECT 'line_chart', '', '', '2024-01-02', 5;`)
+	if !strings.Contains(svg, `<polyline class="line" points="64,216 184,116 304,16"`) {
+		t.Errorf("time series not sorted and spaced by date:\n%s", svg)
+	}
+	if !strings.Contains(svg, ">day</text>") || !strings.Contains(svg, ">value</text>") {
+		t.Errorf("axis labels missing:\n%s", svg)
+	}
+}
+
+func TestChartComponentWithoutData(t *testing.T) {
+	svg := pageSVG(t, `SELECT 'bar_chart' AS component, 'Nothing' AS title, NULL AS label, NULL AS value
+UNION ALL SELECT 'bar_chart', '', status, id FROM tasks WHERE status = 'archived';`)
+	if !strings.Contains(svg, "No data to chart") {
+		t.Errorf("empty chart lacks placeholder:\n%s", svg)
+	}
+}
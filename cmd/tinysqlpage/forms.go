@@ -0,0 +1,360 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	tsql "github.com/SimonWaldherr/tinySQL"
+)
+
+// formField is one input of a form component.
+type formField struct {
+	Name     string
+	Label    string
+	Type     string // text, number, date or select
+	Required bool
+	Options  []string
+	Value    string
+}
+
+// formComponent renders a data-entry form. Its first row holds the form's
+// title, action SQL and submit label; every row describes one field:
+//
+//	SELECT 'form' AS component, 'New task' AS title,
+//	       'INSERT INTO tasks (title, status) VALUES (:title, :status)' AS action,
+//	       'post' AS method, 'title' AS name, 'text' AS field_type, TRUE AS required
+//	UNION ALL SELECT 'form', '', '', '', 'status', 'select', FALSE ...
+//
+// The action never leaves the server: the form posts its ID to /submit,
+// which looks the action up and binds the submitted values into it.
+type formComponent struct {
+	ID     string
+	Page   string
+	Token  string // per-render CSRF token, see signForms
+	Title  string
+	Action string
+	Submit string
+	Fields []formField
+}
+
+func (c formComponent) HTML() string {
+	var sb strings.Builder
+	sb.WriteString(`<section class="component card form">`)
+	if c.Title != "" {
+		sb.WriteString(`<div class="section-title">` + html.EscapeString(c.Title) + `</div>`)
+	}
+	sb.WriteString(`<form method="post" action="/submit">`)
+	sb.WriteString(`<input type="hidden" name="_form" value="` + html.EscapeString(c.ID) + `">`)
+	sb.WriteString(`<input type="hidden" name="_page" value="` + html.EscapeString(c.Page) + `">`)
+	sb.WriteString(`<input type="hidden" name="_token" value="` + html.EscapeString(c.Token) + `">`)
+	for _, f := range c.Fields {
+		name := html.EscapeString(f.Name)
+		required := ""
+		if f.Required {
+			required = " required"
+		}
+		sb.WriteString(`<label>` + html.EscapeString(f.Label))
+		if f.Type == "select" {
+			sb.WriteString(`<select name="` + name + `"` + required + `>`)
+			if !f.Required {
+				sb.WriteString(`<option value=""></option>`)
+			}
+			for _, opt := range f.Options {
+				selected := ""
+				if opt == f.Value {
+					selected = " selected"
+				}
+				sb.WriteString(`<option value="` + html.EscapeString(opt) + `"` + selected + `>` + html.EscapeString(opt) + `</option>`)
+			}
+			sb.WriteString(`</select>`)
+		} else {
+			step := ""
+			if f.Type == "number" {
+				step = ` step="any"`
+			}
+			sb.WriteString(`<input type="` + f.Type + `" name="` + name + `" value="` + html.EscapeString(f.Value) + `"` + step + required + `>`)
+		}
+		sb.WriteString(`</label>`)
+	}
+	sb.WriteString(`<button type="submit">` + html.EscapeString(c.Submit) + `</button></form></section>`)
+	return sb.String()
+}
+
+func buildFormComponent(rs *tsql.ResultSet) component {
+	first := rs.Rows[0]
+	form := formComponent{
+		Title:  stringValue(first, "title"),
+		Action: stringValue(first, "action"),
+		Submit: stringValue(first, "submit"),
+	}
+	if form.Submit == "" {
+		form.Submit = "Submit"
+	}
+	for _, row := range rs.Rows {
+		name := stringValue(row, "name")
+		if name == "" {
+			continue
+		}
+		f := formField{
+			Name:     name,
+			Label:    stringValue(row, "label"),
+			Type:     strings.ToLower(stringValue(row, "field_type")),
+			Required: isTruthy(stringValue(row, "required")),
+			Value:    stringValue(row, "value"),
+		}
+		if f.Label == "" {
+			f.Label = name
+		}
+		switch f.Type {
+		case "number", "date":
+		case "select":
+			for _, opt := range strings.Split(stringValue(row, "options"), ",") {
+				if opt = strings.TrimSpace(opt); opt != "" {
+					f.Options = append(f.Options, opt)
+				}
+			}
+		default:
+			f.Type = "text"
+		}
+		form.Fields = append(form.Fields, f)
+	}
+	return form
+}
+
+func isTruthy(s string) bool {
+	switch strings.ToLower(s) {
+	case "true", "1", "yes", "y":
+		return true
+	}
+	return false
+}
+
+// registerForms gives the forms on page their IDs and remembers them for
+// /submit. IDs are derived from the page and form, so they survive a
+// restart and re-rendering the page finds the same form again.
+func (h *pageHandler) registerForms(page string, comps []component) {
+	h.formMu.Lock()
+	defer h.formMu.Unlock()
+	for i, c := range comps {
+		form, ok := c.(formComponent)
+		if !ok {
+			continue
+		}
+		sum := sha256.New()
+		fmt.Fprintf(sum, "%s\x00%s", page, form.Action)
+		for _, f := range form.Fields {
+			fmt.Fprintf(sum, "\x00%s", f.Name)
+		}
+		form.ID = hex.EncodeToString(sum.Sum(nil))[:16]
+		form.Page = page
+		if h.forms == nil {
+			h.forms = make(map[string]formComponent)
+		}
+		h.forms[form.ID] = form
+		comps[i] = form
+	}
+}
+
+// formCookie holds the browser's random form session. Form tokens are
+// signed over it, so a token scraped by another site is useless without
+// the victim's cookie, which that site can neither read nor set.
+const formCookie = "tinysqlpage_form"
+
+// signForms gives every form in comps a fresh token for this browser,
+// setting the form session cookie when the request has none. It reports
+// whether comps had a form, i.e. whether the page is now per-browser.
+func (h *pageHandler) signForms(w http.ResponseWriter, r *http.Request, comps []component) bool {
+	var session string
+	for i, c := range comps {
+		form, ok := c.(formComponent)
+		if !ok {
+			continue
+		}
+		if session == "" {
+			if ck, err := r.Cookie(formCookie); err == nil && ck.Value != "" {
+				session = ck.Value
+			} else {
+				session = randomHex(16)
+				http.SetCookie(w, &http.Cookie{
+					Name:     formCookie,
+					Value:    session,
+					Path:     "/",
+					HttpOnly: true,
+					Secure:   r.TLS != nil,
+					SameSite: http.SameSiteLaxMode,
+				})
+			}
+		}
+		nonce := randomHex(8)
+		form.Token = nonce + "." + h.formMAC(session, form.ID, nonce)
+		comps[i] = form
+	}
+	return session != ""
+}
+
+// validToken reports whether token was issued by signForms for form id to
+// the browser that sent r.
+func (h *pageHandler) validToken(r *http.Request, id, token string) bool {
+	ck, err := r.Cookie(formCookie)
+	if err != nil || ck.Value == "" {
+		return false
+	}
+	nonce, mac, ok := strings.Cut(token, ".")
+	if !ok || nonce == "" {
+		return false
+	}
+	return hmac.Equal([]byte(mac), []byte(h.formMAC(ck.Value, id, nonce)))
+}
+
+func (h *pageHandler) formMAC(session, id, nonce string) string {
+	h.formMu.Lock()
+	if h.formKey == nil {
+		h.formKey = []byte(randomHex(32))
+	}
+	key := h.formKey
+	h.formMu.Unlock()
+	mac := hmac.New(sha256.New, key)
+	fmt.Fprintf(mac, "%s\x00%s\x00%s", session, id, nonce)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// lookupForm returns the registered form id. When it is unknown, e.g.
+// after a restart, page is rendered once to register its forms.
+func (h *pageHandler) lookupForm(r *http.Request, id, page string) (formComponent, bool) {
+	h.formMu.Lock()
+	form, ok := h.forms[id]
+	h.formMu.Unlock()
+	if ok || page == "" || strings.HasPrefix(filepath.Clean(page), "..") {
+		return form, ok
+	}
+	data, err := os.ReadFile(filepath.Join(h.pagesDir, filepath.Clean(page)+".sql"))
+	if err != nil {
+		return form, false
+	}
+	comps, err := h.renderComponents(r.Context(), string(data), nil)
+	if err != nil {
+		return form, false
+	}
+	h.registerForms(filepath.Clean(page), comps)
+	h.formMu.Lock()
+	defer h.formMu.Unlock()
+	form, ok = h.forms[id]
+	return form, ok
+}
+
+// serveSubmit handles POST /submit: it checks the form token, validates the
+// submitted fields of a registered form, runs the form's action with them
+// bound as literals and redirects back to the page the form came from.
+func (h *pageHandler) serveSubmit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form data", http.StatusBadRequest)
+		return
+	}
+	if !h.validToken(r, r.PostForm.Get("_form"), r.PostForm.Get("_token")) {
+		http.Error(w, "invalid or missing form token; reload the page and try again", http.StatusForbidden)
+		return
+	}
+	form, ok := h.lookupForm(r, r.PostForm.Get("_form"), r.PostForm.Get("_page"))
+	if !ok {
+		http.Error(w, "unknown form", http.StatusBadRequest)
+		return
+	}
+	values, problems := form.validate(r.PostForm)
+	if len(problems) > 0 {
+		http.Error(w, strings.Join(problems, "\n"), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+	script := bindSQL(form.Action, func(name string) (boundValue, bool) {
+		v, ok := values[name]
+		return v, ok
+	})
+	if err := execSQLScript(ctx, h.db, h.tenant, script); err != nil {
+		log.Printf("submit %s: %v", form.Page, err)
+		http.Error(w, "failed to save form", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, backTo(r, form.Page), http.StatusSeeOther)
+}
+
+// validate checks the submitted values against the form's fields and
+// returns them by field name. Empty optional fields are NULL and only
+// number fields bind as numbers. Only declared fields are returned, so
+// extra POST keys cannot reach the SQL.
+func (form formComponent) validate(post url.Values) (map[string]boundValue, []string) {
+	values := make(map[string]boundValue, len(form.Fields))
+	var problems []string
+	for _, f := range form.Fields {
+		v := strings.TrimSpace(post.Get(f.Name))
+		if v == "" {
+			if f.Required {
+				problems = append(problems, f.Label+" is required")
+			}
+			values[f.Name] = boundValue{null: true}
+			continue
+		}
+		switch f.Type {
+		case "number":
+			if _, err := strconv.ParseFloat(v, 64); err != nil || !numericParam.MatchString(v) {
+				problems = append(problems, f.Label+" must be a number")
+			}
+		case "date":
+			if _, err := time.Parse("2006-01-02", v); err != nil {
+				problems = append(problems, f.Label+" must be a date (YYYY-MM-DD)")
+			}
+		case "select":
+			valid := false
+			for _, opt := range f.Options {
+				valid = valid || opt == v
+			}
+			if !valid {
+				problems = append(problems, f.Label+" must be one of "+strings.Join(f.Options, ", "))
+			}
+		}
+		values[f.Name] = boundValue{text: v, quote: f.Type != "number"}
+	}
+	return values, problems
+}
+
+// backTo returns where to send the browser after a submit: the referring
+// page when it is on this server, otherwise the form's page.
+func backTo(r *http.Request, page string) string {
+	if ref, err := url.Parse(r.Referer()); err == nil && ref.Path != "" && (ref.Host == "" || ref.Host == r.Host) {
+		return ref.RequestURI()
+	}
+	if page == "index" {
+		return "/"
+	}
+	return "/" + page
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const formPage = `-- nav_label: Tasks
+SELECT 'form' AS component, 'New task' AS title,
+       'INSERT INTO tasks (id, title, status) VALUES (:id, :title, :status)' AS action,
+       'post' AS method, 'Add' AS submit,
+       'id' AS name, 'number' AS field_type, 'ID' AS label, TRUE AS required, '' AS options
+UNION ALL
+SELECT 'form', '', '', '', '', 'title', 'text', 'Title', TRUE, ''
+UNION ALL
+SELECT 'form', '', '', '', '', 'status', 'select', 'Status', FALSE, 'active, done';
+SELECT 'table' AS component, 'Tasks' AS title, id, title AS task, status FROM tasks ORDER BY id;`
+
+var (
+	formIDPattern    = regexp.MustCompile(`name="_form" value="([0-9a-f]+)"`)
+	formTokenPattern = regexp.MustCompile(`name="_token" value="([0-9a-f.]+)"`)
+)
+
+// renderForm renders the tasks page and returns its form's ID and token
+// and the form session cookie the page set.
+func renderForm(t *testing.T, h *pageHandler) (id, token string, cookie *http.Cookie) {
+	t.Helper()
+	rec := get(h, "/tasks")
+	page := rec.Body.String()
+	m, tok := formIDPattern.FindStringSubmatch(page), formTokenPattern.FindStringSubmatch(page)
+	cookies := rec.Result().Cookies()
+	if m == nil || tok == nil || len(cookies) != 1 {
+		t.Fatalf("page has no signed form (cookies %v):\n%s", cookies, page)
+	}
+	return m[1], tok[1], cookies[0]
+}
+
+// submit posts fields to /submit with the given form session cookie.
+func submit(h http.Handler, fields url.Values, cookie *http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(fields.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Referer", "http://"+req.Host+"/tasks?status=active")
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// postForm renders the tasks page, then submits fields to the form on it.
+func postForm(t *testing.T, h *pageHandler, fields url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+	id, token, cookie := renderForm(t, h)
+	fields.Set("_form", id)
+	fields.Set("_page", "tasks")
+	fields.Set("_token", token)
+	return submit(h, fields, cookie)
+}
+
+func TestFormComponentRendersInputs(t *testing.T) {
+	body := get(paramsHandler(t, formPage), "/tasks").Body.String()
+	for _, want := range []string{
+		`<form method="post" action="/submit">`,
+		`<input type="number" name="id" value="" step="any" required>`,
+		`<input type="text" name="title" value="" required>`,
+		`<select name="status"><option value=""></option><option value="active">active</option><option value="done">done</option></select>`,
+		`<button type="submit">Add</button>`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("form lacks %s", want)
+		}
+	}
+	if strings.Contains(body, "INSERT INTO") {
+		t.Error("action SQL leaked into the page")
+	}
+}
+
+func TestFormSubmitInsertsAndRedirects(t *testing.T) {
+	h := paramsHandler(t, formPage)
+	rec := postForm(t, h, url.Values{"id": {"4"}, "title": {"ship it"}, "status": {"active"}})
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/tasks?status=active" {
+		t.Fatalf("submit: status %d, location %q, body %s", rec.Code, rec.Header().Get("Location"), rec.Body)
+	}
+	if body := get(h, "/tasks").Body.String(); !strings.Contains(body, "<td>4</td><td>ship it</td><td>active</td>") {
+		t.Fatalf("inserted row not shown:\n%s", body)
+	}
+	if rec := postForm(t, h, url.Values{"id": {"-3"}, "title": {"negative"}}); rec.Code != http.StatusSeeOther {
+		t.Fatalf("negative number: status %d: %s", rec.Code, rec.Body)
+	}
+	if body := get(h, "/tasks").Body.String(); !strings.Contains(body, "<td>-3</td><td>negative</td>") {
+		t.Fatalf("negative id not stored:\n%s", body)
+	}
+}
+
+func TestFormSubmitRequiresToken(t *testing.T) {
+	h := paramsHandler(t, formPage)
+	id, token, cookie := renderForm(t, h)
+	_, other, otherCookie := renderForm(t, h)
+	if token == other || cookie.Value == otherCookie.Value {
+		t.Fatal("token and session are not fresh per render and browser")
+	}
+	fields := func(token string) url.Values {
+		return url.Values{"_form": {id}, "_page": {"tasks"}, "_token": {token}, "id": {"8"}, "title": {"forged"}}
+	}
+	for name, rec := range map[string]*httptest.ResponseRecorder{
+		"no token":          submit(h, fields(""), cookie),
+		"no cookie":         submit(h, fields(token), nil),
+		"other browser":     submit(h, fields(token), otherCookie),
+		"tampered token":    submit(h, fields(token+"0"), cookie),
+		"token of nonsense": submit(h, fields("x.y"), cookie),
+	} {
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s: status %d, want 403", name, rec.Code)
+		}
+	}
+	if body := get(h, "/tasks").Body.String(); strings.Contains(body, "forged") {
+		t.Fatal("forged submit inserted a row")
+	}
+	if rec := submit(h, fields(token), cookie); rec.Code != http.StatusSeeOther {
+		t.Fatalf("valid token: status %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestFormSubmitEscapesValues(t *testing.T) {
+	h := paramsHandler(t, formPage)
+	attack := "x'); DROP TABLE tasks; --<script>alert(1)</script>"
+	if rec := postForm(t, h, url.Values{"id": {"5"}, "title": {attack}}); rec.Code != http.StatusSeeOther {
+		t.Fatalf("submit: status %d: %s", rec.Code, rec.Body)
+	}
+	body := get(h, "/tasks").Body.String()
+	if strings.Contains(body, "<script>alert(1)") || !strings.Contains(body, "&lt;script&gt;alert(1)&lt;/script&gt;") {
+		t.Fatalf("stored value not escaped on output:\n%s", body)
+	}
+	if !strings.Contains(body, "write docs") {
+		t.Fatal("existing rows lost after injection attempt")
+	}
+	// Extra fields are not bound, and the number field only takes numbers.
+	rec := postForm(t, h, url.Values{"id": {"6 OR 1=1"}, "title": {"t"}, "status": {"hacked"}})
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "ID must be a number") ||
+		!strings.Contains(rec.Body.String(), "Status must be one of active, done") {
+		t.Fatalf("invalid values: status %d, body %q", rec.Code, rec.Body)
+	}
+}
+
+func TestFormSubmitActionWithComment(t *testing.T) {
+	page := strings.Replace(formPage, "'INSERT INTO tasks (id, title, status)",
+		`'/* the user''s task */ INSERT INTO "tasks" ("id", title, status)`, 1)
+	h := paramsHandler(t, page)
+	title := "x', 'done') --"
+	if rec := postForm(t, h, url.Values{"id": {"7"}, "title": {title}, "status": {"active"}}); rec.Code != http.StatusSeeOther {
+		t.Fatalf("submit: status %d: %s", rec.Code, rec.Body)
+	}
+	if body := get(h, "/tasks").Body.String(); !strings.Contains(body, "<td>7</td><td>x&#39;, &#39;done&#39;) --</td><td>active</td>") {
+		t.Fatalf("value not stored verbatim:\n%s", body)
+	}
+}
+
+func TestFormSubmitValidation(t *testing.T) {
+	h := paramsHandler(t, formPage)
+	rec := postForm(t, h, url.Values{"id": {""}, "title": {"  "}})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status %d, want 400", rec.Code)
+	}
+	for _, want := range []string{"ID is required", "Title is required"} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("body %q lacks %q", rec.Body, want)
+		}
+	}
+
+	_, _, cookie := renderForm(t, h)
+	token := "n." + h.formMAC(cookie.Value, "bogus", "n")
+	rec = submit(h, url.Values{"_form": {"bogus"}, "_token": {token}, "title": {"x"}}, cookie)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown form: status %d", rec.Code)
+	}
+	if rec := get(h, "/submit"); rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET /submit: status %d", rec.Code)
+	}
+}
+
+// A form ID from before a restart is found again by rendering its page,
+// as long as the restarted server signs tokens with the same key.
+func TestFormSubmitAfterRestart(t *testing.T) {
+	h := paramsHandler(t, formPage)
+	h.formKey = []byte("form secret")
+	id, token, cookie := renderForm(t, h)
+	fields := url.Values{"_form": {id}, "_page": {"tasks"}, "_token": {token}, "id": {"7"}, "title": {"after restart"}}
+
+	rekeyed := &pageHandler{db: h.db, tenant: h.tenant, pagesDir: h.pagesDir}
+	if rec := submit(rekeyed, fields, cookie); rec.Code != http.StatusForbidden {
+		t.Fatalf("token from another key: status %d, want 403", rec.Code)
+	}
+	fresh := &pageHandler{db: h.db, tenant: h.tenant, pagesDir: h.pagesDir, formKey: h.formKey}
+	rec := submit(fresh, fields, cookie)
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/tasks?status=active" {
+		t.Fatalf("status %d, location %q: %s", rec.Code, rec.Header().Get("Location"), rec.Body)
+	}
+}
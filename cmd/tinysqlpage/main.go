@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"html"
@@ -13,6 +14,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/text/cases"
@@ -24,6 +26,12 @@ import (
 const (
 	defaultTenant = "tinysqlpage"
 	defaultTitle  = "tinySQLPage"
+
+	// navBadgeTimeout bounds a single `nav_badge_sql` query so one slow
+	// badge cannot stall every page's navigation; navBadgeTTL is how long a
+	// badge value is reused before the query runs again.
+	navBadgeTimeout = 250 * time.Millisecond
+	navBadgeTTL     = 60 * time.Second
 )
 
 func main() {
@@ -36,8 +44,14 @@ func main() {
 	cssFile := flag.String("css", "", "Path to custom CSS file")
 	tplFile := flag.String("template", "", "Path to custom HTML template file (use {{TITLE}}, {{STYLES}}, {{BODY}})")
 	requestTimeout := flag.Duration("request-timeout", 5*time.Second, "Maximum SQL rendering time per HTTP request (0 disables the timeout)")
+	cacheDefault := flag.String("cache-default", "", "Cache policy for pages without cache front-matter: max-age in seconds or no-store (empty sends no Cache-Control)")
+	formSecret := flag.String("form-secret", "", "Key for signing form tokens, so rendered forms stay valid across restarts (default: random per start)")
 	flag.Parse()
 
+	if _, ok := cacheControl(*cacheDefault); !ok {
+		log.Fatalf("invalid -cache-default %q: want seconds or no-store", *cacheDefault)
+	}
+
 	db := tsql.NewDB()
 	ctx := context.Background()
 
@@ -52,6 +66,10 @@ func main() {
 		css:      "",
 		tpl:      "",
 		timeout:  *requestTimeout,
+		cache:    *cacheDefault,
+	}
+	if *formSecret != "" {
+		handler.formKey = []byte(*formSecret)
 	}
 
 	if *cssFile != "" {
@@ -96,11 +114,31 @@ type pageHandler struct {
 	tenant   string
 	pagesDir string
 	timeout  time.Duration
+	cache    string // default `-- cache:` value
 	css      string
 	tpl      string
+
+	badgeMu sync.Mutex
+	badges  map[string]navBadge // keyed by page name
+
+	formMu  sync.Mutex
+	forms   map[string]formComponent // keyed by form ID
+	formKey []byte                   // signs form tokens; random when unset
+}
+
+// navBadge is a cached `nav_badge_sql` result. An empty value means the
+// query failed or returned nothing, and no badge is shown.
+type navBadge struct {
+	sql     string
+	value   string
+	fetched time.Time
 }
 
 func (h *pageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/submit" {
+		h.serveSubmit(w, r)
+		return
+	}
 	if r.Method != http.MethodGet {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
@@ -132,13 +170,20 @@ func (h *pageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	params, err := paramBindings(parsePageParams(string(data)), r.URL.Query())
+	var missing missingParamError
+	if errors.As(err, &missing) {
+		http.Error(w, missing.Error(), http.StatusBadRequest)
+		return
+	}
+
 	ctx := r.Context()
 	if h.timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, h.timeout)
 		defer cancel()
 	}
-	comps, err := h.renderComponents(ctx, string(data))
+	comps, err := h.renderComponents(ctx, string(data), params)
 	if err != nil {
 		log.Printf("render %s: %v", sqlPath, err)
 		http.Error(w, "failed to render page", http.StatusInternalServerError)
@@ -148,16 +193,42 @@ func (h *pageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "page produced no components", http.StatusInternalServerError)
 		return
 	}
+	h.registerForms(clean, comps)
+	signed := h.signForms(w, r, comps)
 
 	title := deriveTitle(comps)
-	htmlBody := h.renderShell(title, comps, clean)
+	body := []byte(h.renderShell(title, comps, clean))
 
+	policy, ok := frontMatter(string(data))["cache"]
+	if !ok {
+		policy = h.cache
+	}
+	cc, ok := cacheControl(policy)
+	if !ok {
+		log.Printf("%s: invalid cache value %q, using the default", sqlPath, policy)
+		cc, _ = cacheControl(h.cache)
+	}
+	if signed {
+		// The form tokens are bound to this browser's cookie, so no
+		// shared cache may keep the page and no other browser may get it.
+		cc = "private, no-store"
+	}
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	_, _ = w.Write([]byte(htmlBody))
+	if cc != "" {
+		w.Header().Set("Cache-Control", cc)
+	}
+	if !strings.Contains(cc, "no-store") {
+		// ServeContent answers 304 Not Modified when If-None-Match
+		// carries this tag.
+		w.Header().Set("ETag", pageETag(body))
+	}
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(body))
 }
 
-func (h *pageHandler) renderComponents(ctx context.Context, script string) ([]component, error) {
-	statements := splitSQLStatements(script)
+// renderComponents runs a page script with params bound (see bindParams)
+// and converts the result sets into components.
+func (h *pageHandler) renderComponents(ctx context.Context, script string, params map[string]string) ([]component, error) {
+	statements := splitSQLStatements(bindParams(script, params))
 	var comps []component
 	for _, stmtSQL := range statements {
 		parsed, err := tsql.ParseSQL(stmtSQL)
@@ -378,6 +449,10 @@ func componentsFromResult(rs *tsql.ResultSet) ([]component, error) {
 	case "table":
 		title := stringValue(rs.Rows[0], "title")
 		return []component{buildTableComponent(rs, title)}, nil
+	case "form":
+		return []component{buildFormComponent(rs)}, nil
+	case "bar_chart", "line_chart":
+		return []component{buildChartComponent(rs, compType)}, nil
 	default:
 		fallbackTitle := fmt.Sprintf("%s result", strings.ToUpper(compType))
 		return []component{genericTableFromResult(rs, fallbackTitle)}, nil
@@ -548,6 +623,7 @@ func (h *pageHandler) buildNavHTML(currentPage string) string {
 	var entries []struct {
 		name   string
 		label  string
+		badge  string
 		order  int
 		hidden bool
 	}
@@ -573,12 +649,17 @@ func (h *pageHandler) buildNavHTML(currentPage string) string {
 		if meta["nav_hidden"] == "true" || meta["nav_hidden"] == "1" {
 			hidden = true
 		}
+		badge := ""
+		if !hidden && meta["nav_badge_sql"] != "" {
+			badge = h.navBadgeValue(name, meta["nav_badge_sql"])
+		}
 		entries = append(entries, struct {
 			name   string
 			label  string
+			badge  string
 			order  int
 			hidden bool
-		}{name: name, label: label, order: order, hidden: hidden})
+		}{name: name, label: label, badge: badge, order: order, hidden: hidden})
 	}
 	sort.Slice(entries, func(i, j int) bool {
 		// Always prefer the index page first
@@ -606,22 +687,66 @@ func (h *pageHandler) buildNavHTML(currentPage string) string {
 		if e.name == currentPage {
 			cls = ` class="active"`
 		}
-		sb.WriteString("<a href=\"" + href + "\"" + cls + ">" + html.EscapeString(e.label) + "</a>")
+		badge := ""
+		if e.badge != "" {
+			badge = ` <span class="badge">` + html.EscapeString(e.badge) + `</span>`
+		}
+		sb.WriteString("<a href=\"" + href + "\"" + cls + ">" + html.EscapeString(e.label) + badge + "</a>")
 	}
 	return sb.String()
 }
 
+// navBadgeValue returns the badge text for a page's `nav_badge_sql`
+// front-matter: the first column of the first row, formatted like table
+// cells. Results are cached per page for navBadgeTTL. Failures are silent
+// and simply yield no badge.
+func (h *pageHandler) navBadgeValue(page, query string) string {
+	h.badgeMu.Lock()
+	defer h.badgeMu.Unlock()
+	if b, ok := h.badges[page]; ok && b.sql == query && time.Since(b.fetched) < navBadgeTTL {
+		return b.value
+	}
+	value := h.queryNavBadge(query)
+	if h.badges == nil {
+		h.badges = make(map[string]navBadge)
+	}
+	h.badges[page] = navBadge{sql: query, value: value, fetched: time.Now()}
+	return value
+}
+
+func (h *pageHandler) queryNavBadge(query string) string {
+	if h.db == nil {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), navBadgeTimeout)
+	defer cancel()
+	parsed, err := tsql.ParseSQL(query)
+	if err != nil {
+		return ""
+	}
+	rs, err := tsql.Execute(ctx, h.db, h.tenant, parsed)
+	if err != nil || rs == nil || len(rs.Rows) == 0 || len(rs.Cols) == 0 {
+		return ""
+	}
+	return stringValue(rs.Rows[0], rs.Cols[0])
+}
+
 // parseFrontMatter reads the top comment lines of a `.sql` file and
 // extracts `key: value` pairs from lines beginning with `--`. This is
 // a lightweight front-matter parser used to customize nav labels,
 // ordering and visibility without changing the SQL execution logic.
 func parseFrontMatter(path string) map[string]string {
-	out := map[string]string{}
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return out
+		return map[string]string{}
 	}
-	lines := strings.Split(string(data), "\n")
+	return frontMatter(string(data))
+}
+
+// frontMatter is parseFrontMatter for a script already in memory.
+func frontMatter(script string) map[string]string {
+	out := map[string]string{}
+	lines := strings.Split(script, "\n")
 	for _, ln := range lines {
 		ln = strings.TrimSpace(ln)
 		if !strings.HasPrefix(ln, "--") {
@@ -680,6 +805,18 @@ body {
 .topbar a:hover {
   color: var(--text);
 }
+.badge {
+  display: inline-block;
+  min-width: 1.25em;
+  margin-left: 0.35rem;
+  padding: 0 0.45em;
+  border-radius: 999px;
+  background: var(--accent);
+  color: #020617;
+  font-size: 0.75em;
+  font-weight: 700;
+  text-align: center;
+}
 .logo {
   font-weight: 700;
   letter-spacing: 0.08em;
@@ -768,6 +905,45 @@ th {
 tr:last-child td {
   border-bottom: none;
 }
+.form form {
+  display: grid;
+  gap: 0.9rem;
+  max-width: 480px;
+}
+.form label {
+  display: grid;
+  gap: 0.3rem;
+  color: var(--muted);
+  font-size: 0.85rem;
+}
+.form input, .form select {
+  padding: 0.55rem 0.7rem;
+  border-radius: 10px;
+  border: 1px solid var(--border);
+  background: rgba(2, 6, 23, 0.6);
+  color: var(--text);
+  font: inherit;
+}
+.form button {
+  justify-self: start;
+  padding: 0.6rem 1.2rem;
+  border: none;
+  border-radius: 10px;
+  background: var(--accent);
+  color: #020617;
+  font-weight: 600;
+  cursor: pointer;
+}
+.chart-wrap svg {
+  display: block;
+  max-height: 360px;
+}
+.chart-wrap text.axis-label, .chart-wrap text.legend, .chart-wrap text.placeholder {
+  fill: var(--text);
+}
+.chart-wrap text.tick {
+  fill: var(--muted);
+}
 `
 
 const defaultTemplate = `<!DOCTYPE html>
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	tsql "github.com/SimonWaldherr/tinySQL"
 )
 
 func TestParseFrontMatter(t *testing.T) {
@@ -53,3 +56,41 @@ func TestBuildNavHTML(t *testing.T) {
 		t.Fatalf("hidden page leaked into nav: %s", nav)
 	}
 }
+
+func TestBuildNavHTMLBadge(t *testing.T) {
+	d := t.TempDir()
+	files := map[string]string{
+		"index.sql":  "-- title: Home\nSELECT 1;\n",
+		"orders.sql": "-- nav_label: Orders\n-- nav_badge_sql: SELECT COUNT(*) FROM pending_orders\nSELECT 1;\n",
+		"broken.sql": "-- nav_label: Broken\n-- nav_badge_sql: SELECT COUNT(*) FROM missing_table\nSELECT 1;\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(d, name), []byte(content), 0644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	db := tsql.NewDB()
+	ctx := context.Background()
+	seed := "CREATE TABLE pending_orders (id INT); INSERT INTO pending_orders VALUES (1); INSERT INTO pending_orders VALUES (2); INSERT INTO pending_orders VALUES (3);"
+	if err := execSQLScript(ctx, db, defaultTenant, seed); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	h := &pageHandler{db: db, tenant: defaultTenant, pagesDir: d}
+	nav := h.buildNavHTML("index")
+	if !strings.Contains(nav, `Orders <span class="badge">3</span></a>`) {
+		t.Fatalf("nav missing orders badge: %s", nav)
+	}
+	if !strings.Contains(nav, ">Broken</a>") {
+		t.Fatalf("failing badge SQL should render the link without a badge: %s", nav)
+	}
+
+	// The badge is cached, so a new row is not reflected until the TTL expires.
+	if err := execSQLScript(ctx, db, defaultTenant, "INSERT INTO pending_orders VALUES (4);"); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	if nav := h.buildNavHTML("index"); !strings.Contains(nav, `<span class="badge">3</span>`) {
+		t.Fatalf("expected cached badge value 3: %s", nav)
+	}
+}
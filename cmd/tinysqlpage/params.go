@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// pageParam is a URL query parameter declared in a page's front-matter:
+//
+//	-- param: status required
+//	-- param: limit default=10
+//
+// Undeclared query parameters are bound too; declaring one adds a default
+// or makes the page answer 400 Bad Request when it is missing.
+type pageParam struct {
+	Name       string
+	Required   bool
+	Default    string
+	HasDefault bool
+}
+
+// missingParamError reports a required parameter absent from the URL.
+type missingParamError struct{ name string }
+
+func (e missingParamError) Error() string {
+	return fmt.Sprintf("missing required parameter %q", e.name)
+}
+
+// parsePageParams returns the `-- param:` declarations in the leading
+// comment block of script.
+func parsePageParams(script string) []pageParam {
+	var params []pageParam
+	for _, ln := range strings.Split(script, "\n") {
+		ln = strings.TrimSpace(ln)
+		if !strings.HasPrefix(ln, "--") {
+			break
+		}
+		kv := strings.TrimSpace(strings.TrimPrefix(ln, "--"))
+		key, rest, ok := strings.Cut(kv, ":")
+		if !ok || strings.TrimSpace(strings.ToLower(key)) != "param" {
+			continue
+		}
+		rest = strings.TrimSpace(rest)
+		name, opts, _ := strings.Cut(rest, " ")
+		if name == "" {
+			continue
+		}
+		p := pageParam{Name: name}
+		opts = strings.TrimSpace(opts)
+		if i := strings.Index(opts, "default="); i >= 0 {
+			// The default runs to the end of the line so it may hold spaces.
+			p.Default, p.HasDefault = strings.TrimSpace(opts[i+len("default="):]), true
+			opts = opts[:i]
+		}
+		for _, f := range strings.Fields(opts) {
+			if strings.EqualFold(f, "required") {
+				p.Required = true
+			}
+		}
+		params = append(params, p)
+	}
+	return params
+}
+
+// paramBindings returns the values bound into a page: every query
+// parameter (its first value), plus the defaults of declared parameters
+// the URL leaves out. A missing required parameter is a missingParamError.
+func paramBindings(decl []pageParam, query url.Values) (map[string]string, error) {
+	bindings := make(map[string]string, len(query))
+	for name, values := range query {
+		if len(values) > 0 {
+			bindings[name] = values[0]
+		}
+	}
+	for _, p := range decl {
+		if _, ok := bindings[p.Name]; ok {
+			continue
+		}
+		switch {
+		case p.HasDefault:
+			bindings[p.Name] = p.Default
+		case p.Required:
+			return nil, missingParamError{p.Name}
+		}
+	}
+	return bindings, nil
+}
+
+var numericParam = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// sqlLiteral renders a parameter value as a SQL literal: plain decimal
+// numbers stay numbers so they compare with numeric columns, anything else
+// becomes a quoted string with embedded quotes doubled. Negative numbers are
+// parenthesized so a preceding minus cannot turn them into a `--` comment.
+func sqlLiteral(v string) string {
+	if numericParam.MatchString(v) {
+		if strings.HasPrefix(v, "-") {
+			return "(" + v + ")"
+		}
+		return v
+	}
+	return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+}
+
+// bindParams substitutes params into script before it is split and parsed.
+// `{{.Params.name}}` becomes a literal (NULL when unbound); inside a quoted
+// string it becomes the escaped text, so `LIKE '%{{.Params.q}}%'` works.
+// `:name` placeholders outside strings and comments are replaced when name
+// is bound. Values never end up in the SQL unescaped.
+func bindParams(script string, params map[string]string) string {
+	return bindSQL(script, func(name string) (boundValue, bool) {
+		v, ok := params[name]
+		return boundValue{text: v, null: !ok}, ok
+	})
+}
+
+// boundValue is a value bound into page SQL.
+type boundValue struct {
+	text string
+	null bool
+	// quote makes numeric-looking text a string literal too.
+	quote bool
+}
+
+func (v boundValue) literal() string {
+	switch {
+	case v.null:
+		return "NULL"
+	case v.quote:
+		return "'" + strings.ReplaceAll(v.text, "'", "''") + "'"
+	}
+	return sqlLiteral(v.text)
+}
+
+// bindSQL is bindParams over a lookup that reports whether name is bound
+// and to what; an unbound `{{.Params.name}}` is NULL. Comments and
+// "quoted" identifiers are copied as the lexer skips them, so neither a
+// quote inside them nor a placeholder can move a value out of a literal.
+func bindSQL(script string, lookup func(name string) (boundValue, bool)) string {
+	var b strings.Builder
+	inString, inIdent := false, false
+	for i := 0; i < len(script); i++ {
+		ch := script[i]
+		if !inIdent && strings.HasPrefix(script[i:], "{{") {
+			if end := strings.Index(script[i:], "}}"); end > 0 {
+				expr := strings.TrimSpace(script[i+2 : i+end])
+				if name, ok := strings.CutPrefix(expr, ".Params."); ok {
+					v, ok := lookup(name)
+					switch {
+					case inString && ok && !v.null:
+						b.WriteString(strings.ReplaceAll(v.text, "'", "''"))
+					case !inString:
+						v.null = v.null || !ok
+						b.WriteString(v.literal())
+					}
+					i += end + 1
+					continue
+				}
+			}
+		}
+		switch {
+		case inIdent:
+			inIdent = ch != '"'
+		case ch == '\'':
+			inString = !inString
+		case !inString && ch == '"':
+			inIdent = true
+		case !inString && ch == '/' && i+1 < len(script) && script[i+1] == '*':
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				end = len(script) - i
+			} else {
+				end += 4
+			}
+			b.WriteString(script[i : i+end])
+			i += end - 1
+			continue
+		case !inString && ch == '-' && i+1 < len(script) && script[i+1] == '-':
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				end = len(script) - i
+			}
+			b.WriteString(script[i : i+end])
+			i += end - 1
+			continue
+		case !inString && ch == ':' && (i == 0 || script[i-1] != ':'):
+			j := i + 1
+			for j < len(script) && isParamNameChar(script[j], j == i+1) {
+				j++
+			}
+			if v, ok := lookup(script[i+1 : j]); ok && j > i+1 {
+				b.WriteString(v.literal())
+				i = j - 1
+				continue
+			}
+		}
+		b.WriteByte(ch)
+	}
+	return b.String()
+}
+
+func isParamNameChar(c byte, first bool) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (!first && c >= '0' && c <= '9')
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tsql "github.com/SimonWaldherr/tinySQL"
+)
+
+// paramsHandler serves a single page, tasks.sql, over a small tasks table.
+func paramsHandler(t *testing.T, page string) *pageHandler {
+	t.Helper()
+	d := t.TempDir()
+	if err := os.WriteFile(filepath.Join(d, "tasks.sql"), []byte(page), 0644); err != nil {
+		t.Fatal(err)
+	}
+	db := tsql.NewDB()
+	seed := `CREATE TABLE tasks (id INT, title TEXT, status TEXT);
+		INSERT INTO tasks VALUES (1, 'write docs', 'active');
+		INSERT INTO tasks VALUES (2, 'fix bug', 'done');
+		INSERT INTO tasks VALUES (3, 'it''s late', 'active');`
+	if err := execSQLScript(context.Background(), db, defaultTenant, seed); err != nil {
+		t.Fatal(err)
+	}
+	return &pageHandler{db: db, tenant: defaultTenant, pagesDir: d}
+}
+
+func get(h http.Handler, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func TestPageParamsFilterRows(t *testing.T) {
+	h := paramsHandler(t, "-- param: status required\n"+
+		"SELECT 'table' AS component, 'Tasks' AS title, title FROM tasks WHERE status = {{.Params.status}} ORDER BY id;")
+	rec := get(h, "/tasks?status=active")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "write docs") || !strings.Contains(body, "it&#39;s late") || strings.Contains(body, "fix bug") {
+		t.Fatalf("want only active tasks:\n%s", body)
+	}
+
+	if rec := get(h, "/tasks"); rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), `"status"`) {
+		t.Fatalf("missing required param: status %d, body %q", rec.Code, rec.Body)
+	}
+}
+
+func TestPageParamsDefaultAndPlaceholder(t *testing.T) {
+	h := paramsHandler(t, "-- param: status default=done\n"+
+		"SELECT 'table' AS component, 'Tasks' AS title, title FROM tasks WHERE status = :status AND title LIKE '%{{.Params.q}}%';")
+	if body := get(h, "/tasks").Body.String(); !strings.Contains(body, "fix bug") || strings.Contains(body, "write docs") {
+		t.Fatalf("default status not applied:\n%s", body)
+	}
+	if body := get(h, "/tasks?status=active&q=late").Body.String(); !strings.Contains(body, "it&#39;s late") || strings.Contains(body, "write docs") {
+		t.Fatalf("URL values not bound:\n%s", body)
+	}
+}
+
+func TestPageParamsEscapeInjection(t *testing.T) {
+	h := paramsHandler(t, "SELECT 'table' AS component, 'Task' AS title, title FROM tasks WHERE id = :id;"+
+		"SELECT 'text' AS component, 'never empty' AS content;")
+	if body := get(h, "/tasks?id=2").Body.String(); !strings.Contains(body, "fix bug") || strings.Contains(body, "write docs") {
+		t.Fatalf("numeric id not bound:\n%s", body)
+	}
+	for _, attack := range []string{"1 OR 1=1", "1' OR '1'='1", "1; DROP TABLE tasks"} {
+		rec := get(h, "/tasks?id="+url.QueryEscape(attack))
+		if body := rec.Body.String(); strings.Contains(body, "write docs") || strings.Contains(body, "fix bug") {
+			t.Fatalf("%q leaked rows (status %d):\n%s", attack, rec.Code, body)
+		}
+	}
+	if _, err := h.db.Get(defaultTenant, "tasks"); err != nil {
+		t.Fatalf("tasks table gone after injection attempt: %v", err)
+	}
+}
+
+func TestPageParamsNegativeNumber(t *testing.T) {
+	// 0-:n with n=-2 must be 0-(-2), not the comment `0--2 ...`.
+	h := paramsHandler(t, "SELECT 'table' AS component, 'Task' AS title, title FROM tasks WHERE id = 0-:n AND status = 'done';")
+	rec := get(h, "/tasks?n=-2")
+	if body := rec.Body.String(); !strings.Contains(body, "fix bug") || strings.Contains(body, "write docs") {
+		t.Fatalf("negative parameter not bound as a number (status %d):\n%s", rec.Code, body)
+	}
+}
+
+func TestPageParamsAfterCommentQuote(t *testing.T) {
+	h := paramsHandler(t, "/* the user's tasks */ SELECT 'table' AS component, 'Tasks' AS title, title FROM \"tasks\"\n"+
+		"WHERE \"id\" = {{.Params.id}} ORDER BY id;")
+	rec := get(h, "/tasks?id=0%20OR%201=1")
+	if body := rec.Body.String(); strings.Contains(body, "write docs") || strings.Contains(body, "fix bug") {
+		t.Fatalf("parameter escaped its literal after a quote in a comment (status %d):\n%s", rec.Code, body)
+	}
+	if body := get(h, "/tasks?id=2").Body.String(); !strings.Contains(body, "fix bug") || strings.Contains(body, "write docs") {
+		t.Fatalf("id=2 not bound as a number:\n%s", body)
+	}
+}
+
+func TestBindParams(t *testing.T) {
+	params := map[string]string{"name": "O'Brien", "n": "5", "x": "1 OR 1=1", "neg": "-5"}
+	cases := []struct{ in, want string }{
+		{"SELECT {{.Params.name}}", "SELECT 'O''Brien'"},
+		{"SELECT {{ .Params.n }}, :n", "SELECT 5, 5"},
+		{"SELECT :x", "SELECT '1 OR 1=1'"},
+		{"SELECT 10-:neg, {{.Params.neg}}", "SELECT 10-(-5), (-5)"},
+		{"SELECT '%{{.Params.name}}%'", "SELECT '%O''Brien%'"},
+		{"SELECT ':n', x::TEXT -- :n\n, {{.Params.missing}}, :missing", "SELECT ':n', x::TEXT -- :n\n, NULL, :missing"},
+		{"/* the user's orders */ SELECT * FROM t WHERE id = {{.Params.x}}", "/* the user's orders */ SELECT * FROM t WHERE id = '1 OR 1=1'"},
+		{`SELECT "it's" FROM t WHERE id = :x`, `SELECT "it's" FROM t WHERE id = '1 OR 1=1'`},
+		{`SELECT "a "" :n {{.Params.n}}" /* :n */, :n /* open`, `SELECT "a "" :n {{.Params.n}}" /* :n */, 5 /* open`},
+	}
+	for _, tc := range cases {
+		if got := bindParams(tc.in, params); got != tc.want {
+			t.Errorf("bindParams(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
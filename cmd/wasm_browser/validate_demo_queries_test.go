@@ -171,8 +171,8 @@ func TestProblematicQueries(t *testing.T) {
 		{
 			name:        "EXISTS clause",
 			sql:         "SELECT name FROM users u WHERE EXISTS (SELECT 1 FROM events e WHERE e.user_id = u.id)",
-			expectError: true, // EXISTS not supported yet
-			reason:      "EXISTS clause not implemented",
+			expectError: false,
+			reason:      "Correlated EXISTS should work",
 		},
 		{
 			name:        "IN with subquery",
@@ -42,6 +42,33 @@ func TestBindPlaceholders_QuotedLiteral(t *testing.T) {
 	}
 }
 
+func TestBindPlaceholders_Named(t *testing.T) {
+	args := []driver.NamedValue{{Name: "id", Ordinal: 1, Value: 7}, {Name: "Name", Ordinal: 2, Value: "x"}}
+	out, err := bindPlaceholders("SELECT * FROM t WHERE id = :id AND name = @name OR id = :ID", args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "SELECT * FROM t WHERE id = 7 AND name = 'x' OR id = 7"
+	if out != want {
+		t.Fatalf("got %q want %q", out, want)
+	}
+	if _, err := bindPlaceholders("SELECT :missing", args[:1]); err == nil {
+		t.Fatal("expected error for an unbound named placeholder")
+	}
+	if _, err := bindPlaceholders("SELECT ':id', :id", args[:1]); err != nil {
+		t.Fatalf("named placeholder in string literal: %v", err)
+	}
+}
+
+func TestBindPlaceholders_MixedStylesRejected(t *testing.T) {
+	args := []driver.NamedValue{{Ordinal: 1, Value: 1}, {Name: "id", Ordinal: 2, Value: 2}}
+	for _, q := range []string{"SELECT $1, :id", "SELECT :id, ?"} {
+		if _, err := bindPlaceholders(q, args); err == nil {
+			t.Fatalf("%s: expected an error for mixed placeholder styles", q)
+		}
+	}
+}
+
 func TestSqlLiteral_Complex(t *testing.T) {
 	if got := sqlLiteral(nil); got != "NULL" {
 		t.Fatalf("nil literal: %s", got)
@@ -134,6 +134,9 @@ type cfg struct {
 	// behaves the same but goes through storage.OpenDB).
 	mode    storage.StorageMode
 	modeSet bool
+	// walSet records wal=1, a shorthand for mode=wal, so a later
+	// conflicting mode= is rejected rather than silently winning.
+	walSet bool
 
 	maxMemoryBytes     int64
 	readOnly           bool
@@ -526,6 +529,13 @@ type conn struct {
 	shadow     *storage.DB // Snapshot copy (MVCC-light)
 	txReadOnly bool        // Active tx requested as read-only
 	txDirty    bool        // A successful write ran against shadow.
+	savepoints []txSavepoint
+}
+
+// txSavepoint is a named copy of the transaction shadow taken by SAVEPOINT.
+type txSavepoint struct {
+	name     string
+	snapshot *storage.DB
 }
 
 func (c *conn) Prepare(query string) (driver.Stmt, error) {
@@ -588,16 +598,27 @@ func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, e
 	return &tx{c: c}, nil
 }
 
-// Ping implements driver.Pinger so database/sql can health-check the connection.
+// Ping implements driver.Pinger so database/sql can health-check the
+// connection. It fails with ctx.Err() once ctx is done and otherwise waits
+// for a reader slot and the server's read lock, so a ping succeeds only when
+// a read could run now.
 func (c *conn) Ping(ctx context.Context) error {
 	if c.srv == nil {
 		return fmt.Errorf("tinysql: no server")
 	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	if err := c.srv.acquireReader(ctx); err != nil {
 		return err
 	}
-	c.srv.releaseReader()
-	return nil
+	defer c.srv.releaseReader()
+	c.srv.mu.RLock()
+	defer c.srv.mu.RUnlock()
+	if c.srv.db == nil {
+		return driver.ErrBadConn
+	}
+	return ctx.Err()
 }
 
 type tx struct{ c *conn }
@@ -702,6 +723,51 @@ func (c *conn) clearTxState() {
 	c.shadow = nil
 	c.txReadOnly = false
 	c.txDirty = false
+	c.savepoints = nil
+}
+
+// execSavepoint applies SAVEPOINT, ROLLBACK TO and RELEASE to the active
+// transaction. Savepoints nest: ROLLBACK TO keeps the named savepoint (so
+// the work can be retried) but discards newer ones, and RELEASE discards the
+// named savepoint together with every newer one.
+func (c *conn) execSavepoint(st engine.Statement) (bool, error) {
+	var name string
+	switch s := st.(type) {
+	case *engine.SavepointStmt:
+		name = s.Name
+	case *engine.RollbackToStmt:
+		name = s.Name
+	case *engine.ReleaseSavepointStmt:
+		name = s.Name
+	default:
+		return false, nil
+	}
+	if !c.inTx {
+		return true, fmt.Errorf("tinysql: savepoint %q requires an active transaction", name)
+	}
+	if c.shadow == nil {
+		// A read-only transaction on an immutable database has nothing to
+		// snapshot; savepoints are accepted but have nothing to restore.
+		return true, nil
+	}
+	if _, ok := st.(*engine.SavepointStmt); ok {
+		c.savepoints = append(c.savepoints, txSavepoint{name: name, snapshot: c.shadow.DeepClone()})
+		return true, nil
+	}
+	i := len(c.savepoints) - 1
+	for i >= 0 && !strings.EqualFold(c.savepoints[i].name, name) {
+		i--
+	}
+	if i < 0 {
+		return true, fmt.Errorf("tinysql: no such savepoint %q", name)
+	}
+	if _, ok := st.(*engine.RollbackToStmt); ok {
+		c.shadow = c.savepoints[i].snapshot.DeepClone()
+		c.savepoints = c.savepoints[:i+1]
+		return true, nil
+	}
+	c.savepoints = c.savepoints[:i]
+	return true, nil
 }
 
 // ------------------- exec / query -------------------
@@ -882,6 +948,12 @@ func (c *conn) execSQL(ctx context.Context, sqlStr string) (driver.Result, error
 	if err != nil {
 		return nil, err
 	}
+	if handled, err := c.execSavepoint(st); handled {
+		if err != nil {
+			return nil, err
+		}
+		return driver.RowsAffected(0), nil
+	}
 	return c.execStatement(ctx, st)
 }
 
@@ -931,6 +1003,8 @@ func writeTargetTable(st engine.Statement) string {
 	switch s := st.(type) {
 	case *engine.Insert:
 		return s.Table
+	case *engine.CopyFrom:
+		return s.Table
 	case *engine.Update:
 		return s.Table
 	case *engine.Delete:
@@ -939,6 +1013,10 @@ func writeTargetTable(st engine.Statement) string {
 		return s.Name
 	case *engine.DropTable:
 		return s.Name
+	case *engine.TruncateTable:
+		return s.Name
+	case *engine.AlterTable:
+		return s.Table
 	default:
 		return ""
 	}
@@ -964,13 +1042,56 @@ func affectedRows(rs *engine.ResultSet, countCell string) int64 {
 	return int64(len(rs.Rows))
 }
 
+// execResult is the driver.Result of a write. lastInsertID is the 1-based
+// position of the last row an INSERT appended to its table, 0 otherwise.
+type execResult struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+func (r execResult) LastInsertId() (int64, error) { return r.lastInsertID, nil }
+func (r execResult) RowsAffected() (int64, error) { return r.rowsAffected, nil }
+
+// tableRowCount returns the number of rows in an INSERT's target table, or
+// -1 for any other statement or a missing table.
+func tableRowCount(db *storage.DB, tenant string, st engine.Statement) int {
+	ins, ok := st.(*engine.Insert)
+	if !ok {
+		return -1
+	}
+	t, err := db.Get(tenant, ins.Table)
+	if err != nil {
+		return -1
+	}
+	return len(t.Rows)
+}
+
+// insertResult counts the rows an INSERT affected from its target table's
+// size before and after. Every VALUES row of an upsert with DO UPDATE
+// either appends or updates exactly one row; otherwise only appended rows
+// count.
+func insertResult(db *storage.DB, tenant string, ins *engine.Insert, before int) driver.Result {
+	after := tableRowCount(db, tenant, ins)
+	if before < 0 || after < 0 {
+		return execResult{}
+	}
+	res := execResult{rowsAffected: int64(after - before)}
+	if after > before {
+		res.lastInsertID = int64(after)
+	}
+	if ins.OnConflict != nil && !ins.OnConflict.DoNothing {
+		res.rowsAffected = int64(len(ins.Rows))
+	}
+	return res
+}
+
 func (c *conn) execStatement(ctx context.Context, st engine.Statement) (driver.Result, error) {
-	// Only SELECT/EXPLAIN/PRAGMA are guaranteed read-only. Treat every other
+	// Only SELECT/EXPLAIN/PRAGMA/SHOW are guaranteed read-only. Treat every other
 	// parsed statement as a write for connection scheduling so DDL, indexes,
 	// views, jobs and RBAC cannot bypass the writer gate.
 	isWrite := true
 	switch st.(type) {
-	case *engine.Select, *engine.Explain, *engine.Pragma:
+	case *engine.Select, *engine.Explain, *engine.Pragma, *engine.ShowTables, *engine.ShowColumns:
 		isWrite = false
 	}
 
@@ -979,8 +1100,12 @@ func (c *conn) execStatement(ctx context.Context, st engine.Statement) (driver.R
 			return nil, fmt.Errorf("tinysql: write attempted in read-only transaction")
 		}
 		var rs *engine.ResultSet
+		var execDB *storage.DB
+		before := -1
 		if c.inTx {
-			r, err := engine.Execute(ctx, c.currentDB(), c.tenant, st)
+			execDB = c.currentDB()
+			before = tableRowCount(execDB, c.tenant, st)
+			r, err := engine.Execute(ctx, execDB, c.tenant, st)
 			if err != nil {
 				return nil, err
 			}
@@ -1002,6 +1127,8 @@ func (c *conn) execStatement(ctx context.Context, st engine.Statement) (driver.R
 				// entire database. All other tables are shared by reference.
 				target := writeTargetTable(st)
 				shadow := base.ShallowCloneForTable(c.tenant, target)
+				execDB = shadow
+				before = tableRowCount(shadow, c.tenant, st)
 				if rs, err = engine.Execute(ctx, shadow, c.tenant, st); err != nil {
 					return nil, err
 				}
@@ -1019,20 +1146,27 @@ func (c *conn) execStatement(ctx context.Context, st engine.Statement) (driver.R
 					}
 				}
 			} else {
+				execDB = base
+				before = tableRowCount(base, c.tenant, st)
 				if rs, err = engine.Execute(ctx, base, c.tenant, st); err != nil {
 					return nil, err
 				}
 			}
 			c.srv.saveIfNeeded()
 		}
-		// Report affected rows for UPDATE/DELETE. The engine returns a single
-		// {updated|deleted: n} cell for the plain form; a RETURNING clause
-		// projects one row per affected row. INSERT has no engine-side count.
-		switch st.(type) {
+		// Report affected rows for UPDATE/DELETE/COPY. The engine returns a single
+		// {updated|deleted|copied: n} cell for the plain form; a RETURNING clause
+		// projects one row per affected row. INSERT has no engine-side count,
+		// so it is derived from the target table (see insertResult).
+		switch st := st.(type) {
+		case *engine.Insert:
+			return insertResult(execDB, c.tenant, st, before), nil
 		case *engine.Update:
 			return driver.RowsAffected(affectedRows(rs, "updated")), nil
 		case *engine.Delete:
 			return driver.RowsAffected(affectedRows(rs, "deleted")), nil
+		case *engine.CopyFrom:
+			return driver.RowsAffected(affectedRows(rs, "copied")), nil
 		}
 		return driver.RowsAffected(0), nil
 	}
@@ -1065,21 +1199,25 @@ func (c *conn) querySQL(ctx context.Context, sqlStr string) (driver.Rows, error)
 	if err != nil {
 		return nil, err
 	}
-
-	// For non-result statements, execute via pre-parsed statement (no re-parse).
-	_, isSelect := st.(*engine.Select)
-	_, isExplain := st.(*engine.Explain)
-	if !isSelect && !isExplain {
-		if _, err = c.execStatement(ctx, st); err != nil {
+	if handled, err := c.execSavepoint(st); handled {
+		if err != nil {
 			return nil, err
 		}
 		return emptyRows{}, nil
 	}
 
-	return c.queryStatement(ctx, st)
+	switch st.(type) {
+	case *engine.Select, *engine.Explain, *engine.ShowTables, *engine.ShowColumns:
+		return c.queryStatement(ctx, st)
+	}
+	// For non-result statements, execute via pre-parsed statement (no re-parse).
+	if _, err = c.execStatement(ctx, st); err != nil {
+		return nil, err
+	}
+	return emptyRows{}, nil
 }
 
-// queryStatement executes an already parsed SELECT/EXPLAIN statement. It is
+// queryStatement executes an already parsed SELECT/EXPLAIN/SHOW statement. It is
 // deliberately shared by normal and prepared queries so locking, snapshots,
 // and database/sql row ownership remain identical.
 func (c *conn) queryStatement(ctx context.Context, st engine.Statement) (driver.Rows, error) {
@@ -1089,14 +1227,16 @@ func (c *conn) queryStatement(ctx context.Context, st engine.Statement) (driver.
 	defer c.srv.releaseReader()
 	c.srv.mu.RLock()
 	defer c.srv.mu.RUnlock()
-	rs, err := engine.Execute(ctx, c.currentDB(), c.tenant, st)
+	db := c.currentDB()
+	rs, err := engine.Execute(ctx, db, c.tenant, st)
 	if err != nil {
 		return nil, err
 	}
-	return &rows{rs: rs}, nil
+	return &rows{rs: rs, cols: resultColumns(db, c.tenant, st, rs)}, nil
 }
 
-// NamedValueChecker
+// NamedValueChecker. Arguments made with sql.Named keep their Name and are
+// bound to :name/@name placeholders by bindPlaceholders.
 func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
 	// Normalize common Go types into database/sql primitive types.
 	switch v := nv.Value.(type) {
@@ -1305,13 +1445,17 @@ func markerSQLForPositionalParams(sqlText string) (string, int, bool) {
 			count++
 			continue
 		}
-		// Keep $1/:1 on the established text-binding path. This also avoids
-		// interpreting PostgreSQL casts or identifiers containing a colon.
+		// Keep $1/:1 and :name/@name on the established text-binding path.
+		// This also avoids interpreting PostgreSQL casts or identifiers
+		// containing a colon.
 		if ch == '$' || ch == ':' {
 			if i+1 < len(sqlText) && sqlText[i+1] >= '0' && sqlText[i+1] <= '9' {
 				return "", 0, false
 			}
 		}
+		if (ch == ':' || ch == '@') && i+1 < len(sqlText) && isPlaceholderNameStart(sqlText[i+1]) {
+			return "", 0, false
+		}
 		out.WriteByte(ch)
 	}
 	return out.String(), count, true
@@ -1354,7 +1498,9 @@ func collectPreparedLiterals(v reflect.Value, markers map[string]int, params []*
 }
 
 type rows struct {
-	rs        *engine.ResultSet
+	rs *engine.ResultSet
+	// cols describes rs.Cols index by index for the ColumnType* methods.
+	cols      []storage.Column
 	cachedRS  *engine.ResultSet
 	lowerCols []string
 	i         int
@@ -1407,9 +1553,143 @@ func (r *rows) Next(dest []driver.Value) error {
 }
 
 // Optional ColumnType* (informativ)
-func (r *rows) ColumnTypeDatabaseTypeName(i int) string { return "TEXT" }
-func (r *rows) ColumnTypeNullable(i int) (bool, bool)   { return true, true }
-func (r *rows) ColumnTypeScanType(i int) any            { return "interface{}" }
+func (r *rows) ColumnTypeDatabaseTypeName(i int) string {
+	if i < 0 || i >= len(r.cols) {
+		return "TEXT"
+	}
+	return databaseTypeName(r.cols[i].Type)
+}
+func (r *rows) ColumnTypeNullable(i int) (bool, bool) { return true, true }
+func (r *rows) ColumnTypeScanType(i int) reflect.Type {
+	return scanTypes[r.ColumnTypeDatabaseTypeName(i)]
+}
+
+var scanTypes = map[string]reflect.Type{
+	"INT":   reflect.TypeOf(int64(0)),
+	"FLOAT": reflect.TypeOf(float64(0)),
+	"BOOL":  reflect.TypeOf(false),
+	"TEXT":  reflect.TypeOf(""),
+	"JSON":  reflect.TypeOf(""),
+	"BLOB":  reflect.TypeOf([]byte(nil)),
+}
+
+// databaseTypeName reports the name of the Go type rows.Next produces for a
+// column type: times and decimals are rendered as text there.
+func databaseTypeName(t storage.ColType) string {
+	switch t {
+	case storage.IntType, storage.Int8Type, storage.Int16Type, storage.Int32Type, storage.Int64Type,
+		storage.UintType, storage.Uint8Type, storage.Uint16Type, storage.Uint32Type, storage.Uint64Type:
+		return "INT"
+	case storage.Float32Type, storage.Float64Type, storage.FloatType:
+		return "FLOAT"
+	case storage.BoolType:
+		return "BOOL"
+	case storage.JsonType, storage.JsonbType, storage.MapType, storage.SliceType, storage.ArrayType:
+		return "JSON"
+	case storage.ByteType, storage.BlobType:
+		return "BLOB"
+	default:
+		return "TEXT"
+	}
+}
+
+// resultColumns describes each column of rs. A SELECT output that is a plain
+// column of a FROM or JOIN table takes the table's declared type; any other
+// column (computed, aliased expression, or non-SELECT output) is typed from
+// its first non-NULL value and is TEXT when every value is NULL.
+func resultColumns(db *storage.DB, tenant string, st engine.Statement, rs *engine.ResultSet) []storage.Column {
+	if rs == nil {
+		return nil
+	}
+	declared := map[string]storage.ColType{}
+	refs := map[string]string{}   // output alias -> referenced column
+	computed := map[string]bool{} // outputs that are expressions
+	if sel, ok := st.(*engine.Select); ok && sel.Union == nil {
+		addTable := func(from engine.FromItem) {
+			if from.Table == "" || from.Subquery != nil || from.TableFunc != nil {
+				return
+			}
+			// A paged-index table answers with its schema alone instead of
+			// loading every row.
+			t, ok, err := db.PagedIndexMetadata(tenant, from.Table)
+			if err != nil {
+				return
+			}
+			if !ok {
+				if t, err = db.Get(tenant, from.Table); err != nil {
+					return
+				}
+			}
+			qualifier := strings.ToLower(from.Table)
+			if from.Alias != "" {
+				qualifier = strings.ToLower(from.Alias)
+			}
+			for _, col := range t.Cols {
+				name := strings.ToLower(col.Name)
+				if _, dup := declared[name]; !dup {
+					declared[name] = col.Type
+				}
+				declared[qualifier+"."+name] = col.Type
+			}
+		}
+		addTable(sel.From)
+		for _, j := range sel.Joins {
+			addTable(j.Right)
+		}
+		for _, p := range sel.Projs {
+			if p.Star {
+				continue
+			}
+			ref, isRef := p.Expr.(*engine.VarRef)
+			switch {
+			case isRef && p.Alias != "":
+				refs[strings.ToLower(p.Alias)] = strings.ToLower(ref.Name)
+			case !isRef && p.Alias != "":
+				computed[strings.ToLower(p.Alias)] = true
+			}
+		}
+	}
+
+	cols := make([]storage.Column, len(rs.Cols))
+	for i, name := range rs.Cols {
+		cols[i] = storage.Column{Name: name, Type: storage.StringType}
+		key := strings.ToLower(name)
+		if ref, ok := refs[key]; ok {
+			key = ref
+		}
+		if !computed[key] {
+			if t, ok := declared[key]; ok {
+				cols[i].Type = t
+				continue
+			}
+		}
+		for _, row := range rs.Rows {
+			if v := row[strings.ToLower(name)]; v != nil {
+				cols[i].Type = valueColType(v)
+				break
+			}
+		}
+	}
+	return cols
+}
+
+// valueColType maps a result value to the column type it is reported as.
+func valueColType(v any) storage.ColType {
+	switch v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return storage.IntType
+	case float32, float64:
+		return storage.Float64Type
+	case bool:
+		return storage.BoolType
+	case string, time.Time:
+		return storage.StringType
+	case []byte:
+		return storage.BlobType
+	default:
+		return storage.JsonType
+	}
+}
 
 type emptyRows struct{}
 
@@ -1418,16 +1698,35 @@ func (emptyRows) Close() error                          { return nil }
 func (emptyRows) Next([]driver.Value) error             { return io.EOF }
 func (emptyRows) ColumnTypeDatabaseTypeName(int) string { return "TEXT" }
 func (emptyRows) ColumnTypeNullable(int) (bool, bool)   { return true, true }
-func (emptyRows) ColumnTypeScanType(int) any            { return "interface{}" }
+func (emptyRows) ColumnTypeScanType(int) reflect.Type   { return scanTypes["TEXT"] }
 
 // Placeholder Binding (einfach/sicher)
+//
+// Supported forms are sequential '?', numbered $1/:1 (1-based, in any order
+// and repeatable) and named :name/@name, which bind sql.Named arguments by
+// case-insensitive name. A statement uses either positional or named
+// placeholders, never both.
 func bindPlaceholders(sqlStr string, args []driver.NamedValue) (string, error) {
 	// Precompute literal strings for all args to avoid repeated formatting.
 	lits := make([]string, len(args))
+	var named map[string]int
 	for i := range args {
 		lits[i] = sqlLiteral(args[i].Value)
+		if args[i].Name != "" {
+			if named == nil {
+				named = make(map[string]int, len(args))
+			}
+			named[strings.ToLower(args[i].Name)] = i
+		}
 	}
 	used := make([]bool, len(lits))
+	var positional, byName bool
+	mixed := func() error {
+		if positional && byName {
+			return fmt.Errorf("tinysql: cannot mix positional and named placeholders")
+		}
+		return nil
+	}
 
 	var sb strings.Builder
 	sb.Grow(len(sqlStr) + len(lits)*8)
@@ -1459,6 +1758,10 @@ func bindPlaceholders(sqlStr string, args []driver.NamedValue) (string, error) {
 
 		// Sequential placeholder '?'
 		if ch == '?' {
+			positional = true
+			if err := mixed(); err != nil {
+				return "", err
+			}
 			if argi >= len(lits) {
 				return "", fmt.Errorf("not enough args for placeholders")
 			}
@@ -1486,6 +1789,10 @@ func bindPlaceholders(sqlStr string, args []driver.NamedValue) (string, error) {
 				j++
 			}
 			if j > i+1 {
+				positional = true
+				if err := mixed(); err != nil {
+					return "", err
+				}
 				if num <= 0 || num > len(lits) {
 					return "", fmt.Errorf("tinysql: invalid placeholder %c%s", ch, sqlStr[i+1:j])
 				}
@@ -1496,6 +1803,27 @@ func bindPlaceholders(sqlStr string, args []driver.NamedValue) (string, error) {
 			}
 		}
 
+		// Named placeholders: :name or @name
+		if (ch == ':' || ch == '@') && i+1 < n && isPlaceholderNameStart(sqlStr[i+1]) {
+			j := i + 2
+			for j < n && isPlaceholderNameChar(sqlStr[j]) {
+				j++
+			}
+			name := sqlStr[i+1 : j]
+			byName = true
+			if err := mixed(); err != nil {
+				return "", err
+			}
+			idx, ok := named[strings.ToLower(name)]
+			if !ok {
+				return "", fmt.Errorf("tinysql: missing argument for named placeholder %c%s", ch, name)
+			}
+			sb.WriteString(lits[idx])
+			used[idx] = true
+			i = j - 1
+			continue
+		}
+
 		sb.WriteByte(ch)
 	}
 
@@ -1508,6 +1836,14 @@ func bindPlaceholders(sqlStr string, args []driver.NamedValue) (string, error) {
 	return sb.String(), nil
 }
 
+func isPlaceholderNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isPlaceholderNameChar(c byte) bool {
+	return isPlaceholderNameStart(c) || (c >= '0' && c <= '9')
+}
+
 // sqlLiteral converts a Go value into a SQL literal string suitable for
 // substitution in a query.
 func sqlLiteral(v any) string {
@@ -1591,8 +1927,25 @@ func applyDSNOption(c *cfg, key, value string) error {
 		if err != nil {
 			return err
 		}
+		if c.walSet && m != storage.ModeWAL {
+			return fmt.Errorf("tinysql: mode=%s conflicts with wal=1", m)
+		}
 		c.mode = m
 		c.modeSet = true
+	case "wal":
+		v, err := parseDSNBool(value, key)
+		if err != nil {
+			return err
+		}
+		if !v {
+			break
+		}
+		if c.modeSet && c.mode != storage.ModeWAL {
+			return fmt.Errorf("tinysql: wal=1 conflicts with mode=%s", c.mode)
+		}
+		c.mode = storage.ModeWAL
+		c.modeSet = true
+		c.walSet = true
 	case "max_memory_bytes":
 		sz, err := parseByteSize(value, key, false)
 		if err != nil {
@@ -133,6 +133,70 @@ func TestDriverModeJSONPersistsAndReopens(t *testing.T) {
 	}
 }
 
+// TestDriverWALOption covers wal=1: commits reach the .wal log rather than
+// a rewritten snapshot, replay after an unclean stop keeps every committed
+// row, and a second writer on the same file is refused.
+func TestDriverWALOption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.db")
+	dsn := "file:" + path + "?tenant=default&wal=1&checkpoint_every=1000"
+	if c, err := parseDSN(dsn); err != nil || !c.modeSet || c.mode != storage.ModeWAL {
+		t.Fatalf("parseDSN(wal=1) = %+v, %v", c, err)
+	}
+	for _, bad := range []string{"file:" + path + "?wal=1&mode=json", "file:" + path + "?mode=disk&wal=1"} {
+		if _, err := parseDSN(bad); err == nil {
+			t.Errorf("parseDSN(%q) accepted conflicting modes", bad)
+		}
+	}
+
+	db, err := sql.Open("tinysql", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`CREATE TABLE events (id INT, note TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 5; i++ {
+		if _, err := db.Exec(`INSERT INTO events VALUES (?, 'e')`, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	other, err := sql.Open("tinysql", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Ping(); !errors.Is(err, storage.ErrWALLocked) {
+		t.Fatalf("second writer: error %v, want ErrWALLocked", err)
+	}
+	other.Close()
+
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("snapshot written without a checkpoint: %v", err)
+	}
+	// A crash mid-append leaves a torn record at the tail of the log.
+	f, err := os.OpenFile(path+".wal", os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte{0x7f, 0x01, 0x02}); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	reopened, err := sql.Open("tinysql", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	var n int
+	if err := reopened.QueryRow(`SELECT COUNT(*) FROM events`).Scan(&n); err != nil || n != 5 {
+		t.Fatalf("rows after replay = %d, %v; want 5", n, err)
+	}
+}
+
 // TestDriverModeRequiresFilePath guards the mode= validation added
 // alongside JSON-mode DSN support: a non-memory mode with no file path is a
 // clear configuration error, not a silent fallback to in-memory.
@@ -639,7 +703,7 @@ func TestRowsNext(t *testing.T) {
 	if nullable, ok := r.ColumnTypeNullable(0); !nullable || !ok {
 		t.Fatalf("expected nullable=true")
 	}
-	if scan := r.ColumnTypeScanType(0); scan != "interface{}" {
+	if scan := r.ColumnTypeScanType(0); scan != reflect.TypeOf("") {
 		t.Fatalf("unexpected scan type: %v", scan)
 	}
 }
@@ -1287,5 +1351,443 @@ func TestDriverRowsAffectedForUpdateDelete(t *testing.T) {
 	}
 }
 
+// TestDriverInsertResult checks RowsAffected and LastInsertId for INSERT:
+// the count of affected rows and the 1-based position of the last appended
+// row, inside and outside a transaction.
+func TestDriverInsertResult(t *testing.T) {
+	db, err := sql.Open("tinysql", "mem://?tenant=insert_result")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TABLE t (id INT PRIMARY KEY, v TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(res sql.Result, wantAffected, wantID int64) {
+		t.Helper()
+		if n, err := res.RowsAffected(); err != nil || n != wantAffected {
+			t.Fatalf("RowsAffected()=%d (err %v), want %d", n, err, wantAffected)
+		}
+		if id, err := res.LastInsertId(); err != nil || id != wantID {
+			t.Fatalf("LastInsertId()=%d (err %v), want %d", id, err, wantID)
+		}
+	}
+
+	res, err := db.Exec(`INSERT INTO t VALUES (1, 'a')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(res, 1, 1)
+
+	res, err = db.Exec(`INSERT INTO t VALUES (2, 'b'), (3, 'c'), (4, 'd')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(res, 3, 4)
+
+	// One row updated in place, one appended.
+	res, err = db.Exec(`INSERT INTO t VALUES (1, 'z'), (5, 'e') ON CONFLICT (id) DO UPDATE SET v = excluded.v`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(res, 2, 5)
+
+	res, err = db.Exec(`INSERT INTO t VALUES (1, 'y') ON CONFLICT (id) DO NOTHING`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(res, 0, 0)
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err = tx.Exec(`INSERT INTO t VALUES (?, ?)`, 6, "f")
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(res, 1, 6)
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDriverPingContext(t *testing.T) {
+	db, err := sql.Open("tinysql", "mem://?tenant=ping&pool_readers=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(4)
+
+	if err := db.PingContext(context.Background()); err != nil {
+		t.Fatalf("PingContext on a fresh pool: %v", err)
+	}
+
+	// A connection exists now, so the cancelled context reaches conn.Ping.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.PingContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("PingContext with cancelled ctx = %v, want context.Canceled", err)
+	}
+	if err := conn.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := db.PingContext(context.Background()); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("concurrent PingContext: %v", err)
+	}
+	stats := db.Stats()
+	if stats.OpenConnections < 1 || stats.OpenConnections > 4 {
+		t.Fatalf("OpenConnections = %d, want 1..4", stats.OpenConnections)
+	}
+	if stats.InUse != 0 {
+		t.Fatalf("InUse = %d after all pings returned, want 0", stats.InUse)
+	}
+}
+
+func TestDriverOrdinalAndNamedParameters(t *testing.T) {
+	db, err := sql.Open("tinysql", "mem://?tenant=named_params")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TABLE t (id INT, name TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO t VALUES ($2, $1), (@id, @name)`, "a", 42, sql.Named("id", 43), sql.Named("name", "b")); err == nil {
+		t.Fatal("expected an error for mixed ordinal and named placeholders")
+	}
+	if _, err := db.Exec(`INSERT INTO t VALUES ($2, $1)`, "a", 42); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO t VALUES (@id, @name)`, sql.Named("name", "b"), sql.Named("id", 43)); err != nil {
+		t.Fatal(err)
+	}
+
+	queryName := func(q string, args ...any) string {
+		t.Helper()
+		var name string
+		if err := db.QueryRow(q, args...).Scan(&name); err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+		return name
+	}
+	if got := queryName(`SELECT name FROM t WHERE id = $1`, 42); got != "a" {
+		t.Fatalf("$1: got %q", got)
+	}
+	if got := queryName(`SELECT name FROM t WHERE id = :id`, sql.Named("id", 43)); got != "b" {
+		t.Fatalf(":id: got %q", got)
+	}
+	if got := queryName(`SELECT name FROM t WHERE name = $2 AND id = $1`, 43, "b"); got != "b" {
+		t.Fatalf("out-of-order ordinals: got %q", got)
+	}
+
+	stmt, err := db.Prepare(`SELECT name FROM t WHERE id = :id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	var name string
+	if err := stmt.QueryRow(sql.Named("id", 42)).Scan(&name); err != nil || name != "a" {
+		t.Fatalf("prepared :id: got %q, %v", name, err)
+	}
+}
+
+func TestDriverColumnTypes(t *testing.T) {
+	db, err := sql.Open("tinysql", "mem://?tenant=column_types")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, q := range []string{
+		`CREATE TABLE t (id INT, price FLOAT, name TEXT, ok BOOL, doc JSON)`,
+		`INSERT INTO t VALUES (1, 2.5, 'a', TRUE, '{"k":1}')`,
+	} {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+	}
+
+	rows, err := db.Query(`SELECT id, price, name AS label, ok, doc, LENGTH(name) AS n, price + 1 AS more, UPPER(name) AS up FROM t`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		name string
+		scan reflect.Type
+	}{
+		{"INT", reflect.TypeOf(int64(0))},
+		{"FLOAT", reflect.TypeOf(float64(0))},
+		{"TEXT", reflect.TypeOf("")},
+		{"BOOL", reflect.TypeOf(false)},
+		{"JSON", reflect.TypeOf("")},
+		{"INT", reflect.TypeOf(int64(0))},
+		{"FLOAT", reflect.TypeOf(float64(0))},
+		{"TEXT", reflect.TypeOf("")},
+	}
+	if len(types) != len(want) {
+		t.Fatalf("got %d column types, want %d", len(types), len(want))
+	}
+	for i, ct := range types {
+		if ct.DatabaseTypeName() != want[i].name || ct.ScanType() != want[i].scan {
+			t.Errorf("column %s: type %s/%v, want %s/%v", ct.Name(), ct.DatabaseTypeName(), ct.ScanType(), want[i].name, want[i].scan)
+		}
+	}
+
+	// Declared types survive an empty result.
+	empty, err := db.Query(`SELECT id, ok FROM t WHERE id < 0`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer empty.Close()
+	types, err = empty.ColumnTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if types[0].DatabaseTypeName() != "INT" || types[1].DatabaseTypeName() != "BOOL" {
+		t.Fatalf("empty result types: %s, %s", types[0].DatabaseTypeName(), types[1].DatabaseTypeName())
+	}
+}
+
 // tiny helper to quiet unused imports during incremental edits
 var _ = fmt.Sprintf
+
+func TestTransactionRollbackRestoresState(t *testing.T) {
+	db, err := sql.Open("tinysql", "mem://?tenant=tx_rollback")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	for _, q := range []string{
+		`CREATE TABLE acct (id INT, balance INT)`,
+		`INSERT INTO acct VALUES (1, 100), (2, 50)`,
+	} {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+	}
+	balances := func(q interface {
+		QueryContext(context.Context, string, ...any) (*sql.Rows, error)
+	}) map[int]int {
+		t.Helper()
+		rows, err := q.QueryContext(ctx, `SELECT id, balance FROM acct`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		got := map[int]int{}
+		for rows.Next() {
+			var id, bal int
+			if err := rows.Scan(&id, &bal); err != nil {
+				t.Fatal(err)
+			}
+			got[id] = bal
+		}
+		return got
+	}
+	want := map[int]int{1: 100, 2: 50}
+
+	// A multi-statement transaction is undone as a whole.
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range []string{
+		`INSERT INTO acct VALUES (3, 10)`,
+		`UPDATE acct SET balance = balance - 30 WHERE id = 1`,
+		`DELETE FROM acct WHERE id = 2`,
+		`CREATE TABLE scratch (x INT)`,
+	} {
+		if _, err := tx.Exec(q); err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+	}
+	if got := balances(tx); !reflect.DeepEqual(got, map[int]int{1: 70, 3: 10}) {
+		t.Fatalf("inside transaction = %v", got)
+	}
+	// Another connection keeps seeing the committed state meanwhile.
+	if got := balances(db); !reflect.DeepEqual(got, want) {
+		t.Fatalf("concurrent reader = %v, want %v", got, want)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if got := balances(db); !reflect.DeepEqual(got, want) {
+		t.Fatalf("after rollback = %v, want %v", got, want)
+	}
+	if _, err := db.Exec(`SELECT x FROM scratch`); err == nil {
+		t.Fatal("table created inside a rolled-back transaction still exists")
+	}
+
+	// A transaction keeps its snapshot while another connection commits.
+	tx, err = db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO acct VALUES (4, 1)`); err != nil {
+		t.Fatal(err)
+	}
+	if got := balances(tx); !reflect.DeepEqual(got, want) {
+		t.Fatalf("snapshot after concurrent commit = %v, want %v", got, want)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`DELETE FROM acct WHERE id = 4`); err != nil {
+		t.Fatal(err)
+	}
+
+	// A panic between Begin and Commit unwinds through the deferred Rollback.
+	func() {
+		defer func() { _ = recover() }()
+		tx, err := db.Begin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer tx.Rollback()
+		if _, err := tx.Exec(`UPDATE acct SET balance = 0`); err != nil {
+			t.Fatal(err)
+		}
+		panic("interrupted transfer")
+	}()
+	if got := balances(db); !reflect.DeepEqual(got, want) {
+		t.Fatalf("after panic = %v, want %v", got, want)
+	}
+}
+
+func TestSavepoints(t *testing.T) {
+	db, err := sql.Open("tinysql", "mem://?tenant=tx_savepoints")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TABLE sp (id INT)`); err != nil {
+		t.Fatal(err)
+	}
+	ids := func(tx *sql.Tx) []int {
+		t.Helper()
+		rows, err := tx.Query(`SELECT id FROM sp ORDER BY id`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		var got []int
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, id)
+		}
+		return got
+	}
+	exec := func(tx *sql.Tx, q string) {
+		t.Helper()
+		if _, err := tx.Exec(q); err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec(tx, `INSERT INTO sp VALUES (1)`)
+	exec(tx, `SAVEPOINT a`)
+	exec(tx, `INSERT INTO sp VALUES (2)`)
+	exec(tx, `SAVEPOINT b`)
+	exec(tx, `INSERT INTO sp VALUES (3)`)
+	exec(tx, `ROLLBACK TO SAVEPOINT b`)
+	if got := ids(tx); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("after ROLLBACK TO b = %v", got)
+	}
+	// ROLLBACK TO keeps the savepoint, so the work can be retried.
+	exec(tx, `INSERT INTO sp VALUES (4)`)
+	exec(tx, `ROLLBACK TO b`)
+	exec(tx, `ROLLBACK TO a`)
+	if got := ids(tx); !reflect.DeepEqual(got, []int{1}) {
+		t.Fatalf("after ROLLBACK TO a = %v", got)
+	}
+	// b was newer than a and is gone now.
+	if _, err := tx.Exec(`ROLLBACK TO b`); err == nil || !strings.Contains(err.Error(), "no such savepoint") {
+		t.Fatalf("ROLLBACK TO discarded savepoint: %v", err)
+	}
+	exec(tx, `INSERT INTO sp VALUES (5)`)
+	exec(tx, `RELEASE SAVEPOINT a`)
+	if _, err := tx.Exec(`ROLLBACK TO a`); err == nil {
+		t.Fatal("ROLLBACK TO a released savepoint succeeded")
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sp`).Scan(&n); err != nil || n != 2 {
+		t.Fatalf("committed rows = %d, %v; want 2", n, err)
+	}
+	if _, err := db.Exec(`SAVEPOINT outside`); err == nil || !strings.Contains(err.Error(), "active transaction") {
+		t.Fatalf("SAVEPOINT outside a transaction: %v", err)
+	}
+}
+
+func TestQueryShowTablesAndDescribe(t *testing.T) {
+	db, err := sql.Open("tinysql", "mem://?tenant=show")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TABLE users (id INT PRIMARY KEY, name TEXT)`); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+
+	var name string
+	if err := db.QueryRow(`SHOW TABLES`).Scan(&name); err != nil || name != "users" {
+		t.Fatalf("SHOW TABLES = %q, %v", name, err)
+	}
+
+	rows, err := db.Query(`DESCRIBE users`)
+	if err != nil {
+		t.Fatalf("DESCRIBE: %v", err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var field, typ, null, key, extra string
+		var def sql.NullString
+		if err := rows.Scan(&field, &typ, &null, &key, &def, &extra); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		got = append(got, field+" "+typ+" "+null+" "+key)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, ", ") != "id INT NO PRI, name TEXT YES " {
+		t.Fatalf("DESCRIBE rows = %q", got)
+	}
+}
@@ -0,0 +1,92 @@
+package driver
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+// openPredicateDB opens an in-memory database through database/sql and runs
+// the given setup statements.
+func openPredicateDB(t *testing.T, setup ...string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("tinysql", "mem://?tenant=predicates")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	for _, q := range setup {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+	}
+	return db
+}
+
+// queryInts returns the single integer column of every row produced by q.
+func queryInts(t *testing.T, db *sql.DB, q string, args ...any) []int64 {
+	t.Helper()
+	rows, err := db.Query(q, args...)
+	if err != nil {
+		t.Fatalf("%s: %v", q, err)
+	}
+	defer rows.Close()
+	out := []int64{}
+	for rows.Next() {
+		var v int64
+		if err := rows.Scan(&v); err != nil {
+			t.Fatalf("%s: scan: %v", q, err)
+		}
+		out = append(out, v)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("%s: %v", q, err)
+	}
+	return out
+}
+
+func TestBetweenThroughDriver(t *testing.T) {
+	db := openPredicateDB(t,
+		`CREATE TABLE r (id INT, n INT, f FLOAT, s TEXT)`,
+		`INSERT INTO r VALUES (1, 1, 0.5, 'apple')`,
+		`INSERT INTO r VALUES (2, 5, 1.5, 'banana')`,
+		`INSERT INTO r VALUES (3, 10, 2.5, 'cherry')`,
+		`INSERT INTO r VALUES (4, 15, 3.5, 'date')`,
+		`INSERT INTO r VALUES (5, NULL, NULL, NULL)`,
+	)
+	cases := []struct {
+		name string
+		q    string
+		args []any
+		want []int64
+	}{
+		{"int range", `SELECT id FROM r WHERE n BETWEEN 2 AND 12 ORDER BY id`, nil, []int64{2, 3}},
+		{"boundaries inclusive", `SELECT id FROM r WHERE n BETWEEN 5 AND 10 ORDER BY id`, nil, []int64{2, 3}},
+		{"empty when low > high", `SELECT id FROM r WHERE n BETWEEN 10 AND 5`, nil, []int64{}},
+		{"float range", `SELECT id FROM r WHERE f BETWEEN 1.0 AND 3.0 ORDER BY id`, nil, []int64{2, 3}},
+		{"float boundary", `SELECT id FROM r WHERE f BETWEEN 0.5 AND 1.5 ORDER BY id`, nil, []int64{1, 2}},
+		{"string ordering", `SELECT id FROM r WHERE s BETWEEN 'b' AND 'cz' ORDER BY id`, nil, []int64{2, 3}},
+		{"string boundary", `SELECT id FROM r WHERE s BETWEEN 'apple' AND 'banana' ORDER BY id`, nil, []int64{1, 2}},
+		{"not between", `SELECT id FROM r WHERE n NOT BETWEEN 5 AND 10 ORDER BY id`, nil, []int64{1, 4}},
+		{"placeholders", `SELECT id FROM r WHERE n BETWEEN ? AND ? ORDER BY id`, []any{1, 5}, []int64{1, 2}},
+		{"null bound", `SELECT id FROM r WHERE n BETWEEN NULL AND 20`, nil, []int64{}},
+		{"not between null bound", `SELECT id FROM r WHERE n NOT BETWEEN NULL AND 20`, nil, []int64{}},
+		{"null operand", `SELECT id FROM r WHERE id = 5 AND (n BETWEEN 0 AND 100 OR n NOT BETWEEN 0 AND 100)`, nil, []int64{}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := queryInts(t, db, c.q, c.args...); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("%s = %v, want %v", c.q, got, c.want)
+			}
+		})
+	}
+
+	// A NULL operand yields NULL rather than false.
+	var v sql.NullBool
+	if err := db.QueryRow(`SELECT NULL BETWEEN 1 AND 2`).Scan(&v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Valid {
+		t.Errorf("NULL BETWEEN 1 AND 2 = %v, want NULL", v.Bool)
+	}
+}
@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+func TestAlterTableAddColumn(t *testing.T) {
+	db := storage.NewDB()
+	ctx := context.Background()
+	execSQL(t, db, `CREATE TABLE items (id INT, name TEXT)`)
+	execSQL(t, db, `INSERT INTO items VALUES (1, 'a'), (2, 'b')`)
+
+	execSQL(t, db, `ALTER TABLE items ADD COLUMN note TEXT`)
+	execSQL(t, db, `ALTER TABLE items ADD qty INT NOT NULL DEFAULT 5`)
+	expectConstraintErr(t, ctx, db, "ALTER TABLE items ADD COLUMN price INT NOT NULL", "NOT NULL")
+	expectConstraintErr(t, ctx, db, "ALTER TABLE items ADD COLUMN NAME TEXT", "already exists")
+
+	rs := execSQL(t, db, `SELECT * FROM items ORDER BY id`)
+	if got := len(rs.Cols); got != 4 || rs.Cols[2] != "note" || rs.Cols[3] != "qty" {
+		t.Fatalf("columns after ALTER = %v", rs.Cols)
+	}
+	for _, r := range rs.Rows {
+		if r["note"] != nil {
+			t.Errorf("backfilled note = %v, want NULL", r["note"])
+		}
+		expectInt(t, r["qty"], 5, "backfilled qty")
+	}
+
+	// New rows see the column and its default; the NOT NULL still holds.
+	execSQL(t, db, `INSERT INTO items (id, name) VALUES (3, 'c')`)
+	execSQL(t, db, `INSERT INTO items VALUES (4, 'd', 'x', 9)`)
+	expectConstraintErr(t, ctx, db, "UPDATE items SET qty = NULL WHERE id = 1", "NOT NULL")
+	rs = execSQL(t, db, `SELECT qty FROM items WHERE id = 3`)
+	expectInt(t, rs.Rows[0]["qty"], 5, "default after ALTER")
+
+	// An empty table accepts a NOT NULL column without a default.
+	execSQL(t, db, `CREATE TABLE empty (id INT)`)
+	execSQL(t, db, `ALTER TABLE empty ADD COLUMN v INT NOT NULL`)
+
+	path := filepath.Join(t.TempDir(), "alter.gob")
+	if err := storage.SaveToFile(db, path); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := storage.LoadFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	rs = execSQL(t, reopened, `SELECT id, note, qty FROM items WHERE id = 4`)
+	if len(rs.Rows) != 1 || rs.Rows[0]["note"] != "x" {
+		t.Fatalf("after reload: %v", rs.Rows)
+	}
+	expectInt(t, rs.Rows[0]["qty"], 9, "reloaded qty")
+	execSQL(t, reopened, `INSERT INTO items (id) VALUES (5)`)
+	rs = execSQL(t, reopened, `SELECT qty FROM items WHERE id = 5`)
+	expectInt(t, rs.Rows[0]["qty"], 5, "reloaded default")
+}
+
+func TestAlterTableDropColumn(t *testing.T) {
+	db := storage.NewDB()
+	ctx := context.Background()
+	execSQL(t, db, `CREATE TABLE items (id INT PRIMARY KEY, a INT, b INT CHECK (b > 0), c INT, d TEXT, e INT, CHECK (e < 100))`)
+	execSQL(t, db, `CREATE TABLE refs (x INT REFERENCES items(a))`)
+	execSQL(t, db, `INSERT INTO items VALUES (1, 10, 20, 30, 'x', 1), (2, 11, 21, 31, 'y', 2)`)
+
+	expectConstraintErr(t, ctx, db, "ALTER TABLE items DROP COLUMN missing", "unknown column")
+	expectConstraintErr(t, ctx, db, "ALTER TABLE items DROP COLUMN id", "PRIMARY KEY")
+	expectConstraintErr(t, ctx, db, "ALTER TABLE items DROP COLUMN a", "FOREIGN KEY")
+	expectConstraintErr(t, ctx, db, "ALTER TABLE items DROP COLUMN e", "CHECK")
+
+	execSQL(t, db, `ALTER TABLE items DROP COLUMN c`) // middle
+	execSQL(t, db, `ALTER TABLE items DROP b`)        // its own CHECK goes with it
+	execSQL(t, db, `DROP TABLE refs`)
+	execSQL(t, db, `ALTER TABLE items DROP COLUMN a`)
+
+	rs := execSQL(t, db, `SELECT * FROM items ORDER BY id`)
+	if len(rs.Cols) != 3 || rs.Cols[0] != "id" || rs.Cols[1] != "d" || rs.Cols[2] != "e" {
+		t.Fatalf("columns after DROP = %v", rs.Cols)
+	}
+	if rs.Rows[1]["d"] != "y" {
+		t.Errorf("row after DROP = %v", rs.Rows[1])
+	}
+	execSQL(t, db, `INSERT INTO items VALUES (3, 'z', 3)`)
+	expectConstraintErr(t, ctx, db, "INSERT INTO items VALUES (4, 'w', 500)", "CHECK")
+
+	// First and last columns of a table without constraints.
+	execSQL(t, db, `CREATE TABLE plain (a INT, b TEXT, c INT)`)
+	execSQL(t, db, `INSERT INTO plain VALUES (1, 'one', 100)`)
+	execSQL(t, db, `ALTER TABLE plain DROP COLUMN a`)
+	execSQL(t, db, `ALTER TABLE plain DROP COLUMN c`)
+	expectConstraintErr(t, ctx, db, "ALTER TABLE plain DROP COLUMN b", "only column")
+	rs = execSQL(t, db, `SELECT * FROM plain`)
+	if len(rs.Cols) != 1 || rs.Rows[0]["b"] != "one" {
+		t.Fatalf("plain after DROP = %v %v", rs.Cols, rs.Rows)
+	}
+
+	path := filepath.Join(t.TempDir(), "drop.gob")
+	if err := storage.SaveToFile(db, path); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := storage.LoadFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	rs = execSQL(t, reopened, `SELECT * FROM items WHERE id = 3`)
+	if len(rs.Cols) != 3 || len(rs.Rows) != 1 || rs.Rows[0]["d"] != "z" {
+		t.Fatalf("after reload: %v %v", rs.Cols, rs.Rows)
+	}
+}
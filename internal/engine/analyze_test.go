@@ -64,3 +64,91 @@ func TestAnalyzePersistsStatisticsAndChoosesMostSelectiveIndex(t *testing.T) {
 		t.Fatalf("statistics were not invalidated after DML: %#v", stats)
 	}
 }
+
+func TestAnalyzeTableRefreshesStatisticsAfterInserts(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE readings (sensor TEXT, value INT)`)
+	execSQL(t, db, `INSERT INTO readings VALUES ('a', 1), ('b', 5), ('a', NULL)`)
+	execSQL(t, db, `ANALYZE TABLE readings`)
+
+	stats, err := db.GetStats("default", "readings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := stats.Columns["value"]
+	if stats.RowCount != 3 || value.TotalCount != 3 || value.NullCount != 1 || value.DistinctCount != 2 || value.Min != "1" || value.Max != "5" {
+		t.Fatalf("initial stats = %#v", stats)
+	}
+
+	execSQL(t, db, `INSERT INTO readings VALUES ('c', 9), ('d', 0)`)
+	if stats, _ := db.GetStats("default", "readings"); !stats.Stale {
+		t.Fatalf("stats not marked stale after INSERT: %#v", stats)
+	}
+	execSQL(t, db, `ANALYZE TABLE readings`)
+	stats, _ = db.GetStats("default", "readings")
+	value = stats.Columns["value"]
+	if stats.Stale || stats.RowCount != 5 || value.DistinctCount != 4 || value.Min != "0" || value.Max != "9" || stats.Columns["sensor"].DistinctCount != 4 {
+		t.Fatalf("refreshed stats = %#v", stats)
+	}
+
+	if _, err := NewParser(`ANALYZE TABLE`).ParseStatement(); err == nil {
+		t.Fatal("expected error for ANALYZE TABLE without a name")
+	}
+}
+
+func TestAnalyzeStatisticsReorderInnerJoins(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE users (u_id INT, u_region INT)`)
+	execSQL(t, db, `CREATE TABLE visits (v_region INT, v_page TEXT)`)
+	execSQL(t, db, `CREATE TABLE profiles (p_user INT, p_name TEXT)`)
+	var users, visits, profiles []string
+	for i := 0; i < 100; i++ {
+		users = append(users, fmt.Sprintf("(%d, %d)", i, i%5))
+		profiles = append(profiles, fmt.Sprintf("(%d, 'name%d')", i, i))
+	}
+	for i := 0; i < 500; i++ {
+		visits = append(visits, fmt.Sprintf("(%d, 'page%d')", i%5, i))
+	}
+	execSQL(t, db, `INSERT INTO users VALUES `+strings.Join(users, ", "))
+	execSQL(t, db, `INSERT INTO visits VALUES `+strings.Join(visits, ", "))
+	execSQL(t, db, `INSERT INTO profiles VALUES `+strings.Join(profiles, ", "))
+
+	// Written order joins the 500-row, low-cardinality visits table first
+	// (100*500/5 = 10000 estimated rows); profiles keeps 100.
+	const q = `SELECT COUNT(*) AS n FROM users u
+		JOIN visits v ON u.u_region = v.v_region
+		JOIN profiles p ON p.p_user = u.u_id
+		WHERE p.p_name = 'name7'`
+	joinTables := func() []string {
+		explain, err := Execute(context.Background(), db, "default", mustParse(`EXPLAIN `+q))
+		if err != nil {
+			t.Fatalf("EXPLAIN: %v", err)
+		}
+		var tables []string
+		for _, row := range explain.Rows {
+			if row["operation"] == "JOIN" {
+				tables = append(tables, fmt.Sprint(row["detail"]))
+			}
+		}
+		return tables
+	}
+	if got := strings.Join(joinTables(), ","); got != "visits as v,profiles as p" {
+		t.Fatalf("join order without statistics = %s", got)
+	}
+	want := expectAsInt(t, execSQL(t, db, q).Rows[0]["n"])
+
+	execSQL(t, db, `ANALYZE`)
+	if got := strings.Join(joinTables(), ","); got != "profiles as p,visits as v" {
+		t.Fatalf("join order with statistics = %s", got)
+	}
+	if got := expectAsInt(t, execSQL(t, db, q).Rows[0]["n"]); got != want || got != 100 {
+		t.Fatalf("reordered join count = %d, want %d", got, want)
+	}
+
+	// A column name shared between sources keeps the written order.
+	execSQL(t, db, `ALTER TABLE profiles ADD COLUMN u_region INT`)
+	execSQL(t, db, `ANALYZE`)
+	if got := strings.Join(joinTables(), ","); got != "visits as v,profiles as p" {
+		t.Fatalf("join order with shared column name = %s", got)
+	}
+}
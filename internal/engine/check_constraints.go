@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+func tableHasChecks(t *storage.Table) bool {
+	if len(t.Checks) > 0 {
+		return true
+	}
+	for _, c := range t.Cols {
+		if c.Check != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// validateCheckConstraints evaluates every column and table CHECK against a
+// proposed row. Only a FALSE result is a violation: a condition that is NULL
+// because of a NULL column passes, as the SQL standard requires.
+func validateCheckConstraints(env ExecEnv, t *storage.Table, row []any) error {
+	if !tableHasChecks(t) {
+		return nil
+	}
+	r := buildTableRow(t.Cols, strings.ToLower(t.Name)+".", row)
+	check := func(name, text string) error {
+		expr, err := schemaExpr(text)
+		if err != nil {
+			return fmt.Errorf("CHECK constraint %q: %w", name, err)
+		}
+		v, err := evalExpr(env, expr, r)
+		if err != nil {
+			return fmt.Errorf("CHECK constraint %q: %w", name, err)
+		}
+		if toTri(v) == tvFalse {
+			return fmt.Errorf("CHECK constraint %q violated", name)
+		}
+		return nil
+	}
+	for _, c := range t.Cols {
+		if c.Check == "" {
+			continue
+		}
+		if err := check(c.Check, c.Check); err != nil {
+			return err
+		}
+	}
+	for _, c := range t.Checks {
+		name := c.Name
+		if name == "" {
+			name = c.Expr
+		}
+		if err := check(name, c.Expr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
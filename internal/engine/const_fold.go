@@ -52,3 +52,224 @@ func foldConstFuncCall(fc *FuncCall) Expr {
 	}
 	return &Literal{Val: v}
 }
+
+// Execution-time folding of operator trees.
+//
+// foldConstants simplifies the parts of an expression that do not depend on
+// the row, so a WHERE clause such as "price > 10 * 1.2 AND 1 = 1" costs one
+// comparison per row instead of four evaluations. Bound parameters are never
+// folded: cached plans read their value through the original *Literal.
+//
+// Folding is applied to a shallow copy of the statement, never in place, so
+// prepared statements executed concurrently keep sharing one immutable AST.
+
+// disableConstantFolding lets tests compare against the unfolded plan.
+var disableConstantFolding bool
+
+// foldConstants returns e with constant subexpressions replaced by literals.
+// It returns e itself when nothing could be folded. A node whose evaluation
+// fails is kept as is, so the error still surfaces (or not) at run time.
+func foldConstants(e Expr) Expr {
+	return foldExpr(e, false)
+}
+
+// foldPredicate folds e for a context that only tests the result for TRUE
+// (WHERE, HAVING, ON). There NULL and FALSE are interchangeable, which also
+// allows NULL AND x → NULL, TRUE AND x → x and FALSE OR x → x.
+func foldPredicate(e Expr) Expr {
+	return foldExpr(e, true)
+}
+
+func isConstLiteral(e Expr) bool {
+	lit, ok := e.(*Literal)
+	return ok && !lit.Parameter
+}
+
+// foldEval evaluates a node whose operands are all constant literals.
+func foldEval(e Expr) Expr {
+	v, err := evalExpr(ExecEnv{}, e, Row{})
+	if err != nil {
+		return e
+	}
+	return &Literal{Val: v}
+}
+
+func foldExpr(e Expr, pred bool) Expr {
+	switch ex := e.(type) {
+	case *Unary:
+		inner := foldExpr(ex.Expr, false)
+		if isConstLiteral(inner) {
+			return foldEval(&Unary{Op: ex.Op, Expr: inner})
+		}
+		if inner != ex.Expr {
+			return &Unary{Op: ex.Op, Expr: inner}
+		}
+	case *IsNull:
+		inner := foldExpr(ex.Expr, false)
+		if isConstLiteral(inner) {
+			return foldEval(&IsNull{Expr: inner, Negate: ex.Negate})
+		}
+		if inner != ex.Expr {
+			return &IsNull{Expr: inner, Negate: ex.Negate}
+		}
+	case *Binary:
+		if ex.Op == "AND" || ex.Op == "OR" {
+			return foldLogical(ex, pred)
+		}
+		left, right := foldExpr(ex.Left, false), foldExpr(ex.Right, false)
+		if isConstLiteral(left) && isConstLiteral(right) {
+			return foldEval(&Binary{Op: ex.Op, Left: left, Right: right})
+		}
+		if left != ex.Left || right != ex.Right {
+			return &Binary{Op: ex.Op, Left: left, Right: right}
+		}
+	case *CaseExpr:
+		return foldCase(ex)
+	}
+	return e
+}
+
+// foldLogical folds AND/OR. Only a constant left operand may discard the
+// right one, mirroring the short circuit in evalLogicalBinary; a constant
+// right operand is dropped only where that cannot skip an evaluation.
+func foldLogical(ex *Binary, pred bool) Expr {
+	left, right := foldExpr(ex.Left, pred), foldExpr(ex.Right, pred)
+	if isConstLiteral(left) && isConstLiteral(right) {
+		return foldEval(&Binary{Op: ex.Op, Left: left, Right: right})
+	}
+	// absorbing is the value that decides the result on its own; neutral is
+	// the one that leaves the other operand's truth value unchanged.
+	absorbing, neutral := tvFalse, tvTrue
+	if ex.Op == "OR" {
+		absorbing, neutral = tvTrue, tvFalse
+	}
+	if isConstLiteral(left) {
+		switch toTri(left.(*Literal).Val) {
+		case absorbing:
+			return &Literal{Val: ex.Op == "OR"}
+		case neutral:
+			if pred {
+				return right
+			}
+		case tvUnknown:
+			if pred && ex.Op == "AND" {
+				return &Literal{Val: nil}
+			}
+		}
+	}
+	if pred && isConstLiteral(right) && toTri(right.(*Literal).Val) == neutral {
+		return left
+	}
+	if left != ex.Left || right != ex.Right {
+		return &Binary{Op: ex.Op, Left: left, Right: right}
+	}
+	return ex
+}
+
+// foldCase drops WHEN branches whose constant condition can never match and
+// resolves the CASE entirely once a constant branch is known to match before
+// any branch that depends on the row.
+func foldCase(ex *CaseExpr) Expr {
+	operand := foldExpr(ex.Operand, false)
+	changed := operand != ex.Operand
+	constOperand := ex.Operand == nil || isConstLiteral(operand)
+	var whens []CaseWhen
+	elseExpr := foldExpr(ex.Else, false)
+	changed = changed || elseExpr != ex.Else
+	for _, w := range ex.Whens {
+		when, then := foldExpr(w.When, false), foldExpr(w.Then, false)
+		changed = changed || when != w.When || then != w.Then
+		if !constOperand || !isConstLiteral(when) {
+			whens = append(whens, CaseWhen{When: when, Then: then})
+			continue
+		}
+		if !caseBranchMatches(operand, when) {
+			changed = true
+			continue
+		}
+		if len(whens) == 0 {
+			return then
+		}
+		// Later branches are unreachable: this one becomes the ELSE.
+		elseExpr, changed = then, true
+		break
+	}
+	if !changed {
+		return ex
+	}
+	if len(whens) == 0 {
+		if elseExpr == nil {
+			return &Literal{Val: nil}
+		}
+		return elseExpr
+	}
+	return &CaseExpr{Operand: operand, Whens: whens, Else: elseExpr}
+}
+
+// caseBranchMatches applies evalCaseExpr's matching rules to constant
+// operands; operand is nil for a searched CASE.
+func caseBranchMatches(operand, when Expr) bool {
+	whenVal := when.(*Literal).Val
+	if operand == nil {
+		return toTri(whenVal) == tvTrue
+	}
+	cmp, err := compare(operand.(*Literal).Val, whenVal)
+	return err == nil && cmp == 0
+}
+
+// foldSelectPredicates returns s, or a shallow copy of it whose WHERE, HAVING
+// and JOIN ... ON predicates have been folded.
+func foldSelectPredicates(s *Select) *Select {
+	if disableConstantFolding {
+		return s
+	}
+	where, having := foldPredicate(s.Where), foldPredicate(s.Having)
+	var joins []JoinClause
+	for i, j := range s.Joins {
+		on := foldPredicate(j.On)
+		if on == j.On {
+			continue
+		}
+		if joins == nil {
+			joins = append([]JoinClause(nil), s.Joins...)
+		}
+		joins[i].On = on
+	}
+	if where == s.Where && having == s.Having && joins == nil {
+		return s
+	}
+	out := *s
+	out.Where, out.Having = where, having
+	if joins != nil {
+		out.Joins = joins
+	}
+	return &out
+}
+
+// foldUpdatePredicate returns s, or a shallow copy with its WHERE folded.
+func foldUpdatePredicate(s *Update) *Update {
+	if disableConstantFolding {
+		return s
+	}
+	where := foldPredicate(s.Where)
+	if where == s.Where {
+		return s
+	}
+	out := *s
+	out.Where = where
+	return &out
+}
+
+// foldDeletePredicate returns s, or a shallow copy with its WHERE folded.
+func foldDeletePredicate(s *Delete) *Delete {
+	if disableConstantFolding {
+		return s
+	}
+	where := foldPredicate(s.Where)
+	if where == s.Where {
+		return s
+	}
+	out := *s
+	out.Where = where
+	return &out
+}
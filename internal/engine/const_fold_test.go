@@ -0,0 +1,170 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+func TestFoldConstants(t *testing.T) {
+	for _, tc := range []struct {
+		cond, want string
+		pred       bool
+	}{
+		{cond: "x > 10 * 2", want: "x > 20"},
+		{cond: "x = 1 + 2", want: "x = 3"},
+		{cond: "NOT TRUE", want: "FALSE"},
+		{cond: "7 IS NULL", want: "FALSE"},
+		{cond: "NULL IS NOT NULL", want: "FALSE"},
+		{cond: "TRUE OR x = 1", want: "TRUE"},
+		{cond: "FALSE AND x = 1", want: "FALSE"},
+		{cond: "x = 1 AND (1 < 2 OR y = 2)", want: "x = 1 AND TRUE"},
+		// A constant on the right must not skip evaluating the left.
+		{cond: "x = 1 OR TRUE", want: "x = 1 OR TRUE"},
+		{cond: "NULL AND x = 1", want: "NULL AND x = 1"},
+		{cond: "CASE 2 WHEN 1 THEN x WHEN 2 THEN y END = 5", want: "y = 5"},
+		{cond: "CASE WHEN x = 1 THEN 'a' WHEN 1 = 1 THEN 'b' WHEN x = 2 THEN 'c' END = 'b'", want: "CASE WHEN x = 1 THEN 'a' ELSE 'b' END = 'b'"},
+		{cond: "CASE 3 WHEN 1 THEN x END IS NULL", want: "TRUE"},
+
+		// Where only TRUE passes, NULL and FALSE are interchangeable.
+		{cond: "NULL AND x = 1", want: "NULL", pred: true},
+		{cond: "x = 1 AND (1 < 2 OR y = 2)", want: "x = 1", pred: true},
+		{cond: "(1 = 1 AND x = 1) OR (FALSE OR y = 2)", want: "x = 1 OR y = 2", pred: true},
+		{cond: "x = 1 OR FALSE", want: "x = 1", pred: true},
+		{cond: "NOT (NULL AND x = 1)", want: "NOT (NULL AND x = 1)", pred: true},
+	} {
+		fold := foldConstants
+		if tc.pred {
+			fold = foldPredicate
+		}
+		got := foldTestString(fold(whereExpr(tc.cond)))
+		if want := foldTestString(whereExpr(tc.want)); got != want {
+			t.Errorf("fold(%s) = %s, want %s", tc.cond, got, want)
+		}
+	}
+}
+
+// foldTestString renders the expression shapes folding produces, printing
+// numbers by value so that 3 and 3.0 compare equal.
+func foldTestString(e Expr) string {
+	switch ex := e.(type) {
+	case nil:
+		return "<nil>"
+	case *Literal:
+		if f, ok := numeric(ex.Val); ok {
+			return fmt.Sprint(f)
+		}
+		return fmt.Sprintf("%#v", ex.Val)
+	case *VarRef:
+		return ex.Name
+	case *Unary:
+		return fmt.Sprintf("%s(%s)", ex.Op, foldTestString(ex.Expr))
+	case *Binary:
+		return fmt.Sprintf("(%s %s %s)", foldTestString(ex.Left), ex.Op, foldTestString(ex.Right))
+	case *IsNull:
+		return fmt.Sprintf("(%s IS NULL negate=%v)", foldTestString(ex.Expr), ex.Negate)
+	case *CaseExpr:
+		out := "CASE " + foldTestString(ex.Operand)
+		for _, w := range ex.Whens {
+			out += " WHEN " + foldTestString(w.When) + " THEN " + foldTestString(w.Then)
+		}
+		return out + " ELSE " + foldTestString(ex.Else) + " END"
+	}
+	return fmt.Sprintf("%#v", e)
+}
+
+func TestFoldConstantsKeepsParametersAndErrors(t *testing.T) {
+	param := &Literal{Val: 1, Parameter: true}
+	e := &Binary{Op: "=", Left: &VarRef{Name: "x"}, Right: &Binary{Op: "+", Left: param, Right: &Literal{Val: 1}}}
+	if got := foldConstants(e); got != Expr(e) {
+		t.Fatalf("expression with a bound parameter was folded: %#v", got)
+	}
+	bad := whereExpr("'a' - 1 = x")
+	if got := foldConstants(bad); !reflect.DeepEqual(got, bad) {
+		t.Fatalf("failing subexpression was folded: %#v", got)
+	}
+}
+
+// randomFoldPredicate builds a boolean expression over x, y (both nullable)
+// that mixes constant and row-dependent operands.
+func randomFoldPredicate(r *rand.Rand, depth int) string {
+	if depth == 0 {
+		return []string{"TRUE", "FALSE", "NULL", "x IS NULL", "y IS NOT NULL", "NULL IS NULL"}[r.Intn(6)]
+	}
+	switch r.Intn(6) {
+	case 0:
+		return fmt.Sprintf("(%s AND %s)", randomFoldPredicate(r, depth-1), randomFoldPredicate(r, depth-1))
+	case 1:
+		return fmt.Sprintf("(%s OR %s)", randomFoldPredicate(r, depth-1), randomFoldPredicate(r, depth-1))
+	case 2:
+		return "NOT (" + randomFoldPredicate(r, depth-1) + ")"
+	case 3:
+		return fmt.Sprintf("(%s) IS NULL", randomFoldNumber(r, depth-1))
+	default:
+		ops := []string{"=", "<>", "<", ">="}
+		return fmt.Sprintf("%s %s %s", randomFoldNumber(r, depth-1), ops[r.Intn(len(ops))], randomFoldNumber(r, depth-1))
+	}
+}
+
+func randomFoldNumber(r *rand.Rand, depth int) string {
+	if depth == 0 || r.Intn(3) == 0 {
+		return []string{"x", "y", "0", "1", "2", "NULL"}[r.Intn(6)]
+	}
+	switch r.Intn(3) {
+	case 0:
+		ops := []string{"+", "-", "*"}
+		return fmt.Sprintf("(%s %s %s)", randomFoldNumber(r, depth-1), ops[r.Intn(len(ops))], randomFoldNumber(r, depth-1))
+	case 1:
+		return fmt.Sprintf("CASE %s WHEN %s THEN %s WHEN %s THEN %s ELSE %s END",
+			randomFoldNumber(r, depth-1), randomFoldNumber(r, 0), randomFoldNumber(r, depth-1),
+			randomFoldNumber(r, 0), randomFoldNumber(r, depth-1), randomFoldNumber(r, depth-1))
+	default:
+		return fmt.Sprintf("CASE WHEN %s THEN %s END", randomFoldPredicate(r, depth-1), randomFoldNumber(r, depth-1))
+	}
+}
+
+func TestConstantFoldingMatchesUnfolded(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE t (id INT, x INT, y INT)`)
+	for i := 0; i < 12; i++ {
+		x, y := fmt.Sprint(i%3), fmt.Sprint(i%4-1)
+		if i%5 == 0 {
+			x = "NULL"
+		}
+		if i%7 == 3 {
+			y = "NULL"
+		}
+		execSQL(t, db, fmt.Sprintf(`INSERT INTO t VALUES (%d, %s, %s)`, i, x, y))
+	}
+	run := func(q string) (*ResultSet, error) {
+		stmt, err := NewParser(q).ParseStatement()
+		if err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+		return Execute(context.Background(), db, "default", stmt)
+	}
+
+	r := rand.New(rand.NewSource(2041))
+	for i := 0; i < 1000; i++ {
+		cond := randomFoldPredicate(r, 3)
+		for _, q := range []string{
+			"SELECT id FROM t WHERE " + cond + " ORDER BY id",
+			"DELETE FROM t WHERE id < 0 AND " + cond,
+		} {
+			folded, err := run(q)
+			disableConstantFolding = true
+			plain, plainErr := run(q)
+			disableConstantFolding = false
+			if (err == nil) != (plainErr == nil) {
+				t.Fatalf("%s\n  folded error: %v\n  unfolded error: %v", q, err, plainErr)
+			}
+			if err == nil && !reflect.DeepEqual(folded.Rows, plain.Rows) {
+				t.Fatalf("%s\n  folded:   %v\n  unfolded: %v", q, folded.Rows, plain.Rows)
+			}
+		}
+	}
+}
@@ -2,8 +2,10 @@ package engine
 
 import (
 	"context"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/SimonWaldherr/tinySQL/internal/storage"
 )
@@ -157,3 +159,146 @@ func expectConstraintErr(t *testing.T, ctx context.Context, db *storage.DB, sql,
 		t.Fatalf("SQL error = %q, want substring %q", err.Error(), want)
 	}
 }
+
+func TestNotNullConstraintValidatesInsertUpdateAndAlter(t *testing.T) {
+	db := storage.NewDB()
+	ctx := context.Background()
+
+	execConstraintSQL(t, ctx, db, "CREATE TABLE people (id INT, name TEXT NOT NULL, note TEXT)")
+	execConstraintSQL(t, ctx, db, "INSERT INTO people VALUES (1, 'ann', NULL)")
+
+	expectConstraintErr(t, ctx, db, "INSERT INTO people VALUES (2, NULL, 'x')", "NOT NULL")
+	expectConstraintErr(t, ctx, db, "INSERT INTO people (id, note) VALUES (2, 'x')", "NOT NULL")
+	expectConstraintErr(t, ctx, db, "INSERT INTO people VALUES (2, 'bob', NULL), (3, NULL, NULL)", "NOT NULL")
+	expectConstraintErr(t, ctx, db, "UPDATE people SET name = NULL WHERE id = 1", "NOT NULL")
+	expectConstraintErr(t, ctx, db, "ALTER TABLE people ADD COLUMN age INT NOT NULL", "NOT NULL")
+
+	rs := queryConstraintSQL(t, ctx, db, "SELECT id, name, note FROM people")
+	if len(rs.Rows) != 1 || rs.Rows[0]["name"] != "ann" || rs.Rows[0]["note"] != nil {
+		t.Fatalf("rows after rejected statements: %#v", rs.Rows)
+	}
+}
+
+func TestUniqueConstraintsAllowNullsAndSkipUpdatedRow(t *testing.T) {
+	db := storage.NewDB()
+	ctx := context.Background()
+
+	execConstraintSQL(t, ctx, db, "CREATE TABLE accounts (id INT PRIMARY KEY, email TEXT UNIQUE)")
+	execConstraintSQL(t, ctx, db, "INSERT INTO accounts VALUES (1, 'a@example.test'), (2, NULL), (3, NULL)")
+
+	// Updating a row to its own value does not collide with itself.
+	execConstraintSQL(t, ctx, db, "UPDATE accounts SET email = 'a@example.test' WHERE id = 1")
+	execConstraintSQL(t, ctx, db, "UPDATE accounts SET id = 1 WHERE id = 1")
+	expectConstraintErr(t, ctx, db, "UPDATE accounts SET email = 'a@example.test' WHERE id = 3", "UNIQUE")
+	expectConstraintErr(t, ctx, db, "UPDATE accounts SET id = 1 WHERE id = 2", "PRIMARY KEY")
+}
+
+func TestTableLevelUniqueConstraint(t *testing.T) {
+	db := storage.NewDB()
+	ctx := context.Background()
+
+	execConstraintSQL(t, ctx, db, "CREATE TABLE seats (id INT PRIMARY KEY, row_no INT, seat INT, UNIQUE (row_no, seat))")
+	execConstraintSQL(t, ctx, db, "INSERT INTO seats VALUES (1, 1, 1), (2, 1, 2), (3, 2, 1), (4, 3, NULL), (5, 3, NULL)")
+
+	expectConstraintErr(t, ctx, db, "INSERT INTO seats VALUES (6, 1, 2)", "UNIQUE")
+	expectConstraintErr(t, ctx, db, "INSERT INTO seats VALUES (6, 4, 4), (7, 4, 4)", "UNIQUE")
+	expectConstraintErr(t, ctx, db, "UPDATE seats SET seat = 1 WHERE id = 2", "UNIQUE")
+	execConstraintSQL(t, ctx, db, "UPDATE seats SET seat = 2 WHERE id = 2")
+	execConstraintSQL(t, ctx, db, "UPDATE seats SET seat = 2 WHERE id = 3")
+
+	rs := queryConstraintSQL(t, ctx, db, "SELECT COUNT(*) AS n FROM seats")
+	expectInt(t, rs.Rows[0]["n"], 5, "rows after rejected statements")
+
+	if _, err := NewParser("CREATE TABLE bad (a INT, UNIQUE (b))").ParseStatement(); err == nil {
+		t.Error("expected an error for UNIQUE on an unknown column")
+	}
+
+	path := filepath.Join(t.TempDir(), "unique.gob")
+	if err := storage.SaveToFile(db, path); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := storage.LoadFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	expectConstraintErr(t, ctx, reopened, "INSERT INTO seats VALUES (6, 1, 1)", "UNIQUE")
+}
+
+func TestCheckConstraints(t *testing.T) {
+	db := storage.NewDB()
+	ctx := context.Background()
+
+	execConstraintSQL(t, ctx, db, `CREATE TABLE bookings (
+		id INT,
+		age INT CHECK (age > 0),
+		start_date DATE,
+		end_date DATE,
+		CONSTRAINT ordered_dates CHECK (end_date >= start_date))`)
+	execConstraintSQL(t, ctx, db, "INSERT INTO bookings VALUES (1, 30, '2024-01-01', '2024-01-05')")
+
+	expectConstraintErr(t, ctx, db, "INSERT INTO bookings VALUES (2, 0, NULL, NULL)", `CHECK constraint "age > 0" violated`)
+	expectConstraintErr(t, ctx, db, "INSERT INTO bookings VALUES (2, 5, '2024-02-01', '2024-01-01')", `CHECK constraint "ordered_dates" violated`)
+	// A CHECK that evaluates to NULL passes.
+	execConstraintSQL(t, ctx, db, "INSERT INTO bookings VALUES (3, NULL, '2024-02-01', NULL)")
+	expectConstraintErr(t, ctx, db, "UPDATE bookings SET age = -1 WHERE id = 1", "CHECK")
+	expectConstraintErr(t, ctx, db, "UPDATE bookings SET end_date = '2023-12-31' WHERE id = 1", "ordered_dates")
+	execConstraintSQL(t, ctx, db, "UPDATE bookings SET age = 31 WHERE id = 1")
+
+	rs := queryConstraintSQL(t, ctx, db, "SELECT id, age FROM bookings ORDER BY id")
+	if len(rs.Rows) != 2 {
+		t.Fatalf("rows after rejected statements: %#v", rs.Rows)
+	}
+	expectInt(t, rs.Rows[0]["age"], 31, "updated age")
+
+	path := filepath.Join(t.TempDir(), "checks.gob")
+	if err := storage.SaveToFile(db, path); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := storage.LoadFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	expectConstraintErr(t, ctx, reopened, "INSERT INTO bookings VALUES (4, -2, NULL, NULL)", "age > 0")
+	expectConstraintErr(t, ctx, reopened, "INSERT INTO bookings VALUES (4, 2, '2024-02-01', '2024-01-01')", "ordered_dates")
+}
+
+func TestColumnDefaults(t *testing.T) {
+	db := storage.NewDB()
+	ctx := context.Background()
+
+	execConstraintSQL(t, ctx, db, `CREATE TABLE orders (
+		id INT,
+		qty INT DEFAULT 5 NOT NULL,
+		total INT DEFAULT (1 + 2),
+		created DATETIME DEFAULT CURRENT_TIMESTAMP,
+		note TEXT)`)
+	execConstraintSQL(t, ctx, db, "INSERT INTO orders (id) VALUES (1)")
+	execConstraintSQL(t, ctx, db, "INSERT INTO orders (id, total, created) VALUES (2, NULL, NULL)")
+
+	rs := queryConstraintSQL(t, ctx, db, "SELECT id, qty, total, created FROM orders ORDER BY id")
+	if len(rs.Rows) != 2 {
+		t.Fatalf("rows = %#v", rs.Rows)
+	}
+	expectInt(t, rs.Rows[0]["qty"], 5, "literal default")
+	expectInt(t, rs.Rows[0]["total"], 3, "expression default")
+	if _, ok := rs.Rows[0]["created"].(time.Time); !ok {
+		t.Errorf("CURRENT_TIMESTAMP default = %#v", rs.Rows[0]["created"])
+	}
+	// An explicit NULL overrides the default.
+	if rs.Rows[1]["total"] != nil || rs.Rows[1]["created"] != nil {
+		t.Errorf("explicit NULLs = %#v", rs.Rows[1])
+	}
+
+	// The default satisfies NOT NULL, but an explicit NULL does not.
+	expectConstraintErr(t, ctx, db, "INSERT INTO orders (id, qty) VALUES (3, NULL)", "NOT NULL")
+	if _, err := NewParser("CREATE TABLE bad (a INT, b INT DEFAULT (a + 1))").ParseStatement(); err == nil || !strings.Contains(err.Error(), "cannot reference columns") {
+		t.Errorf("DEFAULT referencing a column: error %v", err)
+	}
+
+	rs = queryConstraintSQL(t, ctx, db, "PRAGMA table_info(orders)")
+	if rs.Rows[1]["notnull"] != 1 || rs.Rows[2]["dflt_value"] != "(1 + 2)" || rs.Rows[3]["dflt_value"] != "CURRENT_TIMESTAMP" {
+		t.Errorf("table_info = %#v", rs.Rows)
+	}
+}
@@ -0,0 +1,178 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+// copyBatchRows is how many input rows COPY hands to the INSERT path at a
+// time, bounding the literal rows held in memory for large inputs.
+const copyBatchRows = 1024
+
+type copyInputContextKey struct{}
+
+// WithCopyInput returns a context whose COPY ... FROM STDIN statements read
+// from r. Pass it to Execute like WithUser.
+func WithCopyInput(ctx context.Context, r io.Reader) context.Context {
+	return context.WithValue(ctx, copyInputContextKey{}, r)
+}
+
+func copyInputFromContext(ctx context.Context) io.Reader {
+	if ctx == nil {
+		return nil
+	}
+	r, _ := ctx.Value(copyInputContextKey{}).(io.Reader)
+	return r
+}
+
+// executeCopyFrom bulk-loads delimited text into s.Table. Each batch goes
+// through executeInsert with literal values, so coercion, constraints,
+// triggers, policies and the WAL behave exactly as for INSERT, while the
+// statement is parsed only once for the whole input.
+func executeCopyFrom(env ExecEnv, s *CopyFrom) (*ResultSet, error) {
+	src := env.copyInput
+	if s.Source != "" {
+		cleanPath := filepath.Clean(s.Source)
+		if strings.Contains(cleanPath, "..") {
+			return nil, fmt.Errorf("COPY: path traversal not allowed")
+		}
+		f, err := os.Open(cleanPath)
+		if err != nil {
+			return nil, fmt.Errorf("COPY: %v", err)
+		}
+		defer f.Close()
+		src = f
+	}
+	if src == nil {
+		return nil, fmt.Errorf("COPY FROM STDIN requires an input reader (see WithCopyInput)")
+	}
+	t, err := env.db.Get(env.tenant, s.Table)
+	if err != nil {
+		return nil, err
+	}
+	cols := t.Cols
+	if len(s.Columns) > 0 {
+		cols = make([]storage.Column, len(s.Columns))
+		for i, name := range s.Columns {
+			idx, err := t.ColIndex(name)
+			if err != nil {
+				return nil, err
+			}
+			cols[i] = t.Cols[idx]
+		}
+	}
+	width := len(cols)
+
+	read := newCopyReader(src, s.CopyOptions)
+	null := copyNullMarker(s.CopyOptions)
+	ins := &Insert{Table: s.Table, Cols: s.Columns}
+	var copied int
+	flush := func() error {
+		if len(ins.Rows) == 0 {
+			return nil
+		}
+		if _, err := executeInsert(env, ins); err != nil {
+			return err
+		}
+		copied += len(ins.Rows)
+		ins.Rows = ins.Rows[:0]
+		return nil
+	}
+	for line := 1; ; line++ { // 1-based input row, header included
+		fields, err := read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("COPY %s, row %d: %w", s.Table, line, err)
+		}
+		if line == 1 && s.Header {
+			continue
+		}
+		if len(fields) != width {
+			return nil, fmt.Errorf("COPY %s, row %d: expected %d fields, got %d", s.Table, line, width, len(fields))
+		}
+		vals := make([]Expr, width)
+		for i, f := range fields {
+			vals[i] = &Literal{Val: copyFieldValue(f, null, cols[i])}
+		}
+		ins.Rows = append(ins.Rows, vals)
+		if len(ins.Rows) == copyBatchRows {
+			if err := flush(); err != nil {
+				return nil, fmt.Errorf("COPY %s, row %d: %w", s.Table, line, err)
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, fmt.Errorf("COPY %s: %w", s.Table, err)
+	}
+	return &ResultSet{Cols: []string{"copied"}, Rows: []Row{{"copied": copied}}}, nil
+}
+
+// copyFieldValue turns one input field into the literal INSERT would see.
+// Everything is text except NULL and, for BOOL columns, PostgreSQL's boolean
+// spellings, which INSERT's text coercion would otherwise keep as strings.
+func copyFieldValue(field, null string, col storage.Column) any {
+	if field == null {
+		return nil
+	}
+	if col.Type == storage.BoolType {
+		switch strings.ToLower(strings.TrimSpace(field)) {
+		case "t", "true", "y", "yes", "on", "1":
+			return true
+		case "f", "false", "n", "no", "off", "0":
+			return false
+		}
+	}
+	return field
+}
+
+func copyNullMarker(opts CopyOptions) string {
+	switch {
+	case opts.NullSet:
+		return opts.Null
+	case strings.EqualFold(opts.Format, "TEXT"):
+		return `\N`
+	default:
+		return ""
+	}
+}
+
+// newCopyReader returns a function yielding one record per call and io.EOF
+// at the end. A final line without a trailing newline is still a record.
+func newCopyReader(src io.Reader, opts CopyOptions) func() ([]string, error) {
+	delim := opts.Delimiter
+	if strings.EqualFold(opts.Format, "TEXT") {
+		if delim == 0 {
+			delim = '\t'
+		}
+		sc := bufio.NewScanner(src)
+		sc.Buffer(make([]byte, 64*1024), 64*1024*1024)
+		return func() ([]string, error) {
+			if !sc.Scan() {
+				if err := sc.Err(); err != nil {
+					return nil, err
+				}
+				return nil, io.EOF
+			}
+			return strings.Split(strings.TrimSuffix(sc.Text(), "\r"), string(delim)), nil
+		}
+	}
+	if delim == 0 {
+		delim = ','
+	}
+	r := csv.NewReader(src)
+	r.Comma = delim
+	r.FieldsPerRecord = -1
+	r.ReuseRecord = true
+	return r.Read
+}
@@ -0,0 +1,144 @@
+// Parser and AST for PostgreSQL-style bulk loading. COPY is not reserved, so
+// it arrives as an identifier; a bare table named "copy" still selects from
+// that table.
+//
+// Grammar:
+//
+//	COPY table [(col, ...)] FROM {STDIN | 'path'}
+//	    [[WITH] (option [, ...])]
+//
+// where option is FORMAT {CSV | TEXT}, DELIMITER 'c', NULL 'marker' or
+// HEADER [TRUE | FALSE].
+package engine
+
+// CopyOptions describes the text a COPY FROM statement reads. The zero value
+// is CSV with a comma delimiter, an empty field as NULL and no header row.
+type CopyOptions struct {
+	// Columns lists the target columns in input order; empty means every
+	// table column in declaration order.
+	Columns []string
+	// Format is "CSV" (quoted fields) or "TEXT" (tab-separated, no quoting,
+	// \N as NULL). Empty means CSV.
+	Format string
+	// Delimiter separates fields; zero means ',' for CSV and a tab for TEXT.
+	Delimiter rune
+	// Null is the field text read as NULL. It is only consulted when
+	// NullSet is true; otherwise "" for CSV and \N for TEXT.
+	Null    string
+	NullSet bool
+	// Header skips the first input line.
+	Header bool
+}
+
+// CopyFrom represents COPY table FROM STDIN or FROM 'path'.
+type CopyFrom struct {
+	Table string
+	// Source is the file path, or empty for STDIN (see WithCopyInput).
+	Source string
+	CopyOptions
+}
+
+// isCopyStart reports whether the statement begins with COPY followed by a
+// table name.
+func (p *Parser) isCopyStart() bool {
+	return p.isIdentWord("COPY") && (p.peek.Typ == tIdent || p.peek.Typ == tKeyword)
+}
+
+func (p *Parser) parseCopy() (Statement, error) {
+	p.next()
+	stmt := &CopyFrom{Table: p.parseQualifiedIdentLike()}
+	if stmt.Table == "" {
+		return nil, p.errf("expected table name after COPY")
+	}
+	if p.cur.Typ == tSymbol && p.cur.Val == "(" {
+		p.next()
+		for {
+			col := p.parseIdentLike()
+			if col == "" {
+				return nil, p.errf("expected column name")
+			}
+			stmt.Columns = append(stmt.Columns, col)
+			if p.cur.Typ == tSymbol && p.cur.Val == "," {
+				p.next()
+				continue
+			}
+			break
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	switch {
+	case p.cur.Typ == tString:
+		stmt.Source = p.cur.Val
+		p.next()
+	case p.isIdentWord("STDIN"):
+		p.next()
+	default:
+		return nil, p.errf("expected STDIN or a file path after COPY ... FROM")
+	}
+	if p.cur.Typ == tKeyword && p.cur.Val == "WITH" {
+		p.next()
+	}
+	if p.cur.Typ == tSymbol && p.cur.Val == "(" {
+		p.next()
+		for {
+			if err := p.parseCopyOption(&stmt.CopyOptions); err != nil {
+				return nil, err
+			}
+			if p.cur.Typ == tSymbol && p.cur.Val == "," {
+				p.next()
+				continue
+			}
+			break
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+	}
+	return stmt, nil
+}
+
+func (p *Parser) parseCopyOption(opts *CopyOptions) error {
+	name := upper(p.cur.Val)
+	if p.cur.Typ != tIdent && p.cur.Typ != tKeyword {
+		return p.errf("expected COPY option")
+	}
+	p.next()
+	switch name {
+	case "FORMAT":
+		format := upper(p.cur.Val)
+		if format != "CSV" && format != "TEXT" {
+			return p.errf("COPY FORMAT must be CSV or TEXT")
+		}
+		opts.Format = format
+		p.next()
+	case "DELIMITER":
+		if p.cur.Typ != tString || len([]rune(p.cur.Val)) != 1 {
+			return p.errf("COPY DELIMITER must be a single-character string")
+		}
+		opts.Delimiter = []rune(p.cur.Val)[0]
+		p.next()
+	case "NULL":
+		if p.cur.Typ != tString {
+			return p.errf("COPY NULL must be a string")
+		}
+		opts.Null, opts.NullSet = p.cur.Val, true
+		p.next()
+	case "HEADER":
+		opts.Header = true
+		switch upper(p.cur.Val) {
+		case "TRUE", "ON":
+			p.next()
+		case "FALSE", "OFF":
+			opts.Header = false
+			p.next()
+		}
+	default:
+		return p.errf("unknown COPY option %s", name)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+func copyFrom(t *testing.T, db *storage.DB, sql, input string) *ResultSet {
+	t.Helper()
+	ctx := WithCopyInput(context.Background(), strings.NewReader(input))
+	rs, err := Execute(ctx, db, "default", mustParse(sql))
+	if err != nil {
+		t.Fatalf("%s: %v", sql, err)
+	}
+	return rs
+}
+
+func TestCopyFromStdinCoercesTypes(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE items (id INT PRIMARY KEY, price FLOAT, name TEXT, active BOOL)`)
+	rs := copyFrom(t, db, `COPY items FROM STDIN WITH (FORMAT CSV, HEADER)`,
+		"id,price,name,active\n1,2.5,\"widget, large\",true\n2,3,gadget,false\n")
+	if got := rs.RowsAffected(); got != 2 {
+		t.Fatalf("RowsAffected = %d, want 2", got)
+	}
+	rows := execSQL(t, db, `SELECT id, price, name, active FROM items ORDER BY id`).Rows
+	if rows[0]["id"] != 1 || rows[0]["price"] != 2.5 || rows[0]["name"] != "widget, large" || rows[0]["active"] != true {
+		t.Fatalf("row 1 = %#v", rows[0])
+	}
+	if rows[1]["price"] != 3.0 || rows[1]["active"] != false {
+		t.Fatalf("row 2 = %v", rows[1])
+	}
+
+	// A row violating a constraint fails the statement and leaves no rows.
+	ctx := WithCopyInput(context.Background(), strings.NewReader("3,1,x,true\n1,1,y,true\n"))
+	if _, err := Execute(ctx, db, "default", mustParse(`COPY items FROM STDIN`)); err == nil {
+		t.Fatalf("expected primary key violation")
+	}
+	expectInt(t, execSQL(t, db, `SELECT COUNT(*) AS n FROM items`).Rows[0]["n"], 2, "rows after failed COPY")
+}
+
+func TestCopyFromDelimiterNullAndColumns(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE people (id INT, name TEXT, city TEXT)`)
+	copyFrom(t, db, `COPY people (name, id) FROM STDIN WITH (DELIMITER '|', NULL 'null')`,
+		"alice|1\nnull|2\n")
+	rows := execSQL(t, db, `SELECT id, name, city FROM people ORDER BY id`).Rows
+	if rows[0]["name"] != "alice" || rows[0]["city"] != nil {
+		t.Fatalf("row 1 = %#v", rows[0])
+	}
+	if rows[1]["name"] != nil {
+		t.Fatalf("NULL marker not applied: %v", rows[1])
+	}
+
+	// TEXT format: tab-separated with \N as NULL and no quoting.
+	copyFrom(t, db, `COPY people FROM STDIN (FORMAT TEXT)`, "3\t\"quoted\"\t\\N\n")
+	rs := execSQL(t, db, `SELECT name, city FROM people WHERE id = 3`)
+	if rs.Rows[0]["name"] != `"quoted"` || rs.Rows[0]["city"] != nil {
+		t.Fatalf("TEXT row = %v", rs.Rows[0])
+	}
+}
+
+func TestCopyFromPartialLastLine(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE nums (n INT, label TEXT)`)
+	copyFrom(t, db, `COPY nums FROM STDIN`, "1,a\n2,b\n3,c")
+	expectInt(t, execSQL(t, db, `SELECT COUNT(*) AS n FROM nums`).Rows[0]["n"], 3, "rows")
+
+	// A truncated final record is an error, not a silently shorter row.
+	ctx := WithCopyInput(context.Background(), strings.NewReader("4,d\n5"))
+	_, err := Execute(ctx, db, "default", mustParse(`COPY nums FROM STDIN`))
+	if err == nil || !strings.Contains(err.Error(), "row 2") {
+		t.Fatalf("expected field count error on row 2, got %v", err)
+	}
+	ctx = WithCopyInput(context.Background(), strings.NewReader("6,\"unterminated"))
+	if _, err := Execute(ctx, db, "default", mustParse(`COPY nums FROM STDIN`)); err == nil {
+		t.Fatalf("expected error for unterminated quote")
+	}
+	expectInt(t, execSQL(t, db, `SELECT COUNT(*) AS n FROM nums`).Rows[0]["n"], 3, "rows after failed COPY")
+}
+
+func TestCopyFromFileAndErrors(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE t (a INT, b TEXT)`)
+	path := filepath.Join(t.TempDir(), "t.csv")
+	if err := os.WriteFile(path, []byte("1,x\n2,y\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rs, err := Execute(context.Background(), db, "default", mustParse(fmt.Sprintf(`COPY t FROM '%s'`, path)))
+	if err != nil {
+		t.Fatalf("COPY FROM file: %v", err)
+	}
+	if rs.RowsAffected() != 2 {
+		t.Fatalf("RowsAffected = %d", rs.RowsAffected())
+	}
+	for q, want := range map[string]string{
+		`COPY t FROM STDIN`:              "input reader",
+		`COPY t FROM '../t.csv'`:         "traversal",
+		`COPY t FROM STDIN (FORMAT XML)`: "CSV or TEXT",
+		`COPY missing FROM STDIN`:        "missing",
+	} {
+		stmt, err := NewParser(q).ParseStatement()
+		if err == nil {
+			_, err = Execute(context.Background(), db, "default", stmt)
+		}
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: error %v, want %q", q, err, want)
+		}
+	}
+}
+
+func TestCopyFromFasterThanInserts(t *testing.T) {
+	if testing.Short() {
+		t.Skip("timing comparison")
+	}
+	const n = 20000
+	var input strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&input, "%d,name%d,%d.5\n", i, i, i)
+	}
+
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE bulk (id INT, name TEXT, score FLOAT)`)
+	start := time.Now()
+	copyFrom(t, db, `COPY bulk FROM STDIN`, input.String())
+	copyTime := time.Since(start)
+	expectInt(t, execSQL(t, db, `SELECT COUNT(*) AS n FROM bulk`).Rows[0]["n"], n, "copied rows")
+
+	db = storage.NewDB()
+	execSQL(t, db, `CREATE TABLE bulk (id INT, name TEXT, score FLOAT)`)
+	start = time.Now()
+	for i := 0; i < n; i++ {
+		execSQL(t, db, fmt.Sprintf(`INSERT INTO bulk VALUES (%d, 'name%d', %d.5)`, i, i, i))
+	}
+	insertTime := time.Since(start)
+	if copyTime >= insertTime {
+		t.Fatalf("COPY took %v, %d INSERTs took %v", copyTime, n, insertTime)
+	}
+}
+
+func BenchmarkCopyFrom(b *testing.B) {
+	var input strings.Builder
+	for i := 0; i < 100000; i++ {
+		fmt.Fprintf(&input, "%d,name%d,%d.5\n", i, i, i)
+	}
+	data := input.String()
+	stmt := mustParse(`COPY bulk FROM STDIN`)
+	for b.Loop() {
+		db := storage.NewDB()
+		if _, err := Execute(context.Background(), db, "default", mustParse(`CREATE TABLE bulk (id INT, name TEXT, score FLOAT)`)); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := Execute(WithCopyInput(context.Background(), strings.NewReader(data)), db, "default", stmt); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
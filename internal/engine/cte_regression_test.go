@@ -2,6 +2,8 @@ package engine
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/SimonWaldherr/tinySQL/internal/storage"
@@ -94,8 +96,9 @@ func TestRecursiveCTEFanOutJoinExceedsRowLimitInsteadOfExhaustingMemory(t *testi
 
 	// Each iteration self-joins the frontier against a 2-row table with a
 	// trivially-true ON condition, doubling the frontier every round. With
-	// iterLimit at 1024 this would keep doubling well past any reasonable
-	// memory budget (2^30+ rows) long before the iteration cap ever kicks in.
+	// the default recursion depth of 1000 this would keep doubling well past
+	// any reasonable memory budget (2^30+ rows) long before the iteration cap
+	// ever kicks in.
 	stmt, err := NewParser(`
 		WITH RECURSIVE cnt AS (
 			SELECT 1 AS n
@@ -132,3 +135,135 @@ func TestRecursiveCTEUnionAllPreservesDuplicateFrontierRows(t *testing.T) {
 		t.Fatalf("recursive UNION ALL values = %#v, want [1 2 2]", got)
 	}
 }
+
+func TestCTEVisibleInSubqueriesAndShadowsTables(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE items (id INT, qty INT)`)
+	execSQL(t, db, `INSERT INTO items VALUES (1, 5), (2, 50), (3, 500)`)
+
+	rs := execSQL(t, db, `
+		WITH big AS (SELECT id FROM items WHERE qty > 10)
+		SELECT id, (SELECT COUNT(*) FROM big) AS n FROM items
+		WHERE EXISTS (SELECT 1 FROM big b WHERE b.id = items.id)
+		ORDER BY id
+	`)
+	if len(rs.Rows) != 2 {
+		t.Fatalf("CTE in subqueries returned %#v", rs.Rows)
+	}
+	expectInt(t, rs.Rows[0]["id"], 2, "first id")
+	expectInt(t, rs.Rows[1]["id"], 3, "second id")
+	expectInt(t, rs.Rows[0]["n"], 2, "scalar subquery over CTE")
+
+	// A CTE named like a real table wins for the duration of the statement
+	// and leaves the table untouched.
+	rs = execSQL(t, db, `WITH items AS (SELECT 42 AS id) SELECT id FROM items`)
+	if len(rs.Rows) != 1 {
+		t.Fatalf("shadowing CTE returned %#v", rs.Rows)
+	}
+	expectInt(t, rs.Rows[0]["id"], 42, "shadowed id")
+	rs = execSQL(t, db, `SELECT COUNT(*) AS n FROM items`)
+	expectInt(t, rs.Rows[0]["n"], 3, "table after shadowing CTE")
+}
+
+func recursiveCTEInts(t *testing.T, rs *ResultSet, col string) []int {
+	t.Helper()
+	out := make([]int, len(rs.Rows))
+	for i, row := range rs.Rows {
+		out[i] = expectAsInt(t, row[col])
+	}
+	return out
+}
+
+func TestRecursiveCTEFibonacci(t *testing.T) {
+	db := storage.NewDB()
+	rs := execSQL(t, db, `
+		WITH RECURSIVE fib (n, a, b) AS (
+			SELECT 1, 0, 1
+			UNION ALL
+			SELECT n + 1, b, a + b FROM fib WHERE n < 10
+		)
+		SELECT a FROM fib ORDER BY n
+	`)
+	got := recursiveCTEInts(t, rs, "a")
+	want := []int{0, 1, 1, 2, 3, 5, 8, 13, 21, 34}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("fibonacci = %v, want %v", got, want)
+	}
+}
+
+func TestRecursiveCTEEmployeeHierarchy(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE employees (id INT, name TEXT, manager_id INT)`)
+	execSQL(t, db, `INSERT INTO employees VALUES
+		(1, 'ceo', NULL), (2, 'cto', 1), (3, 'cfo', 1),
+		(4, 'dev', 2), (5, 'ops', 2), (6, 'intern', 4), (7, 'other', NULL)`)
+	rs := execSQL(t, db, `
+		WITH RECURSIVE reports AS (
+			SELECT id, name, 0 AS depth FROM employees WHERE id = 2
+			UNION ALL
+			SELECT e.id, e.name, r.depth + 1 FROM employees e JOIN reports r ON e.manager_id = r.id
+		)
+		SELECT name, depth FROM reports ORDER BY depth, name
+	`)
+	var got []string
+	for _, row := range rs.Rows {
+		got = append(got, fmt.Sprintf("%v:%d", row["name"], expectAsInt(t, row["depth"])))
+	}
+	if want := "[cto:0 dev:1 ops:1 intern:2]"; fmt.Sprint(got) != want {
+		t.Fatalf("hierarchy = %v, want %s", got, want)
+	}
+}
+
+func TestRecursiveCTEGraphConnectivityWithUnion(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE edges (src INT, dst INT)`)
+	// 1 → 2 → 3 → 1 is a cycle; 4 → 5 is unreachable from 1.
+	execSQL(t, db, `INSERT INTO edges VALUES (1, 2), (2, 3), (3, 1), (2, 3), (4, 5)`)
+	rs := execSQL(t, db, `
+		WITH RECURSIVE reach (node) AS (
+			SELECT 1
+			UNION
+			SELECT e.dst FROM edges e JOIN reach r ON e.src = r.node
+		)
+		SELECT node FROM reach ORDER BY node
+	`)
+	if got := recursiveCTEInts(t, rs, "node"); fmt.Sprint(got) != "[1 2 3]" {
+		t.Fatalf("reachable nodes = %v, want [1 2 3]", got)
+	}
+}
+
+func TestRecursiveCTECycleStopsAtDepthLimit(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE edges (src INT, dst INT)`)
+	execSQL(t, db, `INSERT INTO edges VALUES (1, 2), (2, 1)`)
+
+	// A depth counter keeps UNION ALL from following the cycle forever.
+	rs := execSQL(t, db, `
+		WITH RECURSIVE walk (node, depth) AS (
+			SELECT 1, 0
+			UNION ALL
+			SELECT e.dst, w.depth + 1 FROM edges e JOIN walk w ON e.src = w.node WHERE w.depth < 5
+		)
+		SELECT node FROM walk ORDER BY depth
+	`)
+	if got := recursiveCTEInts(t, rs, "node"); fmt.Sprint(got) != "[1 2 1 2 1 2]" {
+		t.Fatalf("bounded walk = %v", got)
+	}
+
+	// Without one, the recursion depth limit ends the statement.
+	unbounded := mustParse(`
+		WITH RECURSIVE walk (node) AS (
+			SELECT 1
+			UNION ALL
+			SELECT e.dst FROM edges e JOIN walk w ON e.src = w.node
+		)
+		SELECT COUNT(*) AS n FROM walk
+	`)
+	if _, err := Execute(context.Background(), db, "default", unbounded); err == nil || !strings.Contains(err.Error(), "maximum recursion depth 1000") {
+		t.Fatalf("expected default recursion depth error, got %v", err)
+	}
+	_, err := ExecuteWithOptions(context.Background(), db, "default", unbounded, QueryOptions{MaxRecursionDepth: 10})
+	if err == nil || !strings.Contains(err.Error(), "maximum recursion depth 10") {
+		t.Fatalf("expected MaxRecursionDepth error, got %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+func TestDateColumns(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE ev (id INT, d DATE, ts DATETIME)`)
+	execSQL(t, db, `INSERT INTO ev VALUES
+		(1, '2024-03-05', '2024-03-05 10:00:00'),
+		(2, '2024-01-10 08:30:00', '2023-12-31 23:59:59'),
+		(3, NULL, NULL)`)
+
+	// DATE drops the time of day; both types store time.Time.
+	rs := execSQL(t, db, `SELECT d, ts FROM ev WHERE id = 2`)
+	if d, ok := rs.Rows[0]["d"].(time.Time); !ok || !d.Equal(time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("DATE value = %#v", rs.Rows[0]["d"])
+	}
+	if _, ok := rs.Rows[0]["ts"].(time.Time); !ok {
+		t.Errorf("DATETIME value = %#v", rs.Rows[0]["ts"])
+	}
+
+	rs = execSQL(t, db, `SELECT id, ts FROM ev WHERE ts IS NOT NULL ORDER BY ts`)
+	if len(rs.Rows) != 2 {
+		t.Fatalf("ORDER BY ts: %v", rs.Rows)
+	}
+	expectInt(t, rs.Rows[0]["id"], 2, "earliest ts")
+
+	// Comparisons with string literals are chronological.
+	for q, want := range map[string]map[int]bool{
+		`SELECT id FROM ev WHERE d > '2024-02-01'`:                             {1: true},
+		`SELECT id FROM ev WHERE d = '2024-03-05'`:                             {1: true},
+		`SELECT id FROM ev WHERE d IN ('2024-01-10', '2030-01-01')`:            {2: true},
+		`SELECT id FROM ev WHERE ts BETWEEN '2024-01-01' AND '2024-12-31'`:     {1: true},
+		`SELECT id FROM ev WHERE ts < '2024-01-01T00:00:00' OR d IS NULL`:      {2: true, 3: true},
+		`SELECT id FROM ev WHERE EXTRACT(YEAR FROM ts) = 2024`:                 {1: true},
+		`SELECT id FROM ev WHERE DATE_DIFF('day', d, '2024-03-15') = 10`:       {1: true},
+		`SELECT id FROM ev WHERE DATE_ADD(d, 1, 'DAY') = '2024-01-11'`:         {2: true},
+		`SELECT id FROM ev WHERE d <= CURRENT_DATE AND ts < CURRENT_TIMESTAMP`: {1: true, 2: true},
+	} {
+		got := idSet(execSQL(t, db, q).Rows)
+		if len(got) != len(want) {
+			t.Errorf("%s = %v, want %v", q, got, want)
+			continue
+		}
+		for id := range want {
+			if !got[id] {
+				t.Errorf("%s = %v, want %v", q, got, want)
+				break
+			}
+		}
+	}
+
+	// Date functions propagate NULL.
+	rs = execSQL(t, db, `SELECT DATE(d) AS a, EXTRACT(MONTH FROM d) AS b, DATE_DIFF('day', d, ts) AS c, DATE_ADD(d, 1, 'DAY') AS e FROM ev WHERE id = 3`)
+	for _, k := range []string{"a", "b", "c", "e"} {
+		if rs.Rows[0][k] != nil {
+			t.Errorf("%s = %v, want NULL", k, rs.Rows[0][k])
+		}
+	}
+
+	if _, err := Execute(context.Background(), db, "default", mustParse(`INSERT INTO ev VALUES (4, 'not a date', NULL)`)); err == nil {
+		t.Error("expected an error inserting an unparseable DATE")
+	}
+
+	// Dates survive a snapshot round trip.
+	path := filepath.Join(t.TempDir(), "dates.gob")
+	if err := storage.SaveToFile(db, path); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := storage.LoadFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	rs = execSQL(t, reopened, `SELECT id FROM ev WHERE d = '2024-03-05'`)
+	if len(rs.Rows) != 1 {
+		t.Fatalf("after reload: %v", rs.Rows)
+	}
+	expectInt(t, rs.Rows[0]["id"], 1, "reloaded date lookup")
+}
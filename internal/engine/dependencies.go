@@ -131,10 +131,16 @@ func addDependency(cat *storage.CatalogManager, table string, ctes map[string]bo
 	}
 }
 
+// splitObjectName splits "schema.name". Unqualified names, and the
+// PostgreSQL spelling "public", belong to the default schema main.
 func splitObjectName(name string) (string, string) {
 	parts := strings.SplitN(strings.TrimSpace(name), ".", 2)
 	if len(parts) == 2 {
-		return strings.ToLower(parts[0]), parts[1]
+		schema := strings.ToLower(parts[0])
+		if schema == "public" {
+			schema = "main"
+		}
+		return schema, parts[1]
 	}
 	return "main", name
 }
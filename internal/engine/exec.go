@@ -28,6 +28,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math"
 	"math/big"
 	"math/rand"
@@ -73,6 +74,12 @@ func getAllFunctions() map[string]funcHandler {
 		for k, v := range getGeoFunctions() {
 			m[k] = v
 		}
+		for k, v := range getJSONFunctions() {
+			m[k] = v
+		}
+		for k, v := range getSequenceFunctions() {
+			m[k] = v
+		}
 		allFunctions = m
 	})
 	return allFunctions
@@ -90,6 +97,27 @@ type ResultSet struct {
 	Rows []Row
 }
 
+// RowsAffected reports how many rows a statement touched: the count cell of
+// a plain UPDATE/DELETE/COPY result ({updated: n} / {deleted: n} /
+// {copied: n}), otherwise the
+// number of returned rows. A nil result (INSERT, DDL) reports 0.
+func (rs *ResultSet) RowsAffected() int64 {
+	if rs == nil {
+		return 0
+	}
+	if len(rs.Rows) == 1 && len(rs.Cols) == 1 && (rs.Cols[0] == "updated" || rs.Cols[0] == "deleted" || rs.Cols[0] == "copied") {
+		switch n := rs.Rows[0][rs.Cols[0]].(type) {
+		case int:
+			return int64(n)
+		case int64:
+			return n
+		case float64:
+			return int64(n)
+		}
+	}
+	return int64(len(rs.Rows))
+}
+
 type ExecEnv struct {
 	ctx         context.Context
 	tenant      string
@@ -97,7 +125,10 @@ type ExecEnv struct {
 	ctes        map[string]*ResultSet // For CTE support
 	windowRows  []Row                 // All rows for window function context
 	windowIndex int                   // Current row index in window context
-	viewDepth   int
+	// windowValues holds the precomputed result of each window function
+	// call in the SELECT list, indexed like windowRows (see window.go).
+	windowValues map[*FuncCall][]any
+	viewDepth    int
 	// triggerRow carries new.<col>/old.<col> pseudo-columns while executing a
 	// trigger body statement (see executeTrigger in triggers.go), so
 	// NEW.col/OLD.col resolve even though the body statement's own row
@@ -122,6 +153,26 @@ type ExecEnv struct {
 	// same "now" and ranks consistently. The zero value falls back to
 	// time.Now() (see envNow), which is what ExecEnv{} in tests gets.
 	now time.Time
+	// outerRow is the enclosing query's current row while a correlated
+	// EXISTS subquery executes, so inner references such as t1.pk resolve
+	// against the outer FROM clause when the inner row has no such column.
+	outerRow Row
+	// profile collects per-operation statistics for ExplainAnalyze. Only
+	// the statement's outermost SELECT records into it.
+	profile *queryProfile
+	// limits holds the statement's QueryOptions (see ExecuteWithOptions);
+	// nil when it runs without limits.
+	limits *QueryOptions
+	// userID is the acting user from WithUser; row-level security policies
+	// (see row_policy.go) substitute it for $current_user.
+	userID string
+	// copyInput is the reader COPY ... FROM STDIN consumes (see
+	// WithCopyInput).
+	copyInput io.Reader
+	// deferredWrite is set by executeStatement for REFRESH MATERIALIZED
+	// VIEW, which then runs its query under the shared read lock and stores
+	// the cache swap here to be applied once the write lock is held.
+	deferredWrite *func() error
 }
 
 // envNow returns the statement's evaluation timestamp, falling back to
@@ -166,6 +217,9 @@ func executeCreateView(env ExecEnv, s *CreateView) (*ResultSet, error) {
 			return nil, fmt.Errorf("view %q already exists", s.Name)
 		}
 	}
+	if _, err := env.db.Get(env.tenant, s.Name); err == nil {
+		return nil, fmt.Errorf("cannot create view %q: a table with that name already exists", s.Name)
+	}
 	sqlText := s.SQLText
 	if strings.TrimSpace(sqlText) == "" {
 		return nil, fmt.Errorf("CREATE VIEW %s missing stored SQL text", s.Name)
@@ -213,6 +267,8 @@ func executeCreateMaterializedView(env ExecEnv, s *CreateMaterializedView) (*Res
 		if err := refreshMaterializedView(env, s.Name); err != nil {
 			return nil, err
 		}
+	} else if err := createEmptyMaterializedViewCache(env, s.Name, mv); err != nil {
+		return nil, err
 	}
 	return nil, nil
 }
@@ -288,9 +344,17 @@ func executeDropMaterializedView(env ExecEnv, s *DropMaterializedView) (*ResultS
 	return nil, env.db.Catalog().DeleteMaterializedView(schema, name)
 }
 
+// executeRefreshMaterializedView recomputes the view. Readers keep seeing
+// the previous contents until the new cache table replaces it, so REFRESH
+// always behaves like PostgreSQL's REFRESH ... CONCURRENTLY.
 func executeRefreshMaterializedView(env ExecEnv, s *RefreshMaterializedView) (*ResultSet, error) {
-	_ = s.Concurrently
-	if err := refreshMaterializedView(env, s.Name); err != nil {
+	install, err := prepareMaterializedViewRefresh(env, s.Name)
+	if err != nil {
+		return nil, err
+	}
+	if env.deferredWrite != nil {
+		*env.deferredWrite = install
+	} else if err := install(); err != nil {
 		return nil, err
 	}
 	return &ResultSet{Cols: []string{"refreshed"}, Rows: []Row{{"refreshed": s.Name}}}, nil
@@ -338,6 +402,8 @@ func executeAlterViewMaterialize(env ExecEnv, s *AlterViewMaterialize) (*ResultS
 		if err := refreshMaterializedView(env, s.Name); err != nil {
 			return nil, err
 		}
+	} else if err := createEmptyMaterializedViewCache(env, s.Name, mv); err != nil {
+		return nil, err
 	}
 	return &ResultSet{Cols: []string{"materialized"}, Rows: []Row{{"materialized": s.Name}}}, nil
 }
@@ -453,42 +519,73 @@ func dailyAtToCron(dailyAt string) (string, error) {
 	return fmt.Sprintf("0 %d %d * * *", minute, hour), nil
 }
 
-func refreshMaterializedView(env ExecEnv, name string) (err error) {
+func refreshMaterializedView(env ExecEnv, name string) error {
+	install, err := prepareMaterializedViewRefresh(env, name)
+	if err != nil {
+		return err
+	}
+	return install()
+}
+
+// prepareMaterializedViewRefresh runs the view's query into a new cache
+// table and returns the function that swaps it in. Only the swap writes, so
+// the query may run while other statements still read the old cache. The
+// refresh stays marked in progress until install runs, which the caller
+// must do whenever err is nil.
+func prepareMaterializedViewRefresh(env ExecEnv, name string) (install func() error, err error) {
 	schema, objectName := splitObjectName(name)
 	mv, ok := env.db.Catalog().GetMaterializedView(schema, objectName)
 	if !ok {
-		return fmt.Errorf("materialized view %q not found", name)
+		return nil, fmt.Errorf("materialized view %q not found", name)
 	}
 	if !env.db.Catalog().TryBeginMaterializedViewRefresh(schema, objectName) {
-		return fmt.Errorf("materialized view %q is already refreshing", name)
+		return nil, fmt.Errorf("materialized view %q is already refreshing", name)
 	}
 
 	start := time.Now()
-	rowCount := int64(0)
-	defer func() {
+	finish := func(rowCount int64, err error) {
 		errMsg := ""
 		if err != nil {
 			errMsg = err.Error()
 		}
 		_ = env.db.Catalog().FinishMaterializedViewRefresh(schema, objectName, time.Now(), time.Since(start).Milliseconds(), rowCount, errMsg)
-	}()
+	}
+	cache, err := buildMaterializedViewCache(env, name, mv, false)
+	if err != nil {
+		finish(0, err)
+		return nil, err
+	}
+	return func() (err error) {
+		defer func() { finish(int64(len(cache.Rows)), err) }()
+		return replaceMaterializedViewCache(env, cache)
+	}, nil
+}
 
-	stmt, parseErr := NewParser(mv.SQLText).ParseStatement()
-	if parseErr != nil {
-		return parseErr
+// buildMaterializedViewCache executes the view's SELECT and copies the
+// result into a detached table named after the view's cache table. With
+// empty set only the column names are kept, for CREATE ... WITH NO DATA.
+func buildMaterializedViewCache(env ExecEnv, name string, mv *storage.CatalogMaterializedView, empty bool) (*storage.Table, error) {
+	stmt, err := NewParser(mv.SQLText).ParseStatement()
+	if err != nil {
+		return nil, err
 	}
 	sel, ok := stmt.(*Select)
 	if !ok {
-		return fmt.Errorf("materialized view %q query is not a SELECT", name)
+		return nil, fmt.Errorf("materialized view %q query is not a SELECT", name)
 	}
-	// Recurses via execStmt, not Execute: this runs inside materialized-view
-	// refresh, already inside Execute's write lock on the same goroutine.
-	rs, execErr := execStmt(env, sel)
-	if execErr != nil {
-		return execErr
+	if empty {
+		limited, zero := *sel, 0
+		limited.Limit = &zero
+		sel = &limited
+	}
+	// Recurses via execStmt, not Execute: this runs inside the statement's
+	// content lock on the same goroutine.
+	rs, err := execStmt(env, sel)
+	if err != nil {
+		return nil, err
 	}
 	if rs == nil {
-		return fmt.Errorf("materialized view %q query produced no result set", name)
+		return nil, fmt.Errorf("materialized view %q query produced no result set", name)
 	}
 
 	cols := make([]storage.Column, len(rs.Cols))
@@ -507,53 +604,159 @@ func refreshMaterializedView(env ExecEnv, name string) (err error) {
 		}
 		cache.Rows = append(cache.Rows, row)
 	}
-	rowCount = int64(len(cache.Rows))
-	if _, err := env.db.Get(env.tenant, mv.CacheTableName); err == nil {
-		if err := env.db.Drop(env.tenant, mv.CacheTableName); err != nil {
+	return cache, nil
+}
+
+func replaceMaterializedViewCache(env ExecEnv, cache *storage.Table) error {
+	if _, err := env.db.Get(env.tenant, cache.Name); err == nil {
+		if err := env.db.Drop(env.tenant, cache.Name); err != nil {
 			return err
 		}
 	}
 	return env.db.Put(env.tenant, cache)
 }
 
+// createEmptyMaterializedViewCache gives a WITH NO DATA view an empty cache
+// so it can be queried before its first REFRESH. The view still counts as
+// never refreshed, so a STALE AFTER policy fills it on first use.
+func createEmptyMaterializedViewCache(env ExecEnv, name string, mv *storage.CatalogMaterializedView) error {
+	cache, err := buildMaterializedViewCache(env, name, mv, true)
+	if err != nil {
+		return err
+	}
+	return replaceMaterializedViewCache(env, cache)
+}
+
 func executeAlterTable(env ExecEnv, s *AlterTable) (*ResultSet, error) {
-	// Get the table
 	t, err := env.db.Get(env.tenant, s.Table)
 	if err != nil {
 		return nil, err
 	}
-
-	// Handle ADD COLUMN
 	if s.AddColumn != nil {
-		// Check if column already exists
-		for _, col := range t.Cols {
-			if col.Name == s.AddColumn.Name {
-				return nil, fmt.Errorf("column %q already exists", s.AddColumn.Name)
-			}
+		return nil, alterTableAddColumn(env, t, *s.AddColumn)
+	}
+	if s.DropColumn != "" {
+		return nil, alterTableDropColumn(env, t, s.DropColumn)
+	}
+	return nil, nil
+}
+
+// alterTableAddColumn appends col to t, filling existing rows with the
+// column's default (or NULL). The default is evaluated once, so every
+// existing row receives the same value. Everything is validated before the
+// table is touched because DDL runs without a statement snapshot.
+func alterTableAddColumn(env ExecEnv, t *storage.Table, col storage.Column) error {
+	if _, err := t.ColIndex(col.Name); err == nil {
+		return fmt.Errorf("column %q already exists", col.Name)
+	}
+	if col.Constraint == storage.PrimaryKey {
+		return fmt.Errorf("cannot add PRIMARY KEY column %q", col.Name)
+	}
+	fill := make([]any, 1)
+	if err := applyColumnDefaults(env, fill, []storage.Column{col}); err != nil {
+		return err
+	}
+	if len(t.Rows) > 0 {
+		if col.NotNull && fill[0] == nil {
+			return fmt.Errorf("NOT NULL column %q cannot be added to a table with rows", col.Name)
+		}
+		if col.Constraint == storage.Unique && fill[0] != nil && len(t.Rows) > 1 {
+			return fmt.Errorf("UNIQUE column %q cannot be added with a non-NULL default to a table with rows", col.Name)
 		}
+	}
+	if err := t.AddColumn(col, fill[0]); err != nil {
+		return err
+	}
+	invalidateConstraintIndexes(t)
+	return nil
+}
 
-		// Add the new column to table schema
-		t.Cols = append(t.Cols, *s.AddColumn)
+// alterTableDropColumn removes a column that no other part of the schema
+// depends on. A CHECK on the column itself is dropped along with it.
+func alterTableDropColumn(env ExecEnv, t *storage.Table, name string) error {
+	idx, err := t.ColIndex(name)
+	if err != nil {
+		return err
+	}
+	col := t.Cols[idx]
+	if len(t.Cols) == 1 {
+		return fmt.Errorf("cannot drop column %q: it is the only column of table %q", col.Name, t.Name)
+	}
+	if col.Constraint == storage.PrimaryKey {
+		return fmt.Errorf("cannot drop PRIMARY KEY column %q", col.Name)
+	}
+	if col.Constraint == storage.ForeignKey {
+		return fmt.Errorf("cannot drop column %q: it is a FOREIGN KEY", col.Name)
+	}
+	if err := checkDropColumnDependents(env, t, col.Name); err != nil {
+		return err
+	}
+	if err := t.DropColumn(col.Name); err != nil {
+		return err
+	}
+	invalidateConstraintIndexes(t)
+	return nil
+}
 
-		// Add NULL values for existing rows
-		for i := range t.Rows {
-			t.Rows[i] = append(t.Rows[i], nil)
+func checkDropColumnDependents(env ExecEnv, t *storage.Table, name string) error {
+	lc := strings.ToLower(name)
+	refersTo := func(text string) (bool, error) {
+		expr, err := schemaExpr(text)
+		if err != nil {
+			return false, err
 		}
-		t.InvalidateStats()
-
-		// Update the table
-		if err := env.db.Put(env.tenant, t); err != nil {
-			return nil, fmt.Errorf("alter table: %w", err)
+		for ref := range collectVarRefNames(expr) {
+			if ref == lc || strings.HasSuffix(ref, "."+lc) {
+				return true, nil
+			}
 		}
+		return false, nil
 	}
-
-	return nil, nil
+	for _, c := range t.Cols {
+		if c.Check == "" || strings.EqualFold(c.Name, name) {
+			continue
+		}
+		if used, err := refersTo(c.Check); err != nil || used {
+			return fmt.Errorf("cannot drop column %q: it is used by CHECK (%s)", name, c.Check)
+		}
+	}
+	for _, c := range t.Checks {
+		if used, err := refersTo(c.Expr); err != nil || used {
+			return fmt.Errorf("cannot drop column %q: it is used by CHECK (%s)", name, c.Expr)
+		}
+	}
+	for _, u := range t.Uniques {
+		for _, c := range u.Columns {
+			if strings.EqualFold(c, name) {
+				return fmt.Errorf("cannot drop column %q: it is used by UNIQUE (%s)", name, strings.Join(u.Columns, ", "))
+			}
+		}
+	}
+	for _, ix := range t.Indexes {
+		for _, c := range ix.Columns {
+			if strings.EqualFold(c, name) {
+				return fmt.Errorf("cannot drop column %q: it is used by index %q", name, ix.Name)
+			}
+		}
+	}
+	for _, child := range env.db.ListTables(env.tenant) {
+		for _, c := range child.Cols {
+			if c.Constraint == storage.ForeignKey && c.ForeignKey != nil &&
+				strings.EqualFold(c.ForeignKey.Table, t.Name) && strings.EqualFold(c.ForeignKey.Column, name) {
+				return fmt.Errorf("cannot drop column %q: it is referenced by FOREIGN KEY %s.%s", name, child.Name, c.Name)
+			}
+		}
+	}
+	return nil
 }
 
 func executeInsert(env ExecEnv, s *Insert) (*ResultSet, error) {
 	if len(s.Rows) == 0 {
 		return nil, fmt.Errorf("INSERT requires at least one VALUES clause")
 	}
+	if err := checkPolicyInsert(env, s); err != nil {
+		return nil, err
+	}
 	t, err := env.db.Get(env.tenant, s.Table)
 	if err != nil {
 		return nil, err
@@ -576,6 +779,11 @@ func executeInsertAllColumns(env ExecEnv, s *Insert, t *storage.Table, tmp Row)
 	hasBefore := len(beforeTriggers) > 0
 	hasAfter := len(afterTriggers) > 0
 	needsRow := hasBefore || hasAfter || len(s.Returning) > 0
+	up, err := newUpsertPlan(env, s, t)
+	if err != nil {
+		return nil, err
+	}
+	firstAppended := len(t.Rows)
 	wal, err := beginWALAuto(env, s.Table)
 	if err != nil {
 		return nil, err
@@ -599,6 +807,14 @@ func executeInsertAllColumns(env ExecEnv, s *Insert, t *storage.Table, tmp Row)
 			}
 			row[i] = cv
 		}
+		if handled, upserted, err := up.resolve(env, t, wal, row); err != nil {
+			return nil, err
+		} else if handled {
+			if upserted != nil && len(s.Returning) > 0 {
+				returningRows = append(returningRows, upserted)
+			}
+			continue
+		}
 		if err := validateRowConstraints(env, t, row, -1); err != nil {
 			return nil, err
 		}
@@ -635,7 +851,7 @@ func executeInsertAllColumns(env ExecEnv, s *Insert, t *storage.Table, tmp Row)
 	}
 	t.Version++
 	t.InvalidateStats()
-	t.MarkDirtyFrom(len(t.Rows) - len(s.Rows))
+	up.markDirty(t, firstAppended)
 	markDependentMaterializedViewsStale(env, s.Table)
 	if len(s.Returning) > 0 {
 		return projectReturningRows(env, t.Cols, s.Returning, returningRows)
@@ -658,6 +874,11 @@ func executeInsertSpecificColumns(env ExecEnv, s *Insert, t *storage.Table, tmp
 	hasBefore := len(beforeTriggers) > 0
 	hasAfter := len(afterTriggers) > 0
 	needsRow := hasBefore || hasAfter || len(s.Returning) > 0
+	up, err := newUpsertPlan(env, s, t)
+	if err != nil {
+		return nil, err
+	}
+	firstAppended := len(t.Rows)
 	wal, err := beginWALAuto(env, s.Table)
 	if err != nil {
 		return nil, err
@@ -670,7 +891,7 @@ func executeInsertSpecificColumns(env ExecEnv, s *Insert, t *storage.Table, tmp
 			return nil, err
 		}
 		row := make([]any, len(t.Cols))
-		if err := applyColumnDefaults(row, t.Cols); err != nil {
+		if err := applyColumnDefaults(env, row, t.Cols); err != nil {
 			return nil, err
 		}
 		for i, idx := range colIdx {
@@ -684,6 +905,14 @@ func executeInsertSpecificColumns(env ExecEnv, s *Insert, t *storage.Table, tmp
 			}
 			row[idx] = cv
 		}
+		if handled, upserted, err := up.resolve(env, t, wal, row); err != nil {
+			return nil, err
+		} else if handled {
+			if upserted != nil && len(s.Returning) > 0 {
+				returningRows = append(returningRows, upserted)
+			}
+			continue
+		}
 		if err := validateRowConstraints(env, t, row, -1); err != nil {
 			return nil, err
 		}
@@ -720,7 +949,7 @@ func executeInsertSpecificColumns(env ExecEnv, s *Insert, t *storage.Table, tmp
 	}
 	t.Version++
 	t.InvalidateStats()
-	t.MarkDirtyFrom(len(t.Rows) - len(s.Rows))
+	up.markDirty(t, firstAppended)
 	markDependentMaterializedViewsStale(env, s.Table)
 	if len(s.Returning) > 0 {
 		return projectReturningRows(env, t.Cols, s.Returning, returningRows)
@@ -731,12 +960,22 @@ func executeInsertSpecificColumns(env ExecEnv, s *Insert, t *storage.Table, tmp
 // applyColumnDefaults initializes an INSERT row before explicitly named
 // columns overwrite their positions. Defaults are copied before coercion so a
 // BLOB default can never be shared and mutated through a stored row.
-func applyColumnDefaults(row []any, cols []storage.Column) error {
+// Expression defaults are evaluated once per row.
+func applyColumnDefaults(env ExecEnv, row []any, cols []storage.Column) error {
 	for i, col := range cols {
 		if !col.HasDefault {
 			continue
 		}
 		v := col.DefaultValue
+		if col.DefaultExpr != "" {
+			expr, err := schemaExpr(col.DefaultExpr)
+			if err != nil {
+				return fmt.Errorf("default for column %q: %w", col.Name, err)
+			}
+			if v, err = evalExpr(env, expr, Row{}); err != nil {
+				return fmt.Errorf("default for column %q: %w", col.Name, err)
+			}
+		}
 		if b, ok := v.([]byte); ok {
 			v = append([]byte(nil), b...)
 		}
@@ -796,6 +1035,43 @@ func validateRowConstraints(env ExecEnv, t *storage.Table, row []any, excludeRow
 			}
 		}
 	}
+	for _, u := range t.Uniques {
+		if err := checkUniqueConstraint(t, u, row, excludeRow); err != nil {
+			return err
+		}
+	}
+	return validateCheckConstraints(env, t, row)
+}
+
+// checkUniqueConstraint enforces a table-level UNIQUE (a, b, ...) constraint.
+// Rows sharing the first column's value come from that column's constraint
+// index and are compared on the remaining columns.
+func checkUniqueConstraint(t *storage.Table, u storage.UniqueConstraint, row []any, excludeRow int) error {
+	colIdxs := make([]int, len(u.Columns))
+	for i, name := range u.Columns {
+		ci, err := t.ColIndex(name)
+		if err != nil {
+			return err
+		}
+		if isNull(row[ci]) {
+			return nil
+		}
+		colIdxs[i] = ci
+	}
+	idx := getConstraintIndex(t, colIdxs[0])
+candidates:
+	for _, ri := range idx.rows[comparableKeyPart(row[colIdxs[0]])] {
+		if ri == excludeRow {
+			continue
+		}
+		existing := t.Rows[ri]
+		for _, ci := range colIdxs[1:] {
+			if ci >= len(existing) || existing[ci] == nil || comparableKeyPart(existing[ci]) != comparableKeyPart(row[ci]) {
+				continue candidates
+			}
+		}
+		return fmt.Errorf("duplicate UNIQUE value for columns (%s)", strings.Join(u.Columns, ", "))
+	}
 	return nil
 }
 
@@ -915,6 +1191,10 @@ func constraintValueExists(t *storage.Table, colIdx int, val any, excludeRow int
 }
 
 func executeUpdate(env ExecEnv, s *Update) (*ResultSet, error) {
+	s, err := policyUpdate(env, foldUpdatePredicate(s))
+	if err != nil {
+		return nil, err
+	}
 	if !tenantHasAnyForeignKeys(env) {
 		if rs, ok, err := executeSimpleUpdateFastPath(env, s); ok || err != nil {
 			return rs, err
@@ -1149,6 +1429,10 @@ func buildSimpleUpdatePlan(env ExecEnv, s *Update) (*simpleUpdatePlan, bool, err
 }
 
 func executeDelete(env ExecEnv, s *Delete) (*ResultSet, error) {
+	s, err := policyDelete(env, foldDeletePredicate(s))
+	if err != nil {
+		return nil, err
+	}
 	t, err := env.db.Get(env.tenant, s.Table)
 	if err != nil {
 		return nil, err
@@ -1703,6 +1987,10 @@ func resolveCatalogJobs(env ExecEnv, s *Select) ([]Row, error) {
 		putVal(leftRows[i], "catch_up", j.CatchUp)
 		putVal(leftRows[i], "no_overlap", j.NoOverlap)
 		putVal(leftRows[i], "max_runtime_ms", j.MaxRuntimeMs)
+		putVal(leftRows[i], "max_retries", j.MaxRetries)
+		putVal(leftRows[i], "retry_backoff_ms", j.RetryBackoffMs)
+		putVal(leftRows[i], "retry_count", j.RetryCount)
+		putVal(leftRows[i], "last_error", j.LastError)
 		putVal(leftRows[i], "last_run_at", j.LastRunAt)
 		putVal(leftRows[i], "next_run_at", j.NextRunAt)
 		putVal(leftRows[i], "created_at", j.CreatedAt)
@@ -1809,7 +2097,7 @@ func resolveRegularTable(cteEnv ExecEnv, env ExecEnv, s *Select) ([]Row, error)
 	}
 	if err == nil {
 		leftRows, _ := rowsFromTable(leftT, aliasOr(s.From))
-		return leftRows, nil
+		return applyRowPolicies(env, s.From.Table, leftRows)
 	}
 
 	if rows, found, viewErr := resolveMaterializedViewSource(env, s); found || viewErr != nil {
@@ -1977,6 +2265,7 @@ func applySortOrder(orderBy []OrderItem, outRows []Row) []Row {
 type orderedValueRow struct {
 	row  Row
 	keys []any
+	idx  int // source row position, used by the lazy projection path
 }
 
 type orderedValueRowHeap struct {
@@ -2081,41 +2370,58 @@ func applySortOrderWithLimit(orderBy []OrderItem, outRows []Row, limit, offset *
 		return applySortOrder(orderBy, outRows)
 	}
 
+	lcOrdCols := lowerOrderCols(orderBy)
+	items := topOrderedValueRows(orderBy, len(outRows), limit, offset, func(i int) orderedValueRow {
+		return buildOrderByValues(outRows[i], lcOrdCols)
+	})
+	sorted := make([]Row, len(items))
+	for i, item := range items {
+		sorted[i] = item.row
+	}
+	return sorted
+}
+
+func lowerOrderCols(orderBy []OrderItem) []string {
 	lcOrdCols := make([]string, len(orderBy))
 	for idx, oi := range orderBy {
 		lcOrdCols[idx] = strings.ToLower(oi.Col)
 	}
+	return lcOrdCols
+}
 
-	keepCount := len(outRows)
+// topOrderedValueRows sorts the n items produced by item and keeps only the
+// first limit+offset of them, using a bounded heap when LIMIT is smaller
+// than the input so the full set never has to be sorted.
+func topOrderedValueRows(orderBy []OrderItem, n int, limit, offset *int, item func(i int) orderedValueRow) []orderedValueRow {
+	keepCount := n
 	if limit != nil {
 		keepCount = *limit
 		if offset != nil {
 			keepCount += *offset
 		}
-		if keepCount > len(outRows) {
-			keepCount = len(outRows)
+		if keepCount > n {
+			keepCount = n
 		}
 	}
 	if keepCount <= 0 {
-		return []Row{}
+		return nil
 	}
 
-	items := make([]orderedValueRow, 0, min(cap(outRows), keepCount))
+	items := make([]orderedValueRow, 0, keepCount)
 	var topRows orderedValueRowHeap
-	useTopN := limit != nil && keepCount > 0 && keepCount < len(outRows)
+	useTopN := limit != nil && keepCount < n
 	if useTopN {
 		topRows = orderedValueRowHeap{
 			orderBy: orderBy,
-			items:   make([]orderedValueRow, 0, min(cap(outRows), keepCount)),
+			items:   make([]orderedValueRow, 0, keepCount),
 		}
 	}
 
-	for _, row := range outRows {
-		item := buildOrderByValues(row, lcOrdCols)
+	for i := 0; i < n; i++ {
 		if useTopN {
-			topRows.pushBounded(item, keepCount)
+			topRows.pushBounded(item(i), keepCount)
 		} else {
-			items = append(items, item)
+			items = append(items, item(i))
 		}
 	}
 	if useTopN {
@@ -2125,15 +2431,15 @@ func applySortOrderWithLimit(orderBy []OrderItem, outRows []Row, limit, offset *
 	sort.SliceStable(items, func(i, j int) bool {
 		return compareOrderedValueRows(orderBy, items[i], items[j]) < 0
 	})
-
-	sorted := make([]Row, len(items))
-	for i, item := range items {
-		sorted[i] = item.row
-	}
-	return sorted
+	return items
 }
 
 func executeSelect(env ExecEnv, s *Select) (*ResultSet, error) {
+	// Subqueries run inside the step that evaluates them and are not
+	// profiled on their own.
+	prof := env.profile
+	env.profile = nil
+	s = foldSelectPredicates(s)
 	cteEnv, err := processCTEs(env, s)
 	if err != nil {
 		return nil, err
@@ -2141,85 +2447,240 @@ func executeSelect(env ExecEnv, s *Select) (*ResultSet, error) {
 	// Fast paths access physical storage directly. A CTE source exists only in
 	// the execution environment, so bypass them whenever FROM/JOIN references
 	// an active CTE; otherwise recursive and chained CTEs are treated as
-	// missing physical tables.
-	if !selectReferencesCTE(cteEnv, s) {
+	// missing physical tables. They also resolve columns at plan time, so a
+	// correlated subquery (env.outerRow set) takes the general path, and so
+	// does every query of a tenant with row-level security policies.
+	if !selectReferencesCTE(cteEnv, s) && env.outerRow == nil && !env.db.HasPolicies(env.tenant) {
+		started := time.Now()
 		if rs, ok, err := executeSimpleJoinFastPath(cteEnv, s); ok || err != nil {
-			return rs, err
+			prof.recordFastPath(cteEnv, "HASH JOIN", s, rs, started)
+			return fastPathResult(cteEnv, rs, err)
 		}
 		if rs, ok, err := executeSimpleAggregateFastPath(cteEnv, s); ok || err != nil {
-			return rs, err
+			prof.recordFastPath(cteEnv, "AGGREGATE", s, rs, started)
+			return fastPathResult(cteEnv, rs, err)
 		}
 		if rs, ok, err := executeSimpleSelectFastPath(cteEnv, s); ok || err != nil {
-			return rs, err
+			prof.recordFastPath(cteEnv, "SCAN", s, rs, started)
+			return fastPathResult(cteEnv, rs, err)
 		}
 	}
 
 	// FROM (Tabelle, CTE oder Subselect) - now optional
+	started := time.Now()
 	leftRows, err := resolveFromClause(cteEnv, cteEnv, s)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkRowSetMemory(cteEnv, "scan of "+fromObject(s.From), leftRows); err != nil {
+		return nil, err
+	}
 
-	cur := leftRows
+	// JOINs, with single-table WHERE terms applied to their source first
+	fromFilter, joinFilters, residual := planPredicatePushdown(cteEnv, s)
+	cur, err := applyWhereClause(cteEnv, fromFilter, leftRows)
+	if err != nil {
+		return nil, err
+	}
+	if prof != nil && (s.From.Table != "" || s.From.Subquery != nil || s.From.TableFunc != nil) {
+		details := ""
+		if fromFilter != nil {
+			details = "pushed filter " + exprKind(fromFilter)
+		}
+		prof.record("SCAN", fromObject(s.From), tableRowEstimate(cteEnv, s.From), len(cur), started, details)
+	}
+	joins, joinFilters, _ := planJoinOrder(cteEnv, s, fromFilter, joinFilters)
+	cur, err = processJoins(cteEnv, s.From, joins, cur, joinFilters, prof)
+	if err != nil {
+		return nil, err
+	}
 
-	// JOINs
-	cur, err = processJoins(cteEnv, s.Joins, cur)
+	// WHERE (correlated EXISTS predicates become hash semi-joins)
+	started = time.Now()
+	where, err := rewriteExistsSemiJoins(cteEnv, residual)
+	if err != nil {
+		return nil, err
+	}
+	filtered, err := applyWhereClause(cteEnv, where, cur)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkRowSetMemory(cteEnv, "filter", filtered); err != nil {
+		return nil, err
+	}
+	if where != nil {
+		prof.record("FILTER", "-", len(cur), len(filtered), started, exprKind(where))
+	}
 
-	// WHERE
-	filtered, err := applyWhereClause(cteEnv, s.Where, cur)
+	// Projection, GROUP/HAVING, DISTINCT, ORDER BY and OFFSET/LIMIT
+	// (applied before UNION to each individual SELECT)
+	var baseRows []Row
+	var outCols []string
+	if lazyProjectionEligible(s) {
+		started = time.Now()
+		baseRows, outCols, err = processLazyProjection(cteEnv, s, filtered)
+		if err == nil {
+			op := "LIMIT"
+			if len(s.OrderBy) > 0 {
+				op = "SORT"
+			}
+			prof.record(op, "-", len(filtered), len(baseRows), started, "top-N before projection")
+		}
+	} else {
+		baseRows, outCols, err = projectSortAndLimit(cteEnv, s, filtered, prof)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	// GROUP/HAVING
-	outRows, outCols, err := processGroupByHaving(cteEnv, s, filtered)
+	// Handle UNION operations
+	resultRows := baseRows
+	resultCols := outCols
+
+	if s.Union != nil {
+		var err error
+		started = time.Now()
+		resultRows, resultCols, err = processUnionClauses(cteEnv, s.Union, resultRows, resultCols)
+		if err != nil {
+			return nil, err
+		}
+		prof.record(s.Union.Type.String(), "-", len(baseRows), len(resultRows), started, "")
+	}
+	if err := checkRowSetMemory(cteEnv, "projection", resultRows); err != nil {
+		return nil, err
+	}
+
+	if len(resultCols) == 0 {
+		resultCols = columnsFromRows(resultRows)
+	}
+	return &ResultSet{Cols: resultCols, Rows: resultRows}, nil
+}
+
+// fastPathResult applies the memory limit to a result produced by one of the
+// raw fast paths, which build no intermediate row sets of their own.
+func fastPathResult(env ExecEnv, rs *ResultSet, err error) (*ResultSet, error) {
+	if err == nil && rs != nil {
+		err = checkRowSetMemory(env, "result", rs.Rows)
+	}
 	if err != nil {
 		return nil, err
 	}
+	return rs, nil
+}
+
+// projectSortAndLimit evaluates every projection for every filtered row, then
+// applies DISTINCT, ORDER BY and OFFSET/LIMIT to the projected rows.
+func projectSortAndLimit(env ExecEnv, s *Select, filtered []Row, prof *queryProfile) ([]Row, []string, error) {
+	started := time.Now()
+	outRows, outCols, err := processGroupByHaving(env, s, filtered)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(s.GroupBy) > 0 || anyAggInSelect(s.Projs) || isAggregate(s.Having) {
+		prof.record("AGGREGATE", "-", len(filtered), len(outRows), started, fmt.Sprintf("%d group expression(s)", len(s.GroupBy)))
+	} else {
+		prof.record("PROJECT", "-", len(filtered), len(outRows), started, fmt.Sprintf("%d column(s)", len(outCols)))
+	}
 
-	// DISTINCT
+	started = time.Now()
+	distinctIn := len(outRows)
 	if s.Distinct {
 		// If DISTINCT ON (...) was used, apply DISTINCT ON semantics: keep first
 		// row per distinct-on key. The ORDER BY clause controls which row is
 		// considered "first"; so apply ORDER BY first if present.
 		if len(s.DistinctOn) > 0 {
-			var err error
-			outRows, err = applyDistinctOn(cteEnv, s, outRows)
+			outRows, err = applyDistinctOn(env, s, outRows)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 		} else {
 			outRows = distinctRows(outRows, outCols)
 		}
+		prof.record("DISTINCT", "-", distinctIn, len(outRows), started, "")
 	}
 
-	// ORDER BY
+	started = time.Now()
+	sortIn := len(outRows)
 	if len(s.OrderBy) > 0 {
 		outRows = applySortOrderWithLimit(s.OrderBy, outRows, s.Limit, s.Offset)
+		prof.record("SORT", "-", sortIn, len(outRows), started, fmt.Sprintf("%d key(s)", len(s.OrderBy)))
+	}
+	started = time.Now()
+	limitIn := len(outRows)
+	outRows = applyOffsetLimit(s, outRows)
+	if s.Limit != nil || s.Offset != nil {
+		prof.record("LIMIT", "-", limitIn, len(outRows), started, "")
 	}
+	return outRows, outCols, nil
+}
 
-	// OFFSET/LIMIT (applied before UNION to each individual SELECT)
-	baseRows := applyOffsetLimit(s, outRows)
+// lazyProjectionEligible reports whether a SELECT can defer evaluating its
+// output columns until after ORDER BY and LIMIT have picked the final rows.
+// Aggregates, DISTINCT and window functions need every projected row, so
+// they keep the eager path; without a LIMIT every row is delivered anyway.
+func lazyProjectionEligible(s *Select) bool {
+	if s.Limit == nil || s.Distinct || s.Pivot != nil || s.Having != nil || len(s.GroupBy) > 0 {
+		return false
+	}
+	return !anyAggInSelect(s.Projs) && !anyWindowInSelect(s.Projs)
+}
 
-	// Handle UNION operations
-	resultRows := baseRows
-	resultCols := outCols
+// processLazyProjection is the two-phase form of processNonAggregateQuery.
+// Phase one evaluates only the projections that ORDER BY refers to and
+// selects the rows surviving ORDER BY/OFFSET/LIMIT; phase two evaluates the
+// full projection list for those rows alone.
+func processLazyProjection(env ExecEnv, s *Select, filtered []Row) ([]Row, []string, error) {
+	selected, err := selectLazyRows(env, s, filtered)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(selected) == 0 && len(filtered) > 0 {
+		// Still report the output columns of an empty page (e.g. LIMIT 0).
+		_, outCols, err := processNonAggregateQuery(env, s, filtered[:1])
+		return []Row{}, outCols, err
+	}
+	return processNonAggregateQuery(env, s, selected)
+}
 
-	if s.Union != nil {
-		var err error
-		resultRows, resultCols, err = processUnionClauses(cteEnv, s.Union, resultRows, resultCols)
-		if err != nil {
-			return nil, err
+func selectLazyRows(env ExecEnv, s *Select, filtered []Row) ([]Row, error) {
+	if len(s.OrderBy) == 0 {
+		return applyOffsetLimit(s, filtered), nil
+	}
+	if *s.Limit <= 0 {
+		return nil, nil
+	}
+
+	lcOrdCols := lowerOrderCols(s.OrderBy)
+	needed := make(map[string]bool, len(lcOrdCols))
+	for _, c := range lcOrdCols {
+		needed[c] = true
+	}
+	keyed := &Select{Projs: make([]SelectItem, 0, len(lcOrdCols))}
+	for i, it := range s.Projs {
+		if it.Star {
+			keyed.Projs = append(keyed.Projs, it)
+			continue
+		}
+		name := projName(it, i)
+		if needed[strings.ToLower(name)] {
+			keyed.Projs = append(keyed.Projs, SelectItem{Expr: it.Expr, Alias: name})
 		}
 	}
 
-	if len(resultCols) == 0 {
-		resultCols = columnsFromRows(resultRows)
+	keyRows, _, err := processNonAggregateQuery(env, keyed, filtered)
+	if err != nil {
+		return nil, err
 	}
-	return &ResultSet{Cols: resultCols, Rows: resultRows}, nil
+	items := topOrderedValueRows(s.OrderBy, len(keyRows), s.Limit, s.Offset, func(i int) orderedValueRow {
+		item := buildOrderByValues(keyRows[i], lcOrdCols)
+		item.idx = i
+		return item
+	})
+	sorted := make([]Row, len(items))
+	for i, item := range items {
+		sorted[i] = filtered[item.idx]
+	}
+	return applyOffsetLimit(&Select{Offset: s.Offset}, sorted), nil
 }
 
 // selectReferencesCTE reports whether a SELECT needs rows bound in the active
@@ -2345,7 +2806,7 @@ func executeSimpleJoinFastPath(env ExecEnv, s *Select) (*ResultSet, bool, error)
 				continue
 			}
 		}
-		key := comparableKeyPart(right[plan.rightKey])
+		key := joinKeyPart(right[plan.rightKey])
 		rightByKey[key] = append(rightByKey[key], right)
 	}
 
@@ -2366,7 +2827,7 @@ func executeSimpleJoinFastPath(env ExecEnv, s *Select) (*ResultSet, bool, error)
 				continue
 			}
 		}
-		matches := rightByKey[comparableKeyPart(left[plan.leftKey])]
+		matches := rightByKey[joinKeyPart(left[plan.leftKey])]
 		for _, right := range matches {
 			match, err := evalJoinRawWhere(plan, left, right)
 			if err != nil {
@@ -2939,9 +3400,7 @@ func evalJoinRawRegexp(plan *simpleJoinPlan, left, right []any, ex *RegexpExpr)
 	}
 	str := fmt.Sprintf("%v", val)
 	pat := fmt.Sprintf("%v", patVal)
-	if ex.SimilarTo {
-		pat = similarToRegexp(pat)
-	}
+	pat = regexpPattern(ex, pat)
 	re, err := compileCachedRegexp(pat)
 	if err != nil {
 		return nil, fmt.Errorf("REGEXP: invalid pattern %q: %v", pat, err)
@@ -2954,15 +3413,26 @@ func evalJoinRawRegexp(plan *simpleJoinPlan, left, right []any, ex *RegexpExpr)
 }
 
 func evalJoinRawIn(plan *simpleJoinPlan, left, right []any, ex *InExpr) (any, error) {
+	if len(ex.Values) == 0 {
+		return ex.Negate, nil
+	}
 	val, err := evalJoinRawExpr(plan, left, right, ex.Expr)
 	if err != nil {
 		return nil, err
 	}
+	if val == nil {
+		return nil, nil
+	}
+	sawNull := false
 	for _, valExpr := range ex.Values {
 		listVal, err := evalJoinRawExpr(plan, left, right, valExpr)
 		if err != nil {
 			return nil, err
 		}
+		if listVal == nil {
+			sawNull = true
+			continue
+		}
 		if rawEqual(val, listVal) {
 			if ex.Negate {
 				return false, nil
@@ -2970,10 +3440,7 @@ func evalJoinRawIn(plan *simpleJoinPlan, left, right []any, ex *InExpr) (any, er
 			return true, nil
 		}
 	}
-	if ex.Negate {
-		return true, nil
-	}
-	return false, nil
+	return inListMiss(ex.Negate, sawNull), nil
 }
 
 func evalJoinRawBinary(plan *simpleJoinPlan, left, right []any, ex *Binary) (any, error) {
@@ -4959,6 +5426,10 @@ func isSimpleRawExpr(e Expr) bool {
 			// always return "". Must go through the general evaluator.
 			return false
 		}
+		if catalogFuncNames[ex.Name] {
+			// evalRawFuncCall runs handlers with an empty ExecEnv.
+			return false
+		}
 		for _, arg := range ex.Args {
 			if !isSimpleRawExpr(arg) {
 				return false
@@ -4973,9 +5444,18 @@ func isSimpleRawExpr(e Expr) bool {
 // rowAwareFuncNames lists scalar functions that read the ambient Row map
 // directly rather than only their evaluated arguments. ROW_TO_TEXT is the
 // one exception: evalRawRowToText supplies it with precomputed raw indexes,
-// so it can safely stay on the raw execution path.
+// so it can safely stay on the raw execution path. MATCH ... AGAINST needs
+// its unevaluated column references to find the FULLTEXT table.
 var rowAwareFuncNames = map[string]bool{
-	"ROW_TO_TEXT": true,
+	"ROW_TO_TEXT":    true,
+	matchAgainstFunc: true,
+}
+
+// catalogFuncNames lists scalar functions that read or change the catalog
+// through ExecEnv, which the raw fast path does not carry.
+var catalogFuncNames = map[string]bool{
+	"NEXTVAL": true,
+	"CURRVAL": true,
 }
 
 // exprHasRowAwareFuncCall reports whether e, or any sub-expression reachable
@@ -5224,16 +5704,17 @@ func buildRawAndFilter(colIndex map[string]int, leftExpr, rightExpr Expr) func([
 	// Exactly one side compiled. The specialized side is cheap (raw slice
 	// access + comparison), so it runs first regardless of written order;
 	// the expression side runs only on surviving rows. The fallback
-	// evaluates via evalRawExpr(plan, raw, expr). Unsupported row-aware
-	// functions still force the general evaluator; ROW_TO_TEXT has a raw
-	// implementation with precomputed column indexes and is safe here.
+	// evaluates via evalRawExpr(plan, raw, expr), so it must be raw-evaluable
+	// (an EXISTS subquery is not). Unsupported row-aware functions still force
+	// the general evaluator; ROW_TO_TEXT has a raw implementation with
+	// precomputed column indexes and is safe here.
 	if left == nil {
-		if exprHasRowAwareFuncCall(leftExpr) {
+		if !isSimpleRawExpr(leftExpr) || exprHasRowAwareFuncCall(leftExpr) {
 			return nil
 		}
 		return buildRawAndFilterWithFallback(colIndex, leftExpr, right)
 	}
-	if exprHasRowAwareFuncCall(rightExpr) {
+	if !isSimpleRawExpr(rightExpr) || exprHasRowAwareFuncCall(rightExpr) {
 		return nil
 	}
 	return buildRawAndFilterWithFallback(colIndex, rightExpr, left)
@@ -5292,12 +5773,12 @@ func buildRawOrFilter(colIndex map[string]int, leftExpr, rightExpr Expr) func([]
 	// side short-circuits first. Unsupported row-aware functions still use the
 	// general evaluator, while ROW_TO_TEXT is safe on the raw path.
 	if left == nil {
-		if exprHasRowAwareFuncCall(leftExpr) {
+		if !isSimpleRawExpr(leftExpr) || exprHasRowAwareFuncCall(leftExpr) {
 			return nil
 		}
 		return buildRawOrFilterWithFallback(colIndex, leftExpr, right)
 	}
-	if exprHasRowAwareFuncCall(rightExpr) {
+	if !isSimpleRawExpr(rightExpr) || exprHasRowAwareFuncCall(rightExpr) {
 		return nil
 	}
 	return buildRawOrFilterWithFallback(colIndex, rightExpr, left)
@@ -5432,9 +5913,7 @@ func buildRawFilterRegexp(colIndex map[string]int, ex *RegexpExpr) func([]any) (
 	if !isStr {
 		return nil
 	}
-	if ex.SimilarTo {
-		pattern = similarToRegexp(pattern)
-	}
+	pattern = regexpPattern(ex, pattern)
 	re, err := regexp.Compile(pattern)
 	if err != nil {
 		return nil
@@ -5476,6 +5955,16 @@ func buildRawFilterIn(colIndex map[string]int, ex *InExpr) func([]any) (bool, er
 	return buildInFilter(colIdx, litVals, ex.Negate)
 }
 
+// rawInList reports whether a equals any of vals under rawEqual.
+func rawInList(a any, vals []any) bool {
+	for _, v := range vals {
+		if rawEqual(a, v) {
+			return true
+		}
+	}
+	return false
+}
+
 // reverseComparisonOp reverses the direction of a comparison operator,
 // so that "literal op col" can be treated as "col reversed_op literal".
 func reverseComparisonOp(op string) string {
@@ -5716,31 +6205,54 @@ func buildStringCmpFilter(colIdx int, op string, lit string) func([]any) (bool,
 			if s, ok := raw[colIdx].(string); ok {
 				return s < lit, nil
 			}
-			return false, nil
+			return timeCmpStringLiteral(raw[colIdx], op, lit)
 		}
 	case "<=":
 		return func(raw []any) (bool, error) {
 			if s, ok := raw[colIdx].(string); ok {
 				return s <= lit, nil
 			}
-			return false, nil
+			return timeCmpStringLiteral(raw[colIdx], op, lit)
 		}
 	case ">":
 		return func(raw []any) (bool, error) {
 			if s, ok := raw[colIdx].(string); ok {
 				return s > lit, nil
 			}
-			return false, nil
+			return timeCmpStringLiteral(raw[colIdx], op, lit)
 		}
 	case ">=":
 		return func(raw []any) (bool, error) {
 			if s, ok := raw[colIdx].(string); ok {
 				return s >= lit, nil
 			}
-			return false, nil
+			return timeCmpStringLiteral(raw[colIdx], op, lit)
 		}
 	}
-	return nil
+	return nil
+}
+
+// timeCmpStringLiteral orders a DATE/DATETIME column value against a string
+// literal. Other non-string values never satisfy a string comparison.
+func timeCmpStringLiteral(v any, op string, lit string) (bool, error) {
+	t, ok := v.(time.Time)
+	if !ok {
+		return false, nil
+	}
+	c, err := compareTime(t, lit)
+	if err != nil {
+		return false, err
+	}
+	switch op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	default: // ">="
+		return c >= 0, nil
+	}
 }
 
 // buildColColFilter builds a filter for "raw[lIdx] op raw[rIdx]" (col op col).
@@ -5921,9 +6433,16 @@ func buildCompiledGlobFilter(colIdx int, pattern string, caseInsensitive, negate
 // buildInFilter builds a fast set-membership closure for col IN (litVals).
 // It pre-builds typed maps for all-int and all-string value sets for O(1) lookup.
 func buildInFilter(colIdx int, litVals []any, negate bool) func([]any) (bool, error) {
+	if len(litVals) == 0 {
+		return func([]any) (bool, error) { return negate, nil }
+	}
 	// Try to build typed sets for O(1) lookup.
 	allInt, allStr := true, true
 	for _, v := range litVals {
+		if v == nil && negate {
+			// x NOT IN (..., NULL) is never true, so no row passes.
+			return func([]any) (bool, error) { return false, nil }
+		}
 		if _, ok := v.(int); !ok {
 			allInt = false
 		}
@@ -5983,6 +6502,10 @@ func buildInFilter(colIdx int, litVals []any, negate bool) func([]any) (bool, er
 			return func(raw []any) (bool, error) {
 				s, ok := raw[colIdx].(string)
 				if !ok {
+					// DATE/DATETIME values match string literals by time.
+					if t, isTime := raw[colIdx].(time.Time); isTime {
+						return !rawInList(t, litVals), nil
+					}
 					return false, nil
 				}
 				_, found := set[s]
@@ -5992,6 +6515,9 @@ func buildInFilter(colIdx int, litVals []any, negate bool) func([]any) (bool, er
 		return func(raw []any) (bool, error) {
 			s, ok := raw[colIdx].(string)
 			if !ok {
+				if t, isTime := raw[colIdx].(time.Time); isTime {
+					return rawInList(t, litVals), nil
+				}
 				return false, nil
 			}
 			_, found := set[s]
@@ -6003,6 +6529,9 @@ func buildInFilter(colIdx int, litVals []any, negate bool) func([]any) (bool, er
 	if negate {
 		return func(raw []any) (bool, error) {
 			a := raw[colIdx]
+			if a == nil {
+				return false, nil
+			}
 			for _, v := range litVals {
 				if rawEqual(a, v) {
 					return false, nil
@@ -6013,6 +6542,9 @@ func buildInFilter(colIdx int, litVals []any, negate bool) func([]any) (bool, er
 	}
 	return func(raw []any) (bool, error) {
 		a := raw[colIdx]
+		if a == nil {
+			return false, nil
+		}
 		for _, v := range litVals {
 			if rawEqual(a, v) {
 				return true, nil
@@ -6061,13 +6593,20 @@ func rawEqual(a, b any) bool {
 			return av == bv
 		}
 	case string:
-		if bv, ok := b.(string); ok {
+		switch bv := b.(type) {
+		case string:
 			return av == bv
+		case time.Time:
+			c, err := compareTime(bv, av)
+			return err == nil && c == 0
 		}
 	case bool:
 		if bv, ok := b.(bool); ok {
 			return av == bv
 		}
+	case time.Time:
+		c, err := compareTime(av, b)
+		return err == nil && c == 0
 	}
 	return false
 }
@@ -6379,9 +6918,7 @@ func evalRawRegexp(plan *simpleSelectPlan, raw []any, ex *RegexpExpr) (any, erro
 	}
 	str := fmt.Sprintf("%v", val)
 	pattern := fmt.Sprintf("%v", patVal)
-	if ex.SimilarTo {
-		pattern = similarToRegexp(pattern)
-	}
+	pattern = regexpPattern(ex, pattern)
 	re, err := compileCachedRegexp(pattern)
 	if err != nil {
 		return nil, fmt.Errorf("REGEXP: invalid pattern %q: %v", pattern, err)
@@ -6395,6 +6932,9 @@ func evalRawRegexp(plan *simpleSelectPlan, raw []any, ex *RegexpExpr) (any, erro
 
 // evalRawIn evaluates an IN / NOT IN expression in the raw fast path.
 func evalRawIn(plan *simpleSelectPlan, raw []any, ex *InExpr) (any, error) {
+	if len(ex.Values) == 0 {
+		return ex.Negate, nil
+	}
 	val, err := evalRawExpr(plan, raw, ex.Expr)
 	if err != nil {
 		return nil, err
@@ -6407,11 +6947,16 @@ func evalRawIn(plan *simpleSelectPlan, raw []any, ex *InExpr) (any, error) {
 		// SQL equality, where NULL never equals NULL).
 		return nil, nil
 	}
+	sawNull := false
 	for _, valExpr := range ex.Values {
 		listVal, err := evalRawExpr(plan, raw, valExpr)
 		if err != nil {
 			return nil, err
 		}
+		if listVal == nil {
+			sawNull = true
+			continue
+		}
 		if rawEqual(val, listVal) {
 			if ex.Negate {
 				return false, nil
@@ -6419,10 +6964,7 @@ func evalRawIn(plan *simpleSelectPlan, raw []any, ex *InExpr) (any, error) {
 			return true, nil
 		}
 	}
-	if ex.Negate {
-		return true, nil
-	}
-	return false, nil
+	return inListMiss(ex.Negate, sawNull), nil
 }
 
 func processUnionClauses(env ExecEnv, union *UnionClause, leftRows []Row, leftCols []string) ([]Row, []string, error) {
@@ -6439,9 +6981,10 @@ func processUnionClauses(env ExecEnv, union *UnionClause, leftRows []Row, leftCo
 
 		// Validate column compatibility
 		if len(rightResult.Cols) != len(resultCols) {
-			return nil, nil, fmt.Errorf("UNION: column count mismatch between queries (%d vs %d)",
-				len(resultCols), len(rightResult.Cols))
+			return nil, nil, fmt.Errorf("%s: column count mismatch between queries (%d vs %d)",
+				current.Type, len(resultCols), len(rightResult.Cols))
 		}
+		rightResult.Rows = alignSetOperandRows(rightResult.Rows, rightResult.Cols, resultCols)
 
 		// Process the union based on type
 		switch current.Type {
@@ -6455,8 +6998,9 @@ func processUnionClauses(env ExecEnv, union *UnionClause, leftRows []Row, leftCo
 			resultRows = distinctRows(resultRows, resultCols)
 
 		case Except:
-			// EXCEPT: Remove rows that exist in the right result
-			resultRows = exceptRows(resultRows, rightResult.Rows, resultCols)
+			// EXCEPT: Remove rows that exist in the right result. Like
+			// UNION it is a set operation, so the result is distinct.
+			resultRows = distinctRows(exceptRows(resultRows, rightResult.Rows, resultCols), resultCols)
 
 		case Intersect:
 			// INTERSECT: Keep only rows that exist in both results
@@ -6469,6 +7013,33 @@ func processUnionClauses(env ExecEnv, union *UnionClause, leftRows []Row, leftCo
 	return resultRows, resultCols, nil
 }
 
+// alignSetOperandRows re-keys the rows of a set-operation operand by
+// position, so the right-hand SELECT's i-th column lands under the i-th
+// column name of the left-hand SELECT. Rows are returned unchanged when the
+// names already agree.
+func alignSetOperandRows(rows []Row, from, to []string) []Row {
+	same := true
+	for i := range from {
+		if !strings.EqualFold(from[i], to[i]) {
+			same = false
+			break
+		}
+	}
+	if same {
+		return rows
+	}
+	out := make([]Row, len(rows))
+	for i, r := range rows {
+		nr := make(Row, len(to))
+		for j, c := range to {
+			v, _ := getVal(r, from[j])
+			putVal(nr, c, v)
+		}
+		out[i] = nr
+	}
+	return out
+}
+
 func exceptRows(leftRows, rightRows []Row, cols []string) []Row {
 	// Create a set of right rows for fast lookup
 	rightSet := make(map[string]bool)
@@ -6521,11 +7092,22 @@ func rowSignature(row Row, cols []string) string {
 	return string(buf)
 }
 
-func processJoins(env ExecEnv, joins []JoinClause, cur []Row) ([]Row, error) {
-	for _, j := range joins {
+// processJoins joins cur with each JOIN source in turn. filters, when
+// non-nil, holds a pushed-down WHERE term per join (see
+// planPredicatePushdown) that is applied to that source's rows first.
+func processJoins(env ExecEnv, from FromItem, joins []JoinClause, cur []Row, filters []Expr, prof *queryProfile) ([]Row, error) {
+	// Outer joins NULL-fill the left side using the keys of its first row.
+	// While the left side is empty those keys come from the schemas of the
+	// FROM table and every table joined so far instead.
+	var emptyLeftKeys []string
+	if len(cur) == 0 {
+		emptyLeftKeys = fromItemKeys(env, from)
+	}
+	for i, j := range joins {
 		var rightRows []Row
 		var rightTable *storage.Table
 		var err error
+		started := time.Now()
 
 		if j.Right.Subquery != nil {
 			subRs, err := executeSelect(env, j.Right.Subquery)
@@ -6586,18 +7168,36 @@ func processJoins(env ExecEnv, joins []JoinClause, cur []Row) ([]Row, error) {
 				return nil, err
 			}
 			rightRows, _ = rowsFromTable(rt, aliasOr(j.Right))
+			if rightRows, err = applyRowPolicies(env, j.Right.Table, rightRows); err != nil {
+				return nil, err
+			}
 			rightTable = rt
 		}
 
+		if i < len(filters) && filters[i] != nil {
+			if rightRows, err = applyWhereClause(env, filters[i], rightRows); err != nil {
+				return nil, err
+			}
+		}
+
+		leftKeys := emptyLeftKeys
+		if len(cur) > 0 {
+			leftKeys = keysOfRow(cur[0])
+		}
+		method := ""
+		if prof != nil {
+			method = joinMethod(j, cur, rightRows)
+		}
+		leftCount := len(cur)
 		switch j.Type {
 		case JoinInner:
 			cur, err = processInnerJoin(env, cur, rightRows, j.On)
 		case JoinLeft:
 			cur, err = processLeftJoin(env, cur, rightRows, j.On, aliasOr(j.Right), rightTable)
 		case JoinRight:
-			cur, err = processRightJoin(env, cur, rightRows, j.On)
+			cur, err = processRightJoin(env, cur, rightRows, j.On, leftKeys)
 		case JoinFull:
-			cur, err = processFullOuterJoin(env, cur, rightRows, j.On, aliasOr(j.Right), rightTable)
+			cur, err = processFullOuterJoin(env, cur, rightRows, j.On, leftKeys, aliasOr(j.Right), rightTable)
 		case JoinCross:
 			// CROSS JOIN has no ON condition by construction, so (like the
 			// onCondition == nil case in processInnerJoin) its output size is
@@ -6611,10 +7211,48 @@ func processJoins(env ExecEnv, joins []JoinClause, cur []Row) ([]Row, error) {
 		if err != nil {
 			return nil, err
 		}
+		if err := checkRowSetMemory(env, "join with "+fromObject(j.Right), cur); err != nil {
+			return nil, err
+		}
+		if prof != nil {
+			details := fmt.Sprintf("%s, %d left x %d right rows", j.Type, leftCount, len(rightRows))
+			if j.On != nil {
+				details += ", on " + exprKind(j.On)
+			}
+			prof.record(method+" JOIN", fromObject(j.Right), tableRowEstimate(env, j.Right), len(cur), started, details)
+		}
+		if len(cur) == 0 {
+			emptyLeftKeys = append(emptyLeftKeys, tableRowKeys(aliasOr(j.Right), rightTable)...)
+		}
 	}
 	return cur, nil
 }
 
+// fromItemKeys returns the row keys a FROM table or CTE produces, or nil
+// when the source's columns are only known once it has been evaluated.
+func fromItemKeys(env ExecEnv, from FromItem) []string {
+	if from.Table == "" || from.Subquery != nil || from.TableFunc != nil {
+		return nil
+	}
+	if cte, ok := env.ctes[strings.ToLower(from.Table)]; ok {
+		return tableRowKeys(aliasOr(from), resultSetTable(aliasOr(from), cte.Cols))
+	}
+	t, err := env.db.Get(env.tenant, from.Table)
+	if err != nil {
+		return nil
+	}
+	return tableRowKeys(aliasOr(from), t)
+}
+
+// tableRowKeys returns the qualified and unqualified row keys of t's columns.
+func tableRowKeys(alias string, t *storage.Table) []string {
+	keys := make([]string, 0, 2*len(t.Cols))
+	for _, c := range t.Cols {
+		keys = append(keys, strings.ToLower(alias+"."+c.Name), strings.ToLower(c.Name))
+	}
+	return keys
+}
+
 // maxJoinRows bounds the number of rows a single join step may materialize.
 // LIMIT/OFFSET is applied only after all joins (and WHERE, GROUP BY, DISTINCT,
 // ORDER BY) run, so an unconditional cross join -- no ON clause, or one with a
@@ -6633,13 +7271,23 @@ func processInnerJoin(env ExecEnv, leftRows, rightRows []Row, onCondition Expr)
 		return nil, fmt.Errorf("join would produce more than %d rows without a filtering ON condition; add a condition or LIMIT the inputs", maxJoinRows)
 	}
 
-	// Use hash join optimization for large datasets
-	if len(leftRows) > 500 || len(rightRows) > 500 {
-		optimizer := &HashJoinOptimizer{env: env}
-		return optimizer.ProcessOptimizedJoin(leftRows, rightRows, onCondition, OptimizedJoinTypeInner)
+	if matches, ok, err := hashJoinMatches(env, leftRows, rightRows, onCondition); ok || err != nil {
+		if err != nil {
+			return nil, err
+		}
+		joined := make([]Row, 0, min(len(leftRows), len(rightRows)))
+		for _, ms := range matches {
+			for _, m := range ms {
+				joined = append(joined, m.row)
+			}
+			if int64(len(joined)) > maxJoinRows {
+				return nil, fmt.Errorf("join exceeded row limit %d", maxJoinRows)
+			}
+		}
+		return joined, nil
 	}
 
-	// Fall back to original nested loop for small datasets
+	// Nested loop for small inputs and conditions without a column equality
 	joined := make([]Row, 0, len(leftRows)*len(rightRows)/4) // Estimate result size
 	for _, l := range leftRows {
 		if err := checkCtx(env.ctx); err != nil {
@@ -6667,22 +7315,24 @@ func processInnerJoin(env ExecEnv, leftRows, rightRows []Row, onCondition Expr)
 }
 
 func processLeftJoin(env ExecEnv, leftRows, rightRows []Row, onCondition Expr, rightAlias string, rightTable *storage.Table) ([]Row, error) {
-	// Use hash join optimization for large datasets
-	if len(leftRows) > 500 || len(rightRows) > 500 {
-		optimizer := &HashJoinOptimizer{env: env}
-		result, err := optimizer.ProcessOptimizedJoin(leftRows, rightRows, onCondition, OptimizedJoinTypeLeft)
+	if matches, ok, err := hashJoinMatches(env, leftRows, rightRows, onCondition); ok || err != nil {
 		if err != nil {
 			return nil, err
 		}
-
-		// Add right nulls for unmatched rows (hash join might not handle all cases)
-		for _, row := range result {
-			addRightNulls(row, rightAlias, rightTable)
+		joined := make([]Row, 0, len(leftRows))
+		for li, ms := range matches {
+			for _, m := range ms {
+				joined = append(joined, m.row)
+			}
+			if len(ms) == 0 {
+				m := cloneRow(leftRows[li])
+				addRightNulls(m, rightAlias, rightTable)
+				joined = append(joined, m)
+			}
 		}
-		return result, nil
+		return joined, nil
 	}
 
-	// Fall back to original nested loop for small datasets
 	joined := make([]Row, 0, len(leftRows)) // At least one row per left row
 	for _, l := range leftRows {
 		if err := checkCtx(env.ctx); err != nil {
@@ -6713,12 +7363,31 @@ func processLeftJoin(env ExecEnv, leftRows, rightRows []Row, onCondition Expr, r
 	return joined, nil
 }
 
-func processRightJoin(env ExecEnv, leftRows, rightRows []Row, onCondition Expr) ([]Row, error) {
-	joined := make([]Row, 0, len(rightRows)) // At least one row per right row
-	var leftKeys []string
-	if len(leftRows) > 0 {
-		leftKeys = keysOfRow(leftRows[0])
+func processRightJoin(env ExecEnv, leftRows, rightRows []Row, onCondition Expr, leftKeys []string) ([]Row, error) {
+	if matches, ok, err := hashJoinMatches(env, leftRows, rightRows, onCondition); ok || err != nil {
+		if err != nil {
+			return nil, err
+		}
+		// Regroup by right row; left rows stay in order within each group.
+		byRight := make([][]Row, len(rightRows))
+		for _, ms := range matches {
+			for _, m := range ms {
+				byRight[m.right] = append(byRight[m.right], m.row)
+			}
+		}
+		joined := make([]Row, 0, len(rightRows))
+		for ri, rows := range byRight {
+			joined = append(joined, rows...)
+			if len(rows) == 0 {
+				m := cloneRow(rightRows[ri])
+				addLeftNulls(m, leftKeys)
+				joined = append(joined, m)
+			}
+		}
+		return joined, nil
 	}
+
+	joined := make([]Row, 0, len(rightRows)) // At least one row per right row
 	for _, r := range rightRows {
 		if err := checkCtx(env.ctx); err != nil {
 			return nil, err
@@ -6741,9 +7410,7 @@ func processRightJoin(env ExecEnv, leftRows, rightRows []Row, onCondition Expr)
 		}
 		if !matched {
 			m := cloneRow(r)
-			for _, k := range leftKeys {
-				m[k] = nil
-			}
+			addLeftNulls(m, leftKeys)
 			joined = append(joined, m)
 		}
 	}
@@ -6758,41 +7425,52 @@ func processRightJoin(env ExecEnv, leftRows, rightRows []Row, onCondition Expr)
 // mis-parsed as a table aliased "FULL" with the rest of the clause dropped
 // — a query that looked like a two-table join silently ran as a one-table
 // scan with no error.
-func processFullOuterJoin(env ExecEnv, leftRows, rightRows []Row, onCondition Expr, rightAlias string, rightTable *storage.Table) ([]Row, error) {
+func processFullOuterJoin(env ExecEnv, leftRows, rightRows []Row, onCondition Expr, leftKeys []string, rightAlias string, rightTable *storage.Table) ([]Row, error) {
 	matchedRight := make([]bool, len(rightRows))
 	joined := make([]Row, 0, len(leftRows)+len(rightRows))
 
-	var leftKeys []string
-	if len(leftRows) > 0 {
-		leftKeys = keysOfRow(leftRows[0])
-	}
-
-	for _, l := range leftRows {
-		if err := checkCtx(env.ctx); err != nil {
-			return nil, err
+	if matches, ok, err := hashJoinMatches(env, leftRows, rightRows, onCondition); err != nil {
+		return nil, err
+	} else if ok {
+		for li, ms := range matches {
+			for _, m := range ms {
+				joined = append(joined, m.row)
+				matchedRight[m.right] = true
+			}
+			if len(ms) == 0 {
+				m := cloneRow(leftRows[li])
+				addRightNulls(m, rightAlias, rightTable)
+				joined = append(joined, m)
+			}
 		}
-		matchedAny := false
-		for ri, r := range rightRows {
-			m := mergeRows(l, r)
-			ok := true
-			if onCondition != nil {
-				val, err := evalExpr(env, onCondition, m)
-				if err != nil {
-					return nil, err
+	} else {
+		for _, l := range leftRows {
+			if err := checkCtx(env.ctx); err != nil {
+				return nil, err
+			}
+			matchedAny := false
+			for ri, r := range rightRows {
+				m := mergeRows(l, r)
+				ok := true
+				if onCondition != nil {
+					val, err := evalExpr(env, onCondition, m)
+					if err != nil {
+						return nil, err
+					}
+					ok = (toTri(val) == tvTrue)
+				}
+				if ok {
+					joined = append(joined, m)
+					matchedAny = true
+					matchedRight[ri] = true
 				}
-				ok = (toTri(val) == tvTrue)
 			}
-			if ok {
+			if !matchedAny {
+				m := cloneRow(l)
+				addRightNulls(m, rightAlias, rightTable)
 				joined = append(joined, m)
-				matchedAny = true
-				matchedRight[ri] = true
 			}
 		}
-		if !matchedAny {
-			m := cloneRow(l)
-			addRightNulls(m, rightAlias, rightTable)
-			joined = append(joined, m)
-		}
 	}
 
 	for ri, r := range rightRows {
@@ -6800,9 +7478,7 @@ func processFullOuterJoin(env ExecEnv, leftRows, rightRows []Row, onCondition Ex
 			continue
 		}
 		m := cloneRow(r)
-		for _, k := range leftKeys {
-			m[k] = nil
-		}
+		addLeftNulls(m, leftKeys)
 		joined = append(joined, m)
 	}
 	return joined, nil
@@ -7131,6 +7807,11 @@ func processNonAggregateQuery(env ExecEnv, s *Select, filtered []Row) ([]Row, []
 	// If window functions are present, set up window context
 	if hasWindowFunctions {
 		env.windowRows = filtered
+		values, err := computeWindowValues(env, filtered, s.Projs)
+		if err != nil {
+			return nil, nil, err
+		}
+		env.windowValues = values
 	}
 
 	for rowIdx, r := range filtered {
@@ -7356,7 +8037,7 @@ func evalRecursiveCTE(env ExecEnv, cte *CTE) (*ResultSet, error) {
 		frontier = append(frontier, row)
 	}
 
-	iterLimit := 1024
+	iterLimit := maxRecursionDepth(env)
 	for iter := 0; iter < iterLimit && len(frontier) > 0; iter++ {
 		// SQL recursive evaluation feeds each iteration only the rows produced
 		// by the previous iteration (the working table), not all accumulated
@@ -7399,7 +8080,7 @@ func evalRecursiveCTE(env ExecEnv, cte *CTE) (*ResultSet, error) {
 		}
 	}
 	if len(frontier) > 0 {
-		return nil, fmt.Errorf("recursive CTE %s exceeded iteration limit %d", cte.Name, iterLimit)
+		return nil, fmt.Errorf("recursive CTE %s exceeded maximum recursion depth %d", cte.Name, iterLimit)
 	}
 	return &ResultSet{Cols: accRs.Cols, Rows: accRows}, nil
 }
@@ -7541,6 +8222,8 @@ func compare(a, b any) (int, error) {
 		return compareString(ax, b)
 	case bool:
 		return compareBool(ax, b)
+	case time.Time:
+		return compareTime(ax, b)
 	}
 	if fmt.Sprintf("%v", a) == fmt.Sprintf("%v", b) {
 		return 0, nil
@@ -7626,9 +8309,27 @@ func compareString(ax string, b any) (int, error) {
 		}
 		return 0, nil
 	}
+	if bt, ok := b.(time.Time); ok {
+		c, err := compareTime(bt, ax)
+		return -c, err
+	}
 	return 0, fmt.Errorf("incomparable string and %T", b)
 }
 
+// compareTime orders a DATE/DATETIME value against another time or against a
+// string literal in one of the formats accepted by parseDateTime.
+func compareTime(ax time.Time, b any) (int, error) {
+	switch b.(type) {
+	case time.Time, string:
+		bt, err := parseDateTime(b)
+		if err != nil {
+			return 0, fmt.Errorf("incomparable datetime and %q", b)
+		}
+		return ax.Compare(bt), nil
+	}
+	return 0, fmt.Errorf("incomparable datetime and %T", b)
+}
+
 func compareBool(ax bool, b any) (int, error) {
 	if bb, ok := b.(bool); ok {
 		if !ax && bb {
@@ -7702,11 +8403,13 @@ func evalExpr(env ExecEnv, e Expr, row Row) (any, error) {
 	case *BetweenExpr:
 		return evalBetween(env, ex, row)
 	case *ExistsExpr:
-		return evalExistsExpr(env, ex)
+		return evalExistsExpr(env, ex, row)
+	case *semiJoinExpr:
+		return ex.eval(env, row)
 	case *CaseExpr:
 		return evalCaseExpr(env, ex, row)
 	case *SubqueryExpr:
-		return evalSubqueryExpr(env, ex)
+		return evalSubqueryExpr(env, ex, row)
 	}
 	return nil, fmt.Errorf("unknown expression")
 }
@@ -7776,6 +8479,15 @@ func evalVarRef(env ExecEnv, ex *VarRef, row Row) (any, error) {
 			return v, nil
 		}
 	}
+	if env.outerRow != nil {
+		lower := ex.Lower
+		if lower == "" {
+			lower = strings.ToLower(ex.Name)
+		}
+		if v, ok := getValLower(env.outerRow, lower); ok {
+			return v, nil
+		}
+	}
 	var suggestion string
 	if env.triggerRow != nil {
 		suggestion = columnSuggestionFromRow(ex.Name, row, env.triggerRow)
@@ -7802,6 +8514,10 @@ func evalIn(env ExecEnv, ex *InExpr, row Row) (any, error) {
 	if err != nil {
 		return nil, err
 	}
+	if len(ex.Values) == 0 {
+		// Nothing can match an empty list, not even NULL.
+		return ex.Negate, nil
+	}
 	if val == nil {
 		// SQL three-valued logic: NULL IN (...) and NULL NOT IN (...) are
 		// both unknown, not a definite true/false.
@@ -7809,11 +8525,16 @@ func evalIn(env ExecEnv, ex *InExpr, row Row) (any, error) {
 	}
 
 	// Check against each value in the list
+	sawNull := false
 	for _, valExpr := range ex.Values {
 		listVal, err := evalExpr(env, valExpr, row)
 		if err != nil {
 			return nil, err
 		}
+		if listVal == nil {
+			sawNull = true
+			continue
+		}
 
 		// Compare values
 		cmp, err := compare(val, listVal)
@@ -7825,12 +8546,17 @@ func evalIn(env ExecEnv, ex *InExpr, row Row) (any, error) {
 			return true, nil
 		}
 	}
+	return inListMiss(ex.Negate, sawNull), nil
+}
 
-	// No match found
-	if ex.Negate {
-		return true, nil
+// inListMiss is the result of an IN / NOT IN predicate whose list held no
+// value equal to the operand. A NULL in the list makes the outcome unknown:
+// x IN (1, NULL) might have matched the NULL, so neither form is definite.
+func inListMiss(negate, sawNull bool) any {
+	if sawNull {
+		return nil
 	}
-	return false, nil
+	return negate
 }
 
 func evalLike(env ExecEnv, ex *LikeExpr, row Row) (any, error) {
@@ -7898,7 +8624,7 @@ func evalLike(env ExecEnv, ex *LikeExpr, row Row) (any, error) {
 	return matched, nil
 }
 
-// evalRegexpExpr evaluates REGEXP / RLIKE / SIMILAR TO predicates.
+// evalRegexpExpr evaluates REGEXP / RLIKE / IREGEXP / SIMILAR TO predicates.
 func evalRegexpExpr(env ExecEnv, ex *RegexpExpr, row Row) (any, error) {
 	val, err := evalExpr(env, ex.Expr, row)
 	if err != nil {
@@ -7915,9 +8641,7 @@ func evalRegexpExpr(env ExecEnv, ex *RegexpExpr, row Row) (any, error) {
 	}
 	str := fmt.Sprintf("%v", val)
 	pattern := fmt.Sprintf("%v", patternVal)
-	if ex.SimilarTo {
-		pattern = similarToRegexp(pattern)
-	}
+	pattern = regexpPattern(ex, pattern)
 	re, err := compileCachedRegexp(pattern)
 	if err != nil {
 		return nil, fmt.Errorf("REGEXP: invalid pattern %q: %v", pattern, err)
@@ -7929,8 +8653,13 @@ func evalRegexpExpr(env ExecEnv, ex *RegexpExpr, row Row) (any, error) {
 	return matched, nil
 }
 
-// evalExistsExpr evaluates EXISTS (subquery).
-func evalExistsExpr(env ExecEnv, ex *ExistsExpr) (any, error) {
+// evalExistsExpr evaluates EXISTS (subquery). The current row is bound as
+// env.outerRow so a correlated subquery can reference the outer columns.
+func evalExistsExpr(env ExecEnv, ex *ExistsExpr, row Row) (any, error) {
+	env.outerRow = withOuterRow(env.outerRow, row)
+	if existsProbeEligible(ex.Select) {
+		return existsProbe(env, ex.Select)
+	}
 	rs, err := executeSelect(env, ex.Select)
 	if err != nil {
 		return nil, err
@@ -7938,6 +8667,74 @@ func evalExistsExpr(env ExecEnv, ex *ExistsExpr) (any, error) {
 	return rs != nil && len(rs.Rows) > 0, nil
 }
 
+// existsProbeEligible reports whether a subquery produces one output row per
+// row passing its WHERE clause, so EXISTS only has to find the first such
+// row. Aggregates, grouping, DISTINCT ON, set operations, paging and PIVOT
+// change the row count and take the full execution path.
+func existsProbeEligible(s *Select) bool {
+	return s.Union == nil && s.Pivot == nil && s.Having == nil && len(s.GroupBy) == 0 &&
+		len(s.DistinctOn) == 0 && s.Limit == nil && s.Offset == nil &&
+		!anyAggInSelect(s.Projs) && !anyWindowInSelect(s.Projs)
+}
+
+// existsProbe evaluates EXISTS for an eligible subquery without projecting
+// anything, stopping at the first row that satisfies WHERE.
+func existsProbe(env ExecEnv, s *Select) (bool, error) {
+	cteEnv, err := processCTEs(env, s)
+	if err != nil {
+		return false, err
+	}
+	cur, err := resolveFromClause(cteEnv, cteEnv, s)
+	if err != nil {
+		return false, err
+	}
+	fromFilter, joinFilters, residual := planPredicatePushdown(cteEnv, s)
+	if cur, err = applyWhereClause(cteEnv, fromFilter, cur); err != nil {
+		return false, err
+	}
+	joins, joinFilters, _ := planJoinOrder(cteEnv, s, fromFilter, joinFilters)
+	cur, err = processJoins(cteEnv, s.From, joins, cur, joinFilters, nil)
+	if err != nil {
+		return false, err
+	}
+	where, err := rewriteExistsSemiJoins(cteEnv, residual)
+	if err != nil {
+		return false, err
+	}
+	if where == nil {
+		return len(cur) > 0, nil
+	}
+	for _, r := range cur {
+		if err := checkCtx(env.ctx); err != nil {
+			return false, err
+		}
+		v, err := evalExpr(cteEnv, where, r)
+		if err != nil {
+			return false, err
+		}
+		if toTri(v) == tvTrue {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// withOuterRow returns the row a nested subquery sees as its outer scope:
+// the current row, falling back to any scope that encloses it.
+func withOuterRow(outer, row Row) Row {
+	if outer == nil {
+		return row
+	}
+	merged := make(Row, len(outer)+len(row))
+	for k, v := range outer {
+		merged[k] = v
+	}
+	for k, v := range row {
+		merged[k] = v
+	}
+	return merged
+}
+
 // matchLikePattern matches a string against a SQL LIKE pattern.
 // % matches zero or more characters, _ matches exactly one character.
 //
@@ -8041,6 +8838,18 @@ func matchGlobPattern(str, pattern string) bool {
 	return pIdx == pLen
 }
 
+// regexpPattern returns the Go regular expression a RegexpExpr matches with:
+// SIMILAR TO patterns are translated and IREGEXP adds the (?i) flag.
+func regexpPattern(ex *RegexpExpr, pattern string) string {
+	if ex.SimilarTo {
+		pattern = similarToRegexp(pattern)
+	}
+	if ex.CaseInsensitive {
+		pattern = "(?i)" + pattern
+	}
+	return pattern
+}
+
 // similarToRegexp converts a SQL SIMILAR TO pattern to a Go regexp pattern.
 // Rules:
 //   - % matches any sequence of characters (like .* in regex)
@@ -8104,7 +8913,11 @@ func evalCaseExpr(env ExecEnv, ex *CaseExpr, row Row) (any, error) {
 	return nil, nil
 }
 
-func evalSubqueryExpr(env ExecEnv, ex *SubqueryExpr) (any, error) {
+// evalSubqueryExpr evaluates a scalar subquery. Like EXISTS, the current row
+// is bound as env.outerRow so the subquery may be correlated. The subquery
+// must produce a single column and at most one row; no row yields NULL.
+func evalSubqueryExpr(env ExecEnv, ex *SubqueryExpr, row Row) (any, error) {
+	env.outerRow = withOuterRow(env.outerRow, row)
 	rs, err := executeSelect(env, ex.Select)
 	if err != nil {
 		return nil, err
@@ -8112,29 +8925,14 @@ func evalSubqueryExpr(env ExecEnv, ex *SubqueryExpr) (any, error) {
 	if rs == nil || len(rs.Rows) == 0 {
 		return nil, nil
 	}
+	if len(rs.Cols) != 1 {
+		return nil, fmt.Errorf("scalar subquery must return exactly one column, got %d", len(rs.Cols))
+	}
 	if len(rs.Rows) > 1 {
 		return nil, fmt.Errorf("scalar subquery returned %d rows", len(rs.Rows))
 	}
-	row := rs.Rows[0]
-	if len(rs.Cols) == 1 {
-		if v, ok := getValLower(row, strings.ToLower(rs.Cols[0])); ok {
-			return v, nil
-		}
-	}
-	if len(row) == 1 {
-		for _, v := range row {
-			return v, nil
-		}
-	}
-	for _, col := range rs.Cols {
-		if v, ok := getValLower(row, strings.ToLower(col)); ok {
-			return v, nil
-		}
-	}
-	for _, v := range row {
-		return v, nil
-	}
-	return nil, nil
+	v, _ := getValLower(rs.Rows[0], strings.ToLower(rs.Cols[0]))
+	return v, nil
 }
 
 func evalUnary(env ExecEnv, ex *Unary, row Row) (any, error) {
@@ -8218,6 +9016,9 @@ func evalLogicalBinary(env ExecEnv, ex *Binary, row Row) (any, error) {
 }
 
 func evalArithmeticBinary(op string, lv, rv any) (any, error) {
+	if v, ok, err := evalIntervalArithmetic(op, lv, rv); ok {
+		return v, err
+	}
 	if op == "+" {
 		if isStringValue(lv) || isStringValue(rv) {
 			return stringifySQLValue(lv) + stringifySQLValue(rv), nil
@@ -8326,6 +9127,7 @@ func getBuiltinFunctions() map[string]funcHandler {
 		"FROM_TIMESTAMP":    evalFromTimestampFunc,
 		"TIMESTAMP":         evalTimestampFunc,
 		"DATEDIFF":          evalDateDiff,
+		"DATE_DIFF":         evalDateDiff,
 		"LTRIM":             evalLTrimFunc,
 		"RTRIM":             evalRTrimFunc,
 		"TRIM":              evalTrimFunc,
@@ -8408,6 +9210,7 @@ func getBuiltinFunctions() map[string]funcHandler {
 		"INITCAP":    evalInitcapFunc,
 		"SPLIT_PART": evalSplitPartFunc,
 		"SOUNDEX":    evalSoundexFunc,
+		"DIFFERENCE": evalDifferenceFunc,
 		"QUOTE":      evalQuoteFunc,
 		"HEX":        evalHexFunc,
 		"UNHEX":      evalUnhexFunc,
@@ -8760,6 +9563,10 @@ func evalSplitPartFunc(env ExecEnv, ex *FuncCall, row Row) (any, error) {
 func evalSoundexFunc(env ExecEnv, ex *FuncCall, row Row) (any, error) {
 	return evalSoundex(env, ex.Args, row)
 }
+
+func evalDifferenceFunc(env ExecEnv, ex *FuncCall, row Row) (any, error) {
+	return evalDifference(env, ex.Args, row)
+}
 func evalQuoteFunc(env ExecEnv, ex *FuncCall, row Row) (any, error) {
 	return evalQuote(env, ex.Args, row)
 }
@@ -8857,6 +9664,9 @@ func evalJSONGet(env ExecEnv, args []Expr, row Row) (any, error) {
 		return nil, err
 	}
 	ps, _ := pv.(string)
+	if s, ok := jv.(string); ok {
+		jv, _ = jsonDocument(s)
+	}
 	return jsonGet(jv, ps), nil
 }
 
@@ -8878,8 +9688,13 @@ func evalJSONExtended(env ExecEnv, ex *FuncCall, row Row) (any, error) {
 		if err != nil {
 			return nil, err
 		}
+		// A NULL document or path gives NULL; a NULL value sets JSON null.
+		if jv == nil || pv == nil {
+			return nil, nil
+		}
 		ps, _ := pv.(string)
-		return jsonSet(jv, ps, val), nil
+		doc, text := jsonDocument(jv)
+		return jsonResult(jsonSet(doc, ps, val), text)
 
 	case "JSON_EXTRACT":
 		// Alias for JSON_GET
@@ -8955,6 +9770,10 @@ func evalDateDiff(env ExecEnv, ex *FuncCall, row Row) (any, error) {
 		return nil, err
 	}
 
+	if startVal == nil || endVal == nil {
+		return nil, nil
+	}
+
 	// Convert values to time.Time
 	startTime, err := parseTimeValue(startVal)
 	if err != nil {
@@ -8971,20 +9790,20 @@ func evalDateDiff(env ExecEnv, ex *FuncCall, row Row) (any, error) {
 
 	// Return based on unit
 	switch strings.ToUpper(unit) {
-	case "HOURS":
+	case "HOUR", "HOURS":
 		return int(diff.Hours()), nil
-	case "MINUTES":
+	case "MINUTE", "MINUTES":
 		return int(diff.Minutes()), nil
-	case "SECONDS":
+	case "SECOND", "SECONDS":
 		return int(diff.Seconds()), nil
-	case "DAYS":
+	case "DAY", "DAYS":
 		return int(diff.Hours() / 24), nil
-	case "WEEKS":
+	case "WEEK", "WEEKS":
 		return int(diff.Hours() / (24 * 7)), nil
-	case "MONTHS":
+	case "MONTH", "MONTHS":
 		// Approximate: 30 days per month
 		return int(diff.Hours() / (24 * 30)), nil
-	case "YEARS":
+	case "YEAR", "YEARS":
 		// Approximate: 365 days per year
 		return int(diff.Hours() / (24 * 365)), nil
 	default:
@@ -9136,13 +9955,14 @@ func evalTrimCommon(env ExecEnv, name string, side trimSide, args []Expr, row Ro
 		if err != nil {
 			return nil, err
 		}
-		if cutsetVal != nil {
-			if cutsetStr, ok := cutsetVal.(string); ok {
-				cutset = cutsetStr
-			} else {
-				return nil, fmt.Errorf("%s cutset must be a string", name)
-			}
+		if cutsetVal == nil {
+			return nil, nil
+		}
+		cutsetStr, ok := cutsetVal.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s cutset must be a string", name)
 		}
+		cutset = cutsetStr
 	}
 
 	if cutset == "" {
@@ -9281,10 +10101,13 @@ func evalLength(env ExecEnv, args []Expr, row Row) (any, error) {
 		str = fmt.Sprintf("%v", val)
 	}
 
-	return len(str), nil
+	return utf8.RuneCountInString(str), nil
 }
 
-//nolint:gocyclo // SUBSTRING handling covers varying arity, coercion, and bounds checks.
+// evalSubstring implements SUBSTR/SUBSTRING(str, start [, length]) over
+// characters. Positions are 1-based; a negative start counts back from the
+// end of the string, as in SQLite. The selected window is clipped to the
+// string, so SUBSTR('hello', 0, 2) is 'h'.
 func evalSubstring(env ExecEnv, args []Expr, row Row) (any, error) {
 	if len(args) < 2 || len(args) > 3 {
 		return nil, fmt.Errorf("SUBSTRING expects 2 or 3 arguments")
@@ -9303,51 +10126,48 @@ func evalSubstring(env ExecEnv, args []Expr, row Row) (any, error) {
 	if !ok {
 		str = fmt.Sprintf("%v", val)
 	}
+	runes := []rune(str)
 
-	// Get start position (1-indexed in SQL)
-	startVal, err := evalExpr(env, args[1], row)
-	if err != nil {
+	start, ok, err := evalIntArg(env, "SUBSTRING", "start position", args[1], row)
+	if err != nil || !ok {
 		return nil, err
 	}
-	startAny, err := coerceToInt(startVal)
-	if err != nil {
-		return nil, fmt.Errorf("SUBSTRING start position must be numeric")
-	}
-	start, ok := startAny.(int)
-	if !ok {
-		return nil, fmt.Errorf("SUBSTRING start position must be an integer")
-	}
-	start = start - 1 // Convert to 0-indexed
 	if start < 0 {
-		start = 0
-	}
-	if start >= len(str) {
-		return "", nil
+		start += len(runes) + 1
 	}
-
-	// Get length if provided
+	end := len(runes) + 1
 	if len(args) == 3 {
-		lengthVal, err := evalExpr(env, args[2], row)
-		if err != nil {
+		length, ok, err := evalIntArg(env, "SUBSTRING", "length", args[2], row)
+		if err != nil || !ok {
 			return nil, err
 		}
-		lengthAny, err := coerceToInt(lengthVal)
-		if err != nil {
-			return nil, fmt.Errorf("SUBSTRING length must be numeric")
-		}
-		length, ok := lengthAny.(int)
-		if !ok {
-			return nil, fmt.Errorf("SUBSTRING length must be an integer")
+		if length < 0 {
+			return nil, fmt.Errorf("SUBSTRING length must not be negative")
 		}
+		end = start + length
+	}
 
-		end := start + length
-		if end > len(str) {
-			end = len(str)
-		}
-		return str[start:end], nil
+	// Clip the 1-based window [start, end) to the string.
+	start = max(start, 1)
+	end = min(end, len(runes)+1)
+	if start >= end {
+		return "", nil
 	}
+	return string(runes[start-1 : end-1]), nil
+}
 
-	return str[start:], nil
+// evalIntArg evaluates an integer argument of a string function. ok is false
+// when the argument is NULL, which makes the whole call NULL.
+func evalIntArg(env ExecEnv, fn, what string, e Expr, row Row) (n int, ok bool, err error) {
+	v, err := evalExpr(env, e, row)
+	if err != nil || v == nil {
+		return 0, false, err
+	}
+	iv, err := coerceToInt(v)
+	if err != nil {
+		return 0, false, fmt.Errorf("%s %s must be numeric", fn, what)
+	}
+	return iv.(int), true, nil
 }
 
 func evalLeft(env ExecEnv, args []Expr, row Row) (any, error) {
@@ -9368,26 +10188,19 @@ func evalLeft(env ExecEnv, args []Expr, row Row) (any, error) {
 		str = fmt.Sprintf("%v", val)
 	}
 
-	lenVal, err := evalExpr(env, args[1], row)
-	if err != nil {
+	length, ok, err := evalIntArg(env, "LEFT", "length", args[1], row)
+	if err != nil || !ok {
 		return nil, err
 	}
-	lenAny, err := coerceToInt(lenVal)
-	if err != nil {
-		return nil, fmt.Errorf("LEFT length must be numeric")
-	}
-	length, ok := lenAny.(int)
-	if !ok {
-		return nil, fmt.Errorf("LEFT length must be an integer")
-	}
 
+	runes := []rune(str)
 	if length < 0 {
 		return "", nil
 	}
-	if length > len(str) {
+	if length > len(runes) {
 		return str, nil
 	}
-	return str[:length], nil
+	return string(runes[:length]), nil
 }
 
 func evalRight(env ExecEnv, args []Expr, row Row) (any, error) {
@@ -9408,26 +10221,19 @@ func evalRight(env ExecEnv, args []Expr, row Row) (any, error) {
 		str = fmt.Sprintf("%v", val)
 	}
 
-	lenVal, err := evalExpr(env, args[1], row)
-	if err != nil {
+	length, ok, err := evalIntArg(env, "RIGHT", "length", args[1], row)
+	if err != nil || !ok {
 		return nil, err
 	}
-	lenAny, err := coerceToInt(lenVal)
-	if err != nil {
-		return nil, fmt.Errorf("RIGHT length must be numeric")
-	}
-	length, ok := lenAny.(int)
-	if !ok {
-		return nil, fmt.Errorf("RIGHT length must be an integer")
-	}
 
+	runes := []rune(str)
 	if length < 0 {
 		return "", nil
 	}
-	if length > len(str) {
+	if length > len(runes) {
 		return str, nil
 	}
-	return str[len(str)-length:], nil
+	return string(runes[len(runes)-length:]), nil
 }
 
 // New string functions
@@ -9449,15 +10255,18 @@ func evalReplace(env ExecEnv, args []Expr, row Row) (any, error) {
 	if err != nil {
 		return nil, err
 	}
-	from := fmt.Sprintf("%v", fromVal)
-
 	toVal, err := evalExpr(env, args[2], row)
 	if err != nil {
 		return nil, err
 	}
-	to := fmt.Sprintf("%v", toVal)
-
-	return strings.ReplaceAll(str, from, to), nil
+	if fromVal == nil || toVal == nil {
+		return nil, nil
+	}
+	from := fmt.Sprintf("%v", fromVal)
+	if from == "" {
+		return str, nil
+	}
+	return strings.ReplaceAll(str, from, fmt.Sprintf("%v", toVal)), nil
 }
 
 func evalInstr(env ExecEnv, args []Expr, row Row) (any, error) {
@@ -9468,22 +10277,20 @@ func evalInstr(env ExecEnv, args []Expr, row Row) (any, error) {
 	if err != nil {
 		return nil, err
 	}
-	if val == nil {
-		return 0, nil
-	}
-	str := fmt.Sprintf("%v", val)
-
 	searchVal, err := evalExpr(env, args[1], row)
 	if err != nil {
 		return nil, err
 	}
-	search := fmt.Sprintf("%v", searchVal)
+	if val == nil || searchVal == nil {
+		return nil, nil
+	}
+	str := fmt.Sprintf("%v", val)
 
-	idx := strings.Index(str, search)
+	idx := strings.Index(str, fmt.Sprintf("%v", searchVal))
 	if idx == -1 {
 		return 0, nil
 	}
-	return idx + 1, nil // 1-based index
+	return utf8.RuneCountInString(str[:idx]) + 1, nil // 1-based character index
 }
 
 func evalReverse(env ExecEnv, args []Expr, row Row) (any, error) {
@@ -9518,16 +10325,11 @@ func evalRepeat(env ExecEnv, args []Expr, row Row) (any, error) {
 	}
 	str := fmt.Sprintf("%v", val)
 
-	countVal, err := evalExpr(env, args[1], row)
-	if err != nil {
+	count, ok, err := evalIntArg(env, "REPEAT", "count", args[1], row)
+	if err != nil || !ok {
 		return nil, err
 	}
-	countAny, err := coerceToInt(countVal)
-	if err != nil {
-		return nil, fmt.Errorf("REPEAT count must be numeric")
-	}
-	count, ok := countAny.(int)
-	if !ok || count < 0 {
+	if count < 0 {
 		return "", nil
 	}
 	return strings.Repeat(str, count), nil
@@ -9555,95 +10357,56 @@ func evalPrintf(env ExecEnv, args []Expr, row Row) (any, error) {
 }
 
 func evalLpad(env ExecEnv, args []Expr, row Row) (any, error) {
+	return evalPad(env, "LPAD", true, args, row)
+}
+
+func evalRpad(env ExecEnv, args []Expr, row Row) (any, error) {
+	return evalPad(env, "RPAD", false, args, row)
+}
+
+// evalPad implements LPAD/RPAD(str, length [, pad]) over characters. A
+// string longer than length is truncated, a length of zero or less yields
+// an empty string, and an empty pad leaves a shorter string unchanged.
+func evalPad(env ExecEnv, name string, left bool, args []Expr, row Row) (any, error) {
 	if len(args) < 2 || len(args) > 3 {
-		return nil, fmt.Errorf("LPAD expects 2-3 arguments: (string, length[, pad])")
+		return nil, fmt.Errorf("%s expects 2-3 arguments: (string, length[, pad])", name)
 	}
 	val, err := evalExpr(env, args[0], row)
 	if err != nil {
 		return nil, err
 	}
-	if val == nil {
-		return nil, nil
-	}
-	str := fmt.Sprintf("%v", val)
-
-	lenVal, err := evalExpr(env, args[1], row)
-	if err != nil {
+	length, ok, err := evalIntArg(env, name, "length", args[1], row)
+	if err != nil || !ok || val == nil {
 		return nil, err
 	}
-	lenAny, err := coerceToInt(lenVal)
-	if err != nil {
-		return nil, fmt.Errorf("LPAD length must be numeric")
-	}
-	length, ok := lenAny.(int)
-	if !ok || length < 0 {
-		return str, nil
-	}
-
 	pad := " "
 	if len(args) == 3 {
 		padVal, err := evalExpr(env, args[2], row)
-		if err != nil {
+		if err != nil || padVal == nil {
 			return nil, err
 		}
 		pad = fmt.Sprintf("%v", padVal)
-		if pad == "" {
-			pad = " "
-		}
-	}
-
-	if len(str) >= length {
-		return str[:length], nil
-	}
-	needed := length - len(str)
-	padding := strings.Repeat(pad, (needed/len(pad))+1)[:needed]
-	return padding + str, nil
-}
-
-func evalRpad(env ExecEnv, args []Expr, row Row) (any, error) {
-	if len(args) < 2 || len(args) > 3 {
-		return nil, fmt.Errorf("RPAD expects 2-3 arguments: (string, length[, pad])")
-	}
-	val, err := evalExpr(env, args[0], row)
-	if err != nil {
-		return nil, err
-	}
-	if val == nil {
-		return nil, nil
 	}
-	str := fmt.Sprintf("%v", val)
 
-	lenVal, err := evalExpr(env, args[1], row)
-	if err != nil {
-		return nil, err
+	runes := []rune(fmt.Sprintf("%v", val))
+	if length <= 0 {
+		return "", nil
 	}
-	lenAny, err := coerceToInt(lenVal)
-	if err != nil {
-		return nil, fmt.Errorf("RPAD length must be numeric")
+	if len(runes) >= length {
+		return string(runes[:length]), nil
 	}
-	length, ok := lenAny.(int)
-	if !ok || length < 0 {
-		return str, nil
+	if pad == "" {
+		return string(runes), nil
 	}
-
-	pad := " "
-	if len(args) == 3 {
-		padVal, err := evalExpr(env, args[2], row)
-		if err != nil {
-			return nil, err
-		}
-		pad = fmt.Sprintf("%v", padVal)
-		if pad == "" {
-			pad = " "
-		}
+	padRunes := []rune(pad)
+	padding := make([]rune, length-len(runes))
+	for i := range padding {
+		padding[i] = padRunes[i%len(padRunes)]
 	}
-
-	if len(str) >= length {
-		return str[:length], nil
+	if left {
+		return string(padding) + string(runes), nil
 	}
-	needed := length - len(str)
-	padding := strings.Repeat(pad, (needed/len(pad))+1)[:needed]
-	return str + padding, nil
+	return string(runes) + string(padding), nil
 }
 
 // Math functions
@@ -9680,6 +10443,14 @@ func evalRound(env ExecEnv, args []Expr, row Row) (any, error) {
 	if val == nil {
 		return nil, nil
 	}
+	if len(args) == 1 {
+		// Rounding an integer to zero decimals is the identity; keep
+		// its type rather than widening it to FLOAT.
+		switch val.(type) {
+		case int, int64:
+			return val, nil
+		}
+	}
 	f, ok := numeric(val)
 	if !ok {
 		return nil, fmt.Errorf("ROUND requires numeric argument")
@@ -9851,6 +10622,9 @@ func evalStrftime(env ExecEnv, args []Expr, row Row) (any, error) {
 		if err != nil {
 			return nil, err
 		}
+		if dtVal == nil {
+			return nil, nil
+		}
 		t, err = parseDateTime(dtVal)
 		if err != nil {
 			return nil, err
@@ -9871,6 +10645,9 @@ func evalDate(env ExecEnv, args []Expr, row Row) (any, error) {
 		if err != nil {
 			return nil, err
 		}
+		if val == nil {
+			return nil, nil
+		}
 		t, err = parseDateTime(val)
 		if err != nil {
 			return nil, err
@@ -9888,6 +10665,9 @@ func evalTime(env ExecEnv, args []Expr, row Row) (any, error) {
 		if err != nil {
 			return nil, err
 		}
+		if val == nil {
+			return nil, nil
+		}
 		t, err = parseDateTime(val)
 		if err != nil {
 			return nil, err
@@ -9904,6 +10684,9 @@ func evalYear(env ExecEnv, args []Expr, row Row) (any, error) {
 	if err != nil {
 		return nil, err
 	}
+	if val == nil {
+		return nil, nil
+	}
 	t, err := parseDateTime(val)
 	if err != nil {
 		return nil, err
@@ -9919,6 +10702,9 @@ func evalMonth(env ExecEnv, args []Expr, row Row) (any, error) {
 	if err != nil {
 		return nil, err
 	}
+	if val == nil {
+		return nil, nil
+	}
 	t, err := parseDateTime(val)
 	if err != nil {
 		return nil, err
@@ -9934,6 +10720,9 @@ func evalDay(env ExecEnv, args []Expr, row Row) (any, error) {
 	if err != nil {
 		return nil, err
 	}
+	if val == nil {
+		return nil, nil
+	}
 	t, err := parseDateTime(val)
 	if err != nil {
 		return nil, err
@@ -9949,6 +10738,9 @@ func evalHour(env ExecEnv, args []Expr, row Row) (any, error) {
 	if err != nil {
 		return nil, err
 	}
+	if val == nil {
+		return nil, nil
+	}
 	t, err := parseDateTime(val)
 	if err != nil {
 		return nil, err
@@ -9964,6 +10756,9 @@ func evalMinute(env ExecEnv, args []Expr, row Row) (any, error) {
 	if err != nil {
 		return nil, err
 	}
+	if val == nil {
+		return nil, nil
+	}
 	t, err := parseDateTime(val)
 	if err != nil {
 		return nil, err
@@ -9979,6 +10774,9 @@ func evalSecond(env ExecEnv, args []Expr, row Row) (any, error) {
 	if err != nil {
 		return nil, err
 	}
+	if val == nil {
+		return nil, nil
+	}
 	t, err := parseDateTime(val)
 	if err != nil {
 		return nil, err
@@ -10048,6 +10846,9 @@ func evalMod(env ExecEnv, args []Expr, row Row) (any, error) {
 	if err != nil {
 		return nil, err
 	}
+	if av == nil || bv == nil {
+		return nil, nil
+	}
 	a, ok := numeric(av)
 	if !ok {
 		return nil, fmt.Errorf("MOD: first argument must be numeric")
@@ -10074,6 +10875,9 @@ func evalPower(env ExecEnv, args []Expr, row Row) (any, error) {
 	if err != nil {
 		return nil, err
 	}
+	if baseVal == nil || expVal == nil {
+		return nil, nil
+	}
 	base, ok := numeric(baseVal)
 	if !ok {
 		return nil, fmt.Errorf("POWER: base must be numeric")
@@ -10090,7 +10894,7 @@ func evalSqrt(env ExecEnv, args []Expr, row Row) (any, error) {
 		return nil, fmt.Errorf("SQRT expects 1 argument")
 	}
 	val, err := evalExpr(env, args[0], row)
-	if err != nil {
+	if err != nil || val == nil {
 		return nil, err
 	}
 	n, ok := numeric(val)
@@ -10110,7 +10914,7 @@ func evalLog(env ExecEnv, args []Expr, row Row) (any, error) {
 	if len(args) == 1 {
 		// LOG(x) = natural log
 		val, err := evalExpr(env, args[0], row)
-		if err != nil {
+		if err != nil || val == nil {
 			return nil, err
 		}
 		n, ok := numeric(val)
@@ -10131,6 +10935,9 @@ func evalLog(env ExecEnv, args []Expr, row Row) (any, error) {
 	if err != nil {
 		return nil, err
 	}
+	if baseVal == nil || val == nil {
+		return nil, nil
+	}
 	base, ok := numeric(baseVal)
 	if !ok {
 		return nil, fmt.Errorf("LOG: base must be numeric")
@@ -10150,7 +10957,7 @@ func evalLn(env ExecEnv, args []Expr, row Row) (any, error) {
 		return nil, fmt.Errorf("LN expects 1 argument")
 	}
 	val, err := evalExpr(env, args[0], row)
-	if err != nil {
+	if err != nil || val == nil {
 		return nil, err
 	}
 	n, ok := numeric(val)
@@ -10168,7 +10975,7 @@ func evalLog10(env ExecEnv, args []Expr, row Row) (any, error) {
 		return nil, fmt.Errorf("LOG10 expects 1 argument")
 	}
 	val, err := evalExpr(env, args[0], row)
-	if err != nil {
+	if err != nil || val == nil {
 		return nil, err
 	}
 	n, ok := numeric(val)
@@ -10186,7 +10993,7 @@ func evalLog2(env ExecEnv, args []Expr, row Row) (any, error) {
 		return nil, fmt.Errorf("LOG2 expects 1 argument")
 	}
 	val, err := evalExpr(env, args[0], row)
-	if err != nil {
+	if err != nil || val == nil {
 		return nil, err
 	}
 	n, ok := numeric(val)
@@ -10204,7 +11011,7 @@ func evalExp(env ExecEnv, args []Expr, row Row) (any, error) {
 		return nil, fmt.Errorf("EXP expects 1 argument")
 	}
 	val, err := evalExpr(env, args[0], row)
-	if err != nil {
+	if err != nil || val == nil {
 		return nil, err
 	}
 	n, ok := numeric(val)
@@ -10219,7 +11026,7 @@ func evalSign(env ExecEnv, args []Expr, row Row) (any, error) {
 		return nil, fmt.Errorf("SIGN expects 1 argument")
 	}
 	val, err := evalExpr(env, args[0], row)
-	if err != nil {
+	if err != nil || val == nil {
 		return nil, err
 	}
 	n, ok := numeric(val)
@@ -10239,7 +11046,7 @@ func evalTruncate(env ExecEnv, args []Expr, row Row) (any, error) {
 		return nil, fmt.Errorf("TRUNCATE expects 1-2 arguments")
 	}
 	val, err := evalExpr(env, args[0], row)
-	if err != nil {
+	if err != nil || val == nil {
 		return nil, err
 	}
 	n, ok := numeric(val)
@@ -10252,6 +11059,9 @@ func evalTruncate(env ExecEnv, args []Expr, row Row) (any, error) {
 		if err != nil {
 			return nil, err
 		}
+		if dv == nil {
+			return nil, nil
+		}
 		d, ok := numeric(dv)
 		if !ok {
 			return nil, fmt.Errorf("TRUNCATE: decimals must be numeric")
@@ -10499,14 +11309,27 @@ func evalSplitPart(env ExecEnv, args []Expr, row Row) (any, error) {
 	if err != nil {
 		return nil, err
 	}
+	if strVal == nil || delimVal == nil || partVal == nil {
+		return nil, nil
+	}
 	s := fmt.Sprintf("%v", strVal)
 	delim := fmt.Sprintf("%v", delimVal)
 	part, ok := numeric(partVal)
 	if !ok {
 		return nil, fmt.Errorf("SPLIT_PART: part must be numeric")
 	}
-	parts := strings.Split(s, delim)
+	if int(part) == 0 {
+		return nil, fmt.Errorf("SPLIT_PART: part must not be zero")
+	}
+	// An empty delimiter does not split; negative parts count from the end.
+	parts := []string{s}
+	if delim != "" {
+		parts = strings.Split(s, delim)
+	}
 	idx := int(part) - 1 // 1-indexed
+	if part < 0 {
+		idx = len(parts) + int(part)
+	}
 	if idx < 0 || idx >= len(parts) {
 		return "", nil
 	}
@@ -10521,34 +11344,78 @@ func evalSoundex(env ExecEnv, args []Expr, row Row) (any, error) {
 	if err != nil {
 		return nil, err
 	}
-	s := strings.ToUpper(fmt.Sprintf("%v", val))
-	if len(s) == 0 {
-		return "", nil
+	if val == nil {
+		return nil, nil
+	}
+	return soundex(fmt.Sprintf("%v", val)), nil
+}
+
+// soundexCodes maps consonants to their American Soundex digit. Vowels and
+// Y (code 0) separate equal codes; H and W (absent) do not.
+var soundexCodes = [26]byte{
+	'0', '1', '2', '3', '0', '1', '2', 0, '0', '2', '2', '4', '5',
+	'5', '0', '1', '2', '6', '2', '3', '0', '1', 0, '2', '0', '2',
+}
+
+// soundex returns the four-character American Soundex code of s: its first
+// letter followed by three digits. Non-letters are ignored; a string without
+// ASCII letters yields "".
+func soundex(s string) string {
+	var code [4]byte
+	n := 0
+	var last byte
+	for i := 0; i < len(s) && n < 4; i++ {
+		c := s[i] | 0x20 // ASCII lower case
+		if c < 'a' || c > 'z' {
+			continue
+		}
+		d := soundexCodes[c-'a']
+		switch {
+		case n == 0:
+			code[0] = c - 0x20
+			n = 1
+		case d == 0:
+			continue // H and W keep the previous code
+		case d != '0' && d != last:
+			code[n] = d
+			n++
+		}
+		last = d
 	}
-	// Soundex algorithm
-	result := []byte{s[0]}
-	mapping := map[byte]byte{
-		'B': '1', 'F': '1', 'P': '1', 'V': '1',
-		'C': '2', 'G': '2', 'J': '2', 'K': '2', 'Q': '2', 'S': '2', 'X': '2', 'Z': '2',
-		'D': '3', 'T': '3',
-		'L': '4',
-		'M': '5', 'N': '5',
-		'R': '6',
-	}
-	lastCode := mapping[s[0]]
-	for i := 1; i < len(s) && len(result) < 4; i++ {
-		c := s[i]
-		if code, ok := mapping[c]; ok && code != lastCode {
-			result = append(result, code)
-			lastCode = code
-		} else if c == 'A' || c == 'E' || c == 'I' || c == 'O' || c == 'U' || c == 'H' || c == 'W' || c == 'Y' {
-			lastCode = 0
-		}
-	}
-	for len(result) < 4 {
-		result = append(result, '0')
+	if n == 0 {
+		return ""
 	}
-	return string(result), nil
+	for ; n < 4; n++ {
+		code[n] = '0'
+	}
+	return string(code[:])
+}
+
+// evalDifference implements DIFFERENCE(a, b): how many of the four SOUNDEX
+// characters of a and b agree, from 0 (unrelated) to 4 (same code).
+func evalDifference(env ExecEnv, args []Expr, row Row) (any, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("DIFFERENCE expects 2 arguments")
+	}
+	a, err := evalExpr(env, args[0], row)
+	if err != nil {
+		return nil, err
+	}
+	b, err := evalExpr(env, args[1], row)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil || b == nil {
+		return nil, nil
+	}
+	sa, sb := soundex(fmt.Sprintf("%v", a)), soundex(fmt.Sprintf("%v", b))
+	same := 0
+	for i := 0; i < len(sa) && i < len(sb); i++ {
+		if sa[i] == sb[i] {
+			same++
+		}
+	}
+	return same, nil
 }
 
 func evalQuote(env ExecEnv, args []Expr, row Row) (any, error) {
@@ -10697,6 +11564,9 @@ func evalDayOfWeek(env ExecEnv, args []Expr, row Row) (any, error) {
 	if err != nil {
 		return nil, err
 	}
+	if val == nil {
+		return nil, nil
+	}
 	t, err := parseDateTime(val)
 	if err != nil {
 		return nil, err
@@ -10712,6 +11582,9 @@ func evalDayOfYear(env ExecEnv, args []Expr, row Row) (any, error) {
 	if err != nil {
 		return nil, err
 	}
+	if val == nil {
+		return nil, nil
+	}
 	t, err := parseDateTime(val)
 	if err != nil {
 		return nil, err
@@ -10727,6 +11600,9 @@ func evalWeekOfYear(env ExecEnv, args []Expr, row Row) (any, error) {
 	if err != nil {
 		return nil, err
 	}
+	if val == nil {
+		return nil, nil
+	}
 	t, err := parseDateTime(val)
 	if err != nil {
 		return nil, err
@@ -10743,6 +11619,9 @@ func evalQuarter(env ExecEnv, args []Expr, row Row) (any, error) {
 	if err != nil {
 		return nil, err
 	}
+	if val == nil {
+		return nil, nil
+	}
 	t, err := parseDateTime(val)
 	if err != nil {
 		return nil, err
@@ -10766,6 +11645,9 @@ func evalDateAdd(env ExecEnv, args []Expr, row Row) (any, error) {
 	if err != nil {
 		return nil, err
 	}
+	if dateVal == nil || intervalVal == nil {
+		return nil, nil
+	}
 
 	t, err := parseDateTime(dateVal)
 	if err != nil {
@@ -10813,6 +11695,9 @@ func evalDateSub(env ExecEnv, args []Expr, row Row) (any, error) {
 	if err != nil {
 		return nil, err
 	}
+	if dateVal == nil || intervalVal == nil {
+		return nil, nil
+	}
 
 	t, err := parseDateTime(dateVal)
 	if err != nil {
@@ -10870,14 +11755,26 @@ func evalCast(env ExecEnv, args []Expr, row Row) (any, error) {
 	return castValue(val, strings.ToUpper(typeExpr.Name))
 }
 
+// castValue converts val to the SQL type named by targetType. Besides the
+// common spellings handled here, every column type name accepted by CREATE
+// TABLE is valid and converts like a value inserted into such a column.
+// VARCHAR(n) and CHAR(n) truncate the text to n characters.
 func castValue(val any, targetType string) (any, error) {
 	if val == nil {
 		return nil, nil
 	}
 
-	switch targetType {
+	base, length, err := splitCastType(targetType)
+	if err != nil {
+		return nil, err
+	}
+	switch base {
 	case "TEXT", "STRING", "VARCHAR", "CHAR":
-		return fmt.Sprintf("%v", val), nil
+		s := fmt.Sprintf("%v", val)
+		if length >= 0 && utf8.RuneCountInString(s) > length {
+			s = string([]rune(s)[:length])
+		}
+		return s, nil
 	case "INT", "INTEGER":
 		return coerceToInt(val)
 	case "FLOAT", "REAL", "DOUBLE", "NUMERIC":
@@ -10888,19 +11785,57 @@ func castValue(val any, targetType string) (any, error) {
 		return strconv.ParseFloat(str, 64)
 	case "BOOL", "BOOLEAN":
 		switch v := val.(type) {
-		case bool:
-			return v, nil
-		case int:
-			return v != 0, nil
-		case float64:
-			return v != 0, nil
 		case string:
-			return strings.ToLower(v) == "true" || v == "1", nil
+			switch strings.ToLower(strings.TrimSpace(v)) {
+			case "true", "t", "yes", "1":
+				return true, nil
+			case "false", "f", "no", "0":
+				return false, nil
+			}
+			return nil, fmt.Errorf("cannot convert %q to BOOL", v)
 		}
-		return false, nil
-	default:
+		return coerceToBool(val)
+	}
+	typ, ok := typeKeywordMap[base]
+	if !ok {
 		return nil, fmt.Errorf("unsupported cast type: %s", targetType)
 	}
+	return coerceToTypeAllowNull(val, castTypeFamily(typ))
+}
+
+// splitCastType splits a CAST type such as "VARCHAR(10)" into its upper-case
+// name and length, which is -1 when absent.
+func splitCastType(targetType string) (string, int, error) {
+	base, args, ok := strings.Cut(targetType, "(")
+	if !ok {
+		return strings.ToUpper(strings.TrimSpace(base)), -1, nil
+	}
+	base = strings.ToUpper(strings.TrimSpace(base))
+	args = strings.TrimSuffix(strings.TrimSpace(args), ")")
+	if base != "VARCHAR" && base != "CHAR" {
+		// Precision and scale, as in DECIMAL(10,2), are schema decoration.
+		return base, -1, nil
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(args))
+	if err != nil || n < 0 {
+		return "", 0, fmt.Errorf("invalid length in CAST type %s", targetType)
+	}
+	return base, n, nil
+}
+
+// castTypeFamily maps sized numeric and string types onto the type whose
+// coercion they share.
+func castTypeFamily(t storage.ColType) storage.ColType {
+	switch t {
+	case storage.Int8Type, storage.Int16Type, storage.Int32Type, storage.Int64Type,
+		storage.UintType, storage.Uint8Type, storage.Uint16Type, storage.Uint32Type, storage.Uint64Type:
+		return storage.IntType
+	case storage.Float32Type, storage.Float64Type:
+		return storage.FloatType
+	case storage.StringType:
+		return storage.TextType
+	}
+	return t
 }
 
 // Hashing functions - MD5, SHA1, SHA256, SHA512
@@ -11062,7 +11997,7 @@ func isAggregate(e Expr) bool {
 	case *FuncCall:
 		switch ex.Name {
 		case "COUNT", "SUM", "AVG", "MIN", "MAX", "MEDIAN",
-			"MIN_BY", "MAX_BY", "ARG_MIN", "ARG_MAX":
+			"MIN_BY", "MAX_BY", "ARG_MIN", "ARG_MAX", "JSON_ARRAYAGG":
 			return true
 		}
 	case *Unary:
@@ -11123,6 +12058,8 @@ func evalAggregateFuncCall(env ExecEnv, ex *FuncCall, rows []Row) (any, error) {
 		return evalAggregateMaxBy(env, ex, rows)
 	case "VEC_AVG":
 		return evalAggregateVecAvg(env, ex, rows)
+	case "JSON_ARRAYAGG":
+		return evalAggregateJSONArrayAgg(env, ex, rows)
 	default:
 		// For non-aggregate functions like DATEDIFF, LEFT, etc., evaluate their arguments
 		// in the aggregate context first, then call the function
@@ -11162,7 +12099,7 @@ func evalAggregateCount(env ExecEnv, ex *FuncCall, rows []Row) (any, error) {
 
 	// Handle COUNT(DISTINCT col)
 	if ex.Distinct {
-		seen := make(map[string]bool)
+		seen := make(map[string]struct{})
 		for _, r := range rows {
 			if err := checkCtx(env.ctx); err != nil {
 				return nil, err
@@ -11172,9 +12109,7 @@ func evalAggregateCount(env ExecEnv, ex *FuncCall, rows []Row) (any, error) {
 				return nil, err
 			}
 			if v != nil {
-				// Convert to string for deduplication
-				key := fmt.Sprintf("%v", v)
-				seen[key] = true
+				seen[fmtKeyPart(v)] = struct{}{}
 			}
 		}
 		return len(seen), nil
@@ -11207,6 +12142,11 @@ func evalAggregateSumAvg(env ExecEnv, ex *FuncCall, rows []Row) (any, error) {
 		useRat   bool
 		n        int
 	)
+	// SUM(DISTINCT col) / AVG(DISTINCT col) add each distinct value once.
+	var seen map[string]struct{}
+	if ex.Distinct {
+		seen = make(map[string]struct{})
+	}
 	for _, r := range rows {
 		if err := checkCtx(env.ctx); err != nil {
 			return nil, err
@@ -11218,6 +12158,13 @@ func evalAggregateSumAvg(env ExecEnv, ex *FuncCall, rows []Row) (any, error) {
 		if v == nil {
 			continue
 		}
+		if seen != nil {
+			key := fmtKeyPart(v)
+			if _, dup := seen[key]; dup {
+				continue
+			}
+			seen[key] = struct{}{}
+		}
 		if f, ok := numeric(v); ok {
 			if useRat {
 				sumRat.Add(sumRat, new(big.Rat).SetFloat64(f))
@@ -11678,6 +12625,17 @@ func cloneRow(r Row) Row {
 	}
 	return m
 }
+
+// addLeftNulls is the counterpart of addRightNulls for RIGHT and FULL joins:
+// it sets every left-side key that the right row does not also carry to NULL.
+func addLeftNulls(m Row, leftKeys []string) {
+	for _, k := range leftKeys {
+		if _, ex := m[k]; !ex {
+			m[k] = nil
+		}
+	}
+}
+
 func addRightNulls(m Row, alias string, t *storage.Table) {
 	for _, c := range t.Cols {
 		putVal(m, alias+"."+c.Name, nil)
@@ -11842,6 +12800,8 @@ func coerceToTypeAllowNull(v any, t storage.ColType) (any, error) {
 		return coerceToVector(v)
 	case storage.BlobType:
 		return coerceToBlob(v)
+	case storage.DateType, storage.DateTimeType, storage.TimestampType:
+		return coerceToTime(v, t)
 	default:
 		return v, nil
 	}
@@ -11948,6 +12908,25 @@ func coerceToBlob(v any) (any, error) {
 	return append([]byte(nil), b...), nil
 }
 
+// coerceToTime parses DATE, DATETIME and TIMESTAMP values into time.Time so
+// they compare and sort chronologically. DATE drops the time of day.
+func coerceToTime(v any, t storage.ColType) (any, error) {
+	switch v.(type) {
+	case time.Time, string:
+	default:
+		return nil, fmt.Errorf("cannot convert %T to %s", v, t)
+	}
+	tm, err := parseDateTime(v)
+	if err != nil {
+		return nil, err
+	}
+	if t == storage.DateType {
+		y, m, d := tm.Date()
+		tm = time.Date(y, m, d, 0, 0, 0, 0, tm.Location())
+	}
+	return tm, nil
+}
+
 func coerceToInt(v any) (any, error) {
 	switch x := v.(type) {
 	case int:
@@ -12317,83 +13296,91 @@ func hasWindowFunction(e Expr) bool {
 
 // ==================== Window Function Support ====================
 
-// extractWindowOffset extracts and validates offset from window function arguments
-func extractWindowOffset(env ExecEnv, args []Expr, row Row, defaultOffset int) (int, error) {
+// extractWindowOffset evaluates the optional offset argument of LAG/LEAD.
+// ok is false when the offset is NULL, which makes the function's result
+// NULL as well.
+func extractWindowOffset(env ExecEnv, args []Expr, row Row, defaultOffset int) (offset int, ok bool, err error) {
 	if len(args) <= 1 {
-		return defaultOffset, nil
+		return defaultOffset, true, nil
 	}
 	offsetVal, err := evalExpr(env, args[1], row)
-	if err != nil {
-		return 0, err
-	}
-	if offsetInt, ok := offsetVal.(int); ok {
-		return offsetInt, nil
+	if err != nil || offsetVal == nil {
+		return 0, false, err
 	}
-	if offsetFloat, ok := offsetVal.(float64); ok {
-		return int(offsetFloat), nil
+	offset, err = toInt(offsetVal)
+	if err != nil {
+		return 0, false, fmt.Errorf("offset: %w", err)
 	}
-	return defaultOffset, nil
+	return offset, true, nil
 }
 
 // evalLagFunction evaluates the LAG window function
 func evalLagFunction(env ExecEnv, ex *FuncCall, partitionRows []Row, currentIdx int, row Row) (any, error) {
-	offset, err := extractWindowOffset(env, ex.Args, row, 1)
-	if err != nil {
-		return nil, err
-	}
-	lagIdx := currentIdx - offset
-	if lagIdx < 0 || lagIdx >= len(partitionRows) {
-		// Return default value if provided
-		if len(ex.Args) > 2 {
-			return evalExpr(env, ex.Args[2], row)
-		}
-		return nil, nil
-	}
-	return evalExpr(env, ex.Args[0], partitionRows[lagIdx])
+	return evalLagLead(env, ex, partitionRows, currentIdx, row, -1)
 }
 
 // evalLeadFunction evaluates the LEAD window function
 func evalLeadFunction(env ExecEnv, ex *FuncCall, partitionRows []Row, currentIdx int, row Row) (any, error) {
-	offset, err := extractWindowOffset(env, ex.Args, row, 1)
-	if err != nil {
+	return evalLagLead(env, ex, partitionRows, currentIdx, row, 1)
+}
+
+// evalLagLead returns expr evaluated offset rows before (dir -1) or after
+// (dir 1) the current row, or the default (NULL unless given) when that
+// position falls outside the partition.
+func evalLagLead(env ExecEnv, ex *FuncCall, partitionRows []Row, currentIdx int, row Row, dir int) (any, error) {
+	if err := requireArgs(ex.Name, ex, 1, 3); err != nil {
 		return nil, err
 	}
-	leadIdx := currentIdx + offset
-	if leadIdx < 0 || leadIdx >= len(partitionRows) {
+	offset, ok, err := extractWindowOffset(env, ex.Args, row, 1)
+	if err != nil {
+		return nil, fmt.Errorf("%s %w", ex.Name, err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	idx := currentIdx + dir*offset
+	if idx < 0 || idx >= len(partitionRows) {
 		// Return default value if provided
 		if len(ex.Args) > 2 {
 			return evalExpr(env, ex.Args[2], row)
 		}
 		return nil, nil
 	}
-	return evalExpr(env, ex.Args[0], partitionRows[leadIdx])
+	return evalExpr(env, ex.Args[0], partitionRows[idx])
 }
 
 // evalFirstValue evaluates the FIRST_VALUE window function
-func evalFirstValue(env ExecEnv, ex *FuncCall, partitionRows []Row) (any, error) {
-	if len(ex.Args) == 0 {
-		return nil, fmt.Errorf("FIRST_VALUE requires an argument")
-	}
-	if len(partitionRows) == 0 {
-		return nil, nil
-	}
-	return evalExpr(env, ex.Args[0], partitionRows[0])
+func evalFirstValue(env ExecEnv, ex *FuncCall, partitionRows []Row, currentIdx int) (any, error) {
+	return evalFrameEdgeValue(env, ex, partitionRows, currentIdx, false)
 }
 
 // evalLastValue evaluates the LAST_VALUE window function
 func evalLastValue(env ExecEnv, ex *FuncCall, partitionRows []Row, currentIdx int) (any, error) {
+	return evalFrameEdgeValue(env, ex, partitionRows, currentIdx, true)
+}
+
+// evalFrameEdgeValue evaluates the argument of FIRST_VALUE (last=false) or
+// LAST_VALUE (last=true) on the first or last row of the current row's
+// window frame. Without a frame clause the frame is the whole partition. An
+// empty frame yields NULL.
+func evalFrameEdgeValue(env ExecEnv, ex *FuncCall, partitionRows []Row, currentIdx int, last bool) (any, error) {
 	if len(ex.Args) == 0 {
-		return nil, fmt.Errorf("LAST_VALUE requires an argument")
+		return nil, fmt.Errorf("%s requires an argument", ex.Name)
 	}
 	if len(partitionRows) == 0 {
 		return nil, nil
 	}
-	// Use frame end if specified
-	endIdx := len(partitionRows) - 1
-	if ex.Over.Frame != nil {
-		endIdx = calculateFrameEnd(currentIdx, len(partitionRows), ex.Over.Frame)
+	start, end, err := windowFrameBounds(partitionRows, currentIdx, ex.Over.OrderBy, ex.Over.Frame)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ex.Name, err)
+	}
+	if start > end {
+		return nil, nil
+	}
+	if last {
+		return evalExpr(env, ex.Args[0], partitionRows[end])
 	}
-	return evalExpr(env, ex.Args[0], partitionRows[endIdx])
+	return evalExpr(env, ex.Args[0], partitionRows[start])
 }
 
 // evalMovingAggregate evaluates MOVING_SUM and MOVING_AVG window functions
@@ -12466,6 +13453,10 @@ func evalWindowFunction(env ExecEnv, ex *FuncCall, row Row) (any, error) {
 		return nil, fmt.Errorf("window function %s requires OVER clause", ex.Name)
 	}
 
+	if vals, ok := env.windowValues[ex]; ok && env.windowIndex < len(vals) {
+		return vals[env.windowIndex], nil
+	}
+
 	// Get all rows for this window
 	allRows := env.windowRows
 	if allRows == nil {
@@ -12485,8 +13476,12 @@ func evalWindowFunction(env ExecEnv, ex *FuncCall, row Row) (any, error) {
 
 	// Find current row position in partition
 	currentIdx := findRowIndex(partitionRows, row, env.windowIndex)
+	return evalWindowAt(env, ex, partitionRows, currentIdx, row)
+}
 
-	// Evaluate the specific window function
+// evalWindowAt evaluates window function ex for the row at currentIdx of an
+// already partitioned and ordered row set.
+func evalWindowAt(env ExecEnv, ex *FuncCall, partitionRows []Row, currentIdx int, row Row) (any, error) {
 	switch ex.Name {
 	case "ROW_NUMBER":
 		return currentIdx + 1, nil
@@ -12505,7 +13500,7 @@ func evalWindowFunction(env ExecEnv, ex *FuncCall, row Row) (any, error) {
 	case "LEAD":
 		return evalLeadFunction(env, ex, partitionRows, currentIdx, row)
 	case "FIRST_VALUE":
-		return evalFirstValue(env, ex, partitionRows)
+		return evalFirstValue(env, ex, partitionRows, currentIdx)
 	case "LAST_VALUE":
 		return evalLastValue(env, ex, partitionRows, currentIdx)
 	case "MOVING_SUM", "MOVING_AVG":
@@ -12561,10 +13556,11 @@ func evalDenseRankFunction(partitionRows []Row, currentIdx int, orderBy []OrderI
 }
 
 // evalPercentRank computes SQL PERCENT_RANK(): (RANK - 1) / (partition size - 1),
-// or 0 when the partition has only one row.
+// or 0 when the partition has only one row. Without ORDER BY every row is a
+// peer of every other, so all rows rank first and the result is 0.
 func evalPercentRank(partitionRows []Row, currentIdx int, orderBy []OrderItem) float64 {
 	total := len(partitionRows)
-	if total <= 1 {
+	if total <= 1 || len(orderBy) == 0 {
 		return 0
 	}
 	rank := evalRankFunction(partitionRows, currentIdx, orderBy)
@@ -12711,31 +13707,3 @@ func rowsEqual(a, b Row) bool {
 	}
 	return true
 }
-
-// calculateFrameEnd calculates the end index for frame-based window functions
-func calculateFrameEnd(currentIdx, totalRows int, frame *WindowFrame) int {
-	if frame == nil {
-		return currentIdx // Default: CURRENT ROW
-	}
-
-	switch frame.EndType {
-	case "CURRENT":
-		return currentIdx
-	case "UNBOUNDED_FOLLOWING":
-		return totalRows - 1
-	case "OFFSET_FOLLOWING":
-		endIdx := currentIdx + frame.EndValue
-		if endIdx >= totalRows {
-			return totalRows - 1
-		}
-		return endIdx
-	case "OFFSET_PRECEDING":
-		endIdx := currentIdx - frame.EndValue
-		if endIdx < 0 {
-			return 0
-		}
-		return endIdx
-	default:
-		return currentIdx
-	}
-}
@@ -16,11 +16,20 @@ func executeCreateTable(env ExecEnv, s *CreateTable) (*ResultSet, error) {
 			return nil, nil
 		}
 	}
+	if isCatalogViewSource(env, s.Name) {
+		if s.IfNotExists {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cannot create table %q: a view with that name already exists", s.Name)
+	}
 	if s.VirtualTable && s.Using == "fts" {
 		return executeCreateFTSTable(env, s)
 	}
 	if s.AsSelect == nil {
-		return nil, env.db.Put(env.tenant, storage.NewTable(s.Name, s.Cols, s.IsTemp))
+		t := storage.NewTable(s.Name, s.Cols, s.IsTemp)
+		t.Uniques = s.Uniques
+		t.Checks = s.Checks
+		return nil, env.db.Put(env.tenant, t)
 	}
 	rs, err := execStmt(env, s.AsSelect)
 	if err != nil {
@@ -68,6 +77,49 @@ func executeDropTable(env ExecEnv, s *DropTable) (*ResultSet, error) {
 	return nil, env.db.Drop(env.tenant, s.Name)
 }
 
+// executeTruncateTable empties a table without evaluating or triggering on
+// individual rows. A table that other tables reference through a FOREIGN KEY
+// is refused rather than cascaded.
+func executeTruncateTable(env ExecEnv, s *TruncateTable) (*ResultSet, error) {
+	t, err := env.db.Get(env.tenant, s.Name)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range env.db.ListTables(env.tenant) {
+		if child == t {
+			continue
+		}
+		for _, c := range child.Cols {
+			if c.Constraint == storage.ForeignKey && c.ForeignKey != nil && strings.EqualFold(c.ForeignKey.Table, t.Name) {
+				return nil, fmt.Errorf("cannot truncate table %q: it is referenced by FOREIGN KEY %s.%s", t.Name, child.Name, c.Name)
+			}
+		}
+	}
+	if len(t.Rows) == 0 {
+		return nil, nil
+	}
+	wal, err := beginWALAuto(env, s.Name)
+	if err != nil {
+		return nil, err
+	}
+	for i, r := range t.Rows {
+		if err := wal.logDelete(env, i, r, t.Cols); err != nil {
+			return nil, err
+		}
+	}
+	if err := wal.commit(); err != nil {
+		return nil, err
+	}
+	invalidateConstraintIndexes(t)
+	t.Rows = nil
+	t.Version++
+	t.ClearSecondaryIndexes()
+	t.InvalidateStats()
+	t.MarkDirtyFrom(-1)
+	markDependentMaterializedViewsStale(env, s.Name)
+	return nil, nil
+}
+
 func executeCreateIndex(env ExecEnv, s *CreateIndex) (*ResultSet, error) {
 	schema, name := splitObjectName(s.Name)
 	if _, exists := env.db.Catalog().GetIndexForTenant(env.tenant, schema, name); exists {
@@ -22,6 +22,10 @@ func execStmt(env ExecEnv, stmt Statement) (*ResultSet, error) {
 		return executeAnalyze(env, s)
 	case *Pragma:
 		return executePragma(env, s)
+	case *ShowTables:
+		return executeShowTables(env, s)
+	case *ShowColumns:
+		return executeShowColumns(env, s)
 	case *CreateTable:
 		return executeCreateTable(env, s)
 	case *DropTable:
@@ -48,14 +52,24 @@ func execStmt(env ExecEnv, stmt Statement) (*ResultSet, error) {
 		return executeAlterTable(env, s)
 	case *Insert:
 		return executeInsert(env, s)
+	case *CopyFrom:
+		return executeCopyFrom(env, s)
 	case *Update:
 		return executeUpdate(env, s)
 	case *Delete:
 		return executeDelete(env, s)
+	case *TruncateTable:
+		return executeTruncateTable(env, s)
+	case *SavepointStmt, *RollbackToStmt, *ReleaseSavepointStmt:
+		return nil, fmt.Errorf("savepoints require a transaction opened through the database/sql driver")
 	case *CallProcedure:
 		return executeCallProcedure(env, s)
 	case *Select:
 		return executeSelect(env, s)
+	case *CreateSequence:
+		return executeCreateSequence(env, s)
+	case *DropSequence:
+		return executeDropSequence(env, s)
 	case *CreateJob:
 		return executeCreateJob(env, s)
 	case *AlterJob:
@@ -90,7 +104,7 @@ func execStmt(env ExecEnv, stmt Statement) (*ResultSet, error) {
 
 func isReadOnlyStatement(stmt Statement) bool {
 	switch s := stmt.(type) {
-	case *Select, *Pragma:
+	case *Select, *Pragma, *ShowTables, *ShowColumns:
 		return true
 	case *Explain:
 		// Plain EXPLAIN only inspects the statement. EXPLAIN ANALYZE executes
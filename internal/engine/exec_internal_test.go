@@ -73,7 +73,7 @@ func TestEvalJSONExtended(t *testing.T) {
 	arrayAny, err := evalFuncCall(env, &FuncCall{
 		Name: "JSON_SET",
 		Args: []Expr{
-			&Literal{Val: nil},
+			&Literal{Val: []any{}},
 			&Literal{Val: "[2]"},
 			&Literal{Val: "foo"},
 		},
@@ -3,6 +3,7 @@ package engine
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/SimonWaldherr/tinySQL/internal/storage"
@@ -13,13 +14,30 @@ import (
 // finalization. Keeping it separate from execStmt lets statement handlers
 // focus exclusively on their SQL semantics.
 func executeStatement(ctx context.Context, db *storage.DB, tenant string, stmt Statement) (rs *ResultSet, err error) {
+	// Opened first and closed last, so the span covers locking and
+	// rollback and sees the final error.
+	ctx, span := startStatementSpan(ctx, tenant, stmt)
+	defer func() { endStatementSpan(span, rs, err) }()
+	started := time.Now()
+	defer func() { logStatement(ctx, tenant, stmt, started, rs, err) }()
+
 	if err := checkPermission(ctx, db, stmt); err != nil {
 		recordAudit(ctx, db, tenant, stmt, err)
 		return nil, err
 	}
-	if isReadOnlyStatement(stmt) {
+	// REFRESH MATERIALIZED VIEW only reads until its final cache swap, so it
+	// runs under the read lock and applies that swap under the write lock
+	// afterwards; concurrent queries keep seeing the old contents meanwhile.
+	_, refresh := stmt.(*RefreshMaterializedView)
+	var deferredWrite func() error
+	contentLocked := true
+	if isReadOnlyStatement(stmt) || refresh {
 		db.LockContentForRead()
-		defer db.UnlockContentForRead()
+		defer func() {
+			if contentLocked {
+				db.UnlockContentForRead()
+			}
+		}()
 	} else {
 		db.LockContentForWrite()
 		defer db.UnlockContentForWrite()
@@ -60,8 +78,45 @@ func executeStatement(ctx context.Context, db *storage.DB, tenant string, stmt S
 		}
 	}()
 
+	profile := queryProfileFromContext(ctx)
+	if span != nil {
+		if profile == nil {
+			profile = &queryProfile{}
+		}
+		profile.tracer, profile.spanCtx = tracerFromContext(ctx), ctx
+	}
+
 	statementWAL := newStatementWAL(db.AdvancedWAL())
-	rs, err = execStmt(ExecEnv{ctx: ctx, tenant: tenant, db: db, statementWAL: statementWAL, now: time.Now()}, stmt)
+	env := ExecEnv{
+		ctx:          ctx,
+		tenant:       tenant,
+		db:           db,
+		statementWAL: statementWAL,
+		now:          time.Now(),
+		profile:      profile,
+		limits:       queryOptionsFromContext(ctx),
+	}
+	env.userID, _ = UserFromContext(ctx)
+	env.copyInput = copyInputFromContext(ctx)
+	if refresh {
+		env.deferredWrite = &deferredWrite
+	}
+	rs, err = execStmt(env, stmt)
+	if deferredWrite != nil {
+		db.UnlockContentForRead()
+		contentLocked = false
+		db.LockContentForWrite()
+		writeErr := deferredWrite()
+		db.UnlockContentForWrite()
+		if err == nil {
+			err = writeErr
+		}
+	}
+	if err == nil {
+		if err = checkResultLimits(env, rs); err != nil {
+			rs = nil
+		}
+	}
 	if err == nil {
 		err = statementWAL.commit()
 	}
@@ -77,7 +132,9 @@ func executeStatement(ctx context.Context, db *storage.DB, tenant string, stmt S
 // snapshot. The same is true for every trigger-capable statement.
 func appendOnlySnapshotTarget(db *storage.DB, tenant string, stmt Statement) (string, bool) {
 	s, ok := stmt.(*Insert)
-	if !ok || db.WAL() != nil {
+	if !ok || s.OnConflict != nil || db.WAL() != nil {
+		// An upsert rewrites existing rows in place, which truncation
+		// cannot undo.
 		return "", false
 	}
 	catalog := db.Catalog()
@@ -107,6 +164,15 @@ func tableScopedSnapshotTarget(db *storage.DB, tenant string, stmt Statement) (s
 	var event storage.TriggerEvent
 	switch s := stmt.(type) {
 	case *Insert:
+		if s.OnConflict != nil {
+			// DO UPDATE fires UPDATE triggers as well as INSERT triggers.
+			before, after := db.Catalog().GetTriggersForEvent(s.Table, storage.TriggerUpdate)
+			if len(before) > 0 || len(after) > 0 {
+				return "", false
+			}
+		}
+		table, event = s.Table, storage.TriggerEvent("INSERT")
+	case *CopyFrom:
 		table, event = s.Table, storage.TriggerEvent("INSERT")
 	case *Update:
 		if tenantHasAnyForeignKeys(ExecEnv{tenant: tenant, db: db}) {
@@ -131,7 +197,7 @@ func tableScopedSnapshotTarget(db *storage.DB, tenant string, stmt Statement) (s
 
 func isAtomicDML(stmt Statement) bool {
 	switch s := stmt.(type) {
-	case *Insert, *Update, *Delete:
+	case *Insert, *Update, *Delete, *CopyFrom:
 		return true
 	case *Explain:
 		// EXPLAIN ANALYZE executes its inner statement in the outer statement
@@ -159,3 +225,29 @@ func recordAudit(ctx context.Context, db *storage.DB, tenant string, stmt Statem
 	}
 	log.Append(tenant, user, text, err == nil, errMsg)
 }
+
+// logStatement writes a debug line per statement to the default slog logger:
+// the SQL text when the caller attached it (WithAuditText), else the
+// statement kind, plus tenant, duration and outcome. Result values are never
+// logged.
+func logStatement(ctx context.Context, tenant string, stmt Statement, started time.Time, rs *ResultSet, err error) {
+	logger := slog.Default()
+	if !logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	sqlText, ok := auditTextFromContext(ctx)
+	if !ok {
+		sqlText = statementName(stmt)
+	}
+	attrs := []slog.Attr{
+		slog.String("sql", sqlText),
+		slog.String("tenant", tenant),
+		slog.Duration("duration", time.Since(started)),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	} else if rs != nil {
+		attrs = append(attrs, slog.Int("rows", len(rs.Rows)))
+	}
+	logger.LogAttrs(ctx, slog.LevelDebug, "statement executed", attrs...)
+}
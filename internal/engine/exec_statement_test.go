@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+func captureDefaultLog(t *testing.T, level slog.Level) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	orig := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: level})))
+	t.Cleanup(func() { slog.SetDefault(orig) })
+	return &buf
+}
+
+func TestExecuteLogsStatementsAtDebug(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE accounts (id INT, api_key TEXT)`)
+	execSQL(t, db, `INSERT INTO accounts VALUES (1, 'sk-very-secret')`)
+
+	buf := captureDefaultLog(t, slog.LevelDebug)
+	const query = `SELECT api_key FROM accounts WHERE id = 1`
+	ctx := WithAuditText(context.Background(), query)
+	if _, err := Execute(ctx, db, "default", mustParse(query)); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "sk-very-secret") {
+		t.Fatalf("result value leaked into the log: %s", buf.String())
+	}
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("log is not a single JSON line: %v\n%s", err, buf.String())
+	}
+	if line["level"] != "DEBUG" || line["msg"] != "statement executed" || line["sql"] != query ||
+		line["tenant"] != "default" || line["rows"] != float64(1) {
+		t.Fatalf("line = %v", line)
+	}
+	if _, ok := line["duration"]; !ok {
+		t.Fatalf("line has no duration: %v", line)
+	}
+
+	// Without attached text the statement kind stands in for the SQL.
+	buf.Reset()
+	if _, err := Execute(context.Background(), db, "default", mustParse(`DELETE FROM accounts`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil || line["sql"] != "DELETE" {
+		t.Fatalf("line = %v (%v)", line, err)
+	}
+}
+
+func TestExecuteStatementLogFilteredAboveDebug(t *testing.T) {
+	db := storage.NewDB()
+	buf := captureDefaultLog(t, slog.LevelInfo)
+	execSQL(t, db, `CREATE TABLE t (id INT)`)
+	if buf.Len() != 0 {
+		t.Fatalf("statement logged at info level: %s", buf.String())
+	}
+}
@@ -50,6 +50,17 @@ func explainStatement(env ExecEnv, rows *[]Row, stmt Statement) {
 		explainSelect(env, rows, q, "")
 	case *Insert:
 		addExplainStep(rows, "INSERT", q.Table)
+		if q.OnConflict != nil {
+			action := fmt.Sprintf("DO UPDATE %d column(s)", len(q.OnConflict.Sets))
+			if q.OnConflict.DoNothing {
+				action = "DO NOTHING"
+			}
+			target := "any unique key"
+			if len(q.OnConflict.Cols) > 0 {
+				target = "(" + strings.Join(q.OnConflict.Cols, ", ") + ")"
+			}
+			addExplainStep(rows, "ON CONFLICT", target+" "+action)
+		}
 		if len(q.Returning) > 0 {
 			addExplainStep(rows, "RETURNING", fmt.Sprintf("%d projection(s)", len(q.Returning)))
 		}
@@ -64,6 +75,14 @@ func explainStatement(env ExecEnv, rows *[]Row, stmt Statement) {
 		if q.Where != nil {
 			addExplainStep(rows, "FILTER", exprKind(q.Where))
 		}
+	case *TruncateTable:
+		addExplainStep(rows, "TRUNCATE", q.Name)
+	case *CopyFrom:
+		source := "STDIN"
+		if q.Source != "" {
+			source = q.Source
+		}
+		addExplainStep(rows, "COPY", q.Table+" FROM "+source)
 	case *CreateView:
 		addExplainStep(rows, "CREATE VIEW", q.Name)
 		explainSelect(env, rows, q.Select, "view ")
@@ -106,7 +125,14 @@ func explainSelect(env ExecEnv, rows *[]Row, sel *Select, prefix string) {
 			explainFrom(env, rows, "SCAN", sel.From, prefix)
 		}
 	}
-	for _, join := range sel.Joins {
+	joins := sel.Joins
+	if fromFilter, joinFilters, _ := planPredicatePushdown(env, sel); len(joins) > 1 {
+		var reordered bool
+		if joins, _, reordered = planJoinOrder(env, sel, fromFilter, joinFilters); reordered {
+			addExplainStep(rows, "JOIN ORDER", "statistics")
+		}
+	}
+	for _, join := range joins {
 		explainFrom(env, rows, join.Type.String(), join.Right, prefix)
 		if join.On != nil {
 			addExplainStep(rows, "JOIN FILTER", exprKind(join.On))
@@ -199,6 +225,10 @@ func statementName(stmt Statement) string {
 		return "UPDATE"
 	case *Delete:
 		return "DELETE"
+	case *TruncateTable:
+		return "TRUNCATE"
+	case *CopyFrom:
+		return "COPY"
 	case *Analyze:
 		return "ANALYZE"
 	case *CreateTable:
@@ -254,6 +284,9 @@ func exprKind(e Expr) string {
 		if ex.SimilarTo {
 			return "SIMILAR TO"
 		}
+		if ex.CaseInsensitive {
+			return "IREGEXP"
+		}
 		return "REGEXP"
 	case *BetweenExpr:
 		if ex.Negate {
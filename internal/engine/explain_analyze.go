@@ -0,0 +1,194 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+// AnalyzedStep is one operation of a statement executed by ExplainAnalyze.
+// EstimatedRows is the planner's guess for scans (the table's row count) and
+// the step's input row count for every other operation.
+type AnalyzedStep struct {
+	Operation     string
+	Object        string
+	EstimatedRows int
+	ActualRows    int
+	ElapsedMs     float64
+	Details       string
+}
+
+// AnalyzedPlan is the measured plan of one executed statement.
+type AnalyzedPlan struct {
+	Steps []AnalyzedStep
+	// ActualRows is the number of rows returned, or affected by DML.
+	ActualRows int
+	// ElapsedMs is the wall time of the whole statement.
+	ElapsedMs float64
+}
+
+type queryProfileContextKey struct{}
+
+// queryProfile collects the steps of the statement's outermost SELECT for
+// ExplainAnalyze and, when the statement is traced, turns each into a span.
+// A nil profile records nothing, so the executor calls it unconditionally.
+type queryProfile struct {
+	steps   []AnalyzedStep
+	collect bool
+	// tracer and spanCtx are set for traced statements (see tracing.go).
+	tracer  trace.Tracer
+	spanCtx context.Context
+}
+
+func queryProfileFromContext(ctx context.Context) *queryProfile {
+	if ctx == nil {
+		return nil
+	}
+	p, _ := ctx.Value(queryProfileContextKey{}).(*queryProfile)
+	return p
+}
+
+func (p *queryProfile) record(op, object string, estimated, actual int, started time.Time, details string) {
+	if p == nil {
+		return
+	}
+	step := AnalyzedStep{
+		Operation:     op,
+		Object:        object,
+		EstimatedRows: estimated,
+		ActualRows:    actual,
+		ElapsedMs:     elapsedMs(started),
+		Details:       details,
+	}
+	if p.collect {
+		p.steps = append(p.steps, step)
+	}
+	if p.tracer != nil {
+		p.traceStep(step, started)
+	}
+}
+
+func elapsedMs(started time.Time) float64 {
+	return float64(time.Since(started).Nanoseconds()) / 1e6
+}
+
+// ExplainAnalyze executes stmt exactly like Execute — including its locking,
+// permission checks and any data changes — and reports the operations it
+// ran with their measured row counts and times.
+func ExplainAnalyze(ctx context.Context, db *storage.DB, tenant string, stmt Statement) (*AnalyzedPlan, error) {
+	prof := &queryProfile{collect: true}
+	if _, ok := stmt.(*Select); ok {
+		// DML is reported as one step; a subquery inside it must not
+		// masquerade as the statement's plan.
+		ctx = context.WithValue(ctx, queryProfileContextKey{}, prof)
+	}
+	started := time.Now()
+	rs, err := Execute(ctx, db, tenant, stmt)
+	if err != nil {
+		return nil, err
+	}
+	plan := &AnalyzedPlan{Steps: prof.steps, ActualRows: affectedRows(stmt, rs), ElapsedMs: elapsedMs(started)}
+	if len(plan.Steps) == 0 {
+		plan.Steps = []AnalyzedStep{{
+			Operation:  statementName(stmt),
+			Object:     statementObject(stmt),
+			ActualRows: plan.ActualRows,
+			ElapsedMs:  plan.ElapsedMs,
+		}}
+	}
+	return plan, nil
+}
+
+// affectedRows reads the count DML reports ("updated", "deleted") instead of
+// the length of its one-row summary.
+func affectedRows(stmt Statement, rs *ResultSet) int {
+	if rs == nil {
+		if ins, ok := stmt.(*Insert); ok {
+			return len(ins.Rows)
+		}
+		return 0
+	}
+	switch stmt.(type) {
+	case *Update, *Delete:
+		if len(rs.Rows) == 1 && len(rs.Cols) == 1 {
+			if n, ok := rs.Rows[0][rs.Cols[0]].(int); ok {
+				return n
+			}
+		}
+	}
+	return len(rs.Rows)
+}
+
+func statementObject(stmt Statement) string {
+	switch s := stmt.(type) {
+	case *Insert:
+		return s.Table
+	case *Update:
+		return s.Table
+	case *Delete:
+		return s.Table
+	}
+	return "-"
+}
+
+// fromObject names a FROM or JOIN source for a profile step.
+func fromObject(item FromItem) string {
+	switch {
+	case item.Subquery != nil:
+		return "subquery " + aliasOr(item)
+	case item.TableFunc != nil:
+		return "function " + item.TableFunc.Name
+	case item.Table == "":
+		return "-"
+	}
+	return item.Table
+}
+
+// tableRowEstimate is the stored row count of a FROM source, or 0 when the
+// source is not a table.
+func tableRowEstimate(env ExecEnv, item FromItem) int {
+	if item.Table == "" || item.Subquery != nil || item.TableFunc != nil {
+		return 0
+	}
+	if cte, ok := env.ctes[strings.ToLower(item.Table)]; ok {
+		return len(cte.Rows)
+	}
+	t, err := env.db.Get(env.tenant, item.Table)
+	if err != nil {
+		return 0
+	}
+	return len(t.Rows)
+}
+
+// joinMethod names how processJoins will evaluate one join step.
+func joinMethod(j JoinClause, left, right []Row) string {
+	if j.Type == JoinCross || j.On == nil {
+		return "NESTED LOOP"
+	}
+	if len(left) > 0 && len(right) > 0 && int64(len(left))*int64(len(right)) >= int64(hashJoinMinPairs) &&
+		len(equiJoinKeys(j.On, left[0], right[0])) > 0 {
+		return "HASH"
+	}
+	return "NESTED LOOP"
+}
+
+// recordFastPath describes a SELECT answered by one of the raw fast paths,
+// which run scan, filter and projection as a single operation.
+func (p *queryProfile) recordFastPath(env ExecEnv, op string, s *Select, rs *ResultSet, started time.Time) {
+	if p == nil || rs == nil {
+		return
+	}
+	object, details := fromObject(s.From), "raw fast path"
+	if len(s.Joins) == 1 {
+		object += ", " + fromObject(s.Joins[0].Right)
+	}
+	if s.Where != nil {
+		details += fmt.Sprintf(", filter %s", exprKind(s.Where))
+	}
+	p.record(op, object, tableRowEstimate(env, s.From), len(rs.Rows), started, details)
+}
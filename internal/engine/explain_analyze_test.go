@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+func explainAnalyzeTestDB(t *testing.T) *storage.DB {
+	t.Helper()
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE users (id INT, region TEXT)`)
+	execSQL(t, db, `CREATE TABLE orders (id INT, user_id INT, total INT)`)
+	for i := 1; i <= 40; i++ {
+		execSQL(t, db, fmt.Sprintf(`INSERT INTO users VALUES (%d, 'r%d')`, i, i%4))
+	}
+	for i := 1; i <= 100; i++ {
+		execSQL(t, db, fmt.Sprintf(`INSERT INTO orders VALUES (%d, %d, %d)`, i, i%50+1, i*10))
+	}
+	return db
+}
+
+func explainAnalyze(t *testing.T, db *storage.DB, sql string) *AnalyzedPlan {
+	t.Helper()
+	plan, err := ExplainAnalyze(context.Background(), db, "default", mustParse(sql))
+	if err != nil {
+		t.Fatalf("ExplainAnalyze(%s): %v", sql, err)
+	}
+	if plan.ElapsedMs <= 0 {
+		t.Errorf("%s: total time %v, want > 0", sql, plan.ElapsedMs)
+	}
+	return plan
+}
+
+// analyzedStep returns the first step whose operation is op.
+func analyzedStep(t *testing.T, plan *AnalyzedPlan, op string) AnalyzedStep {
+	t.Helper()
+	for _, s := range plan.Steps {
+		if s.Operation == op {
+			return s
+		}
+	}
+	t.Fatalf("no %s step in %+v", op, plan.Steps)
+	return AnalyzedStep{}
+}
+
+func TestExplainAnalyzeJoin(t *testing.T) {
+	db := explainAnalyzeTestDB(t)
+	sql := `SELECT u.id, o.total FROM users u JOIN orders o ON o.user_id = u.id WHERE o.total > 200 ORDER BY o.total`
+	plan := explainAnalyze(t, db, sql)
+	want := len(execSQL(t, db, sql).Rows)
+	if plan.ActualRows != want {
+		t.Fatalf("ActualRows = %d, want %d", plan.ActualRows, want)
+	}
+
+	scan := analyzedStep(t, plan, "SCAN")
+	if scan.Object != "users" || scan.EstimatedRows != 40 || scan.ActualRows != 40 {
+		t.Errorf("scan step = %+v", scan)
+	}
+	join := analyzedStep(t, plan, "HASH JOIN")
+	if join.Object != "orders" || join.ActualRows != want {
+		t.Errorf("join step = %+v, want %d rows (filter pushed into the join)", join, want)
+	}
+	if sort := analyzedStep(t, plan, "SORT"); sort.ActualRows != want {
+		t.Errorf("sort step = %+v", sort)
+	}
+	for _, s := range plan.Steps {
+		if s.ElapsedMs < 0 {
+			t.Errorf("step %+v has negative time", s)
+		}
+	}
+}
+
+func TestExplainAnalyzeGroupByOrderBy(t *testing.T) {
+	db := explainAnalyzeTestDB(t)
+	// Answered by the aggregate fast path in one operation.
+	plan := explainAnalyze(t, db, `SELECT region, COUNT(*) AS n FROM users WHERE id > 10 GROUP BY region ORDER BY region LIMIT 3`)
+	if plan.ActualRows != 3 {
+		t.Fatalf("ActualRows = %d, want 3", plan.ActualRows)
+	}
+	if agg := analyzedStep(t, plan, "AGGREGATE"); agg.Object != "users" || agg.EstimatedRows != 40 || agg.ActualRows != 3 {
+		t.Errorf("fast-path aggregate step = %+v", agg)
+	}
+
+	// A grouped join runs the general pipeline step by step.
+	plan = explainAnalyze(t, db, `SELECT u.region, SUM(o.total) AS s FROM users u JOIN orders o ON o.user_id = u.id GROUP BY u.region ORDER BY s DESC LIMIT 2`)
+	if plan.ActualRows != 2 {
+		t.Fatalf("ActualRows = %d, want 2", plan.ActualRows)
+	}
+	joined := len(execSQL(t, db, `SELECT o.id FROM users u JOIN orders o ON o.user_id = u.id ORDER BY o.id`).Rows)
+	if agg := analyzedStep(t, plan, "AGGREGATE"); agg.EstimatedRows != joined || agg.ActualRows != 4 {
+		t.Errorf("aggregate step = %+v, want %d rows in, 4 groups out", agg, joined)
+	}
+	// With a LIMIT the sort keeps only the top rows.
+	if sort := analyzedStep(t, plan, "SORT"); sort.EstimatedRows != 4 || sort.ActualRows != 2 {
+		t.Errorf("sort step = %+v", sort)
+	}
+	if limit := analyzedStep(t, plan, "LIMIT"); limit.ActualRows != 2 {
+		t.Errorf("limit step = %+v", limit)
+	}
+}
+
+func TestExplainAnalyzeDML(t *testing.T) {
+	db := explainAnalyzeTestDB(t)
+	plan := explainAnalyze(t, db, `UPDATE orders SET total = 0 WHERE user_id = 7`)
+	if plan.ActualRows != 2 || len(plan.Steps) != 1 || plan.Steps[0].Operation != "UPDATE" {
+		t.Fatalf("plan = %+v, want one UPDATE step affecting 2 rows", plan)
+	}
+	rs := execSQL(t, db, `SELECT COUNT(*) AS n FROM orders WHERE total = 0`)
+	if rs.Rows[0]["n"] != 2 {
+		t.Fatalf("UPDATE under ExplainAnalyze did not run: %v", rs.Rows)
+	}
+}
@@ -1,8 +1,11 @@
 package engine
 
 import (
+	"context"
 	"fmt"
 	"testing"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
 )
 
 func TestEvalExpr_LiteralsVarRefUnaryBinary(t *testing.T) {
@@ -80,3 +83,77 @@ func TestEvalExpr_FuncCallUnknownAndIsNull(t *testing.T) {
 		t.Fatalf("IS NULL failed: %v %v", v, err)
 	}
 }
+
+func TestCaseExpressionForms(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE c (id INT, grp TEXT, qty INT)`)
+	execSQL(t, db, `INSERT INTO c VALUES (1, 'a', 10)`)
+	execSQL(t, db, `INSERT INTO c VALUES (2, 'a', 20)`)
+	execSQL(t, db, `INSERT INTO c VALUES (3, 'b', NULL)`)
+
+	rs := execSQL(t, db, `SELECT id,
+		CASE id WHEN 1 THEN 'one' WHEN 2 THEN 'two' END AS simple,
+		CASE WHEN grp = 'a' AND qty > 15 THEN 'big' WHEN grp = 'a' OR qty IS NULL THEN 'other' END AS searched,
+		CASE qty WHEN 10 THEN 'ten' ELSE 'fallback' END AS fallback
+		FROM c ORDER BY id`)
+	want := []struct{ simple, searched, fallback any }{
+		{"one", "other", "ten"},
+		{"two", "big", "fallback"},
+		// No WHEN matches and there is no ELSE; a NULL operand never
+		// matches a simple CASE branch.
+		{nil, "other", "fallback"},
+	}
+	for i, w := range want {
+		r := rs.Rows[i]
+		if r["simple"] != w.simple || r["searched"] != w.searched || r["fallback"] != w.fallback {
+			t.Errorf("row %d = %v, want %+v", i, r, w)
+		}
+	}
+
+	rs = execSQL(t, db, `SELECT SUM(CASE WHEN qty > 15 THEN qty ELSE 1 END) AS s FROM c`)
+	expectInt(t, rs.Rows[0]["s"], 22, "SUM(CASE ...)")
+	rs = execSQL(t, db, `SELECT grp, SUM(CASE grp WHEN 'a' THEN qty ELSE 100 END) AS s FROM c GROUP BY grp ORDER BY grp`)
+	expectInt(t, rs.Rows[0]["s"], 30, "grouped SUM(CASE ...) for a")
+	expectInt(t, rs.Rows[1]["s"], 100, "grouped SUM(CASE ...) for b")
+}
+
+func TestCastExpression(t *testing.T) {
+	db := storage.NewDB()
+	cases := []struct {
+		expr string
+		want any
+	}{
+		{`CAST(3 AS FLOAT)`, 3.0},
+		{`CAST('42' AS INT)`, 42},
+		{`CAST(' 7 ' AS INTEGER)`, 7},
+		{`CAST(3.9 AS INT)`, 3},
+		{`CAST(-3.9 AS INT)`, -3},
+		{`CAST(7 AS INT64)`, 7},
+		{`CAST('true' AS BOOL)`, true},
+		{`CAST('false' AS BOOLEAN)`, false},
+		{`CAST('1' AS BOOL)`, true},
+		{`CAST('0' AS BOOL)`, false},
+		{`CAST(12 AS TEXT)`, "12"},
+		{`CAST('hello world' AS VARCHAR(5))`, "hello"},
+		{`CAST('héllo' AS CHAR(2))`, "hé"},
+		{`CAST('hi' AS VARCHAR(10))`, "hi"},
+		{`CAST(NULL AS INT)`, nil},
+		{`CAST(NULL AS VARCHAR(3))`, nil},
+	}
+	for _, c := range cases {
+		if got := queryScalar(t, db, c.expr); got != c.want {
+			t.Errorf("%s = %#v, want %#v", c.expr, got, c.want)
+		}
+	}
+
+	for _, expr := range []string{
+		`CAST('abc' AS INT)`,
+		`CAST('maybe' AS BOOL)`,
+		`CAST(1 AS NOSUCHTYPE)`,
+		`CAST('x' AS VARCHAR(-1))`,
+	} {
+		if _, err := Execute(context.Background(), db, "default", mustParse(`SELECT `+expr)); err == nil {
+			t.Errorf("%s: expected error", expr)
+		}
+	}
+}
@@ -62,6 +62,37 @@ func TestCountDistinct(t *testing.T) {
 	}
 }
 
+func TestDistinctAggregates(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE sales (region TEXT, amount INT)`)
+	execSQL(t, db, `INSERT INTO sales VALUES ('n', 10), ('n', 10), ('n', 20), ('s', 5), ('s', 5), ('s', NULL), (NULL, 7)`)
+
+	rs := execSQL(t, db, `SELECT COUNT(*) AS all_rows, COUNT(amount) AS non_null, COUNT(DISTINCT amount) AS d,
+		SUM(amount) AS total, SUM(DISTINCT amount) AS dtotal, AVG(DISTINCT amount) AS davg FROM sales`)
+	row := rs.Rows[0]
+	expectInt(t, row["all_rows"], 7, "COUNT(*)")
+	expectInt(t, row["non_null"], 6, "COUNT(amount)")
+	expectInt(t, row["d"], 4, "COUNT(DISTINCT amount)")
+	if row["total"] != 57.0 || row["dtotal"] != 42.0 || row["davg"] != 10.5 {
+		t.Fatalf("SUM/SUM DISTINCT/AVG DISTINCT = %v/%v/%v", row["total"], row["dtotal"], row["davg"])
+	}
+
+	// NULL is not a distinct value, so the NULL region is not counted.
+	rs = execSQL(t, db, `SELECT COUNT(DISTINCT region) AS n FROM sales`)
+	expectInt(t, rs.Rows[0]["n"], 2, "COUNT(DISTINCT region)")
+
+	rs = execSQL(t, db, `SELECT region, COUNT(DISTINCT amount) AS d, SUM(DISTINCT amount) AS s FROM sales
+		WHERE region IS NOT NULL GROUP BY region ORDER BY region`)
+	if len(rs.Rows) != 2 {
+		t.Fatalf("GROUP BY rows = %v", rs.Rows)
+	}
+	expectInt(t, rs.Rows[0]["d"], 2, "n distinct")
+	expectInt(t, rs.Rows[1]["d"], 1, "s distinct")
+	if rs.Rows[0]["s"] != 30.0 || rs.Rows[1]["s"] != 5.0 {
+		t.Fatalf("SUM(DISTINCT) per group = %v, %v", rs.Rows[0]["s"], rs.Rows[1]["s"])
+	}
+}
+
 func TestInOperator(t *testing.T) {
 	db := storage.NewDB()
 	ctx := context.Background()
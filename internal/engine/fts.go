@@ -254,6 +254,7 @@ func getFTSFunctions() map[string]funcHandler {
 		"BM25":           evalFTSRank, // alias
 		"MATCH":          evalFTSMatch,
 		"FTS_WORD_COUNT": evalFTSWordCount,
+		matchAgainstFunc: evalMatchAgainst,
 	}
 }
 
@@ -362,11 +363,27 @@ func ftsParseAnd(tokens []string, pos int) (*ftsQueryNode, int) {
 	return left, pos
 }
 
-// ftsParseUnary parses NOT and atoms.
+// ftsParseUnary parses NOT, the MySQL boolean-mode prefixes and atoms.
+// +atom must be present, which the implicit AND already requires, and
+// -atom must be absent like NOT atom. A bare + or - applies to the next
+// atom, so -"quoted phrase" works.
 func ftsParseUnary(tokens []string, pos int) (*ftsQueryNode, int) {
 	if pos >= len(tokens) {
 		return nil, pos
 	}
+	if tok := tokens[pos]; tok[0] == '+' || tok[0] == '-' {
+		var operand *ftsQueryNode
+		if len(tok) == 1 {
+			operand, pos = ftsParseAtom(tokens, pos+1)
+		} else {
+			operand, _ = ftsParseAtom([]string{tok[1:]}, 0)
+			pos++
+		}
+		if tok[0] == '-' {
+			return &ftsQueryNode{op: "NOT", operand: operand}, pos
+		}
+		return operand, pos
+	}
 	if strings.ToUpper(tokens[pos]) == "NOT" {
 		pos++
 		operand, newPos := ftsParseAtom(tokens, pos)
@@ -714,6 +731,7 @@ func purgeFTSCachesFor(tenant, table string) {
 		}
 	}
 	ftsDocCacheMu.Unlock()
+	purgeFullTextIndexesFor(tenant, table)
 }
 
 func ftsColsCacheKey(cols []int) string {
@@ -88,6 +88,60 @@ func TestFullJoinIsSynonymForFullOuterJoin(t *testing.T) {
 	}
 }
 
+func TestFullOuterJoinAllRowsMatch(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE l (id INT, a TEXT)`)
+	execSQL(t, db, `CREATE TABLE r (id INT, b TEXT)`)
+	execSQL(t, db, `INSERT INTO l VALUES (1, 'x'), (2, 'y')`)
+	execSQL(t, db, `INSERT INTO r VALUES (2, 'q'), (1, 'p')`)
+	rs := execSQL(t, db, `SELECT l.a AS a, r.b AS b FROM l FULL OUTER JOIN r ON l.id = r.id ORDER BY a`)
+	if len(rs.Rows) != 2 {
+		t.Fatalf("expected 2 matched rows without NULL padding, got %+v", rs.Rows)
+	}
+	if rs.Rows[0]["b"] != "p" || rs.Rows[1]["b"] != "q" {
+		t.Errorf("unexpected pairing: %+v", rs.Rows)
+	}
+}
+
+func TestFullOuterJoinChainedWithInnerJoin(t *testing.T) {
+	db := setupJoinDemoTables(t)
+	execSQL(t, db, `CREATE TABLE badge (emp_id INT, code TEXT)`)
+	execSQL(t, db, `INSERT INTO badge VALUES (1, 'A1'), (4, 'D4')`)
+	rs := execSQL(t, db, `SELECT dept.name AS dname, emp.name AS ename, badge.code AS code
+		FROM dept FULL OUTER JOIN emp ON dept.id = emp.dept_id
+		JOIN badge ON badge.emp_id = emp.id ORDER BY code`)
+	if len(rs.Rows) != 2 {
+		t.Fatalf("expected 2 rows, got %+v", rs.Rows)
+	}
+	if rs.Rows[0]["dname"] != "Engineering" || rs.Rows[0]["ename"] != "Alice" {
+		t.Errorf("matched row: %+v", rs.Rows[0])
+	}
+	// Dave only exists on the right of the FULL JOIN; his dept stays NULL.
+	if rs.Rows[1]["dname"] != nil || rs.Rows[1]["ename"] != "Dave" {
+		t.Errorf("right-only row: %+v", rs.Rows[1])
+	}
+}
+
+func TestOuterJoinWithEmptyLeftSideNullFillsLeftColumns(t *testing.T) {
+	db := setupJoinDemoTables(t)
+	execSQL(t, db, `CREATE TABLE nobody (id INT, label TEXT)`)
+	for _, q := range []string{
+		`SELECT nobody.label AS label, emp.name AS ename FROM nobody FULL OUTER JOIN emp ON nobody.id = emp.id`,
+		`SELECT label, emp.name AS ename FROM nobody RIGHT JOIN emp ON nobody.id = emp.id`,
+		`SELECT nobody.label AS label, emp.name AS ename FROM nobody JOIN dept ON dept.id = nobody.id FULL JOIN emp ON emp.id = nobody.id`,
+	} {
+		rs := execSQL(t, db, q)
+		if len(rs.Rows) != 4 {
+			t.Fatalf("%s: expected 4 rows, got %+v", q, rs.Rows)
+		}
+		for _, r := range rs.Rows {
+			if r["label"] != nil || r["ename"] == nil {
+				t.Errorf("%s: unexpected row %+v", q, r)
+			}
+		}
+	}
+}
+
 func TestCrossJoinProducesCartesianProduct(t *testing.T) {
 	db := storage.NewDB()
 	execSQL(t, db, `CREATE TABLE colors (name TEXT)`)
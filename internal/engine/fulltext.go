@@ -0,0 +1,350 @@
+// MySQL-style full-text search over columns declared FULLTEXT:
+//
+//	CREATE TABLE docs (id INT, body TEXT FULLTEXT)
+//	SELECT id, MATCH(body) AGAINST ('quick fox') AS score
+//	FROM docs WHERE MATCH(body) AGAINST ('quick fox') ORDER BY score DESC
+//
+// MATCH ... AGAINST returns a BM25 relevance score, 0 for rows that do not
+// match, so the same call filters in WHERE and ranks in ORDER BY. In the
+// default NATURAL LANGUAGE MODE a row matches when it contains any query
+// word; IN BOOLEAN MODE the query uses the FTS_MATCH syntax (implicit AND,
+// OR, NOT, "phrase", prefix*, +required and -excluded).
+//
+// The inverted index maps each token to the rows holding it. It is built on
+// first use from the same tokenized documents FTS_SEARCH caches, scores with
+// their corpus-wide IDF, and is rebuilt lazily once the table's Version
+// moves on, so INSERT, UPDATE and DELETE never maintain it eagerly.
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+// matchAgainstFunc is the FuncCall name MATCH ... AGAINST parses to. Its
+// arguments are the query, the mode literal and then the column references.
+const matchAgainstFunc = "MATCH_AGAINST"
+
+// parseMatchAgainst parses the AGAINST (...) tail of MATCH(cols) AGAINST.
+// The query is parsed below the comparison level so that a following
+// IN BOOLEAN MODE is not read as an IN list.
+func (p *Parser) parseMatchAgainst(cols []Expr) (Expr, error) {
+	if len(cols) == 0 {
+		return nil, p.errf("MATCH expects at least one column")
+	}
+	for _, c := range cols {
+		if _, ok := c.(*VarRef); !ok {
+			return nil, p.errf("MATCH expects column names")
+		}
+	}
+	p.next() // AGAINST
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	query, err := p.parseAddSub()
+	if err != nil {
+		return nil, err
+	}
+	mode := "NATURAL"
+	if p.cur.Typ == tKeyword && p.cur.Val == "IN" {
+		p.next()
+		words := []string{"BOOLEAN", "MODE"}
+		if upper(p.cur.Val) == "NATURAL" {
+			mode, words = "NATURAL", []string{"NATURAL", "LANGUAGE", "MODE"}
+		} else {
+			mode = "BOOLEAN"
+		}
+		for _, w := range words {
+			if (p.cur.Typ != tIdent && p.cur.Typ != tKeyword) || upper(p.cur.Val) != w {
+				return nil, p.errf("expected IN NATURAL LANGUAGE MODE or IN BOOLEAN MODE")
+			}
+			p.next()
+		}
+	}
+	if err := p.expectSymbol(")"); err != nil {
+		return nil, err
+	}
+	args := append([]Expr{query, &Literal{Val: mode}}, cols...)
+	return &FuncCall{Name: matchAgainstFunc, Args: args}, nil
+}
+
+// fullTextIndex is the inverted index over one table's FULLTEXT column set.
+type fullTextIndex struct {
+	table    *storage.Table
+	version  int
+	docs     ftsDocCacheEntry
+	idf      ftsIDFFunc
+	postings map[string][]int // token → ascending row indexes
+}
+
+type matchTargetKey struct {
+	db     *storage.DB
+	tenant string
+	cols   string
+}
+
+// fullTextCacheMaxEntries bounds both caches below like ftsDocCacheMaxEntries.
+const fullTextCacheMaxEntries = 256
+
+var (
+	fullTextMu      sync.RWMutex
+	fullTextIndexes = make(map[ftsDocCacheKey]*fullTextIndex)
+	// matchTargets remembers which table an unqualified MATCH column list
+	// resolved to, so a scan does not list every table once per row.
+	matchTargets = make(map[matchTargetKey]string)
+)
+
+// fullTextIndexFor returns the inverted index for cols of table, building
+// it when missing or stale.
+func fullTextIndexFor(tenant string, table *storage.Table, cols []int) *fullTextIndex {
+	key := ftsDocCacheKey{tenant: tenant, table: table.Name, cols: ftsColsCacheKey(cols)}
+	fullTextMu.RLock()
+	idx, ok := fullTextIndexes[key]
+	fullTextMu.RUnlock()
+	if ok && idx.table == table && idx.version == table.Version {
+		return idx
+	}
+
+	docs := getFTSDocCache(tenant, table, cols)
+	postings := make(map[string][]int, len(docs.docFreq))
+	for ri, doc := range docs.docs {
+		for term := range doc.freq {
+			postings[term] = append(postings[term], ri)
+		}
+	}
+	idx = &fullTextIndex{table: table, version: docs.version, docs: docs, idf: ftsIDFLookup(docs), postings: postings}
+	fullTextMu.Lock()
+	if _, exists := fullTextIndexes[key]; !exists {
+		evictOverCap(fullTextIndexes, fullTextCacheMaxEntries)
+	}
+	fullTextIndexes[key] = idx
+	fullTextMu.Unlock()
+	return idx
+}
+
+func purgeFullTextIndexesFor(tenant, table string) {
+	fullTextMu.Lock()
+	for k := range fullTextIndexes {
+		if k.tenant == tenant && k.table == table {
+			delete(fullTextIndexes, k)
+		}
+	}
+	fullTextMu.Unlock()
+}
+
+// mayMatch probes the index: it reports false when no indexed row can
+// satisfy node, letting every row skip tokenization.
+func (idx *fullTextIndex) mayMatch(node *ftsQueryNode) bool {
+	switch node.op {
+	case "TERM":
+		return len(idx.postings[node.term]) > 0
+	case "PHRASE":
+		for _, t := range node.phrase {
+			if len(idx.postings[t]) == 0 {
+				return false
+			}
+		}
+		return true
+	case "NATURAL":
+		for term := range ftsQueryTerms(node.left) {
+			if len(idx.postings[term]) > 0 {
+				return true
+			}
+		}
+		return false
+	case "AND":
+		return node.left != nil && node.right != nil && idx.mayMatch(node.left) && idx.mayMatch(node.right)
+	case "OR":
+		return (node.left != nil && idx.mayMatch(node.left)) || (node.right != nil && idx.mayMatch(node.right))
+	default: // PREFIX, NOT
+		return true
+	}
+}
+
+// resolveMatchTable finds the table whose FULLTEXT columns a MATCH call
+// names and returns the column positions. A qualifier that names a table
+// selects it; otherwise the columns must be FULLTEXT in exactly one table.
+func resolveMatchTable(env ExecEnv, tenant string, refs []*VarRef) (*storage.Table, []int, error) {
+	names := make([]string, len(refs))
+	qualifier := ""
+	for i, ref := range refs {
+		names[i] = varColumn(ref)
+		if dot := strings.LastIndexByte(ref.Name, '.'); dot >= 0 && i == 0 {
+			qualifier = ref.Name[:dot]
+		}
+	}
+	if qualifier != "" {
+		if t, err := env.db.Get(tenant, qualifier); err == nil {
+			cols, err := fullTextColumns(t, names)
+			return t, cols, err
+		}
+	}
+
+	key := matchTargetKey{db: env.db, tenant: tenant, cols: strings.Join(names, ",")}
+	fullTextMu.RLock()
+	name, ok := matchTargets[key]
+	fullTextMu.RUnlock()
+	if ok {
+		if t, err := env.db.Get(tenant, name); err == nil {
+			if cols, err := fullTextColumns(t, names); err == nil {
+				return t, cols, nil
+			}
+		}
+	}
+
+	var found *storage.Table
+	var cols []int
+	var firstErr error
+	for _, t := range env.db.ListTables(tenant) {
+		if !tableHasColumns(t, names) {
+			continue
+		}
+		c, err := fullTextColumns(t, names)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if found != nil {
+			return nil, nil, fmt.Errorf("MATCH: columns %s are FULLTEXT in both %s and %s; qualify them with the table name", key.cols, found.Name, t.Name)
+		}
+		found, cols = t, c
+	}
+	if found == nil {
+		if firstErr != nil {
+			return nil, nil, firstErr
+		}
+		return nil, nil, fmt.Errorf("MATCH: no table has FULLTEXT columns %s", key.cols)
+	}
+	fullTextMu.Lock()
+	if _, exists := matchTargets[key]; !exists {
+		evictOverCap(matchTargets, fullTextCacheMaxEntries)
+	}
+	matchTargets[key] = found.Name
+	fullTextMu.Unlock()
+	return found, cols, nil
+}
+
+func tableHasColumns(t *storage.Table, names []string) bool {
+	for _, name := range names {
+		if _, err := t.ColIndex(name); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func fullTextColumns(t *storage.Table, names []string) ([]int, error) {
+	cols := make([]int, len(names))
+	for i, name := range names {
+		pos, err := t.ColIndex(name)
+		if err != nil {
+			return nil, fmt.Errorf("MATCH: table %s has no column %s", t.Name, name)
+		}
+		if !t.Cols[pos].FullText {
+			return nil, fmt.Errorf("MATCH: column %s.%s has no full-text index; declare it TEXT FULLTEXT", t.Name, t.Cols[pos].Name)
+		}
+		cols[i] = pos
+	}
+	return cols, nil
+}
+
+// evalMatchAgainst scores the current row. The row's own column values are
+// tokenized; the index supplies the early reject and the corpus statistics.
+func evalMatchAgainst(env ExecEnv, ex *FuncCall, row Row) (any, error) {
+	if len(ex.Args) < 3 {
+		return nil, fmt.Errorf("MATCH ... AGAINST expects columns and a query")
+	}
+	refs := make([]*VarRef, len(ex.Args)-2)
+	for i, arg := range ex.Args[2:] {
+		ref, ok := arg.(*VarRef)
+		if !ok {
+			return nil, fmt.Errorf("MATCH expects column names")
+		}
+		refs[i] = ref
+	}
+	tenant := env.tenant
+	if tenant == "" {
+		tenant = "default"
+	}
+	table, cols, err := resolveMatchTable(env, tenant, refs)
+	if err != nil {
+		return nil, err
+	}
+	queryVal, err := evalExpr(env, ex.Args[0], row)
+	if err != nil || queryVal == nil {
+		return 0.0, err
+	}
+	var node *ftsQueryNode
+	if mode, _ := ex.Args[1].(*Literal); mode != nil && mode.Val == "BOOLEAN" {
+		node = ftsParseQuery(fmt.Sprint(queryVal))
+	} else {
+		node = ftsNaturalQuery(ftsTokenize(fmt.Sprint(queryVal)))
+	}
+	idx := fullTextIndexFor(tenant, table, cols)
+	if node == nil || !idx.mayMatch(node) {
+		return 0.0, nil
+	}
+
+	var sb strings.Builder
+	for _, ref := range refs {
+		v, err := evalExpr(env, ref, row)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			sb.WriteString(fmt.Sprint(v))
+			sb.WriteByte(' ')
+		}
+	}
+	tokens := ftsTokenize(sb.String())
+	freq := make(map[string]int, len(tokens))
+	for _, t := range tokens {
+		freq[t]++
+	}
+	if node.op == "NATURAL" {
+		if !ftsAnyTerm(node, freq) {
+			return 0.0, nil
+		}
+		node = node.left
+	} else if !ftsMatchNode(node, freq, tokens) {
+		return 0.0, nil
+	}
+	normDocLen := 1.0
+	if idx.docs.avgDocLen > 0 {
+		normDocLen = float64(len(tokens)) / idx.docs.avgDocLen
+	}
+	return ftsScoreNode(node, freq, normDocLen, idx.idf), nil
+}
+
+// ftsNaturalQuery wraps the query words in a NATURAL node whose left child
+// ANDs them, because ftsScoreNode sums AND branches: a row matching more of
+// the words ranks higher, while any single word is enough to match.
+func ftsNaturalQuery(terms []string) *ftsQueryNode {
+	var node *ftsQueryNode
+	for _, t := range terms {
+		term := &ftsQueryNode{op: "TERM", term: t}
+		if node == nil {
+			node = term
+		} else {
+			node = &ftsQueryNode{op: "AND", left: node, right: term}
+		}
+	}
+	if node == nil {
+		return nil
+	}
+	return &ftsQueryNode{op: "NATURAL", left: node}
+}
+
+func ftsAnyTerm(node *ftsQueryNode, freq map[string]int) bool {
+	for term := range ftsQueryTerms(node.left) {
+		if freq[term] > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+func newFullTextDB(t *testing.T) *storage.DB {
+	t.Helper()
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE articles (id INT, title TEXT FULLTEXT, body TEXT FULLTEXT, tag TEXT)`)
+	execSQL(t, db, `INSERT INTO articles VALUES
+		(1, 'Database tuning', 'Indexes make database queries fast', 'db'),
+		(2, 'Gardening', 'Tomatoes need sun and water', 'garden'),
+		(3, 'Database internals', 'A database engine stores rows; database pages hold the rows', 'db'),
+		(4, 'Cooking', 'Fresh tomatoes make a great sauce', 'food')`)
+	return db
+}
+
+func matchIDs(t *testing.T, db *storage.DB, sql string) string {
+	t.Helper()
+	rs := execSQL(t, db, sql)
+	ids := make([]string, len(rs.Rows))
+	for i, row := range rs.Rows {
+		ids[i] = fmt.Sprint(row["id"])
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestMatchAgainstFiltersAndRanks(t *testing.T) {
+	db := newFullTextDB(t)
+	if got := matchIDs(t, db, `SELECT id FROM articles WHERE MATCH(body) AGAINST ('tomatoes') ORDER BY id`); got != "2,4" {
+		t.Fatalf("single term = %s", got)
+	}
+	// Natural language mode matches any word; boolean mode ANDs them.
+	if got := matchIDs(t, db, `SELECT id FROM articles WHERE MATCH(body) AGAINST ('sauce queries') ORDER BY id`); got != "1,4" {
+		t.Fatalf("natural OR = %s", got)
+	}
+	if got := matchIDs(t, db, `SELECT id FROM articles WHERE MATCH(body) AGAINST ('fresh tomatoes' IN BOOLEAN MODE) ORDER BY id`); got != "4" {
+		t.Fatalf("boolean AND = %s", got)
+	}
+	if got := matchIDs(t, db, `SELECT id FROM articles WHERE MATCH(body) AGAINST ('sauce OR sun' IN BOOLEAN MODE) ORDER BY id`); got != "2,4" {
+		t.Fatalf("boolean OR = %s", got)
+	}
+	if got := matchIDs(t, db, `SELECT id FROM articles WHERE MATCH(body) AGAINST ('tomatoes NOT sauce' IN BOOLEAN MODE)`); got != "2" {
+		t.Fatalf("boolean NOT = %s", got)
+	}
+	// MySQL's +required and -excluded prefixes, also on phrases.
+	for query, want := range map[string]string{
+		`+tomatoes -sauce`:            "2",
+		`+tomatoes +sauce`:            "4",
+		`database -"engine stores"`:   "1",
+		`+database - "engine stores"`: "1",
+		`tomatoes -sun -sauce`:        "",
+	} {
+		sql := `SELECT id FROM articles WHERE MATCH(body) AGAINST ('` + query + `' IN BOOLEAN MODE) ORDER BY id`
+		if got := matchIDs(t, db, sql); got != want {
+			t.Errorf("boolean %q = %q, want %q", query, got, want)
+		}
+	}
+	// Two FULLTEXT columns searched together; more hits rank higher.
+	got := matchIDs(t, db, `SELECT id, MATCH(title, body) AGAINST ('database' IN NATURAL LANGUAGE MODE) AS score
+		FROM articles WHERE MATCH(title, body) AGAINST ('database') ORDER BY score DESC`)
+	if got != "3,1" {
+		t.Fatalf("relevance order = %s", got)
+	}
+	rs := execSQL(t, db, `SELECT MATCH(a.body) AGAINST ('gardening') AS score FROM articles a WHERE id = 2`)
+	if rs.Rows[0]["score"] != 0.0 {
+		t.Fatalf("non-matching score = %v", rs.Rows[0]["score"])
+	}
+}
+
+func TestMatchAgainstSeesUpdates(t *testing.T) {
+	db := newFullTextDB(t)
+	if got := matchIDs(t, db, `SELECT id FROM articles WHERE MATCH(body) AGAINST ('compost')`); got != "" {
+		t.Fatalf("before UPDATE = %s", got)
+	}
+	execSQL(t, db, `UPDATE articles SET body = 'Compost feeds the soil' WHERE id = 2`)
+	if got := matchIDs(t, db, `SELECT id FROM articles WHERE MATCH(body) AGAINST ('compost')`); got != "2" {
+		t.Fatalf("after UPDATE = %s", got)
+	}
+	if got := matchIDs(t, db, `SELECT id FROM articles WHERE MATCH(body) AGAINST ('tomatoes')`); got != "4" {
+		t.Fatalf("replaced words still indexed: %s", got)
+	}
+	execSQL(t, db, `DELETE FROM articles WHERE id = 4`)
+	if got := matchIDs(t, db, `SELECT id FROM articles WHERE MATCH(body) AGAINST ('tomatoes')`); got != "" {
+		t.Fatalf("after DELETE = %s", got)
+	}
+}
+
+func TestMatchAgainstErrors(t *testing.T) {
+	db := newFullTextDB(t)
+	execSQL(t, db, `CREATE TABLE plain (id INT, note TEXT)`)
+	execSQL(t, db, `INSERT INTO plain VALUES (1, 'x')`)
+	for q, want := range map[string]string{
+		`SELECT id FROM articles WHERE MATCH(tag) AGAINST ('db')`:   "no full-text index",
+		`SELECT id FROM plain WHERE MATCH(note) AGAINST ('x')`:      "no full-text index",
+		`SELECT id FROM plain WHERE MATCH(missing) AGAINST ('x')`:   "no table has FULLTEXT columns",
+		`SELECT id FROM plain WHERE MATCH(note) AGAINST ('x' IN X)`: "BOOLEAN MODE",
+		`CREATE TABLE bad (n INT FULLTEXT)`:                         "requires a TEXT column",
+	} {
+		stmt, err := NewParser(q).ParseStatement()
+		if err == nil {
+			_, err = Execute(t.Context(), db, "default", stmt)
+		}
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: error %v, want %q", q, err, want)
+		}
+	}
+}
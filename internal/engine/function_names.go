@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"sort"
+	"strings"
+)
+
+// exprOnlyFuncNames are functions evaluated outside the scalar registry:
+// the aggregates isAggregate recognizes and the window functions of
+// isWindowFuncName.
+var exprOnlyFuncNames = []string{
+	"COUNT", "SUM", "AVG", "MIN", "MAX", "MEDIAN",
+	"MIN_BY", "MAX_BY", "ARG_MIN", "ARG_MAX", "JSON_ARRAYAGG",
+	"ROW_NUMBER", "RANK", "DENSE_RANK", "LAG", "LEAD",
+	"FIRST_VALUE", "LAST_VALUE", "MOVING_SUM", "MOVING_AVG",
+	"NTILE", "PERCENT_RANK", "CUME_DIST",
+}
+
+// IsKnownFunction reports whether name, in any case, is a scalar,
+// aggregate or window function the engine evaluates in expressions.
+// Table-valued functions are looked up with GetTableFunc instead.
+func IsKnownFunction(name string) bool {
+	upper := strings.ToUpper(name)
+	if _, ok := getAllFunctions()[upper]; ok {
+		return true
+	}
+	for _, n := range exprOnlyFuncNames {
+		if n == upper {
+			return true
+		}
+	}
+	return false
+}
+
+// FunctionNames returns the names IsKnownFunction accepts, sorted.
+func FunctionNames() []string {
+	seen := make(map[string]bool)
+	for n := range getAllFunctions() {
+		seen[strings.ToUpper(n)] = true
+	}
+	for _, n := range exprOnlyFuncNames {
+		seen[n] = true
+	}
+	names := make([]string, 0, len(seen))
+	for n := range seen {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SuggestName returns the candidate most likely meant by a mistyped name,
+// or "" if none is close; see suggestSimilarName.
+func SuggestName(name string, candidates []string) string {
+	return suggestSimilarName(name, candidates)
+}
@@ -0,0 +1,26 @@
+package engine
+
+import "testing"
+
+func TestIsKnownFunction(t *testing.T) {
+	for _, name := range []string{"lower", "COALESCE", "count", "MEDIAN", "row_number", "Moving_Avg"} {
+		if !IsKnownFunction(name) {
+			t.Errorf("IsKnownFunction(%q) = false", name)
+		}
+	}
+	for _, name := range []string{"LOWERR", "GROUP_CONCAT", ""} {
+		if IsKnownFunction(name) {
+			t.Errorf("IsKnownFunction(%q) = true", name)
+		}
+	}
+	// Every name outside the registry is one the evaluator handles.
+	for _, name := range exprOnlyFuncNames {
+		if !isAggregate(&FuncCall{Name: name}) && !isWindowFuncName(name) {
+			t.Errorf("%s is neither an aggregate nor a window function", name)
+		}
+	}
+	names := FunctionNames()
+	if len(names) < len(exprOnlyFuncNames) || SuggestName("coalese", names) != "COALESCE" {
+		t.Errorf("FunctionNames() = %d names without COALESCE", len(names))
+	}
+}
@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+func TestHashJoinLargeEquiJoin(t *testing.T) {
+	const n = 10000
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE l (id INT, grp INT)`)
+	execSQL(t, db, `CREATE TABLE r (lid FLOAT, tag TEXT)`)
+	lt, _ := db.Get("default", "l")
+	rt, _ := db.Get("default", "r")
+	for i := 0; i < n; i++ {
+		lt.Rows = append(lt.Rows, []any{i, i % 10})
+		// Every third left row has no partner; the others have one, with the
+		// key stored as FLOAT on this side.
+		if i%3 != 0 {
+			rt.Rows = append(rt.Rows, []any{float64(i), fmt.Sprintf("t%d", i)})
+		} else {
+			rt.Rows = append(rt.Rows, []any{float64(n + i), "orphan"})
+		}
+	}
+	lt.Version++
+	rt.Version++
+
+	rs := execSQL(t, db, `SELECT COUNT(*) AS c, SUM(l.id) AS s FROM l JOIN r ON r.lid = l.id`)
+	want, sum := 0, 0
+	for i := 0; i < n; i++ {
+		if i%3 != 0 {
+			want++
+			sum += i
+		}
+	}
+	if got := rs.Rows[0]["c"]; got != want {
+		t.Fatalf("inner join count = %v, want %d", got, want)
+	}
+	if got, _ := numeric(rs.Rows[0]["s"]); got != float64(sum) {
+		t.Fatalf("inner join sum = %v, want %d", rs.Rows[0]["s"], sum)
+	}
+
+	rs = execSQL(t, db, `SELECT l.id, r.tag FROM l LEFT JOIN r ON l.id = r.lid AND r.tag <> 't4' ORDER BY l.id`)
+	if len(rs.Rows) != n {
+		t.Fatalf("left join returned %d rows, want %d", len(rs.Rows), n)
+	}
+	for i, row := range rs.Rows {
+		tag := row["r.tag"]
+		switch {
+		case i%3 == 0 || i == 4:
+			if tag != nil {
+				t.Fatalf("row %d: tag %v, want NULL", i, tag)
+			}
+		case tag != fmt.Sprintf("t%d", i):
+			t.Fatalf("row %d: tag %v", i, tag)
+		}
+	}
+}
+
+func TestHashJoinNullKeysDoNotMatch(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE a (k INT, name TEXT)`)
+	execSQL(t, db, `CREATE TABLE b (k INT, note TEXT)`)
+	for i := 0; i < 60; i++ {
+		execSQL(t, db, fmt.Sprintf(`INSERT INTO a VALUES (%s, 'a%d')`, nullEvery(i, 4), i))
+		execSQL(t, db, fmt.Sprintf(`INSERT INTO b VALUES (%s, 'b%d')`, nullEvery(i, 5), i))
+	}
+	rs := execSQL(t, db, `SELECT COUNT(*) AS c FROM a JOIN b ON a.k = b.k`)
+	if got := rs.Rows[0]["c"]; got != 36 {
+		t.Fatalf("join count = %v, want 36 (NULL keys must not match)", got)
+	}
+	rs = execSQL(t, db, `SELECT COUNT(*) AS c FROM a FULL JOIN b ON a.k = b.k WHERE a.k IS NULL AND b.k IS NULL`)
+	if got := rs.Rows[0]["c"]; got != 27 {
+		t.Fatalf("unmatched NULL-key rows = %v, want 27", got)
+	}
+}
+
+// nullEvery renders i as SQL, or NULL for every n-th value.
+func nullEvery(i, n int) string {
+	if i%n == 0 {
+		return "NULL"
+	}
+	return fmt.Sprint(i)
+}
+
+func TestHashJoinMatchesNestedLoop(t *testing.T) {
+	db := pushdownTestDB(t)
+	execSQL(t, db, `CREATE TABLE f (x FLOAT, label TEXT)`)
+	for i := 0; i < 60; i++ {
+		execSQL(t, db, fmt.Sprintf(`INSERT INTO f VALUES (%s, 'f%d')`, nullEvery(i%8, 7), i))
+	}
+	queries := []string{
+		`SELECT a.id, b.y FROM a JOIN b ON b.aid = a.id ORDER BY a.id, b.y`,
+		`SELECT a.id, b.y FROM a JOIN b ON a.id = b.aid AND a.x = b.y - 2 ORDER BY b.y`,
+		`SELECT a.id, b.aid, b.y FROM a LEFT JOIN b ON a.x = b.y`,
+		`SELECT a.id, b.aid FROM a RIGHT JOIN b ON a.id = b.aid AND a.x > 1`,
+		`SELECT a.id, b.aid FROM a FULL JOIN b ON a.x = b.y AND a.id < b.aid`,
+		`SELECT a.id, f.label FROM a JOIN f ON f.x = a.x ORDER BY a.x`,
+		`SELECT a.id, f.label FROM a LEFT JOIN f ON a.x = f.x AND f.label LIKE 'f1%'`,
+		`SELECT l.id, r.id AS rid FROM a l JOIN a r ON l.x = r.x AND l.name = r.name ORDER BY l.x`,
+		`SELECT a.id, b.aid, c.z FROM a JOIN b ON a.id = b.aid JOIN c ON c.bid = b.y`,
+		// An unqualified column that only one side carries is hashed too.
+		`SELECT COUNT(*) AS n FROM a JOIN b ON a.id = aid`,
+	}
+	for _, q := range queries {
+		hashed := execSQL(t, db, q)
+		func() {
+			defer func(old int) { hashJoinMinPairs = old }(hashJoinMinPairs)
+			hashJoinMinPairs = math.MaxInt
+			plain := execSQL(t, db, q)
+			if len(plain.Rows) == 0 {
+				t.Errorf("%s: no rows, the comparison proves nothing", q)
+			}
+			if !reflect.DeepEqual(hashed.Rows, plain.Rows) {
+				t.Errorf("%s\n  hash join:   %v\n  nested loop: %v", q, hashed.Rows, plain.Rows)
+			}
+		}()
+	}
+}
+
+func TestEquiJoinKeys(t *testing.T) {
+	left := Row{"a.id": 1, "id": 1, "a.x": 2, "x": 2}
+	right := Row{"b.aid": 1, "aid": 1, "b.x": 3, "x": 3}
+	on := whereExpr("b.aid = a.id AND a.x > 1 AND x = b.x AND aid = a.x")
+	got := equiJoinKeys(on, left, right)
+	want := []hashJoinKey{{left: "a.id", right: "b.aid"}, {left: "a.x", right: "aid"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("equiJoinKeys = %v, want %v", got, want)
+	}
+}
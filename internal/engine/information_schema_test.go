@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+func informationSchemaDB(t *testing.T) *storage.DB {
+	t.Helper()
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE users (id INT PRIMARY KEY, email TEXT UNIQUE, name TEXT NOT NULL)`)
+	execSQL(t, db, `CREATE TABLE orders (id INT PRIMARY KEY, user_id INT REFERENCES users(id), total FLOAT CHECK (total >= 0))`)
+	execSQL(t, db, `INSERT INTO users VALUES (1, 'a@x', 'ann'), (2, 'b@x', 'bob')`)
+	execSQL(t, db, `CREATE VIEW big_orders AS SELECT id FROM orders WHERE total > 100`)
+	return db
+}
+
+func TestInformationSchemaTables(t *testing.T) {
+	db := informationSchemaDB(t)
+	rs := execSQL(t, db, `SELECT * FROM information_schema.tables`)
+	cols := append([]string(nil), rs.Cols...)
+	sort.Strings(cols)
+	if fmt.Sprint(cols) != "[is_view row_count table_name table_schema]" {
+		t.Fatalf("cols = %v", rs.Cols)
+	}
+	want := []string{"orders main 0 false", "users main 2 false", "big_orders main <nil> true"}
+	if len(rs.Rows) != len(want) {
+		t.Fatalf("rows = %v", rs.Rows)
+	}
+	for i, row := range rs.Rows {
+		if got := fmt.Sprint(row["table_name"], " ", row["table_schema"], " ", row["row_count"], " ", row["is_view"]); got != want[i] {
+			t.Errorf("row %d = %s, want %s", i, got, want[i])
+		}
+	}
+
+	tables := len(db.ListTables("default"))
+	expectInt(t, execSQL(t, db, `SELECT COUNT(*) AS n FROM information_schema.tables WHERE is_view = FALSE`).Rows[0]["n"], tables, "base tables")
+
+	// Another tenant sees only its own tables.
+	if _, err := Execute(context.Background(), db, "other", mustParse(`CREATE TABLE notes (body TEXT)`)); err != nil {
+		t.Fatal(err)
+	}
+	rs, err := Execute(context.Background(), db, "other", mustParse(`SELECT table_name FROM information_schema.tables WHERE is_view = FALSE`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs.Rows) != 1 || rs.Rows[0]["table_name"] != "notes" {
+		t.Fatalf("other tenant tables = %v", rs.Rows)
+	}
+}
+
+func TestInformationSchemaColumns(t *testing.T) {
+	db := informationSchemaDB(t)
+	rs := execSQL(t, db, `SELECT * FROM information_schema.columns WHERE table_name = 'users' ORDER BY ordinal_position`)
+	want := []string{"id 1 NO", "email 2 YES", "name 3 NO"}
+	if len(rs.Rows) != len(want) {
+		t.Fatalf("rows = %v", rs.Rows)
+	}
+	for i, row := range rs.Rows {
+		if got := fmt.Sprint(row["column_name"], " ", row["ordinal_position"], " ", row["is_nullable"]); got != want[i] {
+			t.Errorf("column %d = %s, want %s", i, got, want[i])
+		}
+	}
+
+	total := 0
+	for _, tbl := range db.ListTables("default") {
+		total += len(tbl.Cols)
+	}
+	expectInt(t, execSQL(t, db, `SELECT COUNT(*) AS n FROM information_schema.columns`).Rows[0]["n"], total, "columns")
+
+	// The metadata tables join like ordinary ones.
+	rs = execSQL(t, db, `
+		SELECT t.table_name AS table_name, COUNT(*) AS n
+		FROM information_schema.tables t
+		JOIN information_schema.columns c ON c.table_name = t.table_name
+		GROUP BY t.table_name ORDER BY t.table_name`)
+	if len(rs.Rows) != 2 || rs.Rows[0]["table_name"] != "orders" {
+		t.Fatalf("joined rows = %v", rs.Rows)
+	}
+	expectInt(t, rs.Rows[1]["n"], 3, "users columns via join")
+}
+
+func TestInformationSchemaConstraints(t *testing.T) {
+	db := informationSchemaDB(t)
+	rs := execSQL(t, db, `SELECT constraint_name, constraint_type FROM information_schema.table_constraints ORDER BY constraint_name`)
+	var got []string
+	for _, row := range rs.Rows {
+		got = append(got, fmt.Sprint(row["constraint_name"], ":", row["constraint_type"]))
+	}
+	want := "[orders_pkey:PRIMARY KEY orders_total_check:CHECK orders_user_id_fkey:FOREIGN KEY users_email_key:UNIQUE users_pkey:PRIMARY KEY]"
+	if fmt.Sprint(got) != want {
+		t.Fatalf("constraints = %v, want %s", got, want)
+	}
+
+	rs = execSQL(t, db, `SELECT * FROM information_schema.referential_constraints`)
+	if len(rs.Rows) != 1 || rs.Rows[0]["constraint_name"] != "orders_user_id_fkey" || rs.Rows[0]["referenced_table_name"] != "users" {
+		t.Fatalf("referential constraints = %v", rs.Rows)
+	}
+}
@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MonthInterval is the value of an INTERVAL literal expressed in calendar
+// months (MONTH and YEAR units). Unlike time.Duration it has no fixed length:
+// adding one month to January 31st yields the last day of February.
+type MonthInterval struct {
+	Months int
+}
+
+// String renders the interval the way it is written in SQL.
+func (m MonthInterval) String() string {
+	if m.Months%12 == 0 && m.Months != 0 {
+		return fmt.Sprintf("%d YEAR", m.Months/12)
+	}
+	return fmt.Sprintf("%d MONTH", m.Months)
+}
+
+// newIntervalValue converts an INTERVAL amount and unit into a time.Duration
+// (SECOND through WEEK) or a MonthInterval (MONTH and YEAR).
+func newIntervalValue(amount float64, unit string) (any, error) {
+	switch strings.TrimSuffix(strings.ToUpper(unit), "S") {
+	case "SECOND":
+		return time.Duration(amount * float64(time.Second)), nil
+	case "MINUTE":
+		return time.Duration(amount * float64(time.Minute)), nil
+	case "HOUR":
+		return time.Duration(amount * float64(time.Hour)), nil
+	case "DAY":
+		return time.Duration(amount * float64(24*time.Hour)), nil
+	case "WEEK":
+		return time.Duration(amount * float64(7*24*time.Hour)), nil
+	case "MONTH":
+		return MonthInterval{Months: int(amount)}, nil
+	case "YEAR":
+		return MonthInterval{Months: int(amount) * 12}, nil
+	}
+	return nil, fmt.Errorf("unknown INTERVAL unit %q", unit)
+}
+
+// parseIntervalString parses the quoted form INTERVAL '3 days'.
+func parseIntervalString(s string) (any, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("invalid INTERVAL %q: expected '<amount> <unit>'", s)
+	}
+	amount, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid INTERVAL amount %q", fields[0])
+	}
+	return newIntervalValue(amount, fields[1])
+}
+
+func isIntervalValue(v any) bool {
+	switch v.(type) {
+	case time.Duration, MonthInterval:
+		return true
+	}
+	return false
+}
+
+// evalIntervalArithmetic implements timestamp ± INTERVAL and INTERVAL ±
+// INTERVAL. The boolean result reports whether either operand was an
+// interval; when false the caller falls back to numeric arithmetic.
+func evalIntervalArithmetic(op string, lv, rv any) (any, bool, error) {
+	if !isIntervalValue(lv) && !isIntervalValue(rv) {
+		return nil, false, nil
+	}
+	if op != "+" && op != "-" {
+		return nil, true, fmt.Errorf("%s is not supported for INTERVAL values", op)
+	}
+	if lv == nil || rv == nil {
+		return nil, true, nil
+	}
+	if ld, ok := lv.(time.Duration); ok {
+		if rd, ok := rv.(time.Duration); ok {
+			if op == "-" {
+				return ld - rd, true, nil
+			}
+			return ld + rd, true, nil
+		}
+	}
+	if lm, ok := lv.(MonthInterval); ok {
+		if rm, ok := rv.(MonthInterval); ok {
+			if op == "-" {
+				return MonthInterval{Months: lm.Months - rm.Months}, true, nil
+			}
+			return MonthInterval{Months: lm.Months + rm.Months}, true, nil
+		}
+	}
+
+	base, interval := lv, rv
+	if isIntervalValue(lv) {
+		if op == "-" {
+			return nil, true, fmt.Errorf("cannot subtract a timestamp from an INTERVAL")
+		}
+		base, interval = rv, lv
+	}
+	t, err := parseTimeValue(base)
+	if err != nil {
+		return nil, true, err
+	}
+	switch iv := interval.(type) {
+	case time.Duration:
+		if op == "-" {
+			iv = -iv
+		}
+		return t.Add(iv), true, nil
+	case MonthInterval:
+		n := iv.Months
+		if op == "-" {
+			n = -n
+		}
+		return addMonthsClamped(t, n), true, nil
+	}
+	return nil, true, fmt.Errorf("cannot combine %T and %T", lv, rv)
+}
+
+// addMonthsClamped adds n calendar months to t, clamping the day to the end
+// of the target month instead of overflowing into the next one.
+func addMonthsClamped(t time.Time, n int) time.Time {
+	y, m, d := t.Date()
+	first := time.Date(y, m+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	if d > last {
+		d = last
+	}
+	return first.AddDate(0, 0, d-1)
+}
@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+func queryTime(t *testing.T, db *storage.DB, expr string) time.Time {
+	t.Helper()
+	v := queryScalar(t, db, expr)
+	tm, ok := v.(time.Time)
+	if !ok {
+		t.Fatalf("%s: expected time.Time, got %T (%v)", expr, v, v)
+	}
+	return tm
+}
+
+func TestIntervalLiteral(t *testing.T) {
+	db := storage.NewDB()
+	cases := []struct {
+		expr string
+		want any
+	}{
+		{`INTERVAL 90 SECOND`, 90 * time.Second},
+		{`INTERVAL 2 HOURS`, 2 * time.Hour},
+		{`INTERVAL '3' DAY`, 72 * time.Hour},
+		{`INTERVAL '1 week'`, 7 * 24 * time.Hour},
+		{`INTERVAL 2 MONTH`, MonthInterval{Months: 2}},
+		{`INTERVAL 1 YEAR`, MonthInterval{Months: 12}},
+		{`INTERVAL 1 HOUR + INTERVAL 30 MINUTE`, 90 * time.Minute},
+	}
+	for _, c := range cases {
+		if got := queryScalar(t, db, c.expr); got != c.want {
+			t.Errorf("%s = %v (%T), want %v", c.expr, got, got, c.want)
+		}
+	}
+}
+
+func TestIntervalArithmetic(t *testing.T) {
+	db := storage.NewDB()
+
+	tomorrow := time.Now().Add(24 * time.Hour)
+	if got := queryTime(t, db, `NOW() + INTERVAL 1 DAY`); got.Sub(tomorrow).Abs() > 5*time.Second {
+		t.Errorf("NOW() + INTERVAL 1 DAY = %v, want ~%v", got, tomorrow)
+	}
+
+	cases := []struct {
+		expr string
+		want string
+	}{
+		{`'2024-01-31' + INTERVAL 1 MONTH`, "2024-02-29"},
+		{`'2023-01-31' + INTERVAL 1 MONTH`, "2023-02-28"},
+		{`'2024-02-29' + INTERVAL 1 YEAR`, "2025-02-28"},
+		{`'2024-03-31' - INTERVAL 1 MONTH`, "2024-02-29"},
+		{`INTERVAL 2 DAY + '2024-12-31'`, "2025-01-02"},
+		{`'2024-01-01' - INTERVAL 1 WEEK`, "2023-12-25"},
+	}
+	for _, c := range cases {
+		if got := queryTime(t, db, c.expr).Format("2006-01-02"); got != c.want {
+			t.Errorf("%s = %s, want %s", c.expr, got, c.want)
+		}
+	}
+
+	if got := queryScalar(t, db, `NULL + INTERVAL 1 DAY`); got != nil {
+		t.Errorf("NULL + INTERVAL 1 DAY = %v, want NULL", got)
+	}
+}
+
+func TestIntervalErrors(t *testing.T) {
+	db := storage.NewDB()
+	for _, q := range []string{
+		`SELECT INTERVAL 1 FORTNIGHT`,
+		`SELECT INTERVAL 1 DAY - '2024-01-01'`,
+		`SELECT INTERVAL 1 DAY * 2`,
+	} {
+		stmt, err := NewParser(q).ParseStatement()
+		if err == nil {
+			_, err = Execute(context.Background(), db, "default", stmt)
+		}
+		if err == nil {
+			t.Errorf("%s: expected error", q)
+		}
+	}
+}
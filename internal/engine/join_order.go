@@ -0,0 +1,251 @@
+package engine
+
+import (
+	"strings"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+// Statistics-driven join ordering for the general join path. A chain of
+// INNER JOINs produces the same rows in any order, so once every table in
+// the chain has fresh ANALYZE statistics the joins run greedily: at each
+// step, among the joins whose ON condition only references tables already
+// joined, the one with the smallest estimated output goes next. The FROM
+// table always stays first.
+//
+// Estimates use distinct counts: an equality term between two columns keeps
+// 1/max(ndv) of the cross product, and a pushed-down "col = literal" filter
+// keeps 1/ndv of its table.
+//
+// The written order is kept whenever moving a join could change what a
+// column reference or result row means:
+//   - a join is not INNER or has no ON condition;
+//   - a source is not a base table with fresh statistics;
+//   - two sources share an alias or an unqualified column name, because
+//     merged rows resolve such a name to the source joined last;
+//   - an ON term is not a shape whose columns can be attributed to sources.
+//
+// Only the execution order changes; SELECT * still lists columns in the
+// written order.
+
+type joinOrderSource struct {
+	stats *storage.TableStats
+	rows  float64 // estimated rows after the source's pushed-down filter
+}
+
+// joinEquality is one "a.x = b.y" term of an ON condition.
+type joinEquality struct {
+	left, right       int // source indexes, FROM = 0
+	leftCol, rightCol string
+}
+
+type joinOrderStep struct {
+	refs  map[int]bool // sources the ON condition references
+	equis []joinEquality
+}
+
+// planJoinOrder returns s.Joins and their pushed-down filters (see
+// planPredicatePushdown) in execution order. ok is false when the written
+// order is kept.
+func planJoinOrder(env ExecEnv, s *Select, fromFilter Expr, filters []Expr) (joins []JoinClause, joinFilters []Expr, ok bool) {
+	if len(s.Joins) < 2 {
+		return s.Joins, filters, false
+	}
+	sc := newPushdownScope(env, s)
+	sources := make([]joinOrderSource, len(sc.sources))
+	seenCols := make(map[string]bool)
+	items := append([]FromItem{s.From}, make([]FromItem, len(s.Joins))...)
+	for i, j := range s.Joins {
+		if j.Type != JoinInner || j.On == nil {
+			return s.Joins, filters, false
+		}
+		items[i+1] = j.Right
+	}
+	for i, item := range items {
+		src := sc.sources[i]
+		if src.ambiguous || src.cols == nil || item.Subquery != nil || item.TableFunc != nil {
+			return s.Joins, filters, false
+		}
+		if _, isCTE := env.ctes[strings.ToLower(item.Table)]; isCTE {
+			return s.Joins, filters, false
+		}
+		for col := range src.cols {
+			if seenCols[col] {
+				return s.Joins, filters, false
+			}
+			seenCols[col] = true
+		}
+		t, err := env.db.Get(env.tenant, item.Table)
+		if err != nil || t.Stats == nil || t.Stats.Stale {
+			return s.Joins, filters, false
+		}
+		filter := fromFilter
+		if i > 0 {
+			filter = nil
+			if i-1 < len(filters) {
+				filter = filters[i-1]
+			}
+		}
+		sources[i] = joinOrderSource{stats: t.Stats, rows: estimateFilteredRows(t.Stats, filter)}
+	}
+
+	steps := make([]joinOrderStep, len(s.Joins))
+	for i, j := range s.Joins {
+		step, ok := joinOrderStepFor(sc, j.On)
+		if !ok {
+			return s.Joins, filters, false
+		}
+		steps[i] = step
+	}
+
+	placed := map[int]bool{0: true}
+	done := make([]bool, len(s.Joins))
+	order := make([]int, 0, len(s.Joins))
+	cur := sources[0].rows
+	for len(order) < len(s.Joins) {
+		best, bestRows := -1, 0.0
+		for i, step := range steps {
+			if done[i] || !joinRefsPlaced(step.refs, placed, i+1) {
+				continue
+			}
+			rows := estimateJoinRows(sources, step, cur, i+1, placed)
+			if best < 0 || rows < bestRows {
+				best, bestRows = i, rows
+			}
+		}
+		if best < 0 {
+			// An ON condition references a later join in the written order;
+			// leave such queries exactly as written.
+			return s.Joins, filters, false
+		}
+		done[best] = true
+		placed[best+1] = true
+		order = append(order, best)
+		cur = bestRows
+	}
+
+	joins = make([]JoinClause, len(order))
+	joinFilters = make([]Expr, len(order))
+	for pos, i := range order {
+		joins[pos] = s.Joins[i]
+		if i < len(filters) {
+			joinFilters[pos] = filters[i]
+		}
+	}
+	return joins, joinFilters, true
+}
+
+// joinOrderStepFor attributes every column of on to a source. Terms other
+// than column equalities must be single-source shapes that predicate
+// pushdown also accepts.
+func joinOrderStepFor(sc *pushdownScope, on Expr) (joinOrderStep, bool) {
+	step := joinOrderStep{refs: make(map[int]bool)}
+	var terms []Expr
+	collectAndTerms(on, &terms)
+	for _, term := range terms {
+		if b, ok := term.(*Binary); ok && b.Op == "=" {
+			l, lok := b.Left.(*VarRef)
+			r, rok := b.Right.(*VarRef)
+			if lok && rok {
+				li, ri := sc.sourceIndex(l), sc.sourceIndex(r)
+				if li < 0 || ri < 0 {
+					return step, false
+				}
+				step.refs[li], step.refs[ri] = true, true
+				if li != ri {
+					step.equis = append(step.equis, joinEquality{left: li, right: ri, leftCol: varColumn(l), rightCol: varColumn(r)})
+				}
+				continue
+			}
+		}
+		if !simpleJoinPushdownSafe(term) {
+			return step, false
+		}
+		ok := true
+		walkVarRefs(term, func(v *VarRef) {
+			idx := sc.sourceIndex(v)
+			if idx < 0 {
+				ok = false
+				return
+			}
+			step.refs[idx] = true
+		})
+		if !ok {
+			return step, false
+		}
+	}
+	return step, true
+}
+
+// sourceIndex returns the index of the source v belongs to, or -1.
+func (sc *pushdownScope) sourceIndex(v *VarRef) int {
+	alias := sc.varSource(v)
+	if alias == "" {
+		return -1
+	}
+	for i, src := range sc.sources {
+		if src.alias == alias {
+			return i
+		}
+	}
+	return -1
+}
+
+func varColumn(v *VarRef) string {
+	name := strings.ToLower(v.Name)
+	if dot := strings.LastIndexByte(name, '.'); dot >= 0 {
+		return name[dot+1:]
+	}
+	return name
+}
+
+func joinRefsPlaced(refs map[int]bool, placed map[int]bool, self int) bool {
+	for idx := range refs {
+		if idx != self && !placed[idx] {
+			return false
+		}
+	}
+	return true
+}
+
+// estimateJoinRows estimates the output of joining cur rows with source
+// self, dividing the cross product by the larger distinct count of each
+// equality that links self to an already joined source.
+func estimateJoinRows(sources []joinOrderSource, step joinOrderStep, cur float64, self int, placed map[int]bool) float64 {
+	rows := cur * sources[self].rows
+	for _, eq := range step.equis {
+		if (eq.left == self && placed[eq.right]) || (eq.right == self && placed[eq.left]) {
+			ndv := max(columnDistinct(sources[eq.left].stats, eq.leftCol), columnDistinct(sources[eq.right].stats, eq.rightCol))
+			rows /= float64(ndv)
+		}
+	}
+	return max(rows, 1)
+}
+
+// estimateFilteredRows applies the selectivity of the "col = literal" terms
+// of a pushed-down filter to the analyzed row count.
+func estimateFilteredRows(stats *storage.TableStats, filter Expr) float64 {
+	rows := float64(stats.RowCount)
+	var terms []Expr
+	collectAndTerms(filter, &terms)
+	for _, term := range terms {
+		b, ok := term.(*Binary)
+		if !ok || b.Op != "=" {
+			continue
+		}
+		ref, ok := b.Left.(*VarRef)
+		if !ok {
+			ref, ok = b.Right.(*VarRef)
+		}
+		if ok {
+			rows /= float64(columnDistinct(stats, varColumn(ref)))
+		}
+	}
+	return max(rows, 1)
+}
+
+// columnDistinct returns the analyzed distinct count of col, at least 1 so
+// that an all-NULL or unknown column never divides by zero.
+func columnDistinct(stats *storage.TableStats, col string) int {
+	return max(stats.Columns[col].DistinctCount, 1)
+}
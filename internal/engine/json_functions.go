@@ -0,0 +1,344 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// JSON mutation functions. Each accepts either a decoded JSON value (as
+// stored in a JSON column) or JSON text, works on a private copy, and returns
+// the result in the same representation it received. Paths use the
+// parseJSONPath syntax shared with JSON_GET: "a.b[2].c".
+//
+// The constructors JSON_ARRAY, JSON_OBJECT and the JSON_ARRAYAGG aggregate
+// return decoded values, exactly like a JSON column, so they nest inside each
+// other and the driver renders them as JSON text.
+
+func getJSONFunctions() map[string]funcHandler {
+	return map[string]funcHandler{
+		"JSON_DELETE": evalJSONDelete,
+		"JSON_REMOVE": evalJSONDelete,
+		"JSON_MERGE":  evalJSONMerge,
+		"JSON_PATCH":  evalJSONPatch,
+		"JSON_ARRAY":  evalJSONArray,
+		"JSON_OBJECT": evalJSONObject,
+		"JSON_TYPE":   evalJSONType,
+		"JSON_VALID":  evalJSONValid,
+	}
+}
+
+// jsonDocument decodes v for mutation. JSON text is parsed; maps and slices
+// are deep-copied so the caller never writes through to a stored row. text
+// reports whether v was JSON text, so the result can be encoded back.
+func jsonDocument(v any) (doc any, text bool) {
+	if s, ok := v.(string); ok {
+		var parsed any
+		if err := json.Unmarshal([]byte(s), &parsed); err == nil {
+			return parsed, true
+		}
+		return s, false
+	}
+	return jsonDeepCopy(v), false
+}
+
+func jsonDeepCopy(v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(x))
+		for k, e := range x {
+			out[k] = jsonDeepCopy(e)
+		}
+		return out
+	case []any:
+		out := make([]any, len(x))
+		for i, e := range x {
+			out[i] = jsonDeepCopy(e)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// jsonResult encodes doc back to text when the input was JSON text.
+func jsonResult(doc any, text bool) (any, error) {
+	if !text {
+		return doc, nil
+	}
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// evalJSONArgs evaluates every argument and reports whether any is NULL.
+func evalJSONArgs(env ExecEnv, ex *FuncCall, row Row) ([]any, bool, error) {
+	vals := make([]any, len(ex.Args))
+	hasNull := false
+	for i, a := range ex.Args {
+		v, err := evalExpr(env, a, row)
+		if err != nil {
+			return nil, false, err
+		}
+		if v == nil {
+			hasNull = true
+		}
+		vals[i] = v
+	}
+	return vals, hasNull, nil
+}
+
+// evalJSONDelete implements JSON_DELETE(json, path). A path that does not
+// exist leaves the document unchanged.
+func evalJSONDelete(env ExecEnv, ex *FuncCall, row Row) (any, error) {
+	if err := requireArgs(ex.Name, ex, 2, 2); err != nil {
+		return nil, err
+	}
+	vals, hasNull, err := evalJSONArgs(env, ex, row)
+	if err != nil || hasNull {
+		return nil, err
+	}
+	path, ok := vals[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("%s path must be text, got %T", ex.Name, vals[1])
+	}
+	doc, text := jsonDocument(vals[0])
+	return jsonResult(jsonDelete(doc, parseJSONPath(path)), text)
+}
+
+func jsonDelete(doc any, parts []pathPart) any {
+	if len(parts) == 0 {
+		return doc
+	}
+	last := parts[len(parts)-1]
+	parent := doc
+	for _, p := range parts[:len(parts)-1] {
+		parent = jsonGetPart(parent, p)
+	}
+	switch c := parent.(type) {
+	case map[string]any:
+		if last.idx < 0 {
+			delete(c, last.key)
+		}
+	case []any:
+		if last.idx < 0 || last.idx >= len(c) {
+			return doc
+		}
+		trimmed := append(c[:last.idx:last.idx], c[last.idx+1:]...)
+		if len(parts) == 1 {
+			return trimmed
+		}
+		grand := doc
+		for _, p := range parts[:len(parts)-2] {
+			grand = jsonGetPart(grand, p)
+		}
+		jsonSetPart(grand, parts[len(parts)-2], trimmed)
+	}
+	return doc
+}
+
+func jsonGetPart(v any, p pathPart) any {
+	switch c := v.(type) {
+	case map[string]any:
+		if p.idx < 0 {
+			return c[p.key]
+		}
+	case []any:
+		if p.idx >= 0 && p.idx < len(c) {
+			return c[p.idx]
+		}
+	}
+	return nil
+}
+
+func jsonSetPart(v any, p pathPart, val any) {
+	switch c := v.(type) {
+	case map[string]any:
+		if p.idx < 0 {
+			c[p.key] = val
+		}
+	case []any:
+		if p.idx >= 0 && p.idx < len(c) {
+			c[p.idx] = val
+		}
+	}
+}
+
+// evalJSONMerge implements JSON_MERGE(json1, json2): nested objects are
+// merged key by key and on any other overlap the value from json2 wins.
+func evalJSONMerge(env ExecEnv, ex *FuncCall, row Row) (any, error) {
+	if err := requireArgs(ex.Name, ex, 2, 2); err != nil {
+		return nil, err
+	}
+	vals, hasNull, err := evalJSONArgs(env, ex, row)
+	if err != nil || hasNull {
+		return nil, err
+	}
+	dst, text := jsonDocument(vals[0])
+	src, _ := jsonDocument(vals[1])
+	dm, ok1 := dst.(map[string]any)
+	sm, ok2 := src.(map[string]any)
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("%s expects two JSON objects", ex.Name)
+	}
+	return jsonResult(jsonMergeObjects(dm, sm), text)
+}
+
+func jsonMergeObjects(dst, src map[string]any) map[string]any {
+	for k, sv := range src {
+		dv, ok := dst[k].(map[string]any)
+		if svm, ok2 := sv.(map[string]any); ok && ok2 {
+			dst[k] = jsonMergeObjects(dv, svm)
+			continue
+		}
+		dst[k] = sv
+	}
+	return dst
+}
+
+// evalJSONPatch implements JSON_PATCH(json, patch) as an RFC 7396 merge
+// patch: a null member in the patch removes the key from the target.
+func evalJSONPatch(env ExecEnv, ex *FuncCall, row Row) (any, error) {
+	if err := requireArgs(ex.Name, ex, 2, 2); err != nil {
+		return nil, err
+	}
+	vals, hasNull, err := evalJSONArgs(env, ex, row)
+	if err != nil || hasNull {
+		return nil, err
+	}
+	target, text := jsonDocument(vals[0])
+	patch, _ := jsonDocument(vals[1])
+	return jsonResult(jsonMergePatch(target, patch), text)
+}
+
+func jsonMergePatch(target, patch any) any {
+	pm, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	tm, ok := target.(map[string]any)
+	if !ok {
+		tm = make(map[string]any, len(pm))
+	}
+	for k, v := range pm {
+		if v == nil {
+			delete(tm, k)
+			continue
+		}
+		tm[k] = jsonMergePatch(tm[k], v)
+	}
+	return tm
+}
+
+// evalJSONArray implements JSON_ARRAY(v1, v2, ...). NULL arguments become
+// JSON null elements.
+func evalJSONArray(env ExecEnv, ex *FuncCall, row Row) (any, error) {
+	vals, _, err := evalJSONArgs(env, ex, row)
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range vals {
+		vals[i] = jsonDeepCopy(v)
+	}
+	return vals, nil
+}
+
+// evalJSONObject implements JSON_OBJECT(key1, val1, ...).
+func evalJSONObject(env ExecEnv, ex *FuncCall, row Row) (any, error) {
+	if len(ex.Args)%2 != 0 {
+		return nil, fmt.Errorf("JSON_OBJECT expects key/value pairs, got %d arguments", len(ex.Args))
+	}
+	vals, _, err := evalJSONArgs(env, ex, row)
+	if err != nil {
+		return nil, err
+	}
+	obj := make(map[string]any, len(vals)/2)
+	for i := 0; i < len(vals); i += 2 {
+		if vals[i] == nil {
+			return nil, fmt.Errorf("JSON_OBJECT key %d is NULL", i/2+1)
+		}
+		obj[fmt.Sprint(vals[i])] = jsonDeepCopy(vals[i+1])
+	}
+	return obj, nil
+}
+
+// evalJSONType implements JSON_TYPE(json), returning object, array, string,
+// number, boolean or null. JSON text is parsed first.
+func evalJSONType(env ExecEnv, ex *FuncCall, row Row) (any, error) {
+	if err := requireArgs(ex.Name, ex, 1, 1); err != nil {
+		return nil, err
+	}
+	v, err := evalExpr(env, ex.Args[0], row)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	if s, ok := v.(string); ok {
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			return nil, fmt.Errorf("JSON_TYPE: invalid JSON: %w", err)
+		}
+	}
+	switch v.(type) {
+	case nil:
+		return "null", nil
+	case map[string]any:
+		return "object", nil
+	case []any:
+		return "array", nil
+	case string:
+		return "string", nil
+	case bool:
+		return "boolean", nil
+	}
+	if _, ok := numeric(v); ok {
+		return "number", nil
+	}
+	return nil, fmt.Errorf("JSON_TYPE: %T is not a JSON value", v)
+}
+
+// evalJSONValid implements JSON_VALID(x): whether text parses as JSON.
+// Already-decoded JSON values are valid by construction.
+func evalJSONValid(env ExecEnv, ex *FuncCall, row Row) (any, error) {
+	if err := requireArgs(ex.Name, ex, 1, 1); err != nil {
+		return nil, err
+	}
+	v, err := evalExpr(env, ex.Args[0], row)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	switch x := v.(type) {
+	case string:
+		return json.Valid([]byte(x)), nil
+	case []byte:
+		return json.Valid(x), nil
+	case map[string]any, []any, bool:
+		return true, nil
+	}
+	_, ok := numeric(v)
+	return ok, nil
+}
+
+// evalAggregateJSONArrayAgg implements JSON_ARRAYAGG(expr): the non-NULL
+// values of expr in row order, or NULL when there are none.
+func evalAggregateJSONArrayAgg(env ExecEnv, ex *FuncCall, rows []Row) (any, error) {
+	if len(ex.Args) != 1 {
+		return nil, fmt.Errorf("JSON_ARRAYAGG expects 1 argument")
+	}
+	var out []any
+	for _, r := range rows {
+		if err := checkCtx(env.ctx); err != nil {
+			return nil, err
+		}
+		v, err := evalExpr(env, ex.Args[0], r)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			out = append(out, jsonDeepCopy(v))
+		}
+	}
+	if out == nil {
+		return nil, nil
+	}
+	return out, nil
+}
@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+func TestJSONMutationFunctions(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE docs (id INT, doc JSON)`)
+	execSQL(t, db, `INSERT INTO docs VALUES (1, '{"user":{"name":"Ada","tags":["a","b","c"]},"n":1}')`)
+
+	for _, tc := range []struct {
+		query string
+		want  any
+	}{
+		{`SELECT JSON_SET('{"a":1}', 'b.c', 2) AS v`, `{"a":1,"b":{"c":2}}`},
+		{`SELECT JSON_DELETE('{"a":1,"b":2}', 'a') AS v`, `{"b":2}`},
+		{`SELECT JSON_DELETE('{"a":[1,2,3]}', 'a[1]') AS v`, `{"a":[1,3]}`},
+		{`SELECT JSON_DELETE('{"a":1}', 'missing.key') AS v`, `{"a":1}`},
+		{`SELECT JSON_MERGE('{"a":{"x":1},"b":1}', '{"a":{"y":2},"b":2}') AS v`, `{"a":{"x":1,"y":2},"b":2}`},
+		{`SELECT JSON_PATCH('{"a":1,"b":{"c":1,"d":2}}', '{"a":null,"b":{"d":null,"e":3}}') AS v`, `{"b":{"c":1,"e":3}}`},
+		{`SELECT JSON_PATCH('{"a":1}', '[1,2]') AS v`, `[1,2]`},
+		{`SELECT JSON_SET(NULL, '$.a', 1) AS v`, nil},
+		{`SELECT JSON_SET('{"a":1}', NULL, 2) AS v`, nil},
+		{`SELECT JSON_SET('{"a":1}', 'a', NULL) AS v`, `{"a":null}`},
+		{`SELECT JSON_DELETE(NULL, 'a') AS v`, nil},
+		{`SELECT JSON_MERGE('{"a":1}', NULL) AS v`, nil},
+		{`SELECT JSON_PATCH(NULL, '{}') AS v`, nil},
+	} {
+		rs := execSQL(t, db, tc.query)
+		if got := rs.Rows[0]["v"]; !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s = %#v, want %#v", tc.query, got, tc.want)
+		}
+	}
+
+	// Decoded column values come back decoded, and the stored row is not
+	// modified by evaluating the function.
+	rs := execSQL(t, db, `SELECT JSON_SET(doc, 'user.name', 'Grace') AS s, JSON_DELETE(doc, 'user.tags[0]') AS d FROM docs`)
+	if got := jsonGet(rs.Rows[0]["s"], "user.name"); got != "Grace" {
+		t.Errorf("JSON_SET on column: user.name = %v", got)
+	}
+	if got := jsonGet(rs.Rows[0]["d"], "user.tags"); !reflect.DeepEqual(got, []any{"b", "c"}) {
+		t.Errorf("JSON_DELETE on column: user.tags = %#v", got)
+	}
+	rs = execSQL(t, db, `SELECT JSON_GET(doc, 'user.name') AS name, JSON_GET(doc, 'user.tags[0]') AS tag FROM docs`)
+	if rs.Rows[0]["name"] != "Ada" || rs.Rows[0]["tag"] != "a" {
+		t.Errorf("stored document changed: %v", rs.Rows[0])
+	}
+
+	if _, err := Execute(context.Background(), db, "default", mustParse(`SELECT JSON_MERGE('[1]', '{}') AS v`)); err == nil {
+		t.Error("JSON_MERGE accepted a non-object")
+	}
+}
+
+func TestJSONConstructorFunctions(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE items (grp TEXT, name TEXT, qty INT)`)
+	execSQL(t, db, `INSERT INTO items VALUES ('a', 'x', 1), ('a', NULL, 2), ('b', 'y', 3), ('c', NULL, 4)`)
+
+	rs := execSQL(t, db, `SELECT JSON_ARRAY(name, qty) AS arr, JSON_OBJECT('name', name, 'qty', qty) AS obj FROM items WHERE qty <= 2`)
+	if got := rs.Rows[0]["arr"]; !reflect.DeepEqual(got, []any{"x", 1}) {
+		t.Errorf("JSON_ARRAY row 1 = %#v", got)
+	}
+	if got := rs.Rows[1]["arr"]; !reflect.DeepEqual(got, []any{nil, 2}) {
+		t.Errorf("JSON_ARRAY with NULL = %#v", got)
+	}
+	if got := rs.Rows[0]["obj"]; !reflect.DeepEqual(got, map[string]any{"name": "x", "qty": 1}) {
+		t.Errorf("JSON_OBJECT = %#v", got)
+	}
+	rs = execSQL(t, db, `SELECT JSON_ARRAY(JSON_OBJECT('k', 1), JSON_ARRAY()) AS v`)
+	if got := rs.Rows[0]["v"]; !reflect.DeepEqual(got, []any{map[string]any{"k": 1}, []any{}}) {
+		t.Errorf("nested constructors = %#v", got)
+	}
+
+	rs = execSQL(t, db, `SELECT grp, JSON_ARRAYAGG(name) AS names FROM items GROUP BY grp ORDER BY grp`)
+	want := map[string]any{"a": []any{"x"}, "b": []any{"y"}, "c": nil}
+	for _, r := range rs.Rows {
+		if got := r["names"]; !reflect.DeepEqual(got, want[r["grp"].(string)]) {
+			t.Errorf("JSON_ARRAYAGG for %v = %#v", r["grp"], got)
+		}
+	}
+	rs = execSQL(t, db, `SELECT JSON_ARRAYAGG(qty) AS all_qty FROM items`)
+	if got := rs.Rows[0]["all_qty"]; !reflect.DeepEqual(got, []any{1, 2, 3, 4}) {
+		t.Errorf("JSON_ARRAYAGG without GROUP BY = %#v", got)
+	}
+
+	for _, tc := range []struct {
+		query string
+		want  any
+	}{
+		{`SELECT JSON_TYPE('{"a":1}') AS v`, "object"},
+		{`SELECT JSON_TYPE('[1,2]') AS v`, "array"},
+		{`SELECT JSON_TYPE('"s"') AS v`, "string"},
+		{`SELECT JSON_TYPE('2.5') AS v`, "number"},
+		{`SELECT JSON_TYPE('false') AS v`, "boolean"},
+		{`SELECT JSON_TYPE('null') AS v`, "null"},
+		{`SELECT JSON_TYPE(JSON_OBJECT('a', 1)) AS v`, "object"},
+		{`SELECT JSON_TYPE(NULL) AS v`, nil},
+		{`SELECT JSON_VALID('{"a":1}') AS v`, true},
+		{`SELECT JSON_VALID('{"a":') AS v`, false},
+		{`SELECT JSON_VALID('not json') AS v`, false},
+		{`SELECT JSON_VALID(NULL) AS v`, nil},
+	} {
+		rs := execSQL(t, db, tc.query)
+		if got := rs.Rows[0]["v"]; got != tc.want {
+			t.Errorf("%s = %#v, want %#v", tc.query, got, tc.want)
+		}
+	}
+
+	for _, q := range []string{
+		`SELECT JSON_OBJECT('a') AS v`,
+		`SELECT JSON_OBJECT(NULL, 1) AS v`,
+		`SELECT JSON_TYPE('{oops') AS v`,
+	} {
+		if _, err := Execute(context.Background(), db, "default", mustParse(q)); err == nil {
+			t.Errorf("%s: expected an error", q)
+		}
+	}
+}
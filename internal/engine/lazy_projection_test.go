@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+// registerCountingFunc installs a side-effectful scalar function that
+// returns its argument and counts how often it was evaluated.
+func registerCountingFunc(t *testing.T, name string) *int {
+	t.Helper()
+	calls := new(int)
+	funcs := getAllFunctions()
+	funcs[name] = func(env ExecEnv, ex *FuncCall, row Row) (any, error) {
+		*calls++
+		return evalExpr(env, ex.Args[0], row)
+	}
+	t.Cleanup(func() { delete(funcs, name) })
+	return calls
+}
+
+func seedLazyProjectionTable(t *testing.T, n int) *storage.DB {
+	t.Helper()
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE lp (id INT, name TEXT)`)
+	for i := 0; i < n; i++ {
+		execSQL(t, db, fmt.Sprintf(`INSERT INTO lp VALUES (%d, 'n%d')`, i, i))
+	}
+	return db
+}
+
+func TestLazyProjectionSkipsUnselectedRows(t *testing.T) {
+	db := seedLazyProjectionTable(t, 100)
+	calls := registerCountingFunc(t, "LP_COUNT")
+
+	rs := execSQL(t, db, `SELECT id, LP_COUNT(name) AS tag FROM lp ORDER BY id DESC LIMIT 5 OFFSET 2`)
+	if *calls != 5 {
+		t.Errorf("LP_COUNT evaluated %d times, want 5", *calls)
+	}
+	if len(rs.Rows) != 5 {
+		t.Fatalf("got %d rows, want 5", len(rs.Rows))
+	}
+	for i, r := range rs.Rows {
+		wantID := 97 - i
+		if r["id"] != wantID || r["tag"] != fmt.Sprintf("n%d", wantID) {
+			t.Errorf("row %d = %v, want id %d", i, r, wantID)
+		}
+	}
+
+	*calls = 0
+	rs = execSQL(t, db, `SELECT LP_COUNT(id) AS x FROM lp LIMIT 3`)
+	if *calls != 3 || len(rs.Rows) != 3 {
+		t.Errorf("LIMIT without ORDER BY: %d calls, %d rows; want 3 and 3", *calls, len(rs.Rows))
+	}
+}
+
+func TestLazyProjectionOrderByComputedColumn(t *testing.T) {
+	db := seedLazyProjectionTable(t, 20)
+	calls := registerCountingFunc(t, "LP_COUNT")
+
+	// The sort key itself must be computed for every row, once to order and
+	// once more for the rows that are returned.
+	rs := execSQL(t, db, `SELECT name, LP_COUNT(id) AS k FROM lp ORDER BY k DESC LIMIT 2`)
+	if *calls != 22 {
+		t.Errorf("LP_COUNT evaluated %d times, want 22", *calls)
+	}
+	if len(rs.Rows) != 2 || rs.Rows[0]["name"] != "n19" || rs.Rows[1]["name"] != "n18" {
+		t.Errorf("unexpected rows: %v", rs.Rows)
+	}
+}
+
+func TestLazyProjectionLimitZeroKeepsColumns(t *testing.T) {
+	db := seedLazyProjectionTable(t, 3)
+	registerCountingFunc(t, "LP_COUNT")
+
+	rs := execSQL(t, db, `SELECT id, LP_COUNT(name) AS tag FROM lp ORDER BY id LIMIT 0`)
+	if len(rs.Rows) != 0 {
+		t.Fatalf("got %d rows, want 0", len(rs.Rows))
+	}
+	if len(rs.Cols) != 2 || rs.Cols[0] != "id" || rs.Cols[1] != "tag" {
+		t.Errorf("cols = %v, want [id tag]", rs.Cols)
+	}
+}
@@ -309,7 +309,7 @@ func isKeyword(up string) bool {
 		"COALESCE", "NULLIF", "NVL", "IFNULL", "NOW", "CURRENT_TIME", "CURRENT_DATE",
 		"JSON_GET", "JSON_SET", "JSON_EXTRACT", "DATEDIFF",
 		"LTRIM", "RTRIM", "TRIM", "REGEXP", "ISNULL", "ROW_TO_TEXT",
-		"ILIKE", "RLIKE", "GLOB", "SIMILAR", "TO",
+		"ILIKE", "RLIKE", "IREGEXP", "GLOB", "SIMILAR", "TO",
 		"LEVENSHTEIN", "EDIT_DISTANCE",
 		"CONTAINS", "STARTS_WITH", "ENDS_WITH",
 		"BASE64", "BASE64_DECODE",
@@ -2,6 +2,8 @@ package engine
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -103,6 +105,89 @@ func TestMaterializedViewLazyStaleRefresh(t *testing.T) {
 	}
 }
 
+func TestMaterializedViewWithNoDataIsEmptyUntilRefresh(t *testing.T) {
+	db := storage.NewDB()
+	ctx := context.Background()
+
+	mvExecSQL(t, ctx, db, "CREATE TABLE items (id INT, price INT)")
+	mvExecSQL(t, ctx, db, "INSERT INTO items VALUES (1, 10), (2, 20)")
+	mvExecSQL(t, ctx, db, "CREATE MATERIALIZED VIEW pricey AS SELECT id, price FROM items WHERE price > 15 WITH NO DATA")
+
+	rs := mvQuerySQL(t, ctx, db, "SELECT id, price FROM pricey")
+	if len(rs.Rows) != 0 {
+		t.Fatalf("WITH NO DATA rows = %#v, want none", rs.Rows)
+	}
+	if len(rs.Cols) != 2 || rs.Cols[0] != "id" || rs.Cols[1] != "price" {
+		t.Fatalf("WITH NO DATA cols = %v", rs.Cols)
+	}
+
+	mvExecSQL(t, ctx, db, "REFRESH MATERIALIZED VIEW pricey")
+	rs = mvQuerySQL(t, ctx, db, "SELECT id FROM pricey")
+	if len(rs.Rows) != 1 || rs.Rows[0]["id"] != 2 {
+		t.Fatalf("refreshed rows = %#v", rs.Rows)
+	}
+	mvExecSQL(t, ctx, db, "UPDATE items SET price = 30 WHERE id = 1")
+	mvExecSQL(t, ctx, db, "REFRESH MATERIALIZED VIEW CONCURRENTLY pricey")
+	rs = mvQuerySQL(t, ctx, db, "SELECT COUNT(*) AS n FROM pricey")
+	if rs.Rows[0]["n"] != 2 {
+		t.Fatalf("rows after second refresh = %#v", rs.Rows)
+	}
+}
+
+func TestMaterializedViewReadsDuringRefreshSeeOldOrNewData(t *testing.T) {
+	db := storage.NewDB()
+	ctx := context.Background()
+
+	mvExecSQL(t, ctx, db, "CREATE TABLE ticks (id INT)")
+	for i := 0; i < 200; i++ {
+		mvExecSQL(t, ctx, db, fmt.Sprintf("INSERT INTO ticks VALUES (%d)", i))
+	}
+	mvExecSQL(t, ctx, db, "CREATE MATERIALIZED VIEW tick_copy AS SELECT id FROM ticks WITH DATA")
+
+	// Each round adds one row and refreshes, so a reader must always see a
+	// complete snapshot: between 200 and 200+rounds rows, never fewer.
+	const rounds = 20
+	stop := make(chan struct{})
+	errs := make(chan error, 4)
+	var wg sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				rs, err := Execute(ctx, db, "default", mustParse("SELECT COUNT(*) AS n FROM tick_copy"))
+				if err != nil {
+					errs <- err
+					return
+				}
+				if n, _ := rs.Rows[0]["n"].(int); n < 200 || n > 200+rounds {
+					errs <- fmt.Errorf("reader saw %d rows", n)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < rounds; i++ {
+		mvExecSQL(t, ctx, db, fmt.Sprintf("INSERT INTO ticks VALUES (%d)", 200+i))
+		mvExecSQL(t, ctx, db, "REFRESH MATERIALIZED VIEW tick_copy")
+	}
+	close(stop)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+	rs := mvQuerySQL(t, ctx, db, "SELECT COUNT(*) AS n FROM tick_copy")
+	if rs.Rows[0]["n"] != 200+rounds {
+		t.Fatalf("final rows = %#v", rs.Rows)
+	}
+}
+
 func TestMaterializedViewRefreshPoliciesRegisterJobs(t *testing.T) {
 	db := storage.NewDB()
 	ctx := context.Background()
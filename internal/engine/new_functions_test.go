@@ -142,6 +142,71 @@ func TestNewSQLFunctions(t *testing.T) {
 	}
 }
 
+func TestMathFunctionSuite(t *testing.T) {
+	db := storage.NewDB()
+	cases := []struct {
+		expr string
+		want any
+	}{
+		{`ABS(-3)`, 3.0},
+		{`ABS(-2.5)`, 2.5},
+		{`ROUND(3)`, 3},
+		{`ROUND(2.5)`, 3.0},
+		{`ROUND(-2.5)`, -3.0},
+		{`ROUND(2.567, 2)`, 2.57},
+		{`ROUND(1234, -2)`, 1200.0},
+		{`CEIL(1.2)`, 2.0},
+		{`CEILING(-1.2)`, -1.0},
+		{`FLOOR(-1.2)`, -2.0},
+		{`FLOOR(3)`, 3.0},
+		{`MOD(7, 3)`, 1.0},
+		{`MOD(-7, 3)`, -1.0},
+		{`MOD(7.5, 2)`, 1.5},
+		{`POWER(2, 10)`, 1024.0},
+		{`POWER(2, -1)`, 0.5},
+		{`SQRT(16)`, 4.0},
+		{`SQRT(0)`, 0.0},
+		{`LOG(1)`, 0.0},
+		{`LOG10(1000)`, 3.0},
+		{`LOG2(8)`, 3.0},
+		{`EXP(0)`, 1.0},
+		{`SIGN(-5)`, -1},
+		{`SIGN(0)`, 0},
+		{`SIGN(2.5)`, 1},
+		{`GREATEST(1, 3, 2)`, 3},
+		{`GREATEST(1, 2.5)`, 2.5},
+		{`LEAST(3, 1, 2)`, 1},
+		{`LEAST('b', 'a')`, "a"},
+	}
+	for _, c := range cases {
+		if got := queryScalar(t, db, c.expr); got != c.want {
+			t.Errorf("%s = %#v, want %#v", c.expr, got, c.want)
+		}
+	}
+	if got := queryScalar(t, db, `LOG(2.718281828459045)`).(float64); math.Abs(got-1) > 1e-12 {
+		t.Errorf("LOG(e) = %v, want 1", got)
+	}
+
+	for _, expr := range []string{
+		`ABS(NULL)`, `ROUND(NULL)`, `ROUND(1.5, NULL)`, `CEIL(NULL)`, `FLOOR(NULL)`,
+		`MOD(NULL, 2)`, `MOD(2, NULL)`, `POWER(NULL, 2)`, `POWER(2, NULL)`, `SQRT(NULL)`,
+		`LOG(NULL)`, `LOG10(NULL)`, `LOG2(NULL)`, `EXP(NULL)`, `SIGN(NULL)`,
+	} {
+		if got := queryScalar(t, db, expr); got != nil {
+			t.Errorf("%s = %#v, want NULL", expr, got)
+		}
+	}
+
+	for _, expr := range []string{
+		`MOD(7, 0)`, `LOG(0)`, `LOG(-1)`, `LOG10(0)`, `LOG2(0)`, `SQRT(-1)`,
+		`ABS('x')`, `ABS()`, `MOD(1)`, `SQRT(1, 2)`, `ROUND(1, 2, 3)`,
+	} {
+		if _, err := Execute(context.Background(), db, "default", mustParse(`SELECT `+expr)); err == nil {
+			t.Errorf("%s: expected error", expr)
+		}
+	}
+}
+
 func TestPrintfFunction(t *testing.T) {
 	db := storage.NewDB()
 	ctx := context.Background()
@@ -148,3 +148,65 @@ func TestPlainInAndLikeStillExcludeNullRow(t *testing.T) {
 		t.Fatalf("WHERE s LIKE 'a%%' = %v, want only id=1", idSet(rs.Rows))
 	}
 }
+
+func TestInListWithNullEntry(t *testing.T) {
+	db := setupThreeValuedLogicTable(t)
+	cases := []struct {
+		expr string
+		want any
+	}{
+		{`5 IN (5, NULL)`, true},
+		{`7 IN (5, NULL)`, nil},
+		{`5 NOT IN (5, NULL)`, false},
+		{`7 NOT IN (5, NULL)`, nil},
+		{`7 NOT IN (5, 6)`, true},
+		{`'b' IN ('a', 'b')`, true},
+		{`NULL IN (NULL)`, nil},
+		{`5 IN ()`, false},
+		{`5 NOT IN ()`, true},
+		{`NULL IN ()`, false},
+	}
+	for _, c := range cases {
+		if got := queryScalar(t, db, c.expr); got != c.want {
+			t.Errorf("%s = %v, want %v", c.expr, got, c.want)
+		}
+	}
+
+	// A NULL in a NOT IN list can never produce true, on any path.
+	for _, q := range []string{
+		`SELECT id FROM t WHERE v NOT IN (20, NULL)`,
+		`SELECT DISTINCT id FROM t WHERE v NOT IN (20, NULL)`,
+		`SELECT a.id FROM t a JOIN t b ON a.id = b.id WHERE a.v NOT IN (20, NULL)`,
+	} {
+		if rs := execSQL(t, db, q); len(rs.Rows) != 0 {
+			t.Errorf("%s = %v, want no rows", q, idSet(rs.Rows))
+		}
+	}
+	for _, q := range []string{
+		`SELECT id FROM t WHERE v IN (5, NULL)`,
+		`SELECT DISTINCT id FROM t WHERE v IN (5, NULL)`,
+	} {
+		got := idSet(execSQL(t, db, q).Rows)
+		if len(got) != 1 || !got[1] {
+			t.Errorf("%s = %v, want only id=1", q, got)
+		}
+	}
+}
+
+func TestInListEmptyAndColumnValues(t *testing.T) {
+	db := setupThreeValuedLogicTable(t)
+	if rs := execSQL(t, db, `SELECT id FROM t WHERE v IN ()`); len(rs.Rows) != 0 {
+		t.Errorf("WHERE v IN () = %v, want no rows", idSet(rs.Rows))
+	}
+	if got := idSet(execSQL(t, db, `SELECT id FROM t WHERE v NOT IN ()`).Rows); len(got) != 3 {
+		t.Errorf("WHERE v NOT IN () = %v, want all rows", got)
+	}
+	// List entries may reference other columns of the same row.
+	got := idSet(execSQL(t, db, `SELECT id FROM t WHERE id + 4 IN (v, 99)`).Rows)
+	if len(got) != 1 || !got[1] {
+		t.Errorf("WHERE id + 4 IN (v, 99) = %v, want only id=1", got)
+	}
+	if _, err := NewParser(`SELECT id FROM t WHERE v IN (1,)`).ParseStatement(); err == nil {
+		t.Error("trailing comma in IN list should not parse")
+	}
+}
@@ -1,6 +1,9 @@
 package engine
 
-import "strings"
+import (
+	"math"
+	"strings"
+)
 
 // Performance optimizations for tinySQL
 
@@ -18,212 +21,228 @@ const (
 	OptimizedJoinTypeRight
 )
 
-// JoinCondition represents a parsed join condition
-type JoinCondition struct {
-	LeftColumn  string
-	RightColumn string
-	Operator    string
-	IsEquiJoin  bool
-}
+// processCrossJoin handles cross joins (cartesian product)
+func (h *HashJoinOptimizer) processCrossJoin(leftRows, rightRows []Row, joinType OptimizedJoinType) ([]Row, error) {
+	result := make([]Row, 0, len(leftRows)*len(rightRows))
 
-// OptimizedJoinProcessor handles optimized join operations
-func (h *HashJoinOptimizer) ProcessOptimizedJoin(leftRows, rightRows []Row, condition Expr, joinType OptimizedJoinType) ([]Row, error) {
-	if condition == nil {
-		// Cross join - use existing nested loop
-		return h.processCrossJoin(leftRows, rightRows, joinType)
-	}
+	for _, leftRow := range leftRows {
+		if err := checkCtx(h.env.ctx); err != nil {
+			return nil, err
+		}
 
-	// Try to extract equi-join condition
-	joinCond := h.extractJoinCondition(condition)
-	if joinCond != nil && joinCond.IsEquiJoin {
-		return h.processHashJoin(leftRows, rightRows, joinCond, joinType)
+		for _, rightRow := range rightRows {
+			merged := mergeRows(leftRow, rightRow)
+			result = append(result, merged)
+		}
 	}
 
-	// Fall back to nested loop for complex conditions
-	return h.processNestedLoopJoin(leftRows, rightRows, condition, joinType)
+	return result, nil
 }
 
-// extractJoinCondition tries to extract a simple equi-join condition
-func (h *HashJoinOptimizer) extractJoinCondition(expr Expr) *JoinCondition {
-	if binary, ok := expr.(*Binary); ok && binary.Op == "=" {
-		leftVar, leftOk := binary.Left.(*VarRef)
-		rightVar, rightOk := binary.Right.(*VarRef)
-
-		if leftOk && rightOk {
-			return &JoinCondition{
-				LeftColumn:  leftVar.Name,
-				RightColumn: rightVar.Name,
-				Operator:    "=",
-				IsEquiJoin:  true,
-			}
+// hashJoinMinPairs is the smallest left×right product for which an equi-join
+// is hashed; below it the nested loop is just as fast. A var so tests can
+// force either path.
+var hashJoinMinPairs = 1024
+
+// hashJoinKey pairs the lower-case row keys of one "left = right" term.
+type hashJoinKey struct {
+	left, right string
+}
+
+// joinMatch is one right row joined to a left row, with the merged row.
+type joinMatch struct {
+	right int
+	row   Row
+}
+
+// equiJoinKeys returns the column equalities among the top-level AND terms
+// of on, oriented by which side's sample row carries each column. A column
+// that both sides carry (an unqualified name shared by both tables) is
+// ambiguous and its term stays a plain condition.
+func equiJoinKeys(on Expr, leftSample, rightSample Row) []hashJoinKey {
+	var terms []Expr
+	collectAndTerms(on, &terms)
+	var keys []hashJoinKey
+	for _, term := range terms {
+		b, ok := term.(*Binary)
+		if !ok || b.Op != "=" {
+			continue
+		}
+		lv, ok1 := b.Left.(*VarRef)
+		rv, ok2 := b.Right.(*VarRef)
+		if !ok1 || !ok2 {
+			continue
+		}
+		l, r := strings.ToLower(lv.Name), strings.ToLower(rv.Name)
+		switch {
+		case joinSideOnly(l, leftSample, rightSample) && joinSideOnly(r, rightSample, leftSample):
+			keys = append(keys, hashJoinKey{left: l, right: r})
+		case joinSideOnly(r, leftSample, rightSample) && joinSideOnly(l, rightSample, leftSample):
+			keys = append(keys, hashJoinKey{left: r, right: l})
 		}
 	}
-	return nil
+	return keys
 }
 
-// processHashJoin implements hash join for equi-join conditions
-func (h *HashJoinOptimizer) processHashJoin(leftRows, rightRows []Row, condition *JoinCondition, joinType OptimizedJoinType) ([]Row, error) {
-	// For LEFT JOIN, always probe with left rows so unmatched left rows can be detected.
-	// For INNER JOIN, build from the smaller relation to minimise hash table size.
-	var buildRows, probeRows []Row
-	var buildColumn, probeColumn string
-
-	if joinType == OptimizedJoinTypeLeft || len(leftRows) > len(rightRows) {
-		// probe = left, build = right
-		buildRows = rightRows
-		probeRows = leftRows
-		buildColumn = condition.RightColumn
-		probeColumn = condition.LeftColumn
-	} else {
-		// probe = right, build = left (inner join, left is smaller)
-		buildRows = leftRows
-		probeRows = rightRows
-		buildColumn = condition.LeftColumn
-		probeColumn = condition.RightColumn
-	}
+func joinSideOnly(key string, side, other Row) bool {
+	_, in := side[key]
+	_, inOther := other[key]
+	return in && !inOther
+}
 
-	// Build hash table
-	hashTable := make(map[any][]Row)
-	for _, row := range buildRows {
-		if err := checkCtx(h.env.ctx); err != nil {
-			return nil, err
+// hashJoinValue normalizes v so that values compare treats as equal share a
+// map key. class separates values that compare refuses to relate (numbers,
+// text, booleans); ok is false for types whose equality needs compare's
+// conversions, such as dates against text.
+func hashJoinValue(v any) (key any, class byte, ok bool) {
+	switch x := v.(type) {
+	case nil:
+		return nil, 0, true
+	case int, int64, float64:
+		f, _ := numeric(x)
+		if math.IsNaN(f) {
+			return nil, 0, false
 		}
-		key := h.getJoinKey(row, buildColumn)
-		if key != nil {
-			hashTable[key] = append(hashTable[key], row)
+		if f == 0 {
+			f = 0 // -0 equals 0
 		}
+		return f, 'n', true
+	case string:
+		return x, 's', true
+	case bool:
+		return x, 'b', true
 	}
+	return nil, 0, false
+}
 
-	// Probe phase
-	estimatedSize := len(probeRows)
-	if joinType == OptimizedJoinTypeInner {
-		estimatedSize = min(len(leftRows), len(rightRows))
+// joinKeyPart is comparableKeyPart with numbers unified, so an INT column
+// joins a FLOAT column holding the same value.
+func joinKeyPart(v any) any {
+	if k, class, ok := hashJoinValue(v); ok && class == 'n' {
+		return k
 	}
-	result := make([]Row, 0, estimatedSize)
-
-	for _, probeRow := range probeRows {
-		if err := checkCtx(h.env.ctx); err != nil {
-			return nil, err
-		}
+	return comparableKeyPart(v)
+}
 
-		key := h.getJoinKey(probeRow, probeColumn)
-		matched := false
-
-		if key != nil {
-			if matchingRows, exists := hashTable[key]; exists {
-				for _, buildRow := range matchingRows {
-					// probeRow is always left when joinType == Left, or right when Inner+right>left
-					var mergedRow Row
-					if joinType == OptimizedJoinTypeLeft || len(leftRows) > len(rightRows) {
-						mergedRow = mergeRows(probeRow, buildRow) // left=probe, right=build
-					} else {
-						mergedRow = mergeRows(buildRow, probeRow) // left=build, right=probe
-					}
-					result = append(result, mergedRow)
-					matched = true
-				}
+// hashJoinRowKeys computes the join key of every row for the given row keys.
+// A row with a NULL key part gets a nil key: it can never satisfy the
+// equality. classes accumulates the value classes seen per key part.
+func hashJoinRowKeys(rows []Row, cols []string, classes []byte) ([]any, bool) {
+	keys := make([]any, len(rows))
+	var buf []byte
+	for i, row := range rows {
+		buf = buf[:0]
+		var single any
+		for k, col := range cols {
+			v, class, ok := hashJoinValue(row[col])
+			if !ok {
+				return nil, false
 			}
-		}
-
-		// For LEFT JOIN, emit unmatched left (probe) rows with null right columns.
-		if !matched && joinType == OptimizedJoinTypeLeft {
-			if len(buildRows) > 0 {
-				result = append(result, h.addNullsForUnmatchedJoin(probeRow, buildRows[0]))
+			if v == nil {
+				single, buf = nil, nil
+				break
+			}
+			if classes[k] != 0 && classes[k] != class {
+				return nil, false
+			}
+			classes[k] = class
+			if len(cols) == 1 {
+				single = v
 			} else {
-				result = append(result, cloneRow(probeRow))
+				buf = writeFmtKeyPart(buf, v)
 			}
 		}
+		switch {
+		case len(cols) == 1:
+			keys[i] = single
+		case buf != nil:
+			keys[i] = string(buf)
+		}
 	}
-
-	return result, nil
+	return keys, true
 }
 
-// getJoinKey extracts the join key from a row
-func (h *HashJoinOptimizer) getJoinKey(row Row, column string) any {
-	// Try different column name formats
-	if val, exists := row[strings.ToLower(column)]; exists {
-		return val
+// hashJoinMatches joins leftRows with rightRows on the equality terms of on,
+// hashing the smaller side. Each candidate pair is still checked against the
+// whole ON condition, so hashing only decides which pairs get evaluated.
+// The result lists, per left row, its matches in right-row order — the order
+// the nested loop produces. ok is false when on has no usable equality or
+// the key values need compare's type conversions; callers then use the
+// nested loop.
+func hashJoinMatches(env ExecEnv, leftRows, rightRows []Row, on Expr) ([][]joinMatch, bool, error) {
+	if len(leftRows) == 0 || len(rightRows) == 0 || int64(len(leftRows))*int64(len(rightRows)) < int64(hashJoinMinPairs) {
+		return nil, false, nil
 	}
-	if val, exists := row[column]; exists {
-		return val
+	keys := equiJoinKeys(on, leftRows[0], rightRows[0])
+	if len(keys) == 0 {
+		return nil, false, nil
 	}
-
-	// Try qualified names
-	for key, val := range row {
-		if strings.HasSuffix(strings.ToLower(key), "."+strings.ToLower(column)) {
-			return val
-		}
+	leftCols, rightCols := make([]string, len(keys)), make([]string, len(keys))
+	for i, k := range keys {
+		leftCols[i], rightCols[i] = k.left, k.right
 	}
-
-	return nil
-}
-
-// addNullsForUnmatchedJoin creates a row with nulls for unmatched joins
-func (h *HashJoinOptimizer) addNullsForUnmatchedJoin(matchedRow, sampleUnmatchedRow Row) Row {
-	result := cloneRow(matchedRow)
-
-	// Add null values for columns from the unmatched side
-	for key := range sampleUnmatchedRow {
-		if _, exists := result[key]; !exists {
-			result[key] = nil
-		}
+	classes := make([]byte, len(keys))
+	leftKeys, ok := hashJoinRowKeys(leftRows, leftCols, classes)
+	if !ok {
+		return nil, false, nil
+	}
+	rightKeys, ok := hashJoinRowKeys(rightRows, rightCols, classes)
+	if !ok {
+		return nil, false, nil
 	}
 
-	return result
-}
-
-// processCrossJoin handles cross joins (cartesian product)
-func (h *HashJoinOptimizer) processCrossJoin(leftRows, rightRows []Row, joinType OptimizedJoinType) ([]Row, error) {
-	result := make([]Row, 0, len(leftRows)*len(rightRows))
-
-	for _, leftRow := range leftRows {
-		if err := checkCtx(h.env.ctx); err != nil {
-			return nil, err
+	matches := make([][]joinMatch, len(leftRows))
+	try := func(li, ri int) error {
+		m := mergeRows(leftRows[li], rightRows[ri])
+		val, err := evalExpr(env, on, m)
+		if err != nil {
+			return err
 		}
-
-		for _, rightRow := range rightRows {
-			merged := mergeRows(leftRow, rightRow)
-			result = append(result, merged)
+		if toTri(val) == tvTrue {
+			matches[li] = append(matches[li], joinMatch{right: ri, row: m})
 		}
+		return nil
 	}
-
-	return result, nil
-}
-
-// processNestedLoopJoin handles complex join conditions that can't be optimized
-func (h *HashJoinOptimizer) processNestedLoopJoin(leftRows, rightRows []Row, condition Expr, joinType OptimizedJoinType) ([]Row, error) {
-	result := make([]Row, 0, len(leftRows))
-
-	for _, leftRow := range leftRows {
-		if err := checkCtx(h.env.ctx); err != nil {
-			return nil, err
-		}
-
-		matched := false
-		for _, rightRow := range rightRows {
-			merged := mergeRows(leftRow, rightRow)
-
-			shouldInclude := true
-			if condition != nil {
-				val, err := evalExpr(h.env, condition, merged)
-				if err != nil {
-					return nil, err
+	if len(leftRows) <= len(rightRows) {
+		built := buildHashJoinTable(leftKeys)
+		for ri, key := range rightKeys {
+			if ri&63 == 0 {
+				if err := checkCtx(env.ctx); err != nil {
+					return nil, true, err
 				}
-				shouldInclude = (toTri(val) == tvTrue)
 			}
-
-			if shouldInclude {
-				result = append(result, merged)
-				matched = true
+			for _, li := range built[key] {
+				if err := try(li, ri); err != nil {
+					return nil, true, err
+				}
 			}
 		}
-
-		// Handle LEFT JOIN unmatched rows
-		if !matched && joinType == OptimizedJoinTypeLeft && len(rightRows) > 0 {
-			nullRow := h.addNullsForUnmatchedJoin(leftRow, rightRows[0])
-			result = append(result, nullRow)
+		return matches, true, nil
+	}
+	built := buildHashJoinTable(rightKeys)
+	for li, key := range leftKeys {
+		if li&63 == 0 {
+			if err := checkCtx(env.ctx); err != nil {
+				return nil, true, err
+			}
+		}
+		for _, ri := range built[key] {
+			if err := try(li, ri); err != nil {
+				return nil, true, err
+			}
 		}
 	}
+	return matches, true, nil
+}
 
-	return result, nil
+// buildHashJoinTable maps each non-NULL key to the ascending indexes of the
+// rows carrying it.
+func buildHashJoinTable(keys []any) map[any][]int {
+	table := make(map[any][]int, len(keys))
+	for i, k := range keys {
+		if k != nil {
+			table[k] = append(table[k], i)
+		}
+	}
+	return table
 }
@@ -132,7 +132,7 @@ type (
 		Name     string
 		Args     []Expr
 		Star     bool
-		Distinct bool        // For COUNT(DISTINCT col)
+		Distinct bool        // For COUNT/SUM/AVG(DISTINCT col)
 		Over     *OverClause // For window functions
 	}
 	// InExpr represents "expr IN (val1, val2, ...)"
@@ -150,12 +150,13 @@ type (
 		CaseInsensitive bool // For ILIKE
 		GlobStyle       bool // For GLOB (* and ? wildcards instead of % and _)
 	}
-	// RegexpExpr represents "expr REGEXP/RLIKE pattern" and "expr SIMILAR TO pattern".
+	// RegexpExpr represents "expr REGEXP/RLIKE/IREGEXP pattern" and "expr SIMILAR TO pattern".
 	RegexpExpr struct {
-		Expr      Expr
-		Pattern   Expr
-		Negate    bool // For NOT REGEXP / NOT RLIKE / NOT SIMILAR TO
-		SimilarTo bool // Pattern uses SQL SIMILAR TO syntax (% and _ wildcards)
+		Expr            Expr
+		Pattern         Expr
+		Negate          bool // For NOT REGEXP / NOT RLIKE / NOT SIMILAR TO
+		SimilarTo       bool // Pattern uses SQL SIMILAR TO syntax (% and _ wildcards)
+		CaseInsensitive bool // IREGEXP: match ignoring case
 	}
 	// BetweenExpr represents "expr [NOT] BETWEEN lo AND hi" when expr is not a
 	// plain column or literal. Unlike the desugared form
@@ -222,6 +223,8 @@ type Pragma struct {
 type CreateTable struct {
 	Name         string
 	Cols         []storage.Column
+	Uniques      []storage.UniqueConstraint // table-level UNIQUE (a, b, ...)
+	Checks       []storage.CheckClause      // table-level CHECK (expr)
 	IsTemp       bool
 	AsSelect     *Select
 	IfNotExists  bool     // IF NOT EXISTS clause
@@ -236,6 +239,11 @@ type DropTable struct {
 	IfExists bool // IF EXISTS clause
 }
 
+// TruncateTable represents a TRUNCATE [TABLE] statement.
+type TruncateTable struct {
+	Name string
+}
+
 // CreateIndex represents a CREATE INDEX statement.
 type CreateIndex struct {
 	Name        string
@@ -358,17 +366,27 @@ type DropTrigger struct {
 
 // AlterTable represents an ALTER TABLE statement.
 type AlterTable struct {
-	Table     string
-	AddColumn *storage.Column // For ADD COLUMN
-	// Future: DropColumn, RenameColumn, etc.
+	Table      string
+	AddColumn  *storage.Column // For ADD COLUMN
+	DropColumn string          // For DROP COLUMN
 }
 
 // Insert represents an INSERT statement.
 type Insert struct {
-	Table     string
-	Cols      []string
-	Rows      [][]Expr
-	Returning []SelectItem
+	Table      string
+	Cols       []string
+	Rows       [][]Expr
+	OnConflict *OnConflict // For INSERT ... ON CONFLICT (upsert)
+	Returning  []SelectItem
+}
+
+// OnConflict represents "ON CONFLICT (col, ...) DO UPDATE SET ..." or
+// "ON CONFLICT [(col, ...)] DO NOTHING". SET expressions may reference the
+// rejected candidate row as EXCLUDED.col.
+type OnConflict struct {
+	Cols      []string        // empty for DO NOTHING on any unique key
+	Sets      map[string]Expr // nil when DoNothing
+	DoNothing bool
 }
 
 // Update represents an UPDATE statement.
@@ -577,6 +595,18 @@ func (p *Parser) ParseStatement() (Statement, error) {
 // used for individual statements inside CREATE TRIGGER ... BEGIN ... END;
 // public callers must use ParseStatement above.
 func (p *Parser) parseStatement() (Statement, error) {
+	if p.isSavepointStart() && p.peek.Typ != tEOF {
+		return p.parseSavepointStmt()
+	}
+	if p.isCopyStart() {
+		return p.parseCopy()
+	}
+	if p.isShowStart() {
+		return p.parseShow()
+	}
+	if p.isIdentWord("DESCRIBE") && (p.peek.Typ == tIdent || p.peek.Typ == tKeyword) {
+		return p.parseDescribe()
+	}
 	if p.cur.Typ == tIdent {
 		return p.parseBareTableSelect()
 	}
@@ -591,6 +621,8 @@ func (p *Parser) parseStatement() (Statement, error) {
 		return p.parseAnalyze()
 	case "PRAGMA":
 		return p.parsePragma()
+	case "DESC":
+		return p.parseDescribe()
 	case "CREATE":
 		return p.parseCreate()
 	case "DROP":
@@ -603,6 +635,8 @@ func (p *Parser) parseStatement() (Statement, error) {
 		return p.parseUpdate()
 	case "DELETE":
 		return p.parseDelete()
+	case "TRUNCATE":
+		return p.parseTruncate()
 	case "CALL":
 		return p.parseCallProcedure()
 	case "REFRESH":
@@ -665,6 +699,12 @@ func (p *Parser) parseExplain() (Statement, error) {
 func (p *Parser) parseAnalyze() (Statement, error) {
 	p.next()
 	stmt := &Analyze{}
+	if p.cur.Typ == tKeyword && p.cur.Val == "TABLE" {
+		p.next()
+		if p.cur.Typ == tEOF || (p.cur.Typ == tSymbol && p.cur.Val == ";") {
+			return nil, p.errf("expected table name after ANALYZE TABLE")
+		}
+	}
 	if p.cur.Typ != tEOF && (p.cur.Typ != tSymbol || p.cur.Val != ";") {
 		stmt.Table = p.parseQualifiedIdentLike()
 		if stmt.Table == "" {
@@ -793,6 +833,10 @@ func (p *Parser) parseCreateNonTable() (Statement, bool, error) {
 		stmt, err := p.parseCreateVirtualTable()
 		return stmt, true, err
 	}
+	if p.isIdentWord("SEQUENCE") {
+		stmt, err := p.parseCreateSequence()
+		return stmt, true, err
+	}
 	return nil, false, nil
 }
 
@@ -847,11 +891,11 @@ func (p *Parser) parseCreateTable() (Statement, error) {
 		return nil, p.errf("expected table name")
 	}
 	if p.cur.Typ == tSymbol && p.cur.Val == "(" {
-		cols, err := p.parseColumnDefs()
+		cols, constraints, err := p.parseColumnDefs()
 		if err != nil {
 			return nil, err
 		}
-		return &CreateTable{Name: name, Cols: cols, IsTemp: isTemp, IfNotExists: ifNotExists}, nil
+		return &CreateTable{Name: name, Cols: cols, Uniques: constraints.uniques, Checks: constraints.checks, IsTemp: isTemp, IfNotExists: ifNotExists}, nil
 	}
 	if p.cur.Typ == tKeyword && p.cur.Val == "AS" {
 		p.next()
@@ -864,6 +908,18 @@ func (p *Parser) parseCreateTable() (Statement, error) {
 	return nil, p.errf("expected '(' or AS SELECT")
 }
 
+func (p *Parser) parseTruncate() (Statement, error) {
+	p.next()
+	if p.cur.Typ == tKeyword && p.cur.Val == "TABLE" {
+		p.next()
+	}
+	name := p.parseQualifiedIdentLike()
+	if name == "" {
+		return nil, p.errf("expected table name after TRUNCATE")
+	}
+	return &TruncateTable{Name: name}, nil
+}
+
 func (p *Parser) parseDrop() (Statement, error) {
 	p.next()
 
@@ -897,6 +953,10 @@ func (p *Parser) parseDrop() (Statement, error) {
 		return &DropJob{Name: name}, nil
 	}
 
+	if p.isIdentWord("SEQUENCE") {
+		return p.parseDropSequence()
+	}
+
 	// Check for DROP USER / DROP ROLE
 	if p.cur.Typ == tKeyword && (p.cur.Val == "USER" || p.cur.Val == "ROLE") {
 		return p.parseDropUserOrRole()
@@ -1761,6 +1821,18 @@ func (p *Parser) parseAlter() (Statement, error) {
 		return nil, p.errf("expected table name")
 	}
 
+	if p.cur.Typ == tKeyword && p.cur.Val == "DROP" {
+		p.next()
+		if p.cur.Typ == tKeyword && p.cur.Val == "COLUMN" {
+			p.next()
+		}
+		colName := p.parseIdentLike()
+		if colName == "" {
+			return nil, p.errf("expected column name")
+		}
+		return &AlterTable{Table: tableName, DropColumn: colName}, nil
+	}
+
 	if err := p.expectKeyword("ADD"); err != nil {
 		return nil, err
 	}
@@ -1787,6 +1859,9 @@ func (p *Parser) parseAlter() (Statement, error) {
 		DeclaredType: colType.declared,
 		Affinity:     colType.affinity,
 	}
+	if err := p.parseColumnConstraints(&col); err != nil {
+		return nil, err
+	}
 
 	return &AlterTable{
 		Table:     tableName,
@@ -1896,11 +1971,143 @@ func (p *Parser) parseInsert() (Statement, error) {
 	if err != nil {
 		return nil, err
 	}
+	onConflict, err := p.parseOnConflictClause()
+	if err != nil {
+		return nil, err
+	}
 	returning, err := p.parseReturningClause()
 	if err != nil {
 		return nil, err
 	}
-	return &Insert{Table: tname, Cols: cols, Rows: rows, Returning: returning}, nil
+	return &Insert{Table: tname, Cols: cols, Rows: rows, OnConflict: onConflict, Returning: returning}, nil
+}
+
+// parseOnConflictClause parses an optional
+// "ON CONFLICT (col, ...) DO UPDATE SET col = expr, ... | DO NOTHING".
+// As in PostgreSQL, DO NOTHING may omit the target to skip a row that
+// conflicts on any primary key or unique constraint.
+func (p *Parser) parseOnConflictClause() (*OnConflict, error) {
+	if p.cur.Typ != tKeyword || p.cur.Val != "ON" {
+		return nil, nil
+	}
+	p.next()
+	if !p.isIdentWord("CONFLICT") {
+		return nil, p.errf("expected CONFLICT after ON")
+	}
+	p.next()
+	oc := &OnConflict{}
+	if p.isIdentWord("DO") {
+		p.next()
+		if !p.isIdentWord("NOTHING") {
+			return nil, p.errf("ON CONFLICT DO UPDATE requires a conflict target")
+		}
+		p.next()
+		oc.DoNothing = true
+		return oc, nil
+	}
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	for {
+		id := p.parseIdentLike()
+		if id == "" {
+			return nil, p.errf("expected conflict column name")
+		}
+		oc.Cols = append(oc.Cols, id)
+		if p.cur.Typ == tSymbol && p.cur.Val == "," {
+			p.next()
+			continue
+		}
+		break
+	}
+	if err := p.expectSymbol(")"); err != nil {
+		return nil, err
+	}
+	if !p.isIdentWord("DO") {
+		return nil, p.errf("expected DO after ON CONFLICT target")
+	}
+	p.next()
+	if p.isIdentWord("NOTHING") {
+		p.next()
+		oc.DoNothing = true
+		return oc, nil
+	}
+	if err := p.expectKeyword("UPDATE"); err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("SET"); err != nil {
+		return nil, err
+	}
+	sets, err := p.parseSetAssignments()
+	if err != nil {
+		return nil, err
+	}
+	oc.Sets = sets
+	return oc, nil
+}
+
+// isIdentWord reports whether the current token is the non-reserved word w.
+// parseTrimArgs parses the arguments of TRIM, after the opening
+// parenthesis. Besides TRIM(str [, chars]) it accepts the standard form
+// TRIM([BOTH|LEADING|TRAILING] [chars] FROM str), which becomes TRIM, LTRIM
+// or RTRIM(str [, chars]). A side word is only taken as such when no
+// operator follows it, so a column named "leading" still works.
+func (p *Parser) parseTrimArgs() (Expr, error) {
+	name, side := "TRIM", false
+	if p.peek.Typ != tSymbol {
+		switch {
+		case p.isIdentWord("BOTH"):
+			side = true
+		case p.isIdentWord("LEADING"):
+			name, side = "LTRIM", true
+		case p.isIdentWord("TRAILING"):
+			name, side = "RTRIM", true
+		}
+		if side {
+			p.next()
+		}
+	}
+	var first Expr
+	if p.cur.Typ != tKeyword || p.cur.Val != "FROM" {
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		first = e
+	}
+	var args []Expr
+	switch {
+	case p.cur.Typ == tKeyword && p.cur.Val == "FROM":
+		p.next()
+		str, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		args = []Expr{str}
+		if first != nil {
+			args = append(args, first)
+		}
+	case side:
+		return nil, p.errf("expected FROM in TRIM")
+	default:
+		args = []Expr{first}
+		if p.cur.Typ == tSymbol && p.cur.Val == "," {
+			p.next()
+			chars, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, chars)
+		}
+	}
+	if err := p.expectSymbol(")"); err != nil {
+		return nil, err
+	}
+	return &FuncCall{Name: name, Args: args}, nil
+}
+
+func (p *Parser) isIdentWord(w string) bool {
+	return p.cur.Typ == tIdent && strings.EqualFold(p.cur.Val, w)
 }
 
 func (p *Parser) parseInsertValueRows() ([][]Expr, error) {
@@ -1944,6 +2151,28 @@ func (p *Parser) parseUpdate() (Statement, error) {
 	if err := p.expectKeyword("SET"); err != nil {
 		return nil, err
 	}
+	sets, err := p.parseSetAssignments()
+	if err != nil {
+		return nil, err
+	}
+	var where Expr
+	if p.cur.Typ == tKeyword && p.cur.Val == "WHERE" {
+		p.next()
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		where = e
+	}
+	returning, err := p.parseReturningClause()
+	if err != nil {
+		return nil, err
+	}
+	return &Update{Table: tname, Sets: sets, Where: where, Returning: returning}, nil
+}
+
+// parseSetAssignments parses "col = expr, ..." after SET.
+func (p *Parser) parseSetAssignments() (map[string]Expr, error) {
 	sets := map[string]Expr{}
 	for {
 		col := p.parseIdentLike()
@@ -1964,20 +2193,7 @@ func (p *Parser) parseUpdate() (Statement, error) {
 		}
 		break
 	}
-	var where Expr
-	if p.cur.Typ == tKeyword && p.cur.Val == "WHERE" {
-		p.next()
-		e, err := p.parseExpr()
-		if err != nil {
-			return nil, err
-		}
-		where = e
-	}
-	returning, err := p.parseReturningClause()
-	if err != nil {
-		return nil, err
-	}
-	return &Update{Table: tname, Sets: sets, Where: where, Returning: returning}, nil
+	return sets, nil
 }
 
 func (p *Parser) parseDelete() (Statement, error) {
@@ -2723,10 +2939,17 @@ func (p *Parser) parseUnionClause(sel *Select) error {
 			return err
 		}
 
+		// parseSelect consumed any further set operations into the right
+		// operand. Hoist them back onto this chain so that
+		// A UNION B EXCEPT C evaluates left-associatively as (A UNION B) EXCEPT C.
+		tail := rightSelect.Union
+		rightSelect.Union = nil
+
 		// Create the union clause
 		unionClause := &UnionClause{
 			Type:  unionType,
 			Right: rightSelect,
+			Next:  tail,
 		}
 
 		// Find the end of the union chain and append
@@ -2822,24 +3045,56 @@ func (p *Parser) parseJoinOnExpr() (Expr, error) {
 	return on, nil
 }
 
-func (p *Parser) parseColumnDefs() ([]storage.Column, error) {
+// tableConstraints collects the constraints written as separate items of a
+// CREATE TABLE column list rather than on a single column.
+type tableConstraints struct {
+	uniques []storage.UniqueConstraint
+	checks  []storage.CheckClause
+}
+
+func (p *Parser) parseColumnDefs() ([]storage.Column, tableConstraints, error) {
+	var constraints tableConstraints
 	if err := p.expectSymbol("("); err != nil {
-		return nil, err
+		return nil, constraints, err
 	}
 	cols := make([]storage.Column, 0, 8) // Pre-allocate for typical table
 	for {
+		// "CONSTRAINT name" may prefix a table-level CHECK or UNIQUE.
+		constraintName := ""
+		if p.isIdentWord("CONSTRAINT") && (p.peek.Typ == tIdent || p.peek.Typ == tKeyword) {
+			p.next()
+			constraintName = p.parseIdentLike()
+			if !p.isIdentWord("CHECK") && (p.cur.Typ != tKeyword || p.cur.Val != "UNIQUE") {
+				return nil, constraints, p.errf("expected CHECK or UNIQUE after CONSTRAINT %s", constraintName)
+			}
+		}
+
 		// A comma-separated item starting with FOREIGN is a table-level
 		// constraint ("FOREIGN KEY (col) REFERENCES tbl(col) ..."), not a
 		// column definition — apply it to the already-parsed column it
 		// names instead of appending a new column.
 		if p.cur.Typ == tKeyword && p.cur.Val == "FOREIGN" {
 			if err := p.parseTableLevelForeignKey(cols); err != nil {
-				return nil, err
+				return nil, constraints, err
+			}
+		} else if p.cur.Typ == tKeyword && p.cur.Val == "UNIQUE" && p.peek.Typ == tSymbol && p.peek.Val == "(" {
+			u, err := p.parseTableLevelUnique(cols)
+			if err != nil {
+				return nil, constraints, err
+			}
+			if u != nil {
+				constraints.uniques = append(constraints.uniques, *u)
 			}
+		} else if p.isIdentWord("CHECK") && p.peek.Typ == tSymbol && p.peek.Val == "(" {
+			text, err := p.parseCheckExpr()
+			if err != nil {
+				return nil, constraints, err
+			}
+			constraints.checks = append(constraints.checks, storage.CheckClause{Name: constraintName, Expr: text})
 		} else {
 			col, err := p.parseSingleColumnDef()
 			if err != nil {
-				return nil, err
+				return nil, constraints, err
 			}
 			cols = append(cols, col)
 		}
@@ -2849,11 +3104,76 @@ func (p *Parser) parseColumnDefs() ([]storage.Column, error) {
 			continue
 		}
 		if err := p.expectSymbol(")"); err != nil {
-			return nil, err
+			return nil, constraints, err
 		}
 		break
 	}
-	return cols, nil
+	return cols, constraints, nil
+}
+
+// parseCheckExpr parses "CHECK (expr)" and returns the condition's source
+// text. The text, not the AST, is stored with the table and re-parsed on use,
+// as trigger WHEN clauses are.
+func (p *Parser) parseCheckExpr() (string, error) {
+	p.next() // consume CHECK
+	if err := p.expectSymbol("("); err != nil {
+		return "", err
+	}
+	startPos := p.cur.Pos
+	if _, err := p.parseExpr(); err != nil {
+		return "", err
+	}
+	endPos := p.cur.Pos
+	if err := p.expectSymbol(")"); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(p.lx.s[startPos:endPos]), nil
+}
+
+// parseTableLevelUnique parses "UNIQUE (col, ...)". A single column is marked
+// UNIQUE on the column itself and nil is returned; several columns become a
+// table-level constraint.
+func (p *Parser) parseTableLevelUnique(cols []storage.Column) (*storage.UniqueConstraint, error) {
+	p.next() // consume UNIQUE
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	var names []string
+	for {
+		name := p.parseIdentLike()
+		if name == "" {
+			return nil, p.errf("expected column name in UNIQUE (...)")
+		}
+		if findColumnDef(cols, name) < 0 {
+			return nil, p.errf("UNIQUE (%s): no such column in this table", name)
+		}
+		names = append(names, name)
+		if p.cur.Typ == tSymbol && p.cur.Val == "," {
+			p.next()
+			continue
+		}
+		break
+	}
+	if err := p.expectSymbol(")"); err != nil {
+		return nil, err
+	}
+	if len(names) == 1 {
+		i := findColumnDef(cols, names[0])
+		if cols[i].Constraint == storage.NoConstraint {
+			cols[i].Constraint = storage.Unique
+			return nil, nil
+		}
+	}
+	return &storage.UniqueConstraint{Columns: names}, nil
+}
+
+func findColumnDef(cols []storage.Column, name string) int {
+	for i := range cols {
+		if strings.EqualFold(cols[i].Name, name) {
+			return i
+		}
+	}
+	return -1
 }
 
 // parseTableLevelForeignKey parses "FOREIGN KEY (col) REFERENCES tbl(col)
@@ -2936,7 +3256,31 @@ func (p *Parser) parseSingleColumnDef() (storage.Column, error) {
 }
 
 func (p *Parser) parseColumnConstraints(col *storage.Column) error {
-	for p.cur.Typ == tKeyword {
+	for p.cur.Typ == tKeyword || p.isIdentWord("CHECK") || p.isIdentWord("CONSTRAINT") || p.isIdentWord("FULLTEXT") {
+		if p.isIdentWord("FULLTEXT") {
+			if col.Type != storage.TextType && col.Type != storage.StringType {
+				return p.errf("FULLTEXT requires a TEXT column, %q is %s", col.Name, col.Type)
+			}
+			p.next()
+			col.FullText = true
+			continue
+		}
+		if p.isIdentWord("CONSTRAINT") {
+			// Column constraint names are accepted but not retained.
+			p.next()
+			if p.parseIdentLike() == "" {
+				return p.errf("expected constraint name")
+			}
+			continue
+		}
+		if p.isIdentWord("CHECK") {
+			text, err := p.parseCheckExpr()
+			if err != nil {
+				return err
+			}
+			col.Check = text
+			continue
+		}
 		switch p.cur.Val {
 		case "NOT":
 			p.next()
@@ -2975,21 +3319,28 @@ func (p *Parser) parseColumnConstraints(col *storage.Column) error {
 	return nil
 }
 
-// parseColumnDefault accepts deterministic literal defaults. Dynamic defaults
-// (for example CURRENT_TIMESTAMP) intentionally remain unsupported until they
-// can be represented consistently in snapshots and read-only replicas.
+// parseColumnDefault folds literal defaults into DefaultValue. Any other
+// expression (for example CURRENT_TIMESTAMP) is kept as source text and
+// evaluated per inserted row; the computed value is what snapshots, the WAL
+// and replicas see. Column references are rejected because a default is
+// computed before the row exists.
 func (p *Parser) parseColumnDefault(col *storage.Column) error {
 	p.next() // DEFAULT
+	startPos := p.cur.Pos
 	expr, err := p.parseExpr()
 	if err != nil {
 		return err
 	}
-	v, ok := defaultLiteralValue(expr)
-	if !ok {
-		return p.errf("DEFAULT for column %q must be a literal", col.Name)
-	}
+	endPos := p.cur.Pos
 	col.HasDefault = true
-	col.DefaultValue = v
+	if v, ok := defaultLiteralValue(expr); ok {
+		col.DefaultValue = v
+		return nil
+	}
+	if len(collectVarRefNames(expr)) > 0 {
+		return p.errf("DEFAULT for column %q cannot reference columns", col.Name)
+	}
+	col.DefaultExpr = strings.TrimSpace(p.lx.s[startPos:endPos])
 	return nil
 }
 
@@ -3310,6 +3661,9 @@ func (p *Parser) isColumnTypeTerminator() bool {
 	if p.cur.Typ == tSymbol && (p.cur.Val == "," || p.cur.Val == ")") {
 		return true
 	}
+	if p.isIdentWord("CHECK") || p.isIdentWord("CONSTRAINT") || p.isIdentWord("FULLTEXT") {
+		return true
+	}
 	if p.cur.Typ != tKeyword {
 		return false
 	}
@@ -3485,8 +3839,15 @@ func (p *Parser) parseCmp() (Expr, error) {
 	return l, nil
 }
 
+// consumeCmpNot consumes a NOT that negates the comparison that follows it.
+// A NOT before anything else is left alone, so a column definition such as
+// DEFAULT 5 NOT NULL still sees its NOT NULL constraint.
 func (p *Parser) consumeCmpNot() bool {
-	if p.cur.Typ == tKeyword && p.cur.Val == "NOT" {
+	if p.cur.Typ != tKeyword || p.cur.Val != "NOT" || p.peek.Typ != tKeyword {
+		return false
+	}
+	switch p.peek.Val {
+	case "BETWEEN", "IN", "LIKE", "ILIKE", "GLOB", "REGEXP", "RLIKE", "IREGEXP", "SIMILAR":
 		p.next()
 		return true
 	}
@@ -3559,7 +3920,8 @@ func (p *Parser) parseCmpIn(l Expr, negate bool) (Expr, bool, error) {
 		return nil, true, err
 	}
 	var values []Expr
-	for {
+	// An empty list is accepted: x IN () is always false.
+	for !(len(values) == 0 && p.cur.Typ == tSymbol && p.cur.Val == ")") {
 		e, err := p.parseExpr()
 		if err != nil {
 			return nil, true, err
@@ -3625,15 +3987,16 @@ func (p *Parser) parseCmpPatternAndEscape() (Expr, Expr, error) {
 }
 
 func (p *Parser) parseCmpRegexp(l Expr, negate bool) (Expr, bool, error) {
-	if p.cur.Typ != tKeyword || (p.cur.Val != "REGEXP" && p.cur.Val != "RLIKE") {
+	if p.cur.Typ != tKeyword || (p.cur.Val != "REGEXP" && p.cur.Val != "RLIKE" && p.cur.Val != "IREGEXP") {
 		return nil, false, nil
 	}
+	caseInsensitive := p.cur.Val == "IREGEXP"
 	p.next()
 	pattern, err := p.parseAddSub()
 	if err != nil {
 		return nil, true, err
 	}
-	return &RegexpExpr{Expr: l, Pattern: pattern, Negate: negate}, true, nil
+	return &RegexpExpr{Expr: l, Pattern: pattern, Negate: negate, CaseInsensitive: caseInsensitive}, true, nil
 }
 
 func (p *Parser) parseCmpSimilar(l Expr, negate bool) (Expr, bool, error) {
@@ -3801,8 +4164,14 @@ func (p *Parser) parsePrimary() (Expr, error) {
 		// Otherwise treat the keyword as a variable/column reference
 		name := p.cur.Val
 		p.next()
+		if isNiladicTimeFunc(name) {
+			return &FuncCall{Name: strings.ToUpper(name)}, nil
+		}
 		return newVarRef(name), nil
 	case tIdent:
+		if strings.EqualFold(p.cur.Val, "INTERVAL") && (p.peek.Typ == tNumber || p.peek.Typ == tString) {
+			return p.parseIntervalLiteral()
+		}
 		name := p.cur.Val
 		p.next()
 		// Check if it's a function call
@@ -3811,6 +4180,9 @@ func (p *Parser) parsePrimary() (Expr, error) {
 			// Put the current position back and parse as function
 			return p.parseFuncCallWithName(name)
 		}
+		if isNiladicTimeFunc(name) {
+			return &FuncCall{Name: strings.ToUpper(name)}, nil
+		}
 		return newVarRef(name), nil
 	case tSymbol:
 		if p.cur.Val == "(" {
@@ -3829,6 +4201,34 @@ func (p *Parser) parsePrimary() (Expr, error) {
 	return nil, p.errf("unexpected token %q", p.cur.Val)
 }
 
+// parseIntervalLiteral parses INTERVAL <n> <unit> and INTERVAL '<n> <unit>'
+// into a Literal holding a time.Duration or MonthInterval.
+func (p *Parser) parseIntervalLiteral() (Expr, error) {
+	p.next() // consume INTERVAL
+	amount, err := strconv.ParseFloat(strings.TrimSpace(p.cur.Val), 64)
+	if err != nil {
+		if p.cur.Typ != tString {
+			return nil, p.errf("invalid INTERVAL amount %q", p.cur.Val)
+		}
+		v, err := parseIntervalString(p.cur.Val)
+		if err != nil {
+			return nil, p.errf("%v", err)
+		}
+		p.next()
+		return &Literal{Val: v}, nil
+	}
+	p.next()
+	if p.cur.Typ != tIdent && p.cur.Typ != tKeyword {
+		return nil, p.errf("expected INTERVAL unit")
+	}
+	v, err := newIntervalValue(amount, p.cur.Val)
+	if err != nil {
+		return nil, p.errf("%v", err)
+	}
+	p.next()
+	return &Literal{Val: v}, nil
+}
+
 //nolint:gocyclo // CASE parsing naturally involves multiple WHEN/ELSE branches.
 func (p *Parser) parseCaseExpr() (Expr, error) {
 	p.next() // consume CASE
@@ -3881,6 +4281,16 @@ func (p *Parser) parseFuncCall() (Expr, error) {
 	return p.parseFuncCallWithName(name)
 }
 
+// isNiladicTimeFunc reports whether name is one of the standard SQL date/time
+// functions that may be written without parentheses.
+func isNiladicTimeFunc(name string) bool {
+	switch strings.ToUpper(name) {
+	case "CURRENT_DATE", "CURRENT_TIME", "CURRENT_TIMESTAMP":
+		return true
+	}
+	return false
+}
+
 //nolint:gocyclo // Function-call grammar involves numerous special cases.
 func (p *Parser) parseFuncCallWithName(name string) (Expr, error) {
 	// Normalize the function name once at parse time. SQL function names are
@@ -3909,6 +4319,15 @@ func (p *Parser) parseFuncCallWithName(name string) (Expr, error) {
 		}
 		typeName := p.cur.Val
 		p.next()
+		// Keep a length such as VARCHAR(10) with the type name; castValue
+		// applies it.
+		if p.cur.Typ == tSymbol && p.cur.Val == "(" {
+			args, err := p.skipTypeArguments()
+			if err != nil {
+				return nil, err
+			}
+			typeName += args
+		}
 		if err := p.expectSymbol(")"); err != nil {
 			return nil, err
 		}
@@ -3916,6 +4335,26 @@ func (p *Parser) parseFuncCallWithName(name string) (Expr, error) {
 		return &FuncCall{Name: name, Args: []Expr{expr, &Literal{Val: typeName}}}, nil
 	}
 
+	if name == "TRIM" {
+		return p.parseTrimArgs()
+	}
+
+	// EXTRACT(part FROM expr) is lowered to EXTRACT('part', expr).
+	if name == "EXTRACT" && (p.cur.Typ == tIdent || p.cur.Typ == tKeyword) &&
+		p.peek.Typ == tKeyword && p.peek.Val == "FROM" {
+		part := strings.ToUpper(p.cur.Val)
+		p.next()
+		p.next()
+		expr, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+		return &FuncCall{Name: name, Args: []Expr{&Literal{Val: part}, expr}}, nil
+	}
+
 	// Handle COUNT(*)
 	if name == "COUNT" && p.cur.Typ == tSymbol && p.cur.Val == "*" {
 		p.next()
@@ -3950,6 +4389,9 @@ func (p *Parser) parseFuncCallWithName(name string) (Expr, error) {
 	if err := p.expectSymbol(")"); err != nil {
 		return nil, err
 	}
+	if name == "MATCH" && p.isIdentWord("AGAINST") {
+		return p.parseMatchAgainst(args)
+	}
 
 	// Check for OVER clause (window functions)
 	var overClause *OverClause
@@ -4103,6 +4545,12 @@ func (p *Parser) parseWindowFrame() (*WindowFrame, error) {
 		frame.EndType = "CURRENT"
 		frame.EndValue = 0
 	}
+	if frame.StartType == "UNBOUNDED_FOLLOWING" {
+		return nil, p.errf("frame start cannot be UNBOUNDED FOLLOWING")
+	}
+	if frame.EndType == "UNBOUNDED_PRECEDING" {
+		return nil, p.errf("frame end cannot be UNBOUNDED PRECEDING")
+	}
 
 	return frame, nil
 }
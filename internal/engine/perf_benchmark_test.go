@@ -1,6 +1,6 @@
 // Benchmarks covering execution-engine hotspots: single- and multi-column
-// GROUP BY raw paths, ORDER BY at scale, JOIN at both the nested-loop and
-// hash-join thresholds, plain table scans (Row map allocation cost), row-wide
+// GROUP BY raw paths, ORDER BY at scale, JOIN by nested loop and by hashing,
+// plain table scans (Row map allocation cost), row-wide
 // LIKE/REGEXP scans, and FTS_SEARCH repeated-query behavior (which exercises
 // the document cache).
 package engine
@@ -8,6 +8,7 @@ package engine
 import (
 	"context"
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/SimonWaldherr/tinySQL/internal/storage"
@@ -133,19 +134,21 @@ func setupJoinTables(b *testing.B, leftRows, rightRows int) *storage.DB {
 	return db
 }
 
-// BenchmarkJoinNestedLoopBelowThreshold sizes both sides just under the
-// 500-row hash-join cutover (internal/engine/exec.go processInnerJoin), so
-// this benchmark specifically measures the nested-loop + mergeRows path.
-func BenchmarkJoinNestedLoopBelowThreshold(b *testing.B) {
-	db := setupJoinTables(b, 400, 400)
-	runBench(b, db, `SELECT l.id, l.val, r.extra FROM l JOIN r ON l.id = r.id`)
+// BenchmarkJoinNestedLoop and BenchmarkJoinHashJoin run the same equi-join
+// through the general join path (the aggregate keeps it off the two-table raw
+// fast path), once with hashing disabled and once with it enabled.
+const joinBenchQuery = `SELECT COUNT(*) FROM l JOIN r ON l.id = r.id`
+
+func BenchmarkJoinNestedLoop(b *testing.B) {
+	db := setupJoinTables(b, 2000, 2000)
+	defer func(old int) { hashJoinMinPairs = old }(hashJoinMinPairs)
+	hashJoinMinPairs = math.MaxInt
+	runBench(b, db, joinBenchQuery)
 }
 
-// BenchmarkJoinHashJoinAboveThreshold exceeds the cutover on both sides,
-// exercising HashJoinOptimizer.processHashJoin instead of the nested loop.
-func BenchmarkJoinHashJoinAboveThreshold(b *testing.B) {
-	db := setupJoinTables(b, 5000, 5000)
-	runBench(b, db, `SELECT l.id, l.val, r.extra FROM l JOIN r ON l.id = r.id`)
+func BenchmarkJoinHashJoin(b *testing.B) {
+	db := setupJoinTables(b, 2000, 2000)
+	runBench(b, db, joinBenchQuery)
 }
 
 // ─────────────────────────── Row scan / allocation ─────────────────────────
@@ -0,0 +1,217 @@
+package engine
+
+import "strings"
+
+// Predicate pushdown for the general join path. Before the join loop runs,
+// the WHERE clause is split into AND terms; a term that references columns
+// of exactly one source is applied to that source's rows as soon as they are
+// loaded, so rows it rejects never enter a join. The simple two-table hash
+// join (buildSimpleJoinFilters) does the same on raw rows.
+//
+// A term is pushed only when moving it cannot change the result:
+//   - the source is not on the NULL-filled side of an outer join, whose
+//     padded rows would otherwise escape the filter;
+//   - the term is one of the shapes simpleJoinPushdownSafe accepts, so it
+//     cannot raise an error for a row that would never have joined;
+//   - every column resolves unambiguously to that source. Unqualified names
+//     are attributed only when each source's columns are known up front.
+//
+// OR is never split: an OR spanning two sources stays in the WHERE clause.
+
+// disablePredicatePushdown lets tests compare against the unoptimized plan.
+var disablePredicatePushdown bool
+
+type pushdownSource struct {
+	alias string
+	// cols holds lower-case column names; nil when they are only known once
+	// the source has been evaluated (subqueries, table functions, views).
+	cols map[string]bool
+	// nullable marks the NULL-supplying side of an outer join.
+	nullable bool
+	// ambiguous marks an alias used by more than one source.
+	ambiguous bool
+}
+
+type pushdownScope struct {
+	sources []pushdownSource
+}
+
+// newPushdownScope describes the FROM source (index 0) and each joined
+// source (index i+1 for s.Joins[i]).
+func newPushdownScope(env ExecEnv, s *Select) *pushdownScope {
+	items := make([]FromItem, 0, len(s.Joins)+1)
+	items = append(items, s.From)
+	for _, j := range s.Joins {
+		items = append(items, j.Right)
+	}
+	sc := &pushdownScope{sources: make([]pushdownSource, len(items))}
+	seen := make(map[string]int, len(items))
+	for i, item := range items {
+		src := &sc.sources[i]
+		src.alias = strings.ToLower(aliasOr(item))
+		src.cols = pushdownSourceCols(env, item)
+		if prev, dup := seen[src.alias]; dup {
+			src.ambiguous = true
+			sc.sources[prev].ambiguous = true
+		}
+		seen[src.alias] = i
+	}
+	for i, j := range s.Joins {
+		switch j.Type {
+		case JoinLeft:
+			sc.sources[i+1].nullable = true
+		case JoinRight:
+			for k := 0; k <= i; k++ {
+				sc.sources[k].nullable = true
+			}
+		case JoinFull:
+			for k := 0; k <= i+1; k++ {
+				sc.sources[k].nullable = true
+			}
+		}
+	}
+	return sc
+}
+
+func pushdownSourceCols(env ExecEnv, item FromItem) map[string]bool {
+	if item.Table == "" || item.Subquery != nil || item.TableFunc != nil {
+		return nil
+	}
+	var names []string
+	if cte, ok := env.ctes[strings.ToLower(item.Table)]; ok {
+		names = cte.Cols
+	} else if t, err := env.db.Get(env.tenant, item.Table); err == nil {
+		for _, c := range t.Cols {
+			names = append(names, c.Name)
+		}
+	} else {
+		return nil
+	}
+	cols := make(map[string]bool, len(names))
+	for _, n := range names {
+		cols[strings.ToLower(n)] = true
+	}
+	return cols
+}
+
+// planPredicatePushdown returns the filter for the FROM rows, one filter per
+// join (nil where nothing is pushed) and the WHERE terms left for after the
+// joins.
+func planPredicatePushdown(env ExecEnv, s *Select) (from Expr, joins []Expr, remaining Expr) {
+	if disablePredicatePushdown || len(s.Joins) == 0 || s.Where == nil {
+		return nil, nil, s.Where
+	}
+	sc := newPushdownScope(env, s)
+	remaining = s.Where
+	joins = make([]Expr, len(s.Joins))
+	for i, src := range sc.sources {
+		if src.nullable || src.ambiguous {
+			continue
+		}
+		var pushed Expr
+		pushed, remaining = sc.splitPredicate(remaining, src.alias)
+		if i == 0 {
+			from = pushed
+		} else {
+			joins[i-1] = pushed
+		}
+	}
+	return from, joins, remaining
+}
+
+// splitPredicate separates the AND terms of where that reference only
+// tableAlias and are safe to evaluate before the join.
+func (sc *pushdownScope) splitPredicate(where Expr, tableAlias string) (pushed Expr, remaining Expr) {
+	var terms, pushedTerms, rest []Expr
+	collectAndTerms(where, &terms)
+	alias := strings.ToLower(tableAlias)
+	for _, term := range terms {
+		if simpleJoinPushdownSafe(term) && sc.termSource(term) == alias {
+			pushedTerms = append(pushedTerms, term)
+		} else {
+			rest = append(rest, term)
+		}
+	}
+	if len(pushedTerms) == 0 {
+		return nil, where
+	}
+	return joinAndTerms(pushedTerms), joinAndTerms(rest)
+}
+
+func collectAndTerms(e Expr, out *[]Expr) {
+	if e == nil {
+		return
+	}
+	if b, ok := e.(*Binary); ok && b.Op == "AND" {
+		collectAndTerms(b.Left, out)
+		collectAndTerms(b.Right, out)
+		return
+	}
+	*out = append(*out, e)
+}
+
+// termSource returns the alias of the single source every column in e
+// belongs to, or "" when e references no column, several sources, or a
+// column that cannot be attributed.
+func (sc *pushdownScope) termSource(e Expr) string {
+	alias := ""
+	ok := true
+	walkVarRefs(e, func(v *VarRef) {
+		src := sc.varSource(v)
+		if src == "" || (alias != "" && src != alias) {
+			ok = false
+			return
+		}
+		alias = src
+	})
+	if !ok {
+		return ""
+	}
+	return alias
+}
+
+func (sc *pushdownScope) varSource(v *VarRef) string {
+	name := strings.ToLower(v.Name)
+	if dot := strings.LastIndexByte(name, '.'); dot >= 0 {
+		qual, col := name[:dot], name[dot+1:]
+		for _, src := range sc.sources {
+			if src.alias == qual && (src.cols == nil || src.cols[col]) {
+				return src.alias
+			}
+		}
+		return ""
+	}
+	found := ""
+	for _, src := range sc.sources {
+		if src.cols == nil {
+			return ""
+		}
+		if src.cols[name] {
+			if found != "" {
+				return ""
+			}
+			found = src.alias
+		}
+	}
+	return found
+}
+
+// walkVarRefs visits the column references of the expression shapes that
+// simpleJoinPushdownSafe admits.
+func walkVarRefs(e Expr, fn func(*VarRef)) {
+	switch ex := e.(type) {
+	case *VarRef:
+		fn(ex)
+	case *IsNull:
+		walkVarRefs(ex.Expr, fn)
+	case *Binary:
+		walkVarRefs(ex.Left, fn)
+		walkVarRefs(ex.Right, fn)
+	case *LikeExpr:
+		walkVarRefs(ex.Expr, fn)
+	case *RegexpExpr:
+		walkVarRefs(ex.Expr, fn)
+	case *InExpr:
+		walkVarRefs(ex.Expr, fn)
+	}
+}
@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+func pushdownTestDB(t *testing.T) *storage.DB {
+	t.Helper()
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE a (id INT, x INT, name TEXT)`)
+	execSQL(t, db, `CREATE TABLE b (aid INT, y INT, note TEXT)`)
+	execSQL(t, db, `CREATE TABLE c (bid INT, z INT)`)
+	for i := 1; i <= 40; i++ {
+		execSQL(t, db, fmt.Sprintf(`INSERT INTO a VALUES (%d, %d, 'n%d')`, i, i%5, i%3))
+		if i%4 != 0 {
+			execSQL(t, db, fmt.Sprintf(`INSERT INTO b VALUES (%d, %d, NULL)`, i+2, i%7))
+		}
+		execSQL(t, db, fmt.Sprintf(`INSERT INTO c VALUES (%d, %d)`, i%9, i))
+	}
+	return db
+}
+
+func whereExpr(cond string) Expr {
+	return mustParse("SELECT 1 FROM t WHERE " + cond).(*Select).Where
+}
+
+func TestPlanPredicatePushdown(t *testing.T) {
+	db := pushdownTestDB(t)
+	env := ExecEnv{ctx: context.Background(), tenant: "default", db: db}
+	for _, tc := range []struct {
+		query     string
+		from      string
+		joins     []string
+		remaining string
+	}{
+		{
+			query:     `SELECT * FROM a JOIN b ON a.id = b.aid WHERE a.x = 1 AND b.y > 2 AND a.id < b.y`,
+			from:      "a.x = 1",
+			joins:     []string{"b.y > 2"},
+			remaining: "a.id < b.y",
+		},
+		{
+			// Unqualified columns are attributed when exactly one table has them.
+			query:     `SELECT * FROM a JOIN b ON a.id = b.aid WHERE name = 'n1' AND note IS NULL`,
+			from:      "name = 'n1'",
+			joins:     []string{"note IS NULL"},
+			remaining: "",
+		},
+		{
+			// An OR spanning both tables is not split or pushed.
+			query:     `SELECT * FROM a JOIN b ON a.id = b.aid WHERE a.x = 1 OR b.y = 2`,
+			joins:     []string{""},
+			remaining: "a.x = 1 OR b.y = 2",
+		},
+		{
+			// The NULL-filled side of a LEFT JOIN keeps its filter after the join.
+			query:     `SELECT * FROM a LEFT JOIN b ON a.id = b.aid WHERE a.x = 1 AND b.y = 2`,
+			from:      "a.x = 1",
+			joins:     []string{""},
+			remaining: "b.y = 2",
+		},
+		{
+			query:     `SELECT * FROM a FULL JOIN b ON a.id = b.aid JOIN c ON c.bid = b.y WHERE a.x = 1 AND c.z > 3`,
+			joins:     []string{"", "c.z > 3"},
+			remaining: "a.x = 1",
+		},
+	} {
+		from, joins, remaining := planPredicatePushdown(env, mustParse(tc.query).(*Select))
+		check := func(what string, got Expr, want string) {
+			var wantExpr Expr
+			if want != "" {
+				wantExpr = whereExpr(want)
+			}
+			if !reflect.DeepEqual(got, wantExpr) {
+				t.Errorf("%s\n  %s = %#v, want %s", tc.query, what, got, want)
+			}
+		}
+		check("FROM filter", from, tc.from)
+		if len(joins) != len(tc.joins) {
+			t.Fatalf("%s: %d join filters, want %d", tc.query, len(joins), len(tc.joins))
+		}
+		for i, want := range tc.joins {
+			check(fmt.Sprintf("join %d filter", i), joins[i], want)
+		}
+		check("remaining", remaining, tc.remaining)
+	}
+}
+
+func TestPredicatePushdownLimitsJoinInput(t *testing.T) {
+	db := pushdownTestDB(t)
+	orig := maxJoinRows
+	maxJoinRows = 100
+	defer func() { maxJoinRows = orig }()
+
+	// a × c is 1600 pairs, but only one row of a survives its filter.
+	q := `SELECT a.id, c.z FROM a CROSS JOIN c WHERE a.id = 7 AND c.z <= 3`
+	rs := execSQL(t, db, q)
+	if len(rs.Rows) != 3 {
+		t.Fatalf("rows = %d, want 3", len(rs.Rows))
+	}
+
+	disablePredicatePushdown = true
+	defer func() { disablePredicatePushdown = false }()
+	if _, err := Execute(context.Background(), db, "default", mustParse(q)); err == nil || !strings.Contains(err.Error(), "cross join") {
+		t.Fatalf("unoptimized plan: error %v, want the cross join row cap", err)
+	}
+}
+
+func TestPredicatePushdownMatchesUnoptimizedPlan(t *testing.T) {
+	db := pushdownTestDB(t)
+	queries := []string{
+		`SELECT a.id, b.y FROM a JOIN b ON a.id = b.aid WHERE a.x = 1 AND b.y > 2 ORDER BY a.id, b.y`,
+		`SELECT a.id, b.y FROM a LEFT JOIN b ON a.id = b.aid WHERE a.x IN (1, 2) AND b.y IS NULL ORDER BY a.id`,
+		`SELECT a.id, b.y FROM a LEFT JOIN b ON a.id = b.aid WHERE b.y = 3 OR a.x = 0 ORDER BY a.id, b.y`,
+		`SELECT a.id, b.aid FROM a RIGHT JOIN b ON a.id = b.aid WHERE a.x = 2 ORDER BY b.aid`,
+		`SELECT a.id, b.aid FROM a RIGHT JOIN b ON a.id = b.aid WHERE b.y >= 4 ORDER BY b.aid`,
+		`SELECT a.id, b.aid FROM a FULL JOIN b ON a.id = b.aid WHERE a.id IS NULL OR b.aid > 30 ORDER BY b.aid, a.id`,
+		`SELECT a.id, b.y, c.z FROM a JOIN b ON a.id = b.aid JOIN c ON c.bid = b.y WHERE name LIKE 'n1%' AND c.z < 20 AND a.x <> b.y ORDER BY a.id, b.y, c.z`,
+		`SELECT COUNT(*) AS n FROM a JOIN b ON a.id = b.aid JOIN c ON c.bid = a.x WHERE note IS NULL AND z BETWEEN 5 AND 30`,
+		`SELECT a.id FROM a WHERE EXISTS (SELECT 1 FROM b JOIN c ON c.bid = b.y WHERE b.aid = a.id AND c.z > 35) ORDER BY a.id`,
+		`SELECT l.id, r.id AS rid FROM a l JOIN a r ON l.x = r.x WHERE l.id = 3 AND r.id > 30 ORDER BY rid`,
+	}
+	for _, q := range queries {
+		pushed, err := Execute(context.Background(), db, "default", mustParse(q))
+		if err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+		disablePredicatePushdown = true
+		plain, err := Execute(context.Background(), db, "default", mustParse(q))
+		disablePredicatePushdown = false
+		if err != nil {
+			t.Fatalf("%s (unoptimized): %v", q, err)
+		}
+		if len(plain.Rows) == 0 {
+			t.Errorf("%s: no rows, the comparison proves nothing", q)
+		}
+		if !reflect.DeepEqual(pushed.Rows, plain.Rows) {
+			t.Errorf("%s\n  pushdown:    %v\n  unoptimized: %v", q, pushed.Rows, plain.Rows)
+		}
+	}
+}
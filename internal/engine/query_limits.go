@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+// ErrQueryMemoryExceeded is returned when a row set built while executing a
+// statement is estimated to exceed QueryOptions.MaxMemoryBytes.
+var ErrQueryMemoryExceeded = errors.New("query memory limit exceeded")
+
+// ErrQueryRowLimitExceeded is returned when a statement's result has more
+// rows than QueryOptions.MaxRows.
+var ErrQueryRowLimitExceeded = errors.New("query row limit exceeded")
+
+// QueryOptions bounds the resources one statement may use. A zero field
+// means no limit.
+type QueryOptions struct {
+	// MaxMemoryBytes caps the estimated size of every intermediate row set
+	// (scan, join, filter, projection) and of the result.
+	MaxMemoryBytes int64
+	// MaxRows caps the number of rows in the statement's result. A larger
+	// result fails the statement rather than being truncated.
+	MaxRows int
+	// MaxRecursionDepth caps the iterations of each WITH RECURSIVE CTE.
+	// Unlike the other fields, zero means DefaultMaxRecursionDepth rather
+	// than no limit, so a runaway recursion always terminates.
+	MaxRecursionDepth int
+}
+
+// DefaultMaxRecursionDepth is the WITH RECURSIVE iteration cap used when
+// QueryOptions.MaxRecursionDepth is zero.
+const DefaultMaxRecursionDepth = 1000
+
+// maxRecursionDepth returns the statement's WITH RECURSIVE iteration cap.
+func maxRecursionDepth(env ExecEnv) int {
+	if env.limits != nil && env.limits.MaxRecursionDepth > 0 {
+		return env.limits.MaxRecursionDepth
+	}
+	return DefaultMaxRecursionDepth
+}
+
+type queryOptionsContextKey struct{}
+
+func queryOptionsFromContext(ctx context.Context) *QueryOptions {
+	if ctx == nil {
+		return nil
+	}
+	o, _ := ctx.Value(queryOptionsContextKey{}).(*QueryOptions)
+	return o
+}
+
+// ExecuteWithOptions is Execute with per-statement resource limits. A
+// statement that breaches a limit fails with ErrQueryMemoryExceeded or
+// ErrQueryRowLimitExceeded and, like any failed DML, leaves no changes
+// behind.
+func ExecuteWithOptions(ctx context.Context, db *storage.DB, tenant string, stmt Statement, opts QueryOptions) (*ResultSet, error) {
+	if opts.MaxMemoryBytes > 0 || opts.MaxRows > 0 || opts.MaxRecursionDepth > 0 {
+		ctx = context.WithValue(ctx, queryOptionsContextKey{}, &opts)
+	}
+	return Execute(ctx, db, tenant, stmt)
+}
+
+// checkRowSetMemory fails once the estimated size of rows, produced by the
+// named stage, exceeds the statement's memory limit.
+func checkRowSetMemory(env ExecEnv, stage string, rows []Row) error {
+	if env.limits == nil || env.limits.MaxMemoryBytes <= 0 || len(rows) == 0 {
+		return nil
+	}
+	if est := estimateRowSetBytes(rows); est > env.limits.MaxMemoryBytes {
+		return fmt.Errorf("%w: %s holds %d rows (~%d bytes), limit is %d bytes",
+			ErrQueryMemoryExceeded, stage, len(rows), est, env.limits.MaxMemoryBytes)
+	}
+	return nil
+}
+
+// checkResultLimits applies both limits to the result a statement returns.
+func checkResultLimits(env ExecEnv, rs *ResultSet) error {
+	if env.limits == nil || rs == nil {
+		return nil
+	}
+	if env.limits.MaxRows > 0 && len(rs.Rows) > env.limits.MaxRows {
+		return fmt.Errorf("%w: result has %d rows, limit is %d",
+			ErrQueryRowLimitExceeded, len(rs.Rows), env.limits.MaxRows)
+	}
+	return checkRowSetMemory(env, "result", rs.Rows)
+}
+
+// rowSizeSample is how many rows estimateRowSetBytes measures; the rest are
+// assumed to be of the same average size.
+const rowSizeSample = 16
+
+// estimateRowSetBytes approximates the heap held by rows as their count
+// times the average size of evenly spaced sample rows.
+func estimateRowSetBytes(rows []Row) int64 {
+	step := len(rows)/rowSizeSample + 1
+	var sampled, n int64
+	for i := 0; i < len(rows); i += step {
+		sampled += estimatedRowSize(rows[i])
+		n++
+	}
+	return sampled / n * int64(len(rows))
+}
+
+// estimatedRowSize counts a map header, one bucket entry per column and
+// the payload of each value.
+func estimatedRowSize(r Row) int64 {
+	size := int64(48)
+	for k, v := range r {
+		size += 32 + int64(len(k)) + estimatedValueSize(v)
+	}
+	return size
+}
+
+func estimatedValueSize(v any) int64 {
+	switch x := v.(type) {
+	case nil:
+		return 0
+	case string:
+		return int64(len(x))
+	case []byte:
+		return 24 + int64(len(x))
+	case time.Time:
+		return 24
+	case []any:
+		size := int64(24)
+		for _, e := range x {
+			size += 16 + estimatedValueSize(e)
+		}
+		return size
+	case map[string]any:
+		size := int64(48)
+		for k, e := range x {
+			size += 32 + int64(len(k)) + estimatedValueSize(e)
+		}
+		return size
+	}
+	return 8
+}
@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+// queryLimitsTestDB holds a 1000-row table; its self cross join has 1M rows.
+func queryLimitsTestDB(t *testing.T) *storage.DB {
+	t.Helper()
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE nums (n INT)`)
+	execSQL(t, db, `CREATE TABLE sink (a INT, b INT)`)
+	nums, _ := db.Get("default", "nums")
+	for i := 0; i < 1000; i++ {
+		nums.Rows = append(nums.Rows, []any{i})
+	}
+	nums.Version++
+	return db
+}
+
+func execWithOptions(db *storage.DB, sql string, opts QueryOptions) (*ResultSet, error) {
+	return ExecuteWithOptions(context.Background(), db, "default", mustParse(sql), opts)
+}
+
+func TestQueryRowLimit(t *testing.T) {
+	db := queryLimitsTestDB(t)
+	rs, err := execWithOptions(db, `SELECT a.n, b.n FROM nums a CROSS JOIN nums b`, QueryOptions{MaxRows: 100_000})
+	if !errors.Is(err, ErrQueryRowLimitExceeded) || rs != nil {
+		t.Fatalf("1M-row query under a 100K row limit: rs=%v err=%v", rs != nil, err)
+	}
+
+	rs, err = execWithOptions(db, `SELECT a.n, b.n FROM nums a CROSS JOIN nums b WHERE a.n < 10`, QueryOptions{MaxRows: 100_000})
+	if err != nil || len(rs.Rows) != 10_000 {
+		t.Fatalf("query under the limit: err=%v", err)
+	}
+}
+
+func TestQueryMemoryLimit(t *testing.T) {
+	db := queryLimitsTestDB(t)
+	opts := QueryOptions{MaxMemoryBytes: 10 << 20}
+	_, err := execWithOptions(db, `SELECT COUNT(*) FROM nums a CROSS JOIN nums b`, opts)
+	if !errors.Is(err, ErrQueryMemoryExceeded) {
+		t.Fatalf("cross join under a 10 MiB limit: err=%v", err)
+	}
+
+	rs, err := execWithOptions(db, `SELECT COUNT(*) AS c FROM nums a JOIN nums b ON a.n = b.n`, opts)
+	if err != nil || rs.Rows[0]["c"] != 1000 {
+		t.Fatalf("query under the limit: rows=%v err=%v", rs, err)
+	}
+}
+
+func TestQueryLimitsLeaveNoPartialChanges(t *testing.T) {
+	db := queryLimitsTestDB(t)
+	_, err := execWithOptions(db, `CREATE TABLE pairs AS SELECT a.n AS a, b.n AS b FROM nums a CROSS JOIN nums b`, QueryOptions{MaxMemoryBytes: 10 << 20})
+	if !errors.Is(err, ErrQueryMemoryExceeded) {
+		t.Fatalf("CREATE TABLE AS: err=%v", err)
+	}
+	if _, err := db.Get("default", "pairs"); err == nil {
+		t.Fatal("failed CREATE TABLE AS left its table behind")
+	}
+
+	execSQL(t, db, `INSERT INTO sink VALUES (1, 1), (2, 2), (3, 3)`)
+	_, err = execWithOptions(db, `DELETE FROM sink RETURNING a`, QueryOptions{MaxRows: 2})
+	if !errors.Is(err, ErrQueryRowLimitExceeded) {
+		t.Fatalf("DELETE ... RETURNING: err=%v", err)
+	}
+	if rs := execSQL(t, db, `SELECT COUNT(*) AS c FROM sink`); rs.Rows[0]["c"] != 3 {
+		t.Fatalf("DELETE over the row limit was not rolled back: %v rows left", rs.Rows[0]["c"])
+	}
+}
+
+func TestEstimateRowSetBytes(t *testing.T) {
+	rows := []Row{{"name": "abcd", "n": 1}, {"name": "efgh", "n": nil}}
+	// Map header 48, "name" 32+4+4, "n" 32+1 plus 8 for the int (0 for NULL).
+	if got := estimateRowSetBytes(rows); got != (48+40+41)+(48+40+33) {
+		t.Fatalf("estimateRowSetBytes = %d", got)
+	}
+}
@@ -61,6 +61,12 @@ func checkPermission(ctx context.Context, db *storage.DB, stmt Statement) error
 	if !db.Catalog().HasPermission(user, perm, schema, table) {
 		return fmt.Errorf("access denied: user %q lacks %s permission on %s.%s", user, perm, schema, table)
 	}
+	// INSERT ... ON CONFLICT DO UPDATE may rewrite existing rows.
+	if ins, ok := stmt.(*Insert); ok && ins.OnConflict != nil && !ins.OnConflict.DoNothing {
+		if !db.Catalog().HasPermission(user, storage.PermUpdate, schema, table) {
+			return fmt.Errorf("access denied: user %q lacks %s permission on %s.%s", user, storage.PermUpdate, schema, table)
+		}
+	}
 	return nil
 }
 
@@ -73,8 +79,11 @@ func checkPermission(ctx context.Context, db *storage.DB, stmt Statement) error
 // joins/subqueries/CTEs.
 func requiredPermission(stmt Statement) (perm storage.Permission, schema, table string, needsCheck bool) {
 	switch s := stmt.(type) {
-	case *Explain, *Pragma:
+	case *Explain, *Pragma, *ShowTables:
 		return "", "", "", false
+	case *ShowColumns:
+		schema, table = splitObjectName(s.Table)
+		return storage.PermSelect, schema, table, true
 	case *Select:
 		if s.From.Table == "" {
 			return "", "", "", false
@@ -90,12 +99,18 @@ func requiredPermission(stmt Statement) (perm storage.Permission, schema, table
 	case *Insert:
 		schema, table = splitObjectName(s.Table)
 		return storage.PermInsert, schema, table, true
+	case *CopyFrom:
+		schema, table = splitObjectName(s.Table)
+		return storage.PermInsert, schema, table, true
 	case *Update:
 		schema, table = splitObjectName(s.Table)
 		return storage.PermUpdate, schema, table, true
 	case *Delete:
 		schema, table = splitObjectName(s.Table)
 		return storage.PermDelete, schema, table, true
+	case *TruncateTable:
+		schema, table = splitObjectName(s.Name)
+		return storage.PermDelete, schema, table, true
 	case *CallProcedure:
 		return "", "", "", false
 	case *CreateTable:
@@ -141,6 +156,12 @@ func requiredPermission(stmt Statement) (perm storage.Permission, schema, table
 		// DropTrigger only names the trigger, not its table — a schema-wide
 		// DDL check is the best available granularity here.
 		return storage.PermDDL, "*", "*", true
+	case *CreateSequence:
+		schema, table = splitObjectName(s.Name)
+		return storage.PermDDL, schema, table, true
+	case *DropSequence:
+		schema, table = splitObjectName(s.Name)
+		return storage.PermDDL, schema, table, true
 	case *CreateJob, *AlterJob, *DropJob:
 		return storage.PermDDL, "*", "*", true
 	case *CreateUser, *DropUser, *AlterUser, *CreateRole, *DropRole,
@@ -0,0 +1,163 @@
+// Row-level security enforcement (see storage.RowPolicy and DB.SetPolicy).
+//
+// SELECT applies the policy filters of each physical table to its rows as
+// they are materialized for FROM and JOIN, before any user predicate runs;
+// UPDATE and DELETE AND them into their WHERE clause so rows a user cannot
+// see cannot be changed either. The DML flags are checked before a write
+// touches the table. Everything is skipped while the tenant has no
+// policies, so databases that never call SetPolicy see no change.
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+// ErrPolicyViolation is returned (wrapped) when a row-level security policy
+// forbids an INSERT, UPDATE or DELETE.
+var ErrPolicyViolation = errors.New("row-level security policy violation")
+
+// currentUserToken is replaced by the acting user in policy filters.
+const currentUserToken = "$current_user"
+
+// applicablePolicies returns the policies on table that bind env's user.
+func applicablePolicies(env ExecEnv, table string) []storage.RowPolicy {
+	if !env.db.HasPolicies(env.tenant) {
+		return nil
+	}
+	var out []storage.RowPolicy
+	for _, p := range env.db.Policies(env.tenant, table) {
+		if !p.Exempts(env.userID) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// rowPolicyFilter returns the AND of the policy filters on table for env's
+// user, or nil when no filter applies.
+func rowPolicyFilter(env ExecEnv, table string) (Expr, error) {
+	var out Expr
+	for _, p := range applicablePolicies(env, table) {
+		if strings.TrimSpace(p.Filter) == "" {
+			continue
+		}
+		expr, err := schemaExpr(policyFilterText(p.Filter, env.userID))
+		if err != nil {
+			return nil, fmt.Errorf("row policy on %s: %w", table, err)
+		}
+		if out == nil {
+			out = expr
+		} else {
+			out = &Binary{Op: "AND", Left: out, Right: expr}
+		}
+	}
+	return out, nil
+}
+
+// policyFilterText substitutes the acting user for $current_user. Without
+// a user the token becomes NULL, so the filter hides every row.
+func policyFilterText(filter, user string) string {
+	if !strings.Contains(filter, currentUserToken) {
+		return filter
+	}
+	lit := "NULL"
+	if user != "" {
+		lit = "'" + strings.ReplaceAll(user, "'", "''") + "'"
+	}
+	return strings.ReplaceAll(filter, currentUserToken, lit)
+}
+
+// applyRowPolicies drops the rows of table that env's user may not see.
+func applyRowPolicies(env ExecEnv, table string, rows []Row) ([]Row, error) {
+	filter, err := rowPolicyFilter(env, table)
+	if err != nil || filter == nil {
+		return rows, err
+	}
+	return applyWhereClause(env, filter, rows)
+}
+
+// checkRowPolicyDML fails with ErrPolicyViolation unless every policy on
+// table that binds env's user allows perm.
+func checkRowPolicyDML(env ExecEnv, table string, perm storage.Permission) error {
+	for _, p := range applicablePolicies(env, table) {
+		allowed := false
+		switch perm {
+		case storage.PermInsert:
+			allowed = p.CanInsert
+		case storage.PermUpdate:
+			allowed = p.CanUpdate
+		case storage.PermDelete:
+			allowed = p.CanDelete
+		}
+		if !allowed {
+			return fmt.Errorf("%w: %s on %s is not allowed", ErrPolicyViolation, perm, table)
+		}
+	}
+	return nil
+}
+
+// restrictWhereByPolicy returns where AND-ed with table's policy filter.
+func restrictWhereByPolicy(env ExecEnv, table string, where Expr) (Expr, error) {
+	filter, err := rowPolicyFilter(env, table)
+	if err != nil || filter == nil {
+		return where, err
+	}
+	if where == nil {
+		return filter, nil
+	}
+	return &Binary{Op: "AND", Left: where, Right: filter}, nil
+}
+
+// policyUpdate checks the UPDATE mask and returns s limited to the rows
+// the user may see. s itself is not modified.
+func policyUpdate(env ExecEnv, s *Update) (*Update, error) {
+	if !env.db.HasPolicies(env.tenant) {
+		return s, nil
+	}
+	if err := checkRowPolicyDML(env, s.Table, storage.PermUpdate); err != nil {
+		return nil, err
+	}
+	where, err := restrictWhereByPolicy(env, s.Table, s.Where)
+	if err != nil {
+		return nil, err
+	}
+	out := *s
+	out.Where = where
+	return &out, nil
+}
+
+// policyDelete is policyUpdate for DELETE.
+func policyDelete(env ExecEnv, s *Delete) (*Delete, error) {
+	if !env.db.HasPolicies(env.tenant) {
+		return s, nil
+	}
+	if err := checkRowPolicyDML(env, s.Table, storage.PermDelete); err != nil {
+		return nil, err
+	}
+	where, err := restrictWhereByPolicy(env, s.Table, s.Where)
+	if err != nil {
+		return nil, err
+	}
+	out := *s
+	out.Where = where
+	return &out, nil
+}
+
+// checkPolicyInsert checks the INSERT mask; an upsert that can rewrite
+// existing rows needs the UPDATE flag as well.
+func checkPolicyInsert(env ExecEnv, s *Insert) error {
+	if !env.db.HasPolicies(env.tenant) {
+		return nil
+	}
+	if err := checkRowPolicyDML(env, s.Table, storage.PermInsert); err != nil {
+		return err
+	}
+	if s.OnConflict != nil && !s.OnConflict.DoNothing {
+		return checkRowPolicyDML(env, s.Table, storage.PermUpdate)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+func newPolicyDB(t *testing.T) *storage.DB {
+	t.Helper()
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE docs (id INT, user_id TEXT, team TEXT)`)
+	execSQL(t, db, `INSERT INTO docs VALUES (1, 'alice', 'red'), (2, 'bob', 'red'), (3, 'alice', 'blue'), (4, 'carol', 'blue')`)
+	execSQL(t, db, `CREATE TABLE teams (name TEXT, lead TEXT)`)
+	execSQL(t, db, `INSERT INTO teams VALUES ('red', 'rita'), ('blue', 'bert')`)
+	return db
+}
+
+func policyQuery(t *testing.T, db *storage.DB, user, sql string) *ResultSet {
+	t.Helper()
+	rs, err := Execute(WithUser(context.Background(), user), db, "default", mustParse(sql))
+	if err != nil {
+		t.Fatalf("%s as %s: %v", sql, user, err)
+	}
+	return rs
+}
+
+func policyIDs(rs *ResultSet) []int {
+	var ids []int
+	for _, r := range rs.Rows {
+		v, _ := getVal(r, "id")
+		ids = append(ids, v.(int))
+	}
+	return ids
+}
+
+func TestRowPolicyFiltersByCurrentUser(t *testing.T) {
+	db := newPolicyDB(t)
+	db.SetPolicy("default", "docs", storage.RowPolicy{Filter: "user_id = $current_user"})
+
+	if got := policyIDs(policyQuery(t, db, "alice", `SELECT id FROM docs ORDER BY id`)); len(got) != 2 || got[0] != 1 || got[1] != 3 {
+		t.Fatalf("alice sees %v, want [1 3]", got)
+	}
+	if got := policyIDs(policyQuery(t, db, "bob", `SELECT id FROM docs WHERE team = 'red'`)); len(got) != 1 || got[0] != 2 {
+		t.Fatalf("bob sees %v, want [2]", got)
+	}
+	rs := policyQuery(t, db, "alice", `SELECT COUNT(*) AS n FROM docs`)
+	if n, _ := getVal(rs.Rows[0], "n"); n != 2 {
+		t.Fatalf("alice counts %v rows, want 2", n)
+	}
+	rs = policyQuery(t, db, "alice", `SELECT d.id FROM teams tm JOIN docs d ON d.team = tm.name`)
+	if len(rs.Rows) != 2 {
+		t.Fatalf("join as alice returned %d rows, want 2", len(rs.Rows))
+	}
+	// Without a user $current_user is NULL and nothing is visible.
+	if rs := execSQL(t, db, `SELECT id FROM docs`); len(rs.Rows) != 0 {
+		t.Fatalf("anonymous query returned %d rows, want 0", len(rs.Rows))
+	}
+}
+
+func TestRowPolicyExemptUserSeesAllRows(t *testing.T) {
+	db := newPolicyDB(t)
+	db.SetPolicy("default", "docs", storage.RowPolicy{Filter: "user_id = $current_user", CanDelete: true, ExemptUsers: []string{"admin"}})
+
+	if rs := policyQuery(t, db, "admin", `SELECT id FROM docs`); len(rs.Rows) != 4 {
+		t.Fatalf("admin sees %d rows, want 4", len(rs.Rows))
+	}
+	policyQuery(t, db, "admin", `INSERT INTO docs VALUES (5, 'dave', 'red')`)
+	if rs := policyQuery(t, db, "admin", `SELECT id FROM docs`); len(rs.Rows) != 5 {
+		t.Fatalf("admin sees %d rows after insert, want 5", len(rs.Rows))
+	}
+}
+
+func TestRowPolicyRestrictsDML(t *testing.T) {
+	db := newPolicyDB(t)
+	db.SetPolicy("default", "docs", storage.RowPolicy{Filter: "user_id = $current_user", CanUpdate: true})
+	ctx := WithUser(context.Background(), "alice")
+
+	for _, sql := range []string{
+		`INSERT INTO docs VALUES (9, 'alice', 'red')`,
+		`DELETE FROM docs WHERE id = 1`,
+	} {
+		_, err := Execute(ctx, db, "default", mustParse(sql))
+		if !errors.Is(err, ErrPolicyViolation) {
+			t.Fatalf("%s: err = %v, want ErrPolicyViolation", sql, err)
+		}
+	}
+
+	// UPDATE is allowed but only reaches alice's rows.
+	policyQuery(t, db, "alice", `UPDATE docs SET team = 'green'`)
+	db.ClearPolicies("default", "docs")
+	rs := execSQL(t, db, `SELECT id FROM docs WHERE team = 'green' ORDER BY id`)
+	if got := policyIDs(rs); len(got) != 2 || got[0] != 1 || got[1] != 3 {
+		t.Fatalf("updated rows %v, want [1 3]", got)
+	}
+	if rs := execSQL(t, db, `SELECT id FROM docs`); len(rs.Rows) != 4 {
+		t.Fatalf("table has %d rows, want 4", len(rs.Rows))
+	}
+}
+
+func TestRowPoliciesAreANDed(t *testing.T) {
+	db := newPolicyDB(t)
+	db.SetPolicy("default", "docs", storage.RowPolicy{Filter: "user_id = $current_user", CanDelete: true})
+	db.SetPolicy("default", "docs", storage.RowPolicy{Filter: "team = 'blue'"})
+
+	if got := policyIDs(policyQuery(t, db, "alice", `SELECT id FROM docs`)); len(got) != 1 || got[0] != 3 {
+		t.Fatalf("alice sees %v, want [3]", got)
+	}
+	// The second policy does not allow DELETE, so neither does the pair.
+	_, err := Execute(WithUser(context.Background(), "alice"), db, "default", mustParse(`DELETE FROM docs WHERE id = 3`))
+	if !errors.Is(err, ErrPolicyViolation) {
+		t.Fatalf("DELETE err = %v, want ErrPolicyViolation", err)
+	}
+}
@@ -0,0 +1,76 @@
+// Parser and AST for savepoints inside a transaction. BEGIN, COMMIT and a
+// plain ROLLBACK are handled as text by the database/sql driver; these three
+// carry a savepoint name and therefore go through the parser. The driver
+// keeps the savepoint stack, since only it owns transaction snapshots.
+//
+// Grammar:
+//
+//	SAVEPOINT name
+//	ROLLBACK [TRANSACTION] TO [SAVEPOINT] name
+//	RELEASE [SAVEPOINT] name
+package engine
+
+// SavepointStmt represents SAVEPOINT name.
+type SavepointStmt struct {
+	Name string
+}
+
+// RollbackToStmt represents ROLLBACK TO [SAVEPOINT] name.
+type RollbackToStmt struct {
+	Name string
+}
+
+// ReleaseSavepointStmt represents RELEASE [SAVEPOINT] name.
+type ReleaseSavepointStmt struct {
+	Name string
+}
+
+// isSavepointStart reports whether the current identifier begins one of the
+// savepoint statements. SAVEPOINT, ROLLBACK and RELEASE are not reserved, so
+// they arrive as identifiers.
+func (p *Parser) isSavepointStart() bool {
+	return p.isIdentWord("SAVEPOINT") || p.isIdentWord("ROLLBACK") || p.isIdentWord("RELEASE")
+}
+
+func (p *Parser) parseSavepointStmt() (Statement, error) {
+	word := upper(p.cur.Val)
+	p.next()
+	switch word {
+	case "SAVEPOINT":
+		name := p.parseIdentLike()
+		if name == "" {
+			return nil, p.errf("expected savepoint name")
+		}
+		return &SavepointStmt{Name: name}, nil
+	case "ROLLBACK":
+		if (p.cur.Typ == tKeyword || p.cur.Typ == tIdent) && upper(p.cur.Val) == "TRANSACTION" {
+			p.next()
+		}
+		if err := p.expectKeyword("TO"); err != nil {
+			return nil, err
+		}
+		name, err := p.parseSavepointName()
+		if err != nil {
+			return nil, err
+		}
+		return &RollbackToStmt{Name: name}, nil
+	default: // RELEASE
+		name, err := p.parseSavepointName()
+		if err != nil {
+			return nil, err
+		}
+		return &ReleaseSavepointStmt{Name: name}, nil
+	}
+}
+
+// parseSavepointName reads "[SAVEPOINT] name".
+func (p *Parser) parseSavepointName() (string, error) {
+	if p.isIdentWord("SAVEPOINT") {
+		p.next()
+	}
+	name := p.parseIdentLike()
+	if name == "" {
+		return "", p.errf("expected savepoint name")
+	}
+	return name, nil
+}
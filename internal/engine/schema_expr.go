@@ -0,0 +1,36 @@
+package engine
+
+import "sync"
+
+// schemaExprCacheMaxEntries bounds schemaExprCache, mirroring the trigger
+// caches. The texts live in table schemas, so a DROP TABLE does not purge
+// them; the cap keeps schema churn from growing the cache without limit.
+const schemaExprCacheMaxEntries = 256
+
+var (
+	schemaExprMu    sync.RWMutex
+	schemaExprCache = make(map[string]Expr)
+)
+
+// schemaExpr returns the parsed form of an expression stored in a table
+// schema as source text (CHECK conditions and DEFAULT expressions). Each
+// distinct text is parsed once.
+func schemaExpr(text string) (Expr, error) {
+	schemaExprMu.RLock()
+	expr, ok := schemaExprCache[text]
+	schemaExprMu.RUnlock()
+	if ok {
+		return expr, nil
+	}
+	expr, err := NewParser(text).parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	schemaExprMu.Lock()
+	if len(schemaExprCache) >= schemaExprCacheMaxEntries {
+		schemaExprCache = make(map[string]Expr)
+	}
+	schemaExprCache[text] = expr
+	schemaExprMu.Unlock()
+	return expr, nil
+}
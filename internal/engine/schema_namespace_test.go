@@ -70,3 +70,59 @@ func querySchemaSQL(t *testing.T, ctx context.Context, db *storage.DB, sql strin
 	}
 	return rs
 }
+
+func TestDefaultSchemaQualifiersAndCrossSchemaJoin(t *testing.T) {
+	db := storage.NewDB()
+	ctx := context.Background()
+
+	execSchemaSQL(t, ctx, db, "CREATE TABLE public.users (id INT PRIMARY KEY, name TEXT)")
+	execSchemaSQL(t, ctx, db, "INSERT INTO users VALUES (1, 'ann'), (2, 'bob')")
+	execSchemaSQL(t, ctx, db, "CREATE TABLE analytics.events (user_id INT, kind TEXT)")
+	execSchemaSQL(t, ctx, db, "CREATE TABLE events (user_id INT, kind TEXT)")
+	execSchemaSQL(t, ctx, db, "INSERT INTO analytics.events VALUES (1, 'login'), (1, 'click'), (2, 'login')")
+
+	for _, from := range []string{"users", "public.users", "main.users", "PUBLIC.users"} {
+		rs := querySchemaSQL(t, ctx, db, "SELECT COUNT(*) AS n FROM "+from)
+		if rs.Rows[0]["n"] != 2 {
+			t.Fatalf("FROM %s count = %v", from, rs.Rows[0]["n"])
+		}
+	}
+	if _, err := db.Get("default", "public.users"); err != nil {
+		t.Fatalf("storage lookup of public.users: %v", err)
+	}
+	if tbl, _ := db.Get("default", "users"); tbl.Name != "users" {
+		t.Fatalf("CREATE TABLE public.users stored as %q, want users", tbl.Name)
+	}
+
+	// analytics.events is its own table, separate from unqualified events.
+	if rs := querySchemaSQL(t, ctx, db, "SELECT COUNT(*) AS n FROM analytics.events"); rs.Rows[0]["n"] != 3 {
+		t.Fatalf("analytics.events count = %v", rs.Rows[0]["n"])
+	}
+	if rs := querySchemaSQL(t, ctx, db, "SELECT COUNT(*) AS n FROM events"); rs.Rows[0]["n"] != 0 {
+		t.Fatalf("events count = %v", rs.Rows[0]["n"])
+	}
+
+	rs := querySchemaSQL(t, ctx, db, `
+		SELECT u.name AS name, COUNT(*) AS n
+		FROM public.users u
+		JOIN analytics.events e ON e.user_id = u.id
+		GROUP BY u.name ORDER BY u.name`)
+	if len(rs.Rows) != 2 || rs.Rows[0]["name"] != "ann" || rs.Rows[0]["n"] != 2 || rs.Rows[1]["n"] != 1 {
+		t.Fatalf("cross-schema join rows = %#v", rs.Rows)
+	}
+
+	execSchemaSQL(t, ctx, db, "UPDATE public.users SET name = 'anna' WHERE id = 1")
+	execSchemaSQL(t, ctx, db, "DELETE FROM analytics.events WHERE kind = 'click'")
+	rs = querySchemaSQL(t, ctx, db, "SELECT name FROM users WHERE id = 1")
+	if rs.Rows[0]["name"] != "anna" {
+		t.Fatalf("UPDATE public.users did not reach users: %#v", rs.Rows)
+	}
+	if rs := querySchemaSQL(t, ctx, db, "SELECT COUNT(*) AS n FROM analytics.events"); rs.Rows[0]["n"] != 2 {
+		t.Fatalf("analytics.events after DELETE = %v", rs.Rows[0]["n"])
+	}
+
+	execSchemaSQL(t, ctx, db, "DROP TABLE public.users")
+	if _, err := Execute(ctx, db, "default", mustParse("SELECT * FROM users")); err == nil {
+		t.Fatal("DROP TABLE public.users should drop users")
+	}
+}
@@ -2,6 +2,7 @@ package engine
 
 import (
 	"context"
+	"fmt"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -182,3 +183,66 @@ func TestPrimaryKeyConstraintIndexPointSeek(t *testing.T) {
 		t.Fatalf("negative primary-key lookup = %#v", missing.Rows)
 	}
 }
+
+func TestSecondaryIndexFollowsUpdateAndDropIndex(t *testing.T) {
+	db := storage.NewDB()
+	executeIndexSQL(t, db, `CREATE TABLE events (id INT, kind TEXT)`)
+	var sb strings.Builder
+	sb.WriteString(`INSERT INTO events VALUES `)
+	for i := 0; i < 10000; i++ {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "(%d, 'k%d')", i, i%100)
+	}
+	executeIndexSQL(t, db, sb.String())
+	executeIndexSQL(t, db, `CREATE INDEX idx_events_kind ON events(kind)`)
+
+	accessPath := func(sql string) string {
+		stmt := mustParse(sql).(*Select)
+		plan, ok, err := buildSimpleSelectPlan(ExecEnv{ctx: context.Background(), tenant: "default", db: db}, stmt)
+		if err != nil || !ok {
+			t.Fatalf("plan %s: ok=%v, err=%v", sql, ok, err)
+		}
+		return plan.scanType
+	}
+	const lookup = `SELECT id FROM events WHERE kind = 'k7'`
+	if got := accessPath(lookup); got != "INDEX POINT SEEK" {
+		t.Fatalf("access path with index = %q", got)
+	}
+	if rs := executeIndexSQL(t, db, lookup); len(rs.Rows) != 100 {
+		t.Fatalf("indexed lookup returned %d rows", len(rs.Rows))
+	}
+
+	executeIndexSQL(t, db, `UPDATE events SET kind = 'moved' WHERE id = 7`)
+	if rs := executeIndexSQL(t, db, lookup); len(rs.Rows) != 99 {
+		t.Fatalf("lookup of old key after UPDATE returned %d rows", len(rs.Rows))
+	}
+	rs := executeIndexSQL(t, db, `SELECT id FROM events WHERE kind = 'moved'`)
+	if len(rs.Rows) != 1 {
+		t.Fatalf("lookup of new key after UPDATE = %#v", rs.Rows)
+	}
+	expectInt(t, rs.Rows[0]["id"], 7, "updated row")
+
+	executeIndexSQL(t, db, `DROP INDEX idx_events_kind`)
+	if got := accessPath(lookup); got != "TABLE SCAN" {
+		t.Fatalf("access path after DROP INDEX = %q", got)
+	}
+	if rs := executeIndexSQL(t, db, lookup); len(rs.Rows) != 99 {
+		t.Fatalf("scan after DROP INDEX returned %d rows", len(rs.Rows))
+	}
+}
+
+func TestUniqueSecondaryIndexAllowsNullKeys(t *testing.T) {
+	db := storage.NewDB()
+	executeIndexSQL(t, db, `CREATE TABLE accounts (id INT, email TEXT)`)
+	executeIndexSQL(t, db, `INSERT INTO accounts VALUES (1, NULL), (2, NULL), (3, 'a@example.com')`)
+	executeIndexSQL(t, db, `CREATE UNIQUE INDEX idx_accounts_email ON accounts(email)`)
+	executeIndexSQL(t, db, `INSERT INTO accounts VALUES (4, NULL)`)
+	if _, err := Execute(context.Background(), db, "default", mustParse(`INSERT INTO accounts VALUES (5, 'a@example.com')`)); err == nil {
+		t.Fatal("duplicate key unexpectedly inserted")
+	}
+	if _, err := Execute(context.Background(), db, "default", mustParse(`UPDATE accounts SET email = 'a@example.com' WHERE id = 1`)); err == nil {
+		t.Fatal("duplicate key unexpectedly accepted by UPDATE")
+	}
+}
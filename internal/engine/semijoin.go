@@ -0,0 +1,294 @@
+package engine
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+// semiJoinExpr replaces a correlated predicate of the form
+//
+//	EXISTS (SELECT ... FROM inner WHERE inner.k = outer.k [AND <inner-only>])
+//
+// The inner side is scanned once into a hash set of join keys; each outer
+// row then probes the set instead of re-running the subquery. A probe stops
+// at the first matching key, which is all EXISTS needs.
+type semiJoinExpr struct {
+	outerKeys []Expr
+	keys      map[string]struct{}
+}
+
+func (sj *semiJoinExpr) eval(env ExecEnv, row Row) (any, error) {
+	buf := make([]byte, 0, 32)
+	for _, e := range sj.outerKeys {
+		v, err := evalExpr(env, e, row)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			// NULL = x is never true, so no inner row can match.
+			return false, nil
+		}
+		buf = writeSemiJoinKeyPart(buf, v)
+	}
+	_, ok := sj.keys[string(buf)]
+	return ok, nil
+}
+
+// rewriteExistsSemiJoins returns where with every eligible EXISTS subquery
+// replaced by a semiJoinExpr. Only EXISTS nodes reachable through AND, OR
+// and NOT are considered; the original AST is never modified because parsed
+// statements may be cached and executed again.
+func rewriteExistsSemiJoins(env ExecEnv, where Expr) (Expr, error) {
+	switch ex := where.(type) {
+	case *ExistsExpr:
+		sj, ok, err := buildSemiJoin(env, ex.Select)
+		if err != nil || !ok {
+			return where, err
+		}
+		return sj, nil
+	case *Unary:
+		if ex.Op != "NOT" {
+			return where, nil
+		}
+		inner, err := rewriteExistsSemiJoins(env, ex.Expr)
+		if err != nil || inner == ex.Expr {
+			return where, err
+		}
+		return &Unary{Op: ex.Op, Expr: inner}, nil
+	case *Binary:
+		if ex.Op != "AND" && ex.Op != "OR" {
+			return where, nil
+		}
+		left, err := rewriteExistsSemiJoins(env, ex.Left)
+		if err != nil {
+			return where, err
+		}
+		right, err := rewriteExistsSemiJoins(env, ex.Right)
+		if err != nil {
+			return where, err
+		}
+		if left == ex.Left && right == ex.Right {
+			return where, nil
+		}
+		return &Binary{Op: ex.Op, Left: left, Right: right}, nil
+	}
+	return where, nil
+}
+
+// semiJoinEligible reports whether sub has the shape the rewrite supports:
+// a single FROM source without joins, grouping, aggregates, windows, set
+// operations or row limits.
+func semiJoinEligible(sub *Select) bool {
+	if sub == nil || sub.Where == nil {
+		return false
+	}
+	if sub.From.Table == "" && sub.From.Subquery == nil && sub.From.TableFunc == nil {
+		return false
+	}
+	if len(sub.Joins) > 0 || len(sub.GroupBy) > 0 || sub.Having != nil || sub.Pivot != nil ||
+		sub.Union != nil || len(sub.CTEs) > 0 || sub.Limit != nil || sub.Offset != nil {
+		return false
+	}
+	return !anyAggInSelect(sub.Projs) && !anyWindowInSelect(sub.Projs)
+}
+
+// buildSemiJoin evaluates the inner side of an EXISTS subquery once. It
+// returns ok=false whenever the subquery does not fit the supported pattern
+// or the inner scan fails, leaving the caller on the correlated path so
+// behaviour (including error reporting) is unchanged.
+func buildSemiJoin(env ExecEnv, sub *Select) (*semiJoinExpr, bool, error) {
+	if !semiJoinEligible(sub) {
+		return nil, false, nil
+	}
+	innerRows, err := resolveFromClause(env, env, sub)
+	if err != nil {
+		return nil, false, nil
+	}
+
+	qualifier := strings.ToLower(sub.From.Alias)
+	if qualifier == "" {
+		qualifier = strings.ToLower(sub.From.Table)
+	}
+	innerCols := map[string]bool{}
+	if len(innerRows) > 0 {
+		for k := range innerRows[0] {
+			innerCols[strings.ToLower(k)] = true
+		}
+	}
+	isInner := func(name string) bool {
+		if dot := strings.LastIndex(name, "."); dot >= 0 {
+			return name[:dot] == qualifier
+		}
+		return innerCols[name]
+	}
+	// side reports whether every reference in e is inner (or outer) and
+	// whether e references any column at all.
+	side := func(e Expr) (inner, outer, ok bool) {
+		refs, ok := semiJoinColumnRefs(e, nil)
+		if !ok {
+			return false, false, false
+		}
+		for _, r := range refs {
+			if isInner(r) {
+				inner = true
+			} else {
+				outer = true
+			}
+		}
+		return inner, outer, true
+	}
+
+	var innerPred Expr
+	var innerKeys, outerKeys []Expr
+	for _, c := range splitAndConjuncts(sub.Where, nil) {
+		inner, outer, ok := side(c)
+		if !ok {
+			return nil, false, nil
+		}
+		if !outer {
+			if innerPred == nil {
+				innerPred = c
+			} else {
+				innerPred = &Binary{Op: "AND", Left: innerPred, Right: c}
+			}
+			continue
+		}
+		eq, isEq := c.(*Binary)
+		if !inner || !isEq || eq.Op != "=" {
+			return nil, false, nil
+		}
+		li, lo, _ := side(eq.Left)
+		ri, ro, _ := side(eq.Right)
+		switch {
+		case li && !lo && ro && !ri:
+			innerKeys = append(innerKeys, eq.Left)
+			outerKeys = append(outerKeys, eq.Right)
+		case ri && !ro && lo && !li:
+			innerKeys = append(innerKeys, eq.Right)
+			outerKeys = append(outerKeys, eq.Left)
+		default:
+			return nil, false, nil
+		}
+	}
+	if len(outerKeys) == 0 {
+		return nil, false, nil
+	}
+
+	filtered, err := applyWhereClause(env, innerPred, innerRows)
+	if err != nil {
+		return nil, false, nil
+	}
+	sj := &semiJoinExpr{outerKeys: outerKeys, keys: make(map[string]struct{}, len(filtered))}
+	buf := make([]byte, 0, 32)
+rows:
+	for _, r := range filtered {
+		buf = buf[:0]
+		for _, k := range innerKeys {
+			v, err := evalExpr(env, k, r)
+			if err != nil {
+				return nil, false, nil
+			}
+			if v == nil {
+				continue rows
+			}
+			buf = writeSemiJoinKeyPart(buf, v)
+		}
+		if _, seen := sj.keys[string(buf)]; !seen {
+			sj.keys[string(buf)] = struct{}{}
+		}
+	}
+	return sj, true, nil
+}
+
+// writeSemiJoinKeyPart appends a hash key part for v. Numbers are keyed by
+// their float64 value so 2, int64(2) and 2.0 match each other exactly as
+// compare treats them as equal.
+func writeSemiJoinKeyPart(buf []byte, v any) []byte {
+	if f, ok := numeric(v); ok {
+		buf = append(buf, 'F')
+		buf = strconv.AppendFloat(buf, f, 'g', -1, 64)
+		return append(buf, ';')
+	}
+	if r, ok := storage.AsBigRat(v); ok {
+		f, _ := r.Float64()
+		buf = append(buf, 'F')
+		buf = strconv.AppendFloat(buf, f, 'g', -1, 64)
+		return append(buf, ';')
+	}
+	return writeFmtKeyPart(buf, v)
+}
+
+func splitAndConjuncts(e Expr, out []Expr) []Expr {
+	if b, ok := e.(*Binary); ok && b.Op == "AND" {
+		out = splitAndConjuncts(b.Left, out)
+		return splitAndConjuncts(b.Right, out)
+	}
+	return append(out, e)
+}
+
+// semiJoinColumnRefs appends the lowercased column names referenced by e.
+// It returns ok=false for subqueries, aggregates, window functions and any
+// node it does not know, so the rewrite is only applied to expressions whose
+// column usage is fully understood.
+func semiJoinColumnRefs(e Expr, refs []string) ([]string, bool) {
+	ok := true
+	var walk func(Expr)
+	walk = func(e Expr) {
+		if !ok {
+			return
+		}
+		switch ex := e.(type) {
+		case nil, *Literal:
+		case *VarRef:
+			name := ex.Lower
+			if name == "" {
+				name = strings.ToLower(ex.Name)
+			}
+			refs = append(refs, name)
+		case *Unary:
+			walk(ex.Expr)
+		case *Binary:
+			walk(ex.Left)
+			walk(ex.Right)
+		case *IsNull:
+			walk(ex.Expr)
+		case *FuncCall:
+			if ex.Over != nil || isAggregate(ex) {
+				ok = false
+				return
+			}
+			for _, a := range ex.Args {
+				walk(a)
+			}
+		case *InExpr:
+			walk(ex.Expr)
+			for _, v := range ex.Values {
+				walk(v)
+			}
+		case *LikeExpr:
+			walk(ex.Expr)
+			walk(ex.Pattern)
+			walk(ex.Escape)
+		case *RegexpExpr:
+			walk(ex.Expr)
+			walk(ex.Pattern)
+		case *BetweenExpr:
+			walk(ex.Expr)
+			walk(ex.Lo)
+			walk(ex.Hi)
+		case *CaseExpr:
+			walk(ex.Operand)
+			for _, w := range ex.Whens {
+				walk(w.When)
+				walk(w.Then)
+			}
+			walk(ex.Else)
+		default:
+			ok = false
+		}
+	}
+	walk(e)
+	return refs, ok
+}
@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+func seedSemiJoinTables(t testing.TB, outer, inner int) *storage.DB {
+	t.Helper()
+	db := storage.NewDB()
+	ctx := context.Background()
+	run := func(sql string) {
+		if _, err := Execute(ctx, db, "default", mustParse(sql)); err != nil {
+			t.Fatalf("%s: %v", sql, err)
+		}
+	}
+	run(`CREATE TABLE customers (id INT, name TEXT)`)
+	run(`CREATE TABLE orders (id INT, customer_id INT, status TEXT)`)
+	for i := 0; i < outer; i++ {
+		run(fmt.Sprintf(`INSERT INTO customers VALUES (%d, 'c%d')`, i, i))
+	}
+	run(`INSERT INTO customers VALUES (NULL, 'anonymous')`)
+	for i := 0; i < inner; i++ {
+		// Only every third customer has orders; every other order is open.
+		status := "closed"
+		if i%2 == 0 {
+			status = "open"
+		}
+		run(fmt.Sprintf(`INSERT INTO orders VALUES (%d, %d, '%s')`, i, (i*3)%outer, status))
+	}
+	run(`INSERT INTO orders VALUES (-1, NULL, 'open')`)
+	return db
+}
+
+func semiJoinWhere(t *testing.T, db *storage.DB, sql string) Expr {
+	t.Helper()
+	s, ok := mustParse(sql).(*Select)
+	if !ok {
+		t.Fatalf("not a SELECT: %s", sql)
+	}
+	env := ExecEnv{ctx: context.Background(), tenant: "default", db: db}
+	where, err := rewriteExistsSemiJoins(env, s.Where)
+	if err != nil {
+		t.Fatalf("rewrite %s: %v", sql, err)
+	}
+	return where
+}
+
+func TestRewriteExistsSemiJoinEligibility(t *testing.T) {
+	db := seedSemiJoinTables(t, 10, 10)
+	rewritten := []string{
+		`SELECT id FROM customers c WHERE EXISTS (SELECT 1 FROM orders o WHERE o.customer_id = c.id)`,
+		`SELECT id FROM customers WHERE EXISTS (SELECT * FROM orders WHERE customers.id = orders.customer_id AND status = 'open')`,
+	}
+	for _, sql := range rewritten {
+		if _, ok := semiJoinWhere(t, db, sql).(*semiJoinExpr); !ok {
+			t.Errorf("expected semi-join rewrite for %s", sql)
+		}
+	}
+	if w, ok := semiJoinWhere(t, db, `SELECT id FROM customers c WHERE NOT EXISTS (SELECT 1 FROM orders o WHERE o.customer_id = c.id)`).(*Unary); !ok {
+		t.Errorf("expected NOT to be kept, got %T", w)
+	} else if _, ok := w.Expr.(*semiJoinExpr); !ok {
+		t.Errorf("expected NOT EXISTS operand to be rewritten, got %T", w.Expr)
+	}
+
+	kept := []string{
+		// Correlation through a non-equality.
+		`SELECT id FROM customers c WHERE EXISTS (SELECT 1 FROM orders o WHERE o.customer_id > c.id)`,
+		// Aggregate in the subquery.
+		`SELECT id FROM customers c WHERE EXISTS (SELECT COUNT(*) FROM orders o WHERE o.customer_id = c.id)`,
+		// More than one table.
+		`SELECT id FROM customers c WHERE EXISTS (SELECT 1 FROM orders o JOIN customers x ON x.id = o.customer_id WHERE o.customer_id = c.id)`,
+		// Uncorrelated.
+		`SELECT id FROM customers c WHERE EXISTS (SELECT 1 FROM orders o WHERE o.status = 'open')`,
+	}
+	for _, sql := range kept {
+		if _, ok := semiJoinWhere(t, db, sql).(*ExistsExpr); !ok {
+			t.Errorf("expected EXISTS to stay correlated for %s", sql)
+		}
+	}
+}
+
+func TestExistsSemiJoinMatchesCorrelatedPath(t *testing.T) {
+	db := seedSemiJoinTables(t, 60, 40)
+	pairs := []struct{ optimised, correlated string }{
+		{
+			`SELECT id FROM customers c WHERE EXISTS (SELECT 1 FROM orders o WHERE o.customer_id = c.id) ORDER BY id`,
+			`SELECT id FROM customers c WHERE EXISTS (SELECT 1 FROM orders o WHERE o.customer_id = c.id AND o.customer_id >= c.id) ORDER BY id`,
+		},
+		{
+			`SELECT id FROM customers c WHERE EXISTS (SELECT 1 FROM orders o WHERE c.id = o.customer_id AND o.status = 'open') ORDER BY id`,
+			`SELECT id FROM customers c WHERE EXISTS (SELECT 1 FROM orders o WHERE c.id = o.customer_id AND o.status = 'open' AND o.customer_id <= c.id) ORDER BY id`,
+		},
+		{
+			`SELECT name FROM customers c WHERE NOT EXISTS (SELECT 1 FROM orders o WHERE o.customer_id = c.id) ORDER BY name`,
+			`SELECT name FROM customers c WHERE NOT EXISTS (SELECT 1 FROM orders o WHERE o.customer_id = c.id AND o.customer_id >= c.id) ORDER BY name`,
+		},
+	}
+	for _, p := range pairs {
+		want := execSQL(t, db, p.correlated)
+		got := execSQL(t, db, p.optimised)
+		if len(got.Rows) == 0 {
+			t.Fatalf("expected rows for %s", p.optimised)
+		}
+		if !reflect.DeepEqual(got.Rows, want.Rows) {
+			t.Errorf("%s\n got %v\nwant %v", p.optimised, got.Rows, want.Rows)
+		}
+	}
+
+	// The NULL-keyed customer never matches EXISTS but always matches NOT EXISTS.
+	rs := execSQL(t, db, `SELECT name FROM customers c WHERE NOT EXISTS (SELECT 1 FROM orders o WHERE o.customer_id = c.id) AND id IS NULL`)
+	if len(rs.Rows) != 1 || rs.Rows[0]["name"] != "anonymous" {
+		t.Errorf("NOT EXISTS with NULL key: %v", rs.Rows)
+	}
+}
+
+// BenchmarkExistsSemiJoin compares the semi-join against the correlated
+// path; the extra non-equality in the second query keeps it correlated.
+func BenchmarkExistsSemiJoin(b *testing.B) {
+	db := seedSemiJoinTables(b, 1000, 1000)
+	ctx := context.Background()
+	for _, bc := range []struct{ name, sql string }{
+		{"semijoin", `SELECT id FROM customers c WHERE EXISTS (SELECT 1 FROM orders o WHERE o.customer_id = c.id)`},
+		{"correlated", `SELECT id FROM customers c WHERE EXISTS (SELECT 1 FROM orders o WHERE o.customer_id = c.id AND o.customer_id >= c.id)`},
+	} {
+		stmt := mustParse(bc.sql)
+		b.Run(bc.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := Execute(ctx, db, "default", stmt); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+// executeCreateSequence fills in PostgreSQL's defaults: an ascending
+// sequence runs from 1 to the largest int64, a descending one from -1 down
+// to the smallest, and START defaults to the bound it counts away from.
+func executeCreateSequence(env ExecEnv, s *CreateSequence) (*ResultSet, error) {
+	schema, name := splitObjectName(s.Name)
+	if _, exists := env.db.Catalog().GetSequence(env.tenant, schema, name); exists {
+		if s.IfNotExists {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("sequence %q already exists", s.Name)
+	}
+	seq := &storage.CatalogSequence{Schema: schema, Name: name, Increment: 1, Cycle: s.Cycle}
+	if s.Increment != nil {
+		seq.Increment = *s.Increment
+	}
+	seq.Min, seq.Max = 1, math.MaxInt64
+	if seq.Increment < 0 {
+		seq.Min, seq.Max = math.MinInt64, -1
+	}
+	if s.Min != nil {
+		seq.Min = *s.Min
+	}
+	if s.Max != nil {
+		seq.Max = *s.Max
+	}
+	seq.Start = seq.Min
+	if seq.Increment < 0 {
+		seq.Start = seq.Max
+	}
+	if s.Start != nil {
+		seq.Start = *s.Start
+	}
+	return nil, env.db.Catalog().CreateSequence(env.tenant, seq)
+}
+
+func executeDropSequence(env ExecEnv, s *DropSequence) (*ResultSet, error) {
+	schema, name := splitObjectName(s.Name)
+	if _, ok := env.db.Catalog().GetSequence(env.tenant, schema, name); !ok && s.IfExists {
+		return nil, nil
+	}
+	return nil, env.db.Catalog().DropSequence(env.tenant, schema, name)
+}
+
+// getSequenceFunctions returns NEXTVAL and CURRVAL. Both take the sequence
+// name as a string, as in PostgreSQL.
+func getSequenceFunctions() map[string]funcHandler {
+	return map[string]funcHandler{
+		"NEXTVAL": evalNextval,
+		"CURRVAL": evalCurrval,
+	}
+}
+
+func evalNextval(env ExecEnv, ex *FuncCall, row Row) (any, error) {
+	schema, name, err := sequenceNameArg(env, "NEXTVAL", ex, row)
+	if err != nil {
+		return nil, err
+	}
+	v, err := env.db.Catalog().NextSequenceValue(env.tenant, schema, name)
+	if err != nil {
+		return nil, err
+	}
+	return int(v), nil
+}
+
+func evalCurrval(env ExecEnv, ex *FuncCall, row Row) (any, error) {
+	schema, name, err := sequenceNameArg(env, "CURRVAL", ex, row)
+	if err != nil {
+		return nil, err
+	}
+	v, err := env.db.Catalog().CurrentSequenceValue(env.tenant, schema, name)
+	if err != nil {
+		return nil, err
+	}
+	return int(v), nil
+}
+
+func sequenceNameArg(env ExecEnv, fn string, ex *FuncCall, row Row) (schema, name string, err error) {
+	if len(ex.Args) != 1 {
+		return "", "", fmt.Errorf("%s expects 1 argument", fn)
+	}
+	v, err := evalExpr(env, ex.Args[0], row)
+	if err != nil {
+		return "", "", err
+	}
+	s, ok := v.(string)
+	if !ok || s == "" {
+		return "", "", fmt.Errorf("%s expects a sequence name", fn)
+	}
+	schema, name = splitObjectName(s)
+	return schema, name, nil
+}
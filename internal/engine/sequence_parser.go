@@ -0,0 +1,134 @@
+// Parser and AST for PostgreSQL-style sequences. SEQUENCE and its option
+// words are not reserved, so they arrive as identifiers.
+//
+// Grammar:
+//
+//	CREATE SEQUENCE [IF NOT EXISTS] name
+//	    [START [WITH] n] [INCREMENT [BY] n]
+//	    [MINVALUE n | NO MINVALUE] [MAXVALUE n | NO MAXVALUE]
+//	    [CYCLE | NO CYCLE]
+//	DROP SEQUENCE [IF EXISTS] name
+package engine
+
+import "strconv"
+
+// CreateSequence represents CREATE SEQUENCE. Nil bounds take PostgreSQL's
+// defaults, which depend on the sign of the increment (see
+// executeCreateSequence).
+type CreateSequence struct {
+	Name        string
+	IfNotExists bool
+	Start       *int64
+	Increment   *int64
+	Min         *int64
+	Max         *int64
+	Cycle       bool
+}
+
+// DropSequence represents DROP SEQUENCE.
+type DropSequence struct {
+	Name     string
+	IfExists bool
+}
+
+func (p *Parser) parseCreateSequence() (Statement, error) {
+	p.next() // SEQUENCE
+	stmt := &CreateSequence{}
+	if p.cur.Typ == tKeyword && p.cur.Val == "IF" {
+		p.next()
+		if err := p.expectKeyword("NOT"); err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("EXISTS"); err != nil {
+			return nil, err
+		}
+		stmt.IfNotExists = true
+	}
+	stmt.Name = p.parseQualifiedIdentLike()
+	if stmt.Name == "" {
+		return nil, p.errf("expected sequence name")
+	}
+	for {
+		word := ""
+		if p.cur.Typ == tIdent || p.cur.Typ == tKeyword {
+			word = upper(p.cur.Val)
+		}
+		var err error
+		switch word {
+		case "START":
+			p.next()
+			if p.cur.Typ == tKeyword && p.cur.Val == "WITH" {
+				p.next()
+			}
+			stmt.Start, err = p.parseSequenceValue("START")
+		case "INCREMENT":
+			p.next()
+			if p.cur.Typ == tKeyword && p.cur.Val == "BY" {
+				p.next()
+			}
+			stmt.Increment, err = p.parseSequenceValue("INCREMENT")
+		case "MINVALUE":
+			p.next()
+			stmt.Min, err = p.parseSequenceValue("MINVALUE")
+		case "MAXVALUE":
+			p.next()
+			stmt.Max, err = p.parseSequenceValue("MAXVALUE")
+		case "CYCLE":
+			p.next()
+			stmt.Cycle = true
+		case "NO":
+			p.next()
+			switch upper(p.cur.Val) {
+			case "CYCLE":
+				stmt.Cycle = false
+			case "MINVALUE":
+				stmt.Min = nil
+			case "MAXVALUE":
+				stmt.Max = nil
+			default:
+				return nil, p.errf("expected CYCLE, MINVALUE or MAXVALUE after NO")
+			}
+			p.next()
+		default:
+			return stmt, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parseSequenceValue reads an optionally signed integer literal.
+func (p *Parser) parseSequenceValue(option string) (*int64, error) {
+	sign := ""
+	if p.cur.Typ == tSymbol && (p.cur.Val == "-" || p.cur.Val == "+") {
+		sign = p.cur.Val
+		p.next()
+	}
+	if p.cur.Typ != tNumber {
+		return nil, p.errf("%s expects an integer", option)
+	}
+	n, err := strconv.ParseInt(sign+p.cur.Val, 10, 64)
+	if err != nil {
+		return nil, p.errf("%s expects an integer, got %s%s", option, sign, p.cur.Val)
+	}
+	p.next()
+	return &n, nil
+}
+
+func (p *Parser) parseDropSequence() (Statement, error) {
+	p.next() // SEQUENCE
+	stmt := &DropSequence{}
+	if p.cur.Typ == tKeyword && p.cur.Val == "IF" {
+		p.next()
+		if err := p.expectKeyword("EXISTS"); err != nil {
+			return nil, err
+		}
+		stmt.IfExists = true
+	}
+	stmt.Name = p.parseQualifiedIdentLike()
+	if stmt.Name == "" {
+		return nil, p.errf("expected sequence name")
+	}
+	return stmt, nil
+}
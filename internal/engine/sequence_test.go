@@ -0,0 +1,119 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+func nextval(t *testing.T, db *storage.DB, seq string) any {
+	t.Helper()
+	return execSQL(t, db, `SELECT NEXTVAL('`+seq+`') AS v`).Rows[0]["v"]
+}
+
+func expectSQLError(t *testing.T, db *storage.DB, sql, want string) {
+	t.Helper()
+	stmt, err := NewParser(sql).ParseStatement()
+	if err == nil {
+		_, err = Execute(context.Background(), db, "default", stmt)
+	}
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("%s: error %v, want %q", sql, err, want)
+	}
+}
+
+func TestSequenceNextvalAndCurrval(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE SEQUENCE ids`)
+	expectSQLError(t, db, `SELECT CURRVAL('ids') AS v`, "not yet defined")
+	for want := 1; want <= 3; want++ {
+		expectInt(t, nextval(t, db, "ids"), want, "NEXTVAL")
+	}
+	expectInt(t, execSQL(t, db, `SELECT CURRVAL('ids') AS v`).Rows[0]["v"], 3, "CURRVAL")
+	expectInt(t, execSQL(t, db, `SELECT CURRVAL('ids') AS v`).Rows[0]["v"], 3, "CURRVAL repeated")
+
+	// Every inserted row advances the sequence once.
+	execSQL(t, db, `CREATE TABLE items (id INT, name TEXT)`)
+	execSQL(t, db, `INSERT INTO items VALUES (NEXTVAL('ids'), 'a'), (NEXTVAL('ids'), 'b')`)
+	rows := execSQL(t, db, `SELECT id FROM items ORDER BY id`).Rows
+	expectInt(t, rows[0]["id"], 4, "first inserted id")
+	expectInt(t, rows[1]["id"], 5, "second inserted id")
+
+	expectSQLError(t, db, `CREATE SEQUENCE ids`, "already exists")
+	execSQL(t, db, `CREATE SEQUENCE IF NOT EXISTS ids`)
+	execSQL(t, db, `DROP SEQUENCE ids`)
+	expectSQLError(t, db, `SELECT NEXTVAL('ids') AS v`, "does not exist")
+	expectSQLError(t, db, `DROP SEQUENCE ids`, "does not exist")
+	execSQL(t, db, `DROP SEQUENCE IF EXISTS ids`)
+}
+
+// NEXTVAL in a projection over a table runs once per row; the plain
+// column list used to send it down the raw scan path without a catalog.
+func TestSequenceNextvalInProjection(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE SEQUENCE sq`)
+	execSQL(t, db, `CREATE TABLE t (id INT)`)
+	execSQL(t, db, `INSERT INTO t VALUES (10), (20), (30)`)
+	rows := execSQL(t, db, `SELECT id, NEXTVAL('sq') AS n FROM t WHERE id > 0 ORDER BY id`).Rows
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(rows))
+	}
+	for i, r := range rows {
+		expectInt(t, r["n"], i+1, "NEXTVAL per row")
+	}
+	rows = execSQL(t, db, `SELECT id, CURRVAL('sq') AS c FROM t`).Rows
+	expectInt(t, rows[0]["c"], 3, "CURRVAL per row")
+}
+
+func TestSequenceIncrementAndBounds(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE SEQUENCE by5 START WITH 10 INCREMENT BY 5`)
+	for _, want := range []int{10, 15, 20} {
+		expectInt(t, nextval(t, db, "by5"), want, "INCREMENT BY 5")
+	}
+
+	execSQL(t, db, `CREATE SEQUENCE ring MINVALUE 1 MAXVALUE 3 CYCLE`)
+	for _, want := range []int{1, 2, 3, 1, 2} {
+		expectInt(t, nextval(t, db, "ring"), want, "CYCLE")
+	}
+
+	execSQL(t, db, `CREATE SEQUENCE capped MAXVALUE 2 NO CYCLE`)
+	nextval(t, db, "capped")
+	nextval(t, db, "capped")
+	expectSQLError(t, db, `SELECT NEXTVAL('capped') AS v`, "maximum value")
+	expectInt(t, execSQL(t, db, `SELECT CURRVAL('capped') AS v`).Rows[0]["v"], 2, "CURRVAL after exhaustion")
+
+	execSQL(t, db, `CREATE SEQUENCE countdown INCREMENT -1 MINVALUE -2 MAXVALUE 0 CYCLE`)
+	for _, want := range []int{0, -1, -2, 0} {
+		expectInt(t, nextval(t, db, "countdown"), want, "descending CYCLE")
+	}
+
+	expectSQLError(t, db, `CREATE SEQUENCE bad INCREMENT 0`, "must not be zero")
+	expectSQLError(t, db, `CREATE SEQUENCE bad MINVALUE 5 MAXVALUE 1`, "MINVALUE")
+	expectSQLError(t, db, `CREATE SEQUENCE bad START 9 MAXVALUE 3`, "outside")
+}
+
+func TestSequencePersistsInSnapshot(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE SEQUENCE orders_seq START 100`)
+	nextval(t, db, "orders_seq")
+	nextval(t, db, "orders_seq")
+
+	data, err := storage.SaveToBytes(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := storage.LoadFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectInt(t, execSQL(t, loaded, `SELECT CURRVAL('orders_seq') AS v`).Rows[0]["v"], 101, "CURRVAL after reload")
+	expectInt(t, nextval(t, loaded, "orders_seq"), 102, "NEXTVAL after reload")
+
+	// Sequences are tenant-scoped.
+	if _, err := Execute(context.Background(), loaded, "other", mustParse(`SELECT NEXTVAL('orders_seq') AS v`)); err == nil {
+		t.Fatal("expected sequence to be invisible to another tenant")
+	}
+}
@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+func seedSetOperationTables(t *testing.T) *storage.DB {
+	t.Helper()
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE a (x INT)`)
+	execSQL(t, db, `CREATE TABLE b (y INT)`)
+	for _, v := range []string{"1", "1", "2", "NULL"} {
+		execSQL(t, db, `INSERT INTO a VALUES (`+v+`)`)
+	}
+	for _, v := range []string{"2", "3", "NULL"} {
+		execSQL(t, db, `INSERT INTO b VALUES (`+v+`)`)
+	}
+	return db
+}
+
+// setColumn returns the first column of rs in row order.
+func setColumn(rs *ResultSet) []any {
+	out := []any{}
+	for _, r := range rs.Rows {
+		out = append(out, r[rs.Cols[0]])
+	}
+	return out
+}
+
+func TestSetOperations(t *testing.T) {
+	db := seedSetOperationTables(t)
+	cases := []struct {
+		sql  string
+		want []any
+	}{
+		{`SELECT x FROM a UNION ALL SELECT y FROM b`, []any{1, 1, 2, nil, 2, 3, nil}},
+		{`SELECT x FROM a UNION SELECT y FROM b`, []any{1, 2, nil, 3}},
+		// NULLs compare as equal for duplicate elimination.
+		{`SELECT x FROM a INTERSECT SELECT y FROM b`, []any{2, nil}},
+		{`SELECT x FROM a EXCEPT SELECT y FROM b`, []any{1}},
+		{`SELECT y FROM b EXCEPT SELECT x FROM a`, []any{3}},
+		// Chains are evaluated left to right.
+		{`SELECT x FROM a UNION SELECT y FROM b EXCEPT SELECT 3`, []any{1, 2, nil}},
+		{`SELECT x FROM a UNION ALL SELECT y FROM b INTERSECT SELECT 1`, []any{1}},
+		{`SELECT x FROM a EXCEPT SELECT 1 UNION ALL SELECT 1`, []any{2, nil, 1}},
+	}
+	for _, c := range cases {
+		rs := execSQL(t, db, c.sql)
+		if len(rs.Cols) != 1 {
+			t.Fatalf("%s: cols = %v", c.sql, rs.Cols)
+		}
+		if got := setColumn(rs); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s = %v, want %v", c.sql, got, c.want)
+		}
+	}
+}
+
+func TestSetOperationColumnCountMismatch(t *testing.T) {
+	db := seedSetOperationTables(t)
+	for _, q := range []string{
+		`SELECT x, x AS z FROM a UNION SELECT y FROM b`,
+		`SELECT x FROM a INTERSECT SELECT y, y AS z FROM b`,
+	} {
+		_, err := Execute(context.Background(), db, "default", mustParse(q))
+		if err == nil || !strings.Contains(err.Error(), "column count mismatch") {
+			t.Errorf("%s: err = %v, want column count mismatch", q, err)
+		}
+	}
+}
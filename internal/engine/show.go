@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+// executeShowTables lists the tenant's tables in one column named after the
+// tenant, as MySQL names it after the database. LIKE is case-insensitive
+// because table names are.
+func executeShowTables(env ExecEnv, s *ShowTables) (*ResultSet, error) {
+	col := "Tables_in_" + env.tenant
+	pattern := strings.ToLower(s.Like)
+	var names []string
+	for _, t := range env.db.ListTables(env.tenant) {
+		if strings.HasPrefix(strings.ToLower(t.Name), "__mv_") {
+			continue
+		}
+		if s.Like != "" && !matchLikePattern(strings.ToLower(t.Name), pattern, '\\') {
+			continue
+		}
+		names = append(names, t.Name)
+	}
+	sort.Slice(names, func(i, j int) bool { return strings.ToLower(names[i]) < strings.ToLower(names[j]) })
+	rows := make([]Row, len(names))
+	for i, name := range names {
+		rows[i] = Row{strings.ToLower(col): name}
+	}
+	return &ResultSet{Cols: []string{col}, Rows: rows}, nil
+}
+
+// executeShowColumns describes a table with MySQL's DESCRIBE columns. Key
+// is PRI, UNI or MUL (foreign key); Extra marks expression defaults as
+// DEFAULT_GENERATED.
+func executeShowColumns(env ExecEnv, s *ShowColumns) (*ResultSet, error) {
+	t, err := env.db.Get(env.tenant, s.Table)
+	if err != nil {
+		return nil, err
+	}
+	cols := []string{"Field", "Type", "Null", "Key", "Default", "Extra"}
+	rows := make([]Row, 0, len(t.Cols))
+	for _, c := range t.Cols {
+		declaredType := c.DeclaredType
+		if declaredType == "" {
+			declaredType = c.Type.String()
+		}
+		null := "YES"
+		if c.NotNull || c.Constraint == storage.PrimaryKey {
+			null = "NO"
+		}
+		key := ""
+		switch c.Constraint {
+		case storage.PrimaryKey:
+			key = "PRI"
+		case storage.Unique:
+			key = "UNI"
+		case storage.ForeignKey:
+			key = "MUL"
+		}
+		var def any
+		extra := ""
+		if c.DefaultExpr != "" {
+			def, extra = c.DefaultExpr, "DEFAULT_GENERATED"
+		} else if c.HasDefault {
+			def = c.DefaultValue
+		}
+		rows = append(rows, Row{
+			"field":   c.Name,
+			"type":    declaredType,
+			"null":    null,
+			"key":     key,
+			"default": def,
+			"extra":   extra,
+		})
+	}
+	return &ResultSet{Cols: cols, Rows: rows}, nil
+}
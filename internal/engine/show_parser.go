@@ -0,0 +1,70 @@
+// Parser and AST for the MySQL-style schema shortcuts. SHOW and DESCRIBE
+// are not reserved, so they arrive as identifiers; DESC is the ORDER BY
+// keyword and is accepted at statement start as well.
+//
+// Grammar:
+//
+//	SHOW TABLES [LIKE 'pattern']
+//	SHOW COLUMNS {FROM | IN} name
+//	{DESCRIBE | DESC} name
+package engine
+
+// ShowTables represents SHOW TABLES. An empty Like lists every table.
+type ShowTables struct {
+	Like string
+}
+
+// ShowColumns represents SHOW COLUMNS FROM and DESCRIBE.
+type ShowColumns struct {
+	Table string
+}
+
+// isShowStart reports whether the current token opens SHOW TABLES or SHOW
+// COLUMNS, so that a table literally named "show" still parses as a bare
+// table select.
+func (p *Parser) isShowStart() bool {
+	if !p.isIdentWord("SHOW") || (p.peek.Typ != tIdent && p.peek.Typ != tKeyword) {
+		return false
+	}
+	switch upper(p.peek.Val) {
+	case "TABLES", "COLUMNS":
+		return true
+	}
+	return false
+}
+
+func (p *Parser) parseShow() (Statement, error) {
+	p.next() // SHOW
+	word := upper(p.cur.Val)
+	p.next()
+	if word == "TABLES" {
+		stmt := &ShowTables{}
+		if p.cur.Typ == tKeyword && p.cur.Val == "LIKE" {
+			p.next()
+			if p.cur.Typ != tString {
+				return nil, p.errf("expected pattern string after SHOW TABLES LIKE")
+			}
+			stmt.Like = p.cur.Val
+			p.next()
+		}
+		return stmt, nil
+	}
+	if p.cur.Typ != tKeyword || (p.cur.Val != "FROM" && p.cur.Val != "IN") {
+		return nil, p.errf("expected FROM after SHOW COLUMNS")
+	}
+	p.next()
+	return p.parseShowColumnsTable()
+}
+
+func (p *Parser) parseDescribe() (Statement, error) {
+	p.next() // DESCRIBE / DESC
+	return p.parseShowColumnsTable()
+}
+
+func (p *Parser) parseShowColumnsTable() (Statement, error) {
+	name := p.parseQualifiedIdentLike()
+	if name == "" {
+		return nil, p.errf("expected table name")
+	}
+	return &ShowColumns{Table: name}, nil
+}
@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+func showDB(t *testing.T) *storage.DB {
+	t.Helper()
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE users (id INT PRIMARY KEY, email VARCHAR(80) UNIQUE, name TEXT NOT NULL DEFAULT 'anon', created TIMESTAMP DEFAULT CURRENT_TIMESTAMP)`)
+	execSQL(t, db, `CREATE TABLE orders (id INT PRIMARY KEY, user_id INT REFERENCES users(id))`)
+	execSQL(t, db, `CREATE TABLE order_items (order_id INT, sku TEXT)`)
+	return db
+}
+
+func showTableNames(rs *ResultSet) []any {
+	var names []any
+	for _, row := range rs.Rows {
+		names = append(names, row["tables_in_default"])
+	}
+	return names
+}
+
+func TestShowTables(t *testing.T) {
+	db := showDB(t)
+	rs := execSQL(t, db, `SHOW TABLES`)
+	if fmt.Sprint(rs.Cols) != "[Tables_in_default]" {
+		t.Fatalf("cols = %v", rs.Cols)
+	}
+	if got := fmt.Sprint(showTableNames(rs)); got != "[order_items orders users]" {
+		t.Fatalf("SHOW TABLES = %s", got)
+	}
+	if got := fmt.Sprint(showTableNames(execSQL(t, db, `show tables like 'ORD%'`))); got != "[order_items orders]" {
+		t.Fatalf("SHOW TABLES LIKE = %s", got)
+	}
+	if rs := execSQL(t, db, `SHOW TABLES LIKE 'missing%'`); len(rs.Rows) != 0 {
+		t.Fatalf("SHOW TABLES LIKE no match = %v", rs.Rows)
+	}
+}
+
+func TestDescribeTable(t *testing.T) {
+	db := showDB(t)
+	want := []string{
+		"id INT NO PRI <nil> ",
+		"email VARCHAR(80) YES UNI <nil> ",
+		"name TEXT NO  anon ",
+		"created TIMESTAMP YES  CURRENT_TIMESTAMP DEFAULT_GENERATED",
+	}
+	for _, sql := range []string{`DESCRIBE users`, `DESC users`, `SHOW COLUMNS FROM users`} {
+		rs := execSQL(t, db, sql)
+		if fmt.Sprint(rs.Cols) != "[Field Type Null Key Default Extra]" {
+			t.Fatalf("%s: cols = %v", sql, rs.Cols)
+		}
+		if len(rs.Rows) != len(want) {
+			t.Fatalf("%s: rows = %v", sql, rs.Rows)
+		}
+		for i, row := range rs.Rows {
+			got := fmt.Sprint(row["field"], " ", row["type"], " ", row["null"], " ", row["key"], " ", row["default"], " ", row["extra"])
+			if got != want[i] {
+				t.Errorf("%s: row %d = %q, want %q", sql, i, got, want[i])
+			}
+		}
+	}
+	if key := execSQL(t, db, `DESCRIBE orders`).Rows[1]["key"]; key != "MUL" {
+		t.Fatalf("foreign key column Key = %v, want MUL", key)
+	}
+	expectSQLError(t, db, `DESCRIBE missing`, "missing")
+
+	// A table named like the command words still works as a bare select.
+	execSQL(t, db, `CREATE TABLE show (x INT)`)
+	execSQL(t, db, `INSERT INTO show VALUES (1)`)
+	expectInt(t, execSQL(t, db, `show`).Rows[0]["x"], 1, "bare table select")
+}
@@ -0,0 +1,340 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+// dumpInsertBatch is the number of rows written per INSERT by DumpSQL.
+const dumpInsertBatch = 100
+
+// DumpSQL writes tenant's tables as a SQL script: one CREATE TABLE per table
+// with its columns, constraints and defaults, batched multi-row INSERTs and
+// the table's CREATE INDEX statements. Tables are ordered so that every
+// foreign key target is created and filled first. The script uses only
+// syntax SQLite also accepts and is read back by LoadSQL.
+func DumpSQL(ctx context.Context, db *storage.DB, tenant string, w io.Writer) error {
+	db.LockContentForRead()
+	defer db.UnlockContentForRead()
+
+	bw := bufio.NewWriter(w)
+	tables := dumpTableOrder(db.ListTables(tenant))
+	for _, t := range tables {
+		schema, name := splitObjectName(t.Name)
+		if _, err := fmt.Fprintf(bw, "%s;\n", sqliteCreateTableSQL(schema, name, t)); err != nil {
+			return err
+		}
+	}
+	for _, t := range tables {
+		if err := checkCtx(ctx); err != nil {
+			return err
+		}
+		if err := dumpTableRows(ctx, bw, t); err != nil {
+			return err
+		}
+		if err := dumpTableIndexes(bw, t); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// dumpTableOrder drops temporary and materialized-view cache tables and
+// sorts the rest by name, moving each table after those its foreign keys
+// reference. Reference cycles keep name order.
+func dumpTableOrder(all []*storage.Table) []*storage.Table {
+	byName := map[string]*storage.Table{}
+	var names []string
+	for _, t := range all {
+		lc := strings.ToLower(t.Name)
+		if t.IsTemp || strings.HasPrefix(lc, "__mv_") {
+			continue
+		}
+		byName[lc] = t
+		names = append(names, lc)
+	}
+	sort.Strings(names)
+
+	out := make([]*storage.Table, 0, len(names))
+	state := map[string]int{} // 1 = visiting, 2 = done
+	var visit func(string)
+	visit = func(name string) {
+		t, ok := byName[name]
+		if !ok || state[name] != 0 {
+			return
+		}
+		state[name] = 1
+		for _, c := range t.Cols {
+			if c.Constraint == storage.ForeignKey && c.ForeignKey != nil {
+				visit(strings.ToLower(c.ForeignKey.Table))
+			}
+		}
+		state[name] = 2
+		out = append(out, t)
+	}
+	for _, name := range names {
+		visit(name)
+	}
+	return out
+}
+
+func dumpTableRows(ctx context.Context, w io.Writer, t *storage.Table) error {
+	if len(t.Rows) == 0 {
+		return nil
+	}
+	cols := make([]string, len(t.Cols))
+	for i, c := range t.Cols {
+		cols[i] = sqliteIdent(c.Name)
+	}
+	header := "INSERT INTO " + sqliteIdent(t.Name) + " (" + strings.Join(cols, ", ") + ") VALUES\n"
+	for start := 0; start < len(t.Rows); start += dumpInsertBatch {
+		if err := checkCtx(ctx); err != nil {
+			return err
+		}
+		end := min(start+dumpInsertBatch, len(t.Rows))
+		var sb strings.Builder
+		sb.WriteString(header)
+		for i, row := range t.Rows[start:end] {
+			if i > 0 {
+				sb.WriteString(",\n")
+			}
+			sb.WriteString("  (")
+			for j := range t.Cols {
+				if j > 0 {
+					sb.WriteString(", ")
+				}
+				var v any
+				if j < len(row) {
+					v = row[j]
+				}
+				sb.WriteString(sqlLiteral(v))
+			}
+			sb.WriteByte(')')
+		}
+		sb.WriteString(";\n")
+		if _, err := io.WriteString(w, sb.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dumpTableIndexes(w io.Writer, t *storage.Table) error {
+	names := make([]string, 0, len(t.Indexes))
+	for name := range t.Indexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		idx := t.Indexes[name]
+		cols := make([]string, len(idx.Columns))
+		for i, c := range idx.Columns {
+			cols[i] = sqliteIdent(c)
+		}
+		unique := ""
+		if idx.Unique {
+			unique = "UNIQUE "
+		}
+		if _, err := fmt.Fprintf(w, "CREATE %sINDEX %s ON %s (%s);\n",
+			unique, sqliteIdent(idx.Name), sqliteIdent(t.Name), strings.Join(cols, ", ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sqlLiteral renders a stored value as a SQL literal that tinySQL and
+// SQLite both read back as the same value. JSON, decimals and times become
+// quoted text, which the column type converts again on INSERT.
+func sqlLiteral(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return quoteSQLString(x)
+	case []byte:
+		return "X'" + fmt.Sprintf("%X", x) + "'"
+	case bool:
+		if x {
+			return "TRUE"
+		}
+		return "FALSE"
+	case int:
+		return strconv.Itoa(x)
+	case int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(x)
+	case float32:
+		return sqlFloatLiteral(float64(x))
+	case float64:
+		return sqlFloatLiteral(x)
+	case time.Time:
+		return quoteSQLString(x.Format(time.RFC3339Nano))
+	case *big.Rat:
+		return quoteSQLString(x.RatString())
+	case map[string]any, []any:
+		b, err := storage.JSONMarshal(x)
+		if err != nil {
+			return quoteSQLString(fmt.Sprint(x))
+		}
+		return quoteSQLString(string(b))
+	default:
+		return quoteSQLString(fmt.Sprint(x))
+	}
+}
+
+func sqlFloatLiteral(f float64) string {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return quoteSQLString(strconv.FormatFloat(f, 'g', -1, 64))
+	}
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if !strings.Contains(s, ".") {
+		s += ".0" // keep the value a float in SQLite
+	}
+	return s
+}
+
+func quoteSQLString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// LoadSQL executes every statement of a SQL script read from r against
+// tenant, stopping at the first error. Statements are separated by
+// semicolons outside quotes and comments, and r is read incrementally, so
+// scripts written by DumpSQL load without being held in memory at once.
+func LoadSQL(ctx context.Context, db *storage.DB, tenant string, r io.Reader) error {
+	sc := newSQLScriptScanner(r)
+	for n := 1; ; n++ {
+		text, err := sc.next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		stmt, err := NewParser(text).ParseStatement()
+		if err != nil {
+			return fmt.Errorf("statement %d: %w", n, err)
+		}
+		if _, err := Execute(ctx, db, tenant, stmt); err != nil {
+			return fmt.Errorf("statement %d: %w", n, err)
+		}
+	}
+}
+
+// sqlScriptScanner splits a SQL script into statements. Semicolons inside
+// 'strings', "identifiers", -- line comments and /* block comments */ do
+// not end a statement; comments are dropped.
+type sqlScriptScanner struct {
+	r *bufio.Reader
+}
+
+func newSQLScriptScanner(r io.Reader) *sqlScriptScanner {
+	return &sqlScriptScanner{r: bufio.NewReader(r)}
+}
+
+// next returns the next non-empty statement without its semicolon, or
+// io.EOF when the script is exhausted.
+func (s *sqlScriptScanner) next() (string, error) {
+	var sb strings.Builder
+	for {
+		ch, _, err := s.r.ReadRune()
+		if errors.Is(err, io.EOF) {
+			if stmt := strings.TrimSpace(sb.String()); stmt != "" {
+				return stmt, nil
+			}
+			return "", io.EOF
+		}
+		if err != nil {
+			return "", err
+		}
+		switch ch {
+		case ';':
+			if stmt := strings.TrimSpace(sb.String()); stmt != "" {
+				return stmt, nil
+			}
+			sb.Reset()
+			continue
+		case '\'', '"':
+			sb.WriteRune(ch)
+			if err := s.copyQuoted(&sb, ch); err != nil {
+				return "", err
+			}
+			continue
+		case '-', '/':
+			next, _, err := s.r.ReadRune()
+			if err == nil {
+				if ch == '-' && next == '-' {
+					_, err := s.r.ReadString('\n')
+					if err != nil && !errors.Is(err, io.EOF) {
+						return "", err
+					}
+					sb.WriteByte('\n')
+					continue
+				}
+				if ch == '/' && next == '*' {
+					if err := s.skipBlockComment(); err != nil {
+						return "", err
+					}
+					sb.WriteByte(' ')
+					continue
+				}
+				_ = s.r.UnreadRune()
+			}
+		}
+		sb.WriteRune(ch)
+	}
+}
+
+// copyQuoted copies up to and including the closing quote; a doubled quote
+// is an escaped quote character.
+func (s *sqlScriptScanner) copyQuoted(sb *strings.Builder, quote rune) error {
+	for {
+		ch, _, err := s.r.ReadRune()
+		if errors.Is(err, io.EOF) {
+			return nil // unterminated: let the parser report it
+		}
+		if err != nil {
+			return err
+		}
+		sb.WriteRune(ch)
+		if ch != quote {
+			continue
+		}
+		next, _, err := s.r.ReadRune()
+		if err != nil {
+			return nil
+		}
+		if next != quote {
+			return s.r.UnreadRune()
+		}
+		sb.WriteRune(next)
+	}
+}
+
+func (s *sqlScriptScanner) skipBlockComment() error {
+	var prev rune
+	for {
+		ch, _, err := s.r.ReadRune()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if prev == '*' && ch == '/' {
+			return nil
+		}
+		prev = ch
+	}
+}
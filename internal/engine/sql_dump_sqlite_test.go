@@ -0,0 +1,31 @@
+//go:build sqliteimport && !js && !wasm && !baremetal
+
+package engine
+
+import (
+	"database/sql"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestDumpSQLLoadsInSQLite(t *testing.T) {
+	script := dumpString(t, newDumpDB(t))
+
+	sdb, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer sdb.Close()
+	if _, err := sdb.Exec(script); err != nil {
+		t.Fatalf("sqlite rejected dump: %v\n%s", err, script)
+	}
+	var n int
+	var meta sql.NullString
+	if err := sdb.QueryRow(`SELECT COUNT(*) FROM orders`).Scan(&n); err != nil || n != 152 {
+		t.Fatalf("sqlite orders count = %d, %v; want 152", n, err)
+	}
+	if err := sdb.QueryRow(`SELECT meta FROM orders WHERE id = 11`).Scan(&meta); err != nil || meta.Valid {
+		t.Fatalf("sqlite meta for NULL row = %v, %v; want NULL", meta, err)
+	}
+}
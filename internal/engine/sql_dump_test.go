@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+func newDumpDB(t *testing.T) *storage.DB {
+	t.Helper()
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE orders (id INT PRIMARY KEY, customer_id INT REFERENCES customers(id), total FLOAT, meta JSON, note TEXT DEFAULT 'none')`)
+	execSQL(t, db, `CREATE TABLE customers (id INT PRIMARY KEY, name TEXT NOT NULL, active BOOL, CHECK (id > 0))`)
+	execSQL(t, db, `CREATE INDEX idx_orders_customer ON orders (customer_id)`)
+	execSQL(t, db, `INSERT INTO customers VALUES (1, 'Ann''s shop', TRUE), (2, 'Bob; Ltd', FALSE)`)
+	execSQL(t, db, `INSERT INTO orders VALUES (10, 1, 12.5, '{"tags":["a","b"],"n":1}', 'rush'), (11, 2, 3, NULL, NULL)`)
+	for i := 0; i < 150; i++ {
+		execSQL(t, db, fmt.Sprintf(`INSERT INTO orders (id, customer_id, total) VALUES (%d, 1, %d.25)`, 100+i, i))
+	}
+	return db
+}
+
+func dumpString(t *testing.T, db *storage.DB) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := DumpSQL(context.Background(), db, "default", &buf); err != nil {
+		t.Fatalf("DumpSQL: %v", err)
+	}
+	return buf.String()
+}
+
+func TestDumpSQLRoundTrip(t *testing.T) {
+	src := newDumpDB(t)
+	script := dumpString(t, src)
+
+	dst := storage.NewDB()
+	if err := LoadSQL(context.Background(), dst, "default", strings.NewReader(script)); err != nil {
+		t.Fatalf("LoadSQL: %v\n%s", err, script)
+	}
+	for _, q := range []string{
+		`SELECT * FROM customers ORDER BY id`,
+		`SELECT * FROM orders ORDER BY id`,
+	} {
+		want := execSQL(t, src, q)
+		got := execSQL(t, dst, q)
+		if !reflect.DeepEqual(want.Rows, got.Rows) {
+			t.Fatalf("%s differs after round trip:\nwant %v\n got %v", q, want.Rows, got.Rows)
+		}
+	}
+	ot, err := dst.Get("default", "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ot.Indexes["idx_orders_customer"]; !ok {
+		t.Fatalf("index not restored: %v", ot.Indexes)
+	}
+	if c := ot.Cols[4]; !c.HasDefault || c.DefaultValue != "none" {
+		t.Fatalf("default not restored: %+v", c)
+	}
+	if _, err := Execute(context.Background(), dst, "default", mustParse(`INSERT INTO customers (id, name) VALUES (0, 'x')`)); err == nil {
+		t.Fatal("CHECK constraint not restored")
+	}
+}
+
+func TestDumpSQLOutput(t *testing.T) {
+	script := dumpString(t, newDumpDB(t))
+
+	if c, o := strings.Index(script, `CREATE TABLE "customers"`), strings.Index(script, `CREATE TABLE "orders"`); c < 0 || o < 0 || c > o {
+		t.Fatalf("referenced table must be created first:\n%s", script)
+	}
+	if !strings.Contains(script, `(11, 2, 3.0, NULL, NULL)`) {
+		t.Fatalf("NULL values not rendered as NULL:\n%s", script)
+	}
+	if !strings.Contains(script, `'{"n":1,"tags":["a","b"]}'`) && !strings.Contains(script, `'{"tags":["a","b"],"n":1}'`) {
+		t.Fatalf("JSON value not single-quoted:\n%s", script)
+	}
+	if !strings.Contains(script, `'Ann''s shop'`) {
+		t.Fatalf("quote in string not escaped:\n%s", script)
+	}
+	// 152 order rows need two INSERT statements of at most 100 rows.
+	if n := strings.Count(script, `INSERT INTO "orders"`); n != 2 {
+		t.Fatalf("orders written in %d INSERTs, want 2", n)
+	}
+}
+
+func TestSQLScriptScanner(t *testing.T) {
+	sc := newSQLScriptScanner(strings.NewReader(`
+-- leading comment; with a semicolon
+SELECT 'a;b', "c;""d" FROM t; /* block; comment */
+;
+SELECT 1 - -2 / 1
+`))
+	var got []string
+	for {
+		s, err := sc.next()
+		if err != nil {
+			break
+		}
+		got = append(got, strings.Join(strings.Fields(s), " "))
+	}
+	want := []string{`SELECT 'a;b', "c;""d" FROM t`, `SELECT 1 - -2 / 1`}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("statements = %q, want %q", got, want)
+	}
+}
@@ -57,7 +57,7 @@ func pragmaTableInfo(env ExecEnv, tableName string, includeHidden bool) *ResultS
 		}
 		var defaultValue any
 		if c.HasDefault {
-			defaultValue = sqliteDefaultSQL(c.DefaultValue)
+			defaultValue = sqliteColumnDefaultSQL(c)
 		}
 		row := Row{
 			"cid":        i,
@@ -246,13 +246,75 @@ func sqliteCreateTableSQL(schema, name string, t *storage.Table) string {
 			part += " NOT NULL"
 		}
 		if c.HasDefault {
-			part += " DEFAULT " + sqliteDefaultSQL(c.DefaultValue)
+			part += " DEFAULT " + sqliteColumnDefaultSQL(c)
+		}
+		if c.Check != "" {
+			part += " CHECK (" + c.Check + ")"
+		}
+		parts = append(parts, part)
+	}
+	for _, u := range t.Uniques {
+		names := make([]string, len(u.Columns))
+		for i, c := range u.Columns {
+			names[i] = sqliteIdent(c)
+		}
+		parts = append(parts, "UNIQUE ("+strings.Join(names, ", ")+")")
+	}
+	for _, c := range t.Checks {
+		part := "CHECK (" + c.Expr + ")"
+		if c.Name != "" {
+			part = "CONSTRAINT " + sqliteIdent(c.Name) + " " + part
 		}
 		parts = append(parts, part)
 	}
 	return "CREATE TABLE " + sqliteIdent(catalogDisplayName(schema, name)) + " (" + strings.Join(parts, ", ") + ")"
 }
 
+// sqliteColumnDefaultSQL renders a column default as SQLite declares it:
+// literals as SQL literals, the CURRENT_* keywords bare and any other
+// expression in parentheses.
+func sqliteColumnDefaultSQL(c storage.Column) string {
+	if c.DefaultExpr == "" {
+		return sqliteDefaultSQL(c.DefaultValue)
+	}
+	if isNiladicTimeFunc(c.DefaultExpr) {
+		return strings.ToUpper(c.DefaultExpr)
+	}
+	if isParenthesized(c.DefaultExpr) {
+		return c.DefaultExpr
+	}
+	return "(" + c.DefaultExpr + ")"
+}
+
+// isParenthesized reports whether s is wrapped in one pair of parentheses
+// that match each other, so "(1+2)" is but "(1)+(2)" is not.
+func isParenthesized(s string) bool {
+	if !strings.HasPrefix(s, "(") || !strings.HasSuffix(s, ")") {
+		return false
+	}
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+			if depth == 0 && i != len(s)-1 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
+
 func sqliteDefaultSQL(v any) string {
 	switch x := v.(type) {
 	case nil:
@@ -5,6 +5,7 @@ package engine
 
 import (
 	"context"
+	"strconv"
 	"testing"
 
 	"github.com/SimonWaldherr/tinySQL/internal/storage"
@@ -93,6 +94,44 @@ func TestLikeNullSemantics(t *testing.T) {
 	}
 }
 
+func TestLikeAnchoringAndILike(t *testing.T) {
+	db := storage.NewDB()
+	cases := []struct {
+		expr string
+		want bool
+	}{
+		// Patterns are anchored at both ends.
+		{`'abc' LIKE 'ab'`, false},
+		{`'abc' LIKE 'b%'`, false},
+		{`'abc' LIKE '%c'`, true},
+		{`'abc' LIKE 'abc'`, true},
+		// _ matches exactly one character.
+		{`'abc' LIKE 'a_c'`, true},
+		{`'ac' LIKE 'a_c'`, false},
+		{`'abbc' LIKE 'a_c'`, false},
+		// Custom ESCAPE character.
+		{`'a_c' LIKE 'a!_c' ESCAPE '!'`, true},
+		{`'abc' LIKE 'a!_c' ESCAPE '!'`, false},
+		// ILIKE folds case on both sides; LIKE does not.
+		{`'HeLLo' LIKE 'hello'`, false},
+		{`'HeLLo' ILIKE 'hello'`, true},
+		{`'hello' ILIKE 'H_LL%'`, true},
+		{`'hello' NOT ILIKE 'H%'`, false},
+		{`'hello' NOT LIKE 'H%'`, true},
+	}
+	for _, c := range cases {
+		if got := queryBool(t, db, c.expr); got != c.want {
+			t.Errorf("%s = %v, want %v", c.expr, got, c.want)
+		}
+	}
+
+	for _, expr := range []string{`NULL LIKE 'a%'`, `'abc' LIKE NULL`, `NULL ILIKE 'a%'`, `NULL NOT LIKE 'a%'`} {
+		if got := queryScalar(t, db, expr); got != nil {
+			t.Errorf("%s = %v, want NULL", expr, got)
+		}
+	}
+}
+
 func TestBetweenSingleEvaluation(t *testing.T) {
 	db := storage.NewDB()
 	execSQL(t, db, `CREATE TABLE n (v INT)`)
@@ -200,3 +239,219 @@ func TestRegexpCachedAndCorrect(t *testing.T) {
 		t.Error("REGEXP_MATCH: expected true")
 	}
 }
+
+func TestRegexpOperators(t *testing.T) {
+	db := storage.NewDB()
+	cases := []struct {
+		expr string
+		want any
+	}{
+		{`'start here' REGEXP 'here'`, true},
+		{`'start here' RLIKE '^start'`, true},
+		{`'here start' REGEXP '^start'`, false},
+		{`'Start' REGEXP 'start'`, false},
+		{`'Start' IREGEXP 'start'`, true},
+		{`'Start' NOT IREGEXP '^START$'`, false},
+		{`'abc' NOT REGEXP 'x'`, true},
+		{`'abc' NOT RLIKE 'b'`, false},
+		{`NULL REGEXP 'a'`, nil},
+		{`'a' REGEXP NULL`, nil},
+		{`NULL IREGEXP 'a'`, nil},
+	}
+	for _, c := range cases {
+		if got := queryScalar(t, db, c.expr); got != c.want {
+			t.Errorf("%s = %v, want %v", c.expr, got, c.want)
+		}
+	}
+	if _, err := Execute(context.Background(), db, "default", mustParse(`SELECT 'a' IREGEXP '(' AS r`)); err == nil {
+		t.Fatal("expected error for invalid IREGEXP pattern")
+	}
+
+	execSQL(t, db, `CREATE TABLE names (n TEXT)`)
+	execSQL(t, db, `INSERT INTO names VALUES ('Alice'), ('alfred'), ('Bob'), (NULL)`)
+	rs := execSQL(t, db, `SELECT n FROM names WHERE n IREGEXP '^al'`)
+	if len(rs.Rows) != 2 {
+		t.Fatalf("IREGEXP filter: got %v", rs.Rows)
+	}
+	rs = execSQL(t, db, `SELECT n FROM names WHERE n NOT REGEXP '^A'`)
+	if len(rs.Rows) != 2 {
+		t.Fatalf("NOT REGEXP filter: got %v", rs.Rows)
+	}
+}
+
+func TestRegexpPatternCompiledOnce(t *testing.T) {
+	const pattern = `^row-[0-9]+-cache-probe$`
+	if _, err := compileCachedRegexp(pattern); err != nil {
+		t.Fatal(err)
+	}
+	regexCacheMu.RLock()
+	first := regexCache[pattern]
+	regexCacheMu.RUnlock()
+
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE rows10k (v TEXT)`)
+	tbl, _ := db.Get("default", "rows10k")
+	for i := 0; i < 10000; i++ {
+		tbl.Rows = append(tbl.Rows, []any{"row-" + strconv.Itoa(i) + "-cache-probe"})
+	}
+	rs := execSQL(t, db, `SELECT COUNT(*) AS n FROM rows10k WHERE LOWER(v) REGEXP '`+pattern+`'`)
+	expectInt(t, rs.Rows[0]["n"], 10000, "matches")
+
+	regexCacheMu.RLock()
+	again := regexCache[pattern]
+	regexCacheMu.RUnlock()
+	if again != first {
+		t.Fatal("pattern was recompiled instead of served from the cache")
+	}
+}
+
+func TestStringFunctionSuite(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE words (leading TEXT)`)
+	execSQL(t, db, `INSERT INTO words VALUES ('  pad  ')`)
+	if rs := execSQL(t, db, `SELECT TRIM(leading) AS r FROM words`); rs.Rows[0]["r"] != "pad" {
+		t.Errorf("TRIM of a column named leading = %v", rs.Rows[0]["r"])
+	}
+
+	cases := []struct {
+		expr string
+		want any
+	}{
+		{`LENGTH('héllo')`, 5},
+		{`LENGTH('')`, 0},
+		{`UPPER('héllo')`, "HÉLLO"},
+		{`LOWER('ABC')`, "abc"},
+		{`TRIM(LEADING 'x' FROM 'xxaxx')`, "axx"},
+		{`TRIM(TRAILING 'x' FROM 'xxaxx')`, "xxa"},
+		{`TRIM(BOTH 'x' FROM 'xxaxx')`, "a"},
+		{`TRIM('x' FROM 'xxaxx')`, "a"},
+		{`TRIM(FROM '  a  ')`, "a"},
+		{`LTRIM('  a ')`, "a "},
+		{`RTRIM(' a  ')`, " a"},
+		{`SUBSTR('hello', 2)`, "ello"},
+		{`SUBSTR('hello', 2, 3)`, "ell"},
+		{`SUBSTR('hello', -3)`, "llo"},
+		{`SUBSTR('hello', -3, 2)`, "ll"},
+		{`SUBSTR('hello', 0, 2)`, "h"},
+		{`SUBSTR('hello', 10)`, ""},
+		{`SUBSTR('héllo', 2, 2)`, "él"},
+		{`SUBSTR('', 1)`, ""},
+		{`LEFT('héllo', 2)`, "hé"},
+		{`LEFT('日本語', 5)`, "日本語"},
+		{`LEFT('abc', -1)`, ""},
+		{`RIGHT('héllo', 4)`, "éllo"},
+		{`RIGHT('日本語', 1)`, "語"},
+		{`RIGHT('abc', 0)`, ""},
+		{`REPLACE('aXbX', 'X', '-')`, "a-b-"},
+		{`REPLACE('ab', '', 'x')`, "ab"},
+		{`CONCAT('a', 1, 'b')`, "a1b"},
+		{`INSTR('héllo', 'l')`, 3},
+		{`INSTR('hello', 'z')`, 0},
+		{`INSTR('', '')`, 1},
+		{`LPAD('ab', 5, 'xy')`, "xyxab"},
+		{`LPAD('abcdef', 3, 'x')`, "abc"},
+		{`LPAD('ab', 0, 'x')`, ""},
+		{`LPAD('ab', 5, '')`, "ab"},
+		{`LPAD('ab', 4)`, "  ab"},
+		{`RPAD('hé', 4, 'ü')`, "héüü"},
+		{`REPEAT('ab', 3)`, "ababab"},
+		{`REPEAT('ab', 0)`, ""},
+		{`REVERSE('héllo')`, "olléh"},
+		{`REVERSE('')`, ""},
+		{`SPLIT_PART('a,b,c', ',', 2)`, "b"},
+		{`SPLIT_PART('a,b,c', ',', -1)`, "c"},
+		{`SPLIT_PART('a,b,c', ',', 5)`, ""},
+		{`SPLIT_PART('abc', '', 1)`, "abc"},
+	}
+	for _, c := range cases {
+		if got := queryScalar(t, db, c.expr); got != c.want {
+			t.Errorf("%s = %#v, want %#v", c.expr, got, c.want)
+		}
+	}
+
+	// NULL in any required argument yields NULL. CONCAT skips NULL
+	// arguments, as in PostgreSQL and CONCAT_WS.
+	for _, expr := range []string{
+		`LENGTH(NULL)`, `UPPER(NULL)`, `LOWER(NULL)`, `TRIM(NULL)`, `TRIM(NULL FROM 'xax')`,
+		`LTRIM(NULL)`, `RTRIM('a', NULL)`, `SUBSTR(NULL, 1)`, `SUBSTR('abc', NULL)`,
+		`SUBSTR('abc', 1, NULL)`, `LEFT(NULL, 1)`, `LEFT('abc', NULL)`, `RIGHT('abc', NULL)`,
+		`REPLACE(NULL, 'a', 'b')`, `REPLACE('a', NULL, 'b')`,
+		`REPLACE('a', 'a', NULL)`, `INSTR(NULL, 'a')`, `INSTR('a', NULL)`,
+		`LPAD(NULL, 3, 'x')`, `LPAD('a', NULL, 'x')`, `RPAD('a', 3, NULL)`,
+		`REPEAT(NULL, 2)`, `REPEAT('a', NULL)`, `REVERSE(NULL)`,
+		`SPLIT_PART(NULL, ',', 1)`, `SPLIT_PART('a', NULL, 1)`, `SPLIT_PART('a', ',', NULL)`,
+	} {
+		if got := queryScalar(t, db, expr); got != nil {
+			t.Errorf("%s = %#v, want NULL", expr, got)
+		}
+	}
+	if got := queryScalar(t, db, `CONCAT('a', NULL, 'b')`); got != "ab" {
+		t.Errorf("CONCAT with NULL = %#v, want \"ab\"", got)
+	}
+
+	for _, expr := range []string{
+		`SUBSTR('hello', 2, -1)`,
+		`SPLIT_PART('a,b', ',', 0)`,
+		`LPAD('a', 'x', '-')`,
+	} {
+		if _, err := Execute(context.Background(), db, "default", mustParse(`SELECT `+expr)); err == nil {
+			t.Errorf("%s: expected error", expr)
+		}
+	}
+	for _, q := range []string{
+		`SELECT TRIM(LEADING 'x') AS r`,
+		`SELECT TRIM(BOTH FROM) AS r`,
+	} {
+		if _, err := NewParser(q).ParseStatement(); err == nil {
+			t.Errorf("%s: expected parse error", q)
+		}
+	}
+}
+
+func TestStringSimilarityFunctions(t *testing.T) {
+	db := storage.NewDB()
+	cases := []struct {
+		expr string
+		want any
+	}{
+		{`SOUNDEX('Robert')`, "R163"},
+		{`SOUNDEX('Rupert')`, "R163"},
+		{`SOUNDEX('Rubin')`, "R150"},
+		{`SOUNDEX('Ashcraft')`, "A261"},
+		{`SOUNDEX('Tymczak')`, "T522"},
+		{`SOUNDEX('Pfister')`, "P236"},
+		{`SOUNDEX('lee')`, "L000"},
+		{`SOUNDEX(' o''Hara')`, "O600"},
+		{`SOUNDEX('')`, ""},
+		{`SOUNDEX('123')`, ""},
+		{`SOUNDEX(NULL)`, nil},
+		{`LEVENSHTEIN('kitten', 'sitting')`, 3},
+		{`LEVENSHTEIN('flaw', 'lawn')`, 2},
+		{`LEVENSHTEIN('', 'abc')`, 3},
+		{`LEVENSHTEIN('', '')`, 0},
+		{`LEVENSHTEIN('héllo', 'hello')`, 1},
+		{`LEVENSHTEIN(NULL, 'a')`, nil},
+		{`DIFFERENCE('Smith', 'Smythe')`, 4},
+		{`DIFFERENCE('Robert', 'Rubin')`, 2},
+		{`DIFFERENCE('Green', 'Brown')`, 3},
+		{`DIFFERENCE('', 'abc')`, 0},
+		{`DIFFERENCE('a', NULL)`, nil},
+	}
+	for _, c := range cases {
+		if got := queryScalar(t, db, c.expr); got != c.want {
+			t.Errorf("%s = %#v, want %#v", c.expr, got, c.want)
+		}
+	}
+
+	execSQL(t, db, `CREATE TABLE users (name TEXT)`)
+	execSQL(t, db, `INSERT INTO users VALUES ('Smith'), ('Smyth'), ('Schmidt'), ('Jones'), (NULL)`)
+	rs := execSQL(t, db, `SELECT name FROM users WHERE SOUNDEX(name) = SOUNDEX('Smythe') ORDER BY name`)
+	// Schmidt codes to S530 as well: H does not separate S from C.
+	if len(rs.Rows) != 3 || rs.Rows[0]["name"] != "Schmidt" || rs.Rows[2]["name"] != "Smyth" {
+		t.Fatalf("SOUNDEX filter = %v", rs.Rows)
+	}
+	rs = execSQL(t, db, `SELECT name FROM users WHERE LEVENSHTEIN(name, 'Smith') <= 1 ORDER BY name`)
+	if len(rs.Rows) != 2 {
+		t.Fatalf("LEVENSHTEIN filter = %v", rs.Rows)
+	}
+}
@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+func setupSubqueryTables(t *testing.T) *storage.DB {
+	t.Helper()
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE t (id INT, name TEXT, grp INT)`)
+	execSQL(t, db, `CREATE TABLE t2 (id INT, grp INT)`)
+	execSQL(t, db, `INSERT INTO t VALUES (1, 'a', 1), (2, 'b', 1), (3, 'c', 2)`)
+	execSQL(t, db, `INSERT INTO t2 VALUES (2, 1), (3, 1), (1, 2)`)
+	return db
+}
+
+func TestScalarSubqueries(t *testing.T) {
+	db := setupSubqueryTables(t)
+
+	rs := execSQL(t, db, `SELECT (SELECT COUNT(*) FROM t2) AS n`)
+	expectInt(t, rs.Rows[0]["n"], 3, "count subquery")
+
+	rs = execSQL(t, db, `SELECT id FROM t WHERE t.id = (SELECT MAX(id) FROM t2)`)
+	if len(rs.Rows) != 1 {
+		t.Fatalf("WHERE id = (SELECT MAX(id) ...): %v", rs.Rows)
+	}
+	expectInt(t, rs.Rows[0]["id"], 3, "max subquery")
+
+	// Correlated: the subquery sees the outer row's grp.
+	rs = execSQL(t, db, `SELECT id, (SELECT MAX(t2.id) FROM t2 WHERE t2.grp = t.grp) AS m FROM t ORDER BY id`)
+	for i, want := range []int{3, 3, 1} {
+		expectInt(t, rs.Rows[i]["m"], want, "correlated max")
+	}
+	rs = execSQL(t, db, `SELECT id FROM t WHERE grp = (SELECT t2.grp FROM t2 WHERE t2.id = t.id)`)
+	if got := idSet(rs.Rows); len(got) != 1 || !got[2] {
+		t.Errorf("correlated WHERE subquery: %v", rs.Rows)
+	}
+
+	rs = execSQL(t, db, `SELECT (SELECT id FROM t2 WHERE id > 100) AS z`)
+	if rs.Rows[0]["z"] != nil {
+		t.Errorf("empty subquery = %v, want NULL", rs.Rows[0]["z"])
+	}
+
+	for q, want := range map[string]string{
+		`SELECT id FROM t WHERE id = (SELECT name FROM t WHERE id = 1)`: "incomparable",
+		`SELECT (SELECT id, grp FROM t2 WHERE id = 1) AS z`:             "exactly one column",
+		`SELECT (SELECT id FROM t2) AS z`:                               "returned 3 rows",
+	} {
+		_, err := Execute(context.Background(), db, "default", mustParse(q))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: error %v, want %q", q, err, want)
+		}
+	}
+}
+
+func TestExistsPredicates(t *testing.T) {
+	db := setupSubqueryTables(t)
+
+	cases := []struct {
+		q    string
+		want map[int]bool
+	}{
+		{`SELECT id FROM t WHERE EXISTS (SELECT 1 FROM t2 WHERE t2.grp = 2)`, map[int]bool{1: true, 2: true, 3: true}},
+		{`SELECT id FROM t WHERE EXISTS (SELECT 1 FROM t2 WHERE t2.id > 100)`, map[int]bool{}},
+		{`SELECT id FROM t WHERE NOT EXISTS (SELECT 1 FROM t2 WHERE t2.id > 100)`, map[int]bool{1: true, 2: true, 3: true}},
+		// Correlated through the outer alias, with a non-equality so the
+		// semi-join rewrite does not apply.
+		{`SELECT id FROM t o WHERE EXISTS (SELECT 1 FROM t2 WHERE t2.id > o.id AND t2.grp = o.grp)`, map[int]bool{1: true, 2: true}},
+		{`SELECT id FROM t o WHERE NOT EXISTS (SELECT 1 FROM t2 WHERE t2.id > o.id AND t2.grp = o.grp)`, map[int]bool{3: true}},
+		// A NULL comparison in the subquery's WHERE never qualifies a row.
+		{`SELECT id FROM t WHERE EXISTS (SELECT 1 FROM t2 WHERE t2.grp = NULL)`, map[int]bool{}},
+		{`SELECT id FROM t WHERE NOT EXISTS (SELECT 1 FROM t2 WHERE t2.grp <> NULL)`, map[int]bool{1: true, 2: true, 3: true}},
+		// Aggregates always produce a row.
+		{`SELECT id FROM t WHERE EXISTS (SELECT COUNT(*) FROM t2 WHERE t2.id > 100)`, map[int]bool{1: true, 2: true, 3: true}},
+	}
+	for _, c := range cases {
+		rs := execSQL(t, db, c.q)
+		got := idSet(rs.Rows)
+		if len(got) != len(c.want) {
+			t.Errorf("%s = %v, want %v", c.q, got, c.want)
+			continue
+		}
+		for id := range c.want {
+			if !got[id] {
+				t.Errorf("%s = %v, want %v", c.q, got, c.want)
+				break
+			}
+		}
+	}
+
+	// EXISTS stops at the first qualifying row: the row with id = 1, which
+	// would divide by zero, is never evaluated.
+	if !queryBool(t, db, `EXISTS (SELECT 1 FROM t2 WHERE 10 / (id - 1) > 0)`) {
+		t.Error("expected EXISTS to find the first row")
+	}
+}
@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+// OpenTelemetry tracing. A statement run with a tracer gets a
+// "tinysql.Execute" span, a child of whatever span ctx already carries, and
+// one child span per operation of its outermost SELECT — the same steps
+// ExplainAnalyze reports — named tinysql.scan, tinysql.join, tinysql.filter,
+// tinysql.aggregate, tinysql.sort and so on. Spans are recorded when a step
+// completes, backdated to when it started.
+//
+// Only the OpenTelemetry API is linked in. Without an SDK registered by the
+// application the default tracer is a no-op.
+
+// ExecConfig holds the settings ExecuteWith applies to one statement.
+type ExecConfig struct {
+	// Tracer receives the statement's spans.
+	Tracer trace.Tracer
+	// Limits bounds the statement's resources (see ExecuteWithOptions).
+	Limits QueryOptions
+}
+
+// ExecOption configures ExecuteWith.
+type ExecOption func(*ExecConfig)
+
+// WithTracer traces the statement with t. A nil t keeps the no-op default.
+func WithTracer(t trace.Tracer) ExecOption {
+	return func(c *ExecConfig) {
+		if t != nil {
+			c.Tracer = t
+		}
+	}
+}
+
+// WithQueryOptions applies resource limits to the statement.
+func WithQueryOptions(opts QueryOptions) ExecOption {
+	return func(c *ExecConfig) { c.Limits = opts }
+}
+
+// ExecuteWith is Execute configured by functional options.
+func ExecuteWith(ctx context.Context, db *storage.DB, tenant string, stmt Statement, opts ...ExecOption) (*ResultSet, error) {
+	cfg := ExecConfig{Tracer: noop.NewTracerProvider().Tracer("")}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	ctx = context.WithValue(ctx, tracerContextKey{}, cfg.Tracer)
+	return ExecuteWithOptions(ctx, db, tenant, stmt, cfg.Limits)
+}
+
+type tracerContextKey struct{}
+
+func tracerFromContext(ctx context.Context) trace.Tracer {
+	if ctx == nil {
+		return nil
+	}
+	t, _ := ctx.Value(tracerContextKey{}).(trace.Tracer)
+	return t
+}
+
+// startStatementSpan opens the statement span when ctx carries a tracer.
+// The returned context parents the operation spans.
+func startStatementSpan(ctx context.Context, tenant string, stmt Statement) (context.Context, trace.Span) {
+	tracer := tracerFromContext(ctx)
+	if tracer == nil {
+		return ctx, nil
+	}
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system", "tinysql"),
+		attribute.String("db.operation", statementName(stmt)),
+		attribute.String("tinysql.tenant", tenant),
+	}
+	if text, ok := auditTextFromContext(ctx); ok {
+		attrs = append(attrs, attribute.String("db.statement", text))
+	}
+	return tracer.Start(ctx, "tinysql.Execute", trace.WithAttributes(attrs...))
+}
+
+// endStatementSpan closes span with the statement's outcome.
+func endStatementSpan(span trace.Span, rs *ResultSet, err error) {
+	if span == nil {
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else if rs != nil {
+		span.SetAttributes(attribute.Int("tinysql.rows", len(rs.Rows)))
+	}
+	span.End()
+}
+
+// operationSpanName maps a profile operation to its span name: every join
+// method becomes tinysql.join.
+func operationSpanName(op string) string {
+	if strings.HasSuffix(op, " JOIN") {
+		return "tinysql.join"
+	}
+	return "tinysql." + strings.ReplaceAll(strings.ToLower(op), " ", "_")
+}
+
+// traceStep records one completed operation as a child of the statement span.
+func (p *queryProfile) traceStep(step AnalyzedStep, started time.Time) {
+	attrs := []attribute.KeyValue{
+		attribute.String("tinysql.operation", step.Operation),
+		attribute.Int("tinysql.rows", step.ActualRows),
+		attribute.Int("tinysql.input_rows", step.EstimatedRows),
+		attribute.Float64("tinysql.duration_ms", step.ElapsedMs),
+	}
+	if step.Object != "" && step.Object != "-" {
+		attrs = append(attrs, attribute.String("db.sql.table", step.Object))
+	}
+	if step.Details != "" {
+		attrs = append(attrs, attribute.String("tinysql.details", step.Details))
+	}
+	_, span := p.tracer.Start(p.spanCtx, operationSpanName(step.Operation),
+		trace.WithTimestamp(started), trace.WithAttributes(attrs...))
+	span.End(trace.WithTimestamp(started.Add(time.Duration(step.ElapsedMs * float64(time.Millisecond)))))
+}
@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func spanAttr(span sdktrace.ReadOnlySpan, key string) (attribute.Value, bool) {
+	for _, kv := range span.Attributes() {
+		if string(kv.Key) == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestExecuteWithTracerSpans(t *testing.T) {
+	db := explainAnalyzeTestDB(t)
+	rec := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)).Tracer("test")
+
+	ctx, request := tracer.Start(context.Background(), "request")
+	rs, err := ExecuteWith(ctx, db, "default", mustParse(`SELECT u.region, COUNT(*) AS n FROM users u
+		JOIN orders o ON o.user_id = u.id WHERE u.id > 10 OR o.total > 900 GROUP BY u.region ORDER BY u.region`), WithTracer(tracer))
+	request.End()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byName := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range rec.Ended() {
+		byName[s.Name()] = s
+	}
+	stmt, ok := byName["tinysql.Execute"]
+	if !ok {
+		t.Fatalf("no statement span in %v", byName)
+	}
+	if stmt.Parent().SpanID() != request.SpanContext().SpanID() {
+		t.Errorf("statement span is not a child of the request span")
+	}
+	if v, _ := spanAttr(stmt, "db.operation"); v.AsString() != "SELECT" {
+		t.Errorf("db.operation = %q", v.AsString())
+	}
+	if v, _ := spanAttr(stmt, "tinysql.rows"); v.AsInt64() != int64(len(rs.Rows)) {
+		t.Errorf("statement rows = %d, want %d", v.AsInt64(), len(rs.Rows))
+	}
+
+	for _, name := range []string{"tinysql.scan", "tinysql.join", "tinysql.filter", "tinysql.aggregate", "tinysql.sort"} {
+		span, ok := byName[name]
+		if !ok {
+			t.Errorf("missing %s span", name)
+			continue
+		}
+		if span.Parent().SpanID() != stmt.SpanContext().SpanID() {
+			t.Errorf("%s is not a child of the statement span", name)
+		}
+		if span.StartTime().Before(stmt.StartTime()) || span.EndTime().After(stmt.EndTime()) {
+			t.Errorf("%s [%v, %v] lies outside the statement span", name, span.StartTime(), span.EndTime())
+		}
+		if _, ok := spanAttr(span, "tinysql.duration_ms"); !ok {
+			t.Errorf("%s has no duration attribute", name)
+		}
+	}
+	if v, _ := spanAttr(byName["tinysql.scan"], "db.sql.table"); v.AsString() != "users" {
+		t.Errorf("scan table = %q", v.AsString())
+	}
+	if v, _ := spanAttr(byName["tinysql.scan"], "tinysql.rows"); v.AsInt64() != 40 {
+		t.Errorf("scan rows = %d, want 40", v.AsInt64())
+	}
+	if v, _ := spanAttr(byName["tinysql.join"], "db.sql.table"); v.AsString() != "orders" {
+		t.Errorf("join table = %q", v.AsString())
+	}
+	if v, _ := spanAttr(byName["tinysql.aggregate"], "tinysql.rows"); v.AsInt64() != 4 {
+		t.Errorf("aggregate rows = %d, want 4", v.AsInt64())
+	}
+}
+
+func TestExecuteWithTracerRecordsErrors(t *testing.T) {
+	db := explainAnalyzeTestDB(t)
+	rec := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)).Tracer("test")
+	if _, err := ExecuteWith(context.Background(), db, "default", mustParse(`SELECT * FROM missing`), WithTracer(tracer)); err == nil {
+		t.Fatal("expected an error")
+	}
+	spans := rec.Ended()
+	if len(spans) != 1 || spans[0].Status().Code != codes.Error {
+		t.Fatalf("spans = %v", spans)
+	}
+
+	// Without a tracer the statement runs untraced.
+	if _, err := ExecuteWith(context.Background(), db, "default", mustParse(`SELECT id FROM users`)); err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.Ended()) != 1 {
+		t.Fatalf("untraced statement produced spans")
+	}
+}
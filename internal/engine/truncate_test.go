@@ -0,0 +1,44 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+func TestTruncateTable(t *testing.T) {
+	db := storage.NewDB()
+	ctx := context.Background()
+	execSQL(t, db, `CREATE TABLE logs (id INT PRIMARY KEY, msg TEXT)`)
+	execSQL(t, db, `CREATE INDEX idx_logs_msg ON logs(msg)`)
+	execSQL(t, db, `INSERT INTO logs VALUES (1, 'a'), (2, 'b'), (3, 'c')`)
+	// A trigger would fire for DELETE but never for TRUNCATE.
+	execSQL(t, db, `CREATE TABLE audit (n INT)`)
+	execSQL(t, db, `CREATE TRIGGER logs_del AFTER DELETE ON logs BEGIN INSERT INTO audit VALUES (1); END`)
+
+	execSQL(t, db, `TRUNCATE TABLE logs`)
+	expectInt(t, execSQL(t, db, `SELECT COUNT(*) AS n FROM logs`).Rows[0]["n"], 0, "rows after TRUNCATE")
+	expectInt(t, execSQL(t, db, `SELECT COUNT(*) AS n FROM audit`).Rows[0]["n"], 0, "trigger rows")
+	execSQL(t, db, `TRUNCATE logs`)
+
+	// Keys and index entries are gone too, so the same values insert again.
+	execSQL(t, db, `INSERT INTO logs VALUES (1, 'a')`)
+	rs := execSQL(t, db, `SELECT id FROM logs WHERE msg = 'a'`)
+	if len(rs.Rows) != 1 {
+		t.Fatalf("lookup after re-insert = %v", rs.Rows)
+	}
+
+	execSQL(t, db, `CREATE TABLE entries (log_id INT REFERENCES logs(id))`)
+	for q, want := range map[string]string{
+		`TRUNCATE TABLE logs`:    "FOREIGN KEY",
+		`TRUNCATE TABLE missing`: "missing",
+	} {
+		_, err := Execute(ctx, db, "default", mustParse(q))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: error %v, want %q", q, err, want)
+		}
+	}
+	expectInt(t, execSQL(t, db, `SELECT COUNT(*) AS n FROM logs`).Rows[0]["n"], 1, "rows after refused TRUNCATE")
+}
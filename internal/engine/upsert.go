@@ -0,0 +1,208 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+// upsertPlan is the per-statement state of an INSERT ... ON CONFLICT clause,
+// resolved once before the row loop.
+type upsertPlan struct {
+	clause      *OnConflict
+	keys        [][]int // conflict targets; a row conflicts on any of them
+	sets        map[int]Expr
+	tablePrefix string
+	before      []*storage.CatalogTrigger
+	after       []*storage.CatalogTrigger
+	updated     int
+}
+
+func newUpsertPlan(env ExecEnv, s *Insert, t *storage.Table) (*upsertPlan, error) {
+	if s.OnConflict == nil {
+		return nil, nil
+	}
+	up := &upsertPlan{clause: s.OnConflict, tablePrefix: strings.ToLower(s.Table) + "."}
+	if len(s.OnConflict.Cols) > 0 {
+		cols, err := upsertColumns(t, s.OnConflict.Cols)
+		if err != nil {
+			return nil, err
+		}
+		up.keys = [][]int{cols}
+	} else {
+		keys, err := uniqueKeys(t)
+		if err != nil {
+			return nil, err
+		}
+		up.keys = keys
+	}
+	if !s.OnConflict.DoNothing {
+		up.sets = make(map[int]Expr, len(s.OnConflict.Sets))
+		for name, ex := range s.OnConflict.Sets {
+			i, err := t.ColIndex(name)
+			if err != nil {
+				return nil, fmt.Errorf("ON CONFLICT: %w", err)
+			}
+			up.sets[i] = ex
+		}
+		up.before, up.after = env.db.Catalog().GetTriggersForEvent(s.Table, storage.TriggerUpdate)
+	}
+	return up, nil
+}
+
+func upsertColumns(t *storage.Table, names []string) ([]int, error) {
+	cols := make([]int, 0, len(names))
+	for _, name := range names {
+		i, err := t.ColIndex(name)
+		if err != nil {
+			return nil, fmt.Errorf("ON CONFLICT: %w", err)
+		}
+		cols = append(cols, i)
+	}
+	return cols, nil
+}
+
+// uniqueKeys lists every primary key, UNIQUE constraint and unique index of
+// t, the targets of a target-less ON CONFLICT DO NOTHING.
+func uniqueKeys(t *storage.Table) ([][]int, error) {
+	var keys [][]int
+	for i, c := range t.Cols {
+		if c.Constraint == storage.PrimaryKey || c.Constraint == storage.Unique {
+			keys = append(keys, []int{i})
+		}
+	}
+	for _, u := range t.Uniques {
+		cols, err := upsertColumns(t, u.Columns)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, cols)
+	}
+	for _, idx := range t.Indexes {
+		if !idx.Unique {
+			continue
+		}
+		cols, err := upsertColumns(t, idx.Columns)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, cols)
+	}
+	return keys, nil
+}
+
+// conflictRow returns the index of the first existing row that conflicts
+// with candidate on one of the plan's keys, or -1.
+func (up *upsertPlan) conflictRow(t *storage.Table, candidate []any) int {
+	for _, cols := range up.keys {
+		if ri := conflictRowOn(t, cols, candidate); ri >= 0 {
+			return ri
+		}
+	}
+	return -1
+}
+
+// conflictRowOn returns the index of the existing row whose cols equal
+// those of candidate, or -1. A NULL in any conflict column never conflicts,
+// matching how UNIQUE constraints treat NULL.
+func conflictRowOn(t *storage.Table, cols []int, candidate []any) int {
+	for _, c := range cols {
+		if candidate[c] == nil {
+			return -1
+		}
+	}
+rows:
+	for ri, r := range t.Rows {
+		for _, c := range cols {
+			if r[c] == nil {
+				continue rows
+			}
+			if cmp, err := compare(r[c], candidate[c]); err != nil || cmp != 0 {
+				continue rows
+			}
+		}
+		return ri
+	}
+	return -1
+}
+
+// resolve checks candidate against the existing rows. handled reports that
+// a conflicting row was found, in which case the caller must not append
+// candidate; newRow is the updated row, or nil for DO NOTHING. A nil plan
+// never handles a row.
+func (up *upsertPlan) resolve(env ExecEnv, t *storage.Table, wal *walAuto, candidate []any) (handled bool, newRow Row, err error) {
+	if up == nil {
+		return false, nil, nil
+	}
+	ri := up.conflictRow(t, candidate)
+	if ri < 0 {
+		return false, nil, nil
+	}
+	newRow, err = up.apply(env, t, wal, ri, candidate)
+	return true, newRow, err
+}
+
+// markDirty records the rows an INSERT changed: appended rows only, unless
+// an upsert rewrote existing rows in place.
+func (up *upsertPlan) markDirty(t *storage.Table, firstAppended int) {
+	if up != nil && up.updated > 0 {
+		t.MarkDirtyFrom(-1)
+		return
+	}
+	t.MarkDirtyFrom(firstAppended)
+}
+
+// apply resolves a conflict between candidate and t.Rows[ri]. For DO NOTHING
+// it returns a nil row. Otherwise the SET expressions are evaluated against
+// the existing row, with EXCLUDED.col bound to the candidate, and the result
+// replaces the row in place exactly as UPDATE would.
+func (up *upsertPlan) apply(env ExecEnv, t *storage.Table, wal *walAuto, ri int, candidate []any) (Row, error) {
+	if up.clause.DoNothing {
+		return nil, nil
+	}
+	oldRow := buildTableRow(t.Cols, up.tablePrefix, t.Rows[ri])
+	if err := fireTriggerList(env, up.before, oldRow, oldRow); err != nil {
+		return nil, err
+	}
+	evalRow := make(Row, len(oldRow)+len(t.Cols))
+	for k, v := range oldRow {
+		evalRow[k] = v
+	}
+	for i, c := range t.Cols {
+		evalRow["excluded."+strings.ToLower(c.Name)] = candidate[i]
+	}
+	nextRow := append([]any(nil), t.Rows[ri]...)
+	for i, ex := range up.sets {
+		v, err := evalExpr(env, ex, evalRow)
+		if err != nil {
+			return nil, err
+		}
+		cv, err := coerceColumnValue(v, t.Cols[i])
+		if err != nil {
+			return nil, fmt.Errorf("column %q: %w", t.Cols[i].Name, err)
+		}
+		nextRow[i] = cv
+	}
+	if err := validateRowConstraints(env, t, nextRow, ri); err != nil {
+		return nil, err
+	}
+	if err := t.CheckSecondaryIndexConstraints(nextRow, ri); err != nil {
+		return nil, err
+	}
+	patchConstraintIndexRow(t, ri, t.Rows[ri], nextRow)
+	before := t.Rows[ri]
+	t.Rows[ri] = nextRow
+	if err := t.UpdateSecondaryIndexRow(ri, before, nextRow); err != nil {
+		return nil, err
+	}
+	if err := wal.logUpdate(env, ri, before, nextRow, t.Cols); err != nil {
+		return nil, err
+	}
+	newRow := buildTableRow(t.Cols, up.tablePrefix, nextRow)
+	if err := fireTriggerList(env, up.after, newRow, oldRow); err != nil {
+		return nil, err
+	}
+	up.updated++
+	return newRow, nil
+}
@@ -0,0 +1,144 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+// upsertRows renders every row of q as "col=value ..." in table order.
+func upsertRows(t *testing.T, db *storage.DB, q string) []string {
+	t.Helper()
+	rs := execSQL(t, db, q)
+	out := make([]string, 0, len(rs.Rows))
+	for _, r := range rs.Rows {
+		line := ""
+		for i, c := range rs.Cols {
+			if i > 0 {
+				line += " "
+			}
+			line += fmt.Sprintf("%s=%v", c, r[c])
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+func expectUpsertRows(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("rows = %q, want %q", got, want)
+	}
+}
+
+func TestUpsertUpdatesOrInserts(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE kv (k TEXT PRIMARY KEY, v INT, hits INT)`)
+	execSQL(t, db, `INSERT INTO kv VALUES ('a', 1, 0)`)
+
+	execSQL(t, db, `INSERT INTO kv VALUES ('a', 5, 0) ON CONFLICT (k) DO UPDATE SET v = EXCLUDED.v, hits = hits + 1`)
+	execSQL(t, db, `INSERT INTO kv VALUES ('b', 2, 0) ON CONFLICT (k) DO UPDATE SET v = 99`)
+	expectUpsertRows(t, upsertRows(t, db, `SELECT k, v, hits FROM kv`),
+		"k=a v=5 hits=1", "k=b v=2 hits=0")
+
+	// EXCLUDED and the table-qualified existing row can be mixed; later
+	// VALUES rows see the effect of earlier ones.
+	rs := execSQL(t, db, `INSERT INTO kv VALUES ('a', 10, 0), ('a', 100, 0)
+		ON CONFLICT (k) DO UPDATE SET v = kv.v + excluded.v RETURNING k, v`)
+	if len(rs.Rows) != 2 || rs.Rows[1]["v"] != 115 {
+		t.Fatalf("RETURNING rows = %v", rs.Rows)
+	}
+
+	execSQL(t, db, `INSERT INTO kv VALUES ('b', 7, 7) ON CONFLICT (k) DO NOTHING`)
+	expectUpsertRows(t, upsertRows(t, db, `SELECT k, v, hits FROM kv`),
+		"k=a v=115 hits=1", "k=b v=2 hits=0")
+}
+
+func TestUpsertMultiColumnAndNullKeys(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE stock (shop TEXT, sku TEXT, qty INT)`)
+	execSQL(t, db, `INSERT INTO stock VALUES ('x', 'p1', 1), ('y', 'p1', 1)`)
+
+	const upsert = `INSERT INTO stock VALUES (%s) ON CONFLICT (shop, sku) DO UPDATE SET qty = qty + EXCLUDED.qty`
+	execSQL(t, db, fmt.Sprintf(upsert, `'x', 'p1', 5`))
+	execSQL(t, db, fmt.Sprintf(upsert, `'x', 'p2', 3`))
+	// A NULL key column never conflicts, so both rows are inserted.
+	execSQL(t, db, fmt.Sprintf(upsert, `NULL, 'p1', 4`))
+	execSQL(t, db, fmt.Sprintf(upsert, `NULL, 'p1', 4`))
+	expectUpsertRows(t, upsertRows(t, db, `SELECT shop, sku, qty FROM stock`),
+		"shop=x sku=p1 qty=6", "shop=y sku=p1 qty=1", "shop=x sku=p2 qty=3",
+		"shop=<nil> sku=p1 qty=4", "shop=<nil> sku=p1 qty=4")
+
+	// Specific-column inserts take the same path.
+	execSQL(t, db, `INSERT INTO stock (sku, shop, qty) VALUES ('p1', 'y', 9) ON CONFLICT (shop, sku) DO UPDATE SET qty = EXCLUDED.qty`)
+	rs := execSQL(t, db, `SELECT qty FROM stock WHERE shop = 'y'`)
+	expectInt(t, rs.Rows[0]["qty"], 9, "specific-column upsert")
+}
+
+func TestUpsertDoNothingWithoutTarget(t *testing.T) {
+	oc := mustParse(`INSERT INTO u VALUES (1) ON CONFLICT DO NOTHING`).(*Insert).OnConflict
+	if oc == nil || !oc.DoNothing || len(oc.Cols) != 0 {
+		t.Fatalf("OnConflict = %+v, want target-less DO NOTHING", oc)
+	}
+	if _, err := NewParser(`INSERT INTO u VALUES (1) ON CONFLICT DO UPDATE SET v = 1`).ParseStatement(); err == nil {
+		t.Fatal("target-less DO UPDATE parsed")
+	}
+
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE users (id INT PRIMARY KEY, email TEXT UNIQUE, team TEXT, seat INT, UNIQUE (team, seat))`)
+	execSQL(t, db, `INSERT INTO users VALUES (1, 'a@x', 'red', 1)`)
+	// Conflicts on the primary key, the UNIQUE column and the composite
+	// constraint are all skipped; NULLs never conflict.
+	execSQL(t, db, `INSERT INTO users VALUES
+		(1, 'b@x', 'blue', 1),
+		(2, 'a@x', 'blue', 2),
+		(3, 'c@x', 'red', 1),
+		(4, 'd@x', 'red', 2),
+		(5, NULL, NULL, 1),
+		(6, NULL, NULL, 1)
+		ON CONFLICT DO NOTHING`)
+	expectUpsertRows(t, upsertRows(t, db, `SELECT id, email FROM users ORDER BY id`),
+		"id=1 email=a@x", "id=4 email=d@x", "id=5 email=<nil>", "id=6 email=<nil>")
+
+	execSQL(t, db, `CREATE TABLE tags (name TEXT)`)
+	execSQL(t, db, `CREATE UNIQUE INDEX tags_name ON tags (name)`)
+	execSQL(t, db, `INSERT INTO tags VALUES ('go'), ('sql'), ('go') ON CONFLICT DO NOTHING`)
+	expectUpsertRows(t, upsertRows(t, db, `SELECT name FROM tags ORDER BY name`), "name=go", "name=sql")
+
+	// A table without unique keys never conflicts.
+	execSQL(t, db, `CREATE TABLE log (msg TEXT)`)
+	execSQL(t, db, `INSERT INTO log VALUES ('x'), ('x') ON CONFLICT DO NOTHING`)
+	expectUpsertRows(t, upsertRows(t, db, `SELECT msg FROM log`), "msg=x", "msg=x")
+}
+
+func TestUpsertErrorsRollBack(t *testing.T) {
+	db := storage.NewDB()
+	ctx := context.Background()
+	execSQL(t, db, `CREATE TABLE u (id INT PRIMARY KEY, v INT NOT NULL)`)
+	execSQL(t, db, `INSERT INTO u VALUES (1, 1), (2, 2)`)
+
+	for _, q := range []string{
+		`INSERT INTO u VALUES (1, 5) ON CONFLICT (missing) DO NOTHING`,
+		`INSERT INTO u VALUES (1, 5) ON CONFLICT (id) DO UPDATE SET missing = 1`,
+		// The update violates the primary key; the whole statement fails.
+		`INSERT INTO u VALUES (3, 3), (1, 5) ON CONFLICT (id) DO UPDATE SET id = 2`,
+		`INSERT INTO u VALUES (1, 5) ON CONFLICT (id) DO UPDATE SET v = NULL`,
+	} {
+		if _, err := Execute(ctx, db, "default", mustParse(q)); err == nil {
+			t.Errorf("%s: expected error", q)
+		}
+	}
+	expectUpsertRows(t, upsertRows(t, db, `SELECT id, v FROM u`), "id=1 v=1", "id=2 v=2")
+
+	for _, q := range []string{
+		`INSERT INTO u VALUES (1, 1) ON CONFLICT id DO NOTHING`,
+		`INSERT INTO u VALUES (1, 1) ON CONFLICT (id) DO`,
+		`INSERT INTO u VALUES (1, 1) ON DUPLICATE (id) DO NOTHING`,
+	} {
+		if _, err := NewParser(q).ParseStatement(); err == nil {
+			t.Errorf("%s: expected parse error", q)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+func TestViews(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE orders (id INT, customer INT, total INT)`)
+	execSQL(t, db, `CREATE TABLE customers (id INT, name TEXT)`)
+	execSQL(t, db, `INSERT INTO orders VALUES (1, 1, 50), (2, 1, 150), (3, 2, 300)`)
+	execSQL(t, db, `INSERT INTO customers VALUES (1, 'Ada'), (2, 'Grace')`)
+	execSQL(t, db, `CREATE VIEW big_orders AS SELECT id, customer, total FROM orders WHERE total > 100`)
+
+	if got := idSet(execSQL(t, db, `SELECT * FROM big_orders`).Rows); len(got) != 2 || !got[2] || !got[3] {
+		t.Errorf("SELECT from view = %v", got)
+	}
+	if got := idSet(execSQL(t, db, `SELECT id FROM big_orders WHERE customer = 2`).Rows); len(got) != 1 || !got[3] {
+		t.Errorf("WHERE over view = %v", got)
+	}
+	rs := execSQL(t, db, `SELECT c.name AS name, b.total AS total FROM big_orders b JOIN customers c ON c.id = b.customer ORDER BY b.total`)
+	if len(rs.Rows) != 2 || rs.Rows[0]["name"] != "Ada" || rs.Rows[1]["name"] != "Grace" {
+		t.Errorf("view in JOIN = %v", rs.Rows)
+	}
+
+	// The view reflects later changes to its base table.
+	execSQL(t, db, `INSERT INTO orders VALUES (4, 2, 500)`)
+	if got := idSet(execSQL(t, db, `SELECT id FROM big_orders`).Rows); len(got) != 3 {
+		t.Errorf("view after INSERT = %v", got)
+	}
+
+	ctx := context.Background()
+	for q, want := range map[string]string{
+		`CREATE VIEW orders AS SELECT 1 AS x`:                "table with that name",
+		`CREATE TABLE big_orders (id INT)`:                   "view with that name",
+		`CREATE VIEW big_orders AS SELECT id FROM customers`: "already exists",
+	} {
+		_, err := Execute(ctx, db, "default", mustParse(q))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: error %v, want %q", q, err, want)
+		}
+	}
+
+	execSQL(t, db, `DROP VIEW big_orders`)
+	if _, err := Execute(ctx, db, "default", mustParse(`SELECT * FROM big_orders`)); err == nil {
+		t.Error("expected an error selecting from a dropped view")
+	}
+	execSQL(t, db, `CREATE TABLE big_orders (id INT)`)
+}
@@ -641,7 +641,7 @@ func isAggregateName(name string) bool {
 	case "COUNT", "SUM", "AVG", "MIN", "MAX",
 		"GROUP_CONCAT", "STRING_AGG",
 		"FIRST", "LAST",
-		"MIN_BY", "MAX_BY", "ARG_MIN", "ARG_MAX":
+		"MIN_BY", "MAX_BY", "ARG_MIN", "ARG_MAX", "JSON_ARRAYAGG":
 		return true
 	}
 	return false
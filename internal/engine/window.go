@@ -0,0 +1,269 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// computeWindowValues evaluates every window function call in projs once
+// for the whole row set. Each call's rows are partitioned by PARTITION BY,
+// every partition is sorted by the call's ORDER BY, and the function is then
+// evaluated by position. The result is indexed like rows, so the projection
+// loop looks values up by row position instead of re-partitioning per row
+// (which also kept identical rows from being told apart).
+func computeWindowValues(env ExecEnv, rows []Row, projs []SelectItem) (map[*FuncCall][]any, error) {
+	var calls []*FuncCall
+	for _, it := range projs {
+		calls = collectWindowCalls(it.Expr, calls)
+	}
+	if len(calls) == 0 {
+		return nil, nil
+	}
+	out := make(map[*FuncCall][]any, len(calls))
+	for _, call := range calls {
+		if _, done := out[call]; done {
+			continue
+		}
+		vals, err := computeWindowCall(env, rows, call)
+		if err != nil {
+			return nil, err
+		}
+		out[call] = vals
+	}
+	return out, nil
+}
+
+func computeWindowCall(env ExecEnv, rows []Row, ex *FuncCall) ([]any, error) {
+	parts, err := windowPartitions(env, rows, ex.Over.PartitionBy)
+	if err != nil {
+		return nil, err
+	}
+	orderBy := ex.Over.OrderBy
+	lcOrdCols := lowerOrderCols(orderBy)
+	vals := make([]any, len(rows))
+	for _, part := range parts {
+		items := make([]orderedValueRow, len(part))
+		for i, idx := range part {
+			items[i] = buildOrderByValues(rows[idx], lcOrdCols)
+			items[i].idx = idx
+		}
+		if len(orderBy) > 0 {
+			sort.SliceStable(items, func(i, j int) bool {
+				return compareOrderedValueRows(orderBy, items[i], items[j]) < 0
+			})
+		}
+		partitionRows := make([]Row, len(items))
+		for i, it := range items {
+			partitionRows[i] = it.row
+		}
+
+		rank, dense := 0, 0
+		for pos, it := range items {
+			// Peers share RANK and DENSE_RANK; computed incrementally so
+			// large partitions stay linear.
+			if pos == 0 || len(orderBy) == 0 || compareOrderedValueRows(orderBy, items[pos-1], it) != 0 {
+				rank = pos + 1
+				dense++
+			}
+			switch ex.Name {
+			case "ROW_NUMBER":
+				vals[it.idx] = pos + 1
+			case "RANK":
+				vals[it.idx] = rank
+			case "DENSE_RANK":
+				vals[it.idx] = dense
+			default:
+				v, err := evalWindowAt(env, ex, partitionRows, pos, it.row)
+				if err != nil {
+					return nil, err
+				}
+				vals[it.idx] = v
+			}
+		}
+	}
+	return vals, nil
+}
+
+// windowPartitions groups row indices by their PARTITION BY values, keeping
+// partitions and the rows within them in input order. NULL partition keys
+// form a single partition.
+func windowPartitions(env ExecEnv, rows []Row, partitionBy []Expr) ([][]int, error) {
+	if len(partitionBy) == 0 {
+		all := make([]int, len(rows))
+		for i := range all {
+			all[i] = i
+		}
+		return [][]int{all}, nil
+	}
+	var parts [][]int
+	byKey := map[string]int{}
+	buf := make([]byte, 0, 32)
+	for i, r := range rows {
+		buf = buf[:0]
+		for _, e := range partitionBy {
+			v, err := evalExpr(env, e, r)
+			if err != nil {
+				return nil, err
+			}
+			buf = writeSemiJoinKeyPart(buf, v)
+		}
+		p, ok := byKey[string(buf)]
+		if !ok {
+			p = len(parts)
+			byKey[string(buf)] = p
+			parts = append(parts, nil)
+		}
+		parts[p] = append(parts[p], i)
+	}
+	return parts, nil
+}
+
+// collectWindowCalls appends the window function calls in e, walking the
+// same expression kinds as hasWindowFunction.
+func collectWindowCalls(e Expr, out []*FuncCall) []*FuncCall {
+	switch ex := e.(type) {
+	case *FuncCall:
+		if ex.Over != nil {
+			return append(out, ex)
+		}
+		for _, arg := range ex.Args {
+			out = collectWindowCalls(arg, out)
+		}
+	case *Unary:
+		out = collectWindowCalls(ex.Expr, out)
+	case *Binary:
+		out = collectWindowCalls(ex.Left, out)
+		out = collectWindowCalls(ex.Right, out)
+	case *IsNull:
+		out = collectWindowCalls(ex.Expr, out)
+	case *CaseExpr:
+		out = collectWindowCalls(ex.Operand, out)
+		for _, w := range ex.Whens {
+			out = collectWindowCalls(w.When, out)
+			out = collectWindowCalls(w.Then, out)
+		}
+		out = collectWindowCalls(ex.Else, out)
+	}
+	return out
+}
+
+// windowFrameBounds returns the inclusive row range [start, end] of the
+// window frame for the row at currentIdx of a sorted partition. Without a
+// frame clause the frame is the whole partition. ROWS bounds count rows;
+// RANGE bounds compare ORDER BY values, so CURRENT ROW extends to all peers
+// and "n PRECEDING/FOLLOWING" needs a single numeric ORDER BY key. A frame
+// with no rows has start > end.
+func windowFrameBounds(partitionRows []Row, currentIdx int, orderBy []OrderItem, frame *WindowFrame) (int, int, error) {
+	last := len(partitionRows) - 1
+	if frame == nil {
+		return 0, last, nil
+	}
+	bound := func(typ string, val int, isStart bool) (int, error) {
+		switch typ {
+		case "UNBOUNDED_PRECEDING":
+			return 0, nil
+		case "UNBOUNDED_FOLLOWING":
+			return last, nil
+		}
+		if frame.Mode != "RANGE" {
+			switch typ {
+			case "OFFSET_PRECEDING":
+				return currentIdx - val, nil
+			case "OFFSET_FOLLOWING":
+				return currentIdx + val, nil
+			}
+			return currentIdx, nil
+		}
+		if typ == "CURRENT" {
+			return rangePeerBound(partitionRows, currentIdx, orderBy, isStart), nil
+		}
+		delta := float64(val)
+		if typ == "OFFSET_PRECEDING" {
+			delta = -delta
+		}
+		return rangeOffsetBound(partitionRows, currentIdx, orderBy, delta, isStart)
+	}
+	start, err := bound(frame.StartType, frame.StartValue, true)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := bound(frame.EndType, frame.EndValue, false)
+	if err != nil {
+		return 0, 0, err
+	}
+	return max(start, 0), min(end, last), nil
+}
+
+// rangePeerBound returns the first (isStart) or last row that ties with the
+// current row on every ORDER BY key.
+func rangePeerBound(partitionRows []Row, currentIdx int, orderBy []OrderItem, isStart bool) int {
+	cur := partitionRows[currentIdx]
+	i := currentIdx
+	if isStart {
+		for i > 0 && rowsOrderTie(partitionRows[i-1], cur, orderBy) {
+			i--
+		}
+		return i
+	}
+	for i+1 < len(partitionRows) && rowsOrderTie(partitionRows[i+1], cur, orderBy) {
+		i++
+	}
+	return i
+}
+
+// rangeOffsetBound resolves a RANGE "n PRECEDING/FOLLOWING" bound, passed as
+// a signed delta in sort direction. The frame starts at the first row whose
+// key is not before current+delta and ends at the last row whose key is not
+// after it; rows with a NULL key only frame each other.
+func rangeOffsetBound(partitionRows []Row, currentIdx int, orderBy []OrderItem, delta float64, isStart bool) (int, error) {
+	if len(orderBy) != 1 {
+		return 0, fmt.Errorf("RANGE with an offset requires exactly one ORDER BY column")
+	}
+	col := strings.ToLower(orderBy[0].Col)
+	sign := 1.0
+	if orderBy[0].Desc {
+		sign = -1
+	}
+	key := func(r Row) (float64, bool, error) {
+		v, _ := getValLower(r, col)
+		if v == nil {
+			return 0, false, nil
+		}
+		f, ok := numeric(v)
+		if !ok {
+			return 0, false, fmt.Errorf("RANGE with an offset requires a numeric ORDER BY column, got %T", v)
+		}
+		return sign * f, true, nil
+	}
+	cur, ok, err := key(partitionRows[currentIdx])
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return rangePeerBound(partitionRows, currentIdx, orderBy, isStart), nil
+	}
+	threshold := cur + delta
+	if isStart {
+		for i, r := range partitionRows {
+			k, ok, err := key(r)
+			if err != nil {
+				return 0, err
+			}
+			if ok && k >= threshold {
+				return i, nil
+			}
+		}
+		return len(partitionRows), nil
+	}
+	for i := len(partitionRows) - 1; i >= 0; i-- {
+		k, ok, err := key(partitionRows[i])
+		if err != nil {
+			return 0, err
+		}
+		if ok && k <= threshold {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
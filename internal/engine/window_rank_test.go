@@ -7,6 +7,7 @@
 package engine
 
 import (
+	"context"
 	"strconv"
 	"testing"
 
@@ -89,6 +90,59 @@ func TestPercentRankAndCumeDist(t *testing.T) {
 	expectFloat(t, last["cd"], 1.0, 1e-9, "Fay cume_dist")
 }
 
+func TestPercentRankAndCumeDistEdgeCases(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE grades (class TEXT, pts INT)`)
+	// Ten rows in class a with ties at 20 and 40; one row in class b; three
+	// identical rows in class c.
+	for _, v := range []int{10, 20, 20, 30, 40, 40, 40, 50, 60, 70} {
+		execSQL(t, db, `INSERT INTO grades VALUES ('a', `+strconv.Itoa(v)+`)`)
+	}
+	execSQL(t, db, `INSERT INTO grades VALUES ('b', 1)`)
+	execSQL(t, db, `INSERT INTO grades VALUES ('c', 5), ('c', 5), ('c', 5)`)
+
+	rs := execSQL(t, db, `SELECT class, pts,
+		PERCENT_RANK() OVER (PARTITION BY class ORDER BY pts) AS pr,
+		CUME_DIST() OVER (PARTITION BY class ORDER BY pts) AS cd
+		FROM grades ORDER BY class, pts`)
+	if len(rs.Rows) != 14 {
+		t.Fatalf("expected 14 rows, got %d", len(rs.Rows))
+	}
+	// Class a: ranks 1,2,2,4,5,5,5,8,9,10 over 9 gaps; peers share the
+	// cumulative share of the last peer.
+	wantPR := []float64{0, 1, 1, 3, 4, 4, 4, 7, 8, 9}
+	wantCD := []float64{1, 3, 3, 4, 7, 7, 7, 8, 9, 10}
+	prev := 0.0
+	for i := 0; i < 10; i++ {
+		row := rs.Rows[i]
+		expectFloat(t, row["pr"], wantPR[i]/9, 1e-9, "class a percent_rank "+strconv.Itoa(i))
+		expectFloat(t, row["cd"], wantCD[i]/10, 1e-9, "class a cume_dist "+strconv.Itoa(i))
+		cd := row["cd"].(float64)
+		if cd < prev {
+			t.Fatalf("cume_dist decreased at row %d: %v < %v", i, cd, prev)
+		}
+		prev = cd
+	}
+	expectFloat(t, prev, 1.0, 1e-9, "class a cume_dist reaches 1")
+
+	// One-row partition.
+	expectFloat(t, rs.Rows[10]["pr"], 0, 1e-9, "one-row percent_rank")
+	expectFloat(t, rs.Rows[10]["cd"], 1, 1e-9, "one-row cume_dist")
+
+	// All identical values: every row is a peer of every other.
+	for i := 11; i < 14; i++ {
+		expectFloat(t, rs.Rows[i]["pr"], 0, 1e-9, "identical percent_rank")
+		expectFloat(t, rs.Rows[i]["cd"], 1, 1e-9, "identical cume_dist")
+	}
+
+	// Without ORDER BY the whole partition is one peer group too.
+	rs = execSQL(t, db, `SELECT PERCENT_RANK() OVER () AS pr, CUME_DIST() OVER () AS cd FROM grades`)
+	for _, row := range rs.Rows {
+		expectFloat(t, row["pr"], 0, 1e-9, "percent_rank without ORDER BY")
+		expectFloat(t, row["cd"], 1, 1e-9, "cume_dist without ORDER BY")
+	}
+}
+
 func TestNtileEvenAndUnevenSplit(t *testing.T) {
 	db := storage.NewDB()
 	execSQL(t, db, `CREATE TABLE t (id INT)`)
@@ -153,3 +207,145 @@ func TestNtileRejectsNonPositive(t *testing.T) {
 		t.Fatal("expected error for NTILE(0)")
 	}
 }
+
+func TestWindowFunctionsOnDuplicateRows(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE w (g TEXT, h TEXT, v INT)`)
+	execSQL(t, db, `INSERT INTO w VALUES ('a','x',10),('a','x',20),('a','y',20),('a','y',30),('b','x',5),('b','x',5),('b','y',7)`)
+
+	// Fully identical rows must still be numbered and bucketed separately.
+	rs := execSQL(t, db, `SELECT g, h, v,
+		ROW_NUMBER() OVER (ORDER BY v) AS rn,
+		RANK() OVER (PARTITION BY g ORDER BY v) AS rk,
+		DENSE_RANK() OVER (PARTITION BY g ORDER BY v) AS dr,
+		NTILE(2) OVER (PARTITION BY g ORDER BY v) AS nt,
+		ROW_NUMBER() OVER (PARTITION BY g, h ORDER BY v DESC) AS rn2
+		FROM w ORDER BY rn`)
+	want := [][5]int{
+		// rn, rk, dr, nt, rn2
+		{1, 1, 1, 1, 1},
+		{2, 1, 1, 1, 2},
+		{3, 3, 2, 2, 1},
+		{4, 1, 1, 1, 2},
+		{5, 2, 2, 1, 1},
+		{6, 2, 2, 2, 2},
+		{7, 4, 3, 2, 1},
+	}
+	if len(rs.Rows) != len(want) {
+		t.Fatalf("expected %d rows, got %d", len(want), len(rs.Rows))
+	}
+	for i, w := range want {
+		r := rs.Rows[i]
+		label := "row " + strconv.Itoa(i+1)
+		expectInt(t, r["rn"], w[0], label+" ROW_NUMBER")
+		expectInt(t, r["rk"], w[1], label+" RANK")
+		expectInt(t, r["dr"], w[2], label+" DENSE_RANK")
+		expectInt(t, r["nt"], w[3], label+" NTILE")
+		expectInt(t, r["rn2"], w[4], label+" ROW_NUMBER by (g, h)")
+	}
+}
+
+func TestLagLeadOffsetsAndDefaults(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE m (g TEXT, id INT, v INT)`)
+	execSQL(t, db, `INSERT INTO m VALUES ('a', 1, 10), ('a', 2, 20), ('a', 3, 30), ('b', 1, 100), ('b', 2, 200)`)
+
+	rs := execSQL(t, db, `SELECT g, id,
+		LAG(v) OVER (PARTITION BY g ORDER BY id) AS lag1,
+		LAG(v, 2) OVER (PARTITION BY g ORDER BY id) AS lag2,
+		LAG(v, 1, id * -1) OVER (PARTITION BY g ORDER BY id) AS lagdef,
+		LEAD(v) OVER (PARTITION BY g ORDER BY id) AS lead1,
+		LEAD(v, 1, 0) OVER (PARTITION BY g ORDER BY id) AS leaddef,
+		LAG(v, NULL) OVER (PARTITION BY g ORDER BY id) AS lagnull
+		FROM m ORDER BY g, id`)
+	want := []map[string]any{
+		{"lag1": nil, "lag2": nil, "lagdef": -1, "lead1": 20, "leaddef": 20},
+		{"lag1": 10, "lag2": nil, "lagdef": 10, "lead1": 30, "leaddef": 30},
+		{"lag1": 20, "lag2": 10, "lagdef": 20, "lead1": nil, "leaddef": 0},
+		// Partition boundaries are respected.
+		{"lag1": nil, "lag2": nil, "lagdef": -1, "lead1": 200, "leaddef": 200},
+		{"lag1": 100, "lag2": nil, "lagdef": 100, "lead1": nil, "leaddef": 0},
+	}
+	if len(rs.Rows) != len(want) {
+		t.Fatalf("expected %d rows, got %d", len(want), len(rs.Rows))
+	}
+	for i, w := range want {
+		for col, v := range w {
+			label := "row " + strconv.Itoa(i+1) + " " + col
+			if v == nil {
+				if got := rs.Rows[i][col]; got != nil {
+					t.Errorf("%s = %v, want NULL", label, got)
+				}
+				continue
+			}
+			expectInt(t, rs.Rows[i][col], v.(int), label)
+		}
+		if got := rs.Rows[i]["lagnull"]; got != nil {
+			t.Errorf("row %d: LAG with NULL offset = %v, want NULL", i+1, got)
+		}
+	}
+
+	for _, q := range []string{
+		`SELECT LAG() OVER (ORDER BY id) AS x FROM m`,
+		`SELECT LEAD(v, 'two') OVER (ORDER BY id) AS x FROM m`,
+	} {
+		stmt := mustParse(q)
+		if _, err := Execute(context.Background(), db, "default", stmt); err == nil {
+			t.Errorf("%s: expected error", q)
+		}
+	}
+}
+
+func TestFirstLastValueFrames(t *testing.T) {
+	db := storage.NewDB()
+	execSQL(t, db, `CREATE TABLE f (id INT, v INT)`)
+	execSQL(t, db, `INSERT INTO f VALUES (1, 10), (2, 20), (4, 40), (5, 50), (5, 55)`)
+
+	rs := execSQL(t, db, `SELECT id, v,
+		FIRST_VALUE(v) OVER (ORDER BY id) AS first_all,
+		LAST_VALUE(v) OVER (ORDER BY id) AS last_default,
+		LAST_VALUE(v) OVER (ORDER BY id ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS last_running,
+		FIRST_VALUE(v) OVER (ORDER BY id ROWS BETWEEN 1 PRECEDING AND 1 FOLLOWING) AS first_rows,
+		LAST_VALUE(v) OVER (ORDER BY id ROWS BETWEEN 1 PRECEDING AND 1 FOLLOWING) AS last_rows,
+		FIRST_VALUE(v) OVER (ORDER BY id ROWS BETWEEN 2 FOLLOWING AND 3 FOLLOWING) AS ahead,
+		LAST_VALUE(v) OVER (ORDER BY id RANGE BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS last_peer,
+		FIRST_VALUE(v) OVER (ORDER BY id RANGE BETWEEN 1 PRECEDING AND 1 FOLLOWING) AS first_range
+		FROM f ORDER BY id, v`)
+	want := []map[string]any{
+		{"first_all": 10, "last_default": 55, "last_running": 10, "first_rows": 10, "last_rows": 20, "ahead": 40, "last_peer": 10, "first_range": 10},
+		{"first_all": 10, "last_default": 55, "last_running": 20, "first_rows": 10, "last_rows": 40, "ahead": 50, "last_peer": 20, "first_range": 10},
+		{"first_all": 10, "last_default": 55, "last_running": 40, "first_rows": 20, "last_rows": 50, "ahead": 55, "last_peer": 40, "first_range": 40},
+		// RANGE CURRENT ROW includes both id=5 peers; past the partition end
+		// the frame is empty.
+		{"first_all": 10, "last_default": 55, "last_running": 50, "first_rows": 40, "last_rows": 55, "ahead": nil, "last_peer": 55, "first_range": 40},
+		{"first_all": 10, "last_default": 55, "last_running": 55, "first_rows": 50, "last_rows": 55, "ahead": nil, "last_peer": 55, "first_range": 40},
+	}
+	if len(rs.Rows) != len(want) {
+		t.Fatalf("expected %d rows, got %d", len(want), len(rs.Rows))
+	}
+	for i, w := range want {
+		for col, v := range w {
+			label := "row " + strconv.Itoa(i+1) + " " + col
+			if v == nil {
+				if got := rs.Rows[i][col]; got != nil {
+					t.Errorf("%s = %v, want NULL", label, got)
+				}
+				continue
+			}
+			expectInt(t, rs.Rows[i][col], v.(int), label)
+		}
+	}
+
+	for _, q := range []string{
+		`SELECT FIRST_VALUE(v) OVER (ORDER BY id ROWS BETWEEN UNBOUNDED FOLLOWING AND CURRENT ROW) AS x FROM f`,
+		`SELECT FIRST_VALUE(v) OVER (ORDER BY id ROWS BETWEEN CURRENT ROW AND UNBOUNDED PRECEDING) AS x FROM f`,
+	} {
+		if _, err := NewParser(q).ParseStatement(); err == nil {
+			t.Errorf("%s: expected parse error", q)
+		}
+	}
+	q := `SELECT FIRST_VALUE(v) OVER (ORDER BY id, v RANGE BETWEEN 1 PRECEDING AND CURRENT ROW) AS x FROM f`
+	if _, err := Execute(context.Background(), db, "default", mustParse(q)); err == nil {
+		t.Errorf("%s: expected error for multi-key RANGE offset", q)
+	}
+}
@@ -112,6 +112,23 @@ type ImportOptions struct {
 	// StrictTypes when true causes import to fail if data doesn't match detected types (default false).
 	// When false, falls back to TEXT on type conversion errors.
 	StrictTypes bool
+
+	// ProgressFunc, when set, is called as CSV rows are stored with the
+	// number of rows inserted so far and the bytes consumed from the source.
+	// ImportCSVStream calls it after every row, ImportCSV after every batch.
+	ProgressFunc func(rowsInserted, bytesRead int64)
+
+	// StartAtRow skips this many input data rows (CSV records after the
+	// header) before inserting, so an interrupted CSV import can continue
+	// at ImportResult.LastRowProcessed. Skipped rows still feed type inference.
+	StartAtRow int64
+
+	// Resume takes the start row from the <table>.import_progress file that
+	// an unfinished CSV import left next to the database files, overriding
+	// StartAtRow. Without Resume a leftover file is ignored and replaced.
+	// The file is removed once an import reaches the end of its input; an
+	// in-memory database without a path keeps no progress file.
+	Resume bool
 }
 
 // ImportResult returns metadata about the import operation.
@@ -125,6 +142,15 @@ type ImportResult struct {
 	ColumnNames  []string          // Final column names used
 	ColumnTypes  []storage.ColType // Detected column types
 	Errors       []string          // Non-fatal errors encountered during import
+
+	// InferredTypes reports, for CSV imports with TypeInference, the type
+	// votes of each column in the sampled rows and the type chosen for it.
+	InferredTypes []InferredColumnType
+
+	// LastRowProcessed counts the input data rows, from the start of the
+	// input, whose outcome is stored. Pass it as StartAtRow to continue an
+	// interrupted CSV import.
+	LastRowProcessed int64
 }
 
 // ============================================================================
@@ -171,9 +197,14 @@ func ImportCSV(
 	}
 
 	result := &ImportResult{Errors: make([]string, 0)}
+	progress := newImportProgress(db, tableName, src, opts)
+	startRow, err := progress.startRow()
+	if err != nil {
+		return nil, err
+	}
 
 	// Prepare reader and detect encoding
-	rr, enc, _, err := prepareReader(ctx, src, opts)
+	rr, enc, _, err := prepareReader(ctx, progress.counter, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -206,6 +237,9 @@ func ImportCSV(
 				break
 			}
 			if err != nil {
+				if !isCSVParseError(err) {
+					return nil, fmt.Errorf("read: %w", err)
+				}
 				result.Errors = append(result.Errors, fmt.Sprintf("read error: %v", err))
 				continue
 			}
@@ -216,7 +250,7 @@ func ImportCSV(
 	// Type inference
 	var colTypes []storage.ColType
 	if opts.TypeInference {
-		colTypes = inferColumnTypes(sampleData, len(colNames), opts)
+		colTypes, result.InferredTypes = inferColumnReport(sampleData, colNames, opts)
 	} else {
 		colTypes = make([]storage.ColType, len(colNames))
 		for i := range colTypes {
@@ -238,10 +272,11 @@ func ImportCSV(
 	}
 
 	// Insert the sampled rows first, then continue streaming from the reader.
-	rows, skipped, errs := insertCSVRecords(ctx, db, tenant, tableName, colNames, colTypes, sampleData, csvr, opts)
+	rows, skipped, errs := insertCSVRecords(ctx, db, tenant, tableName, colNames, colTypes, sampleData, csvr, progress, startRow, opts)
 	result.RowsInserted = rows
 	result.RowsSkipped = skipped
 	result.Errors = append(result.Errors, errs...)
+	result.LastRowProcessed = progress.rows
 
 	// Ensure the bufio.Reader is consumed/closed if needed
 	_ = sr
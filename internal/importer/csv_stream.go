@@ -0,0 +1,193 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+// ============================================================================
+// Streaming CSV Import
+// ============================================================================
+
+// ImportCSVStream imports delimited data like ImportCSV, but stores every
+// row in the table as soon as it has been parsed instead of collecting
+// BatchSize rows first. Apart from the bounded type-inference sample
+// (SampleRecords rows) and the table itself, memory use does not grow with
+// the input, and the returned ImportResult is updated after each row.
+//
+// Rows stored before ctx is cancelled stay in the table; the partial result
+// is returned together with the context's error. StartAtRow and Resume work
+// as for ImportCSV, with the progress file updated every BatchSize rows and
+// when the import stops.
+func ImportCSVStream(
+	ctx context.Context,
+	db *storage.DB,
+	tenant string,
+	tableName string,
+	src io.Reader,
+	opts *ImportOptions,
+) (*ImportResult, error) {
+	if opts == nil {
+		opts = &ImportOptions{}
+	}
+	applyDefaults(opts)
+
+	if opts.TableName != "" {
+		tableName = opts.TableName
+	}
+	if tableName == "" {
+		return nil, fmt.Errorf("table name is required")
+	}
+
+	result := &ImportResult{Errors: make([]string, 0)}
+	progress := newImportProgress(db, tableName, src, opts)
+	startRow, err := progress.startRow()
+	if err != nil {
+		return nil, err
+	}
+	result.LastRowProcessed = startRow
+	progress.rows = startRow
+
+	rr, enc, _, err := prepareReader(ctx, progress.counter, opts)
+	if err != nil {
+		return nil, err
+	}
+	result.Encoding = enc
+
+	_, csvr, colNames, firstDataRow, delim, hasHeader, lineEnding, err := initCSVFromReader(rr, opts)
+	if err != nil {
+		return nil, err
+	}
+	result.Delimiter = delim
+	result.HadHeader = hasHeader
+	result.ColumnNames = colNames
+	result.LineEnding = lineEnding
+
+	// Type inference needs a sample before the table exists; it is the only
+	// part of the input held in memory.
+	sample := make([][]string, 0, opts.SampleRecords)
+	if firstDataRow != nil {
+		sample = append(sample, firstDataRow)
+	}
+	if opts.TypeInference {
+		for len(sample) < opts.SampleRecords {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+			rec, err := csvr.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				if !isCSVParseError(err) {
+					return nil, fmt.Errorf("read: %w", err)
+				}
+				result.Errors = append(result.Errors, fmt.Sprintf("read error: %v", err))
+				continue
+			}
+			sample = append(sample, rec)
+		}
+		result.ColumnTypes, result.InferredTypes = inferColumnReport(sample, colNames, opts)
+	} else {
+		result.ColumnTypes = make([]storage.ColType, len(colNames))
+		for i := range result.ColumnTypes {
+			result.ColumnTypes[i] = storage.TextType
+		}
+	}
+
+	if opts.CreateTable {
+		if err := createTable(ctx, db, tenant, tableName, colNames, result.ColumnTypes); err != nil {
+			return nil, fmt.Errorf("create table: %w", err)
+		}
+	}
+	if opts.Truncate {
+		if err := truncateTable(ctx, db, tenant, tableName); err != nil {
+			return nil, fmt.Errorf("truncate table: %w", err)
+		}
+	}
+	tbl, err := db.Get(tenant, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("get table: %w", err)
+	}
+
+	rowNum := int64(0)
+	store := func(rec []string) error {
+		rowNum++
+		if rowNum <= startRow {
+			return nil
+		}
+		row, err := convertRow(rec, colNames, result.ColumnTypes, opts)
+		if err != nil {
+			result.RowsSkipped++
+			if opts.StrictTypes {
+				result.Errors = append(result.Errors, fmt.Sprintf("row %d: %v", rowNum, err))
+				return err
+			}
+			result.Errors = append(result.Errors, fmt.Sprintf("row %d: %v (skipped)", rowNum, err))
+			result.LastRowProcessed = rowNum
+			return nil
+		}
+		tbl.Rows = append(tbl.Rows, row)
+		result.RowsInserted++
+		result.LastRowProcessed = rowNum
+		progress.report(result.RowsInserted, rowNum)
+		if result.RowsInserted%int64(opts.BatchSize) == 0 {
+			if err := progress.save(); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("save import progress: %v", err))
+			}
+		}
+		return nil
+	}
+	// stop records where an unfinished import ended for a later Resume.
+	stop := func(err error) (*ImportResult, error) {
+		progress.rows = result.LastRowProcessed
+		if serr := progress.save(); serr != nil && err == nil {
+			err = fmt.Errorf("save import progress: %w", serr)
+		}
+		return result, err
+	}
+
+	for i, rec := range sample {
+		sample[i] = nil
+		if err := ctx.Err(); err != nil {
+			return stop(err)
+		}
+		if err := store(rec); err != nil {
+			return stop(nil)
+		}
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return stop(err)
+		}
+		rec, err := csvr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			if cerr := ctx.Err(); cerr != nil {
+				return stop(cerr)
+			}
+			if !isCSVParseError(err) {
+				return stop(fmt.Errorf("read: %w", err))
+			}
+			rowNum++
+			result.RowsSkipped++
+			result.Errors = append(result.Errors, fmt.Sprintf("row %d: read error: %v", rowNum, err))
+			continue
+		}
+		if err := store(rec); err != nil {
+			return stop(nil)
+		}
+	}
+	result.LastRowProcessed = max(result.LastRowProcessed, rowNum)
+	if err := progress.finish(); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("remove import progress: %v", err))
+	}
+	return result, nil
+}
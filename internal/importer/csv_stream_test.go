@@ -0,0 +1,161 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+// csvGenerator produces "id,name,score" CSV rows on demand so large inputs
+// never exist in memory as a whole.
+type csvGenerator struct {
+	rows, next int
+	buf        []byte
+}
+
+func newCSVGenerator(rows int) *csvGenerator {
+	return &csvGenerator{rows: rows, buf: []byte("id,name,score\n")}
+}
+
+func (g *csvGenerator) Read(p []byte) (int, error) {
+	for len(g.buf) < len(p) && g.next < g.rows {
+		g.next++
+		g.buf = strconv.AppendInt(g.buf, int64(g.next), 10)
+		g.buf = append(g.buf, ",name"...)
+		g.buf = strconv.AppendInt(g.buf, int64(g.next%97), 10)
+		g.buf = append(g.buf, ',')
+		g.buf = strconv.AppendFloat(g.buf, float64(g.next)/4, 'f', -1, 64)
+		g.buf = append(g.buf, '\n')
+	}
+	if len(g.buf) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, g.buf)
+	g.buf = g.buf[:copy(g.buf, g.buf[n:])]
+	return n, nil
+}
+
+func TestImportCSVStream_LargeInput(t *testing.T) {
+	rows := 1_000_000
+	if testing.Short() {
+		rows = 50_000
+	}
+	db := storage.NewDB()
+	var calls, lastRows, lastBytes int64
+	opts := &ImportOptions{
+		HeaderMode: "present",
+		ProgressFunc: func(rowsInserted, bytesRead int64) {
+			calls++
+			if rowsInserted < lastRows || bytesRead < lastBytes {
+				t.Fatalf("progress went backwards: %d/%d after %d/%d", rowsInserted, bytesRead, lastRows, lastBytes)
+			}
+			lastRows, lastBytes = rowsInserted, bytesRead
+		},
+	}
+	var before runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	result, err := ImportCSVStream(context.Background(), db, "default", "big", newCSVGenerator(rows), opts)
+	if err != nil {
+		t.Fatalf("ImportCSVStream: %v", err)
+	}
+	if result.RowsInserted != int64(rows) || result.RowsSkipped != 0 {
+		t.Fatalf("inserted %d, skipped %d; want %d, 0", result.RowsInserted, result.RowsSkipped, rows)
+	}
+	if calls != int64(rows) || lastRows != int64(rows) || lastBytes == 0 {
+		t.Fatalf("progress: %d calls, last %d rows / %d bytes", calls, lastRows, lastBytes)
+	}
+	tbl, err := db.Get("default", "big")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tbl.Rows) != rows || tbl.Rows[rows-1][0] != int64(rows) {
+		t.Fatalf("table has %d rows, last %v", len(tbl.Rows), tbl.Rows[len(tbl.Rows)-1])
+	}
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+	// The stored rows dominate; the importer itself must not keep a copy of
+	// the parsed input around.
+	perRow := (after.HeapAlloc - min(before.HeapAlloc, after.HeapAlloc)) / uint64(rows)
+	if perRow > 512 {
+		t.Fatalf("heap grew by %d bytes per row", perRow)
+	}
+}
+
+func TestImportCSVStream_MatchesImportCSV(t *testing.T) {
+	data := "id,name,active,score\n1,ann,true,1.5\n2,,false,NA\n3,\"c, d\",true,3\n4,eve,,4.25\n"
+	ctx := context.Background()
+
+	db := storage.NewDB()
+	want, err := ImportCSV(ctx, db, "default", "batch", strings.NewReader(data), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ImportCSVStream(ctx, db, "default", "stream", strings.NewReader(data), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.RowsInserted != want.RowsInserted || !reflect.DeepEqual(got.ColumnNames, want.ColumnNames) ||
+		!reflect.DeepEqual(got.ColumnTypes, want.ColumnTypes) || got.HadHeader != want.HadHeader {
+		t.Fatalf("stream result %+v differs from %+v", got, want)
+	}
+	bt, _ := db.Get("default", "batch")
+	st, _ := db.Get("default", "stream")
+	if !reflect.DeepEqual(bt.Rows, st.Rows) {
+		t.Fatalf("rows differ:\nbatch  %v\nstream %v", bt.Rows, st.Rows)
+	}
+}
+
+func TestImportCSVStream_CancelKeepsPartialImport(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db := storage.NewDB()
+	opts := &ImportOptions{
+		HeaderMode:    "present",
+		SampleRecords: 10,
+		ProgressFunc: func(rowsInserted, _ int64) {
+			if rowsInserted == 250 {
+				cancel()
+			}
+		},
+	}
+	result, err := ImportCSVStream(ctx, db, "default", "partial", newCSVGenerator(10_000), opts)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if result == nil || result.RowsInserted != 250 {
+		t.Fatalf("result = %+v, want 250 rows", result)
+	}
+	tbl, err := db.Get("default", "partial")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tbl.Rows) != 250 {
+		t.Fatalf("table kept %d rows, want 250", len(tbl.Rows))
+	}
+}
+
+func TestImportCSV_ProgressPerBatch(t *testing.T) {
+	var calls []int64
+	opts := &ImportOptions{
+		HeaderMode: "present",
+		BatchSize:  40,
+		ProgressFunc: func(rowsInserted, _ int64) {
+			calls = append(calls, rowsInserted)
+		},
+	}
+	if _, err := ImportCSV(context.Background(), storage.NewDB(), "default", "t", newCSVGenerator(100), opts); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{40, 80, 100}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("progress calls = %v, want %v", calls, want)
+	}
+}
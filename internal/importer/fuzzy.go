@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"regexp"
@@ -18,6 +19,14 @@ import (
 // headerPattern matches typical column-name identifiers.
 var headerPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
 
+// ErrFuzzyColumnCount is passed (wrapped) to FuzzyImportOptions.OnError for
+// a CSV row whose field count differs from the header.
+var ErrFuzzyColumnCount = errors.New("column count mismatch")
+
+// ErrFuzzyConversion is passed (wrapped) to FuzzyImportOptions.OnError for a
+// CSV row with a value that cannot be converted to its column's type.
+var ErrFuzzyConversion = errors.New("value conversion failed")
+
 // FuzzyImportOptions extends ImportOptions with fuzzy parsing capabilities
 type FuzzyImportOptions struct {
 	*ImportOptions
@@ -48,6 +57,14 @@ type FuzzyImportOptions struct {
 
 	// AutoFixDelimiters tries to detect and fix inconsistent delimiters (default true)
 	AutoFixDelimiters bool
+
+	// OnError, when set, decides the fate of each bad CSV row instead of
+	// SkipInvalidRows: rows with the wrong field count (ErrFuzzyColumnCount)
+	// are no longer padded or merged, and values that do not convert
+	// (ErrFuzzyConversion) are no longer coerced to text. It receives the
+	// 1-based data row number, the row's original CSV text and the error;
+	// returning true skips the row, false aborts the import.
+	OnError func(row int64, line string, err error) bool
 }
 
 // FuzzyImportCSV is a more forgiving version of ImportCSV that handles malformed data
@@ -94,7 +111,13 @@ func FuzzyImportCSV(
 
 	headers, dataRecords := fuzzyPrepareHeaders(records, opts)
 	numCols := len(headers)
-	dataRecords = normalizeRecords(dataRecords, numCols, opts)
+	var dataLines []string
+	if opts.OnError != nil {
+		lines := fuzzyRecordLines(cleanedData, delimiter)
+		dataLines = lines[len(lines)-len(dataRecords):]
+	} else {
+		dataRecords = normalizeRecords(dataRecords, numCols, opts)
+	}
 
 	columnTypes := fuzzyPrepareColumnTypes(dataRecords, numCols, opts)
 	result.ColumnNames = headers
@@ -104,7 +127,7 @@ func FuzzyImportCSV(
 		return nil, err
 	}
 
-	if err := fuzzyInsertRows(db, tenant, tableName, headers, columnTypes, dataRecords, result, opts); err != nil {
+	if err := fuzzyInsertRows(db, tenant, tableName, headers, columnTypes, dataRecords, dataLines, result, opts); err != nil {
 		return nil, err
 	}
 
@@ -165,8 +188,9 @@ func fuzzyEnsureTable(db *storage.DB, tenant, tableName string, headers []string
 	return nil
 }
 
-// Helper: insert rows with fuzzy conversion and report into result
-func fuzzyInsertRows(db *storage.DB, tenant, tableName string, headers []string, columnTypes []storage.ColType, dataRecords [][]string, result *ImportResult, opts *FuzzyImportOptions) error {
+// Helper: insert rows with fuzzy conversion and report into result. dataLines
+// holds the original text of each record and is only set when opts.OnError is.
+func fuzzyInsertRows(db *storage.DB, tenant, tableName string, headers []string, columnTypes []storage.ColType, dataRecords [][]string, dataLines []string, result *ImportResult, opts *FuzzyImportOptions) error {
 	table, err := db.Get(tenant, tableName)
 	if err != nil {
 		return fmt.Errorf("failed to get table: %v", err)
@@ -175,16 +199,25 @@ func fuzzyInsertRows(db *storage.DB, tenant, tableName string, headers []string,
 	skippedRows := 0
 	for rowIdx, record := range dataRecords {
 		if len(record) != numCols {
+			rowErr := fmt.Errorf("%w (expected %d, got %d)", ErrFuzzyColumnCount, numCols, len(record))
+			if opts.OnError != nil {
+				if !opts.OnError(int64(rowIdx+1), dataLines[rowIdx], rowErr) {
+					return fmt.Errorf("import aborted at row %d: %w", rowIdx+1, rowErr)
+				}
+				result.RowsSkipped++
+				result.Errors = append(result.Errors, fmt.Sprintf("row %d: %v", rowIdx+1, rowErr))
+				continue
+			}
 			skippedRows++
 			result.RowsSkipped++
-			result.Errors = append(result.Errors, fmt.Sprintf("row %d: column count mismatch (expected %d, got %d)", rowIdx+1, numCols, len(record)))
+			result.Errors = append(result.Errors, fmt.Sprintf("row %d: %v", rowIdx+1, rowErr))
 			if skippedRows > opts.MaxSkippedRows {
 				return fmt.Errorf("too many skipped rows (%d), aborting import", skippedRows)
 			}
 			continue
 		}
 		row := make([]interface{}, numCols)
-		hasError := false
+		var rowErr error
 		for colIdx := 0; colIdx < numCols; colIdx++ {
 			value := record[colIdx]
 			if opts.TrimWhitespace {
@@ -192,24 +225,28 @@ func fuzzyInsertRows(db *storage.DB, tenant, tableName string, headers []string,
 			}
 			converted, err := fuzzyConvertValue(value, columnTypes[colIdx], opts)
 			if err != nil {
-				if !opts.CoerceTypes {
-					hasError = true
-					result.Errors = append(result.Errors, fmt.Sprintf("row %d, col %s: %v", rowIdx+1, headers[colIdx], err))
+				if opts.OnError != nil || !opts.CoerceTypes {
+					rowErr = fmt.Errorf("col %s: %w: %v", headers[colIdx], ErrFuzzyConversion, err)
 					break
 				}
 				converted = value
 			}
 			row[colIdx] = converted
 		}
-		if hasError && !opts.SkipInvalidRows {
-			return fmt.Errorf("import failed at row %d", rowIdx+1)
-		}
-		if !hasError {
-			table.Rows = append(table.Rows, row)
-			result.RowsInserted++
-		} else {
+		if rowErr != nil {
+			if opts.OnError != nil {
+				if !opts.OnError(int64(rowIdx+1), dataLines[rowIdx], rowErr) {
+					return fmt.Errorf("import aborted at row %d: %w", rowIdx+1, rowErr)
+				}
+			} else if !opts.SkipInvalidRows {
+				return fmt.Errorf("import failed at row %d", rowIdx+1)
+			}
 			result.RowsSkipped++
+			result.Errors = append(result.Errors, fmt.Sprintf("row %d, %v", rowIdx+1, rowErr))
+			continue
 		}
+		table.Rows = append(table.Rows, row)
+		result.RowsInserted++
 	}
 	return nil
 }
@@ -405,6 +442,30 @@ func tryParseWithDelimiter(data string, delim rune, opts *FuzzyImportOptions) ([
 	return records, score
 }
 
+// fuzzyRecordLines returns the original text of every record that
+// tryParseWithDelimiter keeps for delim, without the line terminator.
+func fuzzyRecordLines(data string, delim rune) []string {
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.Comma = delim
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	var lines []string
+	for {
+		start := reader.InputOffset()
+		_, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(data[start:reader.InputOffset()], "\n"))
+	}
+	return lines
+}
+
 // normalizeRecords ensures all records have the same number of columns
 func normalizeRecords(records [][]string, expectedCols int, opts *FuzzyImportOptions) [][]string {
 	normalized := make([][]string, 0, len(records))
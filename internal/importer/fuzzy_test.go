@@ -1,6 +1,8 @@
 package importer
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
 
@@ -97,3 +99,93 @@ func TestParseLineDelimitedJSON(t *testing.T) {
 		t.Fatalf("expected 2 parsed records, got %d", len(recs))
 	}
 }
+
+func TestFuzzyImportCSVOnErrorSkipsConversionAbortsColumnCount(t *testing.T) {
+	db := storage.NewDB()
+	src := "id,name\n11,alice\nxx,bob\n13,carol\n14,dave,extra\n15,eve\n16,frank\n"
+	var seen []error
+	opts := &FuzzyImportOptions{
+		ImportOptions: &ImportOptions{CreateTable: true, TypeInference: true, HeaderMode: "present"},
+		OnError: func(row int64, line string, err error) bool {
+			seen = append(seen, err)
+			return errors.Is(err, ErrFuzzyConversion)
+		},
+	}
+	res, err := FuzzyImportCSV(context.Background(), db, "default", "people", strings.NewReader(src), opts)
+	if err == nil || !errors.Is(err, ErrFuzzyColumnCount) {
+		t.Fatalf("expected column count abort, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "row 4") {
+		t.Fatalf("abort should name row 4: %v", err)
+	}
+	if res != nil {
+		t.Fatalf("aborted import should not return a result")
+	}
+	if len(seen) != 2 || !errors.Is(seen[0], ErrFuzzyConversion) {
+		t.Fatalf("callback errors = %v", seen)
+	}
+	tbl, _ := db.Get("default", "people")
+	if len(tbl.Rows) != 2 {
+		t.Fatalf("rows before abort = %d, want 2", len(tbl.Rows))
+	}
+}
+
+func TestFuzzyImportCSVOnErrorHaltsAtRow(t *testing.T) {
+	db := storage.NewDB()
+	src := "n\n10\n20\nthree\n40\n50\n"
+	var calls []int64
+	opts := &FuzzyImportOptions{
+		ImportOptions: &ImportOptions{CreateTable: true, TypeInference: true, HeaderMode: "present"},
+		OnError: func(row int64, line string, err error) bool {
+			calls = append(calls, row)
+			return false
+		},
+	}
+	if _, err := FuzzyImportCSV(context.Background(), db, "default", "nums", strings.NewReader(src), opts); err == nil {
+		t.Fatalf("expected abort")
+	}
+	if len(calls) != 1 || calls[0] != 3 {
+		t.Fatalf("callback rows = %v, want [3]", calls)
+	}
+	tbl, _ := db.Get("default", "nums")
+	if len(tbl.Rows) != 2 {
+		t.Fatalf("rows inserted = %d, want 2", len(tbl.Rows))
+	}
+}
+
+func TestFuzzyImportCSVNilOnErrorUsesSkipInvalidRows(t *testing.T) {
+	db := storage.NewDB()
+	src := "a,b\n1,x\n2\n3,y,z\n"
+	opts := &FuzzyImportOptions{ImportOptions: &ImportOptions{CreateTable: true, TypeInference: true, HeaderMode: "present"}}
+	res, err := FuzzyImportCSV(context.Background(), db, "default", "t", strings.NewReader(src), opts)
+	if err != nil {
+		t.Fatalf("FuzzyImportCSV: %v", err)
+	}
+	// Short rows are padded and long rows merged, as before OnError existed.
+	if res.RowsInserted != 3 || res.RowsSkipped != 0 {
+		t.Fatalf("inserted=%d skipped=%d", res.RowsInserted, res.RowsSkipped)
+	}
+}
+
+func TestFuzzyImportCSVOnErrorReceivesOriginalLine(t *testing.T) {
+	db := storage.NewDB()
+	src := "id;note\n1;\"ok\"\n2;\"semi;colon\";x\n3;fine\n4;good\n5;done\n6;last\n"
+	var lines []string
+	opts := &FuzzyImportOptions{
+		ImportOptions: &ImportOptions{CreateTable: true, TypeInference: true, HeaderMode: "present"},
+		OnError: func(row int64, line string, err error) bool {
+			lines = append(lines, line)
+			return true
+		},
+	}
+	res, err := FuzzyImportCSV(context.Background(), db, "default", "notes", strings.NewReader(src), opts)
+	if err != nil {
+		t.Fatalf("FuzzyImportCSV: %v", err)
+	}
+	if len(lines) != 1 || lines[0] != `2;"semi;colon";x` {
+		t.Fatalf("lines = %q", lines)
+	}
+	if res.RowsInserted != 5 || res.RowsSkipped != 1 {
+		t.Fatalf("inserted=%d skipped=%d", res.RowsInserted, res.RowsSkipped)
+	}
+}
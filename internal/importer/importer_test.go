@@ -176,6 +176,46 @@ func TestImportCSV_TypeInference(t *testing.T) {
 	}
 }
 
+// TestImportCSV_InferredTypesReport checks the per-column vote counts and
+// NULL fraction reported alongside the inferred types.
+func TestImportCSV_InferredTypesReport(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("code,note,empty\n")
+	for i := 1; i <= 10; i++ {
+		code := strconv.Itoa(i)
+		if i == 10 {
+			code = "n/a-x"
+		}
+		note := "text"
+		if i%4 == 0 {
+			note = "NULL"
+		}
+		sb.WriteString(code + "," + note + ",\n")
+	}
+
+	result, err := ImportCSV(context.Background(), storage.NewDB(), "default", "report",
+		strings.NewReader(sb.String()), &ImportOptions{HeaderMode: "present"})
+	if err != nil {
+		t.Fatalf("ImportCSV failed: %v", err)
+	}
+	if len(result.InferredTypes) != 3 {
+		t.Fatalf("expected 3 column reports, got %#v", result.InferredTypes)
+	}
+
+	code := result.InferredTypes[0]
+	if code.Name != "code" || code.DetectedType != storage.IntType.String() || code.IntCount != 9 || code.TextCount != 1 {
+		t.Fatalf("mixed int/string column: %+v", code)
+	}
+	note := result.InferredTypes[1]
+	if note.NullCount != 2 || note.SampledRows != 10 || note.NullFraction != 0.2 || note.DetectedType != "TEXT" {
+		t.Fatalf("null fraction column: %+v", note)
+	}
+	empty := result.InferredTypes[2]
+	if empty.NullCount != 10 || empty.NullFraction != 1 || empty.DetectedType != "TEXT" || result.ColumnTypes[2] != storage.TextType {
+		t.Fatalf("all-NULL column: %+v", empty)
+	}
+}
+
 // TestImportCSV_NullHandling verifies that configured null literal
 // strings are interpreted as SQL NULL values on import.
 func TestImportCSV_NullHandling(t *testing.T) {
@@ -3,6 +3,7 @@ package importer
 import (
 	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 
@@ -138,7 +139,10 @@ func insertAllRecords(
 }
 
 // insertCSVRecords inserts an initial slice of CSV records and then continues
-// reading from the provided reader with batching.
+// reading from the provided reader with batching. The first startRow records
+// are skipped. progress follows the flushed batches: if the import stops
+// early its sidecar file keeps the last flushed row, and it is removed once
+// the input is exhausted.
 func insertCSVRecords(
 	ctx context.Context,
 	db *storage.DB,
@@ -148,11 +152,14 @@ func insertCSVRecords(
 	colTypes []storage.ColType,
 	initialRecords [][]string,
 	csvr *csv.Reader,
+	progress *importProgress,
+	startRow int64,
 	opts *ImportOptions,
 ) (rowsInserted int64, rowsSkipped int64, errors []string) {
 	errors = make([]string, 0)
 	batch := make([][]any, 0, opts.BatchSize)
 	rowNum := 0
+	progress.rows = startRow
 
 	flushBatch := func() error {
 		if len(batch) == 0 {
@@ -167,11 +174,25 @@ func insertCSVRecords(
 		tbl.Rows = append(tbl.Rows, batch...)
 		rowsInserted += int64(len(batch))
 		batch = batch[:0]
+		progress.report(rowsInserted, int64(rowNum))
+		if err := progress.save(); err != nil {
+			return fmt.Errorf("save import progress: %w", err)
+		}
 		return nil
 	}
 
+	stop := func() (int64, int64, []string) {
+		if err := progress.save(); err != nil {
+			errors = append(errors, fmt.Sprintf("save import progress: %v", err))
+		}
+		return rowsInserted, rowsSkipped, errors
+	}
+
 	processRecord := func(rec []string) bool {
 		rowNum++
+		if int64(rowNum) <= startRow {
+			return false
+		}
 		row, err := convertRow(rec, colNames, colTypes, opts)
 		if err != nil {
 			if opts.StrictTypes {
@@ -202,8 +223,8 @@ func insertCSVRecords(
 	}
 
 	for _, rec := range initialRecords {
-		if stop := processRecord(rec); stop {
-			return rowsInserted, rowsSkipped, errors
+		if processRecord(rec) {
+			return stop()
 		}
 	}
 
@@ -213,18 +234,31 @@ func insertCSVRecords(
 			break
 		}
 		if err != nil {
+			if ctx.Err() != nil {
+				errors = append(errors, "import cancelled")
+				return stop()
+			}
+			if !isCSVParseError(err) {
+				errors = append(errors, fmt.Sprintf("read error: %v", err))
+				return stop()
+			}
 			rowNum++
 			errors = append(errors, fmt.Sprintf("row %d: read error: %v", rowNum, err))
 			rowsSkipped++
 			continue
 		}
-		if stop := processRecord(rec); stop {
-			return rowsInserted, rowsSkipped, errors
+		if processRecord(rec) {
+			return stop()
 		}
 	}
 
 	if err := flushBatch(); err != nil {
 		errors = append(errors, err.Error())
+		return stop()
+	}
+	progress.rows = max(progress.rows, int64(rowNum))
+	if err := progress.finish(); err != nil {
+		errors = append(errors, fmt.Sprintf("remove import progress: %v", err))
 	}
 
 	return rowsInserted, rowsSkipped, errors
@@ -249,7 +283,15 @@ func streamInsertCSV(
 	if firstDataRow != nil {
 		initialRecords = append(initialRecords, firstDataRow)
 	}
-	return insertCSVRecords(ctx, db, tenant, tableName, colNames, colTypes, initialRecords, csvr, opts)
+	progress := &importProgress{opts: opts}
+	return insertCSVRecords(ctx, db, tenant, tableName, colNames, colTypes, initialRecords, csvr, progress, 0, opts)
+}
+
+// isCSVParseError reports whether err concerns one malformed record, after
+// which reading can continue, rather than a failing source.
+func isCSVParseError(err error) bool {
+	var perr *csv.ParseError
+	return errors.As(err, &perr)
 }
 
 // convertRow converts a CSV record to a typed row for insertion.
@@ -0,0 +1,123 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+// ============================================================================
+// Import Progress & Resume
+// ============================================================================
+
+// progressFileSuffix names the sidecar file, <table>.import_progress, that
+// records how many input rows of an unfinished CSV import are stored.
+const progressFileSuffix = ".import_progress"
+
+// importProgress tracks a CSV import for ProgressFunc and resumption. rows
+// is the number of input data rows whose outcome (stored or skipped) is in
+// the table; it becomes ImportResult.LastRowProcessed and is what the
+// sidecar file holds while the import is unfinished.
+type importProgress struct {
+	opts    *ImportOptions
+	counter *countingReader
+	path    string // sidecar file; empty when the DB has no directory
+	rows    int64
+}
+
+// newImportProgress wraps src for byte counting and resolves where the
+// sidecar file for tableName lives.
+func newImportProgress(db *storage.DB, tableName string, src io.Reader, opts *ImportOptions) *importProgress {
+	p := &importProgress{opts: opts, counter: &countingReader{r: src}}
+	if dir := dbDirectory(db); dir != "" {
+		p.path = filepath.Join(dir, tableName+progressFileSuffix)
+	}
+	return p
+}
+
+// dbDirectory returns the directory holding db's files, or "" for a purely
+// in-memory database.
+func dbDirectory(db *storage.DB) string {
+	cfg := db.Config()
+	if cfg == nil || cfg.Path == "" {
+		return ""
+	}
+	if info, err := os.Stat(cfg.Path); err == nil && info.IsDir() {
+		return cfg.Path
+	}
+	return filepath.Dir(cfg.Path)
+}
+
+// startRow returns the number of input data rows to skip: the sidecar's
+// value when Resume is set and a sidecar exists, StartAtRow otherwise.
+func (p *importProgress) startRow() (int64, error) {
+	if p.opts.Resume && p.path != "" {
+		data, err := os.ReadFile(p.path)
+		switch {
+		case err == nil:
+			n, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid import progress file %s", p.path)
+			}
+			return n, nil
+		case !os.IsNotExist(err):
+			return 0, fmt.Errorf("read import progress: %w", err)
+		}
+	}
+	if p.opts.StartAtRow < 0 {
+		return 0, fmt.Errorf("StartAtRow must not be negative")
+	}
+	return p.opts.StartAtRow, nil
+}
+
+// report records that rows input rows are stored and calls ProgressFunc.
+func (p *importProgress) report(inserted, rows int64) {
+	p.rows = rows
+	if p.opts.ProgressFunc != nil {
+		p.opts.ProgressFunc(inserted, p.counter.Count())
+	}
+}
+
+// save writes the current row count to the sidecar file.
+func (p *importProgress) save() error {
+	if p.path == "" {
+		return nil
+	}
+	return os.WriteFile(p.path, []byte(strconv.FormatInt(p.rows, 10)+"\n"), 0o644)
+}
+
+// finish removes the sidecar file after the whole input was imported.
+func (p *importProgress) finish() error {
+	if p.path == "" {
+		return nil
+	}
+	if err := os.Remove(p.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// countingReader counts the bytes read through it for progress reports.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Count returns the number of bytes read so far.
+func (c *countingReader) Count() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.n
+}
@@ -0,0 +1,148 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SimonWaldherr/tinySQL/internal/storage"
+)
+
+func openResumeDB(t *testing.T) (*storage.DB, string) {
+	t.Helper()
+	dir := t.TempDir()
+	db, err := storage.OpenDB(storage.StorageConfig{Mode: storage.ModeMemory, Path: filepath.Join(dir, "data.gob")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, dir
+}
+
+// checkIDs fails unless table holds ids 1..n exactly once each.
+func checkIDs(t *testing.T, db *storage.DB, table string, n int) {
+	t.Helper()
+	tbl, err := db.Get("default", table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tbl.Rows) != n {
+		t.Fatalf("%s has %d rows, want %d", table, len(tbl.Rows), n)
+	}
+	seen := make(map[int64]bool, n)
+	for _, r := range tbl.Rows {
+		id := r[0].(int64)
+		if seen[id] || id < 1 || id > int64(n) {
+			t.Fatalf("unexpected or duplicate id %d", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestImportCSVResumeAfterFailure(t *testing.T) {
+	db, dir := openResumeDB(t)
+	sidecar := filepath.Join(dir, "items"+progressFileSuffix)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	opts := &ImportOptions{
+		HeaderMode: "present",
+		BatchSize:  100,
+		ProgressFunc: func(rowsInserted, _ int64) {
+			if rowsInserted == 500 {
+				cancel()
+			}
+		},
+	}
+	first, err := ImportCSV(ctx, db, "default", "items", newCSVGenerator(1000), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.RowsInserted != 500 || first.LastRowProcessed != 500 {
+		t.Fatalf("first run: inserted %d, last row %d; want 500, 500", first.RowsInserted, first.LastRowProcessed)
+	}
+	if data, err := os.ReadFile(sidecar); err != nil || string(data) != "500\n" {
+		t.Fatalf("progress file = %q, %v; want 500", data, err)
+	}
+
+	second, err := ImportCSV(context.Background(), db, "default", "items", newCSVGenerator(1000),
+		&ImportOptions{HeaderMode: "present", BatchSize: 100, Resume: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.RowsInserted != 500 || second.LastRowProcessed != 1000 {
+		t.Fatalf("resumed run: inserted %d, last row %d; want 500, 1000", second.RowsInserted, second.LastRowProcessed)
+	}
+	checkIDs(t, db, "items", 1000)
+	if _, err := os.Stat(sidecar); !os.IsNotExist(err) {
+		t.Fatalf("progress file not removed after completion: %v", err)
+	}
+}
+
+func TestImportCSVStreamResumeAfterFailure(t *testing.T) {
+	db, dir := openResumeDB(t)
+	sidecar := filepath.Join(dir, "items"+progressFileSuffix)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	opts := &ImportOptions{
+		HeaderMode: "present",
+		BatchSize:  100,
+		ProgressFunc: func(rowsInserted, _ int64) {
+			if rowsInserted == 321 {
+				cancel()
+			}
+		},
+	}
+	first, err := ImportCSVStream(ctx, db, "default", "items", newCSVGenerator(1000), opts)
+	if !errors.Is(err, context.Canceled) || first.LastRowProcessed != 321 {
+		t.Fatalf("first run: %v, last row %d; want canceled at 321", err, first.LastRowProcessed)
+	}
+	if data, err := os.ReadFile(sidecar); err != nil || string(data) != "321\n" {
+		t.Fatalf("progress file = %q, %v; want 321", data, err)
+	}
+
+	if _, err := ImportCSVStream(context.Background(), db, "default", "items", newCSVGenerator(1000),
+		&ImportOptions{HeaderMode: "present", Resume: true}); err != nil {
+		t.Fatal(err)
+	}
+	checkIDs(t, db, "items", 1000)
+	if _, err := os.Stat(sidecar); !os.IsNotExist(err) {
+		t.Fatalf("progress file not removed after completion: %v", err)
+	}
+}
+
+func TestImportCSVWithoutResumeIgnoresProgressFile(t *testing.T) {
+	db, dir := openResumeDB(t)
+	sidecar := filepath.Join(dir, "items"+progressFileSuffix)
+	if err := os.WriteFile(sidecar, []byte("700\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	result, err := ImportCSV(context.Background(), db, "default", "items", newCSVGenerator(1000),
+		&ImportOptions{HeaderMode: "present"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RowsInserted != 1000 {
+		t.Fatalf("inserted %d rows, want 1000", result.RowsInserted)
+	}
+	checkIDs(t, db, "items", 1000)
+	if _, err := os.Stat(sidecar); !os.IsNotExist(err) {
+		t.Fatalf("leftover progress file not replaced and removed: %v", err)
+	}
+}
+
+func TestImportCSVStartAtRow(t *testing.T) {
+	db := storage.NewDB()
+	result, err := ImportCSV(context.Background(), db, "default", "items", newCSVGenerator(10),
+		&ImportOptions{HeaderMode: "present", StartAtRow: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RowsInserted != 3 || result.LastRowProcessed != 10 {
+		t.Fatalf("inserted %d, last row %d; want 3, 10", result.RowsInserted, result.LastRowProcessed)
+	}
+	tbl, _ := db.Get("default", "items")
+	if tbl.Rows[0][0] != int64(8) {
+		t.Fatalf("first stored id = %v, want 8", tbl.Rows[0][0])
+	}
+}
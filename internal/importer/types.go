@@ -15,10 +15,36 @@ import (
 // Type Inference - Detect column types from sample data
 // ============================================================================
 
+// InferredColumnType reports what type inference saw in one column of the
+// sampled rows (at most ImportOptions.SampleRecords) and the type it chose.
+// Values matching NullLiterals count as NULL and do not vote. The counts let
+// callers override the decision with an explicitly typed table.
+type InferredColumnType struct {
+	Name         string
+	DetectedType string  // chosen tinySQL type, e.g. "INT" or "TEXT"
+	SampledRows  int     // rows inspected, NULLs included
+	NullCount    int     // sampled values treated as NULL
+	NullFraction float64 // NullCount / SampledRows, 0 for an empty sample
+	IntCount     int
+	FloatCount   int
+	BoolCount    int
+	TimeCount    int
+	TextCount    int
+}
+
 // inferColumnTypes analyzes sample data to determine the best tinySQL column type
 // for each column. It tries in order: BOOL → INT → FLOAT → TIME → TEXT.
 func inferColumnTypes(sampleData [][]string, numCols int, opts *ImportOptions) []storage.ColType {
+	types, _ := inferColumnReport(sampleData, make([]string, numCols), opts)
+	return types
+}
+
+// inferColumnReport is inferColumnTypes that also returns the per-column
+// vote counts behind each decision.
+func inferColumnReport(sampleData [][]string, colNames []string, opts *ImportOptions) ([]storage.ColType, []InferredColumnType) {
+	numCols := len(colNames)
 	types := make([]storage.ColType, numCols)
+	report := make([]InferredColumnType, numCols)
 
 	// Initialize type vote counters per column
 	votes := make([]map[storage.ColType]int, numCols)
@@ -36,6 +62,7 @@ func inferColumnTypes(sampleData [][]string, numCols int, opts *ImportOptions) [
 
 			// Skip null values in type inference
 			if isNullValue(val, opts.NullLiterals) {
+				report[colIdx].NullCount++
 				continue
 			}
 
@@ -48,9 +75,21 @@ func inferColumnTypes(sampleData [][]string, numCols int, opts *ImportOptions) [
 	// Determine final type for each column based on votes
 	for colIdx := 0; colIdx < numCols; colIdx++ {
 		types[colIdx] = determineColumnType(votes[colIdx])
+		r := &report[colIdx]
+		r.Name = colNames[colIdx]
+		r.DetectedType = types[colIdx].String()
+		r.SampledRows = len(sampleData)
+		if r.SampledRows > 0 {
+			r.NullFraction = float64(r.NullCount) / float64(r.SampledRows)
+		}
+		r.IntCount = votes[colIdx][storage.IntType]
+		r.FloatCount = votes[colIdx][storage.Float64Type]
+		r.BoolCount = votes[colIdx][storage.BoolType]
+		r.TimeCount = votes[colIdx][storage.TimeType]
+		r.TextCount = votes[colIdx][storage.TextType]
 	}
 
-	return types
+	return types, report
 }
 
 // detectValueType attempts to parse a single value and returns its most specific type.
@@ -0,0 +1,194 @@
+package lineedit
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Completer proposes completions for the word before the cursor. line is the
+// whole line and pos the cursor, both in runes. It returns where the word
+// starts and the candidates that may replace line[start:pos].
+type Completer func(line []rune, pos int) (start int, candidates []string)
+
+// sqlKeywords are offered where neither a table nor a column fits.
+var sqlKeywords = []string{
+	"ALTER", "AND", "AS", "ASC", "BEGIN", "BY", "COMMIT", "CREATE", "DELETE",
+	"DESC", "DESCRIBE", "DISTINCT", "DROP", "EXPLAIN", "FROM", "GROUP",
+	"HAVING", "INDEX", "INNER", "INSERT", "INTO", "JOIN", "LEFT", "LIMIT",
+	"NOT", "NULL", "OFFSET", "ON", "OR", "ORDER", "ROLLBACK", "SELECT", "SET",
+	"SHOW", "TABLE", "TABLES", "UNION", "UPDATE", "VALUES", "VIEW", "WHERE",
+	"WITH",
+}
+
+// SQLCompleter completes SQL by context: table names after FROM, JOIN,
+// UPDATE, INTO and TABLE; column names after SELECT, WHERE, SET, ON, BY and
+// the logical operators, and after "alias." or "table."; keywords
+// elsewhere. Aliases are read from the whole line, so "SELECT u.| FROM
+// users u" completes columns of users.
+type SQLCompleter struct {
+	// Tables lists the table names to offer.
+	Tables func() []string
+	// Columns lists the columns of table, or nil when it does not exist.
+	Columns func(table string) []string
+}
+
+// Complete implements Completer.
+func (c SQLCompleter) Complete(line []rune, pos int) (int, []string) {
+	start := pos
+	for start > 0 && isWordRune(line[start-1]) {
+		start--
+	}
+	word := string(line[start:pos])
+	prev := strings.ToUpper(previousWord(line[:start]))
+
+	switch prev {
+	case "FROM", "JOIN", "UPDATE", "INTO", "TABLE", "DESCRIBE":
+		return start, matchPrefix(c.tables(), word)
+	}
+	if dot := strings.LastIndexByte(word, '.'); dot >= 0 {
+		qualifier, prefix := word[:dot], word[dot+1:]
+		table := c.aliases(string(line))[strings.ToLower(qualifier)]
+		if table == "" {
+			table = qualifier
+		}
+		return start + len([]rune(word[:dot+1])), matchPrefix(c.columns(table), prefix)
+	}
+
+	switch prev {
+	case "SELECT", "WHERE", "SET", "ON", "BY", "AND", "OR", ",", "DISTINCT":
+		var cols []string
+		tables := c.referencedTables(string(line))
+		if len(tables) == 0 {
+			tables = c.tables()
+		}
+		for _, t := range tables {
+			cols = append(cols, c.columns(t)...)
+		}
+		if cand := matchPrefix(cols, word); len(cand) > 0 || word == "" {
+			return start, cand
+		}
+	}
+	if word == "" {
+		return start, nil
+	}
+	return start, matchPrefix(sqlKeywords, word)
+}
+
+func (c SQLCompleter) tables() []string {
+	if c.Tables == nil {
+		return nil
+	}
+	return c.Tables()
+}
+
+func (c SQLCompleter) columns(table string) []string {
+	if c.Columns == nil {
+		return nil
+	}
+	return c.Columns(table)
+}
+
+// aliases maps every lower-cased table name and alias that follows FROM,
+// JOIN, UPDATE or INTO in line to its table.
+func (c SQLCompleter) aliases(line string) map[string]string {
+	out := map[string]string{}
+	// Commas stay as words so "FROM a, b" does not read b as an alias.
+	words := strings.FieldsFunc(strings.ReplaceAll(line, ",", " , "), func(r rune) bool {
+		return r != ',' && !isWordRune(r)
+	})
+	for i := 0; i+1 < len(words); i++ {
+		switch strings.ToUpper(words[i]) {
+		case "FROM", "JOIN", "UPDATE", "INTO":
+		default:
+			continue
+		}
+		table := words[i+1]
+		out[strings.ToLower(table)] = table
+		j := i + 2
+		if j < len(words) && strings.EqualFold(words[j], "AS") {
+			j++
+		}
+		if j < len(words) && words[j] != "," && !isKeyword(words[j]) {
+			out[strings.ToLower(words[j])] = table
+		}
+	}
+	return out
+}
+
+// referencedTables returns the known tables named anywhere in line.
+func (c SQLCompleter) referencedTables(line string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, t := range c.aliases(line) {
+		if seen[strings.ToLower(t)] || c.columns(t) == nil {
+			continue
+		}
+		seen[strings.ToLower(t)] = true
+		out = append(out, t)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// matchPrefix returns the distinct names starting with prefix, ignoring
+// case, in sorted order.
+func matchPrefix(names []string, prefix string) []string {
+	lower := strings.ToLower(prefix)
+	seen := map[string]bool{}
+	var out []string
+	for _, n := range names {
+		if strings.HasPrefix(strings.ToLower(n), lower) && !seen[n] {
+			seen[n] = true
+			out = append(out, n)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// previousWord returns the word or comma before the trailing spaces of
+// head.
+func previousWord(head []rune) string {
+	end := len(head)
+	for end > 0 && unicode.IsSpace(head[end-1]) {
+		end--
+	}
+	if end > 0 && head[end-1] == ',' {
+		return ","
+	}
+	start := end
+	for start > 0 && isWordRune(head[start-1]) {
+		start--
+	}
+	return string(head[start:end])
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isKeyword(w string) bool {
+	up := strings.ToUpper(w)
+	for _, k := range sqlKeywords {
+		if k == up {
+			return true
+		}
+	}
+	return false
+}
+
+// commonPrefix returns the longest prefix all candidates share, ignoring
+// case and spelled as in the first candidate.
+func commonPrefix(candidates []string) []rune {
+	prefix := []rune(candidates[0])
+	for _, c := range candidates[1:] {
+		r := []rune(c)
+		n := 0
+		for n < len(prefix) && n < len(r) && unicode.ToLower(prefix[n]) == unicode.ToLower(r[n]) {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	return prefix
+}
@@ -0,0 +1,345 @@
+package lineedit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrInterrupt is returned by ReadLine when the user presses Ctrl-C.
+var ErrInterrupt = errors.New("interrupt")
+
+// Reader reads one line of input after showing prompt. At end of input it
+// returns io.EOF.
+type Reader interface {
+	ReadLine(prompt string) (string, error)
+}
+
+// Editor is the terminal line editor. It expects raw-mode input: every key
+// arrives as it is pressed and nothing is echoed, so Editor draws the line
+// itself. Supported keys:
+//
+//	Up, Down, Ctrl-P, Ctrl-N     previous / next history entry
+//	Left, Right, Ctrl-B, Ctrl-F  move by one character
+//	Home, End, Ctrl-A, Ctrl-E    beginning / end of line
+//	Ctrl-K, Ctrl-U               kill to end / beginning of line
+//	Backspace, Delete, Ctrl-D    delete; Ctrl-D on an empty line is EOF
+//	Ctrl-R                       reverse incremental search; Ctrl-G cancels
+//	Tab                          complete the word before the cursor
+//	Ctrl-C                       discard the line (ErrInterrupt)
+type Editor struct {
+	in       *bufio.Reader
+	out      io.Writer
+	history  *History
+	complete Completer
+	// raw switches the terminal to raw mode for one ReadLine and returns
+	// the function that restores it. Nil when in is not a terminal, as in
+	// tests that feed key sequences directly.
+	raw func() (restore func(), err error)
+}
+
+// NewEditor returns an Editor reading keys from in and drawing on out.
+// Entered lines are added to history and Tab asks complete; either may be
+// nil.
+func NewEditor(in io.Reader, out io.Writer, history *History, complete Completer) *Editor {
+	return &Editor{in: bufio.NewReader(in), out: out, history: history, complete: complete}
+}
+
+// lineState is the line being edited by one ReadLine call.
+type lineState struct {
+	prompt  string
+	buf     []rune
+	pos     int
+	entries []string
+	// histIdx is the history entry shown, len(entries) for the new line,
+	// whose text is kept in pending while browsing.
+	histIdx int
+	pending []rune
+	search  *searchState
+}
+
+// searchState is an active Ctrl-R search. match indexes entries and is -1
+// while the query matches nothing.
+type searchState struct {
+	query []rune
+	match int
+}
+
+func ctrl(c rune) rune { return c & 0x1f }
+
+// ReadLine shows prompt and returns the edited line without its newline.
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	if e.raw != nil {
+		restore, err := e.raw()
+		if err != nil {
+			return "", err
+		}
+		defer restore()
+	}
+	l := &lineState{prompt: prompt}
+	if e.history != nil {
+		l.entries = e.history.Entries()
+	}
+	l.histIdx = len(l.entries)
+	e.refresh(l)
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			if errors.Is(err, io.EOF) && len(l.buf) > 0 {
+				return e.accept(l), nil
+			}
+			return "", err
+		}
+		if l.search != nil && !e.searchKey(l, r) {
+			e.refresh(l)
+			continue
+		}
+		switch r {
+		case '\r', '\n':
+			return e.accept(l), nil
+		case ctrl('C'):
+			io.WriteString(e.out, "^C\r\n")
+			return "", ErrInterrupt
+		case ctrl('D'):
+			if len(l.buf) == 0 {
+				io.WriteString(e.out, "\r\n")
+				return "", io.EOF
+			}
+			l.deleteAt(l.pos)
+		case ctrl('A'):
+			l.pos = 0
+		case ctrl('E'):
+			l.pos = len(l.buf)
+		case ctrl('B'):
+			l.move(-1)
+		case ctrl('F'):
+			l.move(1)
+		case ctrl('K'):
+			l.buf = l.buf[:l.pos]
+		case ctrl('U'):
+			l.buf = append(l.buf[:0], l.buf[l.pos:]...)
+			l.pos = 0
+		case ctrl('P'):
+			l.recall(-1)
+		case ctrl('N'):
+			l.recall(1)
+		case ctrl('R'):
+			l.search = &searchState{match: -1}
+			l.findOlder(len(l.entries) - 1)
+		case '\t':
+			e.completeWord(l)
+		case ctrl('H'), 127:
+			if l.pos > 0 {
+				l.deleteAt(l.pos - 1)
+				l.pos--
+			}
+		case 27:
+			if err := e.escape(l); err != nil {
+				return "", err
+			}
+		default:
+			if r >= ' ' {
+				l.insert(r)
+			}
+		}
+		e.refresh(l)
+	}
+}
+
+// completeWord handles Tab. A single candidate replaces the word and is
+// followed by a space; several are first narrowed to their common prefix
+// and, when that adds nothing, listed below the line. No candidate rings
+// the bell.
+func (e *Editor) completeWord(l *lineState) {
+	if e.complete == nil {
+		io.WriteString(e.out, "\a")
+		return
+	}
+	start, candidates := e.complete(l.buf, l.pos)
+	switch {
+	case len(candidates) == 0:
+		io.WriteString(e.out, "\a")
+	case len(candidates) == 1:
+		word := []rune(candidates[0])
+		if l.pos == len(l.buf) || l.buf[l.pos] != ' ' {
+			word = append(word, ' ')
+		}
+		l.replace(start, word)
+	default:
+		if prefix := commonPrefix(candidates); len(prefix) > l.pos-start {
+			l.replace(start, prefix)
+			return
+		}
+		fmt.Fprintf(e.out, "\r\n%s\r\n", strings.Join(candidates, "  "))
+	}
+}
+
+// escape handles a CSI or SS3 sequence: arrows, Home, End and Delete.
+func (e *Editor) escape(l *lineState) error {
+	r, _, err := e.in.ReadRune()
+	if err != nil || (r != '[' && r != 'O') {
+		return err
+	}
+	var param strings.Builder
+	for {
+		if r, _, err = e.in.ReadRune(); err != nil {
+			return err
+		}
+		if r >= 0x40 && r <= 0x7e {
+			break
+		}
+		param.WriteRune(r)
+	}
+	switch r {
+	case 'A':
+		l.recall(-1)
+	case 'B':
+		l.recall(1)
+	case 'C':
+		l.move(1)
+	case 'D':
+		l.move(-1)
+	case 'H':
+		l.pos = 0
+	case 'F':
+		l.pos = len(l.buf)
+	case '~':
+		switch param.String() {
+		case "1", "7":
+			l.pos = 0
+		case "4", "8":
+			l.pos = len(l.buf)
+		case "3":
+			l.deleteAt(l.pos)
+		}
+	}
+	return nil
+}
+
+// searchKey handles r during a Ctrl-R search. It returns true when the
+// search has ended and r still needs normal handling, which is how keys
+// like Enter, arrows or Ctrl-A both accept the match and take effect.
+func (e *Editor) searchKey(l *lineState, r rune) bool {
+	s := l.search
+	switch {
+	case r == ctrl('R'):
+		if s.match > 0 {
+			l.findOlder(s.match - 1)
+		}
+		return false
+	case r == ctrl('G'):
+		l.search = nil
+		return false
+	case r == ctrl('H') || r == 127:
+		if len(s.query) > 0 {
+			s.query = s.query[:len(s.query)-1]
+			l.findOlder(len(l.entries) - 1)
+		}
+		return false
+	case r >= ' ':
+		s.query = append(s.query, r)
+		from := s.match
+		if from < 0 {
+			from = len(l.entries) - 1
+		}
+		l.findOlder(from)
+		return false
+	}
+	if s.match >= 0 {
+		l.buf = []rune(l.entries[s.match])
+		l.pos = len(l.buf)
+		l.histIdx = s.match
+	}
+	l.search = nil
+	return true
+}
+
+// findOlder points the search at the newest entry at or before from that
+// contains the query.
+func (l *lineState) findOlder(from int) {
+	q := string(l.search.query)
+	for i := from; i >= 0; i-- {
+		if strings.Contains(l.entries[i], q) {
+			l.search.match = i
+			return
+		}
+	}
+	l.search.match = -1
+}
+
+// recall moves dir steps through history, remembering the new line so that
+// moving back down past the newest entry restores it.
+func (l *lineState) recall(dir int) {
+	next := l.histIdx + dir
+	if next < 0 || next > len(l.entries) {
+		return
+	}
+	if l.histIdx == len(l.entries) {
+		l.pending = append(l.pending[:0], l.buf...)
+	}
+	l.histIdx = next
+	if next == len(l.entries) {
+		l.buf = append([]rune(nil), l.pending...)
+	} else {
+		l.buf = []rune(l.entries[next])
+	}
+	l.pos = len(l.buf)
+}
+
+func (l *lineState) move(d int) {
+	if p := l.pos + d; p >= 0 && p <= len(l.buf) {
+		l.pos = p
+	}
+}
+
+func (l *lineState) insert(r rune) {
+	l.buf = append(l.buf, 0)
+	copy(l.buf[l.pos+1:], l.buf[l.pos:])
+	l.buf[l.pos] = r
+	l.pos++
+}
+
+// replace swaps buf[start:pos] for word and moves the cursor past it.
+func (l *lineState) replace(start int, word []rune) {
+	tail := append([]rune(nil), l.buf[l.pos:]...)
+	l.buf = append(append(l.buf[:start], word...), tail...)
+	l.pos = start + len(word)
+}
+
+func (l *lineState) deleteAt(i int) {
+	if i >= 0 && i < len(l.buf) {
+		l.buf = append(l.buf[:i], l.buf[i+1:]...)
+	}
+}
+
+// accept ends the line and records it in history. History is a
+// convenience, so failing to save it does not fail the read.
+func (e *Editor) accept(l *lineState) string {
+	io.WriteString(e.out, "\r\n")
+	line := string(l.buf)
+	if e.history != nil {
+		e.history.Add(line)
+		_ = e.history.Save()
+	}
+	return line
+}
+
+// refresh redraws the prompt and line and places the cursor.
+func (e *Editor) refresh(l *lineState) {
+	if s := l.search; s != nil {
+		label, match := "reverse-i-search", ""
+		if s.match >= 0 {
+			match = l.entries[s.match]
+		} else if len(s.query) > 0 {
+			label = "failed reverse-i-search"
+		}
+		fmt.Fprintf(e.out, "\r(%s)`%s': %s\x1b[K", label, string(s.query), match)
+		return
+	}
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K", l.prompt, string(l.buf))
+	if back := len(l.buf) - l.pos; back > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
@@ -0,0 +1,99 @@
+// Package lineedit is the small line editor behind the interactive shells in
+// cmd/repl and cmd/tinysql: persistent history, arrow-key recall, the common
+// Emacs bindings and Ctrl-R reverse search on a terminal, and a plain line
+// scanner when stdin is a pipe or file.
+package lineedit
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultHistorySize is how many entries a History keeps when no other
+// limit is given.
+const DefaultHistorySize = 1000
+
+// History is an ordered list of entered lines, oldest first, optionally
+// backed by a file that holds one entry per line.
+type History struct {
+	path    string
+	max     int
+	entries []string
+}
+
+// DefaultHistoryPath returns ~/.config/tinysql/history, or "" when the home
+// directory is unknown.
+func DefaultHistoryPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return ""
+	}
+	return filepath.Join(home, ".config", "tinysql", "history")
+}
+
+// LoadHistory reads the history file at path, keeping the newest max
+// entries. A missing file is an empty history; an empty path keeps the
+// history in memory only.
+func LoadHistory(path string, max int) (*History, error) {
+	if max <= 0 {
+		max = DefaultHistorySize
+	}
+	h := &History{path: path, max: max}
+	if path == "" {
+		return h, nil
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 1024), 1024*1024)
+	for sc.Scan() {
+		h.Add(sc.Text())
+	}
+	return h, sc.Err()
+}
+
+// Add appends line unless it is blank or repeats the newest entry, then
+// drops the oldest entries beyond the limit.
+func (h *History) Add(line string) {
+	line = strings.TrimRight(line, "\r\n")
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if n := len(h.entries); n > 0 && h.entries[n-1] == line {
+		return
+	}
+	h.entries = append(h.entries, line)
+	if over := len(h.entries) - h.max; over > 0 {
+		h.entries = append(h.entries[:0], h.entries[over:]...)
+	}
+}
+
+// Entries returns the history, oldest first. The slice must not be
+// modified.
+func (h *History) Entries() []string { return h.entries }
+
+// Save writes the history file, creating its directory if needed. It is a
+// no-op for an in-memory history.
+func (h *History) Save() error {
+	if h.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o700); err != nil {
+		return err
+	}
+	var b strings.Builder
+	for _, e := range h.entries {
+		b.WriteString(e)
+		b.WriteByte('\n')
+	}
+	return os.WriteFile(h.path, []byte(b.String()), 0o600)
+}
@@ -0,0 +1,253 @@
+package lineedit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	keyUp    = "\x1b[A"
+	keyDown  = "\x1b[B"
+	keyLeft  = "\x1b[D"
+	keyHome  = "\x1b[H"
+	keyDel   = "\x1b[3~"
+	ctrlA    = "\x01"
+	ctrlE    = "\x05"
+	ctrlK    = "\x0b"
+	ctrlR    = "\x12"
+	ctrlC    = "\x03"
+	ctrlD    = "\x04"
+	ctrlG    = "\x07"
+	bksp     = "\x7f"
+	enterKey = "\r"
+)
+
+// readLines feeds keys to an Editor over history h and returns every line
+// read until the keys run out.
+func readLines(t *testing.T, h *History, keys string) []string {
+	t.Helper()
+	e := NewEditor(strings.NewReader(keys), io.Discard, h, nil)
+	var lines []string
+	for {
+		line, err := e.ReadLine("> ")
+		if errors.Is(err, io.EOF) {
+			return lines
+		}
+		if err != nil {
+			t.Fatalf("ReadLine: %v", err)
+		}
+		lines = append(lines, line)
+	}
+}
+
+func historyOf(entries ...string) *History {
+	h, _ := LoadHistory("", 0)
+	for _, e := range entries {
+		h.Add(e)
+	}
+	return h
+}
+
+func TestEditorRecallsHistoryWithArrows(t *testing.T) {
+	h := historyOf("SELECT 1;", "SELECT 2;")
+	got := readLines(t, h, keyUp+enterKey+keyUp+keyUp+keyUp+enterKey+"draft"+keyUp+keyDown+enterKey)
+	want := []string{"SELECT 2;", "SELECT 1;", "draft"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("lines = %q, want %q", got, want)
+	}
+}
+
+func TestEditorEditingKeys(t *testing.T) {
+	cases := []struct {
+		name, keys, want string
+	}{
+		{"Ctrl-A inserts at start", "ELECT" + ctrlA + "S" + enterKey, "SELECT"},
+		{"Ctrl-E returns to end", "SELECT" + ctrlA + ctrlE + " 1" + enterKey, "SELECT 1"},
+		{"Ctrl-K kills to end", "SELECT 1 FROM t" + ctrlA + "\x1b[C\x1b[C\x1b[C\x1b[C\x1b[C\x1b[C\x1b[C\x1b[C" + ctrlK + enterKey, "SELECT 1"},
+		{"Left and backspace", "SELECX" + bksp + "T 1" + keyLeft + keyLeft + "2" + enterKey, "SELECT2 1"},
+		{"Home and Delete", "xSELECT" + keyHome + keyDel + enterKey, "SELECT"},
+		{"Ctrl-D deletes under cursor", "SELECTT" + keyLeft + ctrlD + enterKey, "SELECT"},
+		{"multibyte runes", "grüße" + bksp + "e" + enterKey, "grüße"},
+	}
+	for _, tc := range cases {
+		got := readLines(t, nil, tc.keys)
+		if len(got) != 1 || got[0] != tc.want {
+			t.Errorf("%s: lines = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestEditorReverseSearch(t *testing.T) {
+	h := historyOf("SELECT * FROM users;", "INSERT INTO logs VALUES (1);", "SELECT * FROM orders;")
+	cases := []struct {
+		name, keys, want string
+	}{
+		{"newest match", ctrlR + "SELECT" + enterKey, "SELECT * FROM orders;"},
+		{"Ctrl-R again goes older", ctrlR + "SELECT" + ctrlR + enterKey, "SELECT * FROM users;"},
+		{"narrowed query", ctrlR + "logs" + enterKey, "INSERT INTO logs VALUES (1);"},
+		{"editing key accepts match", ctrlR + "users" + ctrlE + " -- x" + enterKey, "SELECT * FROM users; -- x"},
+		{"Ctrl-G cancels", "draft" + ctrlR + "users" + ctrlG + enterKey, "draft"},
+		{"no match keeps line", "draft" + ctrlR + "nothing" + enterKey, "draft"},
+	}
+	for _, tc := range cases {
+		got := readLines(t, historyOf(h.Entries()...), tc.keys)
+		if len(got) != 1 || got[0] != tc.want {
+			t.Errorf("%s: lines = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestEditorInterruptAndEOF(t *testing.T) {
+	e := NewEditor(strings.NewReader("SELECT"+ctrlC+ctrlD), io.Discard, nil, nil)
+	if _, err := e.ReadLine("> "); !errors.Is(err, ErrInterrupt) {
+		t.Fatalf("Ctrl-C err = %v, want ErrInterrupt", err)
+	}
+	if _, err := e.ReadLine("> "); !errors.Is(err, io.EOF) {
+		t.Fatalf("Ctrl-D on empty line err = %v, want io.EOF", err)
+	}
+}
+
+func TestHistoryDeduplicatesAndCaps(t *testing.T) {
+	h := historyOf("a", "a", "b", "  ", "a", "a")
+	if got := fmt.Sprint(h.Entries()); got != "[a b a]" {
+		t.Fatalf("entries = %s, want [a b a]", got)
+	}
+
+	h, _ = LoadHistory("", 3)
+	for i := 1; i <= 5; i++ {
+		h.Add(fmt.Sprint(i))
+	}
+	if got := fmt.Sprint(h.Entries()); got != "[3 4 5]" {
+		t.Fatalf("capped entries = %s, want [3 4 5]", got)
+	}
+
+	// Lines entered in the editor are deduplicated the same way.
+	h = historyOf()
+	readLines(t, h, "SELECT 1;"+enterKey+keyUp+enterKey+enterKey)
+	if got := fmt.Sprint(h.Entries()); got != "[SELECT 1;]" {
+		t.Fatalf("editor history = %q", h.Entries())
+	}
+}
+
+func TestHistoryPersistsAcrossSessions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tinysql", "history")
+	h, err := LoadHistory(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	readLines(t, h, "CREATE TABLE t (id INT);"+enterKey+"SELECT * FROM t;"+enterKey)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("history file not written: %v", err)
+	}
+	if string(data) != "CREATE TABLE t (id INT);\nSELECT * FROM t;\n" {
+		t.Fatalf("history file = %q", data)
+	}
+
+	next, err := LoadHistory(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readLines(t, next, keyUp+keyUp+enterKey); len(got) != 1 || got[0] != "CREATE TABLE t (id INT);" {
+		t.Fatalf("recalled in new session = %q", got)
+	}
+}
+
+func TestOpenFallsBackToScannerOffTerminal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.sql")
+	if err := os.WriteFile(path, []byte("SELECT 1;\n\x1b[A\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var out strings.Builder
+	in := Open(f, &out, historyOf("old"), nil)
+	if _, ok := in.(*Scanner); !ok {
+		t.Fatalf("Open on a file = %T, want *Scanner", in)
+	}
+	first, _ := in.ReadLine("sql> ")
+	second, _ := in.ReadLine("")
+	if _, err := in.ReadLine(""); !errors.Is(err, io.EOF) {
+		t.Fatalf("end of input err = %v, want io.EOF", err)
+	}
+	// Keys are not interpreted: the arrow escape is read back verbatim.
+	if first != "SELECT 1;" || second != keyUp || out.String() != "sql> " {
+		t.Fatalf("lines = %q, %q; prompts = %q", first, second, out.String())
+	}
+}
+
+func testCompleter() Completer {
+	cols := map[string][]string{
+		"users":  {"id", "name", "email"},
+		"orders": {"id", "user_id", "total"},
+	}
+	return SQLCompleter{
+		Tables:  func() []string { return []string{"users", "orders", "order_items"} },
+		Columns: func(table string) []string { return cols[strings.ToLower(table)] },
+	}.Complete
+}
+
+// completeKeys feeds keys to an Editor with testCompleter and returns the
+// entered line and everything drawn on the terminal.
+func completeKeys(t *testing.T, keys string) (string, string) {
+	t.Helper()
+	var out strings.Builder
+	e := NewEditor(strings.NewReader(keys), &out, nil, testCompleter())
+	line, err := e.ReadLine("> ")
+	if err != nil {
+		t.Fatalf("ReadLine(%q): %v", keys, err)
+	}
+	return line, out.String()
+}
+
+func TestEditorTabCompletion(t *testing.T) {
+	cases := []struct {
+		name, keys, want string
+	}{
+		{"keyword", "SEL\t", "SELECT "},
+		{"lower-case keyword", "sel\t* FROM users", "SELECT * FROM users"},
+		{"table after FROM", "SELECT * FROM use\t", "SELECT * FROM users "},
+		{"table after INSERT INTO", "INSERT INTO ord\t", "INSERT INTO order"},
+		{"table after UPDATE", "UPDATE u\t", "UPDATE users "},
+		{"table after DELETE FROM", "DELETE FROM us\t", "DELETE FROM users "},
+		{"table after JOIN", "SELECT * FROM users JOIN orders_\t", "SELECT * FROM users JOIN orders_"},
+		{"column after SELECT", "SELECT na\t", "SELECT name "},
+		{"column after alias dot", "SELECT o.tot FROM orders o" + strings.Repeat(keyLeft, 14) + "\t", "SELECT o.total FROM orders o"},
+		{"column after table dot", "SELECT users.em\t", "SELECT users.email "},
+		{"column in WHERE", "SELECT * FROM orders WHERE us\t", "SELECT * FROM orders WHERE user_id "},
+		{"mid-line", "SELECT * FROM us WHERE id = 1" + strings.Repeat(keyLeft, 13) + "\t", "SELECT * FROM users WHERE id = 1"},
+	}
+	for _, tc := range cases {
+		if got, _ := completeKeys(t, tc.keys+enterKey); got != tc.want {
+			t.Errorf("%s: line = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestEditorTabListsAmbiguousAndBeepsOnNone(t *testing.T) {
+	line, out := completeKeys(t, "SELECT u. FROM users u"+strings.Repeat(keyLeft, 13)+"\t"+enterKey)
+	if line != "SELECT u. FROM users u" {
+		t.Fatalf("line = %q", line)
+	}
+	if !strings.Contains(out, "\r\nemail  id  name\r\n") {
+		t.Fatalf("ambiguous completion did not list candidates: %q", out)
+	}
+	if !strings.Contains(out[strings.LastIndex(out, "email  id  name"):], "> SELECT u. FROM users u") {
+		t.Fatalf("prompt not redisplayed after the list: %q", out)
+	}
+
+	line, out = completeKeys(t, "SELECT * FROM zzz\t"+enterKey)
+	if line != "SELECT * FROM zzz" || !strings.Contains(out, "\a") {
+		t.Fatalf("no completion: line = %q, output = %q", line, out)
+	}
+}
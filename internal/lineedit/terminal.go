@@ -0,0 +1,56 @@
+package lineedit
+
+import (
+	"bufio"
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// Open returns an Editor when in is a terminal and a Scanner otherwise, so
+// piped scripts keep being read line by line. Prompts and the edited line
+// are drawn on out; history and complete may be nil.
+func Open(in *os.File, out io.Writer, history *History, complete Completer) Reader {
+	fd := int(in.Fd())
+	if !term.IsTerminal(fd) {
+		return NewScanner(in, out)
+	}
+	e := NewEditor(in, out, history, complete)
+	e.raw = func() (func(), error) {
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return nil, err
+		}
+		return func() { _ = term.Restore(fd, state) }, nil
+	}
+	return e
+}
+
+// Scanner is the non-terminal Reader: it prints the prompt, if any, and
+// reads the next line without editing or history.
+type Scanner struct {
+	sc  *bufio.Scanner
+	out io.Writer
+}
+
+// NewScanner returns a Scanner over in that accepts lines up to 10 MB.
+func NewScanner(in io.Reader, out io.Writer) *Scanner {
+	sc := bufio.NewScanner(in)
+	sc.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	return &Scanner{sc: sc, out: out}
+}
+
+// ReadLine implements Reader.
+func (s *Scanner) ReadLine(prompt string) (string, error) {
+	if prompt != "" {
+		io.WriteString(s.out, prompt)
+	}
+	if s.sc.Scan() {
+		return s.sc.Text(), nil
+	}
+	if err := s.sc.Err(); err != nil {
+		return "", err
+	}
+	return "", io.EOF
+}
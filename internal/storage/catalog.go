@@ -66,8 +66,9 @@ type CatalogManager struct {
 	jobs         map[string]*CatalogJob
 	jobRuns      []*CatalogJobHistory
 	nextRun      int64
-	triggers     map[string]*CatalogTrigger // keyed by trigger name
-	rbac         *rbacState                 // users/roles/grants; see rbac.go
+	triggers     map[string]*CatalogTrigger  // keyed by trigger name
+	sequences    map[string]*CatalogSequence // keyed by catalogIndexKey
+	rbac         *rbacState                  // users/roles/grants; see rbac.go
 }
 
 // NewCatalogManager allocates and returns an initialized CatalogManager.
@@ -84,6 +85,7 @@ func NewCatalogManager() *CatalogManager {
 		jobRuns:      make([]*CatalogJobHistory, 0),
 		nextRun:      1,
 		triggers:     make(map[string]*CatalogTrigger),
+		sequences:    make(map[string]*CatalogSequence),
 		rbac:         newRBACState(),
 	}
 }
@@ -207,6 +209,15 @@ type CatalogJob struct {
 	NextRunAt    *time.Time
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
+
+	// Retry policy: a failed run is retried up to MaxRetries times with an
+	// exponential backoff of RetryBackoffMs * 2^RetryCount. RetryCount is
+	// the current attempt and resets to 0 on success; once retries are
+	// exhausted the job is disabled and LastError holds the final error.
+	MaxRetries     int
+	RetryBackoffMs int
+	RetryCount     int
+	LastError      string
 }
 
 // CatalogJobHistory records one completed, failed, skipped, or canceled job run.
@@ -218,6 +229,19 @@ type CatalogJobHistory struct {
 	DurationMs   int64
 	Status       string // 'SUCCEEDED', 'FAILED', 'SKIPPED', 'CANCELED'
 	ErrorMessage string
+	RowsAffected int64
+}
+
+// JobHistoryEntry is one row of the scheduler's _job_history table, as
+// returned by GetJobHistory. Status is JobHistorySuccess, JobHistoryFailure
+// or JobHistorySkipped.
+type JobHistoryEntry struct {
+	JobName      string
+	StartedAt    time.Time
+	FinishedAt   time.Time
+	Status       string
+	ErrorMsg     string
+	RowsAffected int64
 }
 
 // ==================== Catalog Operations ====================
@@ -803,6 +827,50 @@ func (c *CatalogManager) ListJobHistory() []*CatalogJobHistory {
 	return runs
 }
 
+// GetJobHistory returns up to limit runs of the named job, newest first.
+// An empty name matches every job; a non-positive limit returns all runs.
+func (c *CatalogManager) GetJobHistory(name string, limit int) []JobHistoryEntry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	out := make([]JobHistoryEntry, 0)
+	for i := len(c.jobRuns) - 1; i >= 0; i-- {
+		run := c.jobRuns[i]
+		if name != "" && run.JobName != name {
+			continue
+		}
+		out = append(out, jobHistoryEntry(run))
+		if limit > 0 && len(out) >= limit {
+			break
+		}
+	}
+	return out
+}
+
+func jobHistoryEntry(run *CatalogJobHistory) JobHistoryEntry {
+	return JobHistoryEntry{
+		JobName:      run.JobName,
+		StartedAt:    run.StartedAt,
+		FinishedAt:   run.FinishedAt,
+		Status:       jobHistoryStatus(run.Status),
+		ErrorMsg:     run.ErrorMessage,
+		RowsAffected: run.RowsAffected,
+	}
+}
+
+// jobHistoryStatus maps catalog run statuses onto the coarser
+// success/failure vocabulary of the _job_history table.
+func jobHistoryStatus(status string) string {
+	switch status {
+	case "SUCCEEDED":
+		return JobHistorySuccess
+	case "SKIPPED":
+		return JobHistorySkipped
+	default:
+		return JobHistoryFailure
+	}
+}
+
 // GetTables returns a slice with metadata for all registered tables and
 // views.
 func (c *CatalogManager) GetTables() []*CatalogTable {
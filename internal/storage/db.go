@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/crc32"
 	"io"
 	"os"
 	"path/filepath"
@@ -326,6 +327,16 @@ type Column struct {
 	Constraint   ConstraintType
 	ForeignKey   *ForeignKeyRef // Only used if Constraint == ForeignKey
 	PointerTable string         // Target table for POINTER type
+	// DefaultExpr is the source text of a non-literal DEFAULT such as
+	// CURRENT_TIMESTAMP, evaluated for every inserted row. Literal defaults
+	// use DefaultValue instead. HasDefault is set for both.
+	DefaultExpr string
+	// Check is the source text of a column CHECK (expr) constraint. The
+	// engine parses it on first use; storage never evaluates it.
+	Check string
+	// FullText marks a text column declared FULLTEXT; MATCH ... AGAINST
+	// searches it through an inverted index the engine builds on demand.
+	FullText bool
 }
 
 // Table stores rows along with column metadata and indexes.
@@ -337,6 +348,11 @@ type Table struct {
 	// lower-case SQL index name. Unlike catalog metadata these entries are
 	// used by the executor and persisted with table snapshots.
 	Indexes map[string]*SecondaryIndex
+	// Uniques holds table-level UNIQUE (a, b, ...) constraints. Single-column
+	// uniqueness is recorded on the Column itself.
+	Uniques []UniqueConstraint
+	// Checks holds table-level CHECK constraints written after the columns.
+	Checks  []CheckClause
 	IsTemp  bool
 	colPos  map[string]int
 	Version int
@@ -350,10 +366,35 @@ type Table struct {
 	dirtyFrom int
 }
 
+// UniqueConstraint is a table-level UNIQUE constraint over several columns.
+// A row with NULL in any of the columns never conflicts with another row.
+type UniqueConstraint struct {
+	Columns []string
+}
+
+// CheckClause is a table-level CHECK constraint. Expr is the source text of
+// the condition; Name is empty unless declared with CONSTRAINT name.
+type CheckClause struct {
+	Name string
+	Expr string
+}
+
+func cloneUniqueConstraints(uniques []UniqueConstraint) []UniqueConstraint {
+	if uniques == nil {
+		return nil
+	}
+	out := make([]UniqueConstraint, len(uniques))
+	for i, u := range uniques {
+		out[i] = UniqueConstraint{Columns: append([]string(nil), u.Columns...)}
+	}
+	return out
+}
+
 // ColumnStats summarizes one column as of TableStats.AnalyzedAt. Min and Max
 // are display values for introspection; the planner currently uses row and
 // distinct counts, which remain meaningful across all supported column types.
 type ColumnStats struct {
+	TotalCount    int
 	NullCount     int
 	DistinctCount int
 	Min           string
@@ -389,7 +430,7 @@ func (t *Table) Analyze() *TableStats {
 		AnalyzedAt: time.Now().UTC(),
 	}
 	for colIdx, column := range t.Cols {
-		columnStats := ColumnStats{}
+		columnStats := ColumnStats{TotalCount: len(t.Rows)}
 		distinct := make(map[string]struct{})
 		var minValue, maxValue any
 		for _, row := range t.Rows {
@@ -418,6 +459,9 @@ func (t *Table) Analyze() *TableStats {
 	return cloneTableStats(stats)
 }
 
+// AnalyzeTable runs ANALYZE on t and returns the statistics it stored.
+func AnalyzeTable(t *Table) TableStats { return *t.Analyze() }
+
 // InvalidateStats marks the previous ANALYZE result stale after a mutation.
 // RowCount remains useful for observability while distinct/range values are
 // excluded from planner decisions until ANALYZE is run again.
@@ -533,6 +577,55 @@ func (t *Table) ColIndex(name string) (int, error) {
 	return i, nil
 }
 
+// AddColumn appends col to the schema and sets it to fill in every existing
+// row. A BLOB fill is copied per row so rows never share a slice.
+func (t *Table) AddColumn(col Column, fill any) error {
+	lc := strings.ToLower(col.Name)
+	if _, exists := t.colPos[lc]; exists {
+		return fmt.Errorf("column %q already exists on table %q", col.Name, t.Name)
+	}
+	for i := range t.Rows {
+		v := fill
+		if b, ok := v.([]byte); ok {
+			v = append([]byte(nil), b...)
+		}
+		t.Rows[i] = append(t.Rows[i], v)
+	}
+	t.Cols = append(t.Cols, col)
+	if t.colPos == nil {
+		t.colPos = make(map[string]int, len(t.Cols))
+	}
+	t.colPos[lc] = len(t.Cols) - 1
+	t.Version++
+	t.InvalidateStats()
+	t.MarkDirtyFrom(-1)
+	return nil
+}
+
+// DropColumn removes the named column from the schema and from every row.
+// Callers are responsible for rejecting columns that constraints or indexes
+// still depend on.
+func (t *Table) DropColumn(name string) error {
+	idx, err := t.ColIndex(name)
+	if err != nil {
+		return err
+	}
+	for i, row := range t.Rows {
+		if idx < len(row) {
+			t.Rows[i] = append(row[:idx:idx], row[idx+1:]...)
+		}
+	}
+	t.Cols = append(t.Cols[:idx:idx], t.Cols[idx+1:]...)
+	t.colPos = make(map[string]int, len(t.Cols))
+	for i, c := range t.Cols {
+		t.colPos[strings.ToLower(c.Name)] = i
+	}
+	t.Version++
+	t.InvalidateStats()
+	t.MarkDirtyFrom(-1)
+	return nil
+}
+
 type tenantDB struct {
 	tables map[string]*Table
 }
@@ -633,6 +726,11 @@ type DB struct {
 	// day) use this to guarantee cache/index stability: no write can invalidate
 	// vector index or column caches, and the WAL is never appended to.
 	readOnly atomic.Bool
+
+	// Row-level security policies keyed by tenant and table; see SetPolicy.
+	// Like extensions they are process state and are not persisted.
+	policyMu sync.RWMutex
+	policies map[string]map[string][]RowPolicy
 }
 
 // SetReadOnly toggles read-only mode. While enabled, the SQL engine rejects
@@ -942,8 +1040,12 @@ func loadGOBInto(db *DB, filename string) (bool, error) {
 	}
 	defer func() { _ = f.Close() }()
 
+	payload, err := snapshotPayload(f)
+	if err != nil {
+		return false, err
+	}
 	var dump []diskTable
-	var r io.Reader = bufio.NewReader(f)
+	var r io.Reader = bufio.NewReader(payload)
 	if strings.HasSuffix(strings.ToLower(filename), ".gz") {
 		gr, gzErr := gzip.NewReader(r)
 		if gzErr != nil {
@@ -991,7 +1093,11 @@ func ReadCheckpointWatermark(filename string) (uint64, error) {
 	}
 	defer func() { _ = f.Close() }()
 
-	var r io.Reader = bufio.NewReader(f)
+	payload, err := snapshotPayload(f)
+	if err != nil {
+		return 0, err
+	}
+	var r io.Reader = bufio.NewReader(payload)
 	if strings.HasSuffix(strings.ToLower(filename), ".gz") {
 		gr, gzErr := gzip.NewReader(r)
 		if gzErr != nil {
@@ -1035,10 +1141,24 @@ func (db *DB) getTenantRO(tn string) *tenantDB {
 	return db.tenants[strings.ToLower(tn)]
 }
 
+// canonicalTableName strips a default-schema qualifier, so main.users and
+// public.users name the same table as users. Any other qualifier is part of
+// the name: sales.orders lives in the sales schema, apart from orders.
+func canonicalTableName(name string) string {
+	if dot := strings.IndexByte(name, '.'); dot > 0 {
+		switch strings.ToLower(name[:dot]) {
+		case "main", "public":
+			return name[dot+1:]
+		}
+	}
+	return name
+}
+
 // Get returns a table by name for the given tenant.
 // When a StorageBackend is attached, tables not found in memory are loaded
 // from the backend on demand (lazy loading).
 func (db *DB) Get(tn, name string) (*Table, error) {
+	name = canonicalTableName(name)
 	t, found := func() (*Table, bool) {
 		db.mu.RLock()
 		defer db.mu.RUnlock()
@@ -1052,6 +1172,9 @@ func (db *DB) Get(tn, name string) (*Table, error) {
 	if found {
 		return t, nil
 	}
+	if t := db.informationSchemaTable(tn, name); t != nil {
+		return t, nil
+	}
 
 	// Not in memory – try the backend.
 	if db.backend != nil {
@@ -1088,6 +1211,17 @@ func (db *DB) Get(tn, name string) (*Table, error) {
 	return nil, db.noSuchTableError(tn, name)
 }
 
+// GetStats returns a copy of the latest ANALYZE result for a table, or nil
+// when the table has never been analyzed. Check Stale before relying on the
+// distinct counts: DML since the last ANALYZE invalidates them.
+func (db *DB) GetStats(tn, name string) (*TableStats, error) {
+	t, err := db.Get(tn, name)
+	if err != nil {
+		return nil, err
+	}
+	return t.Statistics(), nil
+}
+
 // noSuchTableError builds the "no such table" error for Get, adding a
 // "did you mean ...?" hint when an existing table name is a close typo
 // match. This is a plain edit-distance heuristic (see suggestSimilar), not
@@ -1137,6 +1271,7 @@ func (db *DB) Put(tn string, t *Table) error {
 	if db.IsReadOnly() {
 		return ErrReadOnlyStorage
 	}
+	t.Name = canonicalTableName(t.Name)
 	exists := func() bool {
 		db.mu.Lock()
 		defer db.mu.Unlock()
@@ -1170,6 +1305,7 @@ func (db *DB) Drop(tn, name string) error {
 	if db.IsReadOnly() {
 		return ErrReadOnlyStorage
 	}
+	name = canonicalTableName(name)
 	onDisk, found := func() (bool, bool) {
 		db.mu.Lock()
 		defer db.mu.Unlock()
@@ -1331,6 +1467,8 @@ func cloneTable(t *Table) *Table {
 	nt := NewTable(t.Name, cols, t.IsTemp)
 	nt.Version = t.Version
 	nt.Indexes = cloneSecondaryIndexes(t.Indexes)
+	nt.Uniques = cloneUniqueConstraints(t.Uniques)
+	nt.Checks = append([]CheckClause(nil), t.Checks...)
 	nt.Stats = cloneTableStats(t.Stats)
 	nt.dirtyFrom = t.dirtyFrom
 	nt.Rows = cloneRows(t.Rows)
@@ -1401,13 +1539,12 @@ func cloneCell(v any) any {
 // dramatically cheaper than DeepClone — O(rows in target table) instead of
 // O(rows in all tables).
 func (db *DB) ShallowCloneForTable(tenant, tableName string) *DB {
-	if len(db.tenants) == 0 {
-		return NewDB()
-	}
+	// An empty database still hands over its WAL: the first CREATE TABLE
+	// must be logged, and later writes go through the returned clone.
 	out := NewDB()
 	out.wal = db.wal
 	targetTenant := strings.ToLower(tenant)
-	targetKey := strings.ToLower(tableName)
+	targetKey := strings.ToLower(canonicalTableName(tableName))
 	for tn, tdb := range db.tenants {
 		for _, t := range tdb.tables {
 			key := strings.ToLower(t.Name)
@@ -1436,6 +1573,9 @@ type diskColumn struct {
 	Constraint   ConstraintType
 	ForeignKey   *ForeignKeyRef
 	PointerTable string
+	DefaultExpr  string
+	Check        string
+	FullText     bool
 }
 type diskTable struct {
 	Tenant  string
@@ -1445,6 +1585,8 @@ type diskTable struct {
 	IsTemp  bool
 	Version int
 	Indexes map[string]*SecondaryIndex
+	Uniques []UniqueConstraint
+	Checks  []CheckClause
 	Stats   *TableStats
 }
 
@@ -1460,6 +1602,7 @@ type diskCatalog struct {
 	JobRuns      []*CatalogJobHistory
 	NextRun      int64
 	Triggers     []*CatalogTrigger
+	Sequences    []*CatalogSequence
 }
 
 func catalogToDisk(c *CatalogManager) diskCatalog {
@@ -1481,6 +1624,7 @@ func catalogToDisk(c *CatalogManager) diskCatalog {
 		JobRuns:      make([]*CatalogJobHistory, 0, len(c.jobRuns)),
 		NextRun:      c.nextRun,
 		Triggers:     make([]*CatalogTrigger, 0, len(c.triggers)),
+		Sequences:    make([]*CatalogSequence, 0, len(c.sequences)),
 	}
 	if dc.NextRun == 0 {
 		dc.NextRun = 1
@@ -1529,6 +1673,10 @@ func catalogToDisk(c *CatalogManager) diskCatalog {
 		cp := *t
 		dc.Triggers = append(dc.Triggers, &cp)
 	}
+	for _, seq := range c.sequences {
+		cp := *seq
+		dc.Sequences = append(dc.Sequences, &cp)
+	}
 	return dc
 }
 
@@ -1620,6 +1768,13 @@ func diskToCatalog(dc diskCatalog) *CatalogManager {
 		cp := *t
 		c.triggers[cp.Name] = &cp
 	}
+	for _, seq := range dc.Sequences {
+		if seq == nil {
+			continue
+		}
+		cp := *seq
+		c.sequences[catalogIndexKey(cp.Tenant, cp.Schema, cp.Name)] = &cp
+	}
 	return c
 }
 
@@ -1709,6 +1864,8 @@ func tableToDiskRange(tn string, t *Table, from, to int) diskTable {
 		Cols:    make([]diskColumn, len(t.Cols)),
 		Rows:    make([][]any, to-from),
 		Indexes: cloneSecondaryIndexes(t.Indexes),
+		Uniques: cloneUniqueConstraints(t.Uniques),
+		Checks:  append([]CheckClause(nil), t.Checks...),
 		Stats:   cloneTableStats(t.Stats),
 	}
 	for i, c := range t.Cols {
@@ -1775,6 +1932,8 @@ func diskToTable(dt diskTable) *Table {
 	t := NewTable(dt.Name, cols, dt.IsTemp)
 	t.Version = dt.Version
 	t.Indexes = cloneSecondaryIndexes(dt.Indexes)
+	t.Uniques = cloneUniqueConstraints(dt.Uniques)
+	t.Checks = append([]CheckClause(nil), dt.Checks...)
 	t.Stats = cloneTableStats(dt.Stats)
 	t.Rows = make([][]any, len(dt.Rows))
 	for ri, r := range dt.Rows {
@@ -1822,15 +1981,19 @@ func diskToTable(dt diskTable) *Table {
 // The snapshot is written atomically: data goes to a temporary file in the
 // same directory, is fsynced, and is then renamed over the target. A crash
 // mid-checkpoint therefore never corrupts or truncates the previous snapshot.
-// SaveToFile writes an atomic snapshot of db (every table plus the catalog)
-// to filename, then gob-encodes each value in extra, in order, immediately
-// after — letting a caller persist small auxiliary state (e.g. a WAL
-// checkpoint's last-applied LSN/Seq watermark, see
+//
+// After the snapshot (every table plus the catalog), each value in extra is
+// gob-encoded in order, letting a caller persist small auxiliary state (e.g.
+// a WAL checkpoint's last-applied LSN/Seq watermark, see
 // AdvancedWAL.Checkpoint/WALManager.Checkpoint) atomically with the
 // snapshot itself, via the same temp-file-then-rename step, rather than a
 // separate file whose write could complete independently of this one and
-// leave the two inconsistent after a crash. Existing callers that pass no
-// extra values are unaffected; the file format is unchanged for them.
+// leave the two inconsistent after a crash.
+//
+// The file ends with a checksum trailer (see snapshot_checksum.go) over
+// everything before it, so LoadFromFile rejects a file damaged afterwards
+// with ErrCorruptSnapshot instead of decoding it. Files written before the
+// trailer existed have none and still load, unchecked.
 func SaveToFile(db *DB, filename string, extra ...any) error {
 	db.mu.RLock()
 	defer db.mu.RUnlock()
@@ -1861,7 +2024,10 @@ func SaveToFile(db *DB, filename string, extra ...any) error {
 		return err
 	}
 
-	bw := bufio.NewWriter(f)
+	// The checksum covers exactly the bytes written before the trailer.
+	sum := crc32.New(walCRCTable)
+	cw := &countingWriter{w: io.MultiWriter(f, sum)}
+	bw := bufio.NewWriter(cw)
 	var w io.Writer = bw
 	// Enable gzip compression based on file extension.
 	var gz *gzip.Writer
@@ -1889,6 +2055,9 @@ func SaveToFile(db *DB, filename string, extra ...any) error {
 	if err := bw.Flush(); err != nil {
 		return fail(err)
 	}
+	if err := writeSnapshotTrailer(f, cw.n, sum.Sum32()); err != nil {
+		return fail(err)
+	}
 	if err := f.Sync(); err != nil {
 		return fail(err)
 	}
@@ -1915,8 +2084,12 @@ func LoadFromFile(filename string) (*DB, error) {
 		return nil, err
 	}
 	defer func() { _ = f.Close() }()
+	payload, err := snapshotPayload(f)
+	if err != nil {
+		return nil, err
+	}
 	var dump []diskTable
-	var r io.Reader = bufio.NewReader(f)
+	var r io.Reader = bufio.NewReader(payload)
 	if strings.HasSuffix(strings.ToLower(filename), ".gz") {
 		gr, gzErr := gzip.NewReader(r)
 		if gzErr != nil {
@@ -2151,6 +2324,9 @@ func normalizeCheckpointMaxBytes(v int64) int64 {
 	return v
 }
 
+// ErrWALLocked is returned when another writer already holds the WAL open.
+var ErrWALLocked = errors.New("wal is locked by another writer")
+
 // WALManager encapsulates WAL append, recovery, and checkpoints.
 type WALManager struct {
 	mu                 sync.Mutex
@@ -2554,6 +2730,7 @@ func (db *DB) Evict(tenant, name string) error {
 // TableExists reports whether the named table exists, checking both in-memory
 // tables and the storage backend.
 func (db *DB) TableExists(tenant, name string) bool {
+	name = canonicalTableName(name)
 	db.mu.RLock()
 	td := db.getTenantRO(tenant)
 	if td != nil {
@@ -2641,15 +2818,22 @@ func OpenWAL(db *DB, cfg WALConfig) (*WALManager, error) {
 		basePath = strings.TrimSuffix(basePath, ".gz")
 	}
 	walPath := basePath + ".wal"
-	nextSeq, nextTxID, committed, truncated, err := replayWAL(db, walPath)
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil && !errors.Is(err, os.ErrExist) {
+		return nil, err
+	}
+	// Lock before replaying: recovery may truncate a torn tail, which must
+	// not race a writer that is still appending.
+	f, err := os.OpenFile(walPath, os.O_CREATE|os.O_RDWR, 0o644)
 	if err != nil {
 		return nil, err
 	}
-	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil && !errors.Is(err, os.ErrExist) {
+	if err := lockWALFile(f); err != nil {
+		_ = f.Close()
 		return nil, err
 	}
-	f, err := os.OpenFile(walPath, os.O_CREATE|os.O_RDWR, 0o644)
+	nextSeq, nextTxID, committed, truncated, err := replayWAL(db, walPath)
 	if err != nil {
+		_ = f.Close()
 		return nil, err
 	}
 	size, err := f.Seek(0, io.SeekEnd)
@@ -2770,22 +2954,14 @@ func (w *WALManager) Checkpoint(db *DB) error {
 	if err := w.flushSync(); err != nil {
 		return err
 	}
-	if err := w.file.Close(); err != nil {
+	// Truncate through the open handle so the writer lock is never dropped.
+	if err := w.file.Truncate(0); err != nil {
 		return err
 	}
-	if err := os.Truncate(w.path, 0); err != nil {
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
 		return err
 	}
-	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_RDWR, 0o644)
-	if err != nil {
-		return err
-	}
-	if _, err := f.Seek(0, io.SeekEnd); err != nil {
-		_ = f.Close()
-		return err
-	}
-	w.file = f
-	w.bytes = &countingWriter{w: f}
+	w.bytes = &countingWriter{w: w.file}
 	w.writer = bufio.NewWriter(w.bytes)
 	w.encoder = gob.NewEncoder(w.writer)
 	w.nextSeq = 1
@@ -2794,6 +2970,50 @@ func (w *WALManager) Checkpoint(db *DB) error {
 	return nil
 }
 
+// Sync flushes buffered WAL records and fsyncs the log file.
+func (w *WALManager) Sync() error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return fmt.Errorf("wal is closed")
+	}
+	return w.flushSync()
+}
+
+// CheckpointPath returns the snapshot file the WAL is compacted into.
+func (w *WALManager) CheckpointPath() string {
+	if w == nil {
+		return ""
+	}
+	return w.checkpointPath
+}
+
+// Size returns the current length of the WAL file in bytes.
+func (w *WALManager) Size() int64 {
+	if w == nil {
+		return 0
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.bytes.n
+}
+
+// CheckpointWAL compacts the WAL of db into its snapshot file at path and
+// empties the log. path must name the file the WAL was opened with.
+func CheckpointWAL(db *DB, path string) error {
+	w := db.WAL()
+	if w == nil {
+		return fmt.Errorf("checkpoint %s: database has no WAL attached", path)
+	}
+	if filepath.Clean(path) != filepath.Clean(w.checkpointPath) {
+		return fmt.Errorf("checkpoint %s: WAL belongs to %s", path, w.checkpointPath)
+	}
+	return w.Checkpoint(db)
+}
+
 // Close flushes, syncs, and closes the WAL resources.
 func (w *WALManager) Close() error {
 	if w == nil {
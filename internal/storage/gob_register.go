@@ -4,6 +4,7 @@ package storage
 
 import (
 	"math/big"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -21,4 +22,5 @@ func init() {
 	safeGobRegister(big.Rat{})
 	safeGobRegister(&big.Rat{})
 	safeGobRegister(uuid.UUID{})
+	safeGobRegister(time.Time{})
 }
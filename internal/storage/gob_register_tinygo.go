@@ -4,6 +4,7 @@ package storage
 
 import (
 	"math/big"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -19,4 +20,5 @@ func init() {
 	safeGobRegister([]any{})
 	safeGobRegister(big.Rat{})
 	safeGobRegister(uuid.UUID{})
+	safeGobRegister(time.Time{})
 }
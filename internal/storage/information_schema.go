@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// informationSchemaPrefix names the read-only SQL-standard metadata tables
+// every tenant can query:
+//
+//	information_schema.tables                  (table_name, table_schema, row_count, is_view)
+//	information_schema.columns                 (table_name, column_name, ordinal_position, data_type, is_nullable)
+//	information_schema.table_constraints       (constraint_name, table_name, constraint_type)
+//	information_schema.referential_constraints (constraint_name, table_name, referenced_table_name, referenced_column_name)
+//
+// They are never stored. Get builds a fresh snapshot of the tenant's schema
+// on each lookup, so they are always current and cost nothing until queried.
+// Constraint names follow PostgreSQL's defaults (users_pkey, users_email_key,
+// orders_user_id_fkey, ...) because the schema does not record names.
+const informationSchemaPrefix = "information_schema."
+
+// informationSchemaTable returns the named information_schema table for
+// tenant tn, or nil when name is not one.
+func (db *DB) informationSchemaTable(tn, name string) *Table {
+	lower := strings.ToLower(name)
+	if !strings.HasPrefix(lower, informationSchemaPrefix) {
+		return nil
+	}
+	var t *Table
+	switch strings.TrimPrefix(lower, informationSchemaPrefix) {
+	case "tables":
+		t = NewTable(lower, []Column{
+			{Name: "table_name", Type: TextType},
+			{Name: "table_schema", Type: TextType},
+			{Name: "row_count", Type: IntType},
+			{Name: "is_view", Type: BoolType},
+		}, false)
+		for _, src := range db.informationSchemaSources(tn) {
+			schema, table := splitSchemaName(src.Name)
+			t.Rows = append(t.Rows, []any{table, schema, len(src.Rows), false})
+		}
+		var views [][2]string // schema, name
+		for _, v := range db.Catalog().GetViews() {
+			views = append(views, [2]string{v.Schema, v.Name})
+		}
+		for _, mv := range db.Catalog().GetMaterializedViews() {
+			views = append(views, [2]string{mv.Schema, mv.Name})
+		}
+		sort.Slice(views, func(i, j int) bool {
+			if views[i][0] != views[j][0] {
+				return views[i][0] < views[j][0]
+			}
+			return views[i][1] < views[j][1]
+		})
+		for _, v := range views {
+			t.Rows = append(t.Rows, []any{v[1], v[0], nil, true})
+		}
+	case "columns":
+		t = NewTable(lower, []Column{
+			{Name: "table_name", Type: TextType},
+			{Name: "column_name", Type: TextType},
+			{Name: "ordinal_position", Type: IntType},
+			{Name: "data_type", Type: TextType},
+			{Name: "is_nullable", Type: TextType},
+		}, false)
+		for _, src := range db.informationSchemaSources(tn) {
+			_, table := splitSchemaName(src.Name)
+			for i, c := range src.Cols {
+				dataType := c.DeclaredType
+				if dataType == "" {
+					dataType = c.Type.String()
+				}
+				nullable := "YES"
+				if c.NotNull || c.Constraint == PrimaryKey {
+					nullable = "NO"
+				}
+				t.Rows = append(t.Rows, []any{table, c.Name, i + 1, dataType, nullable})
+			}
+		}
+	case "table_constraints":
+		t = NewTable(lower, []Column{
+			{Name: "constraint_name", Type: TextType},
+			{Name: "table_name", Type: TextType},
+			{Name: "constraint_type", Type: TextType},
+		}, false)
+		for _, src := range db.informationSchemaSources(tn) {
+			_, table := splitSchemaName(src.Name)
+			for _, c := range src.Cols {
+				switch c.Constraint {
+				case PrimaryKey:
+					t.Rows = append(t.Rows, []any{table + "_pkey", table, "PRIMARY KEY"})
+				case Unique:
+					t.Rows = append(t.Rows, []any{table + "_" + c.Name + "_key", table, "UNIQUE"})
+				case ForeignKey:
+					t.Rows = append(t.Rows, []any{table + "_" + c.Name + "_fkey", table, "FOREIGN KEY"})
+				}
+				if c.Check != "" {
+					t.Rows = append(t.Rows, []any{table + "_" + c.Name + "_check", table, "CHECK"})
+				}
+			}
+			for _, u := range src.Uniques {
+				t.Rows = append(t.Rows, []any{table + "_" + strings.Join(u.Columns, "_") + "_key", table, "UNIQUE"})
+			}
+			for i, chk := range src.Checks {
+				name := chk.Name
+				if name == "" {
+					name = fmt.Sprintf("%s_check%d", table, i+1)
+				}
+				t.Rows = append(t.Rows, []any{name, table, "CHECK"})
+			}
+		}
+	case "referential_constraints":
+		t = NewTable(lower, []Column{
+			{Name: "constraint_name", Type: TextType},
+			{Name: "table_name", Type: TextType},
+			{Name: "referenced_table_name", Type: TextType},
+			{Name: "referenced_column_name", Type: TextType},
+		}, false)
+		for _, src := range db.informationSchemaSources(tn) {
+			_, table := splitSchemaName(src.Name)
+			for _, c := range src.Cols {
+				if c.Constraint == ForeignKey && c.ForeignKey != nil {
+					t.Rows = append(t.Rows, []any{table + "_" + c.Name + "_fkey", table, c.ForeignKey.Table, c.ForeignKey.Column})
+				}
+			}
+		}
+	default:
+		return nil
+	}
+	return t
+}
+
+// informationSchemaSources lists tenant tn's user tables by name, leaving
+// out materialized-view caches, which surface as views instead.
+func (db *DB) informationSchemaSources(tn string) []*Table {
+	var out []*Table
+	for _, t := range db.ListTables(tn) {
+		if !strings.HasPrefix(strings.ToLower(t.Name), "__mv_") {
+			out = append(out, t)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return strings.ToLower(out[i].Name) < strings.ToLower(out[j].Name) })
+	return out
+}
+
+// splitSchemaName splits "schema.table" and defaults the schema to main.
+func splitSchemaName(name string) (schema, table string) {
+	if dot := strings.IndexByte(name, '.'); dot >= 0 {
+		return strings.ToLower(name[:dot]), name[dot+1:]
+	}
+	return "main", name
+}
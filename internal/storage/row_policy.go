@@ -0,0 +1,88 @@
+package storage
+
+import "strings"
+
+// RowPolicy is a row-level security rule for one table. Filter is a SQL
+// boolean expression that every row must satisfy to be visible to SELECT,
+// UPDATE and DELETE; the token $current_user in it stands for the acting
+// user (see engine.WithUser), and an empty Filter leaves every row visible.
+// The Can* flags say which kinds of DML the table accepts at all.
+//
+// Several policies on one table are AND-ed: a row must pass every filter
+// and a write must be allowed by every policy. Users listed in ExemptUsers
+// bypass the policy entirely, which is how an administrator sees all rows.
+type RowPolicy struct {
+	Filter      string
+	CanInsert   bool
+	CanUpdate   bool
+	CanDelete   bool
+	ExemptUsers []string
+}
+
+// Exempts reports whether user bypasses the policy.
+func (p RowPolicy) Exempts(user string) bool {
+	if user == "" {
+		return false
+	}
+	for _, u := range p.ExemptUsers {
+		if strings.EqualFold(u, user) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetPolicy adds a row-level security policy to tenant's table. Policies
+// live in memory only and must be set again after the database is reopened.
+func (db *DB) SetPolicy(tenant, table string, policy RowPolicy) {
+	if db == nil {
+		return
+	}
+	tn, tb := strings.ToLower(tenant), strings.ToLower(table)
+	policy.ExemptUsers = append([]string(nil), policy.ExemptUsers...)
+	db.policyMu.Lock()
+	defer db.policyMu.Unlock()
+	if db.policies == nil {
+		db.policies = map[string]map[string][]RowPolicy{}
+	}
+	if db.policies[tn] == nil {
+		db.policies[tn] = map[string][]RowPolicy{}
+	}
+	db.policies[tn][tb] = append(db.policies[tn][tb], policy)
+}
+
+// ClearPolicies removes every row-level security policy from tenant's table.
+func (db *DB) ClearPolicies(tenant, table string) {
+	if db == nil {
+		return
+	}
+	tn := strings.ToLower(tenant)
+	db.policyMu.Lock()
+	defer db.policyMu.Unlock()
+	delete(db.policies[tn], strings.ToLower(table))
+	if len(db.policies[tn]) == 0 {
+		delete(db.policies, tn)
+	}
+}
+
+// Policies returns a copy of the row-level security policies on tenant's
+// table, in the order they were added.
+func (db *DB) Policies(tenant, table string) []RowPolicy {
+	if db == nil {
+		return nil
+	}
+	db.policyMu.RLock()
+	defer db.policyMu.RUnlock()
+	return append([]RowPolicy(nil), db.policies[strings.ToLower(tenant)][strings.ToLower(table)]...)
+}
+
+// HasPolicies reports whether any table of tenant has a row-level security
+// policy. The engine uses it to skip its policy checks cheaply.
+func (db *DB) HasPolicies(tenant string) bool {
+	if db == nil {
+		return false
+	}
+	db.policyMu.RLock()
+	defer db.policyMu.RUnlock()
+	return len(db.policies[strings.ToLower(tenant)]) > 0
+}
@@ -21,6 +21,7 @@ type Scheduler struct {
 	mu          sync.RWMutex
 	running     map[string]*jobExecution // Track currently running jobs
 	cronEntries map[string]cron.EntryID
+	retries     map[string]*time.Timer // pending retry per job name
 	stopCh      chan struct{}
 	started     bool
 	wg          sync.WaitGroup
@@ -32,6 +33,29 @@ type JobExecutor interface {
 	ExecuteSQL(ctx context.Context, sql string) (interface{}, error)
 }
 
+// JobHistoryTable is the table the scheduler creates on Start and appends
+// one row to after every job run.
+const JobHistoryTable = "_job_history"
+
+// Values of the status column of JobHistoryTable.
+const (
+	JobHistorySuccess = "success"
+	JobHistoryFailure = "failure"
+	JobHistorySkipped = "skipped"
+)
+
+// tenantJobExecutor is implemented by executors bound to a single tenant;
+// the scheduler keeps JobHistoryTable in that tenant, else in "default".
+type tenantJobExecutor interface {
+	JobTenant() string
+}
+
+// rowsAffectedResult is implemented by executor results that know how many
+// rows the job's statement touched.
+type rowsAffectedResult interface {
+	RowsAffected() int64
+}
+
 // jobExecution tracks a running job instance
 type jobExecution struct {
 	startTime time.Time
@@ -47,6 +71,7 @@ func NewScheduler(db *DB, executor JobExecutor) *Scheduler {
 		cron:        cron.New(cron.WithLocation(loc), cron.WithSeconds()),
 		running:     make(map[string]*jobExecution),
 		cronEntries: make(map[string]cron.EntryID),
+		retries:     make(map[string]*time.Timer),
 		stopCh:      make(chan struct{}),
 		executor:    executor,
 	}
@@ -64,6 +89,10 @@ func (s *Scheduler) Start() error {
 		s.stopCh = make(chan struct{})
 	}
 
+	if err := s.ensureHistoryTable(); err != nil {
+		return fmt.Errorf("create %s: %w", JobHistoryTable, err)
+	}
+
 	// Register all enabled jobs
 	jobs := s.catalog.ListEnabledJobs()
 	for _, job := range jobs {
@@ -113,12 +142,15 @@ func (s *Scheduler) Stop() {
 	ctx := s.cron.Stop()
 	<-ctx.Done()
 
-	s.mu.RLock()
+	s.mu.Lock()
+	for name := range s.retries {
+		s.cancelRetryLocked(name)
+	}
 	running := make(map[string]*jobExecution, len(s.running))
 	for name, exec := range s.running {
 		running[name] = exec
 	}
-	s.mu.RUnlock()
+	s.mu.Unlock()
 
 	// Cancel all running jobs
 	for name, exec := range running {
@@ -201,6 +233,9 @@ func (s *Scheduler) scheduleCronJob(job *CatalogJob) error {
 
 	// Register with cron
 	id, err := s.cron.AddFunc(job.CronExpr, func() {
+		if s.retryPending(job.Name) {
+			return
+		}
 		s.executeJob(job)
 	})
 	if err == nil {
@@ -243,7 +278,7 @@ func (s *Scheduler) checkIntervalJobs(now time.Time) {
 			continue
 		}
 
-		if job.NextRunAt == nil {
+		if job.NextRunAt == nil || s.retryPending(job.Name) {
 			continue
 		}
 
@@ -306,6 +341,7 @@ func (s *Scheduler) executeJob(job *CatalogJob) {
 		defer s.wg.Done()
 		status := "SUCCEEDED"
 		errMsg := ""
+		var rowsAffected int64
 		defer func() {
 			ctxErr := ctx.Err()
 			cancel()
@@ -324,14 +360,20 @@ func (s *Scheduler) executeJob(job *CatalogJob) {
 				status = "CANCELED"
 				errMsg = ctxErr.Error()
 			}
-			if err := s.catalog.AddJobHistory(&CatalogJobHistory{
+			s.applyRetryPolicy(job, status, errMsg)
+			run := &CatalogJobHistory{
 				JobName:      job.Name,
 				StartedAt:    exec.startTime,
 				FinishedAt:   finishedAt,
 				DurationMs:   finishedAt.Sub(exec.startTime).Milliseconds(),
 				Status:       status,
 				ErrorMessage: errMsg,
-			}); err != nil {
+				RowsAffected: rowsAffected,
+			}
+			if err := s.appendHistoryRow(run); err != nil {
+				log.Printf("Failed to write %s for %q: %v", JobHistoryTable, job.Name, err)
+			}
+			if err := s.catalog.AddJobHistory(run); err != nil {
 				log.Printf("Failed to add job history for %q: %v", job.Name, err)
 			}
 		}()
@@ -354,11 +396,14 @@ func (s *Scheduler) executeJob(job *CatalogJob) {
 
 		// Execute SQL through executor interface
 		if s.executor != nil {
-			if _, err := s.executor.ExecuteSQL(ctx, job.SQLText); err != nil {
+			if res, err := s.executor.ExecuteSQL(ctx, job.SQLText); err != nil {
 				status = "FAILED"
 				errMsg = err.Error()
 				log.Printf("Job %q failed: %v", job.Name, err)
 			} else {
+				if ra, ok := res.(rowsAffectedResult); ok {
+					rowsAffected = ra.RowsAffected()
+				}
 				log.Printf("Job %q completed successfully", job.Name)
 			}
 		} else {
@@ -369,6 +414,139 @@ func (s *Scheduler) executeJob(job *CatalogJob) {
 	}()
 }
 
+func (s *Scheduler) historyTenant() string {
+	if te, ok := s.executor.(tenantJobExecutor); ok && te.JobTenant() != "" {
+		return te.JobTenant()
+	}
+	return "default"
+}
+
+// ensureHistoryTable creates JobHistoryTable in the history tenant unless it
+// already exists. A read-only database keeps no history, as in
+// appendHistoryRow.
+func (s *Scheduler) ensureHistoryTable() error {
+	if s.db.IsReadOnly() {
+		return nil
+	}
+	s.db.LockContentForWrite()
+	defer s.db.UnlockContentForWrite()
+	return s.ensureHistoryTableLocked(s.historyTenant())
+}
+
+func (s *Scheduler) ensureHistoryTableLocked(tenant string) error {
+	if s.db.TableExists(tenant, JobHistoryTable) {
+		return nil
+	}
+	return s.db.Put(tenant, NewTable(JobHistoryTable, []Column{
+		{Name: "job_name", Type: TextType},
+		{Name: "started_at", Type: TimestampType},
+		{Name: "finished_at", Type: TimestampType},
+		{Name: "status", Type: TextType},
+		{Name: "error_msg", Type: TextType},
+		{Name: "rows_affected", Type: Int64Type},
+	}, false))
+}
+
+// appendHistoryRow mirrors a finished run into JobHistoryTable so job
+// outcomes can be queried with plain SQL. The catalog's SUCCEEDED and FAILED
+// become JobHistorySuccess and JobHistoryFailure (see jobHistoryStatus).
+func (s *Scheduler) appendHistoryRow(run *CatalogJobHistory) error {
+	if s.db.IsReadOnly() {
+		return nil
+	}
+	s.db.LockContentForWrite()
+	defer s.db.UnlockContentForWrite()
+
+	tenant := s.historyTenant()
+	if err := s.ensureHistoryTableLocked(tenant); err != nil {
+		return err
+	}
+	t, err := s.db.Get(tenant, JobHistoryTable)
+	if err != nil {
+		return err
+	}
+	entry := jobHistoryEntry(run)
+	var errMsg any
+	if entry.ErrorMsg != "" {
+		errMsg = entry.ErrorMsg
+	}
+	t.Rows = append(t.Rows, []any{entry.JobName, entry.StartedAt, entry.FinishedAt, entry.Status, errMsg, entry.RowsAffected})
+	t.Version++
+	t.InvalidateStats()
+	t.MarkDirtyFrom(len(t.Rows) - 1)
+	return nil
+}
+
+// applyRetryPolicy updates a job's retry bookkeeping after a run. A FAILED
+// run of a job with MaxRetries > 0 schedules another attempt after
+// RetryBackoffMs * 2^RetryCount; once MaxRetries retries have failed the job
+// is disabled. A successful run resets RetryCount.
+func (s *Scheduler) applyRetryPolicy(job *CatalogJob, status, errMsg string) {
+	switch status {
+	case "SUCCEEDED":
+		job.RetryCount = 0
+		return
+	case "FAILED":
+	default:
+		return
+	}
+	job.LastError = errMsg
+	if job.MaxRetries <= 0 {
+		return
+	}
+	if job.RetryCount >= job.MaxRetries {
+		log.Printf("Job %q failed after %d retries, disabling", job.Name, job.RetryCount)
+		job.Enabled = false
+		job.RetryCount = 0
+		s.mu.Lock()
+		s.unscheduleJobLocked(job.Name)
+		s.mu.Unlock()
+		if err := s.catalog.RegisterJob(job); err != nil {
+			log.Printf("Failed to disable job %q: %v", job.Name, err)
+		}
+		return
+	}
+	job.RetryCount++
+	delay := time.Duration(job.RetryBackoffMs) * time.Millisecond << job.RetryCount
+	log.Printf("Job %q failed, retry %d/%d in %s", job.Name, job.RetryCount, job.MaxRetries, delay)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cancelRetryLocked(job.Name)
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		s.mu.Lock()
+		if s.retries[job.Name] != timer {
+			// Canceled or superseded while the timer was firing.
+			s.mu.Unlock()
+			return
+		}
+		delete(s.retries, job.Name)
+		s.mu.Unlock()
+		if _, err := s.catalog.GetJob(job.Name); err != nil {
+			return
+		}
+		s.executeJob(job)
+	})
+	s.retries[job.Name] = timer
+}
+
+// retryPending reports whether a retry is scheduled for the named job; the
+// regular schedule yields to it so a failing job is not run twice.
+func (s *Scheduler) retryPending(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.retries[name]
+	return ok
+}
+
+func (s *Scheduler) cancelRetryLocked(name string) {
+	if t, ok := s.retries[name]; ok {
+		t.Stop()
+		delete(s.retries, name)
+	}
+}
+
 // calculateNextRun computes the next execution time based on schedule type
 func (s *Scheduler) calculateNextRun(job *CatalogJob) {
 	now := time.Now()
@@ -447,6 +625,7 @@ func (s *Scheduler) RemoveJob(name string) error {
 	defer s.mu.Unlock()
 
 	s.unscheduleJobLocked(name)
+	s.cancelRetryLocked(name)
 
 	// Cancel if running
 	if exec, ok := s.running[name]; ok {
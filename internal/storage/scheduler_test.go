@@ -3,16 +3,18 @@ package storage
 import (
 	"context"
 	"errors"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 )
 
 type schedulerTestExecutor struct {
-	mu       sync.Mutex
-	calls    []string
-	err      error
-	panicMsg string
+	mu        sync.Mutex
+	calls     []string
+	err       error
+	failFirst int // fail this many calls with a transient error, then succeed
+	panicMsg  string
 }
 
 func (e *schedulerTestExecutor) ExecuteSQL(_ context.Context, sql string) (interface{}, error) {
@@ -25,6 +27,10 @@ func (e *schedulerTestExecutor) ExecuteSQL(_ context.Context, sql string) (inter
 	if e.err != nil {
 		return nil, e.err
 	}
+	if e.failFirst > 0 {
+		e.failFirst--
+		return nil, errors.New("transient")
+	}
 	return "ok", nil
 }
 
@@ -128,6 +134,37 @@ func TestSchedulerExecuteJobSuccessFailureAndSkipped(t *testing.T) {
 	if statuses["noexec"] != "SKIPPED" {
 		t.Fatalf("noexec status = %q", statuses["noexec"])
 	}
+	// _job_history uses its own vocabulary.
+	for job, want := range map[string]string{"success": JobHistorySuccess, "failing": JobHistoryFailure, "noexec": JobHistorySkipped} {
+		entries := db.Catalog().GetJobHistory(job, 1)
+		if len(entries) != 1 || entries[0].Status != want {
+			t.Fatalf("%s history = %+v, want status %q", job, entries, want)
+		}
+	}
+	if entries := db.Catalog().GetJobHistory("failing", 1); entries[0].ErrorMsg != "boom" {
+		t.Fatalf("failing history error = %q, want boom", entries[0].ErrorMsg)
+	}
+}
+
+// failingSaveBackend is a MemoryBackend whose SaveTable always fails.
+type failingSaveBackend struct{ *MemoryBackend }
+
+func (failingSaveBackend) SaveTable(string, *Table) error { return errors.New("disk full") }
+
+func TestSchedulerStartReportsHistoryTableError(t *testing.T) {
+	db := NewDB()
+	mb := NewMemoryBackend(t.TempDir() + "/db.gob")
+	mb.setDB(db)
+	db.backend = failingSaveBackend{mb}
+	s := NewScheduler(db, &schedulerTestExecutor{})
+	err := s.Start()
+	if err == nil {
+		s.Stop()
+		t.Fatal("Start succeeded without a history table")
+	}
+	if !strings.Contains(err.Error(), JobHistoryTable) || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("Start error = %v", err)
+	}
 }
 
 func TestSchedulerExecuteJobRecoversFromPanic(t *testing.T) {
@@ -296,3 +333,130 @@ func TestSchedulerCalculateNextRunCatchUpAndInvalidInterval(t *testing.T) {
 		t.Fatal("cron calculateNextRun did not set NextRunAt")
 	}
 }
+
+// waitForJobRuns polls the job history until name has at least n runs.
+func waitForJobRuns(t *testing.T, db *DB, name string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		count := 0
+		for _, run := range db.Catalog().ListJobHistory() {
+			if run.JobName == name {
+				count++
+			}
+		}
+		if count >= n {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("job %q did not reach %d runs in time", name, n)
+}
+
+func TestSchedulerRetriesFailingJobThenDisables(t *testing.T) {
+	db := NewDB()
+	exec := &schedulerTestExecutor{err: errors.New("boom")}
+	s := NewScheduler(db, exec)
+
+	job := &CatalogJob{Name: "flaky", SQLText: "SELECT bad", ScheduleType: "INTERVAL", IntervalMs: 3600000, Enabled: true, MaxRetries: 3, RetryBackoffMs: 1}
+	if err := db.Catalog().RegisterJob(job); err != nil {
+		t.Fatalf("RegisterJob failed: %v", err)
+	}
+	s.executeJob(job)
+
+	// One initial run plus exactly MaxRetries retries.
+	waitForJobRuns(t, db, "flaky", 4)
+	time.Sleep(50 * time.Millisecond)
+	s.wg.Wait()
+
+	if got := exec.callCount(); got != 4 {
+		t.Fatalf("executor calls = %d, want 4 (1 run + 3 retries)", got)
+	}
+	if s.retryPending("flaky") {
+		t.Fatal("no retry should be pending once retries are exhausted")
+	}
+	registered, err := db.Catalog().GetJob("flaky")
+	if err != nil {
+		t.Fatalf("GetJob failed: %v", err)
+	}
+	if registered.Enabled {
+		t.Fatal("job should be disabled after exhausting retries")
+	}
+	if registered.LastError != "boom" {
+		t.Fatalf("LastError = %q, want %q", registered.LastError, "boom")
+	}
+}
+
+func TestSchedulerRetryResetsOnSuccess(t *testing.T) {
+	db := NewDB()
+	exec := &schedulerTestExecutor{failFirst: 2}
+	s := NewScheduler(db, exec)
+
+	job := &CatalogJob{Name: "recovering", SQLText: "SELECT 1", ScheduleType: "INTERVAL", IntervalMs: 3600000, Enabled: true, MaxRetries: 5, RetryBackoffMs: 1}
+	if err := db.Catalog().RegisterJob(job); err != nil {
+		t.Fatalf("RegisterJob failed: %v", err)
+	}
+	s.executeJob(job)
+
+	waitForJobRuns(t, db, "recovering", 3)
+	time.Sleep(50 * time.Millisecond)
+	s.wg.Wait()
+
+	if got := exec.callCount(); got != 3 {
+		t.Fatalf("executor calls = %d, want 3 (2 failures + 1 success)", got)
+	}
+	if job.RetryCount != 0 {
+		t.Fatalf("RetryCount = %d, want 0 after success", job.RetryCount)
+	}
+	if !job.Enabled {
+		t.Fatal("job should stay enabled after a successful retry")
+	}
+}
+
+func TestSchedulerJobHistoryTable(t *testing.T) {
+	db := NewDB()
+	exec := &schedulerTestExecutor{}
+	s := NewScheduler(db, exec)
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer s.Stop()
+	if !db.TableExists("default", JobHistoryTable) {
+		t.Fatalf("Start did not create %s", JobHistoryTable)
+	}
+
+	job := &CatalogJob{Name: "hist", SQLText: "SELECT 1", ScheduleType: "INTERVAL", IntervalMs: 3600000, Enabled: true}
+	if err := db.Catalog().RegisterJob(job); err != nil {
+		t.Fatalf("RegisterJob failed: %v", err)
+	}
+	// s.wg also tracks the interval loop once started, so wait on the
+	// recorded history instead.
+	for i := 0; i < 3; i++ {
+		s.executeJob(job)
+		waitForJobRuns(t, db, "hist", i+1)
+	}
+
+	entries := db.Catalog().GetJobHistory("hist", 10)
+	if len(entries) != 3 {
+		t.Fatalf("GetJobHistory returned %d entries, want 3", len(entries))
+	}
+	for _, e := range entries {
+		if e.Status != "success" || e.JobName != "hist" {
+			t.Fatalf("unexpected entry: %+v", e)
+		}
+	}
+	if got := db.Catalog().GetJobHistory("hist", 2); len(got) != 2 {
+		t.Fatalf("limit 2 returned %d entries", len(got))
+	}
+
+	tbl, err := db.Get("default", JobHistoryTable)
+	if err != nil {
+		t.Fatalf("Get %s: %v", JobHistoryTable, err)
+	}
+	if len(tbl.Rows) != 3 {
+		t.Fatalf("%s has %d rows, want 3", JobHistoryTable, len(tbl.Rows))
+	}
+	if tbl.Rows[0][0] != "hist" || tbl.Rows[0][3] != "success" {
+		t.Fatalf("unexpected history row: %v", tbl.Rows[0])
+	}
+}
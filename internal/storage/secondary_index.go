@@ -70,7 +70,7 @@ func (t *Table) DropSecondaryIndex(name string) bool {
 // appended. skipRow is used by UPDATE to ignore a row's current key.
 func (t *Table) CheckSecondaryIndexConstraints(row []any, skipRow int) error {
 	for _, idx := range t.Indexes {
-		if !idx.Unique {
+		if !idx.Unique || t.indexKeyHasNull(idx.Columns, row) {
 			continue
 		}
 		key, err := t.indexKey(idx.Columns, row)
@@ -104,7 +104,7 @@ func (t *Table) RebuildSecondaryIndexes() error {
 				entries[mapKey] = entry
 			}
 			entry.RowIDs = append(entry.RowIDs, rowID)
-			if idx.Unique && len(entry.RowIDs) > 1 {
+			if idx.Unique && len(entry.RowIDs) > 1 && !t.indexKeyHasNull(idx.Columns, row) {
 				return fmt.Errorf("unique index %q: duplicate key", idx.Name)
 			}
 		}
@@ -189,6 +189,17 @@ func (t *Table) ClearSecondaryIndexes() {
 	}
 }
 
+// indexKeyHasNull reports whether any indexed column of row is NULL. As with
+// UNIQUE columns, such a key never conflicts with another row.
+func (t *Table) indexKeyHasNull(columns []string, row []any) bool {
+	for _, col := range columns {
+		if i, err := t.ColIndex(col); err == nil && i < len(row) && row[i] == nil {
+			return true
+		}
+	}
+	return false
+}
+
 type secondaryIndexRowKey struct {
 	index *SecondaryIndex
 	key   []byte
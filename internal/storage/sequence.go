@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// CatalogSequence is a named integer generator created by CREATE SEQUENCE.
+// Sequences are tenant-scoped like tables. Current is the value NEXTVAL
+// returned last and is only meaningful once Called is set, so the first
+// NEXTVAL returns Start.
+type CatalogSequence struct {
+	Tenant    string
+	Schema    string
+	Name      string
+	Start     int64
+	Increment int64
+	Min       int64
+	Max       int64
+	Cycle     bool
+	Current   int64
+	Called    bool
+	CreatedAt time.Time
+}
+
+// validate checks that the generator's bounds are consistent.
+func (s *CatalogSequence) validate() error {
+	switch {
+	case s.Increment == 0:
+		return fmt.Errorf("sequence %q: INCREMENT must not be zero", s.Name)
+	case s.Min >= s.Max:
+		return fmt.Errorf("sequence %q: MINVALUE (%d) must be less than MAXVALUE (%d)", s.Name, s.Min, s.Max)
+	case s.Start < s.Min || s.Start > s.Max:
+		return fmt.Errorf("sequence %q: START value %d is outside [%d, %d]", s.Name, s.Start, s.Min, s.Max)
+	}
+	return nil
+}
+
+// next returns the value following Current, wrapping to the opposite bound
+// when Cycle is set.
+func (s *CatalogSequence) next() (int64, error) {
+	if !s.Called {
+		return s.Start, nil
+	}
+	v := s.Current + s.Increment
+	wrapped := (s.Increment > 0) != (v > s.Current) // int64 overflow
+	if !wrapped && v >= s.Min && v <= s.Max {
+		return v, nil
+	}
+	switch {
+	case s.Cycle && s.Increment > 0:
+		return s.Min, nil
+	case s.Cycle:
+		return s.Max, nil
+	case s.Increment > 0:
+		return 0, fmt.Errorf("sequence %q reached its maximum value (%d)", s.Name, s.Max)
+	default:
+		return 0, fmt.Errorf("sequence %q reached its minimum value (%d)", s.Name, s.Min)
+	}
+}
+
+// CreateSequence registers seq for tenant. It fails if the sequence already
+// exists or its bounds are inconsistent.
+func (c *CatalogManager) CreateSequence(tenant string, seq *CatalogSequence) error {
+	if seq == nil || seq.Name == "" {
+		return fmt.Errorf("sequence name cannot be empty")
+	}
+	if err := seq.validate(); err != nil {
+		return err
+	}
+	if seq.Schema == "" {
+		seq.Schema = "main"
+	}
+	seq.Tenant = normalizeCatalogTenant(tenant)
+	key := catalogIndexKey(tenant, seq.Schema, seq.Name)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.sequences[key]; exists {
+		return fmt.Errorf("sequence %q already exists", seq.Name)
+	}
+	if seq.CreatedAt.IsZero() {
+		seq.CreatedAt = time.Now()
+	}
+	cp := *seq
+	c.sequences[key] = &cp
+	return nil
+}
+
+// DropSequence removes a sequence from tenant.
+func (c *CatalogManager) DropSequence(tenant, schema, name string) error {
+	key := catalogIndexKey(tenant, schema, name)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.sequences[key]; !ok {
+		return fmt.Errorf("sequence %q does not exist", name)
+	}
+	delete(c.sequences, key)
+	return nil
+}
+
+// GetSequence returns a copy of tenant's sequence.
+func (c *CatalogManager) GetSequence(tenant, schema, name string) (*CatalogSequence, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	seq, ok := c.sequences[catalogIndexKey(tenant, schema, name)]
+	if !ok {
+		return nil, false
+	}
+	cp := *seq
+	return &cp, true
+}
+
+// ListSequences returns copies of every sequence of tenant.
+func (c *CatalogManager) ListSequences(tenant string) []*CatalogSequence {
+	tenant = normalizeCatalogTenant(tenant)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]*CatalogSequence, 0, len(c.sequences))
+	for _, seq := range c.sequences {
+		if seq.Tenant == tenant {
+			cp := *seq
+			out = append(out, &cp)
+		}
+	}
+	return out
+}
+
+// NextSequenceValue advances tenant's sequence and returns the new value.
+func (c *CatalogManager) NextSequenceValue(tenant, schema, name string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	seq, ok := c.sequences[catalogIndexKey(tenant, schema, name)]
+	if !ok {
+		return 0, fmt.Errorf("sequence %q does not exist", name)
+	}
+	v, err := seq.next()
+	if err != nil {
+		return 0, err
+	}
+	seq.Current, seq.Called = v, true
+	return v, nil
+}
+
+// CurrentSequenceValue returns the value NEXTVAL returned last. It fails
+// before the first NEXTVAL.
+func (c *CatalogManager) CurrentSequenceValue(tenant, schema, name string) (int64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	seq, ok := c.sequences[catalogIndexKey(tenant, schema, name)]
+	if !ok {
+		return 0, fmt.Errorf("sequence %q does not exist", name)
+	}
+	if !seq.Called {
+		return 0, fmt.Errorf("CURRVAL of sequence %q is not yet defined; call NEXTVAL first", name)
+	}
+	return seq.Current, nil
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+)
+
+// SaveToFile ends every snapshot with a fixed-size trailer: an 8-byte magic,
+// the big-endian payload length (uint64) and the CRC32C of the payload
+// (uint32). The trailer sits after the payload so the checksum can be
+// computed while streaming; readers verify it before decoding anything.
+// Files written before the trailer existed have no magic and are read as-is.
+var snapshotTrailerMagic = []byte("TSQLCRC1")
+
+const snapshotTrailerSize = 8 + 8 + 4
+
+// ErrCorruptSnapshot is returned when a snapshot file fails its checksum or
+// is shorter than its trailer claims.
+var ErrCorruptSnapshot = errors.New("corrupt snapshot")
+
+func writeSnapshotTrailer(w io.Writer, n int64, sum uint32) error {
+	var trailer [snapshotTrailerSize]byte
+	copy(trailer[:8], snapshotTrailerMagic)
+	binary.BigEndian.PutUint64(trailer[8:16], uint64(n))
+	binary.BigEndian.PutUint32(trailer[16:], sum)
+	_, err := w.Write(trailer[:])
+	return err
+}
+
+// snapshotPayload verifies the checksum trailer of a snapshot file and
+// returns a reader over the payload alone, positioned at its start.
+func snapshotPayload(f *os.File) (io.Reader, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	if size < snapshotTrailerSize {
+		return io.NewSectionReader(f, 0, size), nil
+	}
+	var trailer [snapshotTrailerSize]byte
+	if _, err := f.ReadAt(trailer[:], size-snapshotTrailerSize); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(trailer[:8], snapshotTrailerMagic) {
+		return io.NewSectionReader(f, 0, size), nil
+	}
+	n := int64(binary.BigEndian.Uint64(trailer[8:16]))
+	if n != size-snapshotTrailerSize {
+		return nil, fmt.Errorf("%w %s: payload is %d bytes, trailer records %d", ErrCorruptSnapshot, f.Name(), size-snapshotTrailerSize, n)
+	}
+	h := crc32.New(walCRCTable)
+	if _, err := io.Copy(h, io.NewSectionReader(f, 0, n)); err != nil {
+		return nil, err
+	}
+	if got, want := h.Sum32(), binary.BigEndian.Uint32(trailer[16:]); got != want {
+		return nil, fmt.Errorf("%w %s: checksum %08x, want %08x", ErrCorruptSnapshot, f.Name(), got, want)
+	}
+	return io.NewSectionReader(f, 0, n), nil
+}
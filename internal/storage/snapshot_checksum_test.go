@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func snapshotTestDB(t *testing.T) *DB {
+	t.Helper()
+	db := NewDB()
+	table := NewTable("people", []Column{{Name: "id", Type: IntType}, {Name: "name", Type: TextType}}, false)
+	for i := 0; i < 50; i++ {
+		table.Rows = append(table.Rows, []any{i, "person"})
+	}
+	if err := db.Put("default", table); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func loadSnapshotRows(t *testing.T, path string) (int, error) {
+	t.Helper()
+	db, err := LoadFromFile(path)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+	table, err := db.Get("default", "people")
+	if err != nil {
+		return 0, err
+	}
+	return len(table.Rows), nil
+}
+
+func TestSaveToFileSurvivesInterruptedWrite(t *testing.T) {
+	for _, name := range []string{"db.gob", "db.gob.gz"} {
+		path := filepath.Join(t.TempDir(), name)
+		if err := SaveToFile(snapshotTestDB(t), path); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(path + ".tmp"); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("%s: temp file left behind: %v", name, err)
+		}
+
+		// A save that died mid-write leaves only a partial temp file; the
+		// previous snapshot is untouched and a later save replaces it.
+		if err := os.WriteFile(path+".tmp", []byte("partial snapshot"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if n, err := loadSnapshotRows(t, path); err != nil || n != 50 {
+			t.Fatalf("%s: load after interrupted save = %d rows, %v", name, n, err)
+		}
+		if err := SaveToFile(snapshotTestDB(t), path); err != nil {
+			t.Fatalf("%s: save over stale temp file: %v", name, err)
+		}
+		if _, err := os.Stat(path + ".tmp"); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("%s: stale temp file survived a save: %v", name, err)
+		}
+	}
+}
+
+func TestLoadFromFileDetectsCorruption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.gob")
+	if err := SaveToFile(snapshotTestDB(t), path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flipped := append([]byte(nil), data...)
+	flipped[len(flipped)/2] ^= 0xff
+	if err := os.WriteFile(path, flipped, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadSnapshotRows(t, path); !errors.Is(err, ErrCorruptSnapshot) {
+		t.Fatalf("flipped byte: error %v, want ErrCorruptSnapshot", err)
+	}
+	if _, err := ReadCheckpointWatermark(path); !errors.Is(err, ErrCorruptSnapshot) {
+		t.Fatalf("watermark of flipped file: error %v, want ErrCorruptSnapshot", err)
+	}
+
+	// Truncation loses the trailer; the incomplete payload still fails to
+	// decode instead of loading partially.
+	if err := os.WriteFile(path, data[:len(data)/2], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadSnapshotRows(t, path); err == nil {
+		t.Fatal("truncated snapshot loaded without error")
+	}
+
+	// Snapshots written before the trailer existed still load.
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveToWriter(snapshotTestDB(t), f); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := loadSnapshotRows(t, path); err != nil || n != 50 {
+		t.Fatalf("legacy snapshot = %d rows, %v", n, err)
+	}
+}
@@ -193,6 +193,8 @@ func restoreTable(dst, saved *Table) {
 	dst.Cols = copy.Cols
 	dst.Rows = copy.Rows
 	dst.Indexes = copy.Indexes
+	dst.Uniques = copy.Uniques
+	dst.Checks = copy.Checks
 	dst.IsTemp = copy.IsTemp
 	dst.colPos = copy.colPos
 	dst.Version = copy.Version
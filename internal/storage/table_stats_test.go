@@ -34,3 +34,32 @@ func TestTableStatsPersistAcrossSnapshot(t *testing.T) {
 		t.Fatalf("restored stats = %#v", stats)
 	}
 }
+
+func TestAnalyzeTableColumnStatistics(t *testing.T) {
+	db := NewDB()
+	table := NewTable("scores", []Column{{Name: "id", Type: IntType}, {Name: "score", Type: FloatType}, {Name: "name", Type: TextType}}, false)
+	table.Rows = [][]any{{1, 2.5, "b"}, {2, nil, "a"}, {3, 10.0, "b"}, {4, -1.0, nil}}
+	if err := db.Put("default", table); err != nil {
+		t.Fatal(err)
+	}
+	if stats, err := db.GetStats("default", "scores"); err != nil || stats != nil {
+		t.Fatalf("GetStats before ANALYZE = %#v, %v", stats, err)
+	}
+
+	stats := AnalyzeTable(table)
+	score := stats.Columns["score"]
+	if score.TotalCount != 4 || score.NullCount != 1 || score.DistinctCount != 3 || score.Min != "-1" || score.Max != "10" {
+		t.Fatalf("score stats = %#v", score)
+	}
+	if name := stats.Columns["name"]; name.NullCount != 1 || name.DistinctCount != 2 || name.Min != "a" || name.Max != "b" {
+		t.Fatalf("name stats = %#v", name)
+	}
+
+	stored, err := db.GetStats("default", "SCORES")
+	if err != nil || stored == nil || stored.RowCount != 4 || stored.Columns["id"].DistinctCount != 4 {
+		t.Fatalf("GetStats = %#v, %v", stored, err)
+	}
+	if _, err := db.GetStats("default", "missing"); err == nil {
+		t.Fatal("expected error for unknown table")
+	}
+}
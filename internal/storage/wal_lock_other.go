@@ -0,0 +1,9 @@
+//go:build !unix
+
+package storage
+
+import "os"
+
+// lockWALFile is a no-op where flock is unavailable; callers must ensure a
+// single writer themselves.
+func lockWALFile(*os.File) error { return nil }
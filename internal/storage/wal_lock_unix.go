@@ -0,0 +1,23 @@
+//go:build unix
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// lockWALFile takes a non-blocking exclusive advisory lock on the open WAL
+// file. The lock belongs to the open file description, so it is released when
+// the file is closed and a second open in the same process conflicts too.
+func lockWALFile(f *os.File) error {
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return fmt.Errorf("%w: %s", ErrWALLocked, f.Name())
+		}
+		return err
+	}
+	return nil
+}
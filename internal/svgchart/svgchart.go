@@ -0,0 +1,345 @@
+// Package svgchart draws the self-contained SVG bar and line charts used by
+// the HTML output of cmd/repl and the chart components of cmd/tinysqlpage.
+// Charts need no script or stylesheet: every colour and font is an
+// attribute, and the viewBox lets the browser scale them to the page.
+package svgchart
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Chart geometry in SVG user units. The plot area is plotHeight high and
+// grows by one slot per bar group or point, so the viewBox widens with the
+// data and the browser scales it to the page width.
+const (
+	marginLeft   = 64
+	marginRight  = 16
+	marginTop    = 16
+	marginBottom = 56
+	legendHeight = 20
+	plotHeight   = 200
+	slotWidth    = 56
+	minBarWidth  = 24
+	minWidth     = 320
+	axisColor    = "#6b7280"
+)
+
+// palette colours the series in order of appearance.
+var palette = []string{"#0b69ff", "#f97316", "#16a34a", "#dc2626", "#9333ea", "#0891b2"}
+
+// timeLayouts are the text formats ParseTime accepts.
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"}
+
+// Point is one value to chart.
+type Point struct {
+	// Label is the category on the x axis.
+	Label string
+	// Series groups points drawn in one colour; empty for single-series
+	// charts.
+	Series string
+	Value  float64
+	// Null marks a missing value: a gap in a bar chart, skipped by a line
+	// chart.
+	Null bool
+	// Time places the point proportionally on the x axis of a line chart
+	// when every point has one; Label is then not used.
+	Time time.Time
+}
+
+// Bar draws one bar per point, grouped by label: with several series every
+// label gets a group of bars side by side, one per series, and a legend.
+// The value axis always includes zero, so negative values hang below it.
+func Bar(xLabel, yLabel string, pts []Point) string {
+	if len(pts) == 0 {
+		return Placeholder()
+	}
+	series := seriesOf(pts)
+	// Each slot is one label's group; a label repeated within a series
+	// opens a new slot rather than overwriting the earlier bar.
+	var slots []*slot
+	current := map[string]*slot{}
+	for _, p := range pts {
+		s := current[p.Label]
+		if s == nil || s.taken(p.Series) {
+			s = &slot{label: p.Label, bars: map[string]Point{}}
+			slots, current[p.Label] = append(slots, s), s
+		}
+		s.bars[p.Series] = p
+	}
+
+	lo, hi := valueRange(pts, true)
+	slotW := math.Max(slotWidth, 16+minBarWidth*float64(len(series)))
+	c := newCanvas(len(slots), slotW, lo, hi, series)
+	c.axes(xLabel, yLabel)
+	zero := c.y(0)
+	barW := slotW * 0.7 / float64(len(series))
+	for i, s := range slots {
+		x := marginLeft + float64(i)*slotW
+		short := s.label
+		if r := []rune(s.label); len(r) > 10 {
+			// Keep neighbouring labels from overlapping; the bar's tooltip
+			// has the full text.
+			short = string(r[:9]) + "…"
+		}
+		c.xTick(x+slotW/2, short)
+		for j, name := range series {
+			p, ok := s.bars[name]
+			if !ok || p.Null {
+				continue
+			}
+			top, bottom := c.y(p.Value), zero
+			if top > bottom {
+				top, bottom = bottom, top
+			}
+			fmt.Fprintf(&c.b, "<rect class=\"bar\" x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" fill=\"%s\"><title>%s: %s</title></rect>\n",
+				num(x+slotW*0.15+float64(j)*barW), num(top), num(barW), num(bottom-top), palette[j%len(palette)],
+				html.EscapeString(tooltip(p.Label, p.Series)), num(p.Value))
+		}
+	}
+	return c.close()
+}
+
+// Line draws one line per series. When every point has a Time the x axis
+// is a time axis with points placed proportionally and sorted; otherwise
+// labels are spaced evenly in order of appearance. NULL values are
+// skipped.
+func Line(xLabel, yLabel string, pts []Point) string {
+	var valid []Point
+	timed := true
+	for _, p := range pts {
+		if !p.Null {
+			valid = append(valid, p)
+			timed = timed && !p.Time.IsZero()
+		}
+	}
+	if len(valid) == 0 {
+		return Placeholder()
+	}
+	series := seriesOf(valid)
+	lo, hi := valueRange(valid, false)
+
+	var x func(Point) float64
+	var c *canvas
+	if timed {
+		sort.SliceStable(valid, func(i, j int) bool { return valid[i].Time.Before(valid[j].Time) })
+		first, last := valid[0].Time, valid[len(valid)-1].Time
+		span := last.Sub(first)
+		c = newCanvas(len(valid), slotWidth, lo, hi, series)
+		plotW := c.width - marginLeft - marginRight
+		x = func(p Point) float64 {
+			if span <= 0 {
+				return marginLeft + plotW/2
+			}
+			return marginLeft + plotW*float64(p.Time.Sub(first))/float64(span)
+		}
+		layout := timeLayout(valid)
+		for i := range valid {
+			valid[i].Label = valid[i].Time.Format(layout)
+		}
+		c.axes(xLabel, yLabel)
+		c.xTick(x(valid[0]), valid[0].Label)
+		if span > 0 {
+			c.xTick(x(valid[len(valid)-1]), valid[len(valid)-1].Label)
+		}
+	} else {
+		index := map[string]int{}
+		var labels []string
+		for _, p := range valid {
+			if _, ok := index[p.Label]; !ok {
+				index[p.Label] = len(labels)
+				labels = append(labels, p.Label)
+			}
+		}
+		c = newCanvas(len(labels), slotWidth, lo, hi, series)
+		x = func(p Point) float64 {
+			return marginLeft + (float64(index[p.Label])+0.5)*slotWidth
+		}
+		c.axes(xLabel, yLabel)
+		for i, l := range labels {
+			c.xTick(marginLeft+(float64(i)+0.5)*slotWidth, l)
+		}
+	}
+
+	for j, name := range series {
+		color := palette[j%len(palette)]
+		var coords []string
+		var marks strings.Builder
+		for _, p := range valid {
+			if p.Series != name {
+				continue
+			}
+			coords = append(coords, num(x(p))+","+num(c.y(p.Value)))
+			fmt.Fprintf(&marks, "<circle class=\"point\" cx=\"%s\" cy=\"%s\" r=\"3\" fill=\"%s\"><title>%s: %s</title></circle>\n",
+				num(x(p)), num(c.y(p.Value)), color, html.EscapeString(tooltip(p.Label, p.Series)), num(p.Value))
+		}
+		fmt.Fprintf(&c.b, "<polyline class=\"line\" points=\"%s\" fill=\"none\" stroke=\"%s\" stroke-width=\"2\"/>\n", strings.Join(coords, " "), color)
+		c.b.WriteString(marks.String())
+	}
+	return c.close()
+}
+
+// Placeholder stands in for a chart with nothing to draw.
+func Placeholder() string {
+	return fmt.Sprintf("<svg xmlns=\"http://www.w3.org/2000/svg\" class=\"chart chart-empty\" viewBox=\"0 0 %d 80\" width=\"100%%\" font-family=\"sans-serif\" font-size=\"13\">\n"+
+		"<text class=\"placeholder\" x=\"%d\" y=\"44\" text-anchor=\"middle\" fill=\"%s\">No data to chart</text>\n</svg>",
+		minWidth, minWidth/2, axisColor)
+}
+
+// ParseTime reads v as a date or timestamp: a time.Time, or text in RFC
+// 3339, "YYYY-MM-DD HH:MM:SS" or "YYYY-MM-DD" form.
+func ParseTime(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case string:
+		for _, layout := range timeLayouts {
+			if p, err := time.Parse(layout, t); err == nil {
+				return p, true
+			}
+		}
+	case []byte:
+		return ParseTime(string(t))
+	}
+	return time.Time{}, false
+}
+
+// slot is one label's group of bars in a bar chart.
+type slot struct {
+	label string
+	bars  map[string]Point
+}
+
+func (s *slot) taken(series string) bool {
+	_, ok := s.bars[series]
+	return ok
+}
+
+// canvas accumulates the SVG markup of one chart.
+type canvas struct {
+	b      strings.Builder
+	width  float64
+	height float64
+	top    float64
+	lo, hi float64
+}
+
+// newCanvas starts a chart of slots slots of slotW units each. With more
+// than one series a legend row is added above the plot.
+func newCanvas(slots int, slotW, lo, hi float64, series []string) *canvas {
+	w := math.Max(minWidth, marginLeft+marginRight+float64(slots)*slotW)
+	c := &canvas{width: w, top: marginTop, lo: lo, hi: hi}
+	if len(series) > 1 {
+		c.top += legendHeight
+	}
+	c.height = c.top + plotHeight + marginBottom
+	fmt.Fprintf(&c.b, "<svg xmlns=\"http://www.w3.org/2000/svg\" class=\"chart\" viewBox=\"0 0 %s %s\" width=\"100%%\" preserveAspectRatio=\"xMinYMin meet\" font-family=\"sans-serif\" font-size=\"11\">\n",
+		num(c.width), num(c.height))
+	if len(series) > 1 {
+		x := float64(marginLeft)
+		for i, name := range series {
+			fmt.Fprintf(&c.b, "<rect class=\"legend-swatch\" x=\"%s\" y=\"8\" width=\"10\" height=\"10\" fill=\"%s\"/>\n", num(x), palette[i%len(palette)])
+			fmt.Fprintf(&c.b, "<text class=\"legend\" x=\"%s\" y=\"17\">%s</text>\n", num(x+14), html.EscapeString(name))
+			x += 14 + 7*float64(len([]rune(name))) + 16
+		}
+	}
+	return c
+}
+
+// y maps a value to its vertical position in the plot area.
+func (c *canvas) y(v float64) float64 {
+	return c.top + plotHeight*(c.hi-v)/(c.hi-c.lo)
+}
+
+// axes draws both axes, the y ticks at the range ends and zero, and the
+// axis titles. The x axis sits at zero when the range spans it.
+func (c *canvas) axes(xLabel, yLabel string) {
+	left, right := float64(marginLeft), c.width-marginRight
+	base := c.y(math.Max(c.lo, 0))
+	fmt.Fprintf(&c.b, "<line class=\"axis\" x1=\"%s\" y1=\"%s\" x2=\"%s\" y2=\"%s\" stroke=\"%s\"/>\n", num(left), num(c.top), num(left), num(c.top+plotHeight), axisColor)
+	fmt.Fprintf(&c.b, "<line class=\"axis\" x1=\"%s\" y1=\"%s\" x2=\"%s\" y2=\"%s\" stroke=\"%s\"/>\n", num(left), num(base), num(right), num(base), axisColor)
+	ticks := []float64{c.lo, c.hi}
+	if c.lo < 0 && c.hi > 0 {
+		ticks = append(ticks, 0)
+	}
+	for _, v := range ticks {
+		fmt.Fprintf(&c.b, "<text class=\"tick\" x=\"%s\" y=\"%s\" text-anchor=\"end\" dominant-baseline=\"middle\" fill=\"%s\">%s</text>\n",
+			num(left-6), num(c.y(v)), axisColor, num(v))
+	}
+	fmt.Fprintf(&c.b, "<text class=\"axis-label\" x=\"%s\" y=\"%s\" text-anchor=\"middle\" font-weight=\"bold\">%s</text>\n",
+		num((left+right)/2), num(c.height-8), html.EscapeString(xLabel))
+	fmt.Fprintf(&c.b, "<text class=\"axis-label\" transform=\"rotate(-90)\" x=\"%s\" y=\"14\" text-anchor=\"middle\" font-weight=\"bold\">%s</text>\n",
+		num(-(c.top + plotHeight/2)), html.EscapeString(yLabel))
+}
+
+// xTick writes a category or time label under the plot area at x.
+func (c *canvas) xTick(x float64, label string) {
+	fmt.Fprintf(&c.b, "<text class=\"tick\" x=\"%s\" y=\"%s\" text-anchor=\"middle\" fill=\"%s\">%s</text>\n",
+		num(x), num(c.top+plotHeight+16), axisColor, html.EscapeString(label))
+}
+
+func (c *canvas) close() string {
+	c.b.WriteString("</svg>")
+	return c.b.String()
+}
+
+// seriesOf returns the series names of pts in order of appearance.
+func seriesOf(pts []Point) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, p := range pts {
+		if !seen[p.Series] {
+			seen[p.Series] = true
+			out = append(out, p.Series)
+		}
+	}
+	return out
+}
+
+// valueRange returns the value range to plot. Bar charts always include
+// zero so bar lengths compare; a flat range is widened to stay drawable.
+func valueRange(pts []Point, withZero bool) (float64, float64) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, p := range pts {
+		if !p.Null {
+			lo, hi = math.Min(lo, p.Value), math.Max(hi, p.Value)
+		}
+	}
+	if math.IsInf(lo, 1) {
+		lo, hi = 0, 0
+	}
+	if withZero {
+		lo, hi = math.Min(lo, 0), math.Max(hi, 0)
+	}
+	if hi == lo {
+		hi = lo + 1
+	}
+	return lo, hi
+}
+
+// timeLayout formats time ticks as dates unless a point has a time of day.
+func timeLayout(pts []Point) string {
+	for _, p := range pts {
+		if !p.Time.Equal(p.Time.Truncate(24 * time.Hour)) {
+			return "2006-01-02 15:04"
+		}
+	}
+	return "2006-01-02"
+}
+
+func tooltip(label, series string) string {
+	if series == "" {
+		return label
+	}
+	return label + " (" + series + ")"
+}
+
+// num formats an SVG coordinate or tick value with at most two decimals.
+func num(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
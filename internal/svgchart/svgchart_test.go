@@ -0,0 +1,172 @@
+package svgchart
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// elements parses svg, failing unless it is well-formed XML with an <svg>
+// root, and returns the attributes of every element with class class.
+func elements(t *testing.T, svg, class string) []map[string]string {
+	t.Helper()
+	var out []map[string]string
+	dec := xml.NewDecoder(strings.NewReader(svg))
+	first := true
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return out
+		}
+		if err != nil {
+			t.Fatalf("invalid SVG: %v\n%s", err, svg)
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if first && se.Name.Local != "svg" {
+			t.Fatalf("root element = %s, want svg", se.Name.Local)
+		}
+		first = false
+		attrs := map[string]string{}
+		for _, a := range se.Attr {
+			attrs[a.Name.Local] = a.Value
+		}
+		if attrs["class"] == class {
+			out = append(out, attrs)
+		}
+	}
+}
+
+func TestBarPositions(t *testing.T) {
+	svg := Bar("k", "v", []Point{{Label: "a", Value: 10}, {Label: "b", Value: 20}})
+	bars := elements(t, svg, "bar")
+	if len(bars) != 2 {
+		t.Fatalf("bars = %d, want 2", len(bars))
+	}
+	// 0..20 over a 200-unit plot starting at y=16; 56-unit slots from x=64.
+	want := []map[string]string{
+		{"x": "72.4", "y": "116", "width": "39.2", "height": "100"},
+		{"x": "128.4", "y": "16", "width": "39.2", "height": "200"},
+	}
+	for i, w := range want {
+		for k, v := range w {
+			if bars[i][k] != v {
+				t.Errorf("bar %d %s = %s, want %s", i, k, bars[i][k], v)
+			}
+		}
+	}
+}
+
+func TestBarNegativeValuesHangBelowZero(t *testing.T) {
+	svg := Bar("k", "v", []Point{{Label: "loss", Value: -5}, {Label: "gain", Value: 15}})
+	bars := elements(t, svg, "bar")
+	// The range is -5..15, so zero sits at 16 + 200*15/20 = 166.
+	if bars[0]["y"] != "166" || bars[0]["height"] != "50" {
+		t.Errorf("negative bar = %v, want to start at zero and extend down", bars[0])
+	}
+	if bars[1]["y"] != "16" || bars[1]["height"] != "150" {
+		t.Errorf("positive bar = %v", bars[1])
+	}
+	axes := elements(t, svg, "axis")
+	if axes[1]["y1"] != "166" {
+		t.Errorf("x axis at y=%s, want at zero (166)", axes[1]["y1"])
+	}
+	if !strings.Contains(svg, ">-5</text>") || !strings.Contains(svg, ">0</text>") {
+		t.Errorf("value axis lacks the negative and zero ticks:\n%s", svg)
+	}
+}
+
+func TestBarGroupsSeries(t *testing.T) {
+	svg := Bar("quarter", "sales", []Point{
+		{Label: "Q1", Series: "north", Value: 10},
+		{Label: "Q1", Series: "south", Value: 20},
+		{Label: "Q2", Series: "north", Value: 5},
+		{Label: "Q2", Series: "south", Value: 15},
+	})
+	bars := elements(t, svg, "bar")
+	if len(bars) != 4 {
+		t.Fatalf("bars = %d, want two per label", len(bars))
+	}
+	// Two series widen the slot to 64 units, each bar 64*0.7/2 wide and
+	// the plot moves down by the legend row.
+	if bars[0]["x"] != "73.6" || bars[1]["x"] != "96" || bars[2]["x"] != "137.6" {
+		t.Errorf("bar x = %s, %s, %s; want groups side by side", bars[0]["x"], bars[1]["x"], bars[2]["x"])
+	}
+	if bars[1]["y"] != "36" || bars[1]["width"] != "22.4" {
+		t.Errorf("tallest bar = %v", bars[1])
+	}
+	if bars[0]["fill"] == bars[1]["fill"] || bars[0]["fill"] != bars[2]["fill"] {
+		t.Errorf("fills = %s %s %s, want one colour per series", bars[0]["fill"], bars[1]["fill"], bars[2]["fill"])
+	}
+	if n := len(elements(t, svg, "legend-swatch")); n != 2 || !strings.Contains(svg, ">south</text>") {
+		t.Errorf("legend swatches = %d, want one per series", n)
+	}
+	if strings.Count(svg, ">Q1</text>") != 1 {
+		t.Errorf("want one tick per label group:\n%s", svg)
+	}
+}
+
+func TestLinePositions(t *testing.T) {
+	svg := Line("step", "v", []Point{{Label: "a", Value: 1}, {Label: "b", Value: 3}, {Label: "c", Null: true}})
+	lines := elements(t, svg, "line")
+	if len(lines) != 1 || lines[0]["points"] != "92,216 148,16" {
+		t.Fatalf("polyline = %v, want labels spaced evenly", lines)
+	}
+	if n := len(elements(t, svg, "point")); n != 2 {
+		t.Errorf("points = %d, want NULL skipped", n)
+	}
+
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	svg = Line("day", "v", []Point{
+		{Time: day(5), Value: 2, Series: "b"},
+		{Time: day(1), Value: 1, Series: "a"},
+		{Time: day(3), Value: 2, Series: "a"},
+	})
+	lines = elements(t, svg, "line")
+	if len(lines) != 2 {
+		t.Fatalf("polylines = %d, want one per series", len(lines))
+	}
+	// Series keep their order of appearance. Three points give a 320-unit
+	// canvas; day 3 is halfway along its 240-unit plot.
+	if lines[0]["points"] != "304,36" || lines[1]["points"] != "64,236 184,36" {
+		t.Errorf("time series points = %q, %q", lines[0]["points"], lines[1]["points"])
+	}
+}
+
+func TestEmptyChartsShowPlaceholder(t *testing.T) {
+	for _, svg := range []string{
+		Bar("k", "v", nil),
+		Line("k", "v", nil),
+		Line("k", "v", []Point{{Label: "a", Null: true}}),
+	} {
+		elements(t, svg, "")
+		if !strings.Contains(svg, "No data to chart") {
+			t.Errorf("empty chart lacks placeholder: %s", svg)
+		}
+	}
+}
+
+func TestLabelsAreEscaped(t *testing.T) {
+	svg := Bar("<x>", "a&b", []Point{{Label: "<script>", Series: `"s"`, Value: 1}, {Label: "b", Series: "t", Value: 2}})
+	elements(t, svg, "bar")
+	if strings.Contains(svg, "<script>") {
+		t.Fatalf("label not escaped:\n%s", svg)
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	for _, v := range []any{"2024-01-02", "2024-01-02 03:04:05", "2024-01-02T03:04:05Z", []byte("2024-01-02"), time.Now()} {
+		if _, ok := ParseTime(v); !ok {
+			t.Errorf("ParseTime(%v) failed", v)
+		}
+	}
+	for _, v := range []any{"north", 42, nil} {
+		if _, ok := ParseTime(v); ok {
+			t.Errorf("ParseTime(%v) accepted a non-time", v)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewLoggerJSONFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(&buf, "info", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:  trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+	})
+	ctx := WithRequestID(trace.ContextWithSpanContext(context.Background(), sc), "req-1")
+	logger.InfoContext(ctx, "http request", "route", "/api/query", "status", 200)
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("not JSON: %v\n%s", err, buf.String())
+	}
+	want := map[string]any{
+		"level":      "INFO",
+		"msg":        "http request",
+		"route":      "/api/query",
+		"status":     float64(200),
+		"trace_id":   "4bf92f3577b34da6a3ce929d0e0e4736",
+		"span_id":    "00f067aa0ba902b7",
+		"request_id": "req-1",
+	}
+	for k, v := range want {
+		if line[k] != v {
+			t.Errorf("%s = %v, want %v", k, line[k], v)
+		}
+	}
+	if _, ok := line["time"]; !ok {
+		t.Errorf("missing time: %v", line)
+	}
+
+	// Without a request there is nothing to correlate on.
+	buf.Reset()
+	logger.Info("startup")
+	if strings.Contains(buf.String(), "trace_id") || strings.Contains(buf.String(), "request_id") {
+		t.Fatalf("correlation IDs outside a request: %s", buf.String())
+	}
+}
+
+func TestNewLoggerLevelFiltering(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(&buf, "WARN", "text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Debug("debug line")
+	logger.Info("info line")
+	logger.Warn("warn line")
+	logger.With("component", "test").Error("error line")
+	out := buf.String()
+	if strings.Contains(out, "debug line") || strings.Contains(out, "info line") {
+		t.Fatalf("lines below warn were logged:\n%s", out)
+	}
+	if !strings.Contains(out, `msg="warn line"`) || !strings.Contains(out, "component=test") {
+		t.Fatalf("missing warn/error lines:\n%s", out)
+	}
+}
+
+func TestNewLoggerRejectsUnknownSettings(t *testing.T) {
+	if _, err := NewLogger(&bytes.Buffer{}, "verbose", "text"); err == nil {
+		t.Fatal("unknown level accepted")
+	}
+	if _, err := NewLogger(&bytes.Buffer{}, "info", "xml"); err == nil {
+		t.Fatal("unknown format accepted")
+	}
+}
@@ -0,0 +1,88 @@
+// Package telemetry records operational data about executed statements for
+// the server and the command-line tools: the slow query log and the shared
+// log/slog setup.
+package telemetry
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// SlowQueryEntry is one record of the slow query log. It is written as a
+// single JSON line, so the log can be filtered with jq.
+type SlowQueryEntry struct {
+	SQL    string `json:"sql"`
+	Tenant string `json:"tenant"`
+	// Duration is encoded in nanoseconds under "duration_ns".
+	Duration  time.Duration `json:"duration_ns"`
+	Timestamp time.Time     `json:"timestamp"`
+	Rows      int           `json:"rows"`
+	// Error is set when the statement failed.
+	Error string `json:"error,omitempty"`
+}
+
+// SlowQueryLogger writes an entry for every statement that ran for at least
+// Threshold. A zero Threshold logs every statement. A nil logger, or one
+// without an Output, records nothing, so callers need not check whether
+// slow query logging is enabled. It is safe for concurrent use.
+type SlowQueryLogger struct {
+	Threshold time.Duration
+	Output    io.Writer
+
+	mu sync.Mutex
+}
+
+// OpenSlowQueryLog returns a logger appending to the file at path, creating
+// it when missing. Close releases the file.
+func OpenSlowQueryLog(path string, threshold time.Duration) (*SlowQueryLogger, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &SlowQueryLogger{Threshold: threshold, Output: f}, nil
+}
+
+// Record logs a statement that started at started and has just finished.
+// err may be nil; rows is the number of rows the statement returned.
+func (l *SlowQueryLogger) Record(sql, tenant string, started time.Time, rows int, err error) error {
+	if l == nil {
+		return nil
+	}
+	e := SlowQueryEntry{SQL: sql, Tenant: tenant, Duration: time.Since(started), Timestamp: started, Rows: rows}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	return l.Log(e)
+}
+
+// Log writes e when its duration reaches the threshold.
+func (l *SlowQueryLogger) Log(e SlowQueryEntry) error {
+	if l == nil || l.Output == nil || e.Duration < l.Threshold {
+		return nil
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.Output.Write(line)
+	return err
+}
+
+// Close closes the Output when it is an io.Closer.
+func (l *SlowQueryLogger) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if c, ok := l.Output.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
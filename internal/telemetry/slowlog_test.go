@@ -0,0 +1,121 @@
+package telemetry
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func decodeEntries(t *testing.T, data []byte) []SlowQueryEntry {
+	t.Helper()
+	var out []SlowQueryEntry
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		var e SlowQueryEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatalf("line %q is not JSON: %v", sc.Text(), err)
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+func TestSlowQueryLoggerSkipsFastQueries(t *testing.T) {
+	var buf bytes.Buffer
+	l := &SlowQueryLogger{Threshold: 500 * time.Millisecond, Output: &buf}
+	if err := l.Record("SELECT 1", "default", time.Now(), 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("fast query was logged: %s", buf.String())
+	}
+
+	var nilLogger *SlowQueryLogger
+	if err := nilLogger.Record("SELECT 1", "default", time.Now().Add(-time.Hour), 1, nil); err != nil {
+		t.Fatalf("nil logger: %v", err)
+	}
+}
+
+func TestSlowQueryLoggerWritesEntry(t *testing.T) {
+	var buf bytes.Buffer
+	l := &SlowQueryLogger{Threshold: 500 * time.Millisecond, Output: &buf}
+	started := time.Now().Add(-750 * time.Millisecond)
+	if err := l.Record("SELECT * FROM t", "acme", started, 42, errors.New("boom")); err != nil {
+		t.Fatal(err)
+	}
+	entries := decodeEntries(t, buf.Bytes())
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.SQL != "SELECT * FROM t" || e.Tenant != "acme" || e.Rows != 42 || e.Error != "boom" {
+		t.Fatalf("entry = %+v", e)
+	}
+	if e.Duration < 750*time.Millisecond || e.Duration > time.Minute {
+		t.Fatalf("duration = %v", e.Duration)
+	}
+	if !e.Timestamp.Equal(started) {
+		t.Fatalf("timestamp = %v, want %v", e.Timestamp, started)
+	}
+	if !strings.Contains(buf.String(), `"duration_ns":`) {
+		t.Fatalf("missing duration_ns field: %s", buf.String())
+	}
+}
+
+func TestOpenSlowQueryLogCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slow.log")
+	l, err := OpenSlowQueryLog(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Record("SELECT 1", "default", time.Now(), 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopening appends instead of truncating.
+	l, err = OpenSlowQueryLog(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = l.Record("SELECT 2", "default", time.Now(), 1, nil)
+	_ = l.Close()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries := decodeEntries(t, data); len(entries) != 2 || entries[1].SQL != "SELECT 2" {
+		t.Fatalf("entries = %+v", entries)
+	}
+}
+
+func TestSlowQueryLoggerConcurrentWrites(t *testing.T) {
+	var buf bytes.Buffer
+	l := &SlowQueryLogger{Output: &buf}
+	const n = 64
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sql := fmt.Sprintf("SELECT %d, '%s'", i, strings.Repeat("x", 512))
+			if err := l.Record(sql, "default", time.Now(), i, nil); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if entries := decodeEntries(t, buf.Bytes()); len(entries) != n {
+		t.Fatalf("got %d entries, want %d", len(entries), n)
+	}
+}
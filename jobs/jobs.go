@@ -24,6 +24,11 @@ type Config struct {
 	CatchUp      bool
 	NoOverlap    bool
 	MaxRuntimeMs int64
+
+	// MaxRetries and RetryBackoffMs configure retries of failed runs; see
+	// tinysql.CatalogJob.
+	MaxRetries     int
+	RetryBackoffMs int
 }
 
 // Build validates cfg and returns a CatalogJob.
@@ -40,6 +45,9 @@ func Build(cfg Config) (*tinysql.CatalogJob, error) {
 	if sqlText == "" {
 		return nil, fmt.Errorf("job sql is required")
 	}
+	if cfg.MaxRetries < 0 || cfg.RetryBackoffMs < 0 {
+		return nil, fmt.Errorf("retry settings must not be negative")
+	}
 	maxRuntimeMs := cfg.MaxRuntimeMs
 	if maxRuntimeMs <= 0 {
 		maxRuntimeMs = defaultMaxRuntimeMs
@@ -58,6 +66,9 @@ func Build(cfg Config) (*tinysql.CatalogJob, error) {
 		MaxRuntimeMs: maxRuntimeMs,
 		CreatedAt:    now,
 		UpdatedAt:    now,
+
+		MaxRetries:     cfg.MaxRetries,
+		RetryBackoffMs: cfg.RetryBackoffMs,
 	}
 	switch scheduleType {
 	case "INTERVAL":
@@ -18,6 +18,8 @@ const (
 	KindExplain                StatementKind = "explain"
 	KindAnalyze                StatementKind = "analyze"
 	KindPragma                 StatementKind = "pragma"
+	KindShowTables             StatementKind = "show_tables"
+	KindShowColumns            StatementKind = "show_columns"
 	KindInsert                 StatementKind = "insert"
 	KindUpdate                 StatementKind = "update"
 	KindDelete                 StatementKind = "delete"
@@ -33,6 +35,8 @@ const (
 	KindAlter                  StatementKind = "alter"
 	KindCreateTrigger          StatementKind = "create_trigger"
 	KindDropTrigger            StatementKind = "drop_trigger"
+	KindCreateSequence         StatementKind = "create_sequence"
+	KindDropSequence           StatementKind = "drop_sequence"
 	KindCreateJob              StatementKind = "create_job"
 	KindAlterJob               StatementKind = "alter_job"
 	KindDropJob                StatementKind = "drop_job"
@@ -70,6 +74,10 @@ func AnalyzeStatement(stmt engine.Statement) Analysis {
 		return Analysis{Kind: KindAnalyze, ObjectName: s.Table, Mutation: true, ResultProducing: true}
 	case *engine.Pragma:
 		return Analysis{Kind: KindPragma, ReadOnly: true, ResultProducing: true}
+	case *engine.ShowTables:
+		return Analysis{Kind: KindShowTables, ReadOnly: true, ResultProducing: true}
+	case *engine.ShowColumns:
+		return Analysis{Kind: KindShowColumns, ObjectName: s.Table, ReadOnly: true, ResultProducing: true}
 	case *engine.Insert:
 		return Analysis{Kind: KindInsert, ObjectName: s.Table, Mutation: true}
 	case *engine.Update:
@@ -102,6 +110,10 @@ func AnalyzeStatement(stmt engine.Statement) Analysis {
 		return Analysis{Kind: KindCreateTrigger, ObjectName: s.Name, DDL: true}
 	case *engine.DropTrigger:
 		return Analysis{Kind: KindDropTrigger, ObjectName: s.Name, DDL: true}
+	case *engine.CreateSequence:
+		return Analysis{Kind: KindCreateSequence, ObjectName: s.Name, DDL: true}
+	case *engine.DropSequence:
+		return Analysis{Kind: KindDropSequence, ObjectName: s.Name, DDL: true}
 	case *engine.CreateJob:
 		return Analysis{Kind: KindCreateJob, ObjectName: s.Name, DDL: true, Job: true}
 	case *engine.AlterJob:
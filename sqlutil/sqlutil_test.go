@@ -15,6 +15,8 @@ func TestAnalyzeClassifiesCommonStatements(t *testing.T) {
 	}{
 		{"SELECT * FROM users", KindSelect, true, true},
 		{"EXPLAIN SELECT * FROM users", KindExplain, true, true},
+		{"SHOW TABLES LIKE 'u%'", KindShowTables, true, true},
+		{"DESCRIBE users", KindShowColumns, true, true},
 		{"INSERT INTO users VALUES (1)", KindInsert, false, false},
 		{"CREATE VIEW v AS SELECT 1", KindCreateView, false, false},
 	}
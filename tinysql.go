@@ -68,8 +68,11 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"path/filepath"
 	"strings"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/SimonWaldherr/tinySQL/internal/engine"
 	"github.com/SimonWaldherr/tinySQL/internal/importer"
 	"github.com/SimonWaldherr/tinySQL/internal/storage"
@@ -84,6 +87,11 @@ import (
 // Use NewDB to create a new instance.
 type DB = storage.DB
 
+// DBSnapshot is a frozen point-in-time copy of a DB taken with DB.Snapshot.
+// Restore returns a new DB with that state; MarshalBinary and
+// UnmarshalBinary persist it outside the process.
+type DBSnapshot = storage.DBSnapshot
+
 // Table represents a database table with columns and rows.
 // Tables are created via CREATE TABLE statements and accessed through the DB.
 type Table = storage.Table
@@ -109,6 +117,26 @@ type Row = engine.Row
 // Returned by SELECT queries and available for inspection.
 type ResultSet = engine.ResultSet
 
+// AnalyzedPlan is the measured plan returned by ExplainAnalyze.
+type AnalyzedPlan = engine.AnalyzedPlan
+
+// AnalyzedStep is one operation of an AnalyzedPlan with its estimated and
+// actual row counts and elapsed time.
+type AnalyzedStep = engine.AnalyzedStep
+
+// QueryOptions bounds the memory, result rows and WITH RECURSIVE depth of
+// one statement run with ExecuteWithOptions. Zero fields are unlimited,
+// except MaxRecursionDepth, which then defaults to DefaultMaxRecursionDepth.
+type QueryOptions = engine.QueryOptions
+
+// DefaultMaxRecursionDepth is the WITH RECURSIVE iteration cap used when
+// QueryOptions.MaxRecursionDepth is zero.
+const DefaultMaxRecursionDepth = engine.DefaultMaxRecursionDepth
+
+// MonthInterval is the result value of INTERVAL n MONTH / INTERVAL n YEAR.
+// Shorter intervals evaluate to time.Duration.
+type MonthInterval = engine.MonthInterval
+
 // VectorCacheConfig configures the optional process-wide VEC_SEARCH result
 // cache and its opt-in analytics ring buffer.
 type VectorCacheConfig = engine.VectorCacheConfig
@@ -153,6 +181,11 @@ type Permission = storage.Permission
 // Grant authorizes a Permission on a schema/table pair, with "*" as wildcard.
 type Grant = storage.Grant
 
+// RowPolicy is a row-level security rule installed with DB.SetPolicy: a
+// filter expression that limits the rows a user sees (with $current_user
+// standing for the WithUser name) plus the DML it permits.
+type RowPolicy = storage.RowPolicy
+
 // CatalogRole is a named RBAC role and its grants.
 type CatalogRole = storage.CatalogRole
 
@@ -241,6 +274,9 @@ type CatalogJob = storage.CatalogJob
 // CatalogJobHistory records a scheduled job run.
 type CatalogJobHistory = storage.CatalogJobHistory
 
+// JobHistoryEntry is one row of the scheduler's _job_history table.
+type JobHistoryEntry = storage.JobHistoryEntry
+
 // DBHealth describes database lifecycle, storage, scheduler, and recovery state.
 type DBHealth = storage.DBHealth
 
@@ -770,6 +806,51 @@ func Execute(ctx context.Context, db *DB, tenant string, stmt Statement) (*Resul
 	return engine.Execute(ctx, db, tenant, stmt)
 }
 
+// ExecuteWithOptions is Execute with per-statement resource limits. A
+// statement whose intermediate row sets outgrow opts.MaxMemoryBytes fails
+// with ErrQueryMemoryExceeded; one returning more than opts.MaxRows rows fails
+// with ErrQueryRowLimitExceeded. Either way DML is rolled back.
+func ExecuteWithOptions(ctx context.Context, db *DB, tenant string, stmt Statement, opts QueryOptions) (*ResultSet, error) {
+	return engine.ExecuteWithOptions(ctx, db, tenant, stmt, opts)
+}
+
+// ExecOption configures ExecuteWith; see WithTracer and WithQueryOptions.
+type ExecOption = engine.ExecOption
+
+// ExecConfig is the configuration ExecOptions build for one statement.
+type ExecConfig = engine.ExecConfig
+
+// ExecuteWith is Execute configured by functional options:
+//
+//	rs, err := tinysql.ExecuteWith(ctx, db, "default", stmt,
+//	    tinysql.WithTracer(otel.Tracer("myapp")),
+//	    tinysql.WithQueryOptions(tinysql.QueryOptions{MaxRows: 10000}))
+func ExecuteWith(ctx context.Context, db *DB, tenant string, stmt Statement, opts ...ExecOption) (*ResultSet, error) {
+	return engine.ExecuteWith(ctx, db, tenant, stmt, opts...)
+}
+
+// WithTracer traces the statement with an OpenTelemetry tracer: one
+// "tinysql.Execute" span, a child of any span in ctx, with child spans for
+// the scan, join, filter, aggregate and sort phases carrying the table name,
+// row count and duration. Without it, or without an OpenTelemetry SDK
+// registered, tracing is a no-op.
+func WithTracer(t trace.Tracer) ExecOption {
+	return engine.WithTracer(t)
+}
+
+// WithQueryOptions applies resource limits, as ExecuteWithOptions does.
+func WithQueryOptions(opts QueryOptions) ExecOption {
+	return engine.WithQueryOptions(opts)
+}
+
+// ErrQueryMemoryExceeded is returned by ExecuteWithOptions when a statement
+// exceeds QueryOptions.MaxMemoryBytes.
+var ErrQueryMemoryExceeded = engine.ErrQueryMemoryExceeded
+
+// ErrQueryRowLimitExceeded is returned by ExecuteWithOptions when a result
+// has more than QueryOptions.MaxRows rows.
+var ErrQueryRowLimitExceeded = engine.ErrQueryRowLimitExceeded
+
 // ExecSQL parses and executes exactly one SQL statement. It is the concise
 // public entry point for dynamic SQL, scripts with one statement per call, and
 // small embedded applications. For repeated SQL, prefer Compile or database/sql
@@ -784,6 +865,18 @@ func ExecSQL(ctx context.Context, db *DB, tenant, sql string) (*ResultSet, error
 	return Execute(ctx, db, tenant, stmt)
 }
 
+// ExplainAnalyze parses and executes one SQL statement and reports the
+// operations it ran — scans, joins, filters, aggregation, sorting — with
+// actual row counts and timings. The statement really runs: DML changes the
+// database exactly as ExecSQL would.
+func ExplainAnalyze(ctx context.Context, db *DB, tenant string, sql string) (*AnalyzedPlan, error) {
+	stmt, err := ParseSQL(sql)
+	if err != nil {
+		return nil, err
+	}
+	return engine.ExplainAnalyze(ctx, db, tenant, stmt)
+}
+
 // WithUser returns a context carrying the acting username for RBAC
 // permission checks (see CREATE USER/CREATE ROLE/GRANT below). Pass the
 // result to Execute/ExecuteCompiled in place of a plain context.
@@ -811,6 +904,10 @@ func UserFromContext(ctx context.Context) (string, bool) {
 	return engine.UserFromContext(ctx)
 }
 
+// ErrPolicyViolation is returned (wrapped) when a row-level security
+// policy forbids an INSERT, UPDATE or DELETE.
+var ErrPolicyViolation = engine.ErrPolicyViolation
+
 // ParsePermission validates and normalizes an RBAC permission keyword.
 func ParsePermission(s string) (Permission, error) {
 	return storage.ParsePermission(s)
@@ -858,6 +955,14 @@ func (e *SQLJobExecutor) ExecuteSQL(ctx context.Context, sql string) (interface{
 	return Execute(ctx, e.DB, e.Tenant, stmt)
 }
 
+// JobTenant reports the tenant the scheduler keeps its _job_history table in.
+func (e *SQLJobExecutor) JobTenant() string {
+	if e == nil {
+		return ""
+	}
+	return e.Tenant
+}
+
 // StartJobScheduler starts the database job scheduler for a tenant.
 func StartJobScheduler(db *DB, tenant string) error {
 	if db == nil {
@@ -935,10 +1040,29 @@ func GetVal(row Row, name string) (any, bool) {
 //
 // Note: This saves the current state only. For durability during crashes,
 // use AttachAdvancedWAL to enable write-ahead logging.
+//
+// When db was opened in ModeWAL with filename as its path, every commit is
+// already in the log, so SaveToFile only syncs the WAL instead of rewriting
+// the whole snapshot. Use CheckpointWAL to compact the log into the file.
 func SaveToFile(db *DB, filename string) error {
+	if w := db.WAL(); w != nil && filepath.Clean(w.CheckpointPath()) == filepath.Clean(filename) {
+		return w.Sync()
+	}
 	return storage.SaveToFile(db, filename)
 }
 
+// CheckpointWAL writes a full snapshot of a ModeWAL database to path and
+// empties its write-ahead log. path must be the file the database was opened
+// with. Checkpoints also run automatically after checkpoint_every commits,
+// checkpoint_interval or checkpoint_max_bytes of log.
+func CheckpointWAL(db *DB, path string) error {
+	return storage.CheckpointWAL(db, path)
+}
+
+// ErrWALLocked is returned when opening a ModeWAL database whose log is
+// already held by another writer, in this process or another one.
+var ErrWALLocked = storage.ErrWALLocked
+
 // SaveToWriter serializes a consistent database snapshot to w. It is useful
 // for embedded targets, HTTP responses, and callers that own their storage.
 func SaveToWriter(db *DB, w io.Writer) error {
@@ -958,7 +1082,9 @@ func SaveToWriter(db *DB, w io.Writer) error {
 //	}
 //	defer db.Close()
 //
-// Returns a new DB instance or an error if the file cannot be read.
+// Returns a new DB instance or an error if the file cannot be read. A file
+// whose checksum does not match what SaveToFile recorded is rejected rather
+// than partially loaded.
 func LoadFromFile(filename string) (*DB, error) {
 	return storage.LoadFromFile(filename)
 }
@@ -984,6 +1110,26 @@ func LoadFromBytes(snapshot []byte) (*DB, error) {
 	return storage.LoadFromBytes(snapshot)
 }
 
+// ExportSQL writes tenant's tables to w as a portable SQL script: CREATE
+// TABLE statements with columns, constraints and defaults, INSERTs of up to
+// 100 rows each and CREATE INDEX statements. Unlike SaveToWriter the output
+// is plain text that SQLite and other SQL databases can also read.
+//
+// Example:
+//
+//	var buf bytes.Buffer
+//	err := tinysql.ExportSQL(ctx, db, "default", &buf)
+func ExportSQL(ctx context.Context, db *DB, tenant string, w io.Writer) error {
+	return engine.DumpSQL(ctx, db, tenant, w)
+}
+
+// LoadFromSQL executes the statements of a SQL script, such as one written
+// by ExportSQL, against tenant. It stops at the first failing statement;
+// statements before it stay applied.
+func LoadFromSQL(ctx context.Context, db *DB, tenant string, r io.Reader) error {
+	return engine.LoadSQL(ctx, db, tenant, r)
+}
+
 // ============================================================================
 // Advanced WAL - Write-Ahead Logging
 // ============================================================================
@@ -1078,6 +1224,10 @@ type ImportOptions = importer.ImportOptions
 // Contains metadata about the import operation.
 type ImportResult = importer.ImportResult
 
+// InferredColumnType is one entry of ImportResult.InferredTypes: the type
+// votes and NULL count of a sampled CSV column and the type chosen for it.
+type InferredColumnType = importer.InferredColumnType
+
 // ImportFile imports a structured data file into a table.
 // The format is auto-detected from the file extension or content.
 //
@@ -1142,6 +1292,15 @@ func ImportCSV(ctx context.Context, db *DB, tenant, tableName string, src io.Rea
 	return importer.ImportCSV(ctx, db, tenant, tableName, src, opts)
 }
 
+// ImportCSVStream imports CSV/TSV data like ImportCSV but stores each row in
+// the table as soon as it is parsed, so memory use stays constant however
+// large src is. Set ImportOptions.ProgressFunc to observe progress. When ctx
+// is cancelled the rows stored so far are kept and the partial result is
+// returned with ctx's error.
+func ImportCSVStream(ctx context.Context, db *DB, tenant, tableName string, src io.Reader, opts *ImportOptions) (*ImportResult, error) {
+	return importer.ImportCSVStream(ctx, db, tenant, tableName, src, opts)
+}
+
 // ImportJSON imports JSON data from a reader into a table.
 // Supports array of objects format: [{"id": 1, "name": "Alice"}, ...]
 //
@@ -1253,6 +1412,14 @@ func OpenFile(ctx context.Context, filePath string, opts *ImportOptions) (*DB, s
 // delimiters, malformed quotes, or other common data quality problems.
 type FuzzyImportOptions = importer.FuzzyImportOptions
 
+// ErrFuzzyColumnCount is passed (wrapped) to FuzzyImportOptions.OnError for a
+// row whose field count differs from the header.
+var ErrFuzzyColumnCount = importer.ErrFuzzyColumnCount
+
+// ErrFuzzyConversion is passed (wrapped) to FuzzyImportOptions.OnError for a
+// row with a value that does not convert to its column's type.
+var ErrFuzzyConversion = importer.ErrFuzzyConversion
+
 // FuzzyImportCSV is a more forgiving version of ImportCSV that handles malformed data.
 // It attempts to automatically fix common issues like:
 //   - Inconsistent column counts (pads/truncates rows)
@@ -1298,6 +1465,60 @@ func FuzzyImportJSON(ctx context.Context, db *DB, tenant, tableName string, src
 	return importer.FuzzyImportJSON(ctx, db, tenant, tableName, src, opts)
 }
 
+// ============================================================================
+// COPY FROM - PostgreSQL-style bulk load
+// ============================================================================
+
+// CopyOptions configures CopyFromReader and mirrors the WITH (...) options of
+// COPY ... FROM: FORMAT (CSV or TEXT), DELIMITER, NULL and HEADER.
+type CopyOptions = engine.CopyOptions
+
+// WithCopyInput returns a context whose COPY ... FROM STDIN statements read
+// from r:
+//
+//	stmt, _ := tinysql.ParseSQL(`COPY users FROM STDIN WITH (FORMAT CSV, HEADER)`)
+//	rs, err := tinysql.Execute(tinysql.WithCopyInput(ctx, file), db, "default", stmt)
+func WithCopyInput(ctx context.Context, r io.Reader) context.Context {
+	return engine.WithCopyInput(ctx, r)
+}
+
+// CopyFromReader bulk-loads delimited rows from r into an existing table, as
+// COPY tableName FROM STDIN does. Values are coerced to the column types and
+// checked against constraints like INSERT, but the input is never parsed as
+// SQL, which makes it much faster than one INSERT per row. Like any DML, a
+// failing row rolls back the whole load. A nil opts means CSV with a comma
+// delimiter and no header.
+func CopyFromReader(ctx context.Context, db *DB, tenant, tableName string, r io.Reader, opts *CopyOptions) (*ImportResult, error) {
+	stmt := &engine.CopyFrom{Table: tableName}
+	if opts != nil {
+		stmt.CopyOptions = *opts
+	}
+	rs, err := engine.Execute(engine.WithCopyInput(ctx, r), db, tenant, stmt)
+	if err != nil {
+		return nil, err
+	}
+	result := &ImportResult{
+		RowsInserted: rs.RowsAffected(),
+		Delimiter:    stmt.Delimiter,
+		HadHeader:    stmt.Header,
+		ColumnNames:  stmt.Columns,
+	}
+	if result.Delimiter == 0 {
+		result.Delimiter = ','
+		if strings.EqualFold(stmt.Format, "TEXT") {
+			result.Delimiter = '\t'
+		}
+	}
+	if len(result.ColumnNames) == 0 {
+		if t, err := db.Get(tenant, tableName); err == nil {
+			for _, c := range t.Cols {
+				result.ColumnNames = append(result.ColumnNames, c.Name)
+			}
+		}
+	}
+	return result, nil
+}
+
 // ============================================================================
 // External Table-Valued Functions
 // ============================================================================
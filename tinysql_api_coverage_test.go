@@ -3,10 +3,17 @@ package tinysql_test
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
 	tsql "github.com/SimonWaldherr/tinySQL"
 )
 
@@ -63,6 +70,70 @@ func TestPublicExecSQL(t *testing.T) {
 	}
 }
 
+func TestPublicExplainAnalyze(t *testing.T) {
+	db := tsql.NewDB()
+	ctx := context.Background()
+	if _, err := tsql.ExecSQL(ctx, db, "default", `CREATE TABLE ea_items (id INTEGER, price INTEGER)`); err != nil {
+		t.Fatalf("CREATE: %v", err)
+	}
+	if _, err := tsql.ExecSQL(ctx, db, "default", `INSERT INTO ea_items VALUES (1, 5), (2, 15), (3, 25)`); err != nil {
+		t.Fatalf("INSERT: %v", err)
+	}
+	plan, err := tsql.ExplainAnalyze(ctx, db, "default", `SELECT id FROM ea_items WHERE price > 10`)
+	if err != nil {
+		t.Fatalf("ExplainAnalyze: %v", err)
+	}
+	if plan.ActualRows != 2 || len(plan.Steps) == 0 || plan.Steps[0].EstimatedRows != 3 || plan.ElapsedMs <= 0 {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	if _, err := tsql.ExplainAnalyze(ctx, db, "default", `SELECT FROM`); err == nil {
+		t.Fatal("expected a parse error")
+	}
+}
+
+func TestPublicExecuteWithOptions(t *testing.T) {
+	db := tsql.NewDB()
+	ctx := context.Background()
+	if _, err := tsql.ExecSQL(ctx, db, "default", `CREATE TABLE opt_items (id INTEGER)`); err != nil {
+		t.Fatalf("CREATE: %v", err)
+	}
+	if _, err := tsql.ExecSQL(ctx, db, "default", `INSERT INTO opt_items VALUES (1), (2), (3)`); err != nil {
+		t.Fatalf("INSERT: %v", err)
+	}
+	stmt := tsql.MustParseSQL(`SELECT id FROM opt_items`)
+	if _, err := tsql.ExecuteWithOptions(ctx, db, "default", stmt, tsql.QueryOptions{MaxRows: 2}); !errors.Is(err, tsql.ErrQueryRowLimitExceeded) {
+		t.Fatalf("MaxRows: err=%v", err)
+	}
+	if _, err := tsql.ExecuteWithOptions(ctx, db, "default", stmt, tsql.QueryOptions{MaxMemoryBytes: 64}); !errors.Is(err, tsql.ErrQueryMemoryExceeded) {
+		t.Fatalf("MaxMemoryBytes: err=%v", err)
+	}
+	rs, err := tsql.ExecuteWithOptions(ctx, db, "default", stmt, tsql.QueryOptions{MaxRows: 3, MaxMemoryBytes: 1 << 20})
+	if err != nil || len(rs.Rows) != 3 {
+		t.Fatalf("within limits: rs=%v err=%v", rs, err)
+	}
+}
+
+func TestPublicExecuteWithTracer(t *testing.T) {
+	db := tsql.NewDB()
+	ctx := context.Background()
+	if _, err := tsql.ExecSQL(ctx, db, "default", `CREATE TABLE traced (id INTEGER)`); err != nil {
+		t.Fatalf("CREATE: %v", err)
+	}
+	rec := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)).Tracer("test")
+	stmt := tsql.MustParseSQL(`SELECT id FROM traced`)
+	if _, err := tsql.ExecuteWith(ctx, db, "default", stmt, tsql.WithTracer(tracer), tsql.WithQueryOptions(tsql.QueryOptions{MaxRows: 10})); err != nil {
+		t.Fatalf("ExecuteWith: %v", err)
+	}
+	var names []string
+	for _, span := range rec.Ended() {
+		names = append(names, span.Name())
+	}
+	if strings.Join(names, ",") != "tinysql.scan,tinysql.Execute" {
+		t.Fatalf("spans = %v", names)
+	}
+}
+
 func TestPublicReaderWriterPersistence(t *testing.T) {
 	db := tsql.NewDB()
 	if _, err := tsql.ExecSQL(context.Background(), db, "default", `CREATE TABLE snapshots (id INTEGER)`); err != nil {
@@ -272,3 +343,189 @@ func TestPublicAPIByteSnapshots(t *testing.T) {
 		t.Fatalf("unexpected restored rows: %#v", rs.Rows)
 	}
 }
+
+func TestPublicAPICheckpointWAL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.db")
+	db, err := tsql.OpenDB(tsql.StorageConfig{Mode: tsql.ModeWAL, Path: path, CheckpointEvery: 1000, CheckpointMaxBytes: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := tsql.ExecSQL(ctx, db, "default", `CREATE TABLE wal_items (id INT, name TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		if _, err := tsql.ExecSQL(ctx, db, "default", fmt.Sprintf(`INSERT INTO wal_items VALUES (%d, 'row')`, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// In WAL mode SaveToFile only syncs the log; no snapshot is written.
+	if err := tsql.SaveToFile(db, path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("SaveToFile rewrote the snapshot in WAL mode: %v", err)
+	}
+	before := db.WAL().Size()
+	if before == 0 {
+		t.Fatal("inserts were not logged")
+	}
+
+	if err := tsql.CheckpointWAL(db, filepath.Join(t.TempDir(), "other.db")); err == nil {
+		t.Fatal("CheckpointWAL accepted a path the WAL does not belong to")
+	}
+	if err := tsql.CheckpointWAL(db, path); err != nil {
+		t.Fatal(err)
+	}
+	if after := db.WAL().Size(); after >= before {
+		t.Fatalf("WAL size after checkpoint = %d, before %d", after, before)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := tsql.OpenDB(tsql.StorageConfig{Mode: tsql.ModeWAL, Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	rs, err := tsql.ExecSQL(ctx, reopened, "default", `SELECT COUNT(*) AS n FROM wal_items`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := rs.Rows[0]["n"]; fmt.Sprint(n) != "20" {
+		t.Fatalf("rows after checkpoint and reopen = %v, want 20", n)
+	}
+
+	if _, err := tsql.OpenDB(tsql.StorageConfig{Mode: tsql.ModeWAL, Path: path}); !errors.Is(err, tsql.ErrWALLocked) {
+		t.Fatalf("second writer: error %v, want ErrWALLocked", err)
+	}
+}
+
+func TestPublicAPIDBSnapshot(t *testing.T) {
+	db := tsql.NewDB()
+	ctx := context.Background()
+	count := func(db *tsql.DB) int {
+		t.Helper()
+		rs, err := tsql.ExecSQL(ctx, db, "default", `SELECT COUNT(*) AS n FROM snap_rows`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var n int
+		fmt.Sscan(fmt.Sprint(rs.Rows[0]["n"]), &n)
+		return n
+	}
+	if _, err := tsql.ExecSQL(ctx, db, "default", `CREATE TABLE snap_rows (id INT)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tsql.ExecSQL(ctx, db, "default", `INSERT INTO snap_rows VALUES (1), (2), (3)`); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := db.Snapshot()
+	for i := 4; i <= 10; i++ {
+		if _, err := tsql.ExecSQL(ctx, db, "default", fmt.Sprintf(`INSERT INTO snap_rows VALUES (%d)`, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := tsql.ExecSQL(ctx, db, "default", `DELETE FROM snap_rows WHERE id = 1`); err != nil {
+		t.Fatal(err)
+	}
+	restored := snap.Restore()
+	if got := count(restored); got != 3 {
+		t.Fatalf("restored rows = %d, want 3", got)
+	}
+	// The restored DB is independent of the snapshot it came from.
+	if _, err := tsql.ExecSQL(ctx, restored, "default", `DELETE FROM snap_rows`); err != nil {
+		t.Fatal(err)
+	}
+	if got := count(snap.Restore()); got != 3 {
+		t.Fatalf("second restore = %d rows, want 3", got)
+	}
+
+	next := db.Snapshot()
+	if next.SnapshotID() <= snap.SnapshotID() || next.CreatedAt().Before(snap.CreatedAt()) {
+		t.Fatalf("snapshot ids %d then %d, times %v then %v", snap.SnapshotID(), next.SnapshotID(), snap.CreatedAt(), next.CreatedAt())
+	}
+
+	data, err := snap.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded tsql.DBSnapshot
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.SnapshotID() != snap.SnapshotID() || !decoded.CreatedAt().Equal(snap.CreatedAt()) {
+		t.Fatalf("decoded id %d at %v, want %d at %v", decoded.SnapshotID(), decoded.CreatedAt(), snap.SnapshotID(), snap.CreatedAt())
+	}
+	if got := count(decoded.Restore()); got != 3 {
+		t.Fatalf("decoded snapshot rows = %d, want 3", got)
+	}
+	if err := decoded.UnmarshalBinary([]byte("garbage")); err == nil {
+		t.Fatal("UnmarshalBinary accepted garbage")
+	}
+
+	// Readers keep querying their snapshot while a writer keeps inserting.
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 100; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if _, err := tsql.ExecSQL(ctx, db, "default", fmt.Sprintf(`INSERT INTO snap_rows VALUES (%d)`, i)); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	for r := 0; r < 4; r++ {
+		held := db.Snapshot().Restore()
+		want := count(held)
+		for i := 0; i < 20; i++ {
+			if got := count(held); got != want {
+				t.Fatalf("snapshot changed under a writer: %d rows, then %d", want, got)
+			}
+		}
+	}
+	close(done)
+	wg.Wait()
+}
+
+func TestPublicCopyFromReader(t *testing.T) {
+	ctx := context.Background()
+	db := tsql.NewDB()
+	if _, err := tsql.ExecSQL(ctx, db, "default", `CREATE TABLE items (id INT, name TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+	res, err := tsql.CopyFromReader(ctx, db, "default", "items", strings.NewReader("id;name\n1;a\n2;-\n"),
+		&tsql.CopyOptions{Delimiter: ';', Null: "-", NullSet: true, Header: true})
+	if err != nil {
+		t.Fatalf("CopyFromReader: %v", err)
+	}
+	if res.RowsInserted != 2 || res.Delimiter != ';' || !res.HadHeader || len(res.ColumnNames) != 2 {
+		t.Fatalf("result = %+v", res)
+	}
+	rs, err := tsql.ExecSQL(ctx, db, "default", `SELECT COUNT(*) AS n FROM items WHERE name IS NULL`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := tsql.GetVal(rs.Rows[0], "n"); fmt.Sprint(n) != "1" {
+		t.Fatalf("NULL rows = %v", n)
+	}
+
+	stmt, err := tsql.ParseSQL(`COPY items FROM STDIN`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs, err = tsql.Execute(tsql.WithCopyInput(ctx, strings.NewReader("3,c\n")), db, "default", stmt)
+	if err != nil || rs.RowsAffected() != 1 {
+		t.Fatalf("COPY FROM STDIN: rs=%v err=%v", rs, err)
+	}
+}